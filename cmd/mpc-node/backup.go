@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/pkg/backup"
+	"github.com/mr-shifu/mpc-lib/protocols/frost"
+)
+
+// ErrBackupDisabled is returned by ExportBackup when the node has no backup recipient.
+var ErrBackupDisabled = errors.New("mpc-node: no backup recipient configured")
+
+// Backup is the result of keys.backup.
+type Backup struct {
+	KeyID string `json:"keyId"`
+	// Recipients describes the keys the bundle is encrypted to.
+	Recipients string `json:"recipients"`
+	// Data is the encrypted bundle, an OpenPGP message.
+	Data []byte `json:"data"`
+}
+
+// WithBackupRecipient sets the custodians to whose keys ExportBackup encrypts the shares.
+// They are not given with each request, so that a caller of the control API cannot export
+// the shares to a key of its own.
+func (n *Node) WithBackupRecipient(r backup.Recipient) *Node {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.backupRecipient = r
+	return n
+}
+
+// ExportBackup returns the shares of the ceremony keyID, encrypted to the backup recipient of the node:
+// the CMP config of its secp256k1 key and the FROST share of its ed25519 key, whichever it generated.
+// It fails with ErrUnknownKey until all the keys of the ceremony were generated.
+// OPRF keys are exported as well, since their outputs cannot be recomputed without them.
+func (n *Node) ExportBackup(keyID string) (*Backup, error) {
+	n.mtx.Lock()
+	r := n.backupRecipient
+	curves, ok := n.ceremonies[keyID]
+	n.mtx.Unlock()
+	if r == nil {
+		return nil, ErrBackupDisabled
+	}
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	b := &backup.Bundle{KeyID: keyID, Party: n.self, Created: time.Now()}
+	for _, c := range curves {
+		var err error
+		switch c {
+		case CurveSecp256k1:
+			b.Config, err = n.exportCMP(keyID)
+		case CurveEd25519:
+			b.FROST, err = n.exportFROST(keyID)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	var out bytes.Buffer
+	if err := backup.Export(&out, b, r); err != nil {
+		return nil, err
+	}
+	log.Printf("mpc-node: exported the shares of %s to %s", keyID, r)
+	return &Backup{KeyID: keyID, Recipients: r.String(), Data: out.Bytes()}, nil
+}
+
+func (n *Node) exportCMP(keyID string) ([]byte, error) {
+	c, err := n.keyConfig(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return c.MarshalBinary()
+}
+
+func (n *Node) exportFROST(keyID string) ([]byte, error) {
+	_, h, err := n.started(ceremonySessionID(keyID, CurveEd25519))
+	if err != nil {
+		return nil, ErrUnknownKey
+	}
+	result, err := h.Result()
+	if err != nil {
+		return nil, ErrUnknownKey
+	}
+	c, ok := result.(*frost.Config)
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	share, err := n.frost.ExportShare(keyID, c)
+	if err != nil {
+		return nil, err
+	}
+	return share.MarshalBinary()
+}
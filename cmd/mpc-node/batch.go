@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/pkg/approval"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/record"
+)
+
+var (
+	ErrEmptyBatch    = errors.New("mpc-node: empty batch")
+	ErrBatchTooLarge = errors.New("mpc-node: batch too large")
+)
+
+// MaxBatchSize is the maximum number of sessions of a batch.
+const MaxBatchSize = 256
+
+// SignRequest is one of the sign sessions of a batch, with the same fields as a single sign request.
+type SignRequest struct {
+	SignID   string     `json:"signId"`
+	KeyID    string     `json:"keyId"`
+	Parties  []party.ID `json:"parties"`
+	Message  []byte     `json:"message"`
+	DedupKey string     `json:"dedupKey,omitempty"`
+	Context  string     `json:"context,omitempty"`
+	// Labels are stored with the record of the signature, see record.Labels.
+	Labels record.Labels `json:"labels,omitempty"`
+	// ReleaseTime is the time before which the signers refuse to complete the signature, if not zero.
+	// All signers must be given the same one.
+	ReleaseTime time.Time `json:"releaseTime"`
+	// Approvals are the WebAuthn assertions of the operators approving the request,
+	// required if the node has an approval policy (see Node.WithApproval).
+	Approvals []approval.Assertion `json:"approvals,omitempty"`
+}
+
+// BatchItem is the outcome of one session of a batch.
+// Status is nil if the session could not be started or queried, in which case Error describes why.
+type BatchItem struct {
+	SignID string         `json:"signId"`
+	Status *SessionStatus `json:"status,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// StartSignBatch starts the sign sessions of reqs concurrently, for instance to sweep many addresses at once.
+// The requests are independent: they may use different keys and messages, and a request which fails to start
+// does not prevent the others from starting. A batch holds at most MaxBatchSize requests, whose first rounds
+// are computed by one worker per CPU.
+//
+// The returned items are in the order of reqs. SignID is the ID of the session serving the request,
+// which differs from the requested one for retries of a request with a dedupKey (see StartSign).
+// Errors are described at the node's level of detail, like the status of a session.
+func (n *Node) StartSignBatch(reqs []SignRequest) ([]BatchItem, error) {
+	if err := checkBatchSize(len(reqs)); err != nil {
+		return nil, err
+	}
+	items := make([]BatchItem, len(reqs))
+	next := make(chan int)
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(reqs) {
+		workers = len(reqs)
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				item, req := &items[i], reqs[i]
+				item.SignID = req.SignID
+				signID, err := n.StartSignRequest(req)
+				if err != nil {
+					item.Error = n.redact(err)
+					continue
+				}
+				item.SignID = signID
+				n.batchStatus(item)
+			}
+		}()
+	}
+	for i := range reqs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return items, nil
+}
+
+// SignBatchStatus returns the status of the sessions of a batch, in the order of signIDs.
+// Sessions which completed carry their signature, so that the batch can be collected once all are done.
+func (n *Node) SignBatchStatus(signIDs []string) ([]BatchItem, error) {
+	if err := checkBatchSize(len(signIDs)); err != nil {
+		return nil, err
+	}
+	items := make([]BatchItem, len(signIDs))
+	for i, id := range signIDs {
+		items[i].SignID = id
+		n.batchStatus(&items[i])
+	}
+	return items, nil
+}
+
+func (n *Node) batchStatus(item *BatchItem) {
+	status, err := n.Status(item.SignID)
+	if err != nil {
+		item.Error = n.redact(err)
+		return
+	}
+	item.Status = status
+}
+
+func checkBatchSize(size int) error {
+	switch {
+	case size == 0:
+		return ErrEmptyBatch
+	case size > MaxBatchSize:
+		return fmt.Errorf("%w: %d sessions, at most %d", ErrBatchTooLarge, size, MaxBatchSize)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignBatch(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	nodes := newNodes(t, ids)
+	for _, n := range nodes {
+		require.NoError(t, n.CreateKey("key", 1, ids))
+	}
+	run(t, nodes, "key")
+
+	reqs := make([]SignRequest, 0, 5)
+	for i := 0; i < 4; i++ {
+		reqs = append(reqs, SignRequest{SignID: fmt.Sprintf("sign-%d", i), KeyID: "key", Parties: ids, Message: make([]byte, 32)})
+	}
+	reqs = append(reqs, SignRequest{SignID: "unknown", KeyID: "other", Parties: ids, Message: make([]byte, 32)})
+	for _, n := range nodes {
+		items, err := n.StartSignBatch(reqs)
+		require.NoError(t, err)
+		require.Len(t, items, len(reqs))
+		for i, item := range items[:4] {
+			require.Equal(t, reqs[i].SignID, item.SignID)
+			require.Empty(t, item.Error)
+			require.NotNil(t, item.Status)
+		}
+		// an invalid request is described as is, and does not prevent the others from starting
+		require.Equal(t, "unknown", items[4].SignID)
+		require.Nil(t, items[4].Status)
+		require.Contains(t, items[4].Error, ErrUnknownKey.Error())
+	}
+	signIDs := make([]string, 0, 4)
+	for _, req := range reqs[:4] {
+		run(t, nodes, req.SignID)
+		signIDs = append(signIDs, req.SignID)
+	}
+	items, err := nodes["a"].SignBatchStatus(append(signIDs, "missing"))
+	require.NoError(t, err)
+	for _, item := range items[:4] {
+		require.Equal(t, StatusCompleted, item.Status.Status)
+		require.NotEmpty(t, item.Status.Result)
+	}
+	require.Contains(t, items[4].Error, ErrUnknownSession.Error())
+
+	_, err = nodes["a"].StartSignBatch(nil)
+	require.ErrorIs(t, err, ErrEmptyBatch)
+	_, err = nodes["a"].StartSignBatch(make([]SignRequest, MaxBatchSize+1))
+	require.ErrorIs(t, err, ErrBatchTooLarge)
+	_, err = nodes["a"].SignBatchStatus(make([]string, MaxBatchSize+1))
+	require.ErrorIs(t, err, ErrBatchTooLarge)
+}
+
+func TestRedact(t *testing.T) {
+	n := newNodes(t, party.IDSlice{"a"})["a"]
+	internal := errors.New("paillier: p is not prime")
+	require.Equal(t, protocol.Redact(internal, protocol.DetailCode), n.redact(internal))
+	require.NotContains(t, n.redact(internal), "paillier")
+	require.Equal(t, ErrUnknownKey.Error(), n.redact(ErrUnknownKey))
+
+	n.WithErrorDetail(protocol.DetailFull)
+	require.Equal(t, internal.Error(), n.redact(internal))
+}
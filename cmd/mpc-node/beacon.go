@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/pkg/beacon"
+	thresholdenc "github.com/mr-shifu/mpc-lib/pkg/threshold-encryption"
+	"github.com/mr-shifu/mpc-lib/pkg/vrf"
+)
+
+var (
+	ErrUnknownBeacon    = errors.New("mpc-node: unknown beacon round")
+	ErrInvalidResponses = errors.New("mpc-node: invalid beacon responses")
+)
+
+// beaconRound identifies a published beacon.
+type beaconRound struct {
+	keyID string
+	round uint64
+}
+
+// beaconEvaluationID is the ID of the VRF evaluation of a beacon round, see CommitVRF.
+func beaconEvaluationID(round uint64) string {
+	return fmt.Sprintf("beacon/%d", round)
+}
+
+// CommitBeacon starts the evaluation of the beacon of round with the secp256k1 key of the ceremony keyID,
+// and returns the marshalled vrf.Commitment of this node.
+func (n *Node) CommitBeacon(keyID string, round uint64) ([]byte, error) {
+	return n.CommitVRF(keyID, beaconEvaluationID(round), beacon.Input(round))
+}
+
+// RespondBeacon returns the marshalled vrf.Response of this node to the evaluation of the beacon of round,
+// given the marshalled commitments of the quorum, including its own.
+func (n *Node) RespondBeacon(keyID string, round uint64, commitments [][]byte) ([]byte, error) {
+	return n.RespondVRF(keyID, beaconEvaluationID(round), commitments)
+}
+
+// PublishBeacon combines the commitments and responses of a quorum into the beacon of round,
+// which is then served by Beacon, and returns the marshalled beacon.Beacon.
+//
+// Since the randomness of a round is unique, a round which was already published is returned as is.
+func (n *Node) PublishBeacon(keyID string, round uint64, commitments, responses [][]byte) ([]byte, error) {
+	c, err := n.config(keyID)
+	if err != nil {
+		return nil, err
+	}
+	key := beaconRound{keyID: keyID, round: round}
+	n.mtx.Lock()
+	published, ok := n.beacons[key]
+	n.mtx.Unlock()
+	if ok {
+		return cbor.Marshal(published)
+	}
+
+	decodedCommitments := make([]*vrf.Commitment, 0, len(commitments))
+	for _, data := range commitments {
+		commitment := vrf.EmptyCommitment(c.Group)
+		if err := cbor.Unmarshal(data, commitment); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCommitments, err)
+		}
+		decodedCommitments = append(decodedCommitments, commitment)
+	}
+	decodedResponses := make([]*vrf.Response, 0, len(responses))
+	for _, data := range responses {
+		response := vrf.EmptyResponse(c.Group)
+		if err := cbor.Unmarshal(data, response); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidResponses, err)
+		}
+		decodedResponses = append(decodedResponses, response)
+	}
+	b, culprits, err := beacon.Combine(thresholdenc.NewCommittee(c), round, decodedCommitments, decodedResponses)
+	if err != nil {
+		if len(culprits) > 0 {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidResponses, err)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCommitments, err)
+	}
+
+	n.mtx.Lock()
+	n.beacons[key] = b
+	n.mtx.Unlock()
+	return cbor.Marshal(b)
+}
+
+// Beacon returns the marshalled beacon.Beacon of round, once published with PublishBeacon.
+func (n *Node) Beacon(keyID string, round uint64) ([]byte, error) {
+	n.mtx.Lock()
+	b, ok := n.beacons[beaconRound{keyID: keyID, round: round}]
+	n.mtx.Unlock()
+	if !ok {
+		return nil, ErrUnknownBeacon
+	}
+	return cbor.Marshal(b)
+}
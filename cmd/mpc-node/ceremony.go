@@ -0,0 +1,233 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/mr-shifu/mpc-lib/core/hash"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/mr-shifu/mpc-lib/pkg/keystore"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/config"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp/keygen"
+)
+
+// Curves of the keys produced by a keygen ceremony.
+const (
+	// CurveSecp256k1 produces an ECDSA key with CMP.
+	CurveSecp256k1 = "secp256k1"
+	// CurveEd25519 produces an EdDSA key with FROST.
+	CurveEd25519 = "ed25519"
+)
+
+var ErrUnknownCurve = errors.New("mpc-node: unknown curve")
+
+// CeremonyStatus describes the state of a keygen ceremony, made of one session per curve.
+type CeremonyStatus struct {
+	KeyID string `json:"keyId"`
+	// Status is aborted or timed out if any session is, and completed once all sessions are.
+	Status string `json:"status"`
+	// Sessions are the status of the session of each curve.
+	Sessions map[string]*SessionStatus `json:"sessions"`
+	// Transcript is the hex encoded hash binding the transcripts of all sessions, once each was confirmed.
+	Transcript string `json:"transcript,omitempty"`
+}
+
+// CreateKeys starts a keygen ceremony with ID keyID, which produces a key over each of the curves
+// between the same parties, with the same threshold.
+//
+// Each curve is generated by its own session, with ID ceremonySessionID(keyID, curve), so that a wallet
+// needing both ECDSA and EdDSA keys for an account runs a single ceremony. The secp256k1 key is generated first,
+// and the other sessions start once it completed, with their SSID bound to its config: the RID, the identities
+// and the auxiliary information of the parties. Until then they are reported as starting, and messages delivered
+// to them are refused with ErrSessionStarting. CeremonyStatus reports the combined outcome.
+func (n *Node) CreateKeys(keyID string, threshold int, parties []party.ID, curves []string) error {
+	return n.createKeys(keyID, threshold, parties, curves, false)
+}
+
+// CreateOPRFKey starts a keygen ceremony with ID keyID, for a secp256k1 key which only answers OPRF requests.
+//
+// An OPRF party returns kᵢ⋅B for any point B, so that a key answering OPRF requests can be used as an oracle
+// on its shares: a signing key would give away the decryption shares and VRF outputs of its committee.
+// The key is therefore generated by its own keygen, and refused by sign, VRF and beacon requests,
+// while the keys of CreateKeys are refused by OPRF requests.
+func (n *Node) CreateOPRFKey(keyID string, threshold int, parties []party.ID) error {
+	return n.createKeys(keyID, threshold, parties, []string{CurveSecp256k1}, true)
+}
+
+func (n *Node) createKeys(keyID string, threshold int, parties []party.ID, curves []string, oprf bool) error {
+	if len(curves) == 0 {
+		return fmt.Errorf("%w: no curve given", ErrUnknownCurve)
+	}
+	seen := make(map[string]bool, len(curves))
+	for _, c := range curves {
+		if c != CurveSecp256k1 && c != CurveEd25519 {
+			return fmt.Errorf("%w: %q", ErrUnknownCurve, c)
+		}
+		if seen[c] {
+			return fmt.Errorf("%w: %q given twice", ErrUnknownCurve, c)
+		}
+		seen[c] = true
+	}
+
+	n.mtx.Lock()
+	if _, ok := n.ceremonies[keyID]; ok {
+		n.mtx.Unlock()
+		return ErrSessionExists
+	}
+	n.ceremonies[keyID] = curves
+	if oprf {
+		n.oprfKeys[keyID] = true
+	}
+	n.mtx.Unlock()
+
+	cfg := config.NewKeyConfig(keyID, curve.Secp256k1{}, threshold, n.self, parties)
+	// FROST runs over edwards25519 whatever the group of its config, so none is set
+	edCfg := config.NewKeyConfig(keyID, nil, threshold, n.self, parties)
+	var started []string
+	// the sessions waiting for the secp256k1 keygen are reserved before it starts, so that they cannot miss its completion
+	if seen[CurveSecp256k1] {
+		for _, c := range curves {
+			if c == CurveSecp256k1 {
+				continue
+			}
+			s := &session{kind: keystore.CeremonyKeygen, keyID: keyID, parties: party.NewIDSlice(parties)}
+			s.await = func(secp *cmp.Config) protocol.StartFunc {
+				return bindSession(n.frost.Keygen(edCfg, s.pl), secp)
+			}
+			if err := n.reserve(ceremonySessionID(keyID, c), s); err != nil {
+				n.discardCeremony(keyID, started)
+				return err
+			}
+			started = append(started, c)
+		}
+	}
+	for _, c := range curves {
+		var err error
+		switch {
+		case c == CurveSecp256k1:
+			err = n.start(ceremonySessionID(keyID, c), &session{kind: keystore.CeremonyKeygen, keyID: keyID, parties: party.NewIDSlice(parties)}, func(s *session) protocol.StartFunc {
+				return n.mpc.NewMPCKeygenManager().WithProgress(func(p keygen.Progress) {
+					n.mtx.Lock()
+					s.progress = &p
+					n.mtx.Unlock()
+				}).Start(cfg, s.pl)
+			})
+		case !seen[CurveSecp256k1]:
+			err = n.start(ceremonySessionID(keyID, c), &session{kind: keystore.CeremonyKeygen, keyID: keyID, parties: party.NewIDSlice(parties)}, func(s *session) protocol.StartFunc {
+				return n.frost.Keygen(edCfg, s.pl)
+			})
+		default:
+			continue
+		}
+		if err != nil {
+			n.discardCeremony(keyID, started)
+			return err
+		}
+		started = append(started, c)
+	}
+	return nil
+}
+
+// continueCeremony starts the sessions of the ceremony keyID which were waiting for its secp256k1 keygen,
+// once it completed with secp.
+func (n *Node) continueCeremony(keyID string, secp *cmp.Config) {
+	n.mtx.Lock()
+	curves := n.ceremonies[keyID]
+	n.mtx.Unlock()
+	for _, c := range curves {
+		id := ceremonySessionID(keyID, c)
+		n.mtx.Lock()
+		s, ok := n.sessions[id]
+		n.mtx.Unlock()
+		if !ok || s.await == nil || s.handler != nil {
+			continue
+		}
+		if err := n.run(id, s, func(s *session) protocol.StartFunc { return s.await(secp) }); err != nil {
+			log.Printf("mpc-node: session %s: %v", id, err)
+		}
+	}
+}
+
+// bindSession returns start, with the ID of its session extended by the hash of secp, so that its SSID binds
+// the RID, the identities and the auxiliary information of the parties in the secp256k1 key of the ceremony.
+func bindSession(start protocol.StartFunc, secp *cmp.Config) protocol.StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		h := hash.New()
+		if err := h.WriteAny(secp); err != nil {
+			return nil, err
+		}
+		return start(append(append([]byte(nil), sessionID...), h.Sum()...))
+	}
+}
+
+// ceremonySessionID returns the ID of the session generating the key over c in the ceremony keyID.
+// The secp256k1 session keeps the ID of the ceremony, as for keys created before ceremonies supported several curves.
+func ceremonySessionID(keyID, c string) string {
+	if c == CurveSecp256k1 {
+		return keyID
+	}
+	return keyID + "/" + c
+}
+
+// discardCeremony stops the sessions already started for a ceremony which failed to start.
+func (n *Node) discardCeremony(keyID string, started []string) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	for _, c := range started {
+		id := ceremonySessionID(keyID, c)
+		if s, ok := n.sessions[id]; ok {
+			if s.handler != nil {
+				s.handler.Stop()
+			}
+			s.lease.Release()
+		}
+		delete(n.sessions, id)
+	}
+	delete(n.ceremonies, keyID)
+	delete(n.oprfKeys, keyID)
+}
+
+// CeremonyStatus returns the status of the keygen ceremony keyID, and of each of its sessions.
+func (n *Node) CeremonyStatus(keyID string) (*CeremonyStatus, error) {
+	n.mtx.Lock()
+	curves, ok := n.ceremonies[keyID]
+	n.mtx.Unlock()
+	if !ok {
+		return nil, ErrUnknownSession
+	}
+
+	status := &CeremonyStatus{KeyID: keyID, Status: StatusCompleted, Sessions: make(map[string]*SessionStatus, len(curves))}
+	transcript := hash.New(hash.BytesWithDomain{TheDomain: "Ceremony Key ID", Bytes: []byte(keyID)})
+	confirmed := true
+	for _, c := range curves {
+		s, err := n.Status(ceremonySessionID(keyID, c))
+		if err != nil {
+			return nil, err
+		}
+		status.Sessions[c] = s
+		status.Status = combineStatus(status.Status, s.Status)
+		if s.Transcript == "" {
+			confirmed = false
+			continue
+		}
+		_ = transcript.WriteAny(&hash.BytesWithDomain{TheDomain: "Ceremony Transcript " + c, Bytes: []byte(s.Transcript)})
+	}
+	if status.Status == StatusCompleted && confirmed {
+		status.Transcript = fmt.Sprintf("%x", transcript.Sum())
+	}
+	return status, nil
+}
+
+// combineStatus returns the status of a ceremony with status a, after adding a session with status b.
+func combineStatus(a, b string) string {
+	rank := map[string]int{StatusCompleted: 0, StatusRunning: 1, StatusStarting: 2, StatusTimedOut: 3, StatusAborted: 4}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/mr-shifu/mpc-lib/pkg/backup"
+	"github.com/mr-shifu/mpc-lib/protocols/frost"
+	"github.com/stretchr/testify/require"
+)
+
+// delivery is a message which could not be delivered yet, because its session was still starting.
+type delivery struct {
+	to  party.ID
+	id  string
+	msg *protocol.Message
+}
+
+// runCeremony routes the messages of the sessions of the ceremony keyID between the nodes, until it completed
+// on all of them. Messages delivered to a session which is still starting are retried.
+func runCeremony(t *testing.T, nodes map[party.ID]*Node, keyID string, curves []string) map[party.ID]*CeremonyStatus {
+	t.Helper()
+	var backlog []delivery
+	deliver := func(d delivery) {
+		if err := nodes[d.to].Deliver(d.id, d.msg); errors.Is(err, ErrSessionStarting) {
+			backlog = append(backlog, d)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Minute)
+	for {
+		retry := backlog
+		backlog = nil
+		for _, d := range retry {
+			deliver(d)
+		}
+		for self, n := range nodes {
+			for _, c := range curves {
+				id := ceremonySessionID(keyID, c)
+				msgs, err := n.Outbox(id)
+				require.NoError(t, err)
+				for _, msg := range msgs {
+					for other := range nodes {
+						if other != self && msg.IsFor(other) {
+							deliver(delivery{to: other, id: id, msg: msg})
+						}
+					}
+				}
+			}
+		}
+
+		statuses := make(map[party.ID]*CeremonyStatus, len(nodes))
+		done := true
+		for self, n := range nodes {
+			status, err := n.CeremonyStatus(keyID)
+			require.NoError(t, err)
+			require.NotEqual(t, StatusAborted, status.Status)
+			statuses[self] = status
+			done = done && status.Status == StatusCompleted && status.Transcript != ""
+		}
+		if done {
+			return statuses
+		}
+		require.True(t, time.Now().Before(deadline), "ceremony %s did not complete", keyID)
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// backupCustodian returns the OpenPGP key of a custodian, to which the nodes export their backups.
+func backupCustodian(t *testing.T, nodes map[party.ID]*Node) *openpgp.Entity {
+	custodian, err := openpgp.NewEntity("custodian", "", "custodian@example.com", nil)
+	require.NoError(t, err)
+	var keyring bytes.Buffer
+	require.NoError(t, custodian.Serialize(&keyring))
+	r, err := backup.NewOpenPGPRecipient(keyring.Bytes())
+	require.NoError(t, err)
+	for _, n := range nodes {
+		_, err := n.ExportBackup("missing")
+		require.ErrorIs(t, err, ErrBackupDisabled)
+		n.WithBackupRecipient(r)
+	}
+	return custodian
+}
+
+// exportBackup returns the backup of the key keyID by n, as decrypted by custodian.
+func exportBackup(t *testing.T, n *Node, custodian *openpgp.Entity, keyID string) *backup.Bundle {
+	t.Helper()
+	b, err := n.ExportBackup(keyID)
+	require.NoError(t, err)
+	md, err := openpgp.ReadMessage(bytes.NewReader(b.Data), openpgp.EntityList{custodian}, nil, nil)
+	require.NoError(t, err)
+	data, err := io.ReadAll(md.UnverifiedBody)
+	require.NoError(t, err)
+	bundle, err := backup.Decode(data)
+	require.NoError(t, err)
+	require.Equal(t, keyID, bundle.KeyID)
+	require.Equal(t, n.self, bundle.Party)
+	return bundle
+}
+
+func TestCreateKeys(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	nodes := newNodes(t, ids)
+	curves := []string{CurveEd25519, CurveSecp256k1}
+	for _, n := range nodes {
+		require.NoError(t, n.CreateKeys("key", 1, ids, curves))
+	}
+	// the ed25519 keygen waits for the secp256k1 one
+	for _, n := range nodes {
+		status, err := n.Status(ceremonySessionID("key", CurveEd25519))
+		require.NoError(t, err)
+		require.Equal(t, StatusStarting, status.Status)
+	}
+	custodian := backupCustodian(t, nodes)
+	_, err := nodes["a"].ExportBackup("key")
+	require.ErrorIs(t, err, ErrUnknownKey)
+
+	statuses := runCeremony(t, nodes, "key", curves)
+	a, b := statuses["a"], statuses["b"]
+	require.Equal(t, a.Transcript, b.Transcript)
+	for _, c := range curves {
+		require.NotEmpty(t, a.Sessions[c].Result)
+		require.Equal(t, a.Sessions[c].Result, b.Sessions[c].Result, "parties disagree on the %s key", c)
+	}
+	require.NotEqual(t, a.Sessions[CurveSecp256k1].Transcript, a.Sessions[CurveEd25519].Transcript)
+
+	// the backup holds the shares of both keys
+	bundle := exportBackup(t, nodes["a"], custodian, "key")
+	require.NotEmpty(t, bundle.Config)
+	share := &frost.Share{}
+	require.NoError(t, share.UnmarshalBinary(bundle.FROST))
+	require.Equal(t, a.Sessions[CurveEd25519].Result, fmt.Sprintf("%x", share.Config.PublicKey.Bytes()))
+	require.NotEmpty(t, share.Secret)
+
+	// the ed25519 session is bound to the secp256k1 config, which is the same for all parties
+	bindings := make(map[party.ID][]byte, len(nodes))
+	for id, n := range nodes {
+		secp, err := n.config("key")
+		require.NoError(t, err)
+		_, err = bindSession(func(sessionID []byte) (round.Session, error) {
+			bindings[id] = sessionID
+			return nil, nil
+		}, secp)([]byte("session"))
+		require.NoError(t, err)
+	}
+	require.Equal(t, bindings["a"], bindings["b"])
+	require.Greater(t, len(bindings["a"]), len("session"))
+
+	// an ed25519 key can be generated on its own
+	for _, n := range nodes {
+		require.NoError(t, n.CreateKeys("ed", 1, ids, []string{CurveEd25519}))
+	}
+	statuses = runCeremony(t, nodes, "ed", []string{CurveEd25519})
+	require.Equal(t, statuses["a"].Sessions[CurveEd25519].Result, statuses["b"].Sessions[CurveEd25519].Result)
+	bundle = exportBackup(t, nodes["b"], custodian, "ed")
+	require.Empty(t, bundle.Config)
+	require.NotEmpty(t, bundle.FROST)
+
+	require.ErrorIs(t, nodes["a"].CreateKeys("other", 1, ids, []string{CurveEd25519, "p256"}), ErrUnknownCurve)
+	require.ErrorIs(t, nodes["a"].CreateKeys("key", 1, ids, curves), ErrSessionExists)
+}
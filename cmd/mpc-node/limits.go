@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+)
+
+var (
+	ErrInvalidLimits   = errors.New("mpc-node: invalid limits")
+	ErrMessageTooLarge = errors.New("mpc-node: message too large")
+)
+
+// StatusTimedOut is reported for a running session with no activity for longer than its round timeout.
+const StatusTimedOut = "timed_out"
+
+// Limits are the runtime settings of a Node, which can be changed with Reconfigure.
+type Limits struct {
+	// Workers is the number of workers used by new sessions, 0 meaning all CPUs.
+	Workers int `json:"workers"`
+	// RoundTimeout is the maximum time a session may go without receiving a message, 0 meaning no timeout.
+	RoundTimeout time.Duration `json:"roundTimeout"`
+	// MaxMessageSize is the maximum size of a delivered message's content, 0 meaning no limit.
+	MaxMessageSize int `json:"maxMessageSize"`
+	// SessionMemory is the number of bytes each session may retain, 0 meaning no limit (see protocol.MultiHandler.SetMemoryBudget).
+	SessionMemory int `json:"sessionMemory"`
+	// OfflineParties are the parties which exchange their messages as files or QR chunks (see protocol.OfflineBundle)
+	// rather than over a live transport, such as a cold storage co-signer.
+	OfflineParties []party.ID `json:"offlineParties,omitempty"`
+	// OfflineRoundTimeout replaces RoundTimeout for the sessions in which an offline party takes part,
+	// since its messages arrive at the pace of its operators. 0 means no timeout.
+	OfflineRoundTimeout time.Duration `json:"offlineRoundTimeout,omitempty"`
+}
+
+func (l Limits) validate() error {
+	if l.Workers < 0 || l.RoundTimeout < 0 || l.MaxMessageSize < 0 || l.SessionMemory < 0 || l.OfflineRoundTimeout < 0 {
+		return fmt.Errorf("%w: values must not be negative", ErrInvalidLimits)
+	}
+	return nil
+}
+
+// roundTimeout returns the round timeout of a session between parties.
+func (l Limits) roundTimeout(parties party.IDSlice) time.Duration {
+	for _, id := range l.OfflineParties {
+		if parties.Contains(id) {
+			return l.OfflineRoundTimeout
+		}
+	}
+	return l.RoundTimeout
+}
+
+// Limits returns the current limits of the node.
+func (n *Node) Limits() Limits {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	return n.limits
+}
+
+// Reconfigure applies new limits without interrupting running sessions.
+//
+// Timeouts, message size caps and memory budgets apply immediately to all sessions. A new worker count only applies
+// to sessions started afterwards: running sessions keep their pool, which is torn down once they are all done,
+// at the next reconfiguration or within reapInterval.
+// The pool used for Paillier key generation is kept.
+func (n *Node) Reconfigure(l Limits) error {
+	if err := l.validate(); err != nil {
+		return err
+	}
+	n.mtx.Lock()
+	var budgeted []*protocol.MultiHandler
+	if l.SessionMemory != n.limits.SessionMemory {
+		for _, s := range n.sessions {
+			if s.handler != nil {
+				budgeted = append(budgeted, s.handler)
+			}
+		}
+	}
+	if l.Workers != n.limits.Workers {
+		if n.sessionPool != n.pl {
+			n.retired = append(n.retired, n.sessionPool)
+		}
+		n.sessionPool = pool.NewPool(l.Workers)
+	}
+	n.limits = l
+	n.mtx.Unlock()
+
+	// a handler may hold its lock while computing a round, so it is updated without holding ours
+	for _, h := range budgeted {
+		h.SetMemoryBudget(l.SessionMemory)
+	}
+	n.reap()
+	return nil
+}
+
+// reapInterval is the interval at which the retired pools whose sessions are done are torn down.
+var reapInterval = time.Minute
+
+// reapEvery reaps the retired pools at every interval, until the node is closed,
+// so that they are torn down once their sessions are done even if the node is not reconfigured again.
+func (n *Node) reapEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-n.closed:
+			return
+		case <-ticker.C:
+			n.reap()
+		}
+	}
+}
+
+// reap tears down the retired pools which are no longer used by a running session.
+//
+// A handler may report the progress of its session while holding its lock, which takes ours,
+// so the sessions are checked without holding it.
+func (n *Node) reap() {
+	type usage struct {
+		pl *pool.Pool
+		h  *protocol.MultiHandler
+	}
+	n.mtx.Lock()
+	if len(n.retired) == 0 {
+		n.mtx.Unlock()
+		return
+	}
+	usages := make([]usage, 0, len(n.sessions))
+	for _, s := range n.sessions {
+		usages = append(usages, usage{pl: s.pl, h: s.handler})
+	}
+	n.mtx.Unlock()
+
+	inUse := map[*pool.Pool]bool{}
+	for _, u := range usages {
+		if u.h == nil {
+			inUse[u.pl] = true
+			continue
+		}
+		if _, err := u.h.Result(); err != nil && !errors.As(err, new(protocol.Error)) {
+			inUse[u.pl] = true
+		}
+	}
+
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	retired := n.retired[:0]
+	for _, pl := range n.retired {
+		if inUse[pl] {
+			retired = append(retired, pl)
+			continue
+		}
+		pl.TearDown()
+	}
+	n.retired = retired
+}
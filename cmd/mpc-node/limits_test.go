@@ -0,0 +1,100 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconfigure(t *testing.T) {
+	interval := reapInterval
+	reapInterval = 10 * time.Millisecond
+	defer func() { reapInterval = interval }()
+
+	ids := party.IDSlice{"a", "b"}
+	nodes := newNodes(t, ids)
+	for _, n := range nodes {
+		require.NoError(t, n.CreateKey("key", 1, ids))
+	}
+	run(t, nodes, "key")
+	n := nodes["a"]
+
+	require.ErrorIs(t, n.Reconfigure(Limits{Workers: -1}), ErrInvalidLimits)
+	require.Equal(t, Limits{}, n.Limits())
+
+	// a session started before a new worker count keeps its pool, which is retired
+	_, err := n.StartSign("sign", "key", ids, make([]byte, 32), "")
+	require.NoError(t, err)
+	s, err := n.session("sign")
+	require.NoError(t, err)
+	limits := Limits{Workers: 2, MaxMessageSize: 16, SessionMemory: 1 << 20}
+	require.NoError(t, n.Reconfigure(limits))
+	require.Equal(t, limits, n.Limits())
+	n.mtx.Lock()
+	require.Equal(t, n.pl, s.pl)
+	require.NotEqual(t, n.pl, n.sessionPool)
+	n.mtx.Unlock()
+
+	// a message size cap applies to the running session
+	err = n.Deliver("sign", &protocol.Message{From: "b", Data: make([]byte, 17)})
+	require.ErrorIs(t, err, ErrMessageTooLarge)
+
+	// a second worker count retires the pool of the first one, which no session uses
+	require.NoError(t, n.Reconfigure(Limits{Workers: 3}))
+	n.mtx.Lock()
+	require.Empty(t, n.retired)
+	pl := n.sessionPool
+	n.mtx.Unlock()
+	_, err = n.StartSign("sign2", "key", ids, make([]byte, 32), "")
+	require.NoError(t, err)
+	require.NoError(t, n.Reconfigure(Limits{}))
+	n.mtx.Lock()
+	require.Equal(t, []*pool.Pool{pl}, n.retired)
+	n.mtx.Unlock()
+
+	// the retired pool is torn down once its session is removed, without reconfiguring the node again
+	require.NoError(t, n.RemoveSession("sign2"))
+	require.Eventually(t, func() bool {
+		n.mtx.Lock()
+		defer n.mtx.Unlock()
+		return len(n.retired) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestOfflineRoundTimeout(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	nodes := newNodes(t, ids)
+	for _, n := range nodes {
+		require.NoError(t, n.CreateKey("key", 1, ids))
+	}
+	run(t, nodes, "key")
+	n := nodes["a"]
+
+	limits := Limits{RoundTimeout: time.Millisecond, OfflineParties: []party.ID{"c"}, OfflineRoundTimeout: time.Hour}
+	require.Equal(t, time.Millisecond, limits.roundTimeout(ids))
+	require.Equal(t, time.Hour, limits.roundTimeout(party.IDSlice{"a", "c"}))
+	require.ErrorIs(t, n.Reconfigure(Limits{OfflineRoundTimeout: -1}), ErrInvalidLimits)
+
+	// a session with an offline party waits for it longer than the others
+	limits.OfflineParties = []party.ID{"b"}
+	require.NoError(t, n.Reconfigure(limits))
+	_, err := n.StartSign("sign", "key", ids, make([]byte, 32), "")
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	status, err := n.Status("sign")
+	require.NoError(t, err)
+	require.NotEqual(t, StatusTimedOut, status.Status)
+
+	limits.OfflineRoundTimeout = time.Millisecond
+	require.NoError(t, n.Reconfigure(limits))
+	require.Eventually(t, func() bool {
+		status, err := n.Status("sign")
+		require.NoError(t, err)
+		return status.Status == StatusTimedOut
+	}, time.Minute, 10*time.Millisecond)
+	require.NoError(t, n.RemoveSession("sign"))
+}
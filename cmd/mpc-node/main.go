@@ -0,0 +1,142 @@
+// Command mpc-node runs a signer node exposing a JSON-RPC control API.
+//
+// The node does not ship its own transport: protocol messages produced by a session are
+// collected with "session.outbox" and delivered to the other nodes with "session.deliver".
+// This lets platforms plug the node into whichever message bus they already operate.
+//
+// All requests must carry the API key in an "Authorization: Bearer <key>" header.
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/pkg/approval"
+	"github.com/mr-shifu/mpc-lib/pkg/backup"
+	"github.com/mr-shifu/mpc-lib/pkg/selection"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8645", "address to listen on")
+	id := flag.String("id", "", "party ID of this node")
+	workers := flag.Int("workers", 0, "number of workers, 0 uses all CPUs")
+	roundTimeout := flag.Duration("round-timeout", 0, "maximum time a session may go without receiving a message, 0 disables")
+	maxMessageSize := flag.Int("max-message-size", 0, "maximum size of a delivered message, 0 disables")
+	sessionMemory := flag.Int("session-memory", 0, "maximum number of bytes retained by a session, 0 disables")
+	offlineParties := flag.String("offline-parties", "", "comma separated IDs of the parties exchanging their messages as offline bundles")
+	offlineRoundTimeout := flag.Duration("offline-round-timeout", 0, "round timeout of the sessions with an offline party, 0 disables")
+	attestation := flag.String("attestation", "", "hex encoded attestation of this binary, announced to the other parties")
+	minPeerVersion := flag.String("min-peer-version", "", "minimum version of the library the other parties must run, empty disables")
+	fullErrors := flag.Bool("full-errors", false, "return full error details over the control API and to other parties, instead of error codes")
+	signerSelection := flag.String("signer-selection", "latency", "strategy selecting the signers of sign requests naming none: latency, load or round-robin")
+	maxFailureRate := flag.Float64("max-failure-rate", selection.DefaultMaxFailureRate, "failure rate above which a party is only selected to sign if there are not enough other parties")
+	reputationFile := flag.String("reputation-file", "", "file keeping the statistics of the parties across restarts, empty keeps them in memory")
+	approvalPolicy := flag.String("approval-policy", "", "JSON file of the operators' security keys which must approve each sign request, to which their signature counters are written back, empty disables")
+	backupOpenPGP := flag.String("backup-openpgp", "", "OpenPGP keyring file to whose keys keys.backup encrypts the shares")
+	backupAge := flag.String("backup-age", "", "comma separated age public keys to which keys.backup encrypts the shares, instead of -backup-openpgp")
+	flag.Parse()
+
+	apiKey := os.Getenv("MPC_NODE_API_KEY")
+	if apiKey == "" {
+		log.Fatal("mpc-node: MPC_NODE_API_KEY must be set")
+	}
+	if *id == "" {
+		log.Fatal("mpc-node: -id must be set")
+	}
+	attestationBytes, err := hex.DecodeString(*attestation)
+	if err != nil {
+		log.Fatalf("mpc-node: invalid -attestation: %v", err)
+	}
+
+	var offline []party.ID
+	for _, p := range strings.Split(*offlineParties, ",") {
+		if p != "" {
+			offline = append(offline, party.ID(p))
+		}
+	}
+
+	node, err := NewNode(party.ID(*id), Limits{
+		Workers:        *workers,
+		RoundTimeout:   *roundTimeout,
+		MaxMessageSize: *maxMessageSize,
+		SessionMemory:  *sessionMemory,
+
+		OfflineParties:      offline,
+		OfflineRoundTimeout: *offlineRoundTimeout,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer node.Close()
+	node.WithBuild(attestationBytes, *minPeerVersion)
+	var strategy selection.Strategy
+	switch *signerSelection {
+	case "latency":
+		strategy = selection.LatencyAware(0)
+	case "load":
+		strategy = selection.LoadAware(0)
+	case "round-robin":
+		strategy = selection.RoundRobin(0)
+	default:
+		log.Fatalf("mpc-node: invalid -signer-selection %q", *signerSelection)
+	}
+	node.WithSelection(selection.Reliable(strategy, *maxFailureRate))
+	if *reputationFile != "" {
+		store, err := selection.NewFileReputationStore(*reputationFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		node.WithReputation(store)
+	}
+	if *approvalPolicy != "" {
+		policy, err := approval.LoadPolicy(*approvalPolicy)
+		if err != nil {
+			log.Fatal(err)
+		}
+		node.WithApproval(policy)
+	}
+	if *backupOpenPGP != "" && *backupAge != "" {
+		log.Fatal("mpc-node: only one of -backup-openpgp and -backup-age may be set")
+	}
+	if *backupOpenPGP != "" {
+		keyring, err := os.ReadFile(*backupOpenPGP)
+		if err != nil {
+			log.Fatal(err)
+		}
+		r, err := backup.NewOpenPGPRecipient(keyring)
+		if err != nil {
+			log.Fatal(err)
+		}
+		node.WithBackupRecipient(r)
+	}
+	if *backupAge != "" {
+		r, err := backup.NewAgeRecipient(strings.Split(*backupAge, ",")...)
+		if err != nil {
+			log.Fatal(err)
+		}
+		node.WithBackupRecipient(r)
+	}
+	if *fullErrors {
+		node.WithErrorDetail(protocol.DetailFull)
+	}
+
+	// the timeouts bound the connections held by clients which are slow to send their requests
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           NewServer(node, apiKey),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       time.Minute,
+		IdleTimeout:       2 * time.Minute,
+	}
+	log.Printf("mpc-node: party %s listening on %s", *id, *addr)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,988 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/ecdsa"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/pkg/approval"
+	"github.com/mr-shifu/mpc-lib/pkg/backup"
+	"github.com/mr-shifu/mpc-lib/pkg/beacon"
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/keystore"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/record"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/config"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/message"
+	mpc_record "github.com/mr-shifu/mpc-lib/pkg/mpc/record"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/state"
+	"github.com/mr-shifu/mpc-lib/pkg/selection"
+	"github.com/mr-shifu/mpc-lib/pkg/vault"
+	"github.com/mr-shifu/mpc-lib/pkg/vrf"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp/keygen"
+	"github.com/mr-shifu/mpc-lib/protocols/frost"
+)
+
+var (
+	ErrSessionExists  = errors.New("mpc-node: session already exists")
+	ErrUnknownSession = errors.New("mpc-node: unknown session")
+	// ErrSessionStarting is returned when delivering a message to a session still computing its first round.
+	ErrSessionStarting = errors.New("mpc-node: session is starting")
+	ErrUnknownKey      = errors.New("mpc-node: unknown key")
+	// ErrStaleKey is reported for a sign session whose key was replaced while it was running.
+	ErrStaleKey = errors.New("mpc-node: key was replaced during the session")
+	// ErrKeygenCompleted is returned when removing a completed keygen session, which holds the key.
+	ErrKeygenCompleted = errors.New("mpc-node: keygen session holds a key")
+	// ErrKeyPurpose is returned when an OPRF key is used to sign, or a signing key to answer OPRF requests.
+	ErrKeyPurpose = errors.New("mpc-node: key is not generated for this purpose")
+	// ErrInvalidSigners is returned when the signers are not a valid subset of the key's parties,
+	// or when a retried sign request names different signers than the original one.
+	ErrInvalidSigners = errors.New("mpc-node: invalid signers")
+)
+
+// Session status values reported by Node.Status.
+const (
+	StatusStarting  = "starting"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusAborted   = "aborted"
+)
+
+type session struct {
+	kind  string
+	keyID string
+	// handler is nil while the first round is being computed.
+	handler *protocol.MultiHandler
+	pl      *pool.Pool
+	// progress is the last progress reported by a keygen.
+	progress *keygen.Progress
+	// signers and message are set for sign sessions.
+	signers party.IDSlice
+	message []byte
+	// parties are set for keygen sessions.
+	parties party.IDSlice
+	// record is the record stored for a completed sign session, with the labels of the request.
+	record *record.Record
+	labels record.Labels
+	// completion is run once the protocol of the session finished, by watch or by the first Status reporting it.
+	completion sync.Once
+	// result and recoveryID are the encoded result of a completed session.
+	result     string
+	recoveryID *byte
+	// handshakeSent is set once the handshake was added to the outbox.
+	handshakeSent bool
+	// confirmationSent is set once the transcript confirmation was added to the outbox.
+	confirmationSent bool
+	// lastActivity is the last time a message was delivered to the session.
+	lastActivity time.Time
+	// abortLogged is set once the full error of an aborted session was logged.
+	abortLogged bool
+	// outcomeRecorded is set once the completion, abort or timeout of the session was recorded in the reputation.
+	// Completions and aborts are recorded by watch, timeouts once reported.
+	outcomeRecorded bool
+	// lease locks the key used by the session until its protocol finished or the session is removed.
+	// A session which timed out keeps it, since it may still resume.
+	lease *keystore.Lease
+	// stale is set when the key of a completed sign session was replaced meanwhile, and its result discarded.
+	stale bool
+	// await is set for the sessions of a ceremony started once its secp256k1 keygen completed,
+	// and returns their StartFunc given the config it produced.
+	await func(secp *cmp.Config) protocol.StartFunc
+}
+
+// participants returns the parties of the session, including this node.
+func (s *session) participants() party.IDSlice {
+	if s.kind == keystore.CeremonySign {
+		return s.signers
+	}
+	return s.parties
+}
+
+func (s *session) running() bool {
+	if s.handler == nil {
+		return true
+	}
+	_, err := s.handler.Result()
+	return err != nil && !errors.As(err, new(protocol.Error))
+}
+
+// Node holds the key material and running sessions of a single party.
+type Node struct {
+	self  party.ID
+	mpc   *cmp.MPC
+	frost *frost.FROST
+	pl    *pool.Pool
+
+	limits Limits
+	// errorDetail is the level of detail of the errors returned over the control API.
+	errorDetail protocol.DetailLevel
+	// build is announced in the handshake of each session, and buildPolicy applied to the other parties' builds.
+	build       protocol.BuildInfo
+	buildPolicy protocol.BuildPolicy
+	// sessionPool is used by new sessions, and retired pools are still used by running sessions.
+	sessionPool *pool.Pool
+	retired     []*pool.Pool
+	// health is fed by the messages and heartbeats delivered to the sessions, and used by strategy
+	// to select the signers of a sign request which names none.
+	health     *selection.Tracker
+	strategy   selection.Strategy
+	reputation *selection.Reputation
+
+	keys map[string]party.IDSlice
+	// oprfKeys are the keys created by CreateOPRFKey, which only answer OPRF requests.
+	oprfKeys map[string]bool
+	// locks prevent a keygen from replacing the shares of a key while a sign uses them.
+	locks *keystore.KeyLocks
+	// labels maps the ID of a key to the labels set by LabelKey.
+	labels   map[string]record.Labels
+	sessions map[string]*session
+	// ceremonies maps the ID of a keygen ceremony to its curves.
+	ceremonies map[string][]string
+	records    record.RecordStore
+	// dedup maps a sign request's dedupKey to the session which serves it.
+	dedup map[signRequestKey]signRequest
+	// approval is the policy sign requests must satisfy, if not nil.
+	approval *approval.Policy
+	// backupRecipient encrypts the shares exported by ExportBackup, which is disabled if nil.
+	backupRecipient backup.Recipient
+	// vrfNonces maps the VRF evaluations committed by CommitVRF to their nonces.
+	vrfNonces map[vrfEvaluation]*vrf.Nonces
+	// beacons are the beacon rounds published with PublishBeacon.
+	beacons map[beaconRound]*beacon.Beacon
+	// closed stops the reaping of the retired pools once the node is closed.
+	closed    chan struct{}
+	closeOnce sync.Once
+	mtx       sync.Mutex
+}
+
+// signRequestKey identifies retries of the same sign request.
+type signRequestKey struct {
+	keyID    string
+	context  string
+	message  string
+	dedupKey string
+}
+
+// signRequest is the session serving a sign request, bound to the signers it was started with.
+type signRequest struct {
+	signID  string
+	signers party.IDSlice
+}
+
+// NewNode returns a Node with in-memory storage, and the given initial limits.
+func NewNode(self party.ID, limits Limits) (*Node, error) {
+	if err := limits.validate(); err != nil {
+		return nil, err
+	}
+	pl := pool.NewPool(limits.Workers)
+	mpc := cmp.NewMPC(
+		&keystore.InmemoryKeystoreFactory{},
+		&keyopts.InMemoryKeyOptsFactory{},
+		&vault.InmemoryVaultFactory{},
+		&vault.InmemoryVaultFactory{},
+		config.NewInMemoryConfigStore(),
+		config.NewInMemoryConfigStore(),
+		state.NewInMemoryStateStore(),
+		state.NewInMemoryStateStore(),
+		message.NewInMemoryMessageStore(),
+		message.NewInMemoryMessageStore(),
+		pl,
+	)
+	fr := frost.NewFROST(
+		&keystore.InmemoryKeystoreFactory{},
+		&keyopts.InMemoryKeyOptsFactory{},
+		&vault.InmemoryVaultFactory{},
+		config.NewInMemoryConfigStore(),
+		config.NewInMemoryConfigStore(),
+		state.NewInMemoryStateStore(),
+		state.NewInMemoryStateStore(),
+		message.NewInMemoryMessageStore(),
+		message.NewInMemoryMessageStore(),
+		pl,
+	)
+	n := &Node{
+		self:        self,
+		mpc:         mpc,
+		frost:       fr,
+		pl:          pl,
+		limits:      limits,
+		build:       protocol.CurrentBuild(),
+		sessionPool: pl,
+		health:      selection.NewTracker(),
+		strategy:    selection.Reliable(selection.LatencyAware(0), selection.DefaultMaxFailureRate),
+		reputation:  selection.NewReputation(selection.NewInMemoryReputationStore()),
+		keys:        map[string]party.IDSlice{},
+		oprfKeys:    map[string]bool{},
+		locks:       keystore.NewKeyLocks(),
+		labels:      map[string]record.Labels{},
+		sessions:    map[string]*session{},
+		ceremonies:  map[string][]string{},
+		records:     mpc_record.NewInMemoryRecordStore(),
+		dedup:       map[signRequestKey]signRequest{},
+		vrfNonces:   map[vrfEvaluation]*vrf.Nonces{},
+		beacons:     map[beaconRound]*beacon.Beacon{},
+		closed:      make(chan struct{}),
+	}
+	go n.reapEvery(reapInterval)
+	return n, nil
+}
+
+// Close tears down the worker pools of the node.
+func (n *Node) Close() {
+	n.closeOnce.Do(func() { close(n.closed) })
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	for _, pl := range n.retired {
+		pl.TearDown()
+	}
+	n.retired = nil
+	if n.sessionPool != n.pl {
+		n.sessionPool.TearDown()
+	}
+	n.pl.TearDown()
+}
+
+// CreateKey starts a keygen session with ID keyID, for a secp256k1 key.
+func (n *Node) CreateKey(keyID string, threshold int, parties []party.ID) error {
+	return n.CreateKeys(keyID, threshold, parties, []string{CurveSecp256k1})
+}
+
+// StartSign starts a sign session with ID signID, for the message hash msg using the key keyID.
+//
+// If dedupKey is not empty, retries of the same request (keyID, msg, dedupKey) do not start a new session.
+// Instead, the ID of the session started by the first request is returned, whether it is still running or completed.
+// Otherwise, signID is returned.
+//
+// The signers must include this node, and be parties of the key. A retry naming different signers is refused with
+// ErrInvalidSigners, so that overlapping quorums signing the same message are not confused with each other.
+//
+// If no signers are given, threshold+1 signers are selected by the node's selection strategy, and a retry
+// returns the original session whichever signers it was started with. The other signers must then be
+// started with the selected signers, as reported by Status.
+//
+// keystore.ErrKeyBusy is returned while another ceremony replaces the shares of the key.
+func (n *Node) StartSign(signID, keyID string, parties []party.ID, msg []byte, dedupKey string) (string, error) {
+	return n.StartSignWithContext(signID, keyID, "", parties, msg, dedupKey)
+}
+
+// StartSignWithContext is StartSign for an application identified by its context string, such as "payments/v1".
+// The context is bound to the session and its transcript but not to the signed message, so that the sessions of
+// applications sharing a key cannot be replayed across each other: all signers must be given the same context.
+// An empty context is the same as StartSign.
+func (n *Node) StartSignWithContext(signID, keyID, context string, parties []party.ID, msg []byte, dedupKey string) (string, error) {
+	return n.StartSignRequest(SignRequest{
+		SignID:   signID,
+		KeyID:    keyID,
+		Parties:  parties,
+		Message:  msg,
+		DedupKey: dedupKey,
+		Context:  context,
+	})
+}
+
+// StartSignRequest is StartSignWithContext for the fields of r, whose labels are stored with the record
+// of the signature.
+func (n *Node) StartSignRequest(r SignRequest) (string, error) {
+	if err := r.Labels.Validate(); err != nil {
+		return "", err
+	}
+	req := signRequestKey{keyID: r.KeyID, context: r.Context, message: string(r.Message), dedupKey: r.DedupKey}
+	parties := r.Parties
+	auto := len(parties) == 0
+	if auto {
+		selected, err := n.selectSigners(r.KeyID)
+		if err != nil {
+			return "", err
+		}
+		parties = selected
+	}
+	signers := party.NewIDSlice(parties)
+	n.mtx.Lock()
+	keyParties, ok := n.keys[r.KeyID]
+	if !ok {
+		n.mtx.Unlock()
+		return "", ErrUnknownKey
+	}
+	if n.oprfKeys[r.KeyID] {
+		n.mtx.Unlock()
+		return "", fmt.Errorf("%w: %s is an OPRF key", ErrKeyPurpose, r.KeyID)
+	}
+	if !signers.Valid() || !signers.Contains(n.self) || !keyParties.Contains(signers...) {
+		n.mtx.Unlock()
+		return "", fmt.Errorf("%w: %v is not a subset of %v including %s", ErrInvalidSigners, signers, keyParties, n.self)
+	}
+	if r.DedupKey != "" {
+		if existing, ok := n.dedup[req]; ok {
+			n.mtx.Unlock()
+			if !auto && !signersEqual(existing.signers, signers) {
+				return "", fmt.Errorf("%w: request was started with signers %v", ErrInvalidSigners, existing.signers)
+			}
+			return existing.signID, nil
+		}
+		// reserve the request, so that concurrent retries do not start another session
+		n.dedup[req] = signRequest{signID: r.SignID, signers: signers}
+	}
+	approvalPolicy := n.approval
+	n.mtx.Unlock()
+
+	if approvalPolicy != nil {
+		if err := approvalPolicy.Verify(r.approvalRequest(), r.Approvals); err != nil {
+			if r.DedupKey != "" {
+				n.mtx.Lock()
+				delete(n.dedup, req)
+				n.mtx.Unlock()
+			}
+			return "", err
+		}
+	}
+
+	cfg := config.NewSignConfig(r.SignID, r.KeyID, curve.Secp256k1{}, len(signers)-1, n.self, signers, r.Message)
+	if r.Context != "" {
+		cfg.SetContext(r.Context)
+	}
+	if !r.ReleaseTime.IsZero() {
+		cfg.SetReleaseTime(r.ReleaseTime)
+	}
+	sess := &session{kind: keystore.CeremonySign, keyID: r.KeyID, signers: signers, message: r.Message, labels: r.Labels.Clone()}
+	if err := n.start(r.SignID, sess, func(s *session) protocol.StartFunc { return n.mpc.Sign(cfg, s.pl) }); err != nil {
+		if r.DedupKey != "" {
+			n.mtx.Lock()
+			delete(n.dedup, req)
+			n.mtx.Unlock()
+		}
+		return "", err
+	}
+	return r.SignID, nil
+}
+
+// WithApproval requires sign requests to be approved by the operators of policy with their security keys.
+// Retries of a request with a dedupKey return the original session without further approval.
+func (n *Node) WithApproval(policy *approval.Policy) *Node {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.approval = policy
+	return n
+}
+
+// approvalRequest returns the part of r approved by the operators: every field but the approvals.
+func (r SignRequest) approvalRequest() approval.Request {
+	return approval.Request{
+		KeyID:       r.KeyID,
+		SignID:      r.SignID,
+		Message:     r.Message,
+		Context:     r.Context,
+		Parties:     r.Parties,
+		ReleaseTime: r.ReleaseTime,
+		DedupKey:    r.DedupKey,
+		Labels:      r.Labels,
+	}
+}
+
+// WithSelection sets the strategy selecting the signers of sign requests which name none.
+// The default strategy selects the parties with the lowest latency, among those whose failure rate is at most
+// selection.DefaultMaxFailureRate if there are enough of them.
+func (n *Node) WithSelection(strategy selection.Strategy) *Node {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.strategy = strategy
+	return n
+}
+
+// WithReputation sets the store in which the outcome of each session is recorded, blaming the parties
+// which made it fail. The statistics are given to the selection strategy, and reported by Reputation.
+// The default store is in memory.
+func (n *Node) WithReputation(store selection.ReputationStore) *Node {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.reputation = selection.NewReputation(store)
+	return n
+}
+
+// Reputation returns the statistics of the parties, over the sessions whose outcome was reported by Status.
+func (n *Node) Reputation() (map[party.ID]selection.Stats, error) {
+	n.mtx.Lock()
+	reputation := n.reputation
+	n.mtx.Unlock()
+	return reputation.Stats()
+}
+
+// ObserveLatency records a round trip time to a party measured by the transport, used to select signers.
+func (n *Node) ObserveLatency(id party.ID, rtt time.Duration) {
+	n.health.ObserveLatency(id, rtt)
+}
+
+// selectSigners returns threshold+1 parties of the key, including this node, selected by the node's strategy.
+// The load of each party is the number of running sign sessions it takes part in.
+func (n *Node) selectSigners(keyID string) (party.IDSlice, error) {
+	c, err := n.config(keyID)
+	if err != nil {
+		return nil, err
+	}
+	n.mtx.Lock()
+	load := map[party.ID]int{}
+	for _, s := range n.sessions {
+		if s.kind == keystore.CeremonySign && s.running() {
+			for _, id := range s.signers {
+				load[id]++
+			}
+		}
+	}
+	strategy, reputation := n.strategy, n.reputation
+	n.mtx.Unlock()
+	n.health.SetLoad(load)
+
+	candidates, err := reputation.Annotate(n.health.Candidates(c.PartyIDs()))
+	if err != nil {
+		return nil, err
+	}
+	signers, err := strategy.Select(n.self, candidates, c.Threshold+1)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSigners, err)
+	}
+	return signers, nil
+}
+
+func signersEqual(a, b party.IDSlice) bool {
+	return len(a) == len(b) && a.Contains(b...)
+}
+
+// start reserves the session before computing its first round without holding the lock,
+// so that its status can be queried meanwhile.
+//
+// The key of the session is locked first in the mode of its kind (see keystore.ModeOf): exclusively by a keygen,
+// which replaces its shares, and shared by a sign, so that ErrKeyBusy is returned instead of starting
+// a conflicting session.
+func (n *Node) start(id string, s *session, start func(s *session) protocol.StartFunc) error {
+	if err := n.reserve(id, s); err != nil {
+		return err
+	}
+	return n.run(id, s, start)
+}
+
+// reserve registers the session id and locks its key, without starting its protocol.
+// Until run starts it, the session is reported as starting.
+func (n *Node) reserve(id string, s *session) error {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	if _, ok := n.sessions[id]; ok {
+		return ErrSessionExists
+	}
+	// the shares of a keygen session are stored under the ID of the session, which differs from the
+	// ID of the ceremony for curves other than secp256k1
+	keyID := s.keyID
+	if s.kind == keystore.CeremonyKeygen {
+		keyID = id
+	}
+	lease, err := n.locks.Lock(keyID, keystore.ModeOf(s.kind))
+	if err != nil {
+		return err
+	}
+	s.pl, s.lastActivity, s.lease = n.sessionPool, time.Now(), lease
+	n.sessions[id] = s
+	return nil
+}
+
+// run starts the protocol of the session id reserved by reserve.
+// If its first round fails, the session is forgotten and its key released.
+func (n *Node) run(id string, s *session, start func(s *session) protocol.StartFunc) error {
+	n.mtx.Lock()
+	detail := n.errorDetail
+	n.mtx.Unlock()
+	h, err := protocol.NewMultiHandler(start(s), []byte(id), protocol.WithPeerErrorDetail(detail))
+
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	if err != nil {
+		s.lease.Release()
+		delete(n.sessions, id)
+		return err
+	}
+	if n.buildPolicy != nil {
+		h.SetBuildPolicy(n.buildPolicy)
+	}
+	h.SetMemoryBudget(n.limits.SessionMemory)
+	s.handler = h
+	s.lastActivity = time.Now()
+	go n.watch(id, s, h)
+	return nil
+}
+
+// watch completes the session once its protocol finished, and records its outcome in the reputation,
+// whether or not its status is queried.
+func (n *Node) watch(id string, s *session, h *protocol.MultiHandler) {
+	<-h.Done()
+	n.complete(id, s, h)
+	status := StatusCompleted
+	_, err := h.Result()
+	n.mtx.Lock()
+	if s.stale {
+		err = ErrStaleKey
+	}
+	n.mtx.Unlock()
+	if err != nil {
+		status = StatusAborted
+	}
+	n.recordOutcome(id, s, status, h.LastSeen(), err)
+}
+
+// complete releases the key of a session whose protocol finished. If it completed, complete encodes its result,
+// registers the key of a keygen and stores the record of a sign, unless the key was replaced meanwhile.
+// It runs once per session.
+func (n *Node) complete(id string, s *session, h *protocol.MultiHandler) {
+	s.completion.Do(func() {
+		defer s.lease.Release()
+		result, err := h.Result()
+		if err != nil {
+			return
+		}
+		if s.lease.Mode() == keystore.Shared && s.lease.Stale() {
+			log.Printf("mpc-node: session %s: %v", id, ErrStaleKey)
+			n.mtx.Lock()
+			s.stale = true
+			n.mtx.Unlock()
+			return
+		}
+		encoded, err := encodeResult(result)
+		if err != nil {
+			log.Printf("mpc-node: session %s: %v", id, err)
+			return
+		}
+		var recoveryID *byte
+		switch r := result.(type) {
+		case *cmp.Config:
+			n.mtx.Lock()
+			n.keys[s.keyID] = r.PartyIDs()
+			n.mtx.Unlock()
+			if s.kind == keystore.CeremonyKeygen {
+				go n.continueCeremony(s.keyID, r)
+			}
+		case *ecdsa.Signature:
+			// computed after encodeResult, which normalizes the signature to a low S
+			recID, err := r.RecoveryID()
+			if err != nil {
+				log.Printf("mpc-node: session %s: %v", id, err)
+				return
+			}
+			recoveryID = &recID
+			if err := n.recordSignature(id, s, r); err != nil {
+				log.Printf("mpc-node: session %s: %v", id, err)
+			}
+		}
+		n.mtx.Lock()
+		s.result, s.recoveryID = encoded, recoveryID
+		n.mtx.Unlock()
+		if err := n.recordTranscript(s, h); err != nil {
+			log.Printf("mpc-node: session %s: %v", id, err)
+		}
+	})
+}
+
+// RemoveSession stops the session id if it is running, releases its key and forgets it,
+// so that a session which timed out no longer blocks the ceremonies replacing its key.
+// A keygen session is removed with the other sessions of its ceremony, unless it completed.
+func (n *Node) RemoveSession(id string) error {
+	s, err := n.session(id)
+	if err != nil {
+		return err
+	}
+	if s.kind == keystore.CeremonyKeygen {
+		n.mtx.Lock()
+		curves := n.ceremonies[s.keyID]
+		_, generated := n.keys[s.keyID]
+		n.mtx.Unlock()
+		if generated || (s.handler != nil && !s.running()) {
+			return ErrKeygenCompleted
+		}
+		n.discardCeremony(s.keyID, curves)
+		return nil
+	}
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	if s.handler != nil {
+		s.handler.Stop()
+	}
+	s.lease.Release()
+	delete(n.sessions, id)
+	for req, r := range n.dedup {
+		if r.signID == id {
+			delete(n.dedup, req)
+		}
+	}
+	return nil
+}
+
+// Deliver passes a message received from another node to the session.
+func (n *Node) Deliver(id string, msg *protocol.Message) error {
+	s, h, err := n.started(id)
+	if err != nil {
+		return err
+	}
+	limits := n.Limits()
+	if limits.MaxMessageSize > 0 && len(msg.Data) > limits.MaxMessageSize {
+		return fmt.Errorf("%w: %d bytes", ErrMessageTooLarge, len(msg.Data))
+	}
+	now := time.Now()
+	n.mtx.Lock()
+	s.lastActivity = now
+	n.mtx.Unlock()
+	n.health.Observe(map[party.ID]time.Time{msg.From: now})
+	if msg.IsTranscriptConfirmation() {
+		if err := h.ConfirmTranscript(msg); err != nil {
+			return err
+		}
+		n.complete(id, s, h)
+		return n.recordTranscript(s, h)
+	}
+	if !h.CanAccept(msg) {
+		return fmt.Errorf("mpc-node: session %s cannot accept %s", id, msg)
+	}
+	h.Accept(msg)
+	return nil
+}
+
+// Outbox returns the messages produced by the session since the last call.
+// The first outbox of a session starts with the handshake announcing our build.
+// While the session is running, an empty outbox contains a heartbeat instead, so that the other nodes
+// know we are alive during long rounds. Once the session completed, it also contains the confirmation
+// of our transcript hash.
+func (n *Node) Outbox(id string) ([]*protocol.Message, error) {
+	s, h, err := n.started(id)
+	if errors.Is(err, ErrSessionStarting) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	msgs, running := protocol.DrainMessages(h)
+	if running && len(msgs) == 0 {
+		msgs = append(msgs, h.Heartbeat())
+	}
+
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	if !s.handshakeSent {
+		handshake, err := h.Handshake(n.build)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append([]*protocol.Message{handshake}, msgs...)
+		s.handshakeSent = true
+	}
+	if !s.confirmationSent {
+		if confirmation, err := h.TranscriptConfirmation(); err == nil {
+			msgs = append(msgs, confirmation)
+			s.confirmationSent = true
+		}
+	}
+	return msgs, nil
+}
+
+// SessionStatus describes the state of a session.
+type SessionStatus struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"`
+	KeyID  string `json:"keyId"`
+	Status string `json:"status"`
+	// Signers are the signers of a sign session, including the ones selected by the node.
+	Signers party.IDSlice `json:"signers,omitempty"`
+	// Result is the hex encoded public key or signature, once completed.
+	Result string `json:"result,omitempty"`
+	// RecoveryID is the recovery ID of a completed ECDSA signature, with which its public key can be recovered.
+	RecoveryID *byte `json:"recoveryId,omitempty"`
+	// LastSeen is the time at which each other party last sent a message or heartbeat.
+	LastSeen map[party.ID]time.Time `json:"lastSeen,omitempty"`
+	// Transcript is the hex encoded transcript hash, once confirmed by all parties.
+	Transcript string `json:"transcript,omitempty"`
+	// Builds are the builds announced by the parties, including this node.
+	Builds map[party.ID]protocol.BuildInfo `json:"builds,omitempty"`
+	// Memory is the number of bytes retained by the session, counted against Limits.SessionMemory.
+	Memory int `json:"memory,omitempty"`
+	// Progress is the last progress reported by a keygen.
+	Progress *keygen.Progress `json:"progress,omitempty"`
+	// Code is the reason of an abort, and Error its description at the node's level of detail.
+	Code  protocol.ErrorCode `json:"code,omitempty"`
+	Error string             `json:"error,omitempty"`
+}
+
+// WithBuild sets the attestation announced with the node's build, and the minimum version of this library
+// the other parties must run, if not empty. It applies to the sessions started afterwards.
+func (n *Node) WithBuild(attestation []byte, minVersion string) *Node {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.build.Attestation = attestation
+	n.buildPolicy = nil
+	if minVersion != "" {
+		n.buildPolicy = protocol.MinimumVersion(minVersion)
+	}
+	return n
+}
+
+// WithErrorDetail sets the level of detail of the errors returned over the control API,
+// and sent to the other parties by the sessions started afterwards.
+// Full errors are always written to the local log.
+func (n *Node) WithErrorDetail(level protocol.DetailLevel) *Node {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.errorDetail = level
+	return n
+}
+
+// requestErrors describe what is wrong with a request rather than the state of the node,
+// and are returned over the control API whatever its level of detail.
+var requestErrors = []error{
+	ErrSessionExists, ErrUnknownSession, ErrSessionStarting, ErrUnknownKey, ErrKeyPurpose, ErrInvalidSigners,
+	keystore.ErrKeyBusy, record.ErrInvalidLabels,
+	approval.ErrNotApproved, approval.ErrInvalidAssertion, approval.ErrUnknownCredential,
+}
+
+// redact returns the description of err returned over the control API. Unless it is one of requestErrors,
+// it is redacted to the node's level of detail, and written in full to the local log.
+func (n *Node) redact(err error) string {
+	for _, target := range requestErrors {
+		if errors.Is(err, target) {
+			return err.Error()
+		}
+	}
+	n.mtx.Lock()
+	level := n.errorDetail
+	n.mtx.Unlock()
+	if level != protocol.DetailFull {
+		log.Printf("mpc-node: %v", err)
+	}
+	return protocol.Redact(err, level)
+}
+
+// Status returns the status of the session.
+func (n *Node) Status(id string) (*SessionStatus, error) {
+	s, err := n.session(id)
+	if err != nil {
+		return nil, err
+	}
+	n.mtx.Lock()
+	h, progress := s.handler, s.progress
+	n.mtx.Unlock()
+	status := &SessionStatus{ID: id, Kind: s.kind, KeyID: s.keyID, Status: StatusStarting, Signers: s.signers, Progress: progress}
+	if h == nil {
+		return status, nil
+	}
+	status.Status = StatusRunning
+	status.LastSeen = h.LastSeen()
+	status.Builds = h.Builds()
+	status.Memory = h.MemoryUsage().Total()
+	_, err = h.Result()
+	switch {
+	case err == nil:
+		status.Status = StatusCompleted
+		n.complete(id, s, h)
+		n.mtx.Lock()
+		status.Result, status.RecoveryID = s.result, s.recoveryID
+		stale := s.stale
+		n.mtx.Unlock()
+		if stale {
+			status.Status, status.Result, status.Error = StatusAborted, "", ErrStaleKey.Error()
+			break
+		}
+		if transcript, err := h.ConfirmedTranscript(); err == nil {
+			status.Transcript = fmt.Sprintf("%x", transcript)
+		}
+	case errors.As(err, new(protocol.Error)):
+		n.complete(id, s, h)
+		status.Status = StatusAborted
+		status.Code = protocol.Code(err)
+		n.mtx.Lock()
+		status.Error = protocol.Redact(err, n.errorDetail)
+		if !s.abortLogged {
+			s.abortLogged = true
+			log.Printf("mpc-node: session %s aborted: %v", id, err)
+		}
+		n.mtx.Unlock()
+	default:
+		n.mtx.Lock()
+		timeout, last := n.limits.roundTimeout(s.participants()), s.lastActivity
+		n.mtx.Unlock()
+		if timeout > 0 && time.Since(last) > timeout {
+			status.Status = StatusTimedOut
+			n.recordOutcome(id, s, status.Status, status.LastSeen, err)
+		}
+	}
+	return status, nil
+}
+
+// recordOutcome records the first completion, abort or timeout of the session in the reputation.
+// A timeout is blamed on the parties which were not seen within the round timeout.
+func (n *Node) recordOutcome(id string, s *session, status string, lastSeen map[party.ID]time.Time, err error) {
+	n.mtx.Lock()
+	if s.outcomeRecorded {
+		n.mtx.Unlock()
+		return
+	}
+	s.outcomeRecorded = true
+	parties := s.participants()
+	reputation, timeout := n.reputation, n.limits.roundTimeout(parties)
+	n.mtx.Unlock()
+
+	switch status {
+	case StatusCompleted:
+		err = reputation.Completed(parties)
+	case StatusAborted:
+		err = reputation.Aborted(parties, err)
+	case StatusTimedOut:
+		var silent []party.ID
+		for _, id := range parties {
+			if seen, ok := lastSeen[id]; id != n.self && (!ok || time.Since(seen) > timeout) {
+				silent = append(silent, id)
+			}
+		}
+		err = reputation.TimedOut(parties, silent)
+	}
+	if err != nil {
+		log.Printf("mpc-node: session %s: %v", id, err)
+	}
+}
+
+// recordSignature stores the record of a completed sign session.
+func (n *Node) recordSignature(id string, s *session, sig *ecdsa.Signature) error {
+	data, err := sig.SigEthereum()
+	if err != nil {
+		return err
+	}
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	s.record = &record.Record{
+		SessionID:   id,
+		KeyID:       s.keyID,
+		MessageHash: s.message,
+		Signers:     s.signers,
+		Time:        time.Now(),
+		Signature:   data,
+		Labels:      s.labels,
+	}
+	if presig := n.mpc.Presignature(id); presig != nil {
+		s.record.PresignatureID = presig.ID
+	}
+	return n.records.Put(s.record)
+}
+
+// recordTranscript updates the record of a sign session once its transcript is confirmed.
+func (n *Node) recordTranscript(s *session, h *protocol.MultiHandler) error {
+	transcript, err := h.ConfirmedTranscript()
+	if err != nil {
+		return nil
+	}
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	if s.record == nil || s.record.Transcript != nil {
+		return nil
+	}
+	s.record.Transcript = transcript
+	return n.records.Put(s.record)
+}
+
+// Records returns the records of the signatures produced by this node, selected by f.
+func (n *Node) Records(f record.Filter) ([]*record.Record, error) {
+	return n.records.Query(f)
+}
+
+// Keys returns the IDs of the keys generated by this node.
+func (n *Node) Keys() []string {
+	return n.FindKeys(nil)
+}
+
+// FindKeys returns the IDs of the keys generated by this node which have all labels of selector.
+func (n *Node) FindKeys(selector record.Labels) []string {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	ids := make([]string, 0, len(n.keys))
+	for id := range n.keys {
+		if n.labels[id].Match(selector) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// LabelKey replaces the labels of the key keyID, which may still be generated. Empty labels remove them.
+func (n *Node) LabelKey(keyID string, labels record.Labels) error {
+	if err := labels.Validate(); err != nil {
+		return err
+	}
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	_, generated := n.keys[keyID]
+	_, generating := n.ceremonies[keyID]
+	if !generated && !generating {
+		return ErrUnknownKey
+	}
+	if len(labels) == 0 {
+		delete(n.labels, keyID)
+		return nil
+	}
+	n.labels[keyID] = labels.Clone()
+	return nil
+}
+
+// KeyLabels returns the labels of the key keyID.
+func (n *Node) KeyLabels(keyID string) (record.Labels, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	_, generated := n.keys[keyID]
+	_, generating := n.ceremonies[keyID]
+	if !generated && !generating {
+		return nil, ErrUnknownKey
+	}
+	return n.labels[keyID].Clone(), nil
+}
+
+func (n *Node) session(id string) (*session, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	s, ok := n.sessions[id]
+	if !ok {
+		return nil, ErrUnknownSession
+	}
+	return s, nil
+}
+
+// started returns the session and its handler, or ErrSessionStarting if its first round is still being computed.
+func (n *Node) started(id string) (*session, *protocol.MultiHandler, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	s, ok := n.sessions[id]
+	if !ok {
+		return nil, nil, ErrUnknownSession
+	}
+	if s.handler == nil {
+		return nil, nil, ErrSessionStarting
+	}
+	return s, s.handler, nil
+}
+
+func encodeResult(result interface{}) (string, error) {
+	switch r := result.(type) {
+	case *cmp.Config:
+		data, err := r.PublicPoint().MarshalBinary()
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%x", data), nil
+	case *ecdsa.Signature:
+		data, err := r.SigEthereum()
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%x", data), nil
+	case *frost.Config:
+		return fmt.Sprintf("%x", r.PublicKey.Bytes()), nil
+	default:
+		return "", nil
+	}
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/pkg/keystore"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/record"
+	"github.com/mr-shifu/mpc-lib/pkg/oprf"
+	"github.com/stretchr/testify/require"
+)
+
+// newNodes returns a node for each of the parties.
+func newNodes(t *testing.T, ids party.IDSlice) map[party.ID]*Node {
+	t.Helper()
+	nodes := make(map[party.ID]*Node, len(ids))
+	for _, id := range ids {
+		n, err := NewNode(id, Limits{})
+		require.NoError(t, err)
+		t.Cleanup(n.Close)
+		nodes[id] = n
+	}
+	return nodes
+}
+
+// route routes the messages of the session id between the nodes, until done returns true.
+// It does not query the status of the session.
+func route(t *testing.T, nodes map[party.ID]*Node, id string, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Minute)
+	for !done() {
+		for self := range nodes {
+			deliver(t, nodes, self, id)
+		}
+		require.True(t, time.Now().Before(deadline), "session %s did not complete", id)
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// deliver delivers the outbox of the session id of the node self to the other nodes.
+func deliver(t *testing.T, nodes map[party.ID]*Node, self party.ID, id string) {
+	t.Helper()
+	msgs, err := nodes[self].Outbox(id)
+	require.NoError(t, err)
+	for _, msg := range msgs {
+		for other, n := range nodes {
+			if other != self && msg.IsFor(other) {
+				_ = n.Deliver(id, msg)
+			}
+		}
+	}
+}
+
+// run routes the messages of the session id between the nodes, until it completed on all of them.
+func run(t *testing.T, nodes map[party.ID]*Node, id string) map[party.ID]*SessionStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Minute)
+	for {
+		statuses := make(map[party.ID]*SessionStatus, len(nodes))
+		done := true
+		for self, n := range nodes {
+			deliver(t, nodes, self, id)
+			status, err := n.Status(id)
+			require.NoError(t, err)
+			require.NotEqual(t, StatusAborted, status.Status, status.Error)
+			statuses[self] = status
+			done = done && status.Status == StatusCompleted && status.Transcript != ""
+		}
+		if done {
+			return statuses
+		}
+		require.True(t, time.Now().Before(deadline), "session %s did not complete", id)
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestOPRFKeyPurpose(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	nodes := newNodes(t, ids)
+	for _, n := range nodes {
+		require.NoError(t, n.CreateOPRFKey("oprf", 1, ids))
+		require.NoError(t, n.CreateKey("sign", 1, ids))
+	}
+	run(t, nodes, "oprf")
+	run(t, nodes, "sign")
+
+	n := nodes["a"]
+	req, err := oprf.Blind(curve.Secp256k1{}, []byte("password"))
+	require.NoError(t, err)
+	blinded, err := req.Blinded.MarshalBinary()
+	require.NoError(t, err)
+	_, err = n.EvaluateOPRF("oprf", blinded)
+	require.NoError(t, err)
+	_, err = n.OPRFCommittee("oprf")
+	require.NoError(t, err)
+
+	_, err = n.EvaluateOPRF("sign", blinded)
+	require.ErrorIs(t, err, ErrKeyPurpose, "a signing key must not answer OPRF requests")
+	_, err = n.OPRFCommittee("sign")
+	require.ErrorIs(t, err, ErrKeyPurpose)
+
+	_, err = n.StartSign("s1", "oprf", ids, make([]byte, 32), "")
+	require.ErrorIs(t, err, ErrKeyPurpose, "an OPRF key must not sign")
+	_, err = n.StartSign("s2", "oprf", nil, make([]byte, 32), "")
+	require.ErrorIs(t, err, ErrKeyPurpose)
+	_, err = n.SigningCommittee("oprf")
+	require.ErrorIs(t, err, ErrKeyPurpose)
+	_, err = n.CommitVRF("oprf", "e1", []byte("input"))
+	require.ErrorIs(t, err, ErrKeyPurpose)
+
+	_, err = n.SigningCommittee("sign")
+	require.NoError(t, err)
+}
+
+func TestRecordSignatureWithoutStatus(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	nodes := newNodes(t, ids)
+	for _, n := range nodes {
+		require.NoError(t, n.CreateKey("key", 1, ids))
+	}
+	run(t, nodes, "key")
+
+	msg := make([]byte, 32)
+	for _, n := range nodes {
+		_, err := n.StartSign("sign", "key", ids, msg, "")
+		require.NoError(t, err)
+	}
+	// the records are stored once the signature is computed, and updated with the confirmed transcript,
+	// without the status of the session being queried
+	route(t, nodes, "sign", func() bool {
+		for _, n := range nodes {
+			records, err := n.Records(record.Filter{KeyID: "key"})
+			require.NoError(t, err)
+			if len(records) == 0 || records[0].Transcript == nil {
+				return false
+			}
+		}
+		return true
+	})
+	var presignatureID string
+	for _, n := range nodes {
+		records, err := n.Records(record.Filter{KeyID: "key"})
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		require.Equal(t, "sign", records[0].SessionID)
+		require.Equal(t, msg, records[0].MessageHash)
+		require.Len(t, records[0].Signature, 65)
+		// all signers record the same presignature
+		require.NotEmpty(t, records[0].PresignatureID)
+		if presignatureID == "" {
+			presignatureID = records[0].PresignatureID
+		}
+		require.Equal(t, presignatureID, records[0].PresignatureID)
+	}
+}
+
+func TestRecordOutcomeWithoutStatus(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	nodes := newNodes(t, ids)
+	for _, n := range nodes {
+		require.NoError(t, n.CreateKey("key", 1, ids))
+	}
+	// the outcome is recorded once the keygen completes, without its status being queried
+	route(t, nodes, "key", func() bool {
+		for _, n := range nodes {
+			stats, err := n.Reputation()
+			require.NoError(t, err)
+			for _, id := range ids {
+				if stats[id].Sessions != 1 {
+					return false
+				}
+			}
+		}
+		return true
+	})
+	for _, n := range nodes {
+		stats, err := n.Reputation()
+		require.NoError(t, err)
+		for _, id := range ids {
+			require.Zero(t, stats[id].Incidents())
+		}
+	}
+}
+
+func TestSessionLease(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	nodes := newNodes(t, ids)
+	for _, n := range nodes {
+		require.NoError(t, n.CreateKey("key", 1, ids))
+	}
+	run(t, nodes, "key")
+	n := nodes["a"]
+	require.ErrorIs(t, n.RemoveSession("key"), ErrKeygenCompleted)
+
+	// a sign session which timed out keeps its lease until it is removed
+	require.NoError(t, n.Reconfigure(Limits{RoundTimeout: time.Millisecond}))
+	_, err := n.StartSign("timeout", "key", ids, make([]byte, 32), "")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		status, err := n.Status("timeout")
+		require.NoError(t, err)
+		return status.Status == StatusTimedOut
+	}, time.Minute, 10*time.Millisecond)
+	_, err = n.locks.Lock("key", keystore.Exclusive)
+	require.ErrorIs(t, err, keystore.ErrKeyBusy)
+	require.NoError(t, n.RemoveSession("timeout"))
+	_, err = n.Status("timeout")
+	require.ErrorIs(t, err, ErrUnknownSession)
+	lease, err := n.locks.Lock("key", keystore.Exclusive)
+	require.NoError(t, err)
+	lease.Release()
+	require.NoError(t, n.Reconfigure(Limits{}))
+
+	// the result of a sign session is discarded if the key was replaced while it did not hold its lease
+	msg := make([]byte, 32)
+	for _, m := range nodes {
+		_, err := m.StartSign("stale", "key", ids, msg, "")
+		require.NoError(t, err)
+	}
+	s, err := n.session("stale")
+	require.NoError(t, err)
+	s.lease.Release()
+	lease, err = n.locks.Lock("key", keystore.Exclusive)
+	require.NoError(t, err)
+	lease.Release()
+	route(t, nodes, "stale", func() bool {
+		status, err := nodes["b"].Status("stale")
+		require.NoError(t, err)
+		return status.Status == StatusCompleted
+	})
+	require.Eventually(t, func() bool {
+		status, err := n.Status("stale")
+		require.NoError(t, err)
+		return status.Status == StatusAborted
+	}, time.Minute, 10*time.Millisecond)
+	status, err := n.Status("stale")
+	require.NoError(t, err)
+	require.Equal(t, ErrStaleKey.Error(), status.Error)
+	require.Empty(t, status.Result)
+	records, err := n.Records(record.Filter{KeyID: "key"})
+	require.NoError(t, err)
+	require.Empty(t, records)
+}
+
+func TestSignDedup(t *testing.T) {
+	ids := party.IDSlice{"a", "b", "c"}
+	nodes := newNodes(t, ids)
+	for _, n := range nodes {
+		require.NoError(t, n.CreateKey("key", 1, ids))
+	}
+	run(t, nodes, "key")
+
+	signers := party.IDSlice{"a", "b"}
+	msg := make([]byte, 32)
+	for _, id := range signers {
+		signID, err := nodes[id].StartSign("s1", "key", signers, msg, "retry")
+		require.NoError(t, err)
+		require.Equal(t, "s1", signID)
+	}
+	signing := map[party.ID]*Node{"a": nodes["a"], "b": nodes["b"]}
+	run(t, signing, "s1")
+
+	// a retry returns the completed session instead of starting another one
+	n := nodes["a"]
+	signID, err := n.StartSign("s2", "key", signers, msg, "retry")
+	require.NoError(t, err)
+	require.Equal(t, "s1", signID)
+	_, err = n.Status("s2")
+	require.ErrorIs(t, err, ErrUnknownSession)
+	signID, err = n.StartSign("s2", "key", nil, msg, "retry")
+	require.NoError(t, err)
+	require.Equal(t, "s1", signID, "a retry letting the node select the signers returns the original session")
+	_, err = n.StartSign("s2", "key", party.IDSlice{"a", "c"}, msg, "retry")
+	require.ErrorIs(t, err, ErrInvalidSigners)
+
+	// another message, context or dedup key is another request
+	other := make([]byte, 32)
+	other[0] = 1
+	for want, start := range map[string]func(signID string) (string, error){
+		"message": func(signID string) (string, error) { return n.StartSign(signID, "key", signers, other, "retry") },
+		"context": func(signID string) (string, error) {
+			return n.StartSignWithContext(signID, "key", "payments/v1", signers, msg, "retry")
+		},
+		"dedup key":    func(signID string) (string, error) { return n.StartSign(signID, "key", signers, msg, "other") },
+		"no dedup key": func(signID string) (string, error) { return n.StartSign(signID, "key", signers, msg, "") },
+	} {
+		signID, err := start(want)
+		require.NoError(t, err, want)
+		require.Equal(t, want, signID)
+		require.NoError(t, n.RemoveSession(signID))
+	}
+
+	// once the session is removed, a retry starts a new one
+	require.NoError(t, n.RemoveSession("s1"))
+	signID, err = n.StartSign("s3", "key", signers, msg, "retry")
+	require.NoError(t, err)
+	require.Equal(t, "s3", signID)
+}
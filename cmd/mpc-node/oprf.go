@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/pkg/oprf"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp"
+)
+
+var ErrInvalidBlinded = errors.New("mpc-node: invalid blinded input")
+
+// Committee is the public material a client needs to verify evaluation shares, or the VRF combiner
+// and beacon verifiers the responses of the parties.
+type Committee struct {
+	KeyID     string `json:"keyId"`
+	Threshold int    `json:"threshold"`
+	// Shares maps each party to its hex encoded public key share.
+	Shares map[party.ID]string `json:"shares"`
+}
+
+// EvaluateOPRF returns the marshalled oprf.EvaluationShare of this node for the blinded input,
+// using the secp256k1 key of the ceremony keyID, which must have been created by CreateOPRFKey.
+func (n *Node) EvaluateOPRF(keyID string, blinded []byte) ([]byte, error) {
+	c, err := n.oprfConfig(keyID)
+	if err != nil {
+		return nil, err
+	}
+	B := c.Group.NewPoint()
+	if err := B.UnmarshalBinary(blinded); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBlinded, err)
+	}
+	share, err := oprf.Evaluate(n.self, c.ECDSA, B)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBlinded, err)
+	}
+	return cbor.Marshal(share)
+}
+
+// OPRFCommittee returns the public key shares of the OPRF key keyID.
+func (n *Node) OPRFCommittee(keyID string) (*Committee, error) {
+	c, err := n.oprfConfig(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return newCommittee(keyID, c)
+}
+
+// SigningCommittee returns the public key shares of the signing key keyID, used by VRF and beacon requests.
+func (n *Node) SigningCommittee(keyID string) (*Committee, error) {
+	c, err := n.config(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return newCommittee(keyID, c)
+}
+
+func newCommittee(keyID string, c *cmp.Config) (*Committee, error) {
+	committee := &Committee{KeyID: keyID, Threshold: c.Threshold, Shares: make(map[party.ID]string, len(c.Public))}
+	for j, public := range c.Public {
+		data, err := public.ECDSA.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		committee.Shares[j] = fmt.Sprintf("%x", data)
+	}
+	return committee, nil
+}
+
+// config returns the result of the completed secp256k1 keygen of the signing key keyID.
+func (n *Node) config(keyID string) (*cmp.Config, error) {
+	if n.isOPRFKey(keyID) {
+		return nil, fmt.Errorf("%w: %s is an OPRF key", ErrKeyPurpose, keyID)
+	}
+	return n.keyConfig(keyID)
+}
+
+// oprfConfig returns the result of the completed secp256k1 keygen of the OPRF key keyID.
+func (n *Node) oprfConfig(keyID string) (*cmp.Config, error) {
+	c, err := n.keyConfig(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if !n.isOPRFKey(keyID) {
+		return nil, fmt.Errorf("%w: %s is not an OPRF key", ErrKeyPurpose, keyID)
+	}
+	return c, nil
+}
+
+func (n *Node) isOPRFKey(keyID string) bool {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	return n.oprfKeys[keyID]
+}
+
+// keyConfig returns the result of the completed secp256k1 keygen of the ceremony keyID, whatever its purpose.
+func (n *Node) keyConfig(keyID string) (*cmp.Config, error) {
+	_, h, err := n.started(ceremonySessionID(keyID, CurveSecp256k1))
+	if errors.Is(err, ErrUnknownSession) || errors.Is(err, ErrSessionStarting) {
+		return nil, ErrUnknownKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	result, err := h.Result()
+	if err != nil {
+		return nil, ErrUnknownKey
+	}
+	c, ok := result.(*cmp.Config)
+	if !ok || c.Group.Name() != (curve.Secp256k1{}).Name() {
+		return nil, ErrUnknownKey
+	}
+	return c, nil
+}
@@ -0,0 +1,474 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/pkg/approval"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/record"
+)
+
+// maxRequestSize is the maximum size of the body of a request, well above the size of the largest
+// protocol message delivered by session.deliver.
+const maxRequestSize = 16 << 20
+
+// JSON-RPC 2.0 error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeServerError    = -32000
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// Purposes of the keys created by keys.create.
+const (
+	purposeSign = "sign"
+	purposeOPRF = "oprf"
+)
+
+type createKeyParams struct {
+	KeyID     string     `json:"keyId"`
+	Threshold int        `json:"threshold"`
+	Parties   []party.ID `json:"parties"`
+	// Curves are the curves of the keys generated by the ceremony, secp256k1 if empty.
+	Curves []string `json:"curves,omitempty"`
+	// Purpose is "oprf" for a key answering oprf.evaluate, which cannot sign. Keys sign by default.
+	Purpose string `json:"purpose,omitempty"`
+	// Labels are attached to the key by keys.create and keys.label, and select the keys listed by keys.list.
+	Labels record.Labels `json:"labels,omitempty"`
+}
+
+// KeyLabels are the labels of a key, as returned by keys.label and keys.labels.
+type KeyLabels struct {
+	KeyID  string        `json:"keyId"`
+	Labels record.Labels `json:"labels"`
+}
+
+type signParams struct {
+	SignID string `json:"signId"`
+	KeyID  string `json:"keyId"`
+	// Parties are the signers, selected by the node if empty.
+	Parties []party.ID `json:"parties,omitempty"`
+	// Message is the hash to be signed, as raw bytes (base64 in JSON).
+	Message []byte `json:"message"`
+	// DedupKey makes retries of the same request return the original session.
+	DedupKey string `json:"dedupKey,omitempty"`
+	// Context is the context string of the application requesting the signature, which all signers must be given.
+	Context string `json:"context,omitempty"`
+	// Labels are stored with the record of the signature, and select the records of records.query.
+	Labels record.Labels `json:"labels,omitempty"`
+	// ReleaseTime is the time before which the signers refuse to complete the signature, which all signers must be given.
+	ReleaseTime time.Time `json:"releaseTime"`
+	// Approvals are the WebAuthn assertions of the operators, if the node requires approval.
+	Approvals []approval.Assertion `json:"approvals,omitempty"`
+}
+
+// signRequest returns the request of the parameters of sign.start and sign.challenge.
+func (p *signParams) signRequest() SignRequest {
+	return SignRequest{
+		SignID:      p.SignID,
+		KeyID:       p.KeyID,
+		Parties:     p.Parties,
+		Message:     p.Message,
+		DedupKey:    p.DedupKey,
+		Context:     p.Context,
+		Labels:      p.Labels,
+		ReleaseTime: p.ReleaseTime,
+		Approvals:   p.Approvals,
+	}
+}
+
+// ApprovalChallenge is the result of sign.challenge.
+type ApprovalChallenge struct {
+	// Challenge is the WebAuthn challenge the operators sign to approve the request.
+	Challenge []byte `json:"challenge"`
+}
+
+type signBatchParams struct {
+	// Requests are the sessions started by sign.batch.
+	Requests []SignRequest `json:"requests,omitempty"`
+	// IDs are the sessions queried by sign.batchStatus.
+	IDs []string `json:"ids,omitempty"`
+}
+
+type oprfParams struct {
+	KeyID string `json:"keyId"`
+	// Blinded is the compressed point r⋅H₁(x) of the client, only used by oprf.evaluate.
+	Blinded []byte `json:"blinded,omitempty"`
+}
+
+type vrfParams struct {
+	KeyID        string `json:"keyId"`
+	EvaluationID string `json:"evaluationId"`
+	// Input is the VRF input, only used by vrf.commit.
+	Input []byte `json:"input,omitempty"`
+	// Commitments are the marshalled commitments of the quorum, only used by vrf.respond.
+	Commitments [][]byte `json:"commitments,omitempty"`
+}
+
+type beaconParams struct {
+	KeyID string `json:"keyId"`
+	Round uint64 `json:"round"`
+	// Commitments are the marshalled commitments of the quorum, used by beacon.respond and beacon.publish.
+	Commitments [][]byte `json:"commitments,omitempty"`
+	// Responses are the marshalled responses of the quorum, only used by beacon.publish.
+	Responses [][]byte `json:"responses,omitempty"`
+}
+
+type sessionParams struct {
+	ID string `json:"id"`
+	// Message is a marshalled protocol.Message, only used by session.deliver.
+	Message []byte `json:"message,omitempty"`
+}
+
+// NewServer returns an http.Handler serving the JSON-RPC API of node on /rpc.
+func NewServer(node *Node, apiKey string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rpc", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(apiKey)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		var req rpcRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				return
+			}
+			writeResponse(w, &rpcResponse{Error: &rpcError{codeParseError, err.Error()}})
+			return
+		}
+		if req.JSONRPC != "2.0" || req.Method == "" {
+			writeResponse(w, &rpcResponse{ID: req.ID, Error: &rpcError{codeInvalidRequest, "invalid request"}})
+			return
+		}
+		result, rpcErr := dispatch(node, req.Method, req.Params)
+		writeResponse(w, &rpcResponse{ID: req.ID, Result: result, Error: rpcErr})
+	})
+	return mux
+}
+
+func dispatch(node *Node, method string, params json.RawMessage) (interface{}, *rpcError) {
+	switch method {
+	case "keys.create":
+		var p createKeyParams
+		if err := json.Unmarshal(params, &p); err != nil || p.KeyID == "" {
+			return nil, &rpcError{codeInvalidParams, "expected keyId, threshold and parties"}
+		}
+		if err := p.Labels.Validate(); err != nil {
+			return nil, &rpcError{codeInvalidParams, err.Error()}
+		}
+		switch p.Purpose {
+		case "", purposeSign:
+		case purposeOPRF:
+			if len(p.Curves) > 1 || (len(p.Curves) == 1 && p.Curves[0] != CurveSecp256k1) {
+				return nil, &rpcError{codeInvalidParams, "OPRF keys are only generated over secp256k1"}
+			}
+		default:
+			return nil, &rpcError{codeInvalidParams, fmt.Sprintf("unknown purpose %q", p.Purpose)}
+		}
+		if p.Purpose == purposeOPRF {
+			if err := node.CreateOPRFKey(p.KeyID, p.Threshold, p.Parties); err != nil {
+				return nil, serverError(err)
+			}
+			if err := node.LabelKey(p.KeyID, p.Labels); err != nil {
+				return nil, serverError(err)
+			}
+			return status(node, p.KeyID)
+		}
+		if len(p.Curves) == 0 {
+			if err := node.CreateKey(p.KeyID, p.Threshold, p.Parties); err != nil {
+				return nil, serverError(err)
+			}
+			if err := node.LabelKey(p.KeyID, p.Labels); err != nil {
+				return nil, serverError(err)
+			}
+			return status(node, p.KeyID)
+		}
+		if err := node.CreateKeys(p.KeyID, p.Threshold, p.Parties, p.Curves); err != nil {
+			return nil, serverError(err)
+		}
+		if err := node.LabelKey(p.KeyID, p.Labels); err != nil {
+			return nil, serverError(err)
+		}
+		return ceremonyStatus(node, p.KeyID)
+	case "keys.status":
+		var p createKeyParams
+		if err := json.Unmarshal(params, &p); err != nil || p.KeyID == "" {
+			return nil, &rpcError{codeInvalidParams, "expected keyId"}
+		}
+		return ceremonyStatus(node, p.KeyID)
+	case "keys.list":
+		var p createKeyParams
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, &rpcError{codeInvalidParams, "expected labels"}
+			}
+		}
+		return node.FindKeys(p.Labels), nil
+	case "keys.label", "keys.labels":
+		var p createKeyParams
+		if err := json.Unmarshal(params, &p); err != nil || p.KeyID == "" {
+			return nil, &rpcError{codeInvalidParams, "expected keyId"}
+		}
+		if method == "keys.label" {
+			if err := node.LabelKey(p.KeyID, p.Labels); err != nil {
+				return nil, serverError(err)
+			}
+		}
+		labels, err := node.KeyLabels(p.KeyID)
+		if err != nil {
+			return nil, serverError(err)
+		}
+		return &KeyLabels{KeyID: p.KeyID, Labels: labels}, nil
+	case "keys.backup":
+		var p createKeyParams
+		if err := json.Unmarshal(params, &p); err != nil || p.KeyID == "" {
+			return nil, &rpcError{codeInvalidParams, "expected keyId"}
+		}
+		b, err := node.ExportBackup(p.KeyID)
+		if err != nil {
+			return nil, serverError(err)
+		}
+		return b, nil
+	case "sign.start", "sign.challenge":
+		var p signParams
+		if err := json.Unmarshal(params, &p); err != nil || p.SignID == "" || p.KeyID == "" {
+			return nil, &rpcError{codeInvalidParams, "expected signId, keyId and message"}
+		}
+		if method == "sign.challenge" {
+			return &ApprovalChallenge{Challenge: p.signRequest().approvalRequest().Challenge()}, nil
+		}
+		signID, err := node.StartSignRequest(p.signRequest())
+		if err != nil {
+			return nil, serverError(err)
+		}
+		return status(node, signID)
+	case "sign.batch", "sign.batchStatus":
+		var p signBatchParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &rpcError{codeInvalidParams, "expected requests or ids"}
+		}
+		var items []BatchItem
+		var err error
+		if method == "sign.batch" {
+			items, err = node.StartSignBatch(p.Requests)
+		} else {
+			items, err = node.SignBatchStatus(p.IDs)
+		}
+		if err != nil {
+			return nil, serverError(err)
+		}
+		return items, nil
+	case "records.query", "records.export":
+		var f record.Filter
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &f); err != nil {
+				return nil, &rpcError{codeInvalidParams, "expected keyId, signer, from, to and labels"}
+			}
+		}
+		records, err := node.Records(f)
+		if err != nil {
+			return nil, serverError(err)
+		}
+		if method == "records.query" {
+			return records, nil
+		}
+		var out strings.Builder
+		if err := record.Export(&out, records); err != nil {
+			return nil, serverError(err)
+		}
+		return out.String(), nil
+	case "oprf.evaluate", "oprf.committee", "vrf.committee", "beacon.committee":
+		var p oprfParams
+		if err := json.Unmarshal(params, &p); err != nil || p.KeyID == "" {
+			return nil, &rpcError{codeInvalidParams, "expected keyId"}
+		}
+		switch method {
+		case "oprf.committee":
+			committee, err := node.OPRFCommittee(p.KeyID)
+			if err != nil {
+				return nil, serverError(err)
+			}
+			return committee, nil
+		case "vrf.committee", "beacon.committee":
+			// the VRF combiner and beacon verifiers need the public key shares of the signing key
+			committee, err := node.SigningCommittee(p.KeyID)
+			if err != nil {
+				return nil, serverError(err)
+			}
+			return committee, nil
+		}
+		share, err := node.EvaluateOPRF(p.KeyID, p.Blinded)
+		if err != nil {
+			return nil, serverError(err)
+		}
+		return share, nil
+	case "vrf.commit", "vrf.respond":
+		var p vrfParams
+		if err := json.Unmarshal(params, &p); err != nil || p.KeyID == "" || p.EvaluationID == "" {
+			return nil, &rpcError{codeInvalidParams, "expected keyId and evaluationId"}
+		}
+		var out []byte
+		var err error
+		if method == "vrf.commit" {
+			out, err = node.CommitVRF(p.KeyID, p.EvaluationID, p.Input)
+		} else {
+			out, err = node.RespondVRF(p.KeyID, p.EvaluationID, p.Commitments)
+		}
+		if err != nil {
+			return nil, serverError(err)
+		}
+		return out, nil
+	case "beacon.commit", "beacon.respond", "beacon.publish", "beacon.get":
+		var p beaconParams
+		if err := json.Unmarshal(params, &p); err != nil || p.KeyID == "" {
+			return nil, &rpcError{codeInvalidParams, "expected keyId and round"}
+		}
+		var out []byte
+		var err error
+		switch method {
+		case "beacon.commit":
+			out, err = node.CommitBeacon(p.KeyID, p.Round)
+		case "beacon.respond":
+			out, err = node.RespondBeacon(p.KeyID, p.Round, p.Commitments)
+		case "beacon.publish":
+			out, err = node.PublishBeacon(p.KeyID, p.Round, p.Commitments, p.Responses)
+		default:
+			out, err = node.Beacon(p.KeyID, p.Round)
+		}
+		if err != nil {
+			return nil, serverError(err)
+		}
+		return out, nil
+	case "reputation.get":
+		stats, err := node.Reputation()
+		if err != nil {
+			return nil, serverError(err)
+		}
+		return stats, nil
+	case "node.capabilities":
+		return mpc.Capabilities(), nil
+	case "node.limits":
+		return node.Limits(), nil
+	case "node.reconfigure":
+		var l Limits
+		if err := json.Unmarshal(params, &l); err != nil {
+			return nil, &rpcError{codeInvalidParams, "expected workers, roundTimeout and maxMessageSize"}
+		}
+		if err := node.Reconfigure(l); err != nil {
+			return nil, &rpcError{codeInvalidParams, err.Error()}
+		}
+		return node.Limits(), nil
+	case "session.status", "session.outbox", "session.deliver", "session.remove":
+		var p sessionParams
+		if err := json.Unmarshal(params, &p); err != nil || p.ID == "" {
+			return nil, &rpcError{codeInvalidParams, "expected id"}
+		}
+		return sessionMethod(node, method, &p)
+	default:
+		return nil, &rpcError{codeMethodNotFound, "unknown method " + method}
+	}
+}
+
+func sessionMethod(node *Node, method string, p *sessionParams) (interface{}, *rpcError) {
+	switch method {
+	case "session.outbox":
+		msgs, err := node.Outbox(p.ID)
+		if err != nil {
+			return nil, serverError(err)
+		}
+		out := make([][]byte, 0, len(msgs))
+		for _, msg := range msgs {
+			data, err := msg.MarshalBinary()
+			if err != nil {
+				return nil, serverError(err)
+			}
+			out = append(out, data)
+		}
+		return out, nil
+	case "session.deliver":
+		msg := &protocol.Message{}
+		if err := msg.UnmarshalBinary(p.Message); err != nil {
+			return nil, &rpcError{codeInvalidParams, err.Error()}
+		}
+		if err := node.Deliver(p.ID, msg); err != nil {
+			return nil, serverError(err)
+		}
+	case "session.remove":
+		if err := node.RemoveSession(p.ID); err != nil {
+			return nil, serverError(err)
+		}
+		return true, nil
+	}
+	return status(node, p.ID)
+}
+
+func status(node *Node, id string) (interface{}, *rpcError) {
+	s, err := node.Status(id)
+	if err != nil {
+		return nil, serverError(err)
+	}
+	return s, nil
+}
+
+func ceremonyStatus(node *Node, keyID string) (interface{}, *rpcError) {
+	s, err := node.CeremonyStatus(keyID)
+	if err != nil {
+		return nil, serverError(err)
+	}
+	return s, nil
+}
+
+func serverError(err error) *rpcError {
+	if errors.Is(err, ErrUnknownSession) || errors.Is(err, ErrUnknownKey) || errors.Is(err, ErrInvalidSigners) ||
+		errors.Is(err, ErrUnknownCurve) || errors.Is(err, ErrInvalidBlinded) || errors.Is(err, ErrEmptyBatch) || errors.Is(err, ErrBatchTooLarge) ||
+		errors.Is(err, record.ErrInvalidLabels) || errors.Is(err, ErrUnknownEvaluation) || errors.Is(err, ErrDuplicateEvaluation) ||
+		errors.Is(err, ErrInvalidCommitments) || errors.Is(err, ErrInvalidResponses) || errors.Is(err, ErrUnknownBeacon) ||
+		errors.Is(err, approval.ErrNotApproved) || errors.Is(err, ErrKeyPurpose) || errors.Is(err, ErrKeygenCompleted) ||
+		errors.Is(err, approval.ErrInvalidAssertion) || errors.Is(err, approval.ErrUnknownCredential) {
+		return &rpcError{codeInvalidParams, err.Error()}
+	}
+	return &rpcError{codeServerError, err.Error()}
+}
+
+func writeResponse(w http.ResponseWriter, resp *rpcResponse) {
+	resp.JSONRPC = "2.0"
+	_ = json.NewEncoder(w).Encode(resp)
+}
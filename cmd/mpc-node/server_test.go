@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/stretchr/testify/require"
+)
+
+const testAPIKey = "secret"
+
+type testResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result"`
+	Error   *rpcError       `json:"error"`
+}
+
+// post sends body to the /rpc endpoint of srv with the API key, and returns the recorded response.
+func post(srv http.Handler, apiKey string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/rpc", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	return w
+}
+
+// call calls method with params on srv, and decodes its result into result, if not nil.
+func call(t *testing.T, srv http.Handler, method string, params, result interface{}) *rpcError {
+	t.Helper()
+	rawParams, err := json.Marshal(params)
+	require.NoError(t, err)
+	body, err := json.Marshal(&rpcRequest{JSONRPC: "2.0", ID: json.RawMessage("1"), Method: method, Params: rawParams})
+	require.NoError(t, err)
+	w := post(srv, testAPIKey, body)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp testResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, "2.0", resp.JSONRPC)
+	if resp.Error == nil && result != nil {
+		require.NoError(t, json.Unmarshal(resp.Result, result))
+	}
+	return resp.Error
+}
+
+func TestServerRequests(t *testing.T) {
+	n, err := NewNode("a", Limits{})
+	require.NoError(t, err)
+	t.Cleanup(n.Close)
+	srv := NewServer(n, testAPIKey)
+
+	get := httptest.NewRecorder()
+	srv.ServeHTTP(get, httptest.NewRequest(http.MethodGet, "/rpc", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, get.Code)
+	require.Equal(t, http.StatusUnauthorized, post(srv, "", nil).Code)
+	require.Equal(t, http.StatusUnauthorized, post(srv, "other", nil).Code)
+
+	var resp testResponse
+	require.NoError(t, json.Unmarshal(post(srv, testAPIKey, []byte("{")).Body.Bytes(), &resp))
+	require.Equal(t, codeParseError, resp.Error.Code)
+	require.NoError(t, json.Unmarshal(post(srv, testAPIKey, []byte(`{"jsonrpc":"1.0","method":"keys.list"}`)).Body.Bytes(), &resp))
+	require.Equal(t, codeInvalidRequest, resp.Error.Code)
+	// the body of a request is not read beyond maxRequestSize
+	large := append([]byte(`{"jsonrpc":"2.0","method":"keys.list","params":"`), bytes.Repeat([]byte("a"), maxRequestSize)...)
+	require.Equal(t, http.StatusRequestEntityTooLarge, post(srv, testAPIKey, append(large, `"}`...)).Code)
+
+	require.Equal(t, codeMethodNotFound, call(t, srv, "keys.delete", nil, nil).Code)
+	require.Equal(t, codeInvalidParams, call(t, srv, "keys.create", map[string]interface{}{"threshold": 1}, nil).Code)
+	require.Equal(t, codeInvalidParams, call(t, srv, "keys.create", map[string]interface{}{"keyId": "key", "purpose": "other"}, nil).Code)
+	require.Equal(t, codeInvalidParams, call(t, srv, "session.status", map[string]interface{}{"id": "unknown"}, nil).Code)
+	require.Equal(t, codeInvalidParams, call(t, srv, "node.reconfigure", map[string]interface{}{"workers": -1}, nil).Code)
+
+	var limits Limits
+	require.Nil(t, call(t, srv, "node.limits", nil, &limits))
+	require.Equal(t, n.Limits(), limits)
+}
+
+func TestServerKeygenAndSign(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	nodes := newNodes(t, ids)
+	servers := make(map[party.ID]http.Handler, len(ids))
+	for id, n := range nodes {
+		servers[id] = NewServer(n, testAPIKey)
+	}
+
+	for _, srv := range servers {
+		var status SessionStatus
+		require.Nil(t, call(t, srv, "keys.create", map[string]interface{}{"keyId": "key", "threshold": 1, "parties": ids}, &status))
+		require.Equal(t, "key", status.KeyID)
+	}
+	run(t, nodes, "key")
+	var ceremony CeremonyStatus
+	require.Nil(t, call(t, servers["a"], "keys.status", map[string]interface{}{"keyId": "key"}, &ceremony))
+	require.Equal(t, StatusCompleted, ceremony.Status)
+
+	for _, srv := range servers {
+		var status SessionStatus
+		require.Nil(t, call(t, srv, "sign.start", map[string]interface{}{
+			"signId": "sign", "keyId": "key", "parties": ids, "message": make([]byte, 32),
+		}, &status))
+		require.Equal(t, "sign", status.ID)
+	}
+	run(t, nodes, "sign")
+	for _, srv := range servers {
+		var status SessionStatus
+		require.Nil(t, call(t, srv, "session.status", map[string]interface{}{"id": "sign"}, &status))
+		require.Equal(t, StatusCompleted, status.Status)
+		require.NotEmpty(t, status.Result)
+	}
+	require.Equal(t, codeInvalidParams, call(t, servers["a"], "sign.start", map[string]interface{}{
+		"signId": "other", "keyId": "unknown", "message": make([]byte, 32),
+	}, nil).Code)
+}
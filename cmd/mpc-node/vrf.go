@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	thresholdenc "github.com/mr-shifu/mpc-lib/pkg/threshold-encryption"
+	"github.com/mr-shifu/mpc-lib/pkg/vrf"
+)
+
+var (
+	ErrUnknownEvaluation   = errors.New("mpc-node: unknown VRF evaluation")
+	ErrDuplicateEvaluation = errors.New("mpc-node: VRF evaluation already committed")
+	ErrInvalidCommitments  = errors.New("mpc-node: invalid VRF commitments")
+)
+
+// vrfEvaluation identifies the nonces of a VRF evaluation between its commitment and its response.
+type vrfEvaluation struct {
+	keyID string
+	id    string
+}
+
+// CommitVRF starts the VRF evaluation evaluationID of input with the secp256k1 key of the ceremony keyID,
+// and returns the marshalled vrf.Commitment of this node.
+//
+// The nonces are kept until RespondVRF is called for the same evaluation.
+func (n *Node) CommitVRF(keyID, evaluationID string, input []byte) ([]byte, error) {
+	c, err := n.config(keyID)
+	if err != nil {
+		return nil, err
+	}
+	key := vrfEvaluation{keyID: keyID, id: evaluationID}
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	if _, ok := n.vrfNonces[key]; ok {
+		return nil, ErrDuplicateEvaluation
+	}
+	nonces, commitment, err := vrf.Commit(n.self, c.ECDSA, input)
+	if err != nil {
+		return nil, err
+	}
+	data, err := cbor.Marshal(commitment)
+	if err != nil {
+		return nil, err
+	}
+	n.vrfNonces[key] = nonces
+	return data, nil
+}
+
+// RespondVRF returns the marshalled vrf.Response of this node to the evaluation evaluationID,
+// given the marshalled commitments of the quorum, including its own.
+//
+// The nonces of the evaluation are discarded, even if the commitments are invalid.
+func (n *Node) RespondVRF(keyID, evaluationID string, commitments [][]byte) ([]byte, error) {
+	c, err := n.config(keyID)
+	if err != nil {
+		return nil, err
+	}
+	key := vrfEvaluation{keyID: keyID, id: evaluationID}
+	n.mtx.Lock()
+	nonces, ok := n.vrfNonces[key]
+	delete(n.vrfNonces, key)
+	n.mtx.Unlock()
+	if !ok {
+		return nil, ErrUnknownEvaluation
+	}
+
+	decoded := make([]*vrf.Commitment, 0, len(commitments))
+	for _, data := range commitments {
+		commitment := vrf.EmptyCommitment(c.Group)
+		if err := cbor.Unmarshal(data, commitment); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCommitments, err)
+		}
+		decoded = append(decoded, commitment)
+	}
+	response, err := vrf.Respond(nonces, c.ECDSA, thresholdenc.NewCommittee(c), decoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCommitments, err)
+	}
+	return cbor.Marshal(response)
+}
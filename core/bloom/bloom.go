@@ -0,0 +1,141 @@
+// Package bloom implements a small Bloom filter, used to detect values which were already seen
+// without storing them.
+package bloom
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math"
+	"sync"
+)
+
+// Filter is a Bloom filter, safe for concurrent use.
+//
+// Test may return false positives, with a probability depending on the number of added values,
+// but never false negatives.
+type Filter struct {
+	bits   []uint64
+	hashes uint32
+	mtx    sync.RWMutex
+}
+
+// New returns a Filter sized for n values with a false positive rate of about p.
+func New(n int, p float64) *Filter {
+	if n < 1 {
+		n = 1
+	}
+	if p <= 0 || p >= 1 {
+		p = 0.001
+	}
+	m := math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2))
+	k := math.Round(m / float64(n) * math.Ln2)
+	if k < 1 {
+		k = 1
+	}
+	return &Filter{
+		bits:   make([]uint64, (uint64(m)+63)/64),
+		hashes: uint32(k),
+	}
+}
+
+// Add inserts data in the filter, and returns true if it may already have been present.
+func (f *Filter) Add(data []byte) bool {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	present := true
+	f.positions(data, func(word int, mask uint64) {
+		if f.bits[word]&mask == 0 {
+			present = false
+			f.bits[word] |= mask
+		}
+	})
+	return present
+}
+
+// AddAll inserts all values in the filter, unless one of them may already have been present,
+// in which case it inserts none of them and returns true.
+func (f *Filter) AddAll(values ...[]byte) bool {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, data := range values {
+		if f.test(data) {
+			return true
+		}
+	}
+	for _, data := range values {
+		f.positions(data, func(word int, mask uint64) {
+			f.bits[word] |= mask
+		})
+	}
+	return false
+}
+
+// Test returns true if data may have been added to the filter.
+func (f *Filter) Test(data []byte) bool {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+	return f.test(data)
+}
+
+func (f *Filter) test(data []byte) bool {
+	present := true
+	f.positions(data, func(word int, mask uint64) {
+		if f.bits[word]&mask == 0 {
+			present = false
+		}
+	})
+	return present
+}
+
+// Reset removes all values from the filter, typically when starting a new epoch.
+func (f *Filter) Reset() {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for i := range f.bits {
+		f.bits[i] = 0
+	}
+}
+
+// positions calls fn for each of the bits selected by data, using double hashing.
+func (f *Filter) positions(data []byte, fn func(word int, mask uint64)) {
+	digest := sha256.Sum256(data)
+	h1 := binary.BigEndian.Uint64(digest[0:8])
+	h2 := binary.BigEndian.Uint64(digest[8:16]) | 1
+	m := uint64(len(f.bits)) * 64
+	for i := uint64(0); i < uint64(f.hashes); i++ {
+		bit := (h1 + i*h2) % m
+		fn(int(bit/64), 1<<(bit%64))
+	}
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, so that the filter can be persisted.
+func (f *Filter) MarshalBinary() ([]byte, error) {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+	out := make([]byte, 4+8*len(f.bits))
+	binary.BigEndian.PutUint32(out, f.hashes)
+	for i, w := range f.bits {
+		binary.BigEndian.PutUint64(out[4+8*i:], w)
+	}
+	return out, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (f *Filter) UnmarshalBinary(data []byte) error {
+	if len(data) < 12 || (len(data)-4)%8 != 0 {
+		return errors.New("bloom: invalid length")
+	}
+	hashes := binary.BigEndian.Uint32(data)
+	if hashes == 0 {
+		return errors.New("bloom: invalid number of hashes")
+	}
+	bits := make([]uint64, (len(data)-4)/8)
+	for i := range bits {
+		bits[i] = binary.BigEndian.Uint64(data[4+8*i:])
+	}
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.bits, f.hashes = bits, hashes
+	return nil
+}
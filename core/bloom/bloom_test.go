@@ -0,0 +1,47 @@
+package bloom
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+	f := New(1000, 0.001)
+	for i := 0; i < 1000; i++ {
+		assert.False(t, f.Add([]byte(fmt.Sprint("value", i))))
+	}
+	for i := 0; i < 1000; i++ {
+		assert.True(t, f.Test([]byte(fmt.Sprint("value", i))))
+	}
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if f.Test([]byte(fmt.Sprint("other", i))) {
+			falsePositives++
+		}
+	}
+	assert.Less(t, falsePositives, 100)
+
+	data, err := f.MarshalBinary()
+	require.NoError(t, err)
+	g := &Filter{}
+	require.NoError(t, g.UnmarshalBinary(data))
+	assert.True(t, g.Test([]byte("value1")))
+
+	g.Reset()
+	assert.False(t, g.Test([]byte("value1")))
+}
+
+func TestFilterAddAll(t *testing.T) {
+	f := New(100, 0.001)
+	assert.False(t, f.AddAll([]byte("a"), []byte("b")))
+	assert.True(t, f.Test([]byte("a")))
+	assert.True(t, f.Test([]byte("b")))
+
+	// a value already present prevents the others from being added
+	assert.True(t, f.AddAll([]byte("c"), []byte("b")))
+	assert.False(t, f.Test([]byte("c")))
+	assert.False(t, f.AddAll([]byte("c")))
+}
@@ -9,6 +9,11 @@ import (
 	"github.com/mr-shifu/mpc-lib/lib/types"
 )
 
+var (
+	ErrUnknownSigner = errors.New("presignature: unknown signer")
+	ErrInvalidShare  = errors.New("presignature: invalid share")
+)
+
 type PreSignature struct {
 	// ID is a random identifier for this specific presignature.
 	ID types.RID
@@ -24,6 +29,75 @@ type PreSignature struct {
 	ChiShare curve.Scalar
 }
 
+// PreSignaturePublic is the public part of a PreSignature, shared by all signers.
+// It is enough to verify signature shares and combine them, without holding any secret.
+type PreSignaturePublic struct {
+	// R = k⁻¹⋅G
+	R curve.Point
+	// RBar[j] = (k⁻¹kⱼ)⋅G
+	RBar *party.PointMap
+	// S[j] = χⱼ⋅R
+	S *party.PointMap
+}
+
+// Public returns the public part of the PreSignature.
+func (sig *PreSignature) Public() *PreSignaturePublic {
+	return &PreSignaturePublic{
+		R:    sig.R,
+		RBar: sig.RBar,
+		S:    sig.S,
+	}
+}
+
+// VerifySignatureShares returns the list of parties whose shares are invalid,
+// or who are not part of the presignature.
+func (pub *PreSignaturePublic) VerifySignatureShares(shares map[party.ID]SignatureShare, hash []byte) (culprits []party.ID) {
+	for j, share := range shares {
+		if pub.VerifySignatureShare(j, share, hash) != nil {
+			culprits = append(culprits, j)
+		}
+	}
+	return
+}
+
+// VerifySignatureShare verifies the share σⱼ = kⱼm+rχⱼ of party j, by checking that σⱼ⋅R = m⋅R̄ⱼ + r⋅Sⱼ.
+// It returns ErrUnknownSigner if j is not part of the presignature, and ErrInvalidShare if the share is invalid.
+func (pub *PreSignaturePublic) VerifySignatureShare(j party.ID, share SignatureShare, hash []byte) error {
+	Rj, Sj := pub.RBar.Points[j], pub.S.Points[j]
+	if Rj == nil || Sj == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownSigner, j)
+	}
+	if share == nil {
+		return fmt.Errorf("%w: σ of %s is nil", ErrInvalidShare, j)
+	}
+	m := curve.FromHash(pub.R.Curve(), hash)
+	lhs := share.Act(pub.R)
+	rhs := m.Act(Rj).Add(pub.R.XScalar().Act(Sj))
+	if !lhs.Equal(rhs) {
+		return fmt.Errorf("%w: σ of %s", ErrInvalidShare, j)
+	}
+	return nil
+}
+
+// VerifyBigDeltaShare verifies the share Δⱼ = kⱼ⋅Γ broadcast by party j while presigning,
+// given δ = ∑ⱼδⱼ, by checking that Δⱼ = δ⋅R̄ⱼ.
+//
+// Since R̄ⱼ was computed from Δⱼ, this identifies a party which did not send the same Δⱼ to everyone,
+// such as to a coordinator relaying the messages and the signers which computed the presignature.
+func (pub *PreSignaturePublic) VerifyBigDeltaShare(j party.ID, delta curve.Scalar, bigDeltaShare curve.Point) error {
+	Rj := pub.RBar.Points[j]
+	if Rj == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownSigner, j)
+	}
+	if delta == nil || delta.IsZero() || bigDeltaShare == nil || bigDeltaShare.IsIdentity() {
+		return fmt.Errorf("%w: Δ of %s is nil", ErrInvalidShare, j)
+	}
+	if !delta.Act(Rj).Equal(bigDeltaShare) {
+		return fmt.Errorf("%w: Δ of %s", ErrInvalidShare, j)
+	}
+	return nil
+}
+
 // Group returns the elliptic curve group associated with this PreSignature.
 func (sig *PreSignature) Group() curve.Curve {
 	return sig.R.Curve()
@@ -68,21 +142,7 @@ func (sig *PreSignature) Signature(shares map[party.ID]SignatureShare) *Signatur
 // VerifySignatureShares should be called if the signature returned by PreSignature.Signature is not valid.
 // It returns the list of parties whose shares are invalid.
 func (sig *PreSignature) VerifySignatureShares(shares map[party.ID]SignatureShare, hash []byte) (culprits []party.ID) {
-	r := sig.R.XScalar()
-	m := curve.FromHash(sig.Group(), hash)
-	for j, share := range shares {
-		Rj, Sj := sig.RBar.Points[j], sig.S.Points[j]
-		if Rj == nil || Sj == nil {
-			culprits = append(culprits, j)
-			continue
-		}
-		lhs := share.Act(sig.R)
-		rhs := m.Act(Rj).Add(r.Act(Sj))
-		if !lhs.Equal(rhs) {
-			culprits = append(culprits, j)
-		}
-	}
-	return
+	return sig.Public().VerifySignatureShares(shares, hash)
 }
 
 func (sig *PreSignature) Validate() error {
@@ -2,6 +2,7 @@ package ecdsa
 
 import (
 	"encoding/binary"
+	"errors"
 	mrand "math/rand"
 	"testing"
 
@@ -108,3 +109,67 @@ func TestPreSignature_Fail(t *testing.T) {
 		}
 	}
 }
+
+func TestPreSignaturePublic_VerifySignatureShares(t *testing.T) {
+	N := 3
+	group := curve.Secp256k1{}
+	message := []byte("HELLO WORLD")
+	_, _, preSignatures := NewPreSignatures(group, N)
+	sigmaShares := make(map[party.ID]SignatureShare, N)
+	var public *PreSignaturePublic
+	for id, preSignature := range preSignatures {
+		sigmaShares[id] = preSignature.SignatureShare(message)
+		public = preSignature.Public()
+	}
+	if culprits := public.VerifySignatureShares(sigmaShares, message); len(culprits) != 0 {
+		t.Errorf("valid shares reported as invalid: %v", culprits)
+	}
+	bad := party.ID("a")
+	sigmaShares[bad] = group.NewScalar().Set(sigmaShares[bad]).Add(sample.ScalarUnit(mrand.New(mrand.NewSource(1)), group))
+	if culprits := public.VerifySignatureShares(sigmaShares, message); len(culprits) != 1 || culprits[0] != bad {
+		t.Errorf("expected culprit %s, got %v", bad, culprits)
+	}
+}
+
+func TestPreSignaturePublic_VerifyShare(t *testing.T) {
+	N := 3
+	group := curve.Secp256k1{}
+	message := []byte("HELLO WORLD")
+	rand := mrand.New(mrand.NewSource(1))
+	_, _, preSignatures := NewPreSignatures(group, N)
+
+	// δ = k⋅γ and Δⱼ = kⱼ⋅γ⋅G, as computed while presigning
+	gamma := sample.ScalarUnit(rand, group)
+	k := group.NewScalar()
+	var public *PreSignaturePublic
+	for _, preSignature := range preSignatures {
+		k.Add(preSignature.KShare)
+		public = preSignature.Public()
+	}
+	delta := group.NewScalar().Set(k).Mul(gamma)
+
+	for id, preSignature := range preSignatures {
+		if err := public.VerifySignatureShare(id, preSignature.SignatureShare(message), message); err != nil {
+			t.Errorf("valid σ of %s: %v", id, err)
+		}
+		bigDeltaShare := group.NewScalar().Set(preSignature.KShare).Mul(gamma).ActOnBase()
+		if err := public.VerifyBigDeltaShare(id, delta, bigDeltaShare); err != nil {
+			t.Errorf("valid Δ of %s: %v", id, err)
+		}
+
+		other := sample.ScalarUnit(rand, group)
+		if err := public.VerifySignatureShare(id, other, message); !errors.Is(err, ErrInvalidShare) {
+			t.Errorf("invalid σ of %s: got %v", id, err)
+		}
+		if err := public.VerifyBigDeltaShare(id, delta, other.ActOnBase()); !errors.Is(err, ErrInvalidShare) {
+			t.Errorf("invalid Δ of %s: got %v", id, err)
+		}
+	}
+
+	if err := public.VerifySignatureShare("unknown", gamma, message); !errors.Is(err, ErrUnknownSigner) {
+		t.Errorf("unknown signer: got %v", err)
+	}
+	if err := public.VerifyBigDeltaShare("unknown", delta, gamma.ActOnBase()); !errors.Is(err, ErrUnknownSigner) {
+		t.Errorf("unknown signer: got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package ecdsa
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cronokirby/saferith"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+)
+
+// ErrRecovery is returned when a public key cannot be recovered from a signature.
+var ErrRecovery = errors.New("ecdsa: public key recovery failed")
+
+// RecoveryID returns the recovery ID of the signature, which RecoverPublicKey needs to recover the public key
+// from the x-coordinate of R only: bit 0 is the parity of the y-coordinate of R, and bit 1 is set if the
+// x-coordinate of R is not smaller than the group order.
+//
+// It requires a curve encoding points in compressed SEC 1 form, such as secp256k1.
+// For Ethereum, the recovery ID of a signature with a low S must be offset by 27.
+func (sig Signature) RecoveryID() (byte, error) {
+	group := sig.R.Curve()
+	data, err := sig.R.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+	size := curve.ScalarSize(group)
+	if len(data) != size+1 || (data[0] != 2 && data[0] != 3) {
+		return 0, fmt.Errorf("%w: R is not a compressed SEC 1 point", ErrRecovery)
+	}
+	recID := data[0] - 2
+	x := new(saferith.Nat).SetBytes(data[1:])
+	if _, _, lt := x.Cmp(group.Order().Nat()); lt != 1 {
+		recID |= 2
+	}
+	return recID, nil
+}
+
+// RecoverPublicKey returns the public key X for which sig is a valid signature of msgHash.
+//
+// Only the x-coordinate of sig.R is used, along with recID as returned by Signature.RecoveryID,
+// so that signatures decoded from (r, s) can be recovered.
+func RecoverPublicKey(msgHash []byte, sig Signature, recID byte) (curve.Point, error) {
+	if recID > 3 {
+		return nil, fmt.Errorf("%w: invalid recovery ID %d", ErrRecovery, recID)
+	}
+	group := sig.R.Curve()
+	r := sig.R.XScalar()
+	if r == nil || r.IsZero() || sig.S.IsZero() {
+		return nil, fmt.Errorf("%w: invalid signature", ErrRecovery)
+	}
+
+	// x = r, or r + n if it overflowed the group order
+	size := curve.ScalarSize(group)
+	rBytes, err := curve.EncodeScalar(r)
+	if err != nil {
+		return nil, err
+	}
+	x := new(saferith.Nat).SetBytes(rBytes)
+	if recID&2 != 0 {
+		x.Add(x, group.Order().Nat(), size*8+1)
+	}
+	if x.TrueLen() > size*8 {
+		return nil, fmt.Errorf("%w: x-coordinate overflows", ErrRecovery)
+	}
+	compressed := make([]byte, size+1)
+	compressed[0] = 2 + recID&1
+	x.FillBytes(compressed[1:])
+	R := group.NewPoint()
+	if err := R.UnmarshalBinary(compressed); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRecovery, err)
+	}
+
+	// X = r⁻¹ (s R - m G)
+	m := curve.FromHash(group, msgHash)
+	rInv := group.NewScalar().Set(r).Invert()
+	sR := sig.S.Act(R)
+	X := rInv.Act(sR.Sub(m.ActOnBase()))
+	if X.IsIdentity() {
+		return nil, fmt.Errorf("%w: identity", ErrRecovery)
+	}
+	return X, nil
+}
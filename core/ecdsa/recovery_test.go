@@ -0,0 +1,54 @@
+package ecdsa
+
+import (
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+)
+
+func TestRecoverPublicKey(t *testing.T) {
+	group := curve.Secp256k1{}
+	m := []byte("hello")
+
+	for i := 0; i < 16; i++ {
+		x := sample.Scalar(rand.Reader, group)
+		X := x.ActOnBase()
+		sig := NewSignature(x, m, nil)
+
+		recID, err := sig.RecoveryID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		recovered, err := RecoverPublicKey(m, *sig, recID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !recovered.Equal(X) {
+			t.Fatal("recovered a different public key")
+		}
+
+		// the other parity recovers another key
+		other, err := RecoverPublicKey(m, *sig, recID^1)
+		if err == nil && other.Equal(X) {
+			t.Fatal("recovered the public key with the wrong recovery ID")
+		}
+	}
+}
+
+func TestRecoverPublicKeyInvalid(t *testing.T) {
+	group := curve.Secp256k1{}
+	m := []byte("hello")
+	x := sample.Scalar(rand.Reader, group)
+	sig := NewSignature(x, m, nil)
+
+	if _, err := RecoverPublicKey(m, *sig, 4); !errors.Is(err, ErrRecovery) {
+		t.Error("recovery ID 4 should be rejected")
+	}
+	zero := Signature{R: sig.R, S: group.NewScalar()}
+	if _, err := RecoverPublicKey(m, zero, 0); !errors.Is(err, ErrRecovery) {
+		t.Error("s = 0 should be rejected")
+	}
+}
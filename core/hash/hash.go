@@ -21,8 +21,7 @@ const DigestLengthBytes = params.SecBytes * 2 // 64
 // Internally, this is a wrapper around sha3.ShakeHash, but any hash function with
 // an easily extendable output would work as well.
 type Hash struct {
-	h     *blake3.Hasher
-	state []BytesWithDomain
+	h *blake3.Hasher
 }
 
 // New creates a Hash struct where the internal hash function is initialized with "CMP-BLAKE".
@@ -62,6 +61,7 @@ func (hash *Hash) Sum() []byte {
 //   - *saferith.Int
 //   - *saferith.Modulus
 //   - hash.WriterToWithDomain
+//   - hash.SizedWriterToWithDomain, which is streamed into the hash
 //
 // This function will apply its own domain separation for the first two types.
 // The last type already suggests which domain to use, and this function respects it.
@@ -80,6 +80,11 @@ func (hash *Hash) WriteAny(data ...interface{}) error {
 			}
 			bytes, _ := t.GobEncode()
 			toBeWritten = BytesWithDomain{"big.Int", bytes}
+		case SizedWriterToWithDomain:
+			if err := hash.writeSized(t); err != nil {
+				return fmt.Errorf("hash.WriteAny: %s: %w", reflect.TypeOf(t).String(), err)
+			}
+			continue
 		case WriterToWithDomain:
 			var buf = new(bytes.Buffer)
 			_, err := t.WriteTo(buf)
@@ -113,8 +118,6 @@ func (hash *Hash) WriteAny(data ...interface{}) error {
 			return fmt.Errorf("hash.WriteAny: invalid type provided as input")
 		}
 
-		hash.updateState(toBeWritten)
-
 		hash.writeBytesWithDomain(toBeWritten)
 
 	}
@@ -122,34 +125,50 @@ func (hash *Hash) WriteAny(data ...interface{}) error {
 }
 
 func (hash *Hash) writeBytesWithDomain(toBeWritten BytesWithDomain) {
-	var sizeBuf [8]byte
-
-	// Write out `(<domain_size><domain><data_size><data>)`, so that each domain separated piece of data
-	// is distinguished from others.
-
-	_, _ = hash.h.WriteString("(")
-	// <domain_size>
-	binary.BigEndian.PutUint64(sizeBuf[:], uint64(len(toBeWritten.TheDomain)))
-	_, _ = hash.h.Write(sizeBuf[:])
-	// <domain>
-	_, _ = hash.h.WriteString(toBeWritten.TheDomain)
-	// <data_size>
-	binary.BigEndian.PutUint64(sizeBuf[:], uint64(len(toBeWritten.Bytes)))
-	_, _ = hash.h.Write(sizeBuf[:])
+	_, _ = writeHeader(hash.h, toBeWritten.TheDomain, len(toBeWritten.Bytes))
 	// <data>
 	_, _ = hash.h.Write(toBeWritten.Bytes)
 	// )
 	_, _ = hash.h.WriteString(")")
 }
 
-func (hash *Hash) updateState(toBeWritten BytesWithDomain) {
-	hash.state = append(hash.state, toBeWritten)
+// writeSized writes t like writeBytesWithDomain would write its output, without buffering it.
+func (hash *Hash) writeSized(t SizedWriterToWithDomain) error {
+	size := t.Size()
+	_, _ = writeHeader(hash.h, t.Domain(), size)
+	// <data>
+	n, err := t.WriteTo(hash.h)
+	if err != nil {
+		return err
+	}
+	if n != int64(size) {
+		return fmt.Errorf("wrote %d bytes instead of %d", n, size)
+	}
+	// )
+	_, _ = hash.h.WriteString(")")
+	return nil
 }
 
-func (hash *Hash) restoreFromState() {
-	for _, d := range hash.state {
-		hash.writeBytesWithDomain(d)
+// writeHeader writes `(<domain_size><domain><data_size>`, so that each domain separated piece of data
+// `(<domain_size><domain><data_size><data>)` is distinguished from others.
+func writeHeader(w io.Writer, domain string, size int) (int64, error) {
+	var total int64
+	for _, b := range [][]byte{
+		[]byte("("),
+		// <domain_size>
+		binary.BigEndian.AppendUint64(nil, uint64(len(domain))),
+		// <domain>
+		[]byte(domain),
+		// <data_size>
+		binary.BigEndian.AppendUint64(nil, uint64(size)),
+	} {
+		n, err := w.Write(b)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
 	}
+	return total, nil
 }
 
 // Clone returns a copy of the Hash in its current state.
@@ -157,6 +176,22 @@ func (hash *Hash) Clone() *Hash {
 	return &Hash{h: hash.h.Clone()}
 }
 
+// WithDomain clones this hash, and then writes each of data separated by domain,
+// or only the domain if data is empty.
+//
+// The returned branch is independent of this hash, so that different branches,
+// such as one per party, never produce the same output.
+func (hash *Hash) WithDomain(domain string, data ...[]byte) *Hash {
+	newHash := hash.Clone()
+	if len(data) == 0 {
+		data = [][]byte{{}}
+	}
+	for _, d := range data {
+		_ = newHash.WriteAny(BytesWithDomain{TheDomain: domain, Bytes: d})
+	}
+	return newHash
+}
+
 // Fork clones this hash, and then writes some data.
 func (hash *Hash) Fork(data ...interface{}) *Hash {
 	newHash := hash.Clone()
@@ -1,6 +1,7 @@
 package hash
 
 import (
+	"bytes"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
@@ -97,3 +98,57 @@ func TestHash_Clone(t *testing.T) {
 	hashed = h.Sum()
 	fmt.Printf("hashed: %x\n", hashed)
 }
+
+func TestHash_WithDomain(t *testing.T) {
+	h := New()
+	before := h.Sum()
+
+	a := h.WithDomain("party", []byte("a"))
+	b := h.WithDomain("party", []byte("b"))
+	assert.NotEqual(t, a.Sum(), b.Sum())
+	assert.Equal(t, a.Sum(), h.WithDomain("party", []byte("a")).Sum())
+	assert.NotEqual(t, a.Sum(), h.WithDomain("other", []byte("a")).Sum())
+	assert.NotEqual(t, h.Sum(), h.WithDomain("party").Sum())
+	assert.Equal(t, before, h.Sum(), "branching must not change the hash")
+
+	assert.Equal(t, h.Fork(&BytesWithDomain{TheDomain: "party", Bytes: []byte("a")}).Sum(), a.Sum())
+}
+
+type sized struct {
+	BytesWithDomain
+	size int
+}
+
+func (s sized) Size() int { return s.size }
+
+func TestHash_WriteAny_Sized(t *testing.T) {
+	data := BytesWithDomain{TheDomain: "data", Bytes: []byte("streamed")}
+
+	buffered := New()
+	assert.NoError(t, buffered.WriteAny(data))
+	streamed := New()
+	assert.NoError(t, streamed.WriteAny(sized{data, len(data.Bytes)}))
+	assert.Equal(t, buffered.Sum(), streamed.Sum(), "streaming must not change the hash")
+
+	assert.Error(t, New().WriteAny(sized{data, len(data.Bytes) + 1}))
+}
+
+func TestHash_WriteAny_Fields(t *testing.T) {
+	group := curve.Secp256k1{}
+	data := BytesWithDomain{TheDomain: "data", Bytes: []byte("streamed")}
+	fields := Fields{TheDomain: "fields", Values: []interface{}{sized{data, len(data.Bytes)}, sample.Scalar(rand.Reader, group).ActOnBase()}}
+
+	var buf bytes.Buffer
+	n, err := fields.WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.EqualValues(t, fields.Size(), n)
+	assert.Equal(t, fields.Size(), buf.Len())
+
+	buffered := New()
+	assert.NoError(t, buffered.WriteAny(BytesWithDomain{TheDomain: "fields", Bytes: buf.Bytes()}))
+	streamed := New()
+	assert.NoError(t, streamed.WriteAny(fields))
+	assert.Equal(t, buffered.Sum(), streamed.Sum(), "streaming must not change the hash")
+
+	assert.Error(t, New().WriteAny(Fields{TheDomain: "fields", Values: []interface{}{42}}))
+}
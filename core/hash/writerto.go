@@ -1,6 +1,11 @@
 package hash
 
-import "io"
+import (
+	"encoding"
+	"fmt"
+	"io"
+	"reflect"
+)
 
 // WriterToWithDomain represents a type writing itself, and knowing its domain.
 //
@@ -13,6 +18,17 @@ type WriterToWithDomain interface {
 	Domain() string
 }
 
+// SizedWriterToWithDomain is a WriterToWithDomain knowing in advance how many bytes it writes.
+//
+// Hash.WriteAny streams such types directly into the hash function, instead of buffering their output first,
+// which matters for large values such as Paillier ciphertexts.
+type SizedWriterToWithDomain interface {
+	WriterToWithDomain
+
+	// Size returns the number of bytes written by WriteTo.
+	Size() int
+}
+
 // BytesWithDomain is a useful wrapper to annotate some chunk of data with a domain.
 //
 // The intention is to wrap some data using this struct, and then call WriteWithDomain,
@@ -35,3 +51,90 @@ func (b BytesWithDomain) WriteTo(w io.Writer) (int64, error) {
 func (b BytesWithDomain) Domain() string {
 	return b.TheDomain
 }
+
+// Fields groups several values, such as the public statement of a zk proof, so that they are written to the hash
+// as a single SizedWriterToWithDomain.
+//
+// Each value is framed like Hash.WriteAny frames it, `(<domain_size><domain><data_size><data>)`.
+// SizedWriterToWithDomain values are streamed, and encoding.BinaryMarshaler values,
+// which are expected to be small, are marshaled first.
+type Fields struct {
+	TheDomain string
+	Values    []interface{}
+}
+
+// WriteTo implements io.WriterTo.
+func (f Fields) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for _, v := range f.Values {
+		t, err := sizedField(v)
+		if err != nil {
+			return total, err
+		}
+		n, err := writeHeader(w, t.Domain(), t.Size())
+		total += n
+		if err != nil {
+			return total, err
+		}
+		n, err = t.WriteTo(w)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n != int64(t.Size()) {
+			return total, fmt.Errorf("hash.Fields: %s: wrote %d bytes instead of %d", t.Domain(), n, t.Size())
+		}
+		m, err := io.WriteString(w, ")")
+		total += int64(m)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// Domain implements WriterToWithDomain.
+func (f Fields) Domain() string {
+	return f.TheDomain
+}
+
+// Size implements SizedWriterToWithDomain.
+//
+// A value which cannot be written is not counted, WriteTo returns its error.
+func (f Fields) Size() int {
+	size := 0
+	for _, v := range f.Values {
+		t, err := sizedField(v)
+		if err != nil {
+			continue
+		}
+		// (<domain_size><domain><data_size><data>)
+		size += 1 + 8 + len(t.Domain()) + 8 + t.Size() + 1
+	}
+	return size
+}
+
+// sizedBytes is a BytesWithDomain knowing its size.
+type sizedBytes struct {
+	BytesWithDomain
+}
+
+func (b sizedBytes) Size() int {
+	return len(b.Bytes)
+}
+
+// sizedField returns v as a SizedWriterToWithDomain, with the same domain Hash.WriteAny would use.
+func sizedField(v interface{}) (SizedWriterToWithDomain, error) {
+	switch t := v.(type) {
+	case SizedWriterToWithDomain:
+		return t, nil
+	case encoding.BinaryMarshaler:
+		data, err := t.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("hash.Fields: %s: %w", reflect.TypeOf(t).String(), err)
+		}
+		return sizedBytes{BytesWithDomain{TheDomain: reflect.TypeOf(t).String(), Bytes: data}}, nil
+	default:
+		return nil, fmt.Errorf("hash.Fields: invalid type %T provided as input", v)
+	}
+}
@@ -0,0 +1,41 @@
+// Package interop converts the Paillier ciphertexts exchanged during MtA to and from *big.Int, the type
+// handled by the APIs of big.Int based MPC libraries such as github.com/bnb-chain/tss-lib, so that migration
+// bridges can exchange them during a transition.
+//
+// Only the values are converted. The key share and proof formats of tss-lib and multi-party-sig are not
+// implemented: tss-lib runs the GG18/GG20 MtA range proofs rather than the CMP Πaff-g and Πenc proofs, so
+// none of its proofs can be converted into ours, and a format converter without fixtures produced by the
+// other library would not be verifiable.
+package interop
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/mr-shifu/mpc-lib/core/paillier"
+)
+
+// ErrInvalid is returned when converting a value which is invalid for the given key.
+var ErrInvalid = errors.New("interop: invalid value")
+
+// CiphertextToBigInt returns ct as a *big.Int.
+func CiphertextToBigInt(ct *paillier.Ciphertext) *big.Int {
+	return ct.Nat().Big()
+}
+
+// CiphertextFromBigInt returns the ciphertext c encrypted under pk, and checks that it is valid for pk.
+func CiphertextFromBigInt(pk *paillier.PublicKey, c *big.Int) (*paillier.Ciphertext, error) {
+	if c == nil || c.Sign() < 0 {
+		return nil, fmt.Errorf("%w: negative ciphertext", ErrInvalid)
+	}
+	// the bytes of a saferith.Nat are big-endian, so that they decode the minimal encoding of c
+	ct := new(paillier.Ciphertext)
+	if err := ct.UnmarshalBinary(c.Bytes()); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if !pk.ValidateCiphertexts(ct) {
+		return nil, fmt.Errorf("%w: ciphertext is not a unit modulo N²", ErrInvalid)
+	}
+	return ct, nil
+}
@@ -0,0 +1,34 @@
+package interop
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/cronokirby/saferith"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/zk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCiphertext(t *testing.T) {
+	pk, sk := zk.ProverPaillierPublic, zk.ProverPaillierSecret
+
+	m := sample.IntervalL(rand.Reader)
+	ct, _ := pk.Enc(m)
+	decoded, err := CiphertextFromBigInt(pk, CiphertextToBigInt(ct))
+	require.NoError(t, err)
+	assert.True(t, ct.Equal(decoded))
+	plaintext, err := sk.Dec(decoded)
+	require.NoError(t, err)
+	assert.Equal(t, saferith.Choice(1), plaintext.Eq(m))
+
+	// N² is not a unit, and exceeds the ciphertext space
+	_, err = CiphertextFromBigInt(pk, pk.ModulusSquared().Nat().Big())
+	assert.ErrorIs(t, err, ErrInvalid)
+	_, err = CiphertextFromBigInt(pk, big.NewInt(-1))
+	assert.ErrorIs(t, err, ErrInvalid)
+	_, err = CiphertextFromBigInt(pk, nil)
+	assert.ErrorIs(t, err, ErrInvalid)
+}
@@ -0,0 +1,59 @@
+package arith
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/cronokirby/saferith"
+)
+
+var ErrNotConstantTime = errors.New("arith: accelerator does not attest constant time execution")
+
+// Accelerator computes modular exponentiations on behalf of Modulus,
+// for instance with hand-optimized assembly or on a GPU.
+//
+// Since exponents are frequently secret (Paillier nonces, Pedersen openings),
+// an Accelerator must run in time independent of the values of x and e, and attest to it.
+//
+// Accelerator is the only point where alternative big number libraries plug in.
+// saferith types are part of the Paillier, Pedersen and zk APIs, so swapping the
+// whole arithmetic backend would convert every operand at every call site,
+// and a variable time math/big backend could not be ruled out in production.
+type Accelerator interface {
+	// Exp returns xᵉ (mod m).
+	Exp(x, e *saferith.Nat, m *saferith.Modulus) *saferith.Nat
+	// ConstantTime attests that Exp runs in constant time with respect to x and e.
+	ConstantTime() bool
+}
+
+type acceleratorHolder struct {
+	Accelerator
+}
+
+var accelerator atomic.Value
+
+// SetAccelerator installs a as the implementation of modular exponentiation used by Modulus.
+// Passing nil restores the default saferith implementation.
+//
+// Since Pedersen commitments and Paillier operations exponentiate through Modulus,
+// they automatically use the accelerator.
+func SetAccelerator(a Accelerator) error {
+	if a != nil && !a.ConstantTime() {
+		return ErrNotConstantTime
+	}
+	accelerator.Store(acceleratorHolder{a})
+	return nil
+}
+
+// exp returns xᵉ (mod m) with the installed Accelerator, if any.
+func exp(x, e *saferith.Nat, m *saferith.Modulus) *saferith.Nat {
+	if h, ok := accelerator.Load().(acceleratorHolder); ok && h.Accelerator != nil {
+		return h.Exp(x, e, m)
+	}
+	return new(saferith.Nat).Exp(x, e, m)
+}
+
+func accelerated() bool {
+	h, ok := accelerator.Load().(acceleratorHolder)
+	return ok && h.Accelerator != nil
+}
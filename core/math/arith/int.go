@@ -45,26 +45,26 @@ func IsValidBigModN(N *big.Int, ints ...*big.Int) bool {
 	return true
 }
 
-// IsInIntervalLEps returns true if n ∈ [-2ˡ⁺ᵉ,…,2ˡ⁺ᵉ].
-func IsInIntervalLEps(n *saferith.Int) bool {
+// IsInIntervalLEps returns true if n ∈ [-2ˡ⁺ᵉ,…,2ˡ⁺ᵉ], for the slack ε of p.
+func IsInIntervalLEps(n *saferith.Int, p params.Profile) bool {
 	if n == nil {
 		return false
 	}
-	return n.TrueLen() <= params.LPlusEpsilon
+	return n.TrueLen() <= p.LPlusEpsilon()
 }
 
-// IsInIntervalLPrimeEps returns true if n ∈ [-2ˡ'⁺ᵉ,…,2ˡ'⁺ᵉ].
-func IsInIntervalLPrimeEps(n *saferith.Int) bool {
+// IsInIntervalLPrimeEps returns true if n ∈ [-2ˡ'⁺ᵉ,…,2ˡ'⁺ᵉ], for the slack ε of p.
+func IsInIntervalLPrimeEps(n *saferith.Int, p params.Profile) bool {
 	if n == nil {
 		return false
 	}
-	return n.TrueLen() <= params.LPrimePlusEpsilon
+	return n.TrueLen() <= p.LPrimePlusEpsilon()
 }
 
-// IsInIntervalLEpsPlus1RootN returns true if n ∈ [-2¹⁺ˡ⁺ᵉ√N,…,2¹⁺ˡ⁺ᵉ√N], for a Paillier modulus N.
-func IsInIntervalLEpsPlus1RootN(n *saferith.Int) bool {
+// IsInIntervalLEpsPlus1RootN returns true if n ∈ [-2¹⁺ˡ⁺ᵉ√N,…,2¹⁺ˡ⁺ᵉ√N], for a Paillier modulus N and the slack ε of p.
+func IsInIntervalLEpsPlus1RootN(n *saferith.Int, p params.Profile) bool {
 	if n == nil {
 		return false
 	}
-	return n.TrueLen() <= 1+params.LPlusEpsilon+(params.BitsIntModN/2)
+	return n.TrueLen() <= 1+p.LPlusEpsilon()+(params.BitsIntModN/2)
 }
@@ -73,23 +73,22 @@ func ModulusFromFactors(p, q *saferith.Nat) *Modulus {
 // It returns xᵉ (mod n).
 func (n *Modulus) Exp(x, e *saferith.Nat) *saferith.Nat {
 	if n.hasFactorization() {
-		var xp, xq saferith.Nat
-		xp.Exp(x, e, n.p) // x₁ = xᵉ (mod p₁)
-		xq.Exp(x, e, n.q) // x₂ = xᵉ (mod p₂)
+		xp := exp(x, e, n.p) // x₁ = xᵉ (mod p₁)
+		xq := exp(x, e, n.q) // x₂ = xᵉ (mod p₂)
 		// r = x₁ + p₁ ⋅ [p₁⁻¹ (mod p₂)] ⋅ [x₁ - x₂] (mod n)
-		r := xq.ModSub(&xq, &xp, n.Modulus)
+		r := xq.ModSub(xq, xp, n.Modulus)
 		r.ModMul(r, n.pInv, n.Modulus)
 		r.ModMul(r, n.pNat, n.Modulus)
-		r.ModAdd(r, &xp, n.Modulus)
+		r.ModAdd(r, xp, n.Modulus)
 		return r
 	}
-	return new(saferith.Nat).Exp(x, e, n.Modulus)
+	return exp(x, e, n.Modulus)
 }
 
 // ExpI is equivalent to (saferith.Nat).ExpI(x, e, n.Modulus).
 // It returns xᵉ (mod n).
 func (n *Modulus) ExpI(x *saferith.Nat, e *saferith.Int) *saferith.Nat {
-	if n.hasFactorization() {
+	if n.hasFactorization() || accelerated() {
 		y := n.Exp(x, e.Abs())
 		inverted := new(saferith.Nat).ModInverse(y, n.Modulus)
 		y.CondAssign(e.IsNegative(), inverted)
@@ -98,6 +97,24 @@ func (n *Modulus) ExpI(x *saferith.Nat, e *saferith.Int) *saferith.Nat {
 	return new(saferith.Nat).ExpI(x, e, n.Modulus)
 }
 
+// ExpITo is like ExpI, but stores the result in z and returns it.
+// This allows callers to reuse z across exponentiations.
+func (n *Modulus) ExpITo(z, x *saferith.Nat, e *saferith.Int) *saferith.Nat {
+	if n.hasFactorization() || accelerated() {
+		return z.SetNat(n.ExpI(x, e))
+	}
+	return z.ExpI(x, e, n.Modulus)
+}
+
+// Factors returns the factors p and q of n, or nil if n was not created with ModulusFromFactors.
+// They are secret, as is anything derived from them.
+func (n *Modulus) Factors() (p, q *saferith.Nat) {
+	if !n.hasFactorization() {
+		return nil, nil
+	}
+	return n.p.Nat(), n.q.Nat()
+}
+
 func (n Modulus) hasFactorization() bool {
 	return n.p != nil && n.q != nil && n.pNat != nil && n.pInv != nil
 }
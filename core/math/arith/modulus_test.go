@@ -7,14 +7,15 @@ import (
 
 	"github.com/cronokirby/saferith"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 	"github.com/stretchr/testify/assert"
 )
 
 func sampleCoprime(r io.Reader) (*saferith.Nat, *saferith.Nat, *saferith.Modulus) {
-	a := sample.IntervalLEpsN(r).Abs()
+	a := sample.IntervalLEpsN(r, params.Standard).Abs()
 	b := new(saferith.Nat)
 	for b.Coprime(a) != 1 {
-		b = sample.IntervalLEpsN(r).Abs()
+		b = sample.IntervalLEpsN(r, params.Standard).Abs()
 	}
 	cNat := new(saferith.Nat).Mul(a, b, -1)
 	c := saferith.ModulusFromNat(cNat)
@@ -46,6 +47,38 @@ func TestModulus_Exp(t *testing.T) {
 	assert.True(t, yExpected.Eq(ySlow) == 1, "negative exponentiation with acceleration should give the same result")
 }
 
+type countingAccelerator struct {
+	calls     int
+	constTime bool
+}
+
+func (a *countingAccelerator) Exp(x, e *saferith.Nat, m *saferith.Modulus) *saferith.Nat {
+	a.calls++
+	return new(saferith.Nat).Exp(x, e, m)
+}
+
+func (a *countingAccelerator) ConstantTime() bool { return a.constTime }
+
+func TestModulus_Accelerator(t *testing.T) {
+	r := mrand.New(mrand.NewSource(0))
+	a, b, c := sampleCoprime(r)
+	cFast := ModulusFromFactors(a, b)
+	cSlow := ModulusFromN(c)
+
+	assert.ErrorIs(t, SetAccelerator(&countingAccelerator{}), ErrNotConstantTime)
+
+	acc := &countingAccelerator{constTime: true}
+	assert.NoError(t, SetAccelerator(acc))
+	defer func() { _ = SetAccelerator(nil) }()
+
+	x := sample.ModN(r, c)
+	e := new(saferith.Int).SetNat(sample.IntervalLN(r).Abs()).Neg(1)
+	yExpected := new(saferith.Nat).ExpI(x, e, c)
+	assert.True(t, yExpected.Eq(cFast.ExpI(x, e)) == 1, "accelerated exponentiation should give the same result")
+	assert.True(t, yExpected.Eq(cSlow.ExpI(x, e)) == 1, "accelerated exponentiation should give the same result")
+	assert.Equal(t, 3, acc.calls)
+}
+
 func benchmarkExpCRT(b *testing.B, m *Modulus, size int) {
 	r := mrand.New(mrand.NewSource(0))
 	x := new(saferith.Nat)
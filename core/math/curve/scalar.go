@@ -0,0 +1,83 @@
+package curve
+
+import (
+	"errors"
+	"fmt"
+
+	"filippo.io/edwards25519"
+	"github.com/cronokirby/saferith"
+)
+
+// ErrInvalidScalar is returned when decoding a scalar which is not the canonical encoding of a value
+// modulo the group order.
+var ErrInvalidScalar = errors.New("curve: invalid scalar encoding")
+
+// ScalarSize returns the size in bytes of the encoding of a scalar of the group.
+func ScalarSize(group Curve) int {
+	return (group.ScalarBits() + 7) / 8
+}
+
+// EncodeScalar returns the big endian encoding of s, padded to ScalarSize bytes.
+func EncodeScalar(s Scalar) ([]byte, error) {
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	size := ScalarSize(s.Curve())
+	if len(data) > size {
+		return nil, fmt.Errorf("%w: %d bytes", ErrInvalidScalar, len(data))
+	}
+	out := make([]byte, size)
+	copy(out[size-len(data):], data)
+	return out, nil
+}
+
+// DecodeScalar decodes a big endian scalar of exactly ScalarSize bytes, rejecting values which are not
+// reduced modulo the group order. The comparison with the order runs in constant time, so that the
+// decoding of a secret scalar does not leak its value.
+func DecodeScalar(group Curve, data []byte) (Scalar, error) {
+	n, err := decodeCanonical(group.Order(), ScalarSize(group), data)
+	if err != nil {
+		return nil, err
+	}
+	return group.NewScalar().SetNat(n), nil
+}
+
+// ed25519Order is ℓ = 2²⁵² + 27742317777372353535851937790883648493.
+var ed25519Order = saferith.ModulusFromNat(new(saferith.Nat).SetBytes([]byte{
+	0x10, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x14, 0xde, 0xf9, 0xde, 0xa2, 0xf7, 0x9c, 0xd6, 0x58, 0x12, 0x63, 0x1a, 0x5c, 0xf5, 0xd3, 0xed,
+}))
+
+// DecodeEd25519Scalar decodes a little endian edwards25519 scalar of 32 bytes with the same
+// checks as DecodeScalar, for the protocols working with filippo.io/edwards25519 directly.
+func DecodeEd25519Scalar(data []byte) (*edwards25519.Scalar, error) {
+	if len(data) != 32 {
+		return nil, fmt.Errorf("%w: expected 32 bytes, got %d", ErrInvalidScalar, len(data))
+	}
+	var bigEndian [32]byte
+	for i := range data {
+		bigEndian[31-i] = data[i]
+	}
+	if _, err := decodeCanonical(ed25519Order, 32, bigEndian[:]); err != nil {
+		return nil, err
+	}
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidScalar, err)
+	}
+	return s, nil
+}
+
+// decodeCanonical decodes a big endian number of size bytes, which must be smaller than order.
+func decodeCanonical(order *saferith.Modulus, size int, data []byte) (*saferith.Nat, error) {
+	if len(data) != size {
+		return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidScalar, size, len(data))
+	}
+	n := new(saferith.Nat).SetBytes(data)
+	// CmpMod resizes the limbs of the modulus, which may be shared, so we compare with a copy
+	if _, _, lt := n.Cmp(order.Nat()); lt != 1 {
+		return nil, fmt.Errorf("%w: not reduced modulo the group order", ErrInvalidScalar)
+	}
+	return n, nil
+}
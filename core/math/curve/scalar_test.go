@@ -0,0 +1,85 @@
+package curve_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"sync"
+	"testing"
+
+	"filippo.io/edwards25519"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeScalar(t *testing.T) {
+	group := curve.Secp256k1{}
+	s := sample.Scalar(rand.Reader, group)
+	data, err := curve.EncodeScalar(s)
+	require.NoError(t, err)
+	require.Len(t, data, 32)
+
+	decoded, err := curve.DecodeScalar(group, data)
+	require.NoError(t, err)
+	assert.True(t, s.Equal(decoded))
+
+	order := group.Order().Bytes()
+	orderPlusOne := group.Order().Nat().Bytes()
+	orderPlusOne[31]++
+	for _, invalid := range [][]byte{order, orderPlusOne, bytes.Repeat([]byte{0xff}, 32), data[1:], append(data, 0)} {
+		_, err := curve.DecodeScalar(group, invalid)
+		assert.ErrorIs(t, err, curve.ErrInvalidScalar)
+		assert.ErrorIs(t, group.NewScalar().UnmarshalBinary(invalid), curve.ErrInvalidScalar)
+	}
+}
+
+func TestDecodeEd25519Scalar(t *testing.T) {
+	var wide [64]byte
+	_, _ = rand.Read(wide[:])
+	s, err := edwards25519.NewScalar().SetUniformBytes(wide[:])
+	require.NoError(t, err)
+	decoded, err := curve.DecodeEd25519Scalar(s.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, 1, s.Equal(decoded))
+
+	// ℓ in little endian
+	order := []byte{
+		0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10,
+	}
+	_, err = curve.DecodeEd25519Scalar(order)
+	assert.ErrorIs(t, err, curve.ErrInvalidScalar)
+	order[0]--
+	_, err = curve.DecodeEd25519Scalar(order)
+	assert.NoError(t, err)
+	_, err = curve.DecodeEd25519Scalar(order[1:])
+	assert.ErrorIs(t, err, curve.ErrInvalidScalar)
+}
+
+func TestDecodeScalarConcurrent(t *testing.T) {
+	// the group order is shared: decoding must only read it, which the race detector checks
+	group := curve.Secp256k1{}
+	order := group.Order().Bytes()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s := sample.Scalar(rand.Reader, group)
+				data, err := curve.EncodeScalar(s)
+				if !assert.NoError(t, err) {
+					return
+				}
+				decoded, err := curve.DecodeScalar(group, data)
+				if !assert.NoError(t, err) || !assert.True(t, s.Equal(decoded)) {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, order, group.Order().Bytes())
+}
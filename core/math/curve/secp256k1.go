@@ -2,7 +2,6 @@ package curve
 
 import (
 	"encoding/hex"
-	"errors"
 	"fmt"
 
 	"github.com/cronokirby/saferith"
@@ -88,15 +87,14 @@ func (s *Secp256k1Scalar) MarshalBinary() ([]byte, error) {
 	return data[:], nil
 }
 
+// UnmarshalBinary decodes a scalar with the checks of DecodeScalar.
 func (s *Secp256k1Scalar) UnmarshalBinary(data []byte) error {
-	if len(data) != 32 {
-		return fmt.Errorf("invalid length for secp256k1 scalar: %d", len(data))
+	if _, err := decodeCanonical(secp256k1Order, 32, data); err != nil {
+		return err
 	}
 	var exactData [32]byte
 	copy(exactData[:], data)
-	if s.value.SetBytes(&exactData) != 0 {
-		return errors.New("invalid bytes for secp256k1 scalar")
-	}
+	s.value.SetBytes(&exactData)
 	return nil
 }
 
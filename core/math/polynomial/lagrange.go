@@ -75,3 +75,34 @@ func lagrange(group curve.Curve, interpolationDomain map[party.ID]curve.Scalar,
 	lJ.Mul(numerator)
 	return lJ
 }
+
+// LagrangeAt returns the Lagrange coefficients at x for all parties in the interpolation domain,
+// such that f(x) = ∑ⱼ lⱼ(x)⋅f(xⱼ) for any polynomial f of degree less than the size of the domain.
+//
+//	         (x - x₀)⋅⋅⋅(x - xⱼ₋₁)⋅(x - xⱼ₊₁)⋅⋅⋅(x - xₖ)
+//	lⱼ(x) = ----------------------------------------------
+//	        (xⱼ - x₀)⋅⋅⋅(xⱼ - xⱼ₋₁)⋅(xⱼ - xⱼ₊₁)⋅⋅⋅(xⱼ - xₖ)
+func LagrangeAt(group curve.Curve, interpolationDomain []party.ID, x curve.Scalar) map[party.ID]curve.Scalar {
+	scalars := make(map[party.ID]curve.Scalar, len(interpolationDomain))
+	for _, id := range interpolationDomain {
+		scalars[id] = id.Scalar(group)
+	}
+
+	tmp := group.NewScalar()
+	coefficients := make(map[party.ID]curve.Scalar, len(interpolationDomain))
+	for j, xJ := range scalars {
+		numerator := group.NewScalar().SetNat(new(saferith.Nat).SetUint64(1))
+		denominator := group.NewScalar().SetNat(new(saferith.Nat).SetUint64(1))
+		for i, xI := range scalars {
+			if i == j {
+				continue
+			}
+			// numerator *= x - xᵢ
+			numerator.Mul(tmp.Set(x).Sub(xI))
+			// denominator *= xⱼ - xᵢ
+			denominator.Mul(tmp.Set(xJ).Sub(xI))
+		}
+		coefficients[j] = denominator.Invert().Mul(numerator)
+	}
+	return coefficients
+}
@@ -1,11 +1,13 @@
 package polynomial_test
 
 import (
+	"crypto/rand"
 	"testing"
 
 	"github.com/cronokirby/saferith"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
 	"github.com/mr-shifu/mpc-lib/lib/test"
 	"github.com/stretchr/testify/assert"
 )
@@ -29,3 +31,23 @@ func TestLagrange(t *testing.T) {
 	assert.True(t, sumEven.Equal(one))
 	assert.True(t, sumOdd.Equal(one))
 }
+
+func TestLagrangeAt(t *testing.T) {
+	group := curve.Secp256k1{}
+
+	allIDs := test.PartyIDs(6)
+	f := polynomial.NewPolynomial(group, 4, sample.Scalar(rand.Reader, group))
+	x := allIDs[5].Scalar(group)
+	coefs := polynomial.LagrangeAt(group, allIDs[:5], x)
+	sum := group.NewScalar()
+	for j, c := range coefs {
+		sum.Add(group.NewScalar().Set(c).Mul(f.Evaluate(j.Scalar(group))))
+	}
+	assert.True(t, sum.Equal(f.Evaluate(x)))
+
+	// at 0, the coefficients are those of Lagrange
+	zero := polynomial.LagrangeAt(group, allIDs, group.NewScalar())
+	for j, c := range polynomial.Lagrange(group, allIDs) {
+		assert.True(t, c.Equal(zero[j]))
+	}
+}
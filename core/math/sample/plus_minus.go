@@ -28,19 +28,19 @@ func IntervalLPrime(rand io.Reader) *saferith.Int {
 	return sampleNeg(rand, params.LPrime)
 }
 
-// IntervalEps returns an integer in the range ± 2ᵉ, but with constant-time properties.
-func IntervalEps(rand io.Reader) *saferith.Int {
-	return sampleNeg(rand, params.Epsilon)
+// IntervalEps returns an integer in the range ± 2ᵉ for the slack ε of p, but with constant-time properties.
+func IntervalEps(rand io.Reader, p params.Profile) *saferith.Int {
+	return sampleNeg(rand, p.Eps())
 }
 
 // IntervalLEps returns an integer in the range ± 2ˡ⁺ᵉ, but with constant-time properties.
-func IntervalLEps(rand io.Reader) *saferith.Int {
-	return sampleNeg(rand, params.LPlusEpsilon)
+func IntervalLEps(rand io.Reader, p params.Profile) *saferith.Int {
+	return sampleNeg(rand, p.LPlusEpsilon())
 }
 
 // IntervalLPrimeEps returns an integer in the range ± 2ˡ'⁺ᵉ, but with constant-time properties.
-func IntervalLPrimeEps(rand io.Reader) *saferith.Int {
-	return sampleNeg(rand, params.LPrimePlusEpsilon)
+func IntervalLPrimeEps(rand io.Reader, p params.Profile) *saferith.Int {
+	return sampleNeg(rand, p.LPrimePlusEpsilon())
 }
 
 // IntervalLN returns an integer in the range ± 2ˡ•N, where N is the size of a Paillier modulus.
@@ -54,18 +54,18 @@ func IntervalLN2(rand io.Reader) *saferith.Int {
 }
 
 // IntervalLEpsN returns an integer in the range ± 2ˡ⁺ᵉ•N, where N is the size of a Paillier modulus.
-func IntervalLEpsN(rand io.Reader) *saferith.Int {
-	return sampleNeg(rand, params.LPlusEpsilon+params.BitsIntModN)
+func IntervalLEpsN(rand io.Reader, p params.Profile) *saferith.Int {
+	return sampleNeg(rand, p.LPlusEpsilon()+params.BitsIntModN)
 }
 
 // IntervalLEpsN2 returns an integer in the range ± 2ˡ⁺ᵉ•N², where N is the size of a Paillier modulus.
-func IntervalLEpsN2(rand io.Reader) *saferith.Int {
-	return sampleNeg(rand, params.LPlusEpsilon+(2*params.BitsIntModN))
+func IntervalLEpsN2(rand io.Reader, p params.Profile) *saferith.Int {
+	return sampleNeg(rand, p.LPlusEpsilon()+(2*params.BitsIntModN))
 }
 
 // IntervalLEpsRootN returns an integer in the range ± 2ˡ⁺ᵉ•√N, where N is the size of a Paillier modulus.
-func IntervalLEpsRootN(rand io.Reader) *saferith.Int {
-	return sampleNeg(rand, params.LPlusEpsilon+(params.BitsIntModN/2))
+func IntervalLEpsRootN(rand io.Reader, p params.Profile) *saferith.Int {
+	return sampleNeg(rand, p.LPlusEpsilon()+(params.BitsIntModN/2))
 }
 
 // IntervalScalar returns an integer in the range ±q, with q the size of a Scalar.
@@ -160,15 +160,44 @@ func tryBlumPrime(rand io.Reader) *saferith.Nat {
 // p, q are safe primes ((p - 1) / 2 is also prime), and Blum primes (p = 3 mod 4)
 // n = pq.
 func Paillier(rand io.Reader, pl *pool.Pool) (p, q *saferith.Nat) {
+	return PaillierWithProgress(rand, pl, nil)
+}
+
+// PaillierWithProgress is like Paillier, but calls progress after each attempt at finding a safe prime,
+// with the number of attempts made so far and the number of primes found.
+//
+// progress is called from the workers of pl, but never concurrently.
+// The search runs on the Low priority workers of pl, so that it yields to the other sessions sharing it.
+func PaillierWithProgress(rand io.Reader, pl *pool.Pool, progress func(attempts, found int)) (p, q *saferith.Nat) {
 	reader := pool.NewLockedReader(rand)
-	results := pl.Search(2, func() interface{} {
+	var (
+		mtx             sync.Mutex
+		attempts, found int
+		done            bool
+	)
+	results := pl.WithPriority(pool.Low).Search(2, func() interface{} {
 		q := tryBlumPrime(reader)
+		if progress != nil {
+			mtx.Lock()
+			// other workers may still finish an attempt after the search is over
+			if !done {
+				attempts++
+				if q != nil && found < 2 {
+					found++
+				}
+				progress(attempts, found)
+			}
+			mtx.Unlock()
+		}
 		// You have to do this, because of how Go handles nil.
 		if q == nil {
 			return nil
 		}
 		return q
 	})
+	mtx.Lock()
+	done = true
+	mtx.Unlock()
 	p, q = results[0].(*saferith.Nat), results[1].(*saferith.Nat)
 	return
 }
@@ -48,7 +48,7 @@ func UnitModN(rand io.Reader, n *saferith.Modulus) *saferith.Nat {
 		// PERF: Reuse buffer instead of allocating each time
 		mustReadBits(rand, buf)
 		out.SetBytes(buf)
-		if out.IsUnit(n) == 1 {
+		if _, _, lt := out.CmpMod(n); lt == 1 && out.IsUnit(n) == 1 {
 			return out
 		}
 	}
@@ -1,7 +1,9 @@
 package sample
 
 import (
+	"bytes"
 	"crypto/rand"
+	"io"
 	"math/big"
 	"testing"
 
@@ -19,6 +21,18 @@ func TestModN(t *testing.T) {
 	}
 }
 
+func TestUnitModN(t *testing.T) {
+	n := saferith.ModulusFromUint64(13)
+	// 0xff is a unit modulo 13, but is not reduced, and must be rejected
+	x := UnitModN(io.MultiReader(bytes.NewReader([]byte{0xff}), rand.Reader), n)
+	if _, _, lt := x.CmpMod(n); lt != 1 {
+		t.Errorf("UnitModN generated a number >= %v: %v", n, x)
+	}
+	if x.IsUnit(n) != 1 {
+		t.Errorf("UnitModN generated a non unit: %v", x)
+	}
+}
+
 const blumPrimeProbabilityIterations = 20
 
 func TestPaillier(t *testing.T) {
@@ -37,6 +51,25 @@ func TestPaillier(t *testing.T) {
 	}
 }
 
+func TestPaillierWithProgress(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	var attempts, found int
+	PaillierWithProgress(rand.Reader, pl, func(a, f int) {
+		if a != attempts+1 || f < found {
+			t.Errorf("progress went from (%d, %d) to (%d, %d)", attempts, found, a, f)
+		}
+		attempts, found = a, f
+	})
+	if found != 2 {
+		t.Errorf("expected 2 primes to be reported as found, got %d", found)
+	}
+	if attempts < found {
+		t.Errorf("expected at least %d attempts, got %d", found, attempts)
+	}
+}
+
 // This exists to save the results of functions we want to benchmark, to avoid
 // having them optimized away.
 var resultNat *saferith.Nat
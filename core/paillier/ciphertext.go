@@ -3,6 +3,7 @@ package paillier
 import (
 	"crypto/rand"
 	"io"
+	"sync"
 
 	"github.com/cronokirby/saferith"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
@@ -64,17 +65,30 @@ func (ct *Ciphertext) Randomize(pk *PublicKey, nonce *saferith.Nat) *saferith.Na
 	return nonce
 }
 
+// ciphertextBuffers holds the buffers ciphertexts are encoded to by WriteTo, so that hashing the many
+// ciphertexts of a session does not allocate one buffer each.
+var ciphertextBuffers = sync.Pool{New: func() any {
+	buf := make([]byte, params.BytesCiphertext)
+	return &buf
+}}
+
 // WriteTo implements io.WriterTo and should be used within the hash.Hash function.
 func (ct *Ciphertext) WriteTo(w io.Writer) (int64, error) {
 	if ct == nil {
 		return 0, io.ErrUnexpectedEOF
 	}
-	buf := make([]byte, params.BytesCiphertext)
-	ct.c.FillBytes(buf)
-	n, err := w.Write(buf)
+	buf := ciphertextBuffers.Get().(*[]byte)
+	defer ciphertextBuffers.Put(buf)
+	ct.c.FillBytes(*buf)
+	n, err := w.Write(*buf)
 	return int64(n), err
 }
 
+// Size implements hash.SizedWriterToWithDomain, so that ciphertexts are streamed into hash.Hash.
+func (*Ciphertext) Size() int {
+	return params.BytesCiphertext
+}
+
 // Domain implements hash.WriterToWithDomain, and separates this type within hash.Hash.
 func (*Ciphertext) Domain() string {
 	return "Paillier Ciphertext"
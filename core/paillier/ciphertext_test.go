@@ -1,6 +1,7 @@
 package paillier
 
 import (
+	"bytes"
 	"crypto/rand"
 	"testing"
 	"testing/quick"
@@ -8,6 +9,7 @@ import (
 	"github.com/cronokirby/saferith"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
 	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -57,6 +59,16 @@ func TestCiphertextValidate(t *testing.T) {
 	assert.Error(t, err, "decrypting N^2 should fail")
 }
 
+func TestCiphertextDegenerate(t *testing.T) {
+	m := new(saferith.Int).SetUint64(42)
+	ct, _ := paillierPublic.Enc(m)
+	assert.False(t, paillierPublic.IsDegenerate(ct))
+
+	one := new(saferith.Nat).SetUint64(1)
+	assert.True(t, paillierPublic.IsDegenerate(&Ciphertext{one}), "Enc(0) with ρ = 1 is degenerate")
+	assert.True(t, paillierPublic.IsDegenerate(paillierPublic.EncWithNonce(m, one)), "Enc(m) with ρ = 1 is degenerate")
+}
+
 func testEncDecRoundTrip(x uint64, xNeg bool) bool {
 	m := new(saferith.Int).SetUint64(x)
 	if xNeg {
@@ -169,7 +181,7 @@ var resultCiphertext *Ciphertext
 
 func BenchmarkEncryption(b *testing.B) {
 	b.StopTimer()
-	m := sample.IntervalLEps(rand.Reader)
+	m := sample.IntervalLEps(rand.Reader, params.Standard)
 	b.StartTimer()
 	for i := 0; i < b.N; i++ {
 		resultCiphertext, _ = paillierPublic.Enc(m)
@@ -178,7 +190,7 @@ func BenchmarkEncryption(b *testing.B) {
 
 func BenchmarkAddCiphertext(b *testing.B) {
 	b.StopTimer()
-	m := sample.IntervalLEps(rand.Reader)
+	m := sample.IntervalLEps(rand.Reader, params.Standard)
 	c, _ := paillierPublic.Enc(m)
 	b.StartTimer()
 	for i := 0; i < b.N; i++ {
@@ -188,10 +200,26 @@ func BenchmarkAddCiphertext(b *testing.B) {
 
 func BenchmarkMulCiphertext(b *testing.B) {
 	b.StopTimer()
-	m := sample.IntervalLEps(rand.Reader)
+	m := sample.IntervalLEps(rand.Reader, params.Standard)
 	c, _ := paillierPublic.Enc(m)
 	b.StartTimer()
 	for i := 0; i < b.N; i++ {
 		resultCiphertext = c.Mul(paillierPublic, m)
 	}
 }
+
+func TestCiphertextSize(t *testing.T) {
+	m := new(saferith.Int).SetUint64(42)
+	ct, _ := paillierPublic.Enc(m)
+
+	var buf bytes.Buffer
+	n, err := ct.WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.EqualValues(t, ct.Size(), n)
+	assert.Equal(t, ct.Size(), buf.Len())
+
+	buf.Reset()
+	n, err = paillierPublic.WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.EqualValues(t, paillierPublic.Size(), n)
+}
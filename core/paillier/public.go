@@ -130,6 +130,18 @@ func (pk PublicKey) ValidateCiphertexts(cts ...*Ciphertext) bool {
 	return true
 }
 
+// IsDegenerate returns true if ct was computed without randomness, i.e. ct = (1+N)ᵐ = 1 + m⋅N (mod N²).
+// This is the case for the trivial encryption of 0, ct = 1, and such ciphertexts reveal their plaintext.
+//
+// Since ct = (1+N)ᵐ⋅ρᴺ (mod N²), we have ct = ρᴺ (mod N), which is 1 only if ρ = 1.
+func (pk PublicKey) IsDegenerate(ct *Ciphertext) bool {
+	if ct == nil {
+		return true
+	}
+	one := new(saferith.Nat).SetUint64(1)
+	return new(saferith.Nat).Mod(ct.c, pk.n.Modulus).Eq(one) == 1
+}
+
 // WriteTo implements io.WriterTo and should be used within the hash.Hash function.
 func (pk *PublicKey) WriteTo(w io.Writer) (int64, error) {
 	if pk == nil {
@@ -140,6 +152,11 @@ func (pk *PublicKey) WriteTo(w io.Writer) (int64, error) {
 	return int64(n), err
 }
 
+// Size implements hash.SizedWriterToWithDomain, so that public keys are streamed into hash.Hash.
+func (pk *PublicKey) Size() int {
+	return (pk.n.BitLen() + 7) / 8
+}
+
 // Domain implements hash.WriterToWithDomain, and separates this type within hash.Hash.
 func (PublicKey) Domain() string {
 	return "Paillier PublicKey"
@@ -64,6 +64,39 @@ func KeyGen(pl *pool.Pool) (pk *PublicKey, sk *SecretKey) {
 	return
 }
 
+// Progress reports on the search for the two safe primes of a Paillier key.
+type Progress struct {
+	// Attempts is the number of sieving windows searched so far.
+	Attempts int `json:"attempts"`
+	// Found is the number of safe primes found so far, out of 2.
+	Found int `json:"found"`
+}
+
+// Completion estimates the fraction of the search which is done.
+//
+// A sieving window contains 2¹⁶ candidates, and safe primes of our size have a density of about 2.6⋅10⁻⁶,
+// so that an attempt finds one with probability ½, and each missing prime takes 2 more attempts on average.
+func (p Progress) Completion() float64 {
+	if p.Found >= 2 {
+		return 1
+	}
+	remaining := 2 * (2 - p.Found)
+	return float64(p.Attempts) / float64(p.Attempts+remaining)
+}
+
+// KeyGenWithProgress is like KeyGen, but calls progress after each attempt at finding one of the primes.
+func KeyGenWithProgress(pl *pool.Pool, progress func(Progress)) (pk *PublicKey, sk *SecretKey) {
+	var report func(attempts, found int)
+	if progress != nil {
+		report = func(attempts, found int) {
+			progress(Progress{Attempts: attempts, Found: found})
+		}
+	}
+	sk = NewSecretKeyFromPrimes(sample.PaillierWithProgress(rand.Reader, pl, report))
+	pk = sk.PublicKey
+	return
+}
+
 // NewSecretKey generates primes p and q suitable for the scheme, and returns the initialized SecretKey.
 func NewSecretKey(pl *pool.Pool) *SecretKey {
 	// TODO maybe we could take the reader as argument?
@@ -0,0 +1,38 @@
+package pedersen
+
+import (
+	"github.com/cronokirby/saferith"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+)
+
+// CommitBatch computes the commitments sˣⁱ tʸⁱ (mod N) of the pairs (xs[i], ys[i]), as Commit would.
+//
+// It panics if xs and ys have different lengths.
+func (p Parameters) CommitBatch(xs, ys []*saferith.Int) []*saferith.Nat {
+	return p.CommitBatchPooled(nil, xs, ys)
+}
+
+// CommitBatchPooled is equivalent to CommitBatch, but spreads the commitments over the workers of pl,
+// and recycles the temporaries of each worker through the NatAllocator of pl.
+//
+// Tables of fixed powers of s and t are not used: saferith does not expose its Montgomery form,
+// and with its ModMul, building them costs more than the exponentiations they replace for any realistic batch.
+func (p Parameters) CommitBatchPooled(pl *pool.Pool, xs, ys []*saferith.Int) []*saferith.Nat {
+	if len(xs) != len(ys) {
+		panic("pedersen: CommitBatch: xs and ys have different lengths")
+	}
+	nMod := p.n.Modulus
+	nats := pl.Nats()
+	results := pl.Parallelize(len(xs), func(i int) interface{} {
+		ty := nats.Get()
+		defer nats.Put(ty)
+		sx := p.n.ExpI(p.s, xs[i])
+		p.n.ExpITo(ty, p.t, ys[i])
+		return sx.ModMul(sx, ty, nMod)
+	})
+	commitments := make([]*saferith.Nat, len(xs))
+	for i, c := range results {
+		commitments[i] = c.(*saferith.Nat)
+	}
+	return commitments
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/cronokirby/saferith"
 	"github.com/mr-shifu/mpc-lib/core/math/arith"
+	"github.com/mr-shifu/mpc-lib/core/pool"
 	"github.com/mr-shifu/mpc-lib/lib/params"
 )
 
@@ -16,6 +17,10 @@ const (
 	ErrNilFields    Error = "contains nil field"
 	ErrSEqualT      Error = "S cannot be equal to T"
 	ErrNotValidModN Error = "S and T must be in [1,…,N-1] and coprime to N"
+	// ErrInvalidEncoding is returned by UnmarshalBinary for data which is not an encoding of Parameters.
+	ErrInvalidEncoding Error = "invalid encoding"
+	// ErrNoFactors is returned by MarshalFactorsBinary for parameters whose factorization of N is not known.
+	ErrNoFactors Error = "factorization of N is not known"
 )
 
 func (e Error) Error() string {
@@ -25,6 +30,8 @@ func (e Error) Error() string {
 type Parameters struct {
 	n    *arith.Modulus
 	s, t *saferith.Nat
+	// pre is the optional pool of precomputed randomness, see WithPrecomputed.
+	pre *Precomputed
 }
 
 // New returns a new set of Pedersen parameters.
@@ -102,73 +109,187 @@ func (p Parameters) Verify(a, b, e *saferith.Int, S, T *saferith.Nat) bool {
 	return lhs.Eq(rhs) == 1
 }
 
-func (p Parameters) MarshalBiinary() ([]byte, error) {
-	nb, err := p.n.MarshalBinary()
-	if err != nil {
-		return nil, err
+// VerifyPooled is equivalent to Verify, but recycles its temporaries through the NatAllocator of pl.
+func (p Parameters) VerifyPooled(pl *pool.Pool, a, b, e *saferith.Int, S, T *saferith.Nat) bool {
+	if a == nil || b == nil || S == nil || T == nil || e == nil {
+		return false
 	}
-
-	sb, err := p.s.MarshalBinary()
-	if err != nil {
-		return nil, err
+	nMod := p.n.Modulus
+	if !arith.IsValidNatModN(nMod, S, T) {
+		return false
 	}
 
-	tb, err := p.t.MarshalBinary()
-	if err != nil {
-		return nil, err
-	}
+	nats := pl.Nats()
+	sa, tb, te := nats.Get(), nats.Get(), nats.Get()
+	defer nats.Put(sa, tb, te)
 
-	nlb := make([]byte, 2)
-	binary.LittleEndian.PutUint16(nlb, uint16(len(nb)))
+	p.n.ExpITo(sa, p.s, a)         // sᵃ (mod N)
+	p.n.ExpITo(tb, p.t, b)         // tᵇ (mod N)
+	lhs := sa.ModMul(sa, tb, nMod) // lhs = sᵃ⋅tᵇ (mod N)
 
-	slb := make([]byte, 2)
-	binary.LittleEndian.PutUint16(slb, uint16(len(sb)))
+	p.n.ExpITo(te, T, e)          // Tᵉ (mod N)
+	rhs := te.ModMul(te, S, nMod) // rhs = S⋅Tᵉ (mod N)
+	return lhs.Eq(rhs) == 1
+}
 
-	tlb := make([]byte, 2)
-	binary.LittleEndian.PutUint16(tlb, uint16(len(tb)))
+// encodingVersion is the version of the encoding of Parameters.
+const encodingVersion = 1
 
-	buf := make([]byte, 0)
-	buf = append(buf, nlb...)
-	buf = append(buf, nb...)
-	buf = append(buf, slb...)
-	buf = append(buf, sb...)
-	buf = append(buf, tlb...)
-	buf = append(buf, tb...)
+// factorsVersion is the version of the encoding of the factors of N.
+const factorsVersion = 1
 
-	return buf, nil
-}
+// legacyModulusTag is the first byte of the CBOR map of 5 pairs, which the legacy encoding of
+// Parameters has at offset 2. The encoding of encodingVersion has there the most significant bytes
+// of the length of N, which are always 0.
+const legacyModulusTag = 0xa5
 
-func (p *Parameters) UnmarshalBiinary(data []byte) error {
-	nl := binary.LittleEndian.Uint16(data[:2])
-	nb := data[2 : 2+nl]
+// MarshalBinary implements encoding.BinaryMarshaler.
+//
+// The encoding is a version byte, and N, S, T each prefixed by their big-endian uint32 length.
+// It is public: the factors of N are never included, even if they are known, see MarshalFactorsBinary.
+// The precomputed randomness is not encoded.
+func (p Parameters) MarshalBinary() ([]byte, error) {
+	if p.n == nil || p.s == nil || p.t == nil {
+		return nil, ErrNilFields
+	}
+	return appendFields([]byte{encodingVersion}, p.n.Nat(), p.s, p.t), nil
+}
 
-	sl := binary.LittleEndian.Uint16(data[2+nl : 2+nl+2])
-	sb := data[2+nl+2 : 2+nl+2+sl]
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, and validates the parameters it decodes.
+//
+// It also decodes the legacy encoding of Parameters, of which it drops the factorization of N:
+// it is restored with UnmarshalFactorsBinary.
+func (p *Parameters) UnmarshalBinary(data []byte) error {
+	if len(data) > 2 && data[2] == legacyModulusTag {
+		return p.unmarshalLegacy(data)
+	}
+	if len(data) < 1 {
+		return ErrInvalidEncoding
+	}
+	if data[0] != encodingVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidEncoding, data[0])
+	}
+	fields, err := readFields(data[1:], 3)
+	if err != nil {
+		return err
+	}
+	return p.set(arith.ModulusFromN(saferith.ModulusFromNat(fields[0])), fields[1], fields[2])
+}
 
-	tl := binary.LittleEndian.Uint16(data[2+nl+2+sl : 2+nl+2+sl+2])
-	tb := data[2+nl+2+sl+2 : 2+nl+2+sl+2+tl]
+// MarshalFactorsBinary returns the encoding of the factors of N, or ErrNoFactors if they are not known.
+//
+// The factors are secret, and must only be stored along the secret of the parameters.
+// The encoding is a version byte, and the factors each prefixed by their big-endian uint32 length.
+func (p Parameters) MarshalFactorsBinary() ([]byte, error) {
+	if p.n == nil {
+		return nil, ErrNilFields
+	}
+	factorP, factorQ := p.n.Factors()
+	if factorP == nil {
+		return nil, ErrNoFactors
+	}
+	return appendFields([]byte{factorsVersion}, factorP, factorQ), nil
+}
 
-	n := arith.NewEmptyModulus()
-	if err := n.UnmarshalBinary(nb); err != nil {
+// UnmarshalFactorsBinary decodes factors encoded by MarshalFactorsBinary, and sets them as the factors of N,
+// so that p exponentiates faster. It returns an error if they are not the factors of N.
+func (p *Parameters) UnmarshalFactorsBinary(data []byte) error {
+	if p.n == nil {
+		return ErrNilFields
+	}
+	if len(data) < 1 {
+		return ErrInvalidEncoding
+	}
+	if data[0] != factorsVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidEncoding, data[0])
+	}
+	fields, err := readFields(data[1:], 2)
+	if err != nil {
 		return err
 	}
+	n := arith.ModulusFromFactors(fields[0], fields[1])
+	if n.Nat().Eq(p.n.Nat()) != 1 {
+		return fmt.Errorf("%w: factors do not match N", ErrInvalidEncoding)
+	}
 	p.n = n
+	return nil
+}
 
-	var s saferith.Nat
-	if err := s.UnmarshalBinary(sb); err != nil {
-		return err
+// unmarshalLegacy decodes N, S and T each prefixed by their little-endian uint16 length,
+// where N is the CBOR encoding of an arith.Modulus, and S, T are encoded by saferith.Nat.MarshalBinary.
+func (p *Parameters) unmarshalLegacy(data []byte) error {
+	fields := make([][]byte, 3)
+	for i := range fields {
+		if len(data) < 2 {
+			return ErrInvalidEncoding
+		}
+		size := int(binary.LittleEndian.Uint16(data))
+		data = data[2:]
+		if size == 0 || size > len(data) {
+			return fmt.Errorf("%w: legacy field %d of %d bytes", ErrInvalidEncoding, i, size)
+		}
+		fields[i], data = data[:size], data[size:]
+	}
+	if len(data) != 0 {
+		return fmt.Errorf("%w: %d trailing bytes", ErrInvalidEncoding, len(data))
+	}
+	n := arith.NewEmptyModulus()
+	if err := n.UnmarshalBinary(fields[0]); err != nil || n.Modulus == nil {
+		return fmt.Errorf("%w: legacy modulus", ErrInvalidEncoding)
+	}
+	if n.BitLen() > 8*params.BytesIntModN {
+		return fmt.Errorf("%w: legacy modulus of %d bits", ErrInvalidEncoding, n.BitLen())
 	}
-	p.s = &s
+	var s, t saferith.Nat
+	if err := s.UnmarshalBinary(fields[1]); err != nil {
+		return fmt.Errorf("%w: legacy S", ErrInvalidEncoding)
+	}
+	if err := t.UnmarshalBinary(fields[2]); err != nil {
+		return fmt.Errorf("%w: legacy T", ErrInvalidEncoding)
+	}
+	return p.set(arith.ModulusFromN(n.Modulus), &s, &t)
+}
 
-	var t saferith.Nat
-	if err := t.UnmarshalBinary(tb); err != nil {
+// set validates n, s and t, and sets them as the parameters of p.
+func (p *Parameters) set(n *arith.Modulus, s, t *saferith.Nat) error {
+	if err := ValidateParameters(n.Modulus, s, t); err != nil {
 		return err
 	}
-	p.t = &t
-
+	p.n, p.s, p.t, p.pre = n, s, t, nil
 	return nil
 }
 
+// appendFields appends each of fields to buf, prefixed by its big-endian uint32 length.
+func appendFields(buf []byte, fields ...*saferith.Nat) []byte {
+	for _, x := range fields {
+		b := x.Bytes()
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(b)))
+		buf = append(buf, b...)
+	}
+	return buf
+}
+
+// readFields reads count fields appended by appendFields, which must be all of data.
+func readFields(data []byte, count int) ([]*saferith.Nat, error) {
+	fields := make([]*saferith.Nat, count)
+	for i := range fields {
+		if len(data) < 4 {
+			return nil, ErrInvalidEncoding
+		}
+		size := binary.BigEndian.Uint32(data)
+		data = data[4:]
+		if size == 0 || size > params.BytesIntModN || int(size) > len(data) {
+			return nil, fmt.Errorf("%w: field %d of %d bytes", ErrInvalidEncoding, i, size)
+		}
+		fields[i] = new(saferith.Nat).SetBytes(data[:size])
+		data = data[size:]
+	}
+	if len(data) != 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrInvalidEncoding, len(data))
+	}
+	return fields, nil
+}
+
 // WriteTo implements io.WriterTo and should be used within the hash.Hash function.
 func (p *Parameters) WriteTo(w io.Writer) (int64, error) {
 	if p == nil {
@@ -189,6 +310,11 @@ func (p *Parameters) WriteTo(w io.Writer) (int64, error) {
 	return nAll, nil
 }
 
+// Size implements hash.SizedWriterToWithDomain, so that parameters are streamed into hash.Hash.
+func (*Parameters) Size() int {
+	return 3 * params.BytesIntModN
+}
+
 // Domain implements hash.WriterToWithDomain, and separates this type within hash.Hash.
 func (Parameters) Domain() string {
 	return "Pedersen Parameters"
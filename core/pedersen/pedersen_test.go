@@ -1,12 +1,16 @@
 package pedersen
 
 import (
+	"bytes"
 	"crypto/rand"
+	"encoding/binary"
 	"testing"
 
 	"github.com/cronokirby/saferith"
 	"github.com/mr-shifu/mpc-lib/core/math/arith"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	lib_params "github.com/mr-shifu/mpc-lib/lib/params"
 )
 
 var benchParams *Parameters
@@ -48,3 +52,246 @@ func BenchmarkPedersenVerify(b *testing.B) {
 		resultBool = benchParams.Verify(x, y, e, S, T)
 	}
 }
+
+func BenchmarkPedersenVerifyPooled(b *testing.B) {
+	b.StopTimer()
+	pl := pool.NewPool(1).WithNatAllocator()
+	defer pl.TearDown()
+	x := sample.IntervalL(rand.Reader)
+	y := sample.IntervalL(rand.Reader)
+	S := sample.ModN(rand.Reader, benchN)
+	T := sample.ModN(rand.Reader, benchN)
+	e := sample.IntervalL(rand.Reader)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		resultBool = benchParams.VerifyPooled(pl, x, y, e, S, T)
+	}
+}
+
+func TestVerifyPooled(t *testing.T) {
+	pl := pool.NewPool(1).WithNatAllocator()
+	defer pl.TearDown()
+
+	a := sample.IntervalL(rand.Reader)
+	b := sample.IntervalL(rand.Reader)
+	e := sample.IntervalL(rand.Reader)
+	T := sample.UnitModN(rand.Reader, benchN)
+	// S = sᵃ tᵇ T⁻ᵉ (mod N)
+	Te := new(saferith.Nat).ExpI(T, e, benchN)
+	S := benchParams.Commit(a, b)
+	S.ModMul(S, new(saferith.Nat).ModInverse(Te, benchN), benchN)
+
+	for i := 0; i < 3; i++ {
+		if !benchParams.VerifyPooled(pl, a, b, e, S, T) {
+			t.Fatal("valid commitment rejected")
+		}
+		if benchParams.VerifyPooled(pl, b, a, e, S, T) {
+			t.Fatal("invalid commitment accepted")
+		}
+	}
+	if !benchParams.VerifyPooled(nil, a, b, e, S, T) {
+		t.Error("valid commitment rejected without pool")
+	}
+}
+
+func TestPrecomputed(t *testing.T) {
+	pl := pool.NewPool(1)
+	defer pl.TearDown()
+
+	pc := NewPrecomputed(benchParams, 3)
+	if added := pc.Fill(pl); added != 3 || pc.Len() != 3 {
+		t.Fatalf("expected 3 precomputed randomness, added %d, len %d", added, pc.Len())
+	}
+	if added := pc.Fill(pl); added != 0 {
+		t.Fatalf("filled a full pool with %d randomness", added)
+	}
+
+	params := benchParams.WithPrecomputed(pc)
+	x := sample.IntervalL(rand.Reader)
+	r := params.Randomness(lib_params.Standard)
+	if pc.Len() != 2 {
+		t.Fatal("randomness was not taken from the pool")
+	}
+	if r.D.Eq(benchParams.Commit(r.Alpha, r.Gamma)) != 1 {
+		t.Error("D is not a commitment to α, γ")
+	}
+	if params.CommitRandomness(x, r).Eq(benchParams.Commit(x, r.Mu)) != 1 {
+		t.Error("CommitRandomness differs from Commit")
+	}
+	if params.Randomness(lib_params.Standard) == r {
+		t.Error("randomness was reused")
+	}
+
+	// a pool of other parameters is ignored
+	other := &Parameters{n: benchParams.n, s: benchParams.t, t: benchParams.s}
+	other.WithPrecomputed(pc).Randomness(lib_params.Standard)
+	if pc.Len() != 1 {
+		t.Error("randomness was taken for other parameters")
+	}
+}
+
+func TestCommitBatch(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	for _, size := range []int{0, 3, 20} {
+		xs := make([]*saferith.Int, size)
+		ys := make([]*saferith.Int, size)
+		for i := range xs {
+			xs[i] = sample.IntervalL(rand.Reader)
+			ys[i] = sample.IntervalLN(rand.Reader)
+			if i%2 == 1 {
+				xs[i].Neg(1)
+			}
+		}
+		for _, commitments := range [][]*saferith.Nat{benchParams.CommitBatch(xs, ys), benchParams.CommitBatchPooled(pl, xs, ys)} {
+			if len(commitments) != size {
+				t.Fatalf("expected %d commitments, got %d", size, len(commitments))
+			}
+			for i, c := range commitments {
+				if c.Eq(benchParams.Commit(xs[i], ys[i])) != 1 {
+					t.Errorf("commitment %d of a batch of %d differs from Commit", i, size)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkPedersenCommitBatch(b *testing.B) {
+	b.StopTimer()
+	pl := pool.NewPool(0).WithNatAllocator()
+	defer pl.TearDown()
+	const size = 32
+	xs := make([]*saferith.Int, size)
+	ys := make([]*saferith.Int, size)
+	for i := range xs {
+		xs[i] = sample.IntervalL(rand.Reader)
+		ys[i] = sample.IntervalLN(rand.Reader)
+	}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		resultBig = benchParams.CommitBatchPooled(pl, xs, ys)[0]
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	public := New(arith.ModulusFromN(benchN), benchParams.s, benchParams.t)
+	x := sample.IntervalL(rand.Reader)
+	y := sample.IntervalLN(rand.Reader)
+	for name, p := range map[string]*Parameters{"with factors": benchParams, "public": public} {
+		data, err := p.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		publicData, _ := public.MarshalBinary()
+		if !bytes.Equal(data, publicData) {
+			t.Errorf("%s: encoding depends on the factorization of N", name)
+		}
+		decoded := new(Parameters)
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if decoded.N().Nat().Eq(p.N().Nat()) != 1 || decoded.S().Eq(p.S()) != 1 || decoded.T().Eq(p.T()) != 1 {
+			t.Errorf("%s: parameters differ after a round trip", name)
+		}
+		if factorP, _ := decoded.n.Factors(); factorP != nil {
+			t.Errorf("%s: factorization of N was decoded from the public encoding", name)
+		}
+		if decoded.Commit(x, y).Eq(p.Commit(x, y)) != 1 {
+			t.Errorf("%s: commitments differ after a round trip", name)
+		}
+
+		for i := 0; i < len(data); i++ {
+			if err := new(Parameters).UnmarshalBinary(data[:i]); err == nil {
+				t.Fatalf("%s: truncation to %d bytes was accepted", name, i)
+			}
+		}
+		if err := new(Parameters).UnmarshalBinary(append(data, 0)); err == nil {
+			t.Errorf("%s: trailing bytes were accepted", name)
+		}
+		versioned := append([]byte{encodingVersion + 1}, data[1:]...)
+		if err := new(Parameters).UnmarshalBinary(versioned); err == nil {
+			t.Errorf("%s: unknown version was accepted", name)
+		}
+	}
+
+	same := New(benchParams.n, benchParams.s, benchParams.s)
+	data, err := same.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := new(Parameters).UnmarshalBinary(data); err != ErrSEqualT {
+		t.Errorf("expected %v, got %v", ErrSEqualT, err)
+	}
+}
+
+func TestMarshalFactorsBinary(t *testing.T) {
+	public := New(arith.ModulusFromN(benchN), benchParams.s, benchParams.t)
+	if _, err := public.MarshalFactorsBinary(); err != ErrNoFactors {
+		t.Errorf("expected %v, got %v", ErrNoFactors, err)
+	}
+
+	factors, err := benchParams.MarshalFactorsBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := benchParams.MarshalBinary()
+	decoded := new(Parameters)
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.UnmarshalFactorsBinary(factors); err != nil {
+		t.Fatal(err)
+	}
+	factorP, _ := benchParams.n.Factors()
+	if decodedP, _ := decoded.n.Factors(); decodedP == nil || decodedP.Eq(factorP) != 1 {
+		t.Error("factorization of N was not restored")
+	}
+
+	for i := 0; i < len(factors); i++ {
+		if err := new(Parameters).UnmarshalFactorsBinary(factors[:i]); err == nil {
+			t.Fatalf("truncation to %d bytes was accepted", i)
+		}
+	}
+	other, _ := new(saferith.Nat).SetHex("EB")
+	wrong := appendFields([]byte{factorsVersion}, factorP, other)
+	if err := decoded.UnmarshalFactorsBinary(wrong); err == nil {
+		t.Error("factors of another modulus were accepted")
+	}
+}
+
+// legacyMarshal is the encoding of Parameters before encodingVersion.
+func legacyMarshal(t *testing.T, p *Parameters) []byte {
+	var buf []byte
+	for _, m := range []interface{ MarshalBinary() ([]byte, error) }{p.n, p.s, p.t} {
+		b, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(len(b)))
+		buf = append(buf, b...)
+	}
+	return buf
+}
+
+func TestUnmarshalLegacy(t *testing.T) {
+	data := legacyMarshal(t, benchParams)
+	decoded := new(Parameters)
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.N().Nat().Eq(benchParams.N().Nat()) != 1 || decoded.S().Eq(benchParams.S()) != 1 || decoded.T().Eq(benchParams.T()) != 1 {
+		t.Error("parameters differ after decoding the legacy encoding")
+	}
+	if factorP, _ := decoded.n.Factors(); factorP != nil {
+		t.Error("factorization of N was decoded from the legacy encoding")
+	}
+	for i := 0; i < len(data); i += 7 {
+		if err := new(Parameters).UnmarshalBinary(data[:i]); err == nil {
+			t.Fatalf("truncation to %d bytes was accepted", i)
+		}
+	}
+	if err := new(Parameters).UnmarshalBinary(append(data, 0)); err == nil {
+		t.Error("trailing bytes were accepted")
+	}
+}
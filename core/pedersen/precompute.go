@@ -0,0 +1,134 @@
+package pedersen
+
+import (
+	"crypto/rand"
+	"sync"
+
+	"github.com/cronokirby/saferith"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/lib/params"
+)
+
+// Randomness holds the values sampled by a prover committing to a secret x and to a random α,
+// as Commit(x, μ) and Commit(α, γ), along with the exponentiations which do not depend on x.
+//
+// A Randomness must be used for a single proof, since reusing it reveals x.
+type Randomness struct {
+	// Alpha ∈ ± 2ˡ⁺ᵉ
+	Alpha *saferith.Int
+	// Gamma ∈ ± 2ˡ⁺ᵉ•N
+	Gamma *saferith.Int
+	// Mu ∈ ± 2ˡ•N
+	Mu *saferith.Int
+	// D = sᵅ tᵞ (mod N)
+	D *saferith.Nat
+	// TMu = tᵘ (mod N)
+	TMu *saferith.Nat
+}
+
+// NewRandomness samples and exponentiates a new Randomness for a proof of the given profile.
+func (p Parameters) NewRandomness(profile params.Profile) *Randomness {
+	alpha := sample.IntervalLEps(rand.Reader, profile)
+	gamma := sample.IntervalLEpsN(rand.Reader, profile)
+	mu := sample.IntervalLN(rand.Reader)
+	return &Randomness{
+		Alpha: alpha,
+		Gamma: gamma,
+		Mu:    mu,
+		D:     p.Commit(alpha, gamma),
+		TMu:   p.n.ExpI(p.t, mu),
+	}
+}
+
+// Randomness returns a Randomness for a proof of the given profile, taken from the precomputed pool of p,
+// or a new one if the pool is empty or holds randomness for another profile.
+func (p Parameters) Randomness(profile params.Profile) *Randomness {
+	if r := p.pre.take(p, profile); r != nil {
+		return r
+	}
+	return p.NewRandomness(profile)
+}
+
+// CommitRandomness computes sˣ tᵘ (mod N), which is Commit(x, r.Mu).
+func (p Parameters) CommitRandomness(x *saferith.Int, r *Randomness) *saferith.Nat {
+	sx := p.n.ExpI(p.s, x)
+	return sx.ModMul(sx, r.TMu, p.n.Modulus)
+}
+
+// WithPrecomputed returns a copy of p which takes the randomness of its proofs from pc.
+// pc is ignored if it was created for other parameters.
+func (p Parameters) WithPrecomputed(pc *Precomputed) *Parameters {
+	p.pre = pc
+	return &p
+}
+
+// Precomputed is a pool of Randomness for the same Parameters, filled in advance so that
+// the exponentiations are moved out of the rounds of a protocol.
+// It holds randomness for proofs of the standard profile.
+type Precomputed struct {
+	mtx    sync.Mutex
+	params *Parameters
+	size   int
+	items  []*Randomness
+}
+
+// NewPrecomputed returns an empty pool holding up to size Randomness for p.
+func NewPrecomputed(p *Parameters, size int) *Precomputed {
+	return &Precomputed{
+		params: p,
+		size:   size,
+	}
+}
+
+// Fill computes Randomness in pl until the pool is full, and returns the number added.
+// It is meant to be called when the node is idle.
+func (pc *Precomputed) Fill(pl *pool.Pool) int {
+	pc.mtx.Lock()
+	missing := pc.size - len(pc.items)
+	pc.mtx.Unlock()
+	if missing <= 0 {
+		return 0
+	}
+
+	results := pl.Parallelize(missing, func(int) interface{} {
+		return pc.params.NewRandomness(params.Standard)
+	})
+
+	pc.mtx.Lock()
+	defer pc.mtx.Unlock()
+	added := 0
+	for _, r := range results {
+		if len(pc.items) >= pc.size {
+			break
+		}
+		pc.items = append(pc.items, r.(*Randomness))
+		added++
+	}
+	return added
+}
+
+// Len returns the number of Randomness in the pool.
+func (pc *Precomputed) Len() int {
+	pc.mtx.Lock()
+	defer pc.mtx.Unlock()
+	return len(pc.items)
+}
+
+// take removes a Randomness from the pool, or returns nil if it is empty or was created for other parameters
+// than p or another profile.
+func (pc *Precomputed) take(p Parameters, profile params.Profile) *Randomness {
+	if pc == nil || profile.Eps() != params.Standard.Epsilon || pc.params.n.Nat().Eq(p.n.Nat()) != 1 ||
+		pc.params.s.Eq(p.s) != 1 || pc.params.t.Eq(p.t) != 1 {
+		return nil
+	}
+	pc.mtx.Lock()
+	defer pc.mtx.Unlock()
+	if len(pc.items) == 0 {
+		return nil
+	}
+	r := pc.items[len(pc.items)-1]
+	pc.items[len(pc.items)-1] = nil
+	pc.items = pc.items[:len(pc.items)-1]
+	return r
+}
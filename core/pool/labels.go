@@ -0,0 +1,196 @@
+package pool
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PanicError is raised in the goroutine calling Search or Parallelize when a job panicked in a worker,
+// so that the panic can be recovered by the caller instead of crashing the process.
+type PanicError struct {
+	// Label is the label of the job which panicked.
+	Label string
+	// Value is the value passed to panic.
+	Value interface{}
+	// Stack is the stack trace of the worker at the time of the panic.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	if e.Label == "" {
+		return fmt.Sprintf("pool: job panicked: %v", e.Value)
+	}
+	return fmt.Sprintf("pool: job %s panicked: %v", e.Label, e.Value)
+}
+
+// Metrics are the counters of the jobs with a given label.
+type Metrics struct {
+	// Queued is the number of jobs waiting for a worker.
+	Queued int64 `json:"queued"`
+	// Running is the number of jobs currently run by a worker.
+	Running int64 `json:"running"`
+	// Completed is the number of jobs which returned, or panicked.
+	Completed int64 `json:"completed"`
+	// Panicked is the number of jobs which panicked.
+	Panicked int64 `json:"panicked"`
+}
+
+// JobTrace describes a job which was run by a worker.
+//
+// For Search, a job is a worker searching until enough successes were found.
+type JobTrace struct {
+	Label    string
+	Queued   time.Time
+	Started  time.Time
+	Finished time.Time
+	// Panic is the value the job panicked with, or nil.
+	Panic interface{}
+}
+
+// labels holds the limits, metrics and tracer of each label, shared by all the labeled views of a pool.
+type labels struct {
+	mtx     sync.Mutex
+	limits  map[string]chan struct{}
+	metrics map[string]*Metrics
+	tracer  func(JobTrace)
+}
+
+func newLabels() *labels {
+	return &labels{
+		limits:  map[string]chan struct{}{},
+		metrics: map[string]*Metrics{},
+	}
+}
+
+// WithLabel returns a view of the pool whose jobs are labeled, for limits and metrics.
+// The view shares the workers of the pool, and must not be torn down.
+func (p *Pool) WithLabel(label string) *Pool {
+	if p == nil {
+		return nil
+	}
+	view := *p
+	view.label = label
+	return &view
+}
+
+// Label returns the label of the jobs submitted through this pool.
+func (p *Pool) Label() string {
+	if p == nil {
+		return ""
+	}
+	return p.label
+}
+
+// SetLimit restricts the number of jobs with the given label run concurrently to n, across all views of the pool.
+// If n ⩽ 0, the limit is removed.
+//
+// The limit only applies to jobs submitted after this call.
+func (p *Pool) SetLimit(label string, n int) {
+	if p == nil {
+		return
+	}
+	p.labels.mtx.Lock()
+	defer p.labels.mtx.Unlock()
+	if n <= 0 {
+		delete(p.labels.limits, label)
+		return
+	}
+	p.labels.limits[label] = make(chan struct{}, n)
+}
+
+// SetTracer sets a function called after every job of the pool. It must not block.
+func (p *Pool) SetTracer(tracer func(JobTrace)) {
+	if p == nil {
+		return
+	}
+	p.labels.mtx.Lock()
+	defer p.labels.mtx.Unlock()
+	p.labels.tracer = tracer
+}
+
+// Metrics returns a snapshot of the metrics of each label with at least one job.
+func (p *Pool) Metrics() map[string]Metrics {
+	if p == nil {
+		return nil
+	}
+	p.labels.mtx.Lock()
+	defer p.labels.mtx.Unlock()
+	out := make(map[string]Metrics, len(p.labels.metrics))
+	for label, m := range p.labels.metrics {
+		out[label] = Metrics{
+			Queued:    atomic.LoadInt64(&m.Queued),
+			Running:   atomic.LoadInt64(&m.Running),
+			Completed: atomic.LoadInt64(&m.Completed),
+			Panicked:  atomic.LoadInt64(&m.Panicked),
+		}
+	}
+	return out
+}
+
+// job is the bookkeeping of the jobs submitted by a single call to Search or Parallelize.
+type job struct {
+	label   string
+	limit   chan struct{}
+	metrics *Metrics
+	tracer  func(JobTrace)
+	queued  time.Time
+	// panic holds the first *PanicError raised by a worker.
+	panic atomic.Value
+}
+
+func (p *Pool) newJob(count int) *job {
+	p.labels.mtx.Lock()
+	defer p.labels.mtx.Unlock()
+	m, ok := p.labels.metrics[p.label]
+	if !ok {
+		m = &Metrics{}
+		p.labels.metrics[p.label] = m
+	}
+	atomic.AddInt64(&m.Queued, int64(count))
+	return &job{
+		label:   p.label,
+		limit:   p.labels.limits[p.label],
+		metrics: m,
+		tracer:  p.labels.tracer,
+		queued:  time.Now(),
+	}
+}
+
+// run runs f as one of the jobs, recovering a panic.
+// It returns false if f panicked, in which case the panic is stored to be raised by the caller.
+func (j *job) run(f func()) (ok bool) {
+	started := time.Now()
+	atomic.AddInt64(&j.metrics.Queued, -1)
+	atomic.AddInt64(&j.metrics.Running, 1)
+	var panicked interface{}
+	defer func() {
+		if panicked != nil {
+			atomic.AddInt64(&j.metrics.Panicked, 1)
+		}
+		atomic.AddInt64(&j.metrics.Running, -1)
+		atomic.AddInt64(&j.metrics.Completed, 1)
+		if j.limit != nil {
+			<-j.limit
+		}
+		if j.tracer != nil {
+			j.tracer(JobTrace{Label: j.label, Queued: j.queued, Started: started, Finished: time.Now(), Panic: panicked})
+		}
+	}()
+	defer func() {
+		if panicked = recover(); panicked != nil {
+			j.panic.CompareAndSwap(nil, &PanicError{Label: j.label, Value: panicked, Stack: debug.Stack()})
+		}
+	}()
+	f()
+	return true
+}
+
+// raise panics with the *PanicError of a worker, if any.
+func (j *job) raise() {
+	if err, ok := j.panic.Load().(*PanicError); ok {
+		panic(err)
+	}
+}
@@ -0,0 +1,62 @@
+package pool
+
+import (
+	"sync"
+
+	"github.com/cronokirby/saferith"
+)
+
+// NatAllocator recycles the saferith.Nat temporaries of hot code paths,
+// such as Pedersen verification and commitment, to reduce pressure on the garbage collector.
+//
+// Paillier encryption and decryption do not use it: their cost is in modular exponentiations,
+// whose results saferith allocates itself, so that recycling the few remaining temporaries gains nothing.
+//
+// A nil *NatAllocator is valid, and simply allocates a new Nat every time.
+type NatAllocator struct {
+	nats sync.Pool
+}
+
+// NewNatAllocator returns an empty NatAllocator.
+func NewNatAllocator() *NatAllocator {
+	return &NatAllocator{
+		nats: sync.Pool{New: func() interface{} { return new(saferith.Nat) }},
+	}
+}
+
+// Get returns a Nat which may contain a previous value, and must be set before being read.
+func (a *NatAllocator) Get() *saferith.Nat {
+	if a == nil {
+		return new(saferith.Nat)
+	}
+	return a.nats.Get().(*saferith.Nat)
+}
+
+// Put returns temporaries to the allocator. They must not be used afterwards.
+func (a *NatAllocator) Put(xs ...*saferith.Nat) {
+	if a == nil {
+		return
+	}
+	for _, x := range xs {
+		if x != nil {
+			a.nats.Put(x)
+		}
+	}
+}
+
+// WithNatAllocator enables the recycling of Nat temporaries for operations using this pool.
+// It returns the pool itself.
+func (p *Pool) WithNatAllocator() *Pool {
+	if p != nil {
+		p.nats = NewNatAllocator()
+	}
+	return p
+}
+
+// Nats returns the NatAllocator of the pool, which is nil unless enabled with WithNatAllocator.
+func (p *Pool) Nats() *NatAllocator {
+	if p == nil {
+		return nil
+	}
+	return p.nats
+}
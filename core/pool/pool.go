@@ -42,6 +42,8 @@ type command struct {
 	f func(int) interface{}
 	// This is the array where we put results
 	results []interface{}
+	// job holds the label, metrics and panic of the command
+	job *job
 }
 
 // workerSearch is the subroutine called when doing a search command.
@@ -66,9 +68,17 @@ func workerSearch(results []interface{}, ctrChanged chan<- struct{}, f func(int)
 func worker(commands <-chan command) {
 	for c := range commands {
 		if c.search {
-			workerSearch(c.results, c.ctrChanged, c.f, c.ctr)
+			if !c.job.run(func() { workerSearch(c.results, c.ctrChanged, c.f, c.ctr) }) {
+				// stop the search, unless it just completed
+				for ctr := atomic.LoadInt64(c.ctr); ctr > 0; ctr = atomic.LoadInt64(c.ctr) {
+					if atomic.CompareAndSwapInt64(c.ctr, ctr, 0) {
+						c.ctrChanged <- struct{}{}
+						break
+					}
+				}
+			}
 		} else {
-			c.results[c.i] = c.f(c.i)
+			c.job.run(func() { c.results[c.i] = c.f(c.i) })
 			atomic.AddInt64(c.ctr, -1)
 			c.ctrChanged <- struct{}{}
 		}
@@ -85,13 +95,29 @@ func worker(commands <-chan command) {
 //
 // A Pool is only ever intended to be used from a single goroutine, and might cause deadlocks
 // if used by multiple goroutines concurrently.
+//
+// Each pool has a second set of workers for Low priority jobs, see WithPriority.
+//
+// A panic in a worker does not crash the process: it is raised again as a *PanicError
+// in the goroutine which called Search or Parallelize, once the other jobs are done.
 type Pool struct {
 	// The common channel used to send commands to the workers.
 	//
 	// This effectively makes a work stealing pool.
 	commands chan command
-	// This holds the number of workers we've created
+	// low is the channel of the workers of Low jobs, and gate keeps them from competing with Normal jobs.
+	low  chan command
+	gate *gate
+	// This holds the number of workers we've created, for each priority
 	workerCount int
+	// priority is the priority of the jobs submitted through this pool, see WithPriority
+	priority Priority
+	// nats recycles Nat temporaries, if enabled
+	nats *NatAllocator
+	// label is attached to the jobs submitted through this pool, see WithLabel
+	label string
+	// labels is shared by all the labeled views of the pool
+	labels *labels
 }
 
 // NewPool creates a new pool, with a certain number of workers.
@@ -105,10 +131,14 @@ func NewPool(count int) *Pool {
 	}
 
 	p.commands = make(chan command)
+	p.low = make(chan command)
+	p.gate = newGate(count)
 	p.workerCount = count
+	p.labels = newLabels()
 
 	for i := 0; i < count; i++ {
 		go worker(p.commands)
+		go worker(p.low)
 	}
 
 	return &p
@@ -118,6 +148,7 @@ func NewPool(count int) *Pool {
 func (p *Pool) TearDown() {
 	if p != nil {
 		close(p.commands)
+		close(p.low)
 	}
 }
 
@@ -135,26 +166,23 @@ func (p *Pool) Search(count int, f func() interface{}) []interface{} {
 	results := make([]interface{}, count)
 
 	ctr := int64(count)
-	ctrChanged := make(chan struct{})
+	// workers may find up to one more success each once count is reached, and signal a panic once each:
+	// buffering their signals keeps them from blocking once we stopped listening
+	ctrChanged := make(chan struct{}, count+2*p.workerCount)
 	cmd := command{
 		search:     true,
 		ctr:        &ctr,
 		ctrChanged: ctrChanged,
-		f:          func(i int) interface{} { return f() },
+		f:          p.gate.wrap(p.priority, func(int) interface{} { return f() }),
 		results:    results,
+		job:        p.newJob(p.workerCount),
 	}
-	cmdI := 0
-	for cmdI < p.workerCount {
-		select {
-		case p.commands <- cmd:
-			cmdI++
-		case <-ctrChanged:
-		}
-	}
+	p.dispatch(p.workerCount, ctrChanged, func(int) command { return cmd })
 	for atomic.LoadInt64(&ctr) > 0 {
 		<-ctrChanged
 	}
 
+	cmd.job.raise()
 	return results
 }
 
@@ -169,31 +197,57 @@ func (p *Pool) Parallelize(count int, f func(int) interface{}) []interface{} {
 	results := make([]interface{}, count)
 
 	ctr := int64(count)
-	ctrChanged := make(chan struct{})
-	cmdI := 0
-	for cmdI < count {
-		cmd := command{
+	// each job signals once, possibly after we saw ctr reach 0 and stopped listening
+	ctrChanged := make(chan struct{}, count)
+	j := p.newJob(count)
+	f = p.gate.wrap(p.priority, f)
+	p.dispatch(count, ctrChanged, func(i int) command {
+		return command{
 			search:     false,
-			i:          cmdI,
+			i:          i,
 			ctr:        &ctr,
 			ctrChanged: ctrChanged,
 			f:          f,
 			results:    results,
+			job:        j,
 		}
+	})
+	for atomic.LoadInt64(&ctr) > 0 {
+		<-ctrChanged
+	}
+
+	j.raise()
+	return results
+}
+
+// dispatch sends count commands to the workers of the pool's priority, waiting for a slot of the label's limit before each one.
+func (p *Pool) dispatch(count int, ctrChanged <-chan struct{}, cmd func(int) command) {
+	commands := p.commands
+	if p.priority == Low {
+		commands = p.low
+	}
+	cmdI := 0
+	acquired := false
+	for cmdI < count {
+		c := cmd(cmdI)
 		// We won't be able to send all the commands without blocking, so we make
 		// sure to interleave picking off the results of workers to free them up
 		// to receive our commands
+		if c.job.limit != nil && !acquired {
+			select {
+			case c.job.limit <- struct{}{}:
+				acquired = true
+			case <-ctrChanged:
+			}
+			continue
+		}
 		select {
-		case p.commands <- cmd:
+		case commands <- c:
 			cmdI++
+			acquired = false
 		case <-ctrChanged:
 		}
 	}
-	for atomic.LoadInt64(&ctr) > 0 {
-		<-ctrChanged
-	}
-
-	return results
 }
 
 // LockedReader wraps an io.Reader to be safe for concurrent reads.
@@ -0,0 +1,149 @@
+package pool_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolLimit(t *testing.T) {
+	pl := pool.NewPool(8)
+	defer pl.TearDown()
+	pl.SetLimit("zk", 2)
+
+	var running, maxRunning int64
+	results := pl.WithLabel("zk").Parallelize(32, func(i int) interface{} {
+		n := atomic.AddInt64(&running, 1)
+		for {
+			m := atomic.LoadInt64(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt64(&maxRunning, m, n) {
+				break
+			}
+		}
+		defer atomic.AddInt64(&running, -1)
+		return i
+	})
+	for i, r := range results {
+		assert.Equal(t, i, r)
+	}
+	assert.LessOrEqual(t, maxRunning, int64(2))
+
+	metrics := pl.Metrics()["zk"]
+	assert.Equal(t, pool.Metrics{Completed: 32}, metrics)
+}
+
+func TestPoolPanic(t *testing.T) {
+	pl := pool.NewPool(4)
+	defer pl.TearDown()
+
+	var traces int64
+	pl.SetTracer(func(trace pool.JobTrace) {
+		if trace.Panic != nil {
+			atomic.AddInt64(&traces, 1)
+		}
+	})
+
+	var err *pool.PanicError
+	func() {
+		defer func() {
+			require.True(t, errors.As(recover().(error), &err))
+		}()
+		pl.WithLabel("sign").Parallelize(8, func(i int) interface{} {
+			if i == 3 {
+				panic("boom")
+			}
+			return i
+		})
+	}()
+	assert.Equal(t, "sign", err.Label)
+	assert.Equal(t, "boom", err.Value)
+	assert.Equal(t, int64(1), pl.Metrics()["sign"].Panicked)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&traces))
+
+	func() {
+		defer func() {
+			require.True(t, errors.As(recover().(error), &err))
+		}()
+		pl.Search(2, func() interface{} {
+			panic("search")
+		})
+	}()
+	assert.Equal(t, "search", err.Value)
+
+	// the workers survive the panics
+	assert.Len(t, pl.Search(4, func() interface{} { return 1 }), 4)
+}
+
+func TestPoolPriority(t *testing.T) {
+	pl := pool.NewPool(2)
+	defer pl.TearDown()
+	assert.Equal(t, pool.Low, pl.WithPriority(pool.Low).WithLabel("paillier").Priority())
+
+	// Normal jobs keep all the workers busy until released
+	started := make(chan struct{})
+	release := make(chan struct{})
+	normalDone := make(chan struct{})
+	go func() {
+		defer close(normalDone)
+		pl.Parallelize(2, func(i int) interface{} {
+			started <- struct{}{}
+			<-release
+			return i
+		})
+	}()
+	<-started
+	<-started
+
+	var steps int64
+	lowDone := make(chan []interface{})
+	go func() {
+		lowDone <- pl.WithPriority(pool.Low).Search(3, func() interface{} {
+			return atomic.AddInt64(&steps, 1)
+		})
+	}()
+	select {
+	case <-lowDone:
+		t.Fatal("Low job ran while the Normal workers were busy")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Zero(t, atomic.LoadInt64(&steps))
+
+	close(release)
+	<-normalDone
+	results := <-lowDone
+	assert.Len(t, results, 3)
+	assert.GreaterOrEqual(t, atomic.LoadInt64(&steps), int64(3))
+}
+
+func TestPoolSearchDoesNotBlockWorkers(t *testing.T) {
+	pl := pool.NewPool(8)
+	defer pl.TearDown()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// every worker finds a success at once, more than the single one needed:
+		// their extra signals must not block them once Search returned
+		for i := 0; i < 8; i++ {
+			var entered int64
+			pl.Search(1, func() interface{} {
+				atomic.AddInt64(&entered, 1)
+				for atomic.LoadInt64(&entered) < 8 {
+					time.Sleep(time.Millisecond)
+				}
+				return 1
+			})
+			pl.Parallelize(8, func(i int) interface{} { return i })
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("pool deadlocked after searches")
+	}
+}
@@ -0,0 +1,81 @@
+package pool
+
+import "sync"
+
+// Priority is the class of workers running the jobs of a pool.
+type Priority uint8
+
+const (
+	// Normal jobs, such as the proofs of a signature, run as soon as a worker is free.
+	Normal Priority = iota
+	// Low jobs, such as the search for the safe primes of a Paillier key, run on their own workers,
+	// which only take a step while fewer Normal jobs than workers are running,
+	// so that a long key generation does not starve the sessions sharing the pool.
+	Low
+)
+
+// WithPriority returns a view of the pool whose jobs are run by the workers of the given priority.
+// The view shares the workers of the pool, and must not be torn down.
+func (p *Pool) WithPriority(priority Priority) *Pool {
+	if p == nil {
+		return nil
+	}
+	view := *p
+	view.priority = priority
+	return &view
+}
+
+// Priority returns the priority of the jobs submitted through this pool.
+func (p *Pool) Priority() Priority {
+	if p == nil {
+		return Normal
+	}
+	return p.priority
+}
+
+// gate keeps the steps of Low jobs from running while the workers of Normal jobs are all busy.
+type gate struct {
+	mtx  sync.Mutex
+	cond *sync.Cond
+	// capacity is the number of Normal workers, normal the number of Normal jobs running,
+	// and low the number of steps of Low jobs running.
+	capacity int
+	normal   int
+	low      int
+}
+
+func newGate(capacity int) *gate {
+	g := &gate{capacity: capacity}
+	g.cond = sync.NewCond(&g.mtx)
+	return g
+}
+
+// wrap returns f, run as a single step of a job of the given priority.
+func (g *gate) wrap(priority Priority, f func(int) interface{}) func(int) interface{} {
+	if priority == Low {
+		return func(i int) interface{} {
+			g.mtx.Lock()
+			for g.normal+g.low >= g.capacity {
+				g.cond.Wait()
+			}
+			g.low++
+			g.mtx.Unlock()
+			defer g.leave(&g.low)
+			return f(i)
+		}
+	}
+	return func(i int) interface{} {
+		g.mtx.Lock()
+		g.normal++
+		g.mtx.Unlock()
+		defer g.leave(&g.normal)
+		return f(i)
+	}
+}
+
+func (g *gate) leave(running *int) {
+	g.mtx.Lock()
+	*running--
+	g.mtx.Unlock()
+	g.cond.Broadcast()
+}
@@ -0,0 +1,98 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrMemoryBudget is returned by a handler which aborted because its session would retain more than its budget.
+var ErrMemoryBudget = errors.New("protocol: session memory budget exceeded")
+
+// MemoryUsage is the number of bytes retained by a session, as accounted for by its handler.
+//
+// It counts the encoded messages, including the proofs they carry, and the hashes kept for the transcript,
+// but not the state of the rounds, whose secrets are held by the key stores.
+type MemoryUsage struct {
+	// Received are the messages received for the current and later rounds, including our own broadcasts.
+	Received int
+	// Sent are the messages kept to serve retransmission requests.
+	Sent int
+	// Transcript are the hashes of the messages of released rounds and of the broadcast verification.
+	Transcript int
+}
+
+// Total returns the number of bytes retained by the session.
+func (u MemoryUsage) Total() int {
+	return u.Received + u.Sent + u.Transcript
+}
+
+// SetMemoryBudget limits the number of bytes the session may retain, 0 meaning no limit.
+//
+// Once a message would exceed the budget, the sent messages of rounds which the other parties
+// have completed are evicted, since they cannot be asked for again by an honest party.
+// If this is not enough, the session aborts with ErrMemoryBudget, without blaming anyone.
+// The messages of the first round, sent by NewMultiHandler, are accounted for but were not checked.
+func (h *MultiHandler) SetMemoryBudget(bytes int) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.budget = bytes
+}
+
+// MemoryUsage returns the number of bytes currently retained by the session.
+func (h *MultiHandler) MemoryUsage() MemoryUsage {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.memoryUsage()
+}
+
+func (h *MultiHandler) memoryUsage() MemoryUsage {
+	u := MemoryUsage{
+		Received:   h.messages.size + h.broadcast.size,
+		Sent:       h.sentSize,
+		Transcript: h.messages.hashesSize + h.broadcast.hashesSize,
+	}
+	for _, hash := range h.broadcastHashes {
+		u.Transcript += len(hash)
+	}
+	return u
+}
+
+// reserve checks that size more bytes fit in the budget, evicting sent messages if needed.
+// It must be called while holding mtx.
+func (h *MultiHandler) reserve(size int) error {
+	if h.budget <= 0 {
+		return nil
+	}
+	if h.memoryUsage().Total()+size <= h.budget {
+		return nil
+	}
+	h.evictSent()
+	if total := h.memoryUsage().Total(); total+size > h.budget {
+		return fmt.Errorf("%w: %d bytes retained, %d more needed, budget of %d", ErrMemoryBudget, total, size, h.budget)
+	}
+	return nil
+}
+
+// evictSent drops the messages sent before the previous round.
+// Every other party sent its messages of the previous round before we could reach the current one,
+// so it can only ask again for the messages of the previous round and later.
+func (h *MultiHandler) evictSent() {
+	oldest := h.currentRound.Number() - 1
+	kept := h.sent[:0]
+	for _, msg := range h.sent {
+		if msg.RoundNumber < oldest {
+			h.sentSize -= msg.size()
+			continue
+		}
+		kept = append(kept, msg)
+	}
+	for i := len(kept); i < len(h.sent); i++ {
+		h.sent[i] = nil
+	}
+	h.sent = kept
+}
+
+// size is the number of bytes retained by storing the message.
+func (m *Message) size() int {
+	return len(m.Data) + len(m.BroadcastVerification)
+}
@@ -0,0 +1,287 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+)
+
+var (
+	ErrCoordinatorStopped = errors.New("protocol: coordinator stopped")
+	ErrUnsealedMessage    = errors.New("protocol: point-to-point message is not sealed")
+	ErrOutOfOrder         = errors.New("protocol: message out of order")
+)
+
+// Aggregator computes the output of a session, such as its signature, from the broadcast messages
+// received by a Coordinator, indexed by round and sender.
+// It returns a nil output and no error as long as messages are missing.
+// It is called while the Coordinator is locked, and must not call it back.
+type Aggregator func(broadcasts map[round.Number]map[party.ID]*Message) (interface{}, error)
+
+// Coordinator is a non-shareholding participant which relays messages between the parties of a session.
+//
+// It enforces that each party's messages arrive in round order, drops duplicates and messages
+// from unknown parties, and keeps the broadcast messages of each round so that the output of the session,
+// such as the signature computed from the partial signatures of the final round, can be aggregated
+// by an Aggregator and published with Result.
+//
+// The coordinator is not trusted: broadcast messages are still checked for consistency by
+// every party through the BroadcastVerification hash, so that equivocation results in an abort.
+// When RequireSealed is set, point-to-point messages must be encrypted with Seal,
+// so that the coordinator never learns their content.
+type Coordinator struct {
+	ssid     []byte
+	protocol string
+	parties  party.IDSlice
+
+	// RequireSealed rejects point-to-point messages which were not encrypted with Seal.
+	RequireSealed bool
+
+	lastRound map[party.ID]round.Number
+	received  map[round.Number][]*Message
+	seen      map[string]bool
+	out       chan *Message
+	// sending counts the messages being sent to out outside of mtx, which is only closed once they are.
+	sending int
+	// quit is closed by Stop, to drop the messages being sent.
+	quit    chan struct{}
+	stopped bool
+	closed  bool
+
+	aggregate Aggregator
+	result    interface{}
+	err       error
+	done      chan struct{}
+
+	mtx sync.Mutex
+}
+
+// NewCoordinator returns a Coordinator relaying messages for the session ssid of the given protocol,
+// between the given parties.
+func NewCoordinator(ssid []byte, protocolID string, parties []party.ID) *Coordinator {
+	ids := party.NewIDSlice(parties)
+	return &Coordinator{
+		ssid:      ssid,
+		protocol:  protocolID,
+		parties:   ids,
+		lastRound: make(map[party.ID]round.Number, len(ids)),
+		received:  map[round.Number][]*Message{},
+		seen:      map[string]bool{},
+		out:       make(chan *Message, 2*len(ids)*len(ids)),
+		quit:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Listen returns a channel with the messages to be delivered to the parties.
+// Messages with an empty To field must be delivered to all parties except the sender.
+func (c *Coordinator) Listen() <-chan *Message {
+	return c.out
+}
+
+// SetAggregator sets the Aggregator computing the output of the session, which is published by Result
+// once it returns one. It is run on the broadcast messages already received.
+func (c *Coordinator) SetAggregator(aggregate Aggregator) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.aggregate = aggregate
+	c.aggregateBroadcasts()
+}
+
+// Done returns a channel which is closed once the output of the session was aggregated, or could not be,
+// after which Result returns it.
+func (c *Coordinator) Done() <-chan struct{} {
+	return c.done
+}
+
+// Result returns the output of the session computed by the Aggregator, or the error which prevented it.
+func (c *Coordinator) Result() (interface{}, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.result != nil {
+		return c.result, nil
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return nil, errors.New("protocol: not aggregated")
+}
+
+// Accept verifies that msg can be relayed, and queues it for delivery.
+// Duplicate messages are ignored.
+//
+// The message is sent to the channel returned by Listen once the Coordinator is unlocked,
+// so that Accept may be called by the consumer of this channel.
+func (c *Coordinator) Accept(msg *Message) error {
+	c.mtx.Lock()
+	// an abort message stops the session, but must still be relayed before the output channel is closed
+	c.sending++
+	relay, err := c.accept(msg)
+	if !relay {
+		c.sending--
+		c.closeOut()
+	}
+	c.mtx.Unlock()
+	if !relay {
+		return err
+	}
+
+	select {
+	case c.out <- msg:
+	case <-c.quit:
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.sending--
+	c.closeOut()
+	return nil
+}
+
+// accept implements Accept, and returns whether msg must be relayed. It must be called while holding mtx.
+func (c *Coordinator) accept(msg *Message) (bool, error) {
+	if c.stopped {
+		return false, ErrCoordinatorStopped
+	}
+	if err := c.validate(msg); err != nil {
+		return false, err
+	}
+
+	// framework messages are relayed without affecting the sender's ordering
+	if msg.IsResend() || msg.IsHeartbeat() || msg.IsTranscriptConfirmation() {
+		return true, nil
+	}
+
+	key := string(msg.Hash())
+	if c.seen[key] {
+		return false, nil
+	}
+
+	// an abort message is relayed to everyone, and ends the session
+	if msg.RoundNumber == 0 {
+		c.seen[key] = true
+		c.stop()
+		return true, nil
+	}
+
+	if msg.RoundNumber < c.lastRound[msg.From] {
+		return false, fmt.Errorf("%w: round %d from %s after round %d", ErrOutOfOrder, msg.RoundNumber, msg.From, c.lastRound[msg.From])
+	}
+	c.lastRound[msg.From] = msg.RoundNumber
+	c.seen[key] = true
+	c.received[msg.RoundNumber] = append(c.received[msg.RoundNumber], msg)
+	if msg.Broadcast {
+		c.aggregateBroadcasts()
+	}
+	return true, nil
+}
+
+// aggregateBroadcasts runs the Aggregator on the broadcast messages received, until it returns an output or fails.
+// It must be called while holding mtx.
+func (c *Coordinator) aggregateBroadcasts() {
+	if c.aggregate == nil || c.result != nil || c.err != nil {
+		return
+	}
+	broadcasts := make(map[round.Number]map[party.ID]*Message, len(c.received))
+	for number := range c.received {
+		broadcasts[number] = c.messages(number)
+	}
+	result, err := c.aggregate(broadcasts)
+	switch {
+	case err != nil:
+		c.err = err
+	case result != nil:
+		c.result = result
+	default:
+		return
+	}
+	close(c.done)
+}
+
+// Messages returns the broadcast messages received for the given round, indexed by sender.
+// This is typically used to collect the partial signatures sent in the final round.
+func (c *Coordinator) Messages(number round.Number) map[party.ID]*Message {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.messages(number)
+}
+
+func (c *Coordinator) messages(number round.Number) map[party.ID]*Message {
+	msgs := make(map[party.ID]*Message, len(c.parties))
+	for _, msg := range c.received[number] {
+		if msg.Broadcast {
+			msgs[msg.From] = msg
+		}
+	}
+	return msgs
+}
+
+// Stop closes the output channel, dropping the messages not yet relayed.
+// Further calls to Accept return ErrCoordinatorStopped.
+func (c *Coordinator) Stop() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.stop()
+	select {
+	case <-c.quit:
+	default:
+		close(c.quit)
+	}
+}
+
+// stop ends the session: the output channel is closed once the messages being sent are,
+// and the output is no longer aggregated.
+func (c *Coordinator) stop() {
+	if c.stopped {
+		return
+	}
+	c.stopped = true
+	if c.result == nil && c.err == nil {
+		c.err = ErrCoordinatorStopped
+		close(c.done)
+	}
+	c.closeOut()
+}
+
+// closeOut closes the output channel once the session is stopped and no message is being sent.
+// It must be called while holding mtx.
+func (c *Coordinator) closeOut() {
+	if c.stopped && c.sending == 0 && !c.closed {
+		c.closed = true
+		close(c.out)
+	}
+}
+func (c *Coordinator) validate(msg *Message) error {
+	if msg == nil {
+		return errors.New("protocol: nil message")
+	}
+	if msg.Protocol != c.protocol {
+		return fmt.Errorf("protocol: unexpected protocol %s", msg.Protocol)
+	}
+	if !bytes.Equal(msg.SSID, c.ssid) {
+		return errors.New("protocol: unexpected SSID")
+	}
+	if !c.parties.Contains(msg.From) {
+		return fmt.Errorf("protocol: unknown sender %s", msg.From)
+	}
+	if msg.RoundNumber == 0 || msg.IsResend() || msg.IsHeartbeat() || msg.IsTranscriptConfirmation() {
+		return nil
+	}
+	if msg.Broadcast && msg.To != "" {
+		return errors.New("protocol: broadcast message must not have a recipient")
+	}
+	if msg.To == "" {
+		return nil
+	}
+	if msg.To == msg.From || !c.parties.Contains(msg.To) {
+		return fmt.Errorf("protocol: unknown recipient %s", msg.To)
+	}
+	if c.RequireSealed && !IsSealed(msg) {
+		return ErrUnsealedMessage
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package protocol
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// coordinated returns a broadcast message of the given round from the party from, relayed by a coordinator of ssid.
+func coordinated(ssid []byte, from party.ID, number round.Number) *Message {
+	return &Message{
+		SSID:        ssid,
+		Protocol:    "chatty",
+		From:        from,
+		RoundNumber: number,
+		Broadcast:   true,
+		Data:        []byte{byte(number)},
+	}
+}
+
+func TestCoordinatorCallback(t *testing.T) {
+	ssid := []byte("ssid")
+	ids := party.IDSlice{"a", "b"}
+	c := NewCoordinator(ssid, "chatty", ids)
+
+	// the consumer calls back into the coordinator for every message, while the sender fills its buffer
+	n := 8 * cap(c.out)
+	sent := make(chan error, 1)
+	go func() {
+		for number := round.Number(1); number <= round.Number(n); number++ {
+			if err := c.Accept(coordinated(ssid, "a", number)); err != nil {
+				sent <- err
+				return
+			}
+		}
+		sent <- nil
+	}()
+	received := 0
+	for received < n {
+		select {
+		case msg := <-c.Listen():
+			require.NoError(t, c.Accept(msg))
+			received++
+		case <-time.After(10 * time.Second):
+			t.Fatalf("relayed %d messages out of %d", received, n)
+		}
+	}
+	require.NoError(t, <-sent)
+
+	// an abort is relayed before the channel is closed
+	abort := coordinated(ssid, "b", 0)
+	require.NoError(t, c.Accept(abort))
+	assert.Equal(t, abort, <-c.Listen())
+	_, ok := <-c.Listen()
+	assert.False(t, ok)
+	require.ErrorIs(t, c.Accept(coordinated(ssid, "b", 1)), ErrCoordinatorStopped)
+	c.Stop()
+}
+
+func TestCoordinatorAggregate(t *testing.T) {
+	ssid := []byte("ssid")
+	ids := party.IDSlice{"a", "b", "c"}
+	c := NewCoordinator(ssid, "chatty", ids)
+	// the output is the sum of the final round's broadcasts, once all parties sent theirs
+	c.SetAggregator(func(broadcasts map[round.Number]map[party.ID]*Message) (interface{}, error) {
+		if len(broadcasts[2]) < len(ids) {
+			return nil, nil
+		}
+		sum := 0
+		for _, msg := range broadcasts[2] {
+			sum += int(msg.Data[0])
+		}
+		return sum, nil
+	})
+
+	for _, number := range []round.Number{1, 2} {
+		for _, id := range ids {
+			select {
+			case <-c.Done():
+				t.Fatal("aggregated before all broadcasts were received")
+			default:
+			}
+			_, err := c.Result()
+			require.Error(t, err)
+			require.NoError(t, c.Accept(coordinated(ssid, id, number)))
+		}
+	}
+	<-c.Done()
+	result, err := c.Result()
+	require.NoError(t, err)
+	assert.Equal(t, 6, result)
+	assert.Len(t, c.Messages(2), len(ids))
+
+	// the messages received are relayed until the coordinator stops
+	c.Stop()
+	n := 0
+	for range c.Listen() {
+		n++
+	}
+	assert.Equal(t, 2*len(ids), n)
+	result, err = c.Result()
+	require.NoError(t, err)
+	assert.Equal(t, 6, result)
+}
+
+func TestCoordinatorAggregateFails(t *testing.T) {
+	ssid := []byte("ssid")
+	ids := party.IDSlice{"a", "b"}
+	invalid := errors.New("invalid broadcast")
+
+	c := NewCoordinator(ssid, "chatty", ids)
+	require.NoError(t, c.Accept(coordinated(ssid, "a", 1)))
+	// the aggregator runs on the broadcasts received before it was set
+	c.SetAggregator(func(map[round.Number]map[party.ID]*Message) (interface{}, error) {
+		return nil, invalid
+	})
+	<-c.Done()
+	_, err := c.Result()
+	require.ErrorIs(t, err, invalid)
+	c.Stop()
+
+	// a session which stops before its output is aggregated has none
+	c = NewCoordinator(ssid, "chatty", ids)
+	c.SetAggregator(func(map[round.Number]map[party.ID]*Message) (interface{}, error) {
+		return nil, nil
+	})
+	require.NoError(t, c.Accept(coordinated(ssid, "a", 1)))
+	c.Stop()
+	<-c.Done()
+	_, err = c.Result()
+	require.ErrorIs(t, err, ErrCoordinatorStopped)
+}
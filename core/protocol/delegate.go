@@ -0,0 +1,432 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	sw_hash "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/hash"
+)
+
+// ErrDelegate is returned when a RoundDelegate fails or returns an invalid round.
+var ErrDelegate = errors.New("protocol: round delegate failed")
+
+// RoundDelegate computes the rounds of a party on its behalf, typically as the client of a remote service
+// holding the party's keys next to an HSM. The local node then only runs a MultiHandler over DelegatedSession,
+// which moves the messages between the transport and the delegate, and never sees the key material:
+// the rounds it receives only hold public data, and the result of the session only its PublicResult.
+//
+// Messages are exchanged in their encoded form, so that the node does not need to know the protocol.
+// Implementations must be safe for concurrent use by different sessions.
+type RoundDelegate interface {
+	// Start creates a session with the given ID, and returns its first round.
+	Start(sessionID []byte) (*DelegatedRound, error)
+	// Store verifies a message of the current round of the session ssid and stores it.
+	// The broadcast message of a sender is always stored before its p2p message of the same round.
+	Store(ssid []byte, msg *DelegatedMessage) error
+	// Finalize computes the round following number, once all its messages were stored,
+	// and returns it along with the messages to send.
+	Finalize(ssid []byte, number round.Number) (*DelegatedRound, []*DelegatedMessage, error)
+}
+
+// PublicResult is implemented by the results of protocols which a RoundDelegate may hand back to the node.
+// MarshalPublic returns the encoding of the public part of the result, such as the public key of a keygen,
+// which must not include any secret material.
+type PublicResult interface {
+	MarshalPublic() ([]byte, error)
+}
+
+// DelegatedInfo describes a session computed by a RoundDelegate, as round.Info with the group given by name.
+type DelegatedInfo struct {
+	ProtocolID       string
+	FinalRoundNumber round.Number
+	SelfID           party.ID
+	PartyIDs         []party.ID
+	Threshold        int
+	// Group is the name of the group of the session, empty if it is not given by a curve.Curve.
+	Group   string
+	Version round.Version
+}
+
+// DelegatedRound describes a round computed by a RoundDelegate.
+//
+// It only holds public data, in a form which can be sent by a remote RoundDelegate, such as with cbor.
+type DelegatedRound struct {
+	// Info describes the session. It is only read from the first round.
+	Info DelegatedInfo
+	// SSID is the identifier of the session. It is only read from the first round.
+	SSID []byte
+	// Transcript is the transcript of the session hash at this round, used to verify echo broadcasts.
+	Transcript sw_hash.Transcript
+
+	Number round.Number
+	// Broadcast is set if the round expects a broadcast message from the other parties, and Reliable if
+	// it must be reliably broadcast. Message is set if the round expects a p2p message.
+	Broadcast, Reliable, Message bool
+
+	// Result is set once the protocol completed. Err is the message of the error if it aborted, blaming Culprits.
+	Result   *DelegatedResult
+	Err      string
+	Culprits []party.ID
+}
+
+// DelegatedResult is the result of a session computed by a RoundDelegate, as returned by the MultiHandler
+// running DelegatedSession.
+type DelegatedResult struct {
+	// Public is the encoding of the PublicResult of the session, empty if its result has no public part.
+	// The result itself is kept by the delegate.
+	Public []byte
+}
+
+// DelegatedMessage is a message received from or sent by a RoundDelegate, with its content encoded.
+type DelegatedMessage struct {
+	From, To    party.ID
+	Broadcast   bool
+	RoundNumber round.Number
+	Data        []byte
+}
+
+// DelegatedSession returns a StartFunc whose rounds are computed by d, to be passed to NewMultiHandler.
+func DelegatedSession(d RoundDelegate, pl *pool.Pool) StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		dr, err := d.Start(sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDelegate, err)
+		}
+		if dr == nil {
+			return nil, fmt.Errorf("%w: no first round", ErrDelegate)
+		}
+		info, err := dr.Info.info()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDelegate, err)
+		}
+		helper, err := resumeDelegatedSession(string(sessionID), info, dr.SSID, pl, dr)
+		if err != nil {
+			return nil, err
+		}
+		return newDelegatedRound(helper, d, info, dr), nil
+	}
+}
+
+// info returns the round.Info of the session.
+func (i DelegatedInfo) info() (round.Info, error) {
+	info := round.Info{
+		ProtocolID:       i.ProtocolID,
+		FinalRoundNumber: i.FinalRoundNumber,
+		SelfID:           i.SelfID,
+		PartyIDs:         i.PartyIDs,
+		Threshold:        i.Threshold,
+		Version:          i.Version,
+	}
+	switch i.Group {
+	case "":
+	case curve.Secp256k1{}.Name():
+		info.Group = curve.Secp256k1{}
+	default:
+		return round.Info{}, fmt.Errorf("protocol: unknown group %s", i.Group)
+	}
+	return info, nil
+}
+
+// resumeDelegatedSession returns the helper of the round dr, whose session hash is replayed from its transcript.
+func resumeDelegatedSession(ID string, info round.Info, ssid []byte, pl *pool.Pool, dr *DelegatedRound) (*round.Helper, error) {
+	h, err := dr.Transcript.Segment(len(dr.Transcript), "").Hash()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDelegate, err)
+	}
+	return round.ResumeSession(ID, info, ssid, pl, h)
+}
+
+// delegatedRound forwards the messages of a round to its RoundDelegate.
+type delegatedRound struct {
+	*round.Helper
+	delegate RoundDelegate
+	// info is the round.Info of the first round.
+	info  round.Info
+	round *DelegatedRound
+}
+
+// delegatedBroadcastRound is a delegatedRound expecting broadcast messages.
+type delegatedBroadcastRound struct {
+	*delegatedRound
+}
+
+func newDelegatedRound(helper *round.Helper, d RoundDelegate, info round.Info, dr *DelegatedRound) round.Session {
+	switch {
+	case dr.Err != "":
+		return helper.AbortRound(fmt.Errorf("%w: %s", ErrDelegate, dr.Err), dr.Culprits...)
+	case dr.Result != nil:
+		return helper.ResultRound(dr.Result)
+	}
+	r := &delegatedRound{Helper: helper, delegate: d, info: info, round: dr}
+	if dr.Broadcast {
+		return &delegatedBroadcastRound{r}
+	}
+	return r
+}
+
+// VerifyMessage implements round.Round. The delegate verifies and stores the message at once.
+func (r *delegatedRound) VerifyMessage(msg round.Message) error {
+	return r.store(msg)
+}
+
+// StoreMessage implements round.Round. The message was already stored by VerifyMessage.
+func (r *delegatedRound) StoreMessage(round.Message) error { return nil }
+
+// StoreBroadcastMessage implements round.Round.
+func (r *delegatedRound) StoreBroadcastMessage(msg round.Message) error {
+	return r.store(msg)
+}
+
+func (r *delegatedRound) store(msg round.Message) error {
+	content, err := round.ContentAs[*rawContent](msg)
+	if err != nil {
+		return err
+	}
+	return r.delegate.Store(r.SSID(), &DelegatedMessage{
+		From:        msg.From,
+		To:          msg.To,
+		Broadcast:   msg.Broadcast,
+		RoundNumber: r.round.Number,
+		Data:        content.data,
+	})
+}
+
+// Finalize implements round.Round.
+func (r *delegatedRound) Finalize(out chan<- *round.Message) (round.Session, error) {
+	next, msgs, err := r.delegate.Finalize(r.SSID(), r.round.Number)
+	if err != nil {
+		return r, fmt.Errorf("%w: %w", ErrDelegate, err)
+	}
+	if next == nil {
+		return r, fmt.Errorf("%w: no next round", ErrDelegate)
+	}
+	for _, msg := range msgs {
+		content := &rawContent{number: msg.RoundNumber, data: msg.Data}
+		if msg.Broadcast {
+			err = r.BroadcastMessage(out, content)
+		} else {
+			err = r.SendMessage(out, content, msg.To)
+		}
+		if err != nil {
+			return r, err
+		}
+	}
+	helper, err := resumeDelegatedSession(r.ID, r.info, r.SSID(), r.Pool, next)
+	if err != nil {
+		return r, err
+	}
+	return newDelegatedRound(helper, r.delegate, r.info, next), nil
+}
+
+// CanFinalize implements round.Round. The MultiHandler only finalizes once all messages were stored.
+func (r *delegatedRound) CanFinalize() bool { return true }
+
+// MessageContent implements round.Round.
+func (r *delegatedRound) MessageContent() round.Content {
+	if !r.round.Message {
+		return nil
+	}
+	return &rawContent{number: r.round.Number}
+}
+
+// Number implements round.Round.
+func (r *delegatedRound) Number() round.Number { return r.round.Number }
+
+// BroadcastContent implements round.BroadcastRound.
+func (r *delegatedBroadcastRound) BroadcastContent() round.BroadcastContent {
+	return &rawContent{number: r.round.Number, reliable: r.round.Reliable}
+}
+
+// rawContent holds the encoded content of a delegated message, which is decoded by the delegate.
+type rawContent struct {
+	number   round.Number
+	reliable bool
+	data     cbor.RawMessage
+}
+
+func (c *rawContent) RoundNumber() round.Number { return c.number }
+
+func (c *rawContent) Reliable() bool { return c.reliable }
+
+func (c *rawContent) MarshalCBOR() ([]byte, error) {
+	return c.data.MarshalCBOR()
+}
+
+func (c *rawContent) UnmarshalCBOR(data []byte) error {
+	return c.data.UnmarshalCBOR(data)
+}
+
+// LocalDelegate implements RoundDelegate by running the rounds of a protocol in process.
+// It is the counterpart of DelegatedSession in the service computing the rounds,
+// which exposes its methods over the RPC transport of the deployment.
+//
+// The rounds of the protocol must use a session hash of the sw/hash package, whose transcript is sent along
+// with each round. The result of a session is only handed back as its PublicResult, if it has one.
+type LocalDelegate struct {
+	mtx   sync.Mutex
+	start StartFunc
+	// sessions holds the current round of each session, by SSID.
+	sessions map[string]round.Session
+}
+
+// NewLocalDelegate returns a LocalDelegate creating the sessions with start.
+func NewLocalDelegate(start StartFunc) *LocalDelegate {
+	return &LocalDelegate{
+		start:    start,
+		sessions: map[string]round.Session{},
+	}
+}
+
+// Start implements RoundDelegate.
+func (d *LocalDelegate) Start(sessionID []byte) (*DelegatedRound, error) {
+	r, err := d.start(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if _, ok := d.sessions[string(r.SSID())]; ok {
+		return nil, fmt.Errorf("protocol: session %x already started", r.SSID())
+	}
+	dr, err := describeRound(r)
+	if err != nil {
+		return nil, err
+	}
+	d.sessions[string(r.SSID())] = r
+	return dr, nil
+}
+
+// Store implements RoundDelegate.
+func (d *LocalDelegate) Store(ssid []byte, msg *DelegatedMessage) error {
+	r, err := d.session(ssid, msg.RoundNumber)
+	if err != nil {
+		return err
+	}
+	roundMsg, err := getRoundMessage(&Message{
+		From:      msg.From,
+		To:        msg.To,
+		Broadcast: msg.Broadcast,
+		Data:      msg.Data,
+	}, r)
+	if err != nil {
+		return err
+	}
+	if msg.Broadcast {
+		return r.(round.BroadcastRound).StoreBroadcastMessage(roundMsg)
+	}
+	if err := r.VerifyMessage(roundMsg); err != nil {
+		return err
+	}
+	return r.StoreMessage(roundMsg)
+}
+
+// Finalize implements RoundDelegate.
+func (d *LocalDelegate) Finalize(ssid []byte, number round.Number) (*DelegatedRound, []*DelegatedMessage, error) {
+	r, err := d.session(ssid, number)
+	if err != nil {
+		return nil, nil, err
+	}
+	out := make(chan *round.Message, r.N()+1)
+	next, err := r.Finalize(out)
+	close(out)
+	if err != nil {
+		return nil, nil, err
+	}
+	var msgs []*DelegatedMessage
+	for roundMsg := range out {
+		data, err := round.MarshalContent(roundMsg.Content)
+		if err != nil {
+			return nil, nil, err
+		}
+		msgs = append(msgs, &DelegatedMessage{
+			From:        roundMsg.From,
+			To:          roundMsg.To,
+			Broadcast:   roundMsg.Broadcast,
+			RoundNumber: roundMsg.Content.RoundNumber(),
+			Data:        data,
+		})
+	}
+
+	dr, err := describeRound(next)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	switch next.(type) {
+	case *round.Abort, *round.Output:
+		delete(d.sessions, string(ssid))
+	default:
+		d.sessions[string(ssid)] = next
+	}
+	return dr, msgs, nil
+}
+
+// session returns the current round of the session ssid, which must be number.
+func (d *LocalDelegate) session(ssid []byte, number round.Number) (round.Session, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	r, ok := d.sessions[string(ssid)]
+	if !ok {
+		return nil, fmt.Errorf("protocol: unknown session %x", ssid)
+	}
+	if r.Number() != number {
+		return nil, fmt.Errorf("protocol: session %x is in round %d, not %d", ssid, r.Number(), number)
+	}
+	return r, nil
+}
+
+// describeRound returns the public description of r.
+func describeRound(r round.Session) (*DelegatedRound, error) {
+	sessionHash, ok := r.Hash().(*sw_hash.Hash)
+	if !ok {
+		return nil, fmt.Errorf("protocol: session hash %T has no transcript", r.Hash())
+	}
+	dr := &DelegatedRound{
+		Info: DelegatedInfo{
+			ProtocolID:       r.ProtocolID(),
+			FinalRoundNumber: r.FinalRoundNumber(),
+			SelfID:           r.SelfID(),
+			PartyIDs:         r.PartyIDs(),
+			Threshold:        r.Threshold(),
+		},
+		SSID:       r.SSID(),
+		Transcript: sessionHash.Transcript(),
+		Number:     r.Number(),
+		Message:    r.MessageContent() != nil,
+	}
+	if r.Group() != nil {
+		dr.Info.Group = r.Group().Name()
+	}
+	if v, ok := r.(interface{ Version() round.Version }); ok {
+		dr.Info.Version = v.Version()
+	}
+	switch R := r.(type) {
+	case *round.Abort:
+		dr.Err, dr.Culprits = "aborted", R.Culprits
+		if R.Err != nil {
+			dr.Err = R.Err.Error()
+		}
+	case *round.Output:
+		dr.Result = &DelegatedResult{}
+		if public, ok := R.Result.(PublicResult); ok {
+			data, err := public.MarshalPublic()
+			if err != nil {
+				return nil, err
+			}
+			dr.Result.Public = data
+		}
+	case round.BroadcastRound:
+		if content := R.BroadcastContent(); content != nil {
+			dr.Broadcast, dr.Reliable = true, content.Reliable()
+		}
+	}
+	return dr, nil
+}
@@ -13,6 +13,8 @@ type Error struct {
 	Culprits []party.ID
 	// Err is the underlying error.
 	Err error
+	// Code is the reason for the error which may be shared with other parties.
+	Code ErrorCode
 }
 
 // Error implement error.
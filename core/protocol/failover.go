@@ -0,0 +1,316 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	ErrNotLeader      = errors.New("protocol: replica is not the leader")
+	ErrStaleTerm      = errors.New("protocol: checkpoint of a stale term")
+	ErrUnknownSession = errors.New("protocol: unknown session")
+)
+
+// Election is the leader election hook of a party running active/passive replicas.
+// It is typically backed by a lease in the shared keystore, or by a coordination service.
+type Election interface {
+	// Leader returns the replica currently leading, and its term. Each new leader must get a greater term.
+	Leader() (replica string, term uint64, err error)
+}
+
+// Checkpoint is the encrypted Snapshot of an in-flight session, written by the replica running it.
+type Checkpoint struct {
+	SSID []byte
+	// Session is the application's identifier of the session, such as the sign ID,
+	// from which a ResumeFunc can be recreated.
+	Session string
+	// Replica is the replica running the session, and Term its term when the checkpoint was written.
+	Replica string
+	Term    uint64
+	// Snapshot is the snapshot encrypted with Snapshot.Encrypt.
+	Snapshot []byte
+}
+
+// CheckpointStore keeps the checkpoints of in-flight sessions, shared by the replicas of a party.
+type CheckpointStore interface {
+	// Put stores c, replacing the checkpoint of the same session, unless it was written
+	// in a later term, in which case ErrStaleTerm is returned.
+	Put(c *Checkpoint) error
+	// Delete removes the checkpoint of the session. Deleting a missing checkpoint is not an error.
+	Delete(ssid []byte) error
+	// List returns all stored checkpoints.
+	List() ([]*Checkpoint, error)
+}
+
+// Failover runs the sessions of one replica of a party, so that a passive replica can take over
+// the in-flight sessions of the leader when it crashes, rather than stalling the whole committee.
+//
+// The leader registers each session it starts, which then checkpoints its state each time it moves
+// to a new round, before sending the messages of the round: a replica taking over from the last checkpoint
+// thus never computes again a round whose messages may have been delivered, which would equivocate.
+// Checkpoint additionally saves the messages received since, so that fewer of them are resent.
+// Messages are routed to the replica running the session with Accept, which fails with ErrNotLeader
+// on a passive replica, so that the transport can redirect them. Once a passive replica is elected,
+// TakeOver resumes the checkpointed sessions from the shared stores, and asks the other parties
+// to resend the messages of the current round.
+//
+// Checkpoints are fenced by the term of the election: a former leader which did not notice
+// it was replaced can no longer overwrite them, and abandons its sessions on its next checkpoint.
+type Failover struct {
+	replica  string
+	election Election
+	store    CheckpointStore
+	key      []byte
+
+	sessions map[string]*failoverSession
+	mtx      sync.Mutex
+}
+
+type failoverSession struct {
+	session string
+	handler *MultiHandler
+}
+
+// NewFailover returns the Failover of `replica`. Snapshots are encrypted with key, which must be
+// 32 bytes long and shared by all replicas of the party.
+func NewFailover(replica string, election Election, store CheckpointStore, key []byte) *Failover {
+	return &Failover{
+		replica:  replica,
+		election: election,
+		store:    store,
+		key:      key,
+		sessions: map[string]*failoverSession{},
+	}
+}
+
+// Register adds a session started by this replica, which must be the leader, and checkpoints it.
+// `session` is the application's identifier of the session, given to resolve in TakeOver.
+// It must be called before h accepts any message.
+func (f *Failover) Register(session string, h *MultiHandler) error {
+	if _, err := f.term(); err != nil {
+		return err
+	}
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if err := h.SetCheckpointer(f.checkpointer(session)); err != nil {
+		return err
+	}
+	f.sessions[string(h.ssid)] = &failoverSession{session: session, handler: h}
+	return nil
+}
+
+// Handler returns the handler of the session running on this replica.
+func (f *Failover) Handler(ssid []byte) (*MultiHandler, bool) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	s, ok := f.sessions[string(ssid)]
+	if !ok {
+		return nil, false
+	}
+	return s.handler, true
+}
+
+// Accept hands msg to the session it belongs to.
+// ErrNotLeader is returned on a passive replica, and ErrUnknownSession if the session does not run here.
+func (f *Failover) Accept(msg *Message) error {
+	if _, err := f.term(); err != nil {
+		return err
+	}
+	h, ok := f.Handler(msg.SSID)
+	if !ok {
+		return fmt.Errorf("%w: %x", ErrUnknownSession, msg.SSID)
+	}
+	h.Accept(msg)
+	return nil
+}
+
+// Checkpoint saves the snapshots of the sessions running on this replica, and removes those which are over.
+//
+// If this replica is no longer the leader, its sessions are abandoned without notifying the other parties,
+// since they are continued by the new leader, and ErrNotLeader or ErrStaleTerm is returned.
+func (f *Failover) Checkpoint() error {
+	_, err := f.term()
+	if err == nil {
+		err = f.checkpointAll()
+	}
+	if errors.Is(err, ErrNotLeader) || errors.Is(err, ErrStaleTerm) {
+		f.abandon()
+	}
+	return err
+}
+
+func (f *Failover) checkpointAll() error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for ssid, s := range f.sessions {
+		select {
+		case <-s.handler.Done():
+			if err := f.store.Delete(s.handler.ssid); err != nil {
+				return err
+			}
+			delete(f.sessions, ssid)
+			continue
+		default:
+		}
+		if err := s.handler.Checkpoint(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TakeOver resumes the sessions checkpointed by previous leaders, once this replica is the leader.
+// resolve returns the ResumeFunc of a checkpoint's session, from the stores shared with the previous leader.
+//
+// Each resumed handler first sends a Resend request for the current round, followed by the messages
+// it had already sent in that round, which the other parties drop as duplicates if they were delivered.
+// Sessions which cannot be resumed are removed from the store, and reported in the returned error.
+func (f *Failover) TakeOver(resolve func(session string) ResumeFunc) ([]*MultiHandler, error) {
+	if _, err := f.term(); err != nil {
+		return nil, err
+	}
+	checkpoints, err := f.store.List()
+	if err != nil {
+		return nil, fmt.Errorf("protocol: failover: %w", err)
+	}
+
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	var handlers []*MultiHandler
+	var errs []error
+	for _, c := range checkpoints {
+		if _, ok := f.sessions[string(c.SSID)]; ok {
+			continue
+		}
+		h, err := f.resume(c, resolve)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("session %s: %w", c.Session, err))
+			_ = f.store.Delete(c.SSID)
+			continue
+		}
+		f.sessions[string(c.SSID)] = &failoverSession{session: c.Session, handler: h}
+		// the checkpoint is written again in our term, so that the previous leader can no longer overwrite it
+		if err := h.Checkpoint(); err != nil && !errors.Is(err, ErrInvalidSnapshot) {
+			return handlers, err
+		}
+		handlers = append(handlers, h)
+	}
+	return handlers, errors.Join(errs...)
+}
+
+// resume resumes the session of c. Its restored round is checkpointed if it is finalized at once.
+func (f *Failover) resume(c *Checkpoint, resolve func(session string) ResumeFunc) (*MultiHandler, error) {
+	snapshot, err := DecryptSnapshot(c.Snapshot, f.key)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(snapshot.SSID, c.SSID) {
+		return nil, fmt.Errorf("%w: checkpoint of another session", ErrInvalidSnapshot)
+	}
+	resume := resolve(c.Session)
+	if resume == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSession, c.Session)
+	}
+	h, err := resumeMultiHandler(resume, snapshot, f.checkpointer(c.Session))
+	if err != nil {
+		return nil, err
+	}
+	resend, err := h.ResendRequest()
+	if err != nil {
+		return nil, err
+	}
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.send(resend)
+	current := h.currentRound.Number()
+	for _, msg := range h.sent {
+		if msg.RoundNumber == current {
+			h.send(msg)
+		}
+	}
+	return h, nil
+}
+
+// checkpointer returns the checkpointer of the handler of session, which stores its snapshots in the current term.
+// It fails with ErrNotLeader once this replica is no longer the leader, so that the handler is abandoned.
+func (f *Failover) checkpointer(session string) func(*Snapshot) error {
+	return func(snapshot *Snapshot) error {
+		term, err := f.term()
+		if err != nil {
+			return err
+		}
+		data, err := snapshot.Encrypt(f.key)
+		if err != nil {
+			return err
+		}
+		return f.store.Put(&Checkpoint{
+			SSID:     snapshot.SSID,
+			Session:  session,
+			Replica:  f.replica,
+			Term:     term,
+			Snapshot: data,
+		})
+	}
+}
+
+// term returns the current term if this replica is the leader, or ErrNotLeader.
+func (f *Failover) term() (uint64, error) {
+	leader, term, err := f.election.Leader()
+	if err != nil {
+		return 0, fmt.Errorf("protocol: failover: %w", err)
+	}
+	if leader != f.replica {
+		return 0, fmt.Errorf("%w: %s leads term %d", ErrNotLeader, leader, term)
+	}
+	return term, nil
+}
+
+// abandon stops the sessions of this replica without sending an abort to the other parties.
+func (f *Failover) abandon() {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for ssid, s := range f.sessions {
+		s.handler.Abandon(ErrNotLeader)
+		delete(f.sessions, ssid)
+	}
+}
+
+// InMemoryCheckpointStore is a CheckpointStore which does not survive restarts,
+// and can only be shared by replicas running in the same process.
+type InMemoryCheckpointStore struct {
+	checkpoints map[string]*Checkpoint
+	mtx         sync.Mutex
+}
+
+func NewInMemoryCheckpointStore() *InMemoryCheckpointStore {
+	return &InMemoryCheckpointStore{checkpoints: map[string]*Checkpoint{}}
+}
+
+func (s *InMemoryCheckpointStore) Put(c *Checkpoint) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if prev, ok := s.checkpoints[string(c.SSID)]; ok && prev.Term > c.Term {
+		return fmt.Errorf("%w: %d < %d", ErrStaleTerm, c.Term, prev.Term)
+	}
+	s.checkpoints[string(c.SSID)] = c
+	return nil
+}
+
+func (s *InMemoryCheckpointStore) Delete(ssid []byte) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.checkpoints, string(ssid))
+	return nil
+}
+
+func (s *InMemoryCheckpointStore) List() ([]*Checkpoint, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	checkpoints := make([]*Checkpoint, 0, len(s.checkpoints))
+	for _, c := range s.checkpoints {
+		checkpoints = append(checkpoints, c)
+	}
+	return checkpoints, nil
+}
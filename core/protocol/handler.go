@@ -5,10 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/fxamacker/cbor/v2"
 	"github.com/mr-shifu/mpc-lib/core/hash"
 	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
 	"github.com/mr-shifu/mpc-lib/lib/round"
 )
 
@@ -19,6 +21,24 @@ import (
 // An optional sessionID can be provided, which should unique among all protocol executions.
 type StartFunc func(sessionID []byte) (round.Session, error)
 
+// ErrSlowConsumer is returned by a handler which aborted because the messages it sent were not read from Listen.
+var ErrSlowConsumer = errors.New("protocol: outgoing messages are not read")
+
+// maxPendingRounds is the number of rounds of messages a handler holds for a consumer of Listen which
+// does not keep up, beyond the buffer of Listen, before aborting with ErrSlowConsumer.
+const maxPendingRounds = 8
+
+// VerificationPolicy decides when a MultiHandler aborts after a message failed verification.
+type VerificationPolicy uint8
+
+const (
+	// AbortOnFirstError aborts as soon as a message is rejected, blaming its sender.
+	AbortOnFirstError VerificationPolicy = iota
+	// CollectAllErrors keeps verifying the messages of the round, and aborts once they were all received,
+	// blaming every party whose message was rejected.
+	CollectAllErrors
+)
+
 // Handler represents some kind of handler for a protocol.
 type Handler interface {
 	// Result should return the result of running the protocol, or an error
@@ -40,15 +60,75 @@ type MultiHandler struct {
 	rounds          map[round.Number]round.Session
 	err             *Error
 	result          interface{}
-	messages        map[round.Number]map[party.ID]*Message
-	broadcast       map[round.Number]map[party.ID]*Message
+	messages        *queue
+	broadcast       *queue
 	broadcastHashes map[round.Number][]byte
+	sent            []*Message
+	confirmed       map[party.ID]bool
+	lastSeen        map[party.ID]time.Time
 	out             chan *Message
 	mtx             sync.Mutex
+
+	// policy is the VerificationPolicy, and failures the rejected messages of the current round it collected.
+	policy   VerificationPolicy
+	failures map[party.ID]error
+
+	// budget is the number of bytes the session may retain, and sentSize the number of bytes of sent.
+	budget   int
+	sentSize int
+
+	// builds are the builds announced in handshakes, handshakes the hashes of these messages,
+	// and buildPolicy the policy applied to them.
+	builds      map[party.ID]BuildInfo
+	handshakes  map[party.ID][]byte
+	buildPolicy BuildPolicy
+
+	// pending holds the messages which did not fit in out, at most maxPendingRounds times the size of out.
+	// They are forwarded by sendLoop without holding mtx, so that a slow consumer of Listen
+	// does not block the computation of the next round.
+	pending []*Message
+	sending bool
+	closing bool
+	// stopped is closed by Stop, so that sendLoop gives up on a consumer which no longer reads.
+	stopped chan struct{}
+
+	// signer signs the messages we send, and verifier checks the signatures of the messages we receive.
+	signer   MessageSigner
+	verifier MessageVerifier
+	// droppedResends counts the messages which were not sent again because the send buffer was full.
+	droppedResends int
+	// checkpointer persists the state of each new round before its messages are sent, see SetCheckpointer.
+	checkpointer func(*Snapshot) error
+	// peerErrorDetail is the level of detail of the errors sent to other parties when aborting.
+	peerErrorDetail DetailLevel
+	// debugInvariants is set by WithDebugInvariants.
+	debugInvariants bool
+	// done is closed once the protocol completed or aborted.
+	done chan struct{}
+
+	// the following are read without holding mtx, so that heartbeats are not blocked by a long round.
+	ssid        []byte
+	selfID      party.ID
+	protocolID  string
+	roundNumber atomic.Uint32
+	finished    atomic.Bool
+}
+
+// HandlerOption configures a MultiHandler. Unlike the Set methods, options are applied
+// before the first round is finalized.
+type HandlerOption func(h *MultiHandler)
+
+// WithDebugInvariants enables checking the invariants documented by each round (see round.InvariantChecker)
+// after it is reached, aborting with the first violated invariant.
+// It is intended for debugging integrations.
+func WithDebugInvariants() HandlerOption {
+	return func(h *MultiHandler) {
+		h.debugInvariants = true
+	}
 }
 
 // NewMultiHandler expects a StartFunc for the desired protocol. It returns a handler that the user can interact with.
-func NewMultiHandler(create StartFunc, sessionID []byte) (*MultiHandler, error) {
+func NewMultiHandler(create StartFunc, sessionID []byte, opts ...HandlerOption) (*MultiHandler, error) {
 	r, err := create(sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("protocol: failed to create round: %w", err)
@@ -56,19 +136,44 @@ func NewMultiHandler(create StartFunc, sessionID []byte) (*MultiHandler, error)
 	h := &MultiHandler{
 		currentRound:    r,
 		rounds:          map[round.Number]round.Session{r.Number(): r},
-		messages:        newQueue(r.OtherPartyIDs(), r.FinalRoundNumber()),
-		broadcast:       newQueue(r.OtherPartyIDs(), r.FinalRoundNumber()),
+		messages:        newQueue(r.PartyIDs(), r.FinalRoundNumber()),
+		broadcast:       newQueue(r.PartyIDs(), r.FinalRoundNumber()),
 		broadcastHashes: map[round.Number][]byte{},
 		out:             make(chan *Message, 2*r.N()),
+		done:            make(chan struct{}),
+		stopped:         make(chan struct{}),
+		ssid:            r.SSID(),
+		selfID:          r.SelfID(),
+		protocolID:      r.ProtocolID(),
+	}
+	for _, opt := range opts {
+		opt(h)
 	}
+	h.roundNumber.Store(uint32(r.Number()))
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	defer h.recoverPoolPanic()
 	h.finalize()
 	return h, nil
 }
 
+// SetVerificationPolicy changes when the handler aborts after a message failed verification.
+// It should be called before any message is accepted.
+func (h *MultiHandler) SetVerificationPolicy(policy VerificationPolicy) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.policy = policy
+}
+
 // Result returns the protocol result if the protocol completed successfully. Otherwise an error is returned.
 func (h *MultiHandler) Result() (interface{}, error) {
 	h.mtx.Lock()
 	defer h.mtx.Unlock()
+	return h.outcome()
+}
+
+// outcome implements Result. It must be called while holding mtx.
+func (h *MultiHandler) outcome() (interface{}, error) {
 	if h.result != nil {
 		return h.result, nil
 	}
@@ -78,6 +183,11 @@ func (h *MultiHandler) Result() (interface{}, error) {
 	return nil, errors.New("protocol: not finished")
 }
 
+// Done returns a channel which is closed once the protocol completed or aborted, after which Result returns its outcome.
+func (h *MultiHandler) Done() <-chan struct{} {
+	return h.done
+}
+
 // Listen returns a channel with outgoing messages that must be sent to other parties.
 // The message received should be _reliably_ broadcast if msg.Broadcast is true.
 // The channel is closed when either an error occurs or the protocol detects an error.
@@ -89,6 +199,12 @@ func (h *MultiHandler) Listen() <-chan *Message {
 
 // CanAccept returns true if the message is designated for this protocol protocol execution.
 func (h *MultiHandler) CanAccept(msg *Message) bool {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.canAccept(msg)
+}
+
+func (h *MultiHandler) canAccept(msg *Message) bool {
 	r := h.currentRound
 	if msg == nil {
 		return false
@@ -115,6 +231,11 @@ func (h *MultiHandler) CanAccept(msg *Message) bool {
 		return false
 	}
 
+	// retransmission requests, heartbeats and handshakes are always accepted
+	if msg.IsResend() || msg.IsHeartbeat() || msg.IsHandshake() {
+		return true
+	}
+
 	// check if message for unexpected round
 	if msg.RoundNumber > r.FinalRoundNumber() {
 		return false
@@ -131,34 +252,61 @@ func (h *MultiHandler) CanAccept(msg *Message) bool {
 // and an error is returned by Result().
 //
 // This function may be called concurrently from different threads but may block until all previous calls have finished.
+// Messages are therefore handed to the rounds one at a time, as required by round.Round.
 func (h *MultiHandler) Accept(msg *Message) {
 	h.mtx.Lock()
 	defer h.mtx.Unlock()
+	defer h.recoverPoolPanic()
+
+	if !h.canAccept(msg) || !h.verified(msg) {
+		return
+	}
+	// handshakes are recorded even once we are done, so that all parties bind the same ones to the transcript
+	if msg.IsHandshake() {
+		h.acceptHandshake(msg)
+		return
+	}
+	// exit early if we are already done
+	if h.err != nil || h.result != nil {
+		return
+	}
 
-	// exit early if the message is bad, or if we are already done
-	if !h.CanAccept(msg) || h.err != nil || h.result != nil || h.duplicate(msg) {
+	h.markSeen(msg.From)
+	if msg.IsHeartbeat() {
+		return
+	}
+
+	// a Resend message is served from the messages we already sent
+	if msg.IsResend() {
+		h.resend(msg.From, msg.Data)
+		return
+	}
+
+	if h.duplicate(msg) {
 		return
 	}
 
 	// a msg with roundNumber 0 is considered an abort from another party
 	if msg.RoundNumber == 0 {
-		h.abort(fmt.Errorf("aborted by other party with error: \"%s\"", msg.Data), msg.From)
+		h.abort(fmt.Errorf("%w with error: \"%s\"", errAbortedByPeer, msg.Data), msg.From)
 		return
 	}
 
+	if err := h.reserve(msg.size()); err != nil {
+		h.abort(err)
+		return
+	}
 	h.store(msg)
 	if h.currentRound.Number() != msg.RoundNumber {
 		return
 	}
 
 	if msg.Broadcast {
-		if err := h.verifyBroadcastMessage(msg); err != nil {
-			h.abort(err, msg.From)
+		if err := h.verifyBroadcastMessage(msg); err != nil && h.reject(err, msg.From) {
 			return
 		}
 	} else {
-		if err := h.verifyMessage(msg); err != nil {
-			h.abort(err, msg.From)
+		if err := h.verifyMessage(msg); err != nil && h.reject(err, msg.From) {
 			return
 		}
 	}
@@ -189,7 +337,7 @@ func (h *MultiHandler) verifyBroadcastMessage(msg *Message) error {
 	}
 
 	// otherwise, we can try to handle the p2p message that may be stored.
-	msg = h.messages[msg.RoundNumber][msg.From]
+	msg = h.messages.get(msg.RoundNumber, msg.From)
 	if msg == nil {
 		return nil
 	}
@@ -205,10 +353,14 @@ func (h *MultiHandler) verifyMessage(msg *Message) error {
 		return nil
 	}
 
+	// the sender was already rejected in this round
+	if _, ok = h.failures[msg.From]; ok {
+		return nil
+	}
+
 	// exit if we don't yet have the broadcast message
 	if _, ok = r.(round.BroadcastRound); ok {
-		q := h.broadcast[msg.RoundNumber]
-		if q == nil || q[msg.From] == nil {
+		if h.broadcast.get(msg.RoundNumber, msg.From) == nil {
 			return nil
 		}
 	}
@@ -231,12 +383,16 @@ func (h *MultiHandler) verifyMessage(msg *Message) error {
 }
 
 func (h *MultiHandler) finalize() {
-	// only finalize if we have received all messages
-	if !h.receivedAll() {
+	// only finalize if we have received all messages, and the handshakes required by the build policy
+	if !h.receivedAll() || !h.receivedHandshakes() {
+		return
+	}
+	if len(h.failures) > 0 {
+		h.abortFailures()
 		return
 	}
 	if !h.checkBroadcastHash() {
-		h.abort(errors.New("broadcast verification failed"))
+		h.abort(errBroadcastVerification)
 		return
 	}
 
@@ -250,10 +406,18 @@ func (h *MultiHandler) finalize() {
 		h.abort(err, h.currentRound.SelfID())
 		return
 	}
+	if h.debugInvariants {
+		if err := round.CheckInvariants(r); err != nil {
+			h.abort(err, r.SelfID())
+			return
+		}
+	}
 
-	// forward messages with the correct header.
+	// forward messages with the correct header, once they fit in the budget.
+	msgs := make([]*Message, 0, len(out))
+	size := 0
 	for roundMsg := range out {
-		data, err := cbor.Marshal(roundMsg.Content)
+		data, err := round.MarshalContent(roundMsg.Content)
 		if err != nil {
 			panic(fmt.Errorf("failed to marshal round message: %w", err))
 		}
@@ -267,19 +431,50 @@ func (h *MultiHandler) finalize() {
 			Broadcast:             roundMsg.Broadcast,
 			BroadcastVerification: h.broadcastHashes[r.Number()-1],
 		}
+		if err := h.sign(msg); err != nil {
+			h.abort(err, r.SelfID())
+			return
+		}
+		msgs = append(msgs, msg)
+		size += msg.size()
+		if msg.Broadcast {
+			size += msg.size()
+		}
+	}
+	if err = h.reserve(size); err != nil {
+		h.abort(err)
+		return
+	}
+	for _, msg := range msgs {
 		if msg.Broadcast {
 			h.store(msg)
 		}
-		h.out <- msg
+		h.sent = append(h.sent, msg)
+		h.sentSize += msg.size()
 	}
 
 	roundNumber := r.Number()
 	// if we get a round with the same number, we can safely assume that we got the same one.
 	if _, ok := h.rounds[roundNumber]; ok {
+		h.release(msgs)
 		return
 	}
 	h.rounds[roundNumber] = r
 	h.currentRound = r
+	h.roundNumber.Store(uint32(roundNumber))
+	h.failures = nil
+	// messages of previous rounds were processed, and are only needed for the transcript
+	h.messages.release(roundNumber)
+	h.broadcast.release(roundNumber)
+
+	// the new round is persisted before its messages are released, so that a session resumed
+	// from its last checkpoint never computes again a round whose messages may have been delivered
+	if !h.finalRound() && h.persist() != nil {
+		return
+	}
+	if h.release(msgs); h.err != nil {
+		return
+	}
 
 	// either we get the current round, the next one, or one of the two final ones
 	switch R := r.(type) {
@@ -297,25 +492,18 @@ func (h *MultiHandler) finalize() {
 
 	if _, ok := r.(round.BroadcastRound); ok {
 		// handle queued broadcast messages, which will then check the subsequent normal message
-		for id, m := range h.broadcast[roundNumber] {
-			if m == nil || id == r.SelfID() {
+		for _, m := range h.broadcast.messages(roundNumber) {
+			if m.From == r.SelfID() {
 				continue
 			}
-			// if false, we aborted and so we return
-			if err = h.verifyBroadcastMessage(m); err != nil {
-				h.abort(err, m.From)
+			if err = h.verifyBroadcastMessage(m); err != nil && h.reject(err, m.From) {
 				return
 			}
 		}
 	} else {
 		// handle simple queued messages
-		for _, m := range h.messages[roundNumber] {
-			if m == nil {
-				continue
-			}
-			// if false, we aborted and so we return
-			if err = h.verifyMessage(m); err != nil {
-				h.abort(err, m.From)
+		for _, m := range h.messages.messages(roundNumber) {
+			if err = h.verifyMessage(m); err != nil && h.reject(err, m.From) {
 				return
 			}
 		}
@@ -325,30 +513,183 @@ func (h *MultiHandler) finalize() {
 	h.finalize()
 }
 
+// release sends msgs, and stops if the protocol aborted meanwhile.
+func (h *MultiHandler) release(msgs []*Message) {
+	for _, msg := range msgs {
+		h.send(msg)
+		if h.err != nil {
+			return
+		}
+	}
+}
+
+// finalRound returns whether the current round is the result or the abort of the protocol.
+func (h *MultiHandler) finalRound() bool {
+	switch h.currentRound.(type) {
+	case *round.Abort, *round.Output:
+		return true
+	}
+	return false
+}
+
 func (h *MultiHandler) abort(err error, culprits ...party.ID) {
 	if err != nil {
 		h.err = &Error{
 			Culprits: culprits,
 			Err:      err,
+			Code:     classify(err, party.NewIDSlice(culprits).Remove(h.selfID)),
 		}
-		select {
-		case h.out <- &Message{
+		msg := &Message{
 			SSID:     h.currentRound.SSID(),
 			From:     h.currentRound.SelfID(),
 			Protocol: h.currentRound.ProtocolID(),
-			Data:     []byte(h.err.Error()),
-		}:
+			Data:     []byte(Redact(*h.err, h.peerErrorDetail)),
+		}
+		// the other parties learn of the abort from their timeouts if we cannot sign it
+		if h.sign(msg) == nil {
+			h.send(msg)
+		}
+	}
+	h.finish()
+}
+
+// finish closes out once the pending messages are sent, and done. It must be called while holding mtx.
+func (h *MultiHandler) finish() {
+	if h.finished.Swap(true) {
+		return
+	}
+	h.closing = true
+	if !h.sending {
+		close(h.out)
+	}
+	close(h.done)
+}
+
+// reject handles a message from `from` which failed verification according to the policy.
+// It returns true if the protocol was aborted.
+func (h *MultiHandler) reject(err error, from party.ID) bool {
+	if h.policy == AbortOnFirstError {
+		h.abort(err, from)
+		return true
+	}
+	if h.failures == nil {
+		h.failures = map[party.ID]error{}
+	}
+	if _, ok := h.failures[from]; !ok {
+		h.failures[from] = err
+	}
+	return false
+}
+
+// abortFailures aborts with the collected failures of the current round, blaming all their senders.
+func (h *MultiHandler) abortFailures() {
+	culprits := make([]party.ID, 0, len(h.failures))
+	errs := make([]error, 0, len(h.failures))
+	for _, id := range h.currentRound.PartyIDs() {
+		if err, ok := h.failures[id]; ok {
+			culprits = append(culprits, id)
+			errs = append(errs, fmt.Errorf("%s: %w", id, err))
+		}
+	}
+	h.abort(errors.Join(errs...), culprits...)
+}
+
+// recoverPoolPanic aborts the protocol if a job of the pool panicked while a round was processing a message,
+// so that a bug in a round only fails its session. Other panics are raised again.
+// It must be deferred while holding mtx.
+func (h *MultiHandler) recoverPoolPanic() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	err, ok := r.(*pool.PanicError)
+	if !ok {
+		panic(r)
+	}
+	if h.err == nil && h.result == nil {
+		h.abort(err, h.selfID)
+	}
+}
+
+// send hands msg to out, or queues it for sendLoop if out is full. It never blocks, and must be called while holding mtx.
+// If the queue is full, the protocol aborts with ErrSlowConsumer; the abort message is still queued.
+func (h *MultiHandler) send(msg *Message) {
+	if h.closing {
+		return
+	}
+	if !h.sending {
+		select {
+		case h.out <- msg:
+			return
 		default:
 		}
+		h.sending = true
+		go h.sendLoop()
+	}
+	if len(h.pending) >= maxPendingRounds*cap(h.out) && h.err == nil && h.result == nil {
+		h.abort(ErrSlowConsumer)
+		return
+	}
+	h.pending = append(h.pending, msg)
+}
 
+// sendLoop forwards pending messages to out in order, and closes it if the protocol finished in the meantime.
+// It drops the pending messages and returns once Stop is called, even if the consumer of Listen stopped reading.
+func (h *MultiHandler) sendLoop() {
+	for {
+		h.mtx.Lock()
+		if len(h.pending) == 0 {
+			h.sending = false
+			if h.closing {
+				close(h.out)
+			}
+			h.mtx.Unlock()
+			return
+		}
+		msg := h.pending[0]
+		h.pending[0] = nil
+		h.pending = h.pending[1:]
+		h.mtx.Unlock()
+
+		select {
+		case h.out <- msg:
+		case <-h.stopped:
+			h.mtx.Lock()
+			h.pending = nil
+			h.mtx.Unlock()
+		}
 	}
-	close(h.out)
+}
+
+// Abandon ends the execution of the protocol with err, without alerting the other users,
+// such as when it is continued by another replica of the party. The messages not yet read from Listen are still sent.
+func (h *MultiHandler) Abandon(err error) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.abandon(err)
+}
+
+// abandon implements Abandon. It must be called while holding mtx.
+func (h *MultiHandler) abandon(err error) {
+	if h.err != nil || h.result != nil {
+		return
+	}
+	h.err = &Error{Err: err, Code: classify(err, nil)}
+	h.finish()
 }
 
 // Stop cancels the current execution of the protocol, and alerts the other users.
+// The messages not yet read from Listen are dropped, and the channel is closed.
 func (h *MultiHandler) Stop() {
-	if h.err != nil || h.result != nil {
-		h.abort(errors.New("aborted by user"), h.currentRound.SelfID())
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if h.err == nil && h.result == nil {
+		h.abort(errAbortedByUser, h.selfID)
+	}
+	select {
+	case <-h.stopped:
+	default:
+		close(h.stopped)
 	}
 }
 
@@ -361,12 +702,11 @@ func (h *MultiHandler) receivedAll() bool {
 	number := r.Number()
 	// check all broadcast messages
 	if _, ok := r.(round.BroadcastRound); ok {
-		if h.broadcast[number] == nil {
+		if !h.broadcast.expects(number) {
 			return true
 		}
 		for _, id := range r.PartyIDs() {
-			msg := h.broadcast[number][id]
-			if msg == nil {
+			if h.broadcast.get(number, id) == nil {
 				return false
 			}
 		}
@@ -375,7 +715,7 @@ func (h *MultiHandler) receivedAll() bool {
 		if h.broadcastHashes[number] == nil {
 			hashState := r.Hash()
 			for _, id := range r.PartyIDs() {
-				msg := h.broadcast[number][id]
+				msg := h.broadcast.get(number, id)
 				_ = hashState.WriteAny(&hash.BytesWithDomain{
 					TheDomain: "Message",
 					Bytes:     msg.Hash(),
@@ -387,11 +727,11 @@ func (h *MultiHandler) receivedAll() bool {
 
 	// check all normal messages
 	if expectsNormalMessage(r) {
-		if h.messages[number] == nil {
+		if !h.messages.expects(number) {
 			return true
 		}
 		for _, id := range r.OtherPartyIDs() {
-			if h.messages[number][id] == nil {
+			if h.messages.get(number, id) == nil {
 				return false
 			}
 		}
@@ -403,30 +743,19 @@ func (h *MultiHandler) duplicate(msg *Message) bool {
 	if msg.RoundNumber == 0 {
 		return false
 	}
-	var q map[party.ID]*Message
-	if msg.Broadcast {
-		q = h.broadcast[msg.RoundNumber]
-	} else {
-		q = h.messages[msg.RoundNumber]
-	}
 	// technically, we already received the nil message since it is not expected :)
-	if q == nil {
-		return true
+	if msg.Broadcast {
+		return h.broadcast.has(msg.RoundNumber, msg.From)
 	}
-	return q[msg.From] != nil
+	return h.messages.has(msg.RoundNumber, msg.From)
 }
 
 func (h *MultiHandler) store(msg *Message) {
-	var q map[party.ID]*Message
 	if msg.Broadcast {
-		q = h.broadcast[msg.RoundNumber]
+		h.broadcast.set(msg)
 	} else {
-		q = h.messages[msg.RoundNumber]
-	}
-	if q == nil || q[msg.From] != nil {
-		return
+		h.messages.set(msg)
 	}
-	q[msg.From] = msg
 }
 
 // getRoundMessage attempts to unmarshal a raw Message for round `r` in a round.Message.
@@ -438,16 +767,16 @@ func getRoundMessage(msg *Message, r round.Session) (round.Message, error) {
 	if msg.Broadcast {
 		b, ok := r.(round.BroadcastRound)
 		if !ok {
-			return round.Message{}, errors.New("got broadcast message when none was expected")
+			return round.Message{}, fmt.Errorf("%w: got broadcast message when none was expected", errInvalidMessage)
 		}
 		content = b.BroadcastContent()
 	} else {
 		content = r.MessageContent()
 	}
 
-	// unmarshal message
-	if err := cbor.Unmarshal(msg.Data, content); err != nil {
-		return round.Message{}, fmt.Errorf("failed to unmarshal: %w", err)
+	// unmarshal message, rejecting degenerate values
+	if err := round.DecodeContent(msg.Data, content); err != nil {
+		return round.Message{}, fmt.Errorf("%w: %w", errInvalidMessage, err)
 	}
 	roundMsg := round.Message{
 		From:      msg.From,
@@ -467,31 +796,19 @@ func (h *MultiHandler) checkBroadcastHash() bool {
 		return true
 	}
 
-	for _, msg := range h.messages[number] {
-		if msg != nil && !bytes.Equal(previousHash, msg.BroadcastVerification) {
+	for _, msg := range h.messages.messages(number) {
+		if !bytes.Equal(previousHash, msg.BroadcastVerification) {
 			return false
 		}
 	}
-	for _, msg := range h.broadcast[number] {
-		if msg != nil && !bytes.Equal(previousHash, msg.BroadcastVerification) {
+	for _, msg := range h.broadcast.messages(number) {
+		if !bytes.Equal(previousHash, msg.BroadcastVerification) {
 			return false
 		}
 	}
 	return true
 }
 
-func newQueue(senders []party.ID, rounds round.Number) map[round.Number]map[party.ID]*Message {
-	n := len(senders)
-	q := make(map[round.Number]map[party.ID]*Message, rounds)
-	for i := round.Number(2); i <= rounds; i++ {
-		q[i] = make(map[party.ID]*Message, n)
-		for _, id := range senders {
-			q[i][id] = nil
-		}
-	}
-	return q
-}
-
 func (h *MultiHandler) String() string {
-	return fmt.Sprintf("party: %s, protocol: %s", h.currentRound.SelfID(), h.currentRound.ProtocolID())
+	return fmt.Sprintf("party: %s, protocol: %s", h.selfID, h.protocolID)
 }
@@ -0,0 +1,223 @@
+package protocol
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/hash"
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/keystore"
+	"github.com/mr-shifu/mpc-lib/pkg/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chattyContent is the content of the messages of chattyRound.
+type chattyContent struct {
+	Round round.Number
+}
+
+func (c *chattyContent) RoundNumber() round.Number { return c.Round }
+
+// chattyBroadcast is the content of the broadcast messages of chattyBroadcastRound.
+type chattyBroadcast struct {
+	round.NormalBroadcastContent
+	Round round.Number
+	From  party.ID
+}
+
+func (c *chattyBroadcast) RoundNumber() round.Number { return c.Round }
+
+// chattyRound is a round of a test protocol which expects no message, and sends one to every other party,
+// so that a handler runs all its rounds at once.
+// If broadcast is set, it broadcasts the message instead, and the next round waits for the broadcasts of all parties.
+type chattyRound struct {
+	*round.Helper
+	number    round.Number
+	broadcast bool
+	// violated is the round whose invariant does not hold, if any.
+	violated round.Number
+}
+
+// chattyBroadcastRound is a round of the broadcast variant of the chatty protocol, after the first one.
+type chattyBroadcastRound struct {
+	*chattyRound
+}
+
+func (r *chattyBroadcastRound) BroadcastContent() round.BroadcastContent {
+	return &chattyBroadcast{Round: r.number}
+}
+
+func (chattyRound) VerifyMessage(round.Message) error         { return nil }
+func (chattyRound) StoreMessage(round.Message) error          { return nil }
+func (chattyRound) StoreBroadcastMessage(round.Message) error { return nil }
+func (chattyRound) CanFinalize() bool                         { return true }
+func (chattyRound) MessageContent() round.Content             { return nil }
+func (r *chattyRound) Number() round.Number                   { return r.number }
+func (r *chattyRound) Invariants() []round.Invariant {
+	return []round.Invariant{{Name: "not violated", Check: func() error {
+		if r.number == r.violated {
+			return errors.New("violated")
+		}
+		return nil
+	}}}
+}
+func (r *chattyRound) Finalize(out chan<- *round.Message) (round.Session, error) {
+	if r.number == r.FinalRoundNumber() {
+		return r.ResultRound(true), nil
+	}
+	next := &chattyRound{Helper: r.Helper, number: r.number + 1, broadcast: r.broadcast, violated: r.violated}
+	if r.broadcast {
+		if err := r.BroadcastMessage(out, &chattyBroadcast{Round: next.number, From: r.SelfID()}); err != nil {
+			return r, err
+		}
+		return &chattyBroadcastRound{chattyRound: next}, nil
+	}
+	for _, id := range r.OtherPartyIDs() {
+		if err := r.SendMessage(out, &chattyContent{Round: next.number}, id); err != nil {
+			return r, err
+		}
+	}
+	return next, nil
+}
+
+// startChatty returns the StartFunc of a chatty protocol of the given number of rounds between ids.
+func startChatty(t *testing.T, rounds round.Number, self party.ID, ids party.IDSlice) StartFunc {
+	return startChattyProtocol(t, rounds, self, ids, false)
+}
+
+// startChattyProtocol returns the StartFunc of a chatty protocol, whose messages are broadcast if broadcast is set.
+func startChattyProtocol(t *testing.T, rounds round.Number, self party.ID, ids party.IDSlice, broadcast bool) StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		hashes := hash.NewHashManager(keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts()))
+		info := round.Info{
+			ProtocolID:       "chatty",
+			FinalRoundNumber: rounds,
+			SelfID:           self,
+			PartyIDs:         ids,
+			Group:            curve.Secp256k1{},
+		}
+		helper, err := round.NewSession("chatty", info, sessionID, nil, hashes.NewHasher("chatty", keyopts.New().WithKeyID("chatty").WithPartyID(string(self))))
+		require.NoError(t, err)
+		return &chattyRound{Helper: helper, number: 1, broadcast: broadcast}, nil
+	}
+}
+
+func TestHandlerPendingBound(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	// each round sends one message, so that the buffer of Listen and the pending queue overflow
+	// long before the last round
+	h, err := NewMultiHandler(startChatty(t, 8*maxPendingRounds, "a", ids), nil)
+	require.NoError(t, err)
+	_, err = h.Result()
+	require.ErrorIs(t, err, ErrSlowConsumer)
+	assert.Equal(t, CodeResourceExhausted, Code(err))
+
+	h.mtx.Lock()
+	pending := len(h.pending)
+	h.mtx.Unlock()
+	assert.LessOrEqual(t, pending, maxPendingRounds*cap(h.out)+1)
+
+	// the messages are still delivered in order, ending with the abort message, once they are read
+	var last *Message
+	number := round.Number(1)
+	for msg := range h.Listen() {
+		if msg.RoundNumber != 0 {
+			number++
+			require.Equal(t, number, msg.RoundNumber)
+		}
+		last = msg
+	}
+	require.NotNil(t, last)
+	assert.Equal(t, round.Number(0), last.RoundNumber)
+}
+
+func TestHandlerStopUnblocksSend(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	// the protocol completes, but its messages do not fit in the buffer of Listen
+	h, err := NewMultiHandler(startChatty(t, 2*maxPendingRounds, "a", ids), nil)
+	require.NoError(t, err)
+	_, err = h.Result()
+	require.NoError(t, err)
+
+	// nobody reads the messages: Stop drops them, and lets the send loop close the channel
+	h.Stop()
+	h.Stop()
+	require.Eventually(t, func() bool {
+		h.mtx.Lock()
+		defer h.mtx.Unlock()
+		return !h.sending
+	}, time.Second, time.Millisecond)
+	n := 0
+	for range h.Listen() {
+		n++
+	}
+	assert.LessOrEqual(t, n, cap(h.out))
+}
+
+func TestHandlerCompletes(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	h, err := NewMultiHandler(startChatty(t, 3, "a", ids), nil)
+	require.NoError(t, err)
+	result, err := h.Result()
+	require.NoError(t, err)
+	assert.Equal(t, true, result)
+	select {
+	case <-h.Done():
+	default:
+		t.Fatal("Done is not closed")
+	}
+	n := 0
+	for range h.Listen() {
+		n++
+	}
+	assert.Equal(t, 2, n)
+}
+
+func TestHandlerDebugInvariants(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	start := func(sessionID []byte) (round.Session, error) {
+		r, err := startChatty(t, 3, "a", ids)(sessionID)
+		r.(*chattyRound).violated = 2
+		return r, err
+	}
+
+	// invariants are only checked in debug mode
+	h, err := NewMultiHandler(start, nil)
+	require.NoError(t, err)
+	_, err = h.Result()
+	require.NoError(t, err)
+
+	h, err = NewMultiHandler(start, nil, WithDebugInvariants())
+	require.NoError(t, err)
+	_, err = h.Result()
+	require.ErrorIs(t, err, round.ErrInvariantViolated)
+	var invariantErr *round.InvariantError
+	require.ErrorAs(t, err, &invariantErr)
+	assert.Equal(t, round.Number(2), invariantErr.Round)
+}
+
+func TestHandlerPeerErrorDetail(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	abortMessage := func(opts ...HandlerOption) string {
+		h, err := NewMultiHandler(startChattyProtocol(t, 3, "a", ids, true), nil, opts...)
+		require.NoError(t, err)
+		h.Stop()
+		var data string
+		for msg := range h.Listen() {
+			if msg.RoundNumber == 0 && !msg.IsResend() && !msg.IsHeartbeat() && !msg.IsHandshake() {
+				data = string(msg.Data)
+			}
+		}
+		return data
+	}
+
+	// other parties only learn the code of the error by default
+	assert.Equal(t, Redact(errAbortedByUser, DetailCode), abortMessage())
+	assert.Equal(t, Redact(errAbortedByUser, DetailCode), abortMessage(WithPeerErrorDetail(DetailCode)))
+	assert.Contains(t, abortMessage(WithPeerErrorDetail(DetailFull)), errAbortedByUser.Error())
+}
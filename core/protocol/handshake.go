@@ -0,0 +1,271 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+)
+
+// ErrBuildRejected is returned when the build announced by a party is refused by the BuildPolicy.
+var ErrBuildRejected = errors.New("protocol: build rejected")
+
+// HandshakeRoundNumber is the round number of a handshake message, which announces the sender's build.
+//
+// Handshakes are handled by the framework rather than by the protocol's rounds, and should be sent along
+// with the messages of the first round. A handler with a BuildPolicy waits for the handshakes of all parties,
+// its own included, before finalizing any round after the first.
+const HandshakeRoundNumber round.Number = ResendRoundNumber - 3
+
+// modulePath is the path of this library, used to find its version in the build information.
+const modulePath = "github.com/mr-shifu/mpc-lib"
+
+// IsHandshake returns true if the message announces the sender's build.
+func (m Message) IsHandshake() bool {
+	return m.RoundNumber == HandshakeRoundNumber
+}
+
+// BuildInfo describes the software run by a party.
+type BuildInfo struct {
+	// Version is the version of this library, such as "v1.2.0", or "(devel)" for a local build.
+	Version string `json:"version"`
+	// Commit is the VCS revision the binary was built from, if known.
+	Commit string `json:"commit,omitempty"`
+	// Attestation is an optional attestation of the binary, such as the hash of a measured enclave.
+	Attestation []byte `json:"attestation,omitempty"`
+}
+
+// CurrentBuild returns the BuildInfo of the running binary, as recorded by the Go toolchain.
+func CurrentBuild() BuildInfo {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return BuildInfo{}
+	}
+	var build BuildInfo
+	if info.Main.Path == modulePath {
+		build.Version = info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			build.Version = dep.Version
+		}
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			build.Commit = setting.Value
+		}
+	}
+	return build
+}
+
+// WriteTo implements io.WriterTo interface.
+func (b BuildInfo) WriteTo(w io.Writer) (int64, error) {
+	data, err := cbor.Marshal(b)
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// Domain implements hash.WriterToWithDomain.
+func (BuildInfo) Domain() string {
+	return "Build Info"
+}
+
+// BuildPolicy decides whether to run a protocol with a party, given the build it announced.
+type BuildPolicy func(id party.ID, build BuildInfo) error
+
+// MinimumVersion returns a BuildPolicy rejecting parties running a version of this library older than min,
+// or whose version is not a release, such as a local build.
+func MinimumVersion(min string) BuildPolicy {
+	return func(id party.ID, build BuildInfo) error {
+		cmp, ok := compareVersions(build.Version, min)
+		if !ok {
+			return fmt.Errorf("%w: %s runs unknown version %q", ErrBuildRejected, id, build.Version)
+		}
+		if cmp < 0 {
+			return fmt.Errorf("%w: %s runs version %s, older than %s", ErrBuildRejected, id, build.Version, min)
+		}
+		return nil
+	}
+}
+
+// compareVersions compares two versions of the form vMAJOR.MINOR.PATCH, ignoring pre-release and build suffixes.
+// It returns false if either is not of this form.
+func compareVersions(a, b string) (int, bool) {
+	pa, ok := parseVersion(a)
+	if !ok {
+		return 0, false
+	}
+	pb, ok := parseVersion(b)
+	if !ok {
+		return 0, false
+	}
+	for i := range pa {
+		switch {
+		case pa[i] < pb[i]:
+			return -1, true
+		case pa[i] > pb[i]:
+			return 1, true
+		}
+	}
+	return 0, true
+}
+
+func parseVersion(v string) ([3]int, bool) {
+	var parts [3]int
+	if !strings.HasPrefix(v, "v") {
+		return parts, false
+	}
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	fields := strings.Split(v, ".")
+	if len(fields) != 3 {
+		return parts, false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
+
+// SetBuildPolicy sets the policy applied to the builds announced by the other parties.
+// If it returns an error, the protocol is aborted and the party is blamed.
+// Once it is set, the rounds after the first are only finalized once every party, including this one
+// (see Handshake), announced its build, so that a party cannot bypass the policy by not announcing one.
+// It should be called before any message is accepted.
+func (h *MultiHandler) SetBuildPolicy(policy BuildPolicy) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.buildPolicy = policy
+}
+
+// Handshake returns a handshake message announcing build to all other parties, and records it as our own.
+func (h *MultiHandler) Handshake(build BuildInfo) (*Message, error) {
+	data, err := cbor.Marshal(build)
+	if err != nil {
+		return nil, err
+	}
+	msg := &Message{
+		SSID:        h.ssid,
+		From:        h.selfID,
+		Protocol:    h.protocolID,
+		RoundNumber: HandshakeRoundNumber,
+		Data:        data,
+		Broadcast:   true,
+	}
+
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	defer h.recoverPoolPanic()
+	if _, ok := h.builds[h.selfID]; ok {
+		return nil, errors.New("protocol: handshake already sent")
+	}
+	if err := h.sign(msg); err != nil {
+		return nil, err
+	}
+	h.recordBuild(msg, build)
+	// the round may have been waiting for our own handshake
+	if h.err == nil && h.result == nil {
+		h.finalize()
+	}
+	return msg, nil
+}
+
+// acceptHandshake records the build announced by msg, aborting if the BuildPolicy rejects it.
+// Once the protocol is over, the handshake is only recorded for the transcript.
+// It must be called with the lock held.
+func (h *MultiHandler) acceptHandshake(msg *Message) {
+	if _, ok := h.builds[msg.From]; ok || msg.From == h.selfID {
+		return
+	}
+	running := h.err == nil && h.result == nil
+	if running {
+		h.markSeen(msg.From)
+	}
+	var build BuildInfo
+	if err := cbor.Unmarshal(msg.Data, &build); err != nil {
+		if running {
+			h.abort(fmt.Errorf("protocol: invalid handshake: %w", err), msg.From)
+		}
+		return
+	}
+	if h.buildPolicy != nil {
+		if err := h.buildPolicy(msg.From, build); err != nil {
+			if running {
+				h.abort(err, msg.From)
+			}
+			return
+		}
+	}
+	h.recordBuild(msg, build)
+	// the round may have been waiting for this handshake
+	if running {
+		h.finalize()
+	}
+}
+
+// receivedHandshakes returns true if every party announced its build, or if no BuildPolicy requires it.
+// It must be called with the lock held.
+func (h *MultiHandler) receivedHandshakes() bool {
+	if h.buildPolicy == nil {
+		return true
+	}
+	for _, id := range h.currentRound.PartyIDs() {
+		if _, ok := h.builds[id]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// recordBuild stores the build of the sender of msg, along with the hash of msg for the transcript.
+// It must be called with the lock held.
+func (h *MultiHandler) recordBuild(msg *Message, build BuildInfo) {
+	if h.builds == nil {
+		h.builds = map[party.ID]BuildInfo{}
+		h.handshakes = map[party.ID][]byte{}
+	}
+	h.builds[msg.From] = build
+	h.handshakes[msg.From] = msg.Hash()
+}
+
+// Builds returns the builds announced so far, including our own once Handshake was called.
+func (h *MultiHandler) Builds() map[party.ID]BuildInfo {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	builds := make(map[party.ID]BuildInfo, len(h.builds))
+	for id, b := range h.builds {
+		builds[id] = b
+	}
+	return builds
+}
+
+// transcriptHashes returns the hashes of the broadcast messages for the transcript, including the handshakes
+// once every party announced its build. Since handshakes are recorded even after the protocol completed,
+// all parties eventually include the same ones, whatever the order in which they arrived.
+// It must be called with the lock held.
+func (h *MultiHandler) transcriptHashes() map[round.Number]map[party.ID][]byte {
+	hashes := h.broadcast.messageHashes()
+	if len(h.handshakes) == h.currentRound.N() {
+		hashes[HandshakeRoundNumber] = make(map[party.ID][]byte, len(h.handshakes))
+		for id, hash := range h.handshakes {
+			hashes[HandshakeRoundNumber][id] = hash
+		}
+	}
+	return hashes
+}
@@ -0,0 +1,164 @@
+package protocol
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinimumVersion(t *testing.T) {
+	policy := MinimumVersion("v1.4.0")
+	tests := []struct {
+		version string
+		ok      bool
+	}{
+		{"v1.4.0", true},
+		{"v1.10.2", true},
+		{"v2.0.0-rc.1", true},
+		{"v1.3.9", false},
+		{"v0.9.0+incompatible", false},
+		{"(devel)", false},
+		{"", false},
+		{"v1.4", false},
+	}
+	for _, test := range tests {
+		err := policy("a", BuildInfo{Version: test.version})
+		if test.ok {
+			assert.NoError(t, err, test.version)
+		} else {
+			assert.True(t, errors.Is(err, ErrBuildRejected), test.version)
+		}
+	}
+}
+
+// newChattyHandlers returns the handlers of the broadcast variant of the chatty protocol, with the given build policy.
+func newChattyHandlers(t *testing.T, ids party.IDSlice, policy BuildPolicy) map[party.ID]*MultiHandler {
+	handlers := make(map[party.ID]*MultiHandler, len(ids))
+	for _, id := range ids {
+		h, err := NewMultiHandler(startChattyProtocol(t, 4, id, ids, true), []byte("handshake"))
+		require.NoError(t, err)
+		h.SetBuildPolicy(policy)
+		handlers[id] = h
+	}
+	return handlers
+}
+
+// deliverAll delivers the messages sent by the handlers until none is left, skipping the ones for which skip
+// returns true.
+func deliverAll(handlers map[party.ID]*MultiHandler, skip func(msg *Message, to party.ID) bool) {
+	for {
+		var msgs []*Message
+		for _, h := range handlers {
+			pending, _ := DrainMessages(h)
+			msgs = append(msgs, pending...)
+		}
+		if len(msgs) == 0 {
+			return
+		}
+		for _, msg := range msgs {
+			for id, h := range handlers {
+				if id != msg.From && msg.IsFor(id) && (skip == nil || !skip(msg, id)) {
+					h.Accept(msg)
+				}
+			}
+		}
+	}
+}
+
+func TestHandshakeRequired(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	handlers := newChattyHandlers(t, ids, MinimumVersion("v1.0.0"))
+	handshakes := make(map[party.ID]*Message, len(ids))
+	for _, id := range ids {
+		msg, err := handlers[id].Handshake(BuildInfo{Version: "v1.2.0"})
+		require.NoError(t, err)
+		handshakes[id] = msg
+	}
+	_, err := handlers["a"].Handshake(BuildInfo{Version: "v1.2.0"})
+	assert.Error(t, err, "a handshake is sent once")
+
+	// a party which does not announce its build cannot bypass the policy
+	deliverAll(handlers, nil)
+	for _, h := range handlers {
+		_, err := h.Result()
+		require.Error(t, err)
+		require.Equal(t, uint32(2), h.roundNumber.Load(), "the round waits for the handshakes")
+	}
+	handlers["a"].Accept(handshakes["b"])
+	handlers["b"].Accept(handshakes["a"])
+	deliverAll(handlers, nil)
+	for _, h := range handlers {
+		_, err := h.Result()
+		require.NoError(t, err)
+		assert.Equal(t, BuildInfo{Version: "v1.2.0"}, h.Builds()["a"])
+		assert.Len(t, h.Builds(), 2)
+	}
+}
+
+func TestHandshakeRejected(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	tests := map[string]func(msg *Message){
+		"old version": func(msg *Message) {},
+		"invalid":     func(msg *Message) { msg.Data = []byte{0xff} },
+	}
+	for name, modify := range tests {
+		handlers := newChattyHandlers(t, ids, MinimumVersion("v1.4.0"))
+		_, err := handlers["a"].Handshake(BuildInfo{Version: "v1.4.0"})
+		require.NoError(t, err)
+		msg, err := handlers["b"].Handshake(BuildInfo{Version: "v1.3.9"})
+		require.NoError(t, err)
+		modify(msg)
+		handlers["a"].Accept(msg)
+		_, err = handlers["a"].Result()
+		require.Error(t, err, name)
+		var e Error
+		require.True(t, errors.As(err, &e), name)
+		assert.Equal(t, []party.ID{"b"}, e.Culprits, name)
+		if name == "old version" {
+			assert.ErrorIs(t, err, ErrBuildRejected)
+		}
+	}
+}
+
+func TestHandshakeTranscript(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	builds := map[party.ID]BuildInfo{"a": {Version: "v1.0.0"}, "b": {Version: "v1.1.0", Commit: "abc"}}
+
+	// without a policy, b receives the handshake of a before completing, and a the one of b after
+	handlers := newChattyHandlers(t, ids, nil)
+	handshakes := make(map[party.ID]*Message, len(ids))
+	for _, id := range ids {
+		msg, err := handlers[id].Handshake(builds[id])
+		require.NoError(t, err)
+		handshakes[id] = msg
+	}
+	handlers["b"].Accept(handshakes["a"])
+	deliverAll(handlers, func(msg *Message, to party.ID) bool { return msg.IsHandshake() })
+	for _, h := range handlers {
+		_, err := h.Result()
+		require.NoError(t, err)
+	}
+	without := handlers["a"].TranscriptHash()
+	handlers["a"].Accept(handshakes["b"])
+	assert.Equal(t, handlers["a"].TranscriptHash(), handlers["b"].TranscriptHash(), "handshakes are bound whatever their order")
+	assert.NotEqual(t, without, handlers["a"].TranscriptHash(), "handshakes are bound once all were received")
+
+	// a party announcing another build gives another transcript
+	other := newChattyHandlers(t, ids, nil)
+	for _, id := range ids {
+		build := builds[id]
+		if id == "b" {
+			build.Commit = "def"
+		}
+		msg, err := other[id].Handshake(build)
+		require.NoError(t, err)
+		handshakes[id] = msg
+	}
+	other["a"].Accept(handshakes["b"])
+	other["b"].Accept(handshakes["a"])
+	deliverAll(other, nil)
+	assert.NotEqual(t, handlers["a"].TranscriptHash(), other["a"].TranscriptHash())
+}
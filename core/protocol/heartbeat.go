@@ -0,0 +1,76 @@
+package protocol
+
+import (
+	"context"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+)
+
+// HeartbeatRoundNumber is the round number of a heartbeat message.
+//
+// Heartbeats are handled by the framework rather than by the protocol's rounds. They let the other parties
+// know that the sender is still alive while it performs a long computation, such as generating a Paillier key,
+// so that a session manager can extend its timeouts instead of aborting.
+const HeartbeatRoundNumber round.Number = ResendRoundNumber - 2
+
+// IsHeartbeat returns true if the message is a heartbeat.
+func (m Message) IsHeartbeat() bool {
+	return m.RoundNumber == HeartbeatRoundNumber
+}
+
+// Heartbeat returns a heartbeat message for all other parties.
+// Its content is the round the sender is currently in.
+//
+// It does not wait for Accept, so that heartbeats can be sent while a long round is being finalized.
+func (h *MultiHandler) Heartbeat() *Message {
+	return &Message{
+		SSID:        h.ssid,
+		From:        h.selfID,
+		Protocol:    h.protocolID,
+		RoundNumber: HeartbeatRoundNumber,
+		Data:        []byte{byte(h.roundNumber.Load())},
+		Broadcast:   true,
+	}
+}
+
+// LastSeen returns the time at which a message was last received from each other party,
+// including heartbeats. Parties from which nothing was received yet are omitted.
+func (h *MultiHandler) LastSeen() map[party.ID]time.Time {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	seen := make(map[party.ID]time.Time, len(h.lastSeen))
+	for id, t := range h.lastSeen {
+		seen[id] = t
+	}
+	return seen
+}
+
+// markSeen records that a message was received from id. It must be called with the lock held.
+func (h *MultiHandler) markSeen(id party.ID) {
+	if h.lastSeen == nil {
+		h.lastSeen = map[party.ID]time.Time{}
+	}
+	h.lastSeen[id] = time.Now()
+}
+
+// SendHeartbeats calls send with a heartbeat every interval, until ctx is done or the protocol has finished.
+// Since heartbeats are computed on the calling goroutine, they keep being sent while
+// Accept is blocked in a long round.
+func SendHeartbeats(ctx context.Context, h *MultiHandler, interval time.Duration, send func(*Message)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if h.finished.Load() {
+				return
+			}
+			send(h.Heartbeat())
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package protocol
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeartbeat(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	a, err := NewMultiHandler(startChattyProtocol(t, 2, "a", ids, true), []byte("heartbeat"))
+	require.NoError(t, err)
+	b, err := NewMultiHandler(startChattyProtocol(t, 2, "b", ids, true), []byte("heartbeat"))
+	require.NoError(t, err)
+	assert.Empty(t, b.LastSeen())
+
+	hb := a.Heartbeat()
+	assert.True(t, hb.IsHeartbeat())
+	assert.True(t, hb.IsFor("b"))
+	// the first round sent its broadcast, and waits for the others in the second one
+	assert.Equal(t, []byte{2}, hb.Data)
+
+	before := time.Now()
+	b.Accept(hb)
+	seen := b.LastSeen()
+	require.Contains(t, seen, party.ID("a"))
+	assert.False(t, seen["a"].Before(before))
+
+	// a heartbeat is not a protocol message
+	_, err = b.Result()
+	assert.Error(t, err)
+	assert.Equal(t, uint32(2), b.roundNumber.Load())
+}
+
+func TestSendHeartbeats(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	h, err := NewMultiHandler(startChattyProtocol(t, 2, "a", ids, true), []byte("heartbeat"))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sent := make(chan *Message, 16)
+	done := make(chan struct{})
+	go func() {
+		SendHeartbeats(ctx, h, time.Millisecond, func(msg *Message) {
+			select {
+			case sent <- msg:
+			default:
+			}
+		})
+		close(done)
+	}()
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-sent:
+			assert.True(t, msg.IsHeartbeat())
+		case <-time.After(time.Second):
+			t.Fatal("no heartbeat sent")
+		}
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("SendHeartbeats did not return once its context was done")
+	}
+
+	// once the protocol has finished, no more heartbeats are sent
+	handlers := runChatty(t, ids, false)
+	done = make(chan struct{})
+	go func() {
+		SendHeartbeats(context.Background(), handlers["a"], time.Millisecond, func(*Message) {
+			t.Error("heartbeat sent after the protocol finished")
+		})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("SendHeartbeats did not return once the protocol finished")
+	}
+}
@@ -1,6 +1,7 @@
 package protocol
 
 import (
+	"encoding/binary"
 	"fmt"
 
 	"github.com/fxamacker/cbor/v2"
@@ -27,6 +28,9 @@ type Message struct {
 	// BroadcastVerification is the hash of all messages broadcast by the parties,
 	// and is included in all messages in the round following a broadcast round.
 	BroadcastVerification []byte
+	// Signature is the signature of the message by its sender, if its handler has a MessageSigner.
+	// It is not covered by Hash, which it signs.
+	Signature []byte
 }
 
 // String implements fmt.Stringer.
@@ -42,30 +46,111 @@ func (m Message) IsFor(id party.ID) bool {
 	return m.To == "" || m.To == id
 }
 
-// Hash returns a 64 byte hash of the message content, including the headers.
-// Can be used to produce a signature for the message.
+// Hash returns a 64 byte hash of the canonical encoding of the message, including the headers.
+// Can be used to produce a signature for the message, and to recognize duplicates.
 func (m *Message) Hash() []byte {
-	var broadcast byte
-	if m.Broadcast {
-		broadcast = 1
+	h := hash.New(hash.BytesWithDomain{TheDomain: "Message", Bytes: m.encode()})
+	return h.Sum()
+}
+
+// MarshalBinary returns the canonical encoding of the message: the version, then SSID, From, To, Protocol,
+// RoundNumber, Data, Broadcast and BroadcastVerification, in this order, with variable length fields
+// prefixed by their length, followed by the Signature if there is one.
+// The same message is always encoded to the same bytes, whatever the transport.
+func (m *Message) MarshalBinary() ([]byte, error) {
+	buf := m.encode()
+	if len(m.Signature) > 0 {
+		buf = round.AppendField(buf, m.Signature)
 	}
-	h := hash.New(
-		hash.BytesWithDomain{TheDomain: "SSID", Bytes: m.SSID},
-		m.From,
-		m.To,
-		hash.BytesWithDomain{TheDomain: "Protocol", Bytes: []byte(m.Protocol)},
-		m.RoundNumber,
-		hash.BytesWithDomain{TheDomain: "Content", Bytes: m.Data},
-		hash.BytesWithDomain{TheDomain: "Broadcast", Bytes: []byte{broadcast}},
-		hash.BytesWithDomain{TheDomain: "BroadcastVerification", Bytes: m.BroadcastVerification},
+	return buf, nil
+}
+
+func (m *Message) encode() []byte {
+	buf := make([]byte, 0, 64+len(m.SSID)+len(m.Data)+len(m.BroadcastVerification))
+	buf = append(buf, round.EncodingVersion)
+	buf = round.AppendField(buf, m.SSID)
+	buf = round.AppendField(buf, []byte(m.From))
+	buf = round.AppendField(buf, []byte(m.To))
+	buf = round.AppendField(buf, []byte(m.Protocol))
+	buf = binary.BigEndian.AppendUint16(buf, uint16(m.RoundNumber))
+	buf = round.AppendField(buf, m.Data)
+	buf = round.AppendBool(buf, m.Broadcast)
+	buf = round.AppendField(buf, m.BroadcastVerification)
+	return buf
+}
+
+// UnmarshalBinary decodes a message encoded by MarshalBinary.
+// Messages encoded with cbor by earlier versions are still accepted.
+func (m *Message) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] != round.EncodingVersion {
+		return m.unmarshalCBOR(data)
+	}
+	var (
+		decoded  Message
+		from     []byte
+		to       []byte
+		protocol []byte
+		err      error
 	)
-	return h.Sum()
+	rest := data[1:]
+	if decoded.SSID, rest, err = round.ReadField(rest); err != nil {
+		return err
+	}
+	if from, rest, err = round.ReadField(rest); err != nil {
+		return err
+	}
+	if to, rest, err = round.ReadField(rest); err != nil {
+		return err
+	}
+	if protocol, rest, err = round.ReadField(rest); err != nil {
+		return err
+	}
+	if len(rest) < 2 {
+		return fmt.Errorf("%w: truncated round number", round.ErrInvalidEncoding)
+	}
+	decoded.RoundNumber = round.Number(binary.BigEndian.Uint16(rest))
+	if decoded.Data, rest, err = round.ReadField(rest[2:]); err != nil {
+		return err
+	}
+	if decoded.Broadcast, rest, err = round.ReadBool(rest); err != nil {
+		return err
+	}
+	if decoded.BroadcastVerification, rest, err = round.ReadField(rest); err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		if decoded.Signature, rest, err = round.ReadField(rest); err != nil {
+			return err
+		}
+		if len(decoded.Signature) == 0 {
+			return fmt.Errorf("%w: empty signature", round.ErrInvalidEncoding)
+		}
+	}
+	if len(rest) != 0 {
+		return fmt.Errorf("%w: trailing data", round.ErrInvalidEncoding)
+	}
+	decoded.From, decoded.To, decoded.Protocol = party.ID(from), party.ID(to), string(protocol)
+	// copy the variable length fields, so that m does not alias data
+	decoded.SSID = cloneField(decoded.SSID)
+	decoded.Data = cloneField(decoded.Data)
+	decoded.BroadcastVerification = cloneField(decoded.BroadcastVerification)
+	decoded.Signature = cloneField(decoded.Signature)
+	*m = decoded
+	return nil
+}
+
+// cloneField copies a decoded field, keeping empty fields nil as in the cbor encoding.
+func cloneField(b []byte) []byte {
+	if len(b) == 0 {
+		return nil
+	}
+	return append([]byte(nil), b...)
 }
 
 // marshallableMessage is a copy of message for the purpose of cbor marshalling.
 //
-// This is a workaround to use cbor's default marshalling for Message, all while providing
-// a MarshalBinary method
+// This is the encoding of messages before the canonical encoding of MarshalBinary,
+// kept to decode messages persisted or sent by earlier versions.
 type marshallableMessage struct {
 	SSID                  []byte
 	From                  party.ID
@@ -77,27 +162,10 @@ type marshallableMessage struct {
 	BroadcastVerification []byte
 }
 
-func (m *Message) toMarshallable() *marshallableMessage {
-	return &marshallableMessage{
-		SSID:                  m.SSID,
-		From:                  m.From,
-		To:                    m.To,
-		Protocol:              m.Protocol,
-		RoundNumber:           m.RoundNumber,
-		Data:                  m.Data,
-		Broadcast:             m.Broadcast,
-		BroadcastVerification: m.BroadcastVerification,
-	}
-}
-
-func (m *Message) MarshalBinary() ([]byte, error) {
-	return cbor.Marshal(m.toMarshallable())
-}
-
-func (m *Message) UnmarshalBinary(data []byte) error {
-	deserialized := m.toMarshallable()
+func (m *Message) unmarshalCBOR(data []byte) error {
+	deserialized := &marshallableMessage{}
 	if err := cbor.Unmarshal(data, deserialized); err != nil {
-		return nil
+		return fmt.Errorf("%w: %v", round.ErrInvalidEncoding, err)
 	}
 	m.SSID = deserialized.SSID
 	m.From = deserialized.From
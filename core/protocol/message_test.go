@@ -0,0 +1,72 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageEncoding(t *testing.T) {
+	msg := &Message{
+		SSID:                  []byte("ssid"),
+		From:                  "a",
+		To:                    "b",
+		Protocol:              "cmp/sign",
+		RoundNumber:           3,
+		Data:                  []byte{1, 2, 3},
+		BroadcastVerification: []byte{4},
+	}
+	data, err := msg.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, round.EncodingVersion, data[0])
+
+	decoded := &Message{}
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, msg, decoded)
+	assert.Equal(t, msg.Hash(), decoded.Hash())
+
+	// each header is bound by the encoding, and so by the hash
+	other := *msg
+	other.From, other.To = "", "ab"
+	assert.NotEqual(t, msg.Hash(), other.Hash())
+	other = *msg
+	other.Broadcast = true
+	assert.NotEqual(t, msg.Hash(), other.Hash())
+
+	for n := 1; n < len(data); n++ {
+		assert.ErrorIs(t, (&Message{}).UnmarshalBinary(data[:n]), round.ErrInvalidEncoding)
+	}
+	assert.ErrorIs(t, (&Message{}).UnmarshalBinary(append(data, 0)), round.ErrInvalidEncoding)
+
+	// the signature is encoded after the message, and is not covered by its hash
+	signed := *msg
+	signed.Signature = []byte{5, 6}
+	data, err = signed.MarshalBinary()
+	require.NoError(t, err)
+	decoded = &Message{}
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, &signed, decoded)
+	assert.Equal(t, msg.Hash(), decoded.Hash())
+	assert.ErrorIs(t, (&Message{}).UnmarshalBinary(append(data, 0)), round.ErrInvalidEncoding)
+}
+
+func TestMessageEncodingLegacy(t *testing.T) {
+	msg := &Message{SSID: []byte("ssid"), From: "a", Protocol: "cmp/sign", RoundNumber: 2, Data: []byte{1}, Broadcast: true}
+	legacy, err := cbor.Marshal(&marshallableMessage{
+		SSID:        msg.SSID,
+		From:        msg.From,
+		Protocol:    msg.Protocol,
+		RoundNumber: msg.RoundNumber,
+		Data:        msg.Data,
+		Broadcast:   msg.Broadcast,
+	})
+	require.NoError(t, err)
+
+	decoded := &Message{}
+	require.NoError(t, decoded.UnmarshalBinary(legacy))
+	assert.Equal(t, msg, decoded)
+	assert.Error(t, decoded.UnmarshalBinary([]byte{0xff}))
+}
@@ -0,0 +1,181 @@
+package protocol
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mr-shifu/mpc-lib/core/hash"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+)
+
+var (
+	ErrEquivocation   = errors.New("protocol: party sent different broadcast messages")
+	ErrCeremonyFailed = errors.New("protocol: ceremony failed verification")
+)
+
+// Observer is a read-only participant, which holds no share but receives all the messages of a session,
+// verifies them, and attests to the outcome of the ceremony.
+//
+// Without further configuration, the observer checks the headers of each message, that no party equivocates
+// on its broadcast messages, and that all parties agree on the BroadcastVerification of each round.
+// Verify can be set to additionally check the zk proofs contained in the messages,
+// which requires knowledge of the protocol's round contents.
+type Observer struct {
+	ssid     []byte
+	protocol string
+	parties  party.IDSlice
+
+	// Verify is called on each message accepted by the observer, and should return an error if
+	// a proof it contains is invalid.
+	Verify func(msg *Message) error
+
+	// seen holds the hashes of accepted messages, and broadcasts the hashes of broadcast messages by round and sender.
+	seen          map[string]bool
+	broadcasts    map[round.Number]map[party.ID][]byte
+	verifications map[round.Number][]byte
+	err           error
+	mtx           sync.Mutex
+}
+
+// NewObserver returns an Observer for the session ssid of the given protocol, between the given parties.
+func NewObserver(ssid []byte, protocolID string, parties []party.ID) *Observer {
+	return &Observer{
+		ssid:          ssid,
+		protocol:      protocolID,
+		parties:       party.NewIDSlice(parties),
+		seen:          map[string]bool{},
+		broadcasts:    map[round.Number]map[party.ID][]byte{},
+		verifications: map[round.Number][]byte{},
+	}
+}
+
+// Accept verifies msg and adds it to the transcript.
+// Once a message fails verification, the ceremony is considered failed, and the error is also returned by Err.
+func (o *Observer) Accept(msg *Message) error {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	if err := o.accept(msg); err != nil {
+		if o.err == nil {
+			o.err = err
+		}
+		return err
+	}
+	return nil
+}
+
+func (o *Observer) accept(msg *Message) error {
+	if msg == nil {
+		return errors.New("protocol: nil message")
+	}
+	if msg.Protocol != o.protocol || !bytes.Equal(msg.SSID, o.ssid) {
+		return errors.New("protocol: message belongs to another session")
+	}
+	if !o.parties.Contains(msg.From) {
+		return fmt.Errorf("protocol: unknown sender %s", msg.From)
+	}
+	if msg.IsResend() || msg.IsHeartbeat() || msg.IsTranscriptConfirmation() {
+		return nil
+	}
+	if msg.RoundNumber == 0 {
+		return fmt.Errorf("protocol: party %s aborted: %s", msg.From, msg.Data)
+	}
+
+	key := string(msg.Hash())
+	if o.seen[key] {
+		return nil
+	}
+
+	if msg.Broadcast {
+		if previous, ok := o.broadcasts[msg.RoundNumber][msg.From]; ok && !bytes.Equal(previous, []byte(key)) {
+			return fmt.Errorf("%w: %s in round %d", ErrEquivocation, msg.From, msg.RoundNumber)
+		}
+	}
+
+	if msg.BroadcastVerification != nil {
+		if v, ok := o.verifications[msg.RoundNumber]; ok && !bytes.Equal(v, msg.BroadcastVerification) {
+			return fmt.Errorf("%w: %s reports different broadcasts for round %d", ErrEquivocation, msg.From, msg.RoundNumber-1)
+		}
+		o.verifications[msg.RoundNumber] = msg.BroadcastVerification
+	}
+
+	if o.Verify != nil {
+		if err := o.Verify(msg); err != nil {
+			return fmt.Errorf("protocol: message from %s in round %d: %w", msg.From, msg.RoundNumber, err)
+		}
+	}
+	o.seen[key] = true
+	if msg.Broadcast {
+		if o.broadcasts[msg.RoundNumber] == nil {
+			o.broadcasts[msg.RoundNumber] = map[party.ID][]byte{}
+		}
+		o.broadcasts[msg.RoundNumber][msg.From] = []byte(key)
+	}
+	return nil
+}
+
+// Err returns the first verification failure, if any.
+func (o *Observer) Err() error {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	return o.err
+}
+
+// TranscriptHash returns the canonical hash of the broadcast messages accepted so far,
+// which matches the transcript confirmed by the parties (see MultiHandler.ConfirmedTranscript).
+func (o *Observer) TranscriptHash() []byte {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	return transcriptHash(o.ssid, o.protocol, o.broadcasts)
+}
+
+// Attestation is a statement signed by an Observer, binding the outcome of a ceremony to its transcript.
+type Attestation struct {
+	SSID           []byte
+	Protocol       string
+	TranscriptHash []byte
+	// Outcome is the result of the ceremony, for example the encoded signature.
+	Outcome []byte
+	// Observer is the identity key of the observer.
+	Observer  ed25519.PublicKey
+	Signature []byte
+}
+
+// Attest returns an Attestation of the outcome, signed with the observer's identity key.
+// The caller is responsible for checking the outcome itself, for example by verifying the signature
+// against the public key. An error is returned if any message failed verification.
+func (o *Observer) Attest(outcome []byte, key ed25519.PrivateKey) (*Attestation, error) {
+	if err := o.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCeremonyFailed, err)
+	}
+	a := &Attestation{
+		SSID:           o.ssid,
+		Protocol:       o.protocol,
+		TranscriptHash: o.TranscriptHash(),
+		Outcome:        outcome,
+		Observer:       key.Public().(ed25519.PublicKey),
+	}
+	a.Signature = ed25519.Sign(key, a.signedData())
+	return a, nil
+}
+
+// Verify returns true if the attestation was signed by the given observer.
+func (a *Attestation) Verify(observer ed25519.PublicKey) bool {
+	if len(observer) != ed25519.PublicKeySize || !bytes.Equal(observer, a.Observer) {
+		return false
+	}
+	return ed25519.Verify(observer, a.signedData(), a.Signature)
+}
+
+func (a *Attestation) signedData() []byte {
+	return hash.New(
+		hash.BytesWithDomain{TheDomain: "Attestation SSID", Bytes: a.SSID},
+		hash.BytesWithDomain{TheDomain: "Attestation Protocol", Bytes: []byte(a.Protocol)},
+		hash.BytesWithDomain{TheDomain: "Attestation Transcript", Bytes: a.TranscriptHash},
+		hash.BytesWithDomain{TheDomain: "Attestation Outcome", Bytes: a.Outcome},
+	).Sum()
+}
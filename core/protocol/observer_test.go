@@ -0,0 +1,105 @@
+package protocol
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// observeChatty runs the broadcast variant of the chatty protocol between ids,
+// and returns the handlers and all the messages they sent, once completed.
+func observeChatty(t *testing.T, ids party.IDSlice) (map[party.ID]*MultiHandler, []*Message) {
+	handlers := make(map[party.ID]*MultiHandler, len(ids))
+	for _, id := range ids {
+		h, err := NewMultiHandler(startChattyProtocol(t, 4, id, ids, true), []byte("transcript"))
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+	var sent []*Message
+	for {
+		var msgs []*Message
+		for _, id := range ids {
+			pending, _ := DrainMessages(handlers[id])
+			msgs = append(msgs, pending...)
+		}
+		if len(msgs) == 0 {
+			return handlers, sent
+		}
+		sent = append(sent, msgs...)
+		for _, msg := range msgs {
+			for _, id := range ids {
+				if id != msg.From && msg.IsFor(id) {
+					handlers[id].Accept(msg)
+				}
+			}
+		}
+	}
+}
+
+func TestObserver(t *testing.T) {
+	ids := party.IDSlice{"a", "b", "c"}
+	handlers, sent := observeChatty(t, ids)
+	require.NotEmpty(t, sent)
+	ssid := sent[0].SSID
+
+	o := NewObserver(ssid, "chatty", ids)
+	for _, msg := range sent {
+		require.NoError(t, o.Accept(msg))
+	}
+	// messages may be received more than once
+	require.NoError(t, o.Accept(sent[0]))
+	require.NoError(t, o.Err())
+	assert.Equal(t, handlers["a"].TranscriptHash(), o.TranscriptHash())
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	a, err := o.Attest([]byte("outcome"), key)
+	require.NoError(t, err)
+	assert.Equal(t, o.TranscriptHash(), a.TranscriptHash)
+	assert.True(t, a.Verify(pub))
+	other, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	assert.False(t, a.Verify(other))
+	forged := *a
+	forged.Outcome = []byte("other outcome")
+	assert.False(t, forged.Verify(pub))
+
+	o = NewObserver(ssid, "chatty", ids)
+	otherSession := *sent[0]
+	otherSession.SSID = []byte("other")
+	assert.Error(t, o.Accept(&otherSession))
+	unknown := *sent[0]
+	unknown.From = "d"
+	assert.Error(t, o.Accept(&unknown))
+	assert.Error(t, o.Accept(nil))
+}
+
+func TestObserverEquivocation(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	_, sent := observeChatty(t, ids)
+
+	o := NewObserver(sent[0].SSID, "chatty", ids)
+	var broadcast *Message
+	for _, msg := range sent {
+		require.NoError(t, o.Accept(msg))
+		if msg.Broadcast && broadcast == nil {
+			broadcast = msg
+		}
+	}
+	require.NotNil(t, broadcast)
+
+	// the same party broadcasting another message in the same round is caught
+	equivocation := *broadcast
+	equivocation.Data = append(append([]byte(nil), broadcast.Data...), 0)
+	assert.ErrorIs(t, o.Accept(&equivocation), ErrEquivocation)
+	assert.ErrorIs(t, o.Err(), ErrEquivocation)
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, err = o.Attest([]byte("outcome"), key)
+	assert.ErrorIs(t, err, ErrCeremonyFailed)
+}
@@ -0,0 +1,204 @@
+package protocol
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/party"
+)
+
+// OfflineChunkPrefix marks a chunk produced by SplitChunks so that it can be recognized
+// when scanned back from a QR code or pasted from a text file.
+const OfflineChunkPrefix = "MPCX"
+
+var (
+	ErrInvalidBundleSignature = errors.New("protocol: invalid offline bundle signature")
+	ErrUnknownBundleSigner    = errors.New("protocol: unknown offline bundle signer")
+	ErrInvalidChunk           = errors.New("protocol: invalid offline chunk")
+)
+
+// OfflineBundle is a set of protocol messages exchanged with an air-gapped party.
+//
+// A cold party does not share a live transport with the rest of the committee.
+// Instead, it receives the messages addressed to it as a file (or a sequence of QR chunks),
+// feeds them to its Handler, and exports its own outgoing messages as a new bundle.
+// Each bundle is signed by the identity key of the party who produced it.
+type OfflineBundle struct {
+	// From is the party which produced this bundle.
+	From party.ID
+	// Messages contains the marshalled protocol messages.
+	Messages [][]byte
+	// Signature is an ed25519 signature over the bundle contents.
+	Signature []byte
+}
+
+// NewOfflineBundle marshals msgs and signs them with the identity key of `from`.
+func NewOfflineBundle(from party.ID, key ed25519.PrivateKey, msgs ...*Message) (*OfflineBundle, error) {
+	b := &OfflineBundle{
+		From:     from,
+		Messages: make([][]byte, 0, len(msgs)),
+	}
+	for _, msg := range msgs {
+		data, err := msg.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("protocol: failed to marshal offline message: %w", err)
+		}
+		b.Messages = append(b.Messages, data)
+	}
+	b.Signature = ed25519.Sign(key, b.signedData())
+	return b, nil
+}
+
+// Verify checks the signature of the bundle against the sender's identity key.
+func (b *OfflineBundle) Verify(key ed25519.PublicKey) error {
+	if len(key) != ed25519.PublicKeySize {
+		return ErrUnknownBundleSigner
+	}
+	if !ed25519.Verify(key, b.signedData(), b.Signature) {
+		return ErrInvalidBundleSignature
+	}
+	return nil
+}
+
+// Open verifies the bundle and returns the messages it contains.
+//
+// Messages which were not sent by the bundle's signer are rejected, so that a bundle
+// cannot be used to relay messages on behalf of another party.
+func (b *OfflineBundle) Open(key ed25519.PublicKey) ([]*Message, error) {
+	if err := b.Verify(key); err != nil {
+		return nil, err
+	}
+	msgs := make([]*Message, 0, len(b.Messages))
+	for _, data := range b.Messages {
+		msg := &Message{}
+		if err := msg.UnmarshalBinary(data); err != nil {
+			return nil, fmt.Errorf("protocol: failed to unmarshal offline message: %w", err)
+		}
+		if msg.From != b.From {
+			return nil, fmt.Errorf("protocol: offline bundle from %s contains message from %s", b.From, msg.From)
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+// WriteTo writes the cbor encoding of the bundle to w.
+func (b *OfflineBundle) WriteTo(w io.Writer) (int64, error) {
+	data, err := cbor.Marshal(b)
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// ReadOfflineBundle decodes a bundle previously written with OfflineBundle.WriteTo.
+func ReadOfflineBundle(r io.Reader) (*OfflineBundle, error) {
+	b := &OfflineBundle{}
+	if err := cbor.NewDecoder(r).Decode(b); err != nil {
+		return nil, fmt.Errorf("protocol: failed to decode offline bundle: %w", err)
+	}
+	return b, nil
+}
+
+func (b *OfflineBundle) signedData() []byte {
+	data, _ := cbor.Marshal(struct {
+		From     party.ID
+		Messages [][]byte
+	}{b.From, b.Messages})
+	return data
+}
+
+// DrainMessages collects all messages currently queued by the handler without blocking.
+//
+// This is used by a cold party to gather its outgoing messages after having accepted
+// an incoming bundle. The second return value is false once the handler has closed its channel,
+// meaning that the protocol has finished.
+func DrainMessages(h Handler) ([]*Message, bool) {
+	var msgs []*Message
+	for {
+		select {
+		case msg, ok := <-h.Listen():
+			if !ok {
+				return msgs, false
+			}
+			msgs = append(msgs, msg)
+		default:
+			return msgs, true
+		}
+	}
+}
+
+// SplitChunks splits data into printable chunks of at most size bytes of payload,
+// suitable for QR codes. Each chunk is formatted as "MPCX:<index>/<total>:<base64>".
+func SplitChunks(data []byte, size int) []string {
+	if size <= 0 {
+		size = len(data)
+	}
+	total := (len(data) + size - 1) / size
+	if total == 0 {
+		total = 1
+	}
+	chunks := make([]string, 0, total)
+	for i := 0; i < total; i++ {
+		end := (i + 1) * size
+		if end > len(data) {
+			end = len(data)
+		}
+		payload := base64.RawURLEncoding.EncodeToString(data[i*size : end])
+		chunks = append(chunks, fmt.Sprintf("%s:%d/%d:%s", OfflineChunkPrefix, i+1, total, payload))
+	}
+	return chunks
+}
+
+// JoinChunks reassembles chunks produced by SplitChunks.
+// The chunks may be given in any order, but all of them must be present exactly once.
+func JoinChunks(chunks []string) ([]byte, error) {
+	var parts [][]byte
+	for _, c := range chunks {
+		fields := strings.SplitN(c, ":", 3)
+		if len(fields) != 3 || fields[0] != OfflineChunkPrefix {
+			return nil, ErrInvalidChunk
+		}
+		idx, total, ok := strings.Cut(fields[1], "/")
+		if !ok {
+			return nil, ErrInvalidChunk
+		}
+		i, err := strconv.Atoi(idx)
+		if err != nil {
+			return nil, ErrInvalidChunk
+		}
+		n, err := strconv.Atoi(total)
+		if err != nil || n <= 0 {
+			return nil, ErrInvalidChunk
+		}
+		if parts == nil {
+			parts = make([][]byte, n)
+		}
+		if n != len(parts) || i < 1 || i > n || parts[i-1] != nil {
+			return nil, ErrInvalidChunk
+		}
+		payload, err := base64.RawURLEncoding.DecodeString(fields[2])
+		if err != nil {
+			return nil, ErrInvalidChunk
+		}
+		parts[i-1] = payload
+	}
+	if parts == nil {
+		return nil, ErrInvalidChunk
+	}
+	var data []byte
+	for _, p := range parts {
+		if p == nil {
+			return nil, ErrInvalidChunk
+		}
+		data = append(data, p...)
+	}
+	return data, nil
+}
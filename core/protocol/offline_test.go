@@ -0,0 +1,51 @@
+package protocol
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOfflineBundle(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	msgs := []*Message{
+		{SSID: []byte("ssid"), From: "a", To: "b", Protocol: "cmp/sign", RoundNumber: 2, Data: []byte{1, 2, 3}},
+		{SSID: []byte("ssid"), From: "a", Protocol: "cmp/sign", RoundNumber: 2, Data: []byte{4}, Broadcast: true},
+	}
+	b, err := NewOfflineBundle("a", priv, msgs...)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	_, err = b.WriteTo(&buf)
+	require.NoError(t, err)
+
+	chunks := SplitChunks(buf.Bytes(), 64)
+	// reverse the order to make sure indices are respected
+	for i, j := 0, len(chunks)-1; i < j; i, j = i+1, j-1 {
+		chunks[i], chunks[j] = chunks[j], chunks[i]
+	}
+	data, err := JoinChunks(chunks)
+	require.NoError(t, err)
+
+	b2, err := ReadOfflineBundle(bytes.NewReader(data))
+	require.NoError(t, err)
+	opened, err := b2.Open(pub)
+	require.NoError(t, err)
+	require.Len(t, opened, len(msgs))
+	for i := range msgs {
+		assert.Equal(t, msgs[i].Hash(), opened[i].Hash())
+	}
+
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	_, err = b2.Open(otherPub)
+	assert.ErrorIs(t, err, ErrInvalidBundleSignature)
+
+	_, err = JoinChunks(chunks[1:])
+	assert.ErrorIs(t, err, ErrInvalidChunk)
+}
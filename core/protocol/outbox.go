@@ -0,0 +1,231 @@
+package protocol
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OutboxStore persists the messages of an Outbox until they are acknowledged.
+type OutboxStore interface {
+	// Put stores the message with the given ID, replacing any previous value.
+	Put(id string, data []byte) error
+	// Delete removes the message with the given ID. Deleting a missing message is not an error.
+	Delete(id string) error
+	// List returns all stored messages, indexed by ID.
+	List() (map[string][]byte, error)
+}
+
+// MessageID returns the identifier of a message in an Outbox.
+// Recipients acknowledge a message by sending back this ID.
+func MessageID(msg *Message) string {
+	return hex.EncodeToString(msg.Hash())
+}
+
+// Outbox persists outgoing messages before sending them, and keeps retrying until they are acknowledged.
+//
+// Since pending messages are reloaded from the store, a process which restarts in the middle of
+// a round resends the messages it had not yet delivered, rather than forcing the session to abort.
+// Delivery is at-least-once: recipients drop the duplicates through Handler.Accept.
+type Outbox struct {
+	store OutboxStore
+	send  func(*Message) error
+	mtx   sync.Mutex
+}
+
+// NewOutbox returns an Outbox delivering messages with send, and persisting them in store.
+func NewOutbox(store OutboxStore, send func(*Message) error) *Outbox {
+	return &Outbox{
+		store: store,
+		send:  send,
+	}
+}
+
+// Enqueue persists msg and attempts to send it.
+// An error is returned only if the message could not be persisted, since sending is retried by Flush.
+func (o *Outbox) Enqueue(msg *Message) error {
+	data, err := msg.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("protocol: outbox: %w", err)
+	}
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	if err := o.store.Put(MessageID(msg), data); err != nil {
+		return fmt.Errorf("protocol: outbox: %w", err)
+	}
+	_ = o.send(msg)
+	return nil
+}
+
+// Ack removes the message with the given ID, which will no longer be resent.
+func (o *Outbox) Ack(id string) error {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	return o.store.Delete(id)
+}
+
+// Pending returns the number of messages not yet acknowledged.
+func (o *Outbox) Pending() (int, error) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	msgs, err := o.store.List()
+	return len(msgs), err
+}
+
+// Flush resends all messages which were not acknowledged, and returns the first send error.
+func (o *Outbox) Flush() error {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	msgs, err := o.store.List()
+	if err != nil {
+		return fmt.Errorf("protocol: outbox: %w", err)
+	}
+	var first error
+	for id, data := range msgs {
+		msg := &Message{}
+		if err := msg.UnmarshalBinary(data); err != nil {
+			// a corrupted entry would be retried forever
+			_ = o.store.Delete(id)
+			continue
+		}
+		if err := o.send(msg); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Run calls Flush every interval until ctx is done.
+func (o *Outbox) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = o.Flush()
+		}
+	}
+}
+
+// InMemoryOutboxStore is an OutboxStore which does not survive restarts.
+type InMemoryOutboxStore struct {
+	msgs map[string][]byte
+	mtx  sync.Mutex
+}
+
+func NewInMemoryOutboxStore() *InMemoryOutboxStore {
+	return &InMemoryOutboxStore{msgs: map[string][]byte{}}
+}
+
+func (s *InMemoryOutboxStore) Put(id string, data []byte) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.msgs[id] = data
+	return nil
+}
+
+func (s *InMemoryOutboxStore) Delete(id string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.msgs, id)
+	return nil
+}
+
+func (s *InMemoryOutboxStore) List() (map[string][]byte, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	msgs := make(map[string][]byte, len(s.msgs))
+	for id, data := range s.msgs {
+		msgs[id] = data
+	}
+	return msgs, nil
+}
+
+// FileOutboxStore is an OutboxStore keeping one file per message in a directory.
+type FileOutboxStore struct {
+	dir string
+}
+
+const outboxFileExt = ".msg"
+
+// NewFileOutboxStore returns a FileOutboxStore in dir, which is created if needed.
+func NewFileOutboxStore(dir string) (*FileOutboxStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileOutboxStore{dir: dir}, nil
+}
+
+// Put writes the message to a temporary file which is then renamed,
+// so that a crash never leaves a partially written message.
+func (s *FileOutboxStore) Put(id string, data []byte) error {
+	if err := validOutboxID(id); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(s.dir, id+outboxFileExt))
+}
+
+func (s *FileOutboxStore) Delete(id string) error {
+	if err := validOutboxID(id); err != nil {
+		return err
+	}
+	err := os.Remove(filepath.Join(s.dir, id+outboxFileExt))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *FileOutboxStore) List() (map[string][]byte, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	msgs := make(map[string][]byte, len(entries))
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, outboxFileExt) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, name))
+		if err != nil {
+			return nil, err
+		}
+		msgs[strings.TrimSuffix(name, outboxFileExt)] = data
+	}
+	return msgs, nil
+}
+
+// validOutboxID ensures that an ID cannot escape the store's directory.
+func validOutboxID(id string) error {
+	if _, err := hex.DecodeString(id); err != nil || id == "" {
+		return fmt.Errorf("protocol: outbox: invalid message id %q", id)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package protocol
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxRestart(t *testing.T) {
+	dir := t.TempDir()
+	msg := &Message{SSID: []byte("ssid"), From: "a", To: "b", Protocol: "cmp/sign", RoundNumber: 1, Data: []byte{1}}
+
+	store, err := NewFileOutboxStore(dir)
+	require.NoError(t, err)
+	failing := NewOutbox(store, func(*Message) error { return errors.New("network down") })
+	require.NoError(t, failing.Enqueue(msg))
+	assert.Error(t, failing.Flush())
+
+	// a new outbox on the same directory picks up the pending message
+	store, err = NewFileOutboxStore(dir)
+	require.NoError(t, err)
+	var sent []*Message
+	o := NewOutbox(store, func(m *Message) error {
+		sent = append(sent, m)
+		return nil
+	})
+	require.NoError(t, o.Flush())
+	require.Len(t, sent, 1)
+	assert.Equal(t, msg.Hash(), sent[0].Hash())
+
+	require.NoError(t, o.Ack(MessageID(sent[0])))
+	pending, err := o.Pending()
+	require.NoError(t, err)
+	assert.Zero(t, pending)
+
+	assert.Error(t, store.Delete("../escape"))
+}
@@ -0,0 +1,143 @@
+package protocol
+
+import (
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+)
+
+// queue holds the messages received in each round, in slices indexed by the position of the sender,
+// so that its size is linear in the number of parties, with a single index shared by all rounds.
+//
+// Once a round is over, release drops its messages, and only keeps the hashes of the broadcast
+// messages, which are needed for the transcript.
+type queue struct {
+	// index[id] is the position of id in the slices of each round.
+	index map[party.ID]int
+	// rounds[number] are the messages received for the round, or nil if none are expected.
+	rounds [][]*Message
+	// hashes[number] are the hashes of the messages of a released round.
+	hashes [][][]byte
+	// size is the number of bytes of the messages held, and hashesSize the one of the hashes.
+	size       int
+	hashesSize int
+}
+
+// received marks a message which was released after its round was over.
+var received = &Message{}
+
+// newQueue returns a queue expecting a message from each party in every round from 2 to the final one.
+func newQueue(parties []party.ID, final round.Number) *queue {
+	q := &queue{
+		index:  make(map[party.ID]int, len(parties)),
+		rounds: make([][]*Message, final+1),
+		hashes: make([][][]byte, final+1),
+	}
+	for i, id := range parties {
+		q.index[id] = i
+	}
+	for number := round.Number(2); number <= final; number++ {
+		q.rounds[number] = make([]*Message, len(parties))
+	}
+	return q
+}
+
+// expects returns true if messages are expected for the round.
+func (q *queue) expects(number round.Number) bool {
+	return int(number) < len(q.rounds) && q.rounds[number] != nil
+}
+
+// get returns the message received from id in the round, or nil.
+func (q *queue) get(number round.Number, id party.ID) *Message {
+	i, ok := q.index[id]
+	if !ok || !q.expects(number) {
+		return nil
+	}
+	return q.rounds[number][i]
+}
+
+// has returns true if a message was received from id in the round, or if none is expected.
+func (q *queue) has(number round.Number, id party.ID) bool {
+	i, ok := q.index[id]
+	if !ok || !q.expects(number) {
+		return true
+	}
+	return q.rounds[number][i] != nil
+}
+
+// set stores msg, unless a message was already received from its sender in its round.
+func (q *queue) set(msg *Message) {
+	if !q.has(msg.RoundNumber, msg.From) {
+		q.rounds[msg.RoundNumber][q.index[msg.From]] = msg
+		q.size += msg.size()
+	}
+}
+
+// messages returns the messages received in the round, which must not have been released.
+func (q *queue) messages(number round.Number) []*Message {
+	if !q.expects(number) {
+		return nil
+	}
+	msgs := make([]*Message, 0, len(q.rounds[number]))
+	for _, msg := range q.rounds[number] {
+		if msg != nil && msg != received {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
+
+// release drops the messages received in all rounds before number, keeping their hashes.
+func (q *queue) release(number round.Number) {
+	for n := round.Number(2); n < number && int(n) < len(q.rounds); n++ {
+		msgs := q.rounds[n]
+		if msgs == nil || q.hashes[n] != nil {
+			continue
+		}
+		q.hashes[n] = make([][]byte, len(msgs))
+		for i, msg := range msgs {
+			if msg != nil {
+				q.hashes[n][i] = msg.Hash()
+				q.size -= msg.size()
+				q.hashesSize += len(q.hashes[n][i])
+				msgs[i] = received
+			}
+		}
+	}
+}
+
+// restore marks the messages of released rounds as received, with the given hashes by round and sender.
+func (q *queue) restore(hashes map[round.Number]map[party.ID][]byte) {
+	for number, byID := range hashes {
+		if !q.expects(number) {
+			continue
+		}
+		q.hashes[number] = make([][]byte, len(q.rounds[number]))
+		for id, hash := range byID {
+			if i, ok := q.index[id]; ok {
+				q.hashes[number][i] = hash
+				q.hashesSize += len(hash)
+				q.rounds[number][i] = received
+			}
+		}
+	}
+}
+
+// messageHashes returns the hashes of the messages received so far, by round and sender.
+func (q *queue) messageHashes() map[round.Number]map[party.ID][]byte {
+	hashes := make(map[round.Number]map[party.ID][]byte, len(q.rounds))
+	for number, msgs := range q.rounds {
+		if msgs == nil {
+			continue
+		}
+		hashes[round.Number(number)] = make(map[party.ID][]byte, len(msgs))
+		for id, i := range q.index {
+			switch {
+			case q.hashes[number] != nil && q.hashes[number][i] != nil:
+				hashes[round.Number(number)][id] = q.hashes[number][i]
+			case msgs[i] != nil && msgs[i] != received:
+				hashes[round.Number(number)][id] = msgs[i].Hash()
+			}
+		}
+	}
+	return hashes
+}
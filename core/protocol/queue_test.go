@@ -0,0 +1,35 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueueRelease(t *testing.T) {
+	q := newQueue([]party.ID{"a", "b", "c"}, 3)
+	assert.False(t, q.expects(1))
+	assert.True(t, q.expects(2))
+
+	msg := &Message{SSID: []byte("ssid"), From: "b", Protocol: "cmp/sign", RoundNumber: 2, Data: []byte{1}, Broadcast: true}
+	assert.False(t, q.has(2, "b"))
+	q.set(msg)
+	assert.True(t, q.has(2, "b"))
+	assert.Equal(t, []*Message{msg}, q.messages(2))
+	assert.Equal(t, msg.size(), q.size)
+	before := q.messageHashes()
+
+	// releasing keeps the message as received, and its hash for the transcript
+	q.release(3)
+	assert.True(t, q.has(2, "b"))
+	assert.Empty(t, q.messages(2))
+	assert.Equal(t, before, q.messageHashes())
+	assert.Equal(t, msg.Hash(), q.messageHashes()[2]["b"])
+	assert.Zero(t, q.size)
+	assert.Equal(t, len(msg.Hash()), q.hashesSize)
+
+	// a later message for a released round is ignored
+	q.set(&Message{From: "b", RoundNumber: 2, Data: []byte{2}})
+	assert.Equal(t, before, q.messageHashes())
+}
@@ -0,0 +1,115 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+)
+
+// ErrorCode is the reason for an abort, as shared with parties across a trust boundary.
+//
+// Unlike the error itself, a code does not reveal which check failed or why,
+// so that a malicious counterparty cannot use aborts as an oracle on our internal state.
+type ErrorCode uint8
+
+const (
+	// CodeInternal is a failure on our side, such as sampling, hashing or storage.
+	CodeInternal ErrorCode = iota + 1
+	// CodeInvalidMessage is a message which could not be decoded for its round.
+	CodeInvalidMessage
+	// CodeVerificationFailed is a message which was decoded but rejected by the round.
+	CodeVerificationFailed
+	// CodeBroadcastMismatch is a broadcast which was not received identically by all parties.
+	CodeBroadcastMismatch
+	// CodeAbortedByUser is a session stopped locally.
+	CodeAbortedByUser
+	// CodeAbortedByPeer is a session aborted by another party.
+	CodeAbortedByPeer
+	// CodeResourceExhausted is a session which exceeded a local limit, such as its memory budget.
+	CodeResourceExhausted
+)
+
+var codeNames = map[ErrorCode]string{
+	CodeInternal:           "internal error",
+	CodeInvalidMessage:     "invalid message",
+	CodeVerificationFailed: "verification failed",
+	CodeBroadcastMismatch:  "broadcast verification failed",
+	CodeAbortedByUser:      "aborted by user",
+	CodeAbortedByPeer:      "aborted by other party",
+	CodeResourceExhausted:  "resource exhausted",
+}
+
+func (c ErrorCode) String() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("error code %d", uint8(c))
+}
+
+// DetailLevel controls how much of an error is revealed.
+type DetailLevel int
+
+const (
+	// DetailCode only reveals the ErrorCode of an error.
+	DetailCode DetailLevel = iota
+	// DetailFull reveals the full error, and should be reserved for local logs.
+	DetailFull
+)
+
+// WithPeerErrorDetail sets the level of detail of the errors sent to other parties when aborting,
+// DetailCode by default.
+func WithPeerErrorDetail(level DetailLevel) HandlerOption {
+	return func(h *MultiHandler) {
+		h.peerErrorDetail = level
+	}
+}
+
+var (
+	errInvalidMessage        = errors.New("invalid message")
+	errBroadcastVerification = errors.New("broadcast verification failed")
+	errAbortedByUser         = errors.New("aborted by user")
+	errAbortedByPeer         = errors.New("aborted by other party")
+)
+
+// Code returns the ErrorCode of an error returned by a Handler.
+func Code(err error) ErrorCode {
+	var e Error
+	if errors.As(err, &e) && e.Code != 0 {
+		return e.Code
+	}
+	return classify(err, nil)
+}
+
+// classify returns the code of err, where culprits are the parties other than us who were blamed for it.
+func classify(err error, culprits []party.ID) ErrorCode {
+	switch {
+	case errors.Is(err, errAbortedByPeer):
+		return CodeAbortedByPeer
+	case errors.Is(err, errAbortedByUser):
+		return CodeAbortedByUser
+	case errors.Is(err, ErrMemoryBudget), errors.Is(err, ErrSlowConsumer):
+		return CodeResourceExhausted
+	case errors.Is(err, errBroadcastVerification):
+		return CodeBroadcastMismatch
+	case errors.Is(err, errInvalidMessage), errors.Is(err, round.ErrInvalidContent), errors.Is(err, round.ErrNilFields):
+		return CodeInvalidMessage
+	case len(culprits) > 0:
+		// an error blaming another party comes from one of its messages
+		return CodeVerificationFailed
+	}
+	return CodeInternal
+}
+
+// Redact returns the description of err at the given level of detail.
+func Redact(err error, level DetailLevel) string {
+	if err == nil {
+		return ""
+	}
+	if level == DetailFull {
+		return err.Error()
+	}
+	code := Code(err)
+	return fmt.Sprintf("code %d: %s", uint8(code), code)
+}
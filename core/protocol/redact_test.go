@@ -0,0 +1,38 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	proofErr := errors.New("round 3: failed to validate mod proof")
+	tests := []struct {
+		err      error
+		culprits []party.ID
+		code     ErrorCode
+	}{
+		{proofErr, []party.ID{"b"}, CodeVerificationFailed},
+		{proofErr, nil, CodeInternal},
+		{fmt.Errorf("round 2: %w", round.ErrInvalidContent), []party.ID{"b"}, CodeInvalidMessage},
+		{errBroadcastVerification, nil, CodeBroadcastMismatch},
+		{fmt.Errorf("%w with error: \"code 3\"", errAbortedByPeer), []party.ID{"b"}, CodeAbortedByPeer},
+		{fmt.Errorf("%w: 10 bytes retained", ErrMemoryBudget), nil, CodeResourceExhausted},
+	}
+	for _, tt := range tests {
+		err := Error{Culprits: tt.culprits, Err: tt.err, Code: classify(tt.err, tt.culprits)}
+		assert.Equal(t, tt.code, Code(err), tt.err.Error())
+		assert.Equal(t, err.Error(), Redact(err, DetailFull))
+
+		redacted := Redact(err, DetailCode)
+		assert.Contains(t, redacted, tt.code.String())
+		assert.NotContains(t, redacted, "mod proof")
+	}
+	assert.Equal(t, CodeAbortedByUser, Code(errAbortedByUser))
+	assert.Empty(t, Redact(nil, DetailCode))
+}
@@ -0,0 +1,93 @@
+package protocol
+
+import (
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+)
+
+// ResendRoundNumber is the round number of a Resend message.
+//
+// Similarly to a message with round number 0 signaling an abort, a message with this round number
+// is handled by the framework rather than by the protocol's rounds.
+// It asks the recipients to send again the messages of the given rounds,
+// which allows a party who reconnects in the middle of a round to catch up instead of aborting the session.
+const ResendRoundNumber round.Number = ^round.Number(0)
+
+// ResendRequest is the content of a Resend message.
+type ResendRequest struct {
+	// Rounds are the rounds for which messages are requested.
+	Rounds []round.Number
+}
+
+// IsResend returns true if the message is a request for retransmission.
+func (m Message) IsResend() bool {
+	return m.RoundNumber == ResendRoundNumber
+}
+
+// ResendRequest returns a Resend message asking all other parties for the messages of the current round.
+//
+// Retransmitted messages are the exact messages which were originally sent, so that they are
+// subject to the same checks as any other message. In particular, a broadcast message which differs
+// from the one received by other parties is still detected by the broadcast verification.
+func (h *MultiHandler) ResendRequest() (*Message, error) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	r := h.currentRound
+	data, err := cbor.Marshal(&ResendRequest{Rounds: []round.Number{r.Number()}})
+	if err != nil {
+		return nil, fmt.Errorf("protocol: failed to marshal resend request: %w", err)
+	}
+	msg := &Message{
+		SSID:        r.SSID(),
+		From:        r.SelfID(),
+		Protocol:    r.ProtocolID(),
+		RoundNumber: ResendRoundNumber,
+		Data:        data,
+	}
+	if err := h.sign(msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// DroppedResends returns the number of messages which were requested again by other parties but not sent,
+// because the send buffer was full. The requesting parties should ask again once the buffer was read.
+func (h *MultiHandler) DroppedResends() int {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.droppedResends
+}
+
+// resend queues the messages sent to `to` during the requested rounds, signed anew if the handler has a
+// MessageSigner. Messages are dropped once the send buffer is full, so that requests cannot grow it without bound,
+// and counted by DroppedResends.
+func (h *MultiHandler) resend(to party.ID, data []byte) {
+	var req ResendRequest
+	if err := cbor.Unmarshal(data, &req); err != nil {
+		return
+	}
+	requested := make(map[round.Number]bool, len(req.Rounds))
+	for _, number := range req.Rounds {
+		requested[number] = true
+	}
+	for _, msg := range h.sent {
+		if !requested[msg.RoundNumber] || !msg.IsFor(to) {
+			continue
+		}
+		if len(h.pending) >= cap(h.out) {
+			h.droppedResends++
+			continue
+		}
+		// the message may still be read by the consumer of Listen, so the copy is signed instead
+		resent := *msg
+		if err := h.sign(&resent); err != nil {
+			h.droppedResends++
+			continue
+		}
+		h.send(&resent)
+	}
+}
@@ -0,0 +1,127 @@
+package protocol
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signers returns a MessageSigner for each party, and a MessageVerifier accepting their signatures.
+func signers(t *testing.T, ids party.IDSlice) (map[party.ID]MessageSigner, MessageVerifier) {
+	keys := make(map[party.ID]ed25519.PublicKey, len(ids))
+	signers := make(map[party.ID]MessageSigner, len(ids))
+	for _, id := range ids {
+		pk, sk, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		keys[id] = pk
+		signers[id] = func(msg *Message) ([]byte, error) { return ed25519.Sign(sk, msg.Hash()), nil }
+	}
+	verifier := func(msg *Message) error {
+		key, ok := keys[msg.From]
+		if !ok || !ed25519.Verify(key, msg.Hash(), msg.Signature) {
+			return fmt.Errorf("%w: %s", ErrInvalidMessageSignature, msg)
+		}
+		return nil
+	}
+	return signers, verifier
+}
+
+func TestResendSigned(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	sign, verify := signers(t, ids)
+	signed := 0
+	signA := sign["a"]
+	sign["a"] = func(msg *Message) ([]byte, error) {
+		signed++
+		return signA(msg)
+	}
+	handlers := newChattyHandlers(t, ids, nil)
+	for id, h := range handlers {
+		require.NoError(t, h.SetMessageSigner(sign[id]))
+		h.SetMessageVerifier(verify)
+	}
+	a, b := handlers["a"], handlers["b"]
+	originals, _ := DrainMessages(a)
+	require.Len(t, originals, 1)
+	require.NoError(t, verify(originals[0]), "the messages of the first round are signed")
+
+	// b lost the broadcast of a, and asks for it again
+	request, err := b.ResendRequest()
+	require.NoError(t, err)
+	require.NoError(t, verify(request))
+	signed = 0
+	a.Accept(request)
+	resent, _ := DrainMessages(a)
+	require.Len(t, resent, 1)
+	assert.Equal(t, 1, signed, "resent messages are signed anew")
+	assert.Equal(t, originals[0].Hash(), resent[0].Hash())
+	assert.NoError(t, verify(resent[0]))
+	assert.NotSame(t, originals[0], resent[0])
+
+	// a forged request is ignored
+	forged := *request
+	forged.Signature = bytes.Repeat([]byte{1}, ed25519.SignatureSize)
+	a.Accept(&forged)
+	resent, _ = DrainMessages(a)
+	assert.Empty(t, resent)
+	unsigned := *originals[0]
+	unsigned.Signature = nil
+	b.Accept(&unsigned)
+	assert.Equal(t, uint32(2), b.roundNumber.Load(), "an unsigned broadcast is ignored")
+
+	b.Accept(originals[0])
+	msgs, _ := DrainMessages(b)
+	for _, msg := range msgs {
+		a.Accept(msg)
+	}
+	deliverAll(handlers, nil)
+	for _, h := range handlers {
+		_, err := h.Result()
+		require.NoError(t, err)
+	}
+	confirmation, err := b.TranscriptConfirmation()
+	require.NoError(t, err)
+	require.NoError(t, a.ConfirmTranscript(confirmation))
+	confirmation.Signature = nil
+	assert.ErrorIs(t, a.ConfirmTranscript(confirmation), ErrInvalidMessageSignature)
+}
+
+func TestResendDropped(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	handlers := newChattyHandlers(t, ids, nil)
+	a, b := handlers["a"], handlers["b"]
+	request, err := b.ResendRequest()
+	require.NoError(t, err)
+
+	// nobody reads the messages of a, so that its send buffer fills up
+	const requests = 20
+	for i := 0; i < requests; i++ {
+		a.Accept(request)
+	}
+	dropped := a.DroppedResends()
+	assert.Positive(t, dropped)
+	a.mtx.Lock()
+	pending := len(a.pending)
+	a.mtx.Unlock()
+	assert.LessOrEqual(t, pending, cap(a.out))
+
+	// once the send loop returned, the remaining messages are all buffered in the channel
+	sent := 0
+	for {
+		a.mtx.Lock()
+		sending := a.sending
+		a.mtx.Unlock()
+		msgs, _ := DrainMessages(a)
+		sent += len(msgs)
+		if !sending && len(msgs) == 0 {
+			break
+		}
+	}
+	assert.Equal(t, 1+requests, sent+dropped, "every request is either served or reported")
+}
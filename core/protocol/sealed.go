@@ -0,0 +1,139 @@
+package protocol
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/hash"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+var (
+	ErrNotSealed     = errors.New("protocol: message is not sealed")
+	ErrAlreadySealed = errors.New("protocol: message is already sealed")
+	ErrSealBroadcast = errors.New("protocol: broadcast messages cannot be sealed")
+)
+
+// sealedPrefix is prepended to the Data of a sealed message, so that a sealed message
+// can never be confused with a round's cbor content.
+var sealedPrefix = []byte("SEALED\x00")
+
+// sealedData is the envelope stored in the Data field of a sealed message.
+type sealedData struct {
+	// Ephemeral = e⋅G
+	Ephemeral []byte
+	// Ciphertext = AEAD(H(e⋅Y), Data)
+	Ciphertext []byte
+}
+
+// Seal encrypts the content of a point-to-point message to the recipient's public key `to`,
+// typically the ElGamal key Yⱼ from the recipient's public config.
+//
+// This allows a whole ceremony to run over a single broadcast channel: sealed messages can be
+// delivered to everyone, but only the intended recipient is able to read them.
+// The headers (SSID, From, To, Protocol, RoundNumber) are authenticated but left in the clear,
+// so that relays can still route messages and the recipient can discard the ones not addressed to it.
+func Seal(msg *Message, to curve.Point) error {
+	if msg.Broadcast || msg.To == "" {
+		return ErrSealBroadcast
+	}
+	if IsSealed(msg) {
+		return ErrAlreadySealed
+	}
+	group := to.Curve()
+	e := sample.ScalarUnit(rand.Reader, group)
+	ephemeral, err := e.ActOnBase().MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("protocol: seal: %w", err)
+	}
+	aead, err := sealingKey(e.Act(to), ephemeral)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	ciphertext := aead.Seal(nil, nonce, msg.Data, sealedHeader(msg))
+
+	data, err := cbor.Marshal(&sealedData{
+		Ephemeral:  ephemeral,
+		Ciphertext: ciphertext,
+	})
+	if err != nil {
+		return fmt.Errorf("protocol: seal: %w", err)
+	}
+	msg.Data = append(append([]byte{}, sealedPrefix...), data...)
+	return nil
+}
+
+// Open decrypts a message sealed with Seal using the recipient's secret key `secret`,
+// restoring the original content in msg.Data.
+func Open(msg *Message, secret curve.Scalar) error {
+	if !IsSealed(msg) {
+		return ErrNotSealed
+	}
+	var env sealedData
+	if err := cbor.Unmarshal(msg.Data[len(sealedPrefix):], &env); err != nil {
+		return fmt.Errorf("protocol: open: %w", err)
+	}
+	E := secret.Curve().NewPoint()
+	if err := E.UnmarshalBinary(env.Ephemeral); err != nil {
+		return fmt.Errorf("protocol: open: %w", err)
+	}
+	if E.IsIdentity() {
+		return errors.New("protocol: open: ephemeral key is identity")
+	}
+	aead, err := sealingKey(secret.Act(E), env.Ephemeral)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	data, err := aead.Open(nil, nonce, env.Ciphertext, sealedHeader(msg))
+	if err != nil {
+		return fmt.Errorf("protocol: open: %w", err)
+	}
+	msg.Data = data
+	return nil
+}
+
+// IsSealed returns true if the message content was encrypted with Seal.
+func IsSealed(msg *Message) bool {
+	if msg == nil || len(msg.Data) < len(sealedPrefix) {
+		return false
+	}
+	return string(msg.Data[:len(sealedPrefix)]) == string(sealedPrefix)
+}
+
+// sealingKey derives a fresh AEAD key from the shared point and the ephemeral public key.
+// Since the ephemeral key is used only once, a zero nonce is safe.
+func sealingKey(shared curve.Point, ephemeral []byte) (cipher.AEAD, error) {
+	sharedBytes, err := shared.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("protocol: sealing key: %w", err)
+	}
+	h := hash.New(
+		hash.BytesWithDomain{TheDomain: "Sealed Message Shared Point", Bytes: sharedBytes},
+		hash.BytesWithDomain{TheDomain: "Sealed Message Ephemeral", Bytes: ephemeral},
+	)
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(h.Digest(), key); err != nil {
+		return nil, fmt.Errorf("protocol: sealing key: %w", err)
+	}
+	return chacha20poly1305.New(key)
+}
+
+// sealedHeader returns the authenticated headers of a sealed message.
+func sealedHeader(msg *Message) []byte {
+	h := hash.New(
+		hash.BytesWithDomain{TheDomain: "SSID", Bytes: msg.SSID},
+		msg.From,
+		msg.To,
+		hash.BytesWithDomain{TheDomain: "Protocol", Bytes: []byte(msg.Protocol)},
+		msg.RoundNumber,
+	)
+	return h.Sum()
+}
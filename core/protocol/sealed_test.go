@@ -0,0 +1,40 @@
+package protocol
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealOpen(t *testing.T) {
+	group := curve.Secp256k1{}
+	secret := sample.Scalar(rand.Reader, group)
+	public := secret.ActOnBase()
+
+	content := []byte("round content")
+	msg := &Message{SSID: []byte("ssid"), From: "a", To: "b", Protocol: "cmp/sign", RoundNumber: 2, Data: append([]byte{}, content...)}
+	require.NoError(t, Seal(msg, public))
+	assert.True(t, IsSealed(msg))
+	assert.NotContains(t, string(msg.Data), string(content))
+	assert.ErrorIs(t, Seal(msg, public), ErrAlreadySealed)
+
+	// another key cannot open the message
+	other := *msg
+	assert.Error(t, Open(&other, sample.Scalar(rand.Reader, group)))
+
+	// headers are authenticated
+	tampered := *msg
+	tampered.RoundNumber = 3
+	assert.Error(t, Open(&tampered, secret))
+
+	require.NoError(t, Open(msg, secret))
+	assert.Equal(t, content, msg.Data)
+	assert.ErrorIs(t, Open(msg, secret), ErrNotSealed)
+
+	bcast := &Message{From: "a", Broadcast: true, Data: content}
+	assert.ErrorIs(t, Seal(bcast, public), ErrSealBroadcast)
+}
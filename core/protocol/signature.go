@@ -0,0 +1,67 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidMessageSignature is returned by a MessageVerifier refusing the signature of a message.
+var ErrInvalidMessageSignature = errors.New("protocol: invalid message signature")
+
+// MessageSigner returns the signature of msg by its sender, such as with the identity key of the party,
+// so that the other parties can authenticate it whoever relays it. It should sign msg.Hash(),
+// which covers every field but the signature.
+type MessageSigner func(msg *Message) ([]byte, error)
+
+// MessageVerifier returns an error if msg.Signature is not a valid signature of msg by msg.From.
+type MessageVerifier func(msg *Message) error
+
+// SetMessageSigner signs the messages handed to Listen, including the ones sent again to a party catching up
+// (see ResendRequest), which are signed anew, and the messages returned by Handshake, ResendRequest and
+// TranscriptConfirmation. Heartbeats, which only tell that the sender is alive, are not signed.
+//
+// It must be called before the messages are read from Listen: the messages of the first round,
+// sent by NewMultiHandler, are signed when it is called.
+func (h *MultiHandler) SetMessageSigner(signer MessageSigner) error {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.signer = signer
+	for _, msg := range h.sent {
+		if err := h.sign(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetMessageVerifier makes the handler ignore the messages whose signature verifier refuses, except heartbeats.
+// The sender of such a message is not blamed, since anyone relaying it may have forged it.
+// It should be called before any message is accepted.
+func (h *MultiHandler) SetMessageVerifier(verifier MessageVerifier) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.verifier = verifier
+}
+
+// sign sets the signature of msg, if the handler has a MessageSigner. It must be called while holding mtx,
+// before msg is handed to anyone.
+func (h *MultiHandler) sign(msg *Message) error {
+	if h.signer == nil {
+		return nil
+	}
+	signature, err := h.signer(msg)
+	if err != nil {
+		return fmt.Errorf("protocol: failed to sign message: %w", err)
+	}
+	msg.Signature = signature
+	return nil
+}
+
+// verified returns true if msg is a heartbeat, or if its signature is accepted by the MessageVerifier, if any.
+// It must be called while holding mtx.
+func (h *MultiHandler) verified(msg *Message) bool {
+	if h.verifier == nil || msg.IsHeartbeat() {
+		return true
+	}
+	return h.verifier(msg) == nil
+}
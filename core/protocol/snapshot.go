@@ -0,0 +1,236 @@
+package protocol
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+var (
+	ErrInvalidSnapshot = errors.New("protocol: invalid snapshot")
+	// ErrCheckpoint ends a session whose state could not be persisted before the messages of a new round were sent.
+	ErrCheckpoint = errors.New("protocol: failed to checkpoint the session")
+)
+
+// SnapshotVersion is the version of the encoding of snapshots, recorded in each of them.
+// Snapshots taken before versions were recorded have version 0.
+const SnapshotVersion = 1
+
+// snapshotAD is the associated data of an encrypted snapshot, so that it cannot be confused with other ciphertexts.
+var snapshotAD = []byte("protocol snapshot")
+
+// ResumeFunc recreates the round of a session restored from a Snapshot, from the state kept in the protocol's stores.
+// It is given the SSID of the session, and the number of the round to recreate.
+type ResumeFunc func(ssid []byte, number round.Number) (round.Session, error)
+
+// Snapshot is the state of an in-flight MultiHandler which is not kept in the protocol's stores,
+// so that a session can be moved to another host with access to the same stores.
+type Snapshot struct {
+	// Version is the SnapshotVersion of the host which took the snapshot.
+	Version  int `cbor:",omitempty"`
+	SSID     []byte
+	Protocol string
+	SelfID   party.ID
+	// Round is the number of the current round.
+	Round round.Number
+	// Messages are the messages received for the current and later rounds, including our own broadcasts.
+	Messages []*Message
+	// Transcript holds the hashes of the broadcast messages of previous rounds, by round and sender.
+	Transcript map[round.Number]map[party.ID][]byte
+	// BroadcastHashes are the verification hashes of the broadcast rounds which are over.
+	BroadcastHashes map[round.Number][]byte
+	// Sent are the messages sent so far, which are retransmitted on request.
+	Sent []*Message
+}
+
+// Snapshot returns the state of the session, which can be resumed with ResumeMultiHandler.
+//
+// Messages accepted after the snapshot are not part of it, so the handler should not be used afterwards.
+// An error is returned if the session is already over.
+func (h *MultiHandler) Snapshot() (*Snapshot, error) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.snapshot()
+}
+
+// snapshot implements Snapshot. It must be called while holding mtx.
+func (h *MultiHandler) snapshot() (*Snapshot, error) {
+	if h.err != nil || h.result != nil {
+		return nil, fmt.Errorf("%w: session is over", ErrInvalidSnapshot)
+	}
+
+	r := h.currentRound
+	s := &Snapshot{
+		Version:         SnapshotVersion,
+		SSID:            r.SSID(),
+		Protocol:        r.ProtocolID(),
+		SelfID:          r.SelfID(),
+		Round:           r.Number(),
+		Transcript:      map[round.Number]map[party.ID][]byte{},
+		BroadcastHashes: make(map[round.Number][]byte, len(h.broadcastHashes)),
+		Sent:            append([]*Message(nil), h.sent...),
+	}
+	for number := r.Number(); number <= r.FinalRoundNumber(); number++ {
+		s.Messages = append(s.Messages, h.broadcast.messages(number)...)
+		s.Messages = append(s.Messages, h.messages.messages(number)...)
+	}
+	for number, hashes := range h.broadcast.messageHashes() {
+		if number < r.Number() {
+			s.Transcript[number] = hashes
+		}
+	}
+	for number, hash := range h.broadcastHashes {
+		s.BroadcastHashes[number] = hash
+	}
+	return s, nil
+}
+
+// SetCheckpointer sets the function persisting the state of the session, and persists its current state.
+//
+// Each time the session moves to a new round, its snapshot is passed to checkpoint before the messages
+// of the round are sent, so that a session resumed from the last persisted snapshot never computes again,
+// with fresh randomness, a round whose messages may have been delivered. If checkpoint fails,
+// the handler is abandoned with ErrCheckpoint, without sending any further message.
+//
+// It must be called before the handler accepts any message.
+func (h *MultiHandler) SetCheckpointer(checkpoint func(*Snapshot) error) error {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.checkpointer = checkpoint
+	return h.persist()
+}
+
+// Checkpoint persists the current state of the session with the function set by SetCheckpointer,
+// such as to save the messages received since the last round. If it fails, the handler is abandoned.
+func (h *MultiHandler) Checkpoint() error {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.persist()
+}
+
+// persist passes the snapshot of the session to the checkpointer, if any. It must be called while holding mtx.
+func (h *MultiHandler) persist() error {
+	if h.checkpointer == nil {
+		return nil
+	}
+	s, err := h.snapshot()
+	if err != nil {
+		return err
+	}
+	if err := h.checkpointer(s); err != nil {
+		err = fmt.Errorf("%w: %w", ErrCheckpoint, err)
+		h.abandon(err)
+		return err
+	}
+	return nil
+}
+
+// ResumeMultiHandler returns a handler continuing the session of the snapshot,
+// whose current round is recreated by resume.
+func ResumeMultiHandler(resume ResumeFunc, s *Snapshot, opts ...HandlerOption) (*MultiHandler, error) {
+	return resumeMultiHandler(resume, s, nil, opts...)
+}
+
+// resumeMultiHandler implements ResumeMultiHandler, with the checkpointer of the handler set
+// before the restored round is finalized.
+func resumeMultiHandler(resume ResumeFunc, s *Snapshot, checkpoint func(*Snapshot) error, opts ...HandlerOption) (*MultiHandler, error) {
+	if s == nil {
+		return nil, fmt.Errorf("%w: nil snapshot", ErrInvalidSnapshot)
+	}
+	r, err := resume(s.SSID, s.Round)
+	if err != nil {
+		return nil, fmt.Errorf("protocol: failed to resume round: %w", err)
+	}
+	if r.Number() != s.Round || r.ProtocolID() != s.Protocol || r.SelfID() != s.SelfID || !bytes.Equal(r.SSID(), s.SSID) {
+		return nil, fmt.Errorf("%w: resumed round %d of %s does not match", ErrInvalidSnapshot, r.Number(), r.ProtocolID())
+	}
+
+	h := &MultiHandler{
+		currentRound:    r,
+		rounds:          map[round.Number]round.Session{r.Number(): r},
+		messages:        newQueue(r.PartyIDs(), r.FinalRoundNumber()),
+		broadcast:       newQueue(r.PartyIDs(), r.FinalRoundNumber()),
+		broadcastHashes: make(map[round.Number][]byte, len(s.BroadcastHashes)),
+		sent:            s.Sent,
+		out:             make(chan *Message, 2*r.N()),
+		done:            make(chan struct{}),
+		stopped:         make(chan struct{}),
+		ssid:            r.SSID(),
+		selfID:          r.SelfID(),
+		protocolID:      r.ProtocolID(),
+		checkpointer:    checkpoint,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	for _, msg := range s.Sent {
+		h.sentSize += msg.size()
+	}
+	for number, hash := range s.BroadcastHashes {
+		h.broadcastHashes[number] = hash
+	}
+	h.broadcast.restore(s.Transcript)
+	for _, msg := range s.Messages {
+		if msg == nil || msg.RoundNumber < s.Round || msg.RoundNumber > r.FinalRoundNumber() || !r.PartyIDs().Contains(msg.From) {
+			return nil, fmt.Errorf("%w: unexpected message %v", ErrInvalidSnapshot, msg)
+		}
+		h.store(msg)
+	}
+	h.roundNumber.Store(uint32(r.Number()))
+	h.resume()
+	return h, nil
+}
+
+// resume finalizes the restored round if all its messages were received.
+func (h *MultiHandler) resume() {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	defer h.recoverPoolPanic()
+	h.finalize()
+}
+
+// Encrypt returns the snapshot encrypted with XChaCha20-Poly1305 under key, which must be 32 bytes long.
+func (s *Snapshot) Encrypt(key []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("protocol: snapshot: %w", err)
+	}
+	data, err := cbor.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("protocol: snapshot: %w", err)
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(data)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("protocol: snapshot: %w", err)
+	}
+	return aead.Seal(nonce, nonce, data, snapshotAD), nil
+}
+
+// DecryptSnapshot decrypts a snapshot encrypted with Snapshot.Encrypt.
+func DecryptSnapshot(data, key []byte) (*Snapshot, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("protocol: snapshot: %w", err)
+	}
+	if len(data) < aead.NonceSize()+aead.Overhead() {
+		return nil, fmt.Errorf("%w: too short", ErrInvalidSnapshot)
+	}
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], snapshotAD)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	s := &Snapshot{}
+	if err := cbor.Unmarshal(plaintext, s); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	if s.Version > SnapshotVersion {
+		return nil, fmt.Errorf("%w: version %d is not supported", ErrInvalidSnapshot, s.Version)
+	}
+	return s, nil
+}
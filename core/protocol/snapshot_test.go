@@ -0,0 +1,103 @@
+package protocol
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// panicRound is a round whose Finalize panics as a job of the pool would.
+type panicRound struct {
+	*chattyRound
+}
+
+func (r *panicRound) Finalize(chan<- *round.Message) (round.Session, error) {
+	panic(&pool.PanicError{Label: "test", Value: "boom"})
+}
+
+func TestResumePoolPanic(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	h, err := NewMultiHandler(startChattyProtocol(t, 4, "a", ids, true), []byte("resume"))
+	require.NoError(t, err)
+	s, err := h.Snapshot()
+	require.NoError(t, err)
+
+	helper := h.currentRound.(*chattyBroadcastRound).Helper
+	resumed, err := ResumeMultiHandler(func(ssid []byte, number round.Number) (round.Session, error) {
+		return &panicRound{chattyRound: &chattyRound{Helper: helper, number: number}}, nil
+	}, s)
+	require.NoError(t, err, "a panic of the pool aborts the session instead of crashing")
+	_, err = resumed.Result()
+	var panicErr *pool.PanicError
+	assert.ErrorAs(t, err, &panicErr)
+	<-resumed.Done()
+}
+
+func TestCheckpointBeforeSend(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	handlers := newChattyHandlers(t, ids, nil)
+	var checkpointed []round.Number
+	require.NoError(t, handlers["a"].SetCheckpointer(func(s *Snapshot) error {
+		checkpointed = append(checkpointed, s.Round)
+		return nil
+	}))
+	deliverAll(handlers, nil)
+	for _, h := range handlers {
+		_, err := h.Result()
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []round.Number{2, 3, 4}, checkpointed)
+
+	// a round which cannot be persisted is never sent, and the session is abandoned without an abort message
+	handlers = newChattyHandlers(t, ids, nil)
+	failure := errors.New("disk full")
+	require.NoError(t, handlers["a"].SetCheckpointer(func(s *Snapshot) error {
+		if s.Round >= 3 {
+			return failure
+		}
+		return nil
+	}))
+	var sent []*Message
+	deliverAll(handlers, func(msg *Message, _ party.ID) bool {
+		if msg.From == "a" {
+			sent = append(sent, msg)
+		}
+		return false
+	})
+	_, err := handlers["a"].Result()
+	require.ErrorIs(t, err, ErrCheckpoint)
+	require.ErrorIs(t, err, failure)
+	require.NotEmpty(t, sent)
+	for _, msg := range sent {
+		assert.Equal(t, round.Number(2), msg.RoundNumber)
+	}
+	require.ErrorIs(t, handlers["a"].Checkpoint(), ErrInvalidSnapshot)
+}
+
+func TestSnapshotVersion(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	h, err := NewMultiHandler(startChattyProtocol(t, 4, "a", ids, true), []byte("resume"))
+	require.NoError(t, err)
+	s, err := h.Snapshot()
+	require.NoError(t, err)
+	assert.Equal(t, SnapshotVersion, s.Version)
+
+	key := make([]byte, 32)
+	data, err := s.Encrypt(key)
+	require.NoError(t, err)
+	decrypted, err := DecryptSnapshot(data, key)
+	require.NoError(t, err)
+	assert.Equal(t, s.Version, decrypted.Version)
+
+	// snapshots taken by a later version are refused
+	s.Version = SnapshotVersion + 1
+	data, err = s.Encrypt(key)
+	require.NoError(t, err)
+	_, err = DecryptSnapshot(data, key)
+	assert.ErrorIs(t, err, ErrInvalidSnapshot)
+}
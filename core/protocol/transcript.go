@@ -0,0 +1,131 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/mr-shifu/mpc-lib/core/hash"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+)
+
+var (
+	ErrTranscriptMismatch     = errors.New("protocol: transcript differs from other party")
+	ErrTranscriptNotConfirmed = errors.New("protocol: transcript not confirmed by all parties")
+)
+
+// ConfirmRoundNumber is the round number of a transcript confirmation message,
+// which is exchanged after the protocol completed (see TranscriptConfirmation).
+const ConfirmRoundNumber round.Number = ResendRoundNumber - 1
+
+// IsTranscriptConfirmation returns true if the message confirms the sender's transcript hash.
+func (m Message) IsTranscriptConfirmation() bool {
+	return m.RoundNumber == ConfirmRoundNumber
+}
+
+// transcriptHash returns the canonical hash of a session's transcript.
+//
+// Only broadcast messages are included, since they are the only messages received by all parties.
+// They are hashed in order of round, and then of sender, independently of the order in which they were received.
+// hashes[number][id] is the hash of the broadcast message sent by id in the round.
+func transcriptHash(ssid []byte, protocolID string, hashes map[round.Number]map[party.ID][]byte) []byte {
+	numbers := make([]round.Number, 0, len(hashes))
+	for number := range hashes {
+		numbers = append(numbers, number)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+	h := hash.New(
+		hash.BytesWithDomain{TheDomain: "SSID", Bytes: ssid},
+		hash.BytesWithDomain{TheDomain: "Protocol", Bytes: []byte(protocolID)},
+	)
+	for _, number := range numbers {
+		ids := make([]party.ID, 0, len(hashes[number]))
+		for id := range hashes[number] {
+			ids = append(ids, id)
+		}
+		for _, id := range party.NewIDSlice(ids) {
+			_ = h.WriteAny(number, id, hash.BytesWithDomain{TheDomain: "Message", Bytes: hashes[number][id]})
+		}
+	}
+	return h.Sum()
+}
+
+// TranscriptHash returns the canonical hash of the broadcast messages sent and received so far,
+// including the handshakes once all parties announced their build.
+// Once the protocol has completed, all honest parties obtain the same value.
+func (h *MultiHandler) TranscriptHash() []byte {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.transcriptHash()
+}
+
+// transcriptHash implements TranscriptHash. It must be called while holding mtx.
+func (h *MultiHandler) transcriptHash() []byte {
+	return transcriptHash(h.currentRound.SSID(), h.currentRound.ProtocolID(), h.transcriptHashes())
+}
+
+// TranscriptConfirmation returns a message confirming this party's transcript hash,
+// to be sent to all other parties once the protocol has completed.
+func (h *MultiHandler) TranscriptConfirmation() (*Message, error) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if _, err := h.outcome(); err != nil {
+		return nil, err
+	}
+	r := h.currentRound
+	msg := &Message{
+		SSID:        r.SSID(),
+		From:        r.SelfID(),
+		Protocol:    r.ProtocolID(),
+		RoundNumber: ConfirmRoundNumber,
+		Data:        h.transcriptHash(),
+		Broadcast:   true,
+	}
+	if err := h.sign(msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// ConfirmTranscript checks the transcript hash confirmed by another party against our own.
+func (h *MultiHandler) ConfirmTranscript(msg *Message) error {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	r := h.currentRound
+	if msg == nil || !msg.IsTranscriptConfirmation() || msg.Protocol != r.ProtocolID() || !bytes.Equal(msg.SSID, r.SSID()) {
+		return errors.New("protocol: not a transcript confirmation for this session")
+	}
+	if msg.From == r.SelfID() || !r.PartyIDs().Contains(msg.From) {
+		return fmt.Errorf("protocol: unknown sender %s", msg.From)
+	}
+	if !h.verified(msg) {
+		return fmt.Errorf("%w: transcript confirmation of %s", ErrInvalidMessageSignature, msg.From)
+	}
+	if _, err := h.outcome(); err != nil {
+		return err
+	}
+	if !bytes.Equal(h.transcriptHash(), msg.Data) {
+		return fmt.Errorf("%w: %s", ErrTranscriptMismatch, msg.From)
+	}
+	if h.confirmed == nil {
+		h.confirmed = map[party.ID]bool{}
+	}
+	h.confirmed[msg.From] = true
+	return nil
+}
+
+// ConfirmedTranscript returns the transcript hash once all other parties have confirmed it.
+// The value can be stored with the protocol's result, so that disputes can reference an agreed transcript.
+func (h *MultiHandler) ConfirmedTranscript() ([]byte, error) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	for _, id := range h.currentRound.OtherPartyIDs() {
+		if !h.confirmed[id] {
+			return nil, fmt.Errorf("%w: missing %s", ErrTranscriptNotConfirmed, id)
+		}
+	}
+	return h.transcriptHash(), nil
+}
@@ -0,0 +1,136 @@
+package protocol
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runChatty runs the broadcast variant of the chatty protocol between ids, and returns the handlers once completed.
+// The messages are delivered in the order of ids, or in the reverse order if reverse is set.
+func runChatty(t *testing.T, ids party.IDSlice, reverse bool) map[party.ID]*MultiHandler {
+	handlers := make(map[party.ID]*MultiHandler, len(ids))
+	for _, id := range ids {
+		h, err := NewMultiHandler(startChattyProtocol(t, 4, id, ids, true), []byte("transcript"))
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+	for {
+		var msgs []*Message
+		for _, id := range ids {
+			pending, _ := DrainMessages(handlers[id])
+			if reverse {
+				msgs = append(pending, msgs...)
+			} else {
+				msgs = append(msgs, pending...)
+			}
+		}
+		if len(msgs) == 0 {
+			return handlers
+		}
+		for _, msg := range msgs {
+			for _, id := range ids {
+				if id != msg.From && msg.IsFor(id) {
+					handlers[id].Accept(msg)
+				}
+			}
+		}
+	}
+}
+
+func TestTranscriptConfirmation(t *testing.T) {
+	ids := party.IDSlice{"a", "b", "c"}
+	handlers := runChatty(t, ids, false)
+	confirmations := make(map[party.ID]*Message, len(ids))
+	for _, id := range ids {
+		_, err := handlers[id].Result()
+		require.NoError(t, err)
+		confirmations[id], err = handlers[id].TranscriptConfirmation()
+		require.NoError(t, err)
+		assert.True(t, confirmations[id].IsTranscriptConfirmation())
+		assert.Equal(t, handlers[ids[0]].TranscriptHash(), handlers[id].TranscriptHash())
+	}
+
+	a := handlers["a"]
+	_, err := a.ConfirmedTranscript()
+	assert.ErrorIs(t, err, ErrTranscriptNotConfirmed)
+	require.NoError(t, a.ConfirmTranscript(confirmations["b"]))
+	_, err = a.ConfirmedTranscript()
+	assert.ErrorIs(t, err, ErrTranscriptNotConfirmed)
+
+	forged := *confirmations["c"]
+	forged.Data = append([]byte(nil), forged.Data...)
+	forged.Data[0] ^= 1
+	assert.ErrorIs(t, a.ConfirmTranscript(&forged), ErrTranscriptMismatch)
+	assert.Error(t, a.ConfirmTranscript(confirmations["a"]), "a party does not confirm its own transcript")
+	unknown := *confirmations["c"]
+	unknown.From = "d"
+	assert.Error(t, a.ConfirmTranscript(&unknown))
+	other := *confirmations["c"]
+	other.SSID = []byte("other")
+	assert.Error(t, a.ConfirmTranscript(&other))
+
+	require.NoError(t, a.ConfirmTranscript(confirmations["c"]))
+	transcript, err := a.ConfirmedTranscript()
+	require.NoError(t, err)
+	assert.Equal(t, a.TranscriptHash(), transcript)
+}
+
+func TestTranscriptBindsBroadcasts(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	first, second := runChatty(t, ids, false), runChatty(t, ids, true)
+	// the same session gives the same transcript, whatever the order of the messages
+	assert.Equal(t, first["a"].TranscriptHash(), second["b"].TranscriptHash())
+
+	// a handler which did not complete cannot confirm
+	h, err := NewMultiHandler(startChattyProtocol(t, 4, "a", ids, true), []byte("transcript"))
+	require.NoError(t, err)
+	_, err = h.TranscriptConfirmation()
+	assert.Error(t, err)
+	assert.NotEqual(t, first["a"].TranscriptHash(), h.TranscriptHash())
+	confirmation, err := first["b"].TranscriptConfirmation()
+	require.NoError(t, err)
+	assert.Error(t, h.ConfirmTranscript(confirmation))
+}
+
+func TestTranscriptConcurrentAccept(t *testing.T) {
+	ids := party.IDSlice{"a", "b"}
+	handlers := make(map[party.ID]*MultiHandler, len(ids))
+	for _, id := range ids {
+		h, err := NewMultiHandler(startChattyProtocol(t, 16, id, ids, true), []byte("transcript"))
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+	// the transcript may be queried while the handler is processing messages
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_, _ = handlers["a"].TranscriptConfirmation()
+			_ = handlers["a"].TranscriptHash()
+		}
+	}()
+	for done := false; !done; {
+		done = true
+		for _, id := range ids {
+			msgs, _ := DrainMessages(handlers[id])
+			for _, msg := range msgs {
+				done = false
+				for _, other := range ids {
+					if other != id && msg.IsFor(other) {
+						handlers[other].Accept(msg)
+					}
+				}
+			}
+		}
+	}
+	wg.Wait()
+	for _, h := range handlers {
+		_, err := h.Result()
+		require.NoError(t, err)
+	}
+}
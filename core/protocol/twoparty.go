@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"sync"
 
-	"github.com/fxamacker/cbor/v2"
 	"github.com/mr-shifu/mpc-lib/lib/round"
 )
 
@@ -97,8 +96,8 @@ func (h *TwoPartyHandler) canAdvance() bool {
 
 func extractRoundMessage(r round.Session, msg *Message) (round.Message, error) {
 	content := r.MessageContent()
-	if err := cbor.Unmarshal(msg.Data, content); err != nil {
-		return round.Message{}, fmt.Errorf("failed to unmarshal message: %w", err)
+	if err := round.DecodeContent(msg.Data, content); err != nil {
+		return round.Message{}, err
 	}
 	roundMsg := round.Message{
 		From:      msg.From,
@@ -145,7 +144,7 @@ func (h *TwoPartyHandler) advance() {
 		}
 		close(out)
 		for roundMsg := range out {
-			data, err := cbor.Marshal(roundMsg.Content)
+			data, err := round.MarshalContent(roundMsg.Content)
 			if err != nil {
 				panic(fmt.Errorf("failed to marshal round message: %w", err))
 			}
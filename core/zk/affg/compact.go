@@ -0,0 +1,141 @@
+package zkaffg
+
+import (
+	"github.com/cronokirby/saferith"
+	"github.com/mr-shifu/mpc-lib/core/math/arith"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
+)
+
+// CompactProof is the challenge-response form of a Proof.
+//
+// Instead of the commitments A, Bₓ, By, E and F, it carries the challenge e, from which the verifier
+// recomputes them with the verification equations, before checking that they hash to e.
+// This drops two ciphertexts mod N², a point and two Pedersen commitments from the proof.
+type CompactProof struct {
+	group curve.Curve
+	// Challenge = e
+	Challenge *saferith.Int
+	// S = sˣ tᵐ (mod N)
+	S *saferith.Nat
+	// T = sʸ tᵘ (mod N)
+	T *saferith.Nat
+	// Z1 = Z₁ = α + e⋅x
+	Z1 *saferith.Int
+	// Z2 = Z₂ = β + e⋅y
+	Z2 *saferith.Int
+	// Z3 = Z₃ = γ + e⋅m
+	Z3 *saferith.Int
+	// Z4 = Z₄ = δ + e⋅μ
+	Z4 *saferith.Int
+	// W = w = ρ⋅sᵉ (mod N₀)
+	W *saferith.Nat
+	// Wy = wy = ρy⋅rᵉ (mod N₁)
+	Wy *saferith.Nat
+}
+
+// Compact returns the CompactProof of a proof created with NewProof, or nil for a decoded proof.
+func (p *Proof) Compact() *CompactProof {
+	if p == nil || p.e == nil {
+		return nil
+	}
+	return &CompactProof{
+		group:     p.group,
+		Challenge: p.e,
+		S:         p.S,
+		T:         p.T,
+		Z1:        p.Z1,
+		Z2:        p.Z2,
+		Z3:        p.Z3,
+		Z4:        p.Z4,
+		W:         p.W,
+		Wy:        p.Wy,
+	}
+}
+
+// NewCompactProof is NewProof returning the CompactProof.
+func NewCompactProof(group curve.Curve, hash hash.Hash, public Public, private Private) *CompactProof {
+	return NewProof(group, hash, public, private).Compact()
+}
+
+func (p *CompactProof) Verify(hash hash.Hash, public Public) bool {
+	if p == nil || p.Challenge == nil || p.S == nil || p.T == nil ||
+		p.Z1 == nil || p.Z2 == nil || p.Z3 == nil || p.Z4 == nil || p.W == nil || p.Wy == nil {
+		return false
+	}
+	if !arith.IsInIntervalLEps(p.Z1, public.Profile) {
+		return false
+	}
+	if !arith.IsInIntervalLPrimeEps(p.Z2, public.Profile) {
+		return false
+	}
+	if !arith.IsValidNatModN(public.Prover.N(), p.Wy) || !arith.IsValidNatModN(public.Verifier.N(), p.W) {
+		return false
+	}
+	if !arith.IsValidNatModN(public.Aux.N(), p.S, p.T) {
+		return false
+	}
+
+	proof := &Proof{
+		group:      p.group,
+		Commitment: p.commitment(public),
+		Z1:         p.Z1,
+		Z2:         p.Z2,
+		Z3:         p.Z3,
+		Z4:         p.Z4,
+		W:          p.W,
+		Wy:         p.Wy,
+	}
+	if !proof.IsValid(public) {
+		return false
+	}
+
+	e, err := challenge(hash, p.group, public, proof.Commitment)
+	if err != nil {
+		return false
+	}
+	return e.Eq(p.Challenge) == 1
+}
+
+// VerifySegment recomputes the commitment of the compact proof from its responses, and checks its challenge
+// against the hash state replayed from the sender's segment of a recorded transcript.
+func (p *CompactProof) VerifySegment(segment hash.Segment, public Public) bool {
+	h, err := segment.Hash()
+	if err != nil {
+		return false
+	}
+	return p.Verify(h, public)
+}
+
+// commitment recomputes the commitment of the proof from its challenge and responses.
+func (p *CompactProof) commitment(public Public) *Commitment {
+	verifier := public.Verifier
+	prover := public.Prover
+	minusE := new(saferith.Int).SetInt(p.Challenge).Neg(1)
+
+	// A = Enc₀(z₂;w) ⊕ (z₁ ⊙ Kv) ⊕ (-e ⊙ Dv)
+	A := verifier.EncWithNonce(p.Z2, p.W).
+		Add(verifier, public.Kv.Clone().Mul(verifier, p.Z1)).
+		Add(verifier, public.Dv.Clone().Mul(verifier, minusE))
+
+	// Bₓ = [z₁]G - [e]Xp
+	Bx := p.group.NewScalar().SetNat(p.Z1.Mod(p.group.Order())).ActOnBase()
+	Bx = Bx.Sub(p.group.NewScalar().SetNat(p.Challenge.Mod(p.group.Order())).Act(public.Xp))
+
+	// By = Enc₁(z₂;wy) ⊕ (-e ⊙ Fp)
+	By := prover.EncWithNonce(p.Z2, p.Wy).Add(prover, public.Fp.Clone().Mul(prover, minusE))
+
+	// E = sᶻ¹ tᶻ³ S⁻ᵉ (mod N)
+	E := public.Aux.Commit(p.Z1, p.Z3)
+	E.ModMul(E, public.Aux.NArith().ExpI(p.S, minusE), public.Aux.N())
+
+	// F = sᶻ² tᶻ⁴ T⁻ᵉ (mod N)
+	F := public.Aux.Commit(p.Z2, p.Z4)
+	F.ModMul(F, public.Aux.NArith().ExpI(p.T, minusE), public.Aux.N())
+
+	return &Commitment{A: A, Bx: Bx, By: By, E: E, S: p.S, F: F, T: p.T}
+}
+
+func EmptyCompact(group curve.Curve) *CompactProof {
+	return &CompactProof{group: group}
+}
@@ -2,13 +2,16 @@ package zkaffp
 
 import (
 	"crypto/rand"
+	"io"
 
 	"github.com/cronokirby/saferith"
+	core_hash "github.com/mr-shifu/mpc-lib/core/hash"
 	"github.com/mr-shifu/mpc-lib/core/math/arith"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
 	"github.com/mr-shifu/mpc-lib/core/paillier"
 	"github.com/mr-shifu/mpc-lib/core/pedersen"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
 )
 
@@ -31,6 +34,29 @@ type Public struct {
 	// Verifier = N₀
 	Prover, Verifier *paillier.PublicKey
 	Aux              *pedersen.Parameters
+	// Profile is the profile of the range checks, params.Standard if zero.
+	Profile params.Profile
+}
+
+// WriteTo implements io.WriterTo, writing each value of the statement framed as in the hash.
+func (p Public) WriteTo(w io.Writer) (int64, error) {
+	return p.fields().WriteTo(w)
+}
+
+// Domain implements hash.WriterToWithDomain.
+func (Public) Domain() string {
+	return "zkaffp Public"
+}
+
+// Size implements hash.SizedWriterToWithDomain, so that the statement is streamed into the hash.
+func (p Public) Size() int {
+	return p.fields().Size()
+}
+
+func (p Public) fields() core_hash.Fields {
+	return core_hash.Fields{TheDomain: p.Domain(), Values: []interface{}{
+		p.Aux, p.Prover, p.Verifier, p.Kv, p.Dv, p.Fp, p.Xp,
+	}}
 }
 
 type Private struct {
@@ -112,16 +138,16 @@ func NewProof(group curve.Curve, hash hash.Hash, public Public, private Private)
 	verifier := public.Verifier
 	prover := public.Prover
 
-	alpha := sample.IntervalLEps(rand.Reader)
-	beta := sample.IntervalLPrimeEps(rand.Reader)
+	alpha := sample.IntervalLEps(rand.Reader, public.Profile)
+	beta := sample.IntervalLPrimeEps(rand.Reader, public.Profile)
 
 	rho := sample.UnitModN(rand.Reader, N0)
 	rhoX := sample.UnitModN(rand.Reader, N1)
 	rhoY := sample.UnitModN(rand.Reader, N1)
 
-	gamma := sample.IntervalLEpsN(rand.Reader)
+	gamma := sample.IntervalLEpsN(rand.Reader, public.Profile)
 	m := sample.IntervalLN(rand.Reader)
-	delta := sample.IntervalLEpsN(rand.Reader)
+	delta := sample.IntervalLEpsN(rand.Reader, public.Profile)
 	mu := sample.IntervalLN(rand.Reader)
 
 	cAlpha := public.Kv.Clone().Mul(verifier, alpha)            // = Cᵃ mod N₀ = α ⊙ Kv
@@ -187,10 +213,10 @@ func (p *Proof) Verify(group curve.Curve, hash hash.Hash, public Public) bool {
 	verifier := public.Verifier
 	prover := public.Prover
 
-	if !arith.IsInIntervalLEps(p.Z1) {
+	if !arith.IsInIntervalLEps(p.Z1, public.Profile) {
 		return false
 	}
-	if !arith.IsInIntervalLPrimeEps(p.Z2) {
+	if !arith.IsInIntervalLPrimeEps(p.Z2, public.Profile) {
 		return false
 	}
 
@@ -235,9 +261,18 @@ func (p *Proof) Verify(group curve.Curve, hash hash.Hash, public Public) bool {
 	return true
 }
 
+// VerifySegment checks that Dv and Fp result from the affine operation on Kv by the plaintext x of Xp,
+// with the hash state replayed from the prover's segment of a recorded transcript.
+func (p *Proof) VerifySegment(group curve.Curve, segment hash.Segment, public Public) bool {
+	h, err := segment.Hash()
+	if err != nil {
+		return false
+	}
+	return p.Verify(group, h, public)
+}
+
 func challenge(hash hash.Hash, group curve.Curve, public Public, commitment *Commitment) (e *saferith.Int, err error) {
-	err = hash.WriteAny(public.Aux, public.Prover, public.Verifier,
-		public.Kv, public.Dv, public.Fp, public.Xp,
+	err = hash.WriteAny(public,
 		commitment.A, commitment.Bx, commitment.By,
 		commitment.E, commitment.S, commitment.F, commitment.T)
 
@@ -23,8 +23,7 @@ func TestAffG(t *testing.T) {
 	hash_ks := keystore.NewInMemoryKeystore(hahs_vault, hahs_keyopts)
 	hash_mgr := hash.NewHashManager(hash_ks)
 
-	opts := keyopts.Options{}
-	opts.Set("id", "1", "partyid", "a")
+	opts := keyopts.New().WithKeyID("1").WithPartyID("a")
 	h := hash_mgr.NewHasher("test", opts)
 
 	group := curve.Secp256k1{}
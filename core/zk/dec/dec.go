@@ -2,6 +2,7 @@ package zkdec
 
 import (
 	"crypto/rand"
+	"io"
 
 	"github.com/cronokirby/saferith"
 	"github.com/mr-shifu/mpc-lib/core/hash"
@@ -10,6 +11,7 @@ import (
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
 	"github.com/mr-shifu/mpc-lib/core/paillier"
 	"github.com/mr-shifu/mpc-lib/core/pedersen"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 )
 
 type Public struct {
@@ -22,6 +24,27 @@ type Public struct {
 	// Prover = N₀
 	Prover *paillier.PublicKey
 	Aux    *pedersen.Parameters
+	// Profile is the profile of the range checks, params.Standard if zero.
+	Profile params.Profile
+}
+
+// WriteTo implements io.WriterTo, writing each value of the statement framed as in the hash.
+func (p Public) WriteTo(w io.Writer) (int64, error) {
+	return p.fields().WriteTo(w)
+}
+
+// Domain implements hash.WriterToWithDomain.
+func (Public) Domain() string {
+	return "zkdec Public"
+}
+
+// Size implements hash.SizedWriterToWithDomain, so that the statement is streamed into the hash.
+func (p Public) Size() int {
+	return p.fields().Size()
+}
+
+func (p Public) fields() hash.Fields {
+	return hash.Fields{TheDomain: p.Domain(), Values: []interface{}{p.Aux, p.Prover, p.C, p.X}}
 }
 
 type Private struct {
@@ -73,10 +96,10 @@ func (p *Proof) IsValid(public Public) bool {
 func NewProof(group curve.Curve, hash *hash.Hash, public Public, private Private) *Proof {
 	N := public.Prover.N()
 	NModulus := public.Prover.Modulus()
-	alpha := sample.IntervalLEps(rand.Reader)
+	alpha := sample.IntervalLEps(rand.Reader, public.Profile)
 
 	mu := sample.IntervalLN(rand.Reader)
-	nu := sample.IntervalLEpsN(rand.Reader)
+	nu := sample.IntervalLEpsN(rand.Reader, public.Profile)
 	r := sample.UnitModN(rand.Reader, N)
 
 	gamma := group.NewScalar().SetNat(alpha.Mod(group.Order()))
@@ -150,8 +173,7 @@ func (p *Proof) Verify(hash *hash.Hash, public Public) bool {
 }
 
 func challenge(hash *hash.Hash, group curve.Curve, public Public, commitment *Commitment) (e *saferith.Int, err error) {
-	err = hash.WriteAny(public.Aux, public.Prover,
-		public.C, public.X,
+	err = hash.WriteAny(public,
 		commitment.S, commitment.T, commitment.A, commitment.Gamma)
 	e = sample.IntervalScalar(hash.Digest(), group)
 	return
@@ -0,0 +1,39 @@
+package zkdleq
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/hash"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDLEQ(t *testing.T) {
+	group := curve.Secp256k1{}
+
+	a := sample.Scalar(rand.Reader, group)
+	H := sample.Scalar(rand.Reader, group).ActOnBase()
+	public := Public{
+		H: H,
+		X: a.ActOnBase(),
+		Y: a.Act(H),
+	}
+
+	proof := NewProof(group, hash.New(), public, Private{A: a})
+	assert.True(t, proof.Verify(hash.New(), public))
+
+	out, err := cbor.Marshal(proof)
+	require.NoError(t, err, "failed to marshal proof")
+	proof2 := Empty(group)
+	require.NoError(t, cbor.Unmarshal(out, proof2), "failed to unmarshal proof")
+	assert.True(t, proof2.Verify(hash.New(), public))
+
+	// a different exponent for Y is rejected
+	wrong := public
+	wrong.Y = sample.Scalar(rand.Reader, group).Act(H)
+	assert.False(t, proof2.Verify(hash.New(), wrong))
+}
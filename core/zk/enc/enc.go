@@ -2,13 +2,16 @@ package zkenc
 
 import (
 	"crypto/rand"
+	"io"
 
 	"github.com/cronokirby/saferith"
+	core_hash "github.com/mr-shifu/mpc-lib/core/hash"
 	"github.com/mr-shifu/mpc-lib/core/math/arith"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
 	"github.com/mr-shifu/mpc-lib/core/paillier"
 	"github.com/mr-shifu/mpc-lib/core/pedersen"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
 )
 
@@ -18,7 +21,29 @@ type Public struct {
 
 	Prover *paillier.PublicKey
 	Aux    *pedersen.Parameters
+	// Profile is the profile of the range checks, params.Standard if zero.
+	Profile params.Profile
 }
+
+// WriteTo implements io.WriterTo, writing each value of the statement framed as in the hash.
+func (p Public) WriteTo(w io.Writer) (int64, error) {
+	return p.fields().WriteTo(w)
+}
+
+// Domain implements hash.WriterToWithDomain.
+func (Public) Domain() string {
+	return "zkenc Public"
+}
+
+// Size implements hash.SizedWriterToWithDomain, so that the statement is streamed into the hash.
+func (p Public) Size() int {
+	return p.fields().Size()
+}
+
+func (p Public) fields() core_hash.Fields {
+	return core_hash.Fields{TheDomain: p.Domain(), Values: []interface{}{p.Aux, p.Prover, p.K}}
+}
+
 type Private struct {
 	// K = k ∈ 2ˡ = Dec₀(K)
 	// plaintext of K
@@ -65,17 +90,16 @@ func NewProof(group curve.Curve, hash hash.Hash, public Public, private Private)
 	N := public.Prover.N()
 	NModulus := public.Prover.Modulus()
 
-	alpha := sample.IntervalLEps(rand.Reader)
+	rnd := public.Aux.Randomness(public.Profile)
+	alpha, mu, gamma := rnd.Alpha, rnd.Mu, rnd.Gamma
 	r := sample.UnitModN(rand.Reader, N)
-	mu := sample.IntervalLN(rand.Reader)
-	gamma := sample.IntervalLEpsN(rand.Reader)
 
 	A := public.Prover.EncWithNonce(alpha, r)
 
 	commitment := &Commitment{
-		S: public.Aux.Commit(private.K, mu),
+		S: public.Aux.CommitRandomness(private.K, rnd),
 		A: A,
-		C: public.Aux.Commit(alpha, gamma),
+		C: rnd.D,
 	}
 
 	e, _ := challenge(hash, group, public, commitment)
@@ -105,7 +129,7 @@ func (p *Proof) Verify(group curve.Curve, hash hash.Hash, public Public) bool {
 
 	prover := public.Prover
 
-	if !arith.IsInIntervalLEps(p.Z1) {
+	if !arith.IsInIntervalLEps(p.Z1, public.Profile) {
 		return false
 	}
 
@@ -132,8 +156,18 @@ func (p *Proof) Verify(group curve.Curve, hash hash.Hash, public Public) bool {
 	return true
 }
 
+// VerifySegment checks that K encrypts a plaintext in the range of the profile under the prover's Paillier key,
+// such as the nonce share Kⱼ of sign round 1, with the hash state replayed from the prover's segment of a recorded transcript.
+func (p *Proof) VerifySegment(group curve.Curve, segment hash.Segment, public Public) bool {
+	h, err := segment.Hash()
+	if err != nil {
+		return false
+	}
+	return p.Verify(group, h, public)
+}
+
 func challenge(hash hash.Hash, group curve.Curve, public Public, commitment *Commitment) (e *saferith.Int, err error) {
-	err = hash.WriteAny(public.Aux, public.Prover, public.K,
+	err = hash.WriteAny(public,
 		commitment.S, commitment.A, commitment.C)
 	e = sample.IntervalScalar(hash.Digest(), group)
 	return
@@ -8,6 +8,7 @@ import (
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
 	"github.com/mr-shifu/mpc-lib/core/zk"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/hash"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/keystore"
@@ -22,8 +23,7 @@ func TestEnc(t *testing.T) {
 	hash_ks := keystore.NewInMemoryKeystore(hahs_vault, hahs_keyopts)
 	hash_mgr := hash.NewHashManager(hash_ks)
 
-	opts := keyopts.Options{}
-	opts.Set("id", "1", "partyid", "a")
+	opts := keyopts.New().WithKeyID("1").WithPartyID("a")
 	h := hash_mgr.NewHasher("test", opts)
 
 	group := curve.Secp256k1{}
@@ -56,3 +56,27 @@ func TestEnc(t *testing.T) {
 
 	assert.True(t, proof3.Verify(group, h.Clone(), public))
 }
+
+func TestEncCompactProfile(t *testing.T) {
+	hash_mgr := hash.NewHashManager(keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts()))
+	opts := keyopts.New().WithKeyID("1").WithPartyID("a")
+	h := hash_mgr.NewHasher("test", opts)
+
+	group := curve.Secp256k1{}
+	prover := zk.ProverPaillierPublic
+	k := sample.IntervalL(rand.Reader)
+	K, rho := prover.Enc(k)
+	public := Public{
+		K:      K,
+		Prover: prover,
+		Aux:    zk.Pedersen,
+	}
+	private := Private{K: k, Rho: rho}
+
+	standard := NewProof(group, h.Clone(), public, private)
+
+	public.Profile = params.Compact
+	compact := NewProof(group, h.Clone(), public, private)
+	assert.True(t, compact.Verify(group, h.Clone(), public))
+	assert.False(t, standard.Verify(group, h.Clone(), public), "proof with a larger slack should be rejected")
+}
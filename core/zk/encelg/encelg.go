@@ -2,6 +2,7 @@ package zkencelg
 
 import (
 	"crypto/rand"
+	"io"
 
 	"github.com/cronokirby/saferith"
 	"github.com/mr-shifu/mpc-lib/core/hash"
@@ -10,6 +11,7 @@ import (
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
 	"github.com/mr-shifu/mpc-lib/core/paillier"
 	"github.com/mr-shifu/mpc-lib/core/pedersen"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 )
 
 type Public struct {
@@ -25,7 +27,29 @@ type Public struct {
 
 	Prover *paillier.PublicKey
 	Aux    *pedersen.Parameters
+	// Profile is the profile of the range checks, params.Standard if zero.
+	Profile params.Profile
 }
+
+// WriteTo implements io.WriterTo, writing each value of the statement framed as in the hash.
+func (p Public) WriteTo(w io.Writer) (int64, error) {
+	return p.fields().WriteTo(w)
+}
+
+// Domain implements hash.WriterToWithDomain.
+func (Public) Domain() string {
+	return "zkencelg Public"
+}
+
+// Size implements hash.SizedWriterToWithDomain, so that the statement is streamed into the hash.
+func (p Public) Size() int {
+	return p.fields().Size()
+}
+
+func (p Public) fields() hash.Fields {
+	return hash.Fields{TheDomain: p.Domain(), Values: []interface{}{p.Aux, p.Prover, p.C, p.A, p.B, p.X}}
+}
+
 type Private struct {
 	// X = x = Dec(C)
 	X *saferith.Int
@@ -85,12 +109,12 @@ func NewProof(group curve.Curve, hash *hash.Hash, public Public, private Private
 	N := public.Prover.N()
 	NModulus := public.Prover.Modulus()
 
-	alpha := sample.IntervalLEps(rand.Reader)
+	alpha := sample.IntervalLEps(rand.Reader, public.Profile)
 	alphaScalar := group.NewScalar().SetNat(alpha.Mod(group.Order()))
 	mu := sample.IntervalLN(rand.Reader)
 	r := sample.UnitModN(rand.Reader, N)
 	beta := sample.Scalar(rand.Reader, group)
-	gamma := sample.IntervalLEpsN(rand.Reader)
+	gamma := sample.IntervalLEpsN(rand.Reader, public.Profile)
 
 	commitment := &Commitment{
 		S: public.Aux.Commit(private.X, mu),
@@ -131,7 +155,7 @@ func (p *Proof) Verify(hash *hash.Hash, public Public) bool {
 
 	prover := public.Prover
 
-	if !arith.IsInIntervalLEps(p.Z1) {
+	if !arith.IsInIntervalLEps(p.Z1, public.Profile) {
 		return false
 	}
 
@@ -177,7 +201,7 @@ func (p *Proof) Verify(hash *hash.Hash, public Public) bool {
 }
 
 func challenge(hash *hash.Hash, group curve.Curve, public Public, commitment *Commitment) (e *saferith.Int, err error) {
-	err = hash.WriteAny(public.Aux, public.Prover, public.C, public.A, public.B, public.X,
+	err = hash.WriteAny(public,
 		commitment.S, commitment.D, commitment.Y, commitment.Z, commitment.T)
 	e = sample.IntervalScalar(hash.Digest(), group)
 	return
@@ -2,17 +2,40 @@ package zkfac
 
 import (
 	"crypto/rand"
+	"io"
 
 	"github.com/cronokirby/saferith"
 	"github.com/mr-shifu/mpc-lib/core/hash"
 	"github.com/mr-shifu/mpc-lib/core/math/arith"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
 	"github.com/mr-shifu/mpc-lib/core/pedersen"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 )
 
 type Public struct {
 	N   *saferith.Modulus
 	Aux *pedersen.Parameters
+	// Profile is the profile of the range checks, params.Standard if zero.
+	Profile params.Profile
+}
+
+// WriteTo implements io.WriterTo, writing each value of the statement framed as in the hash.
+func (p Public) WriteTo(w io.Writer) (int64, error) {
+	return p.fields().WriteTo(w)
+}
+
+// Domain implements hash.WriterToWithDomain.
+func (Public) Domain() string {
+	return "zkfac Public"
+}
+
+// Size implements hash.SizedWriterToWithDomain, so that the statement is streamed into the hash.
+func (p Public) Size() int {
+	return p.fields().Size()
+}
+
+func (p Public) fields() hash.Fields {
+	return hash.Fields{TheDomain: p.Domain(), Values: []interface{}{p.N, p.Aux}}
 }
 
 type Private struct {
@@ -41,14 +64,14 @@ func NewProof(private Private, hash *hash.Hash, public Public) *Proof {
 	Nhat := public.Aux.NArith()
 
 	// Figure 28, point 1.
-	alpha := sample.IntervalLEpsRootN(rand.Reader)
-	beta := sample.IntervalLEpsRootN(rand.Reader)
+	alpha := sample.IntervalLEpsRootN(rand.Reader, public.Profile)
+	beta := sample.IntervalLEpsRootN(rand.Reader, public.Profile)
 	mu := sample.IntervalLN(rand.Reader)
 	nu := sample.IntervalLN(rand.Reader)
 	sigma := sample.IntervalLN2(rand.Reader)
-	r := sample.IntervalLEpsN2(rand.Reader)
-	x := sample.IntervalLEpsN(rand.Reader)
-	y := sample.IntervalLEpsN(rand.Reader)
+	r := sample.IntervalLEpsN2(rand.Reader, public.Profile)
+	x := sample.IntervalLEpsN(rand.Reader, public.Profile)
+	y := sample.IntervalLEpsN(rand.Reader, public.Profile)
 
 	pInt := new(saferith.Int).SetNat(private.P)
 	qInt := new(saferith.Int).SetNat(private.Q)
@@ -131,11 +154,11 @@ func (p *Proof) Verify(public Public, hash *hash.Hash) bool {
 	}
 
 	// DEVIATION: for the bounds to work, we add an extra bit, to ensure that we don't have spurious failures.
-	return arith.IsInIntervalLEpsPlus1RootN(p.Z1) && arith.IsInIntervalLEpsPlus1RootN(p.Z2)
+	return arith.IsInIntervalLEpsPlus1RootN(p.Z1, public.Profile) && arith.IsInIntervalLEpsPlus1RootN(p.Z2, public.Profile)
 }
 
 func challenge(hash *hash.Hash, public Public, commitment Commitment) (*saferith.Int, error) {
-	err := hash.WriteAny(public.N, public.Aux, commitment.P, commitment.Q, commitment.A, commitment.B, commitment.T)
+	err := hash.WriteAny(public, commitment.P, commitment.Q, commitment.A, commitment.B, commitment.T)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,112 @@
+package zklogstar
+
+import (
+	"github.com/cronokirby/saferith"
+	"github.com/mr-shifu/mpc-lib/core/math/arith"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
+)
+
+// CompactProof is the challenge-response form of a Proof.
+//
+// Instead of the commitments A, Y and D, it carries the challenge e, from which the verifier
+// recomputes them with the verification equations, before checking that they hash to e.
+// This drops a ciphertext mod N², a point and a Pedersen commitment from the proof.
+type CompactProof struct {
+	group curve.Curve
+	// Challenge = e
+	Challenge *saferith.Int
+	// S = sˣ tᵘ (mod N)
+	S *saferith.Nat
+	// Z1 = α + e x
+	Z1 *saferith.Int
+	// Z2 = r ρᵉ mod N
+	Z2 *saferith.Nat
+	// Z3 = γ + e μ
+	Z3 *saferith.Int
+}
+
+// Compact returns the CompactProof of a proof created with NewProof, or nil for a decoded proof.
+func (p *Proof) Compact() *CompactProof {
+	if p == nil || p.e == nil {
+		return nil
+	}
+	return &CompactProof{
+		group:     p.group,
+		Challenge: p.e,
+		S:         p.S,
+		Z1:        p.Z1,
+		Z2:        p.Z2,
+		Z3:        p.Z3,
+	}
+}
+
+// NewCompactProof is NewProof returning the CompactProof.
+func NewCompactProof(group curve.Curve, hash hash.Hash, public Public, private Private) *CompactProof {
+	return NewProof(group, hash, public, private).Compact()
+}
+
+func (p *CompactProof) Verify(hash hash.Hash, public Public) bool {
+	if p == nil || p.Challenge == nil || p.S == nil || p.Z1 == nil || p.Z2 == nil || p.Z3 == nil {
+		return false
+	}
+	if public.G == nil {
+		public.G = p.group.NewBasePoint()
+	}
+	if !arith.IsInIntervalLEps(p.Z1, public.Profile) {
+		return false
+	}
+	if !arith.IsValidNatModN(public.Prover.N(), p.Z2) || !arith.IsValidNatModN(public.Aux.N(), p.S) {
+		return false
+	}
+
+	proof := &Proof{
+		group:      p.group,
+		Commitment: p.commitment(public),
+		Z1:         p.Z1,
+		Z2:         p.Z2,
+		Z3:         p.Z3,
+	}
+	if !proof.IsValid(public) {
+		return false
+	}
+
+	e, err := challenge(hash, p.group, public, proof.Commitment)
+	if err != nil {
+		return false
+	}
+	return e.Eq(p.Challenge) == 1
+}
+
+// VerifySegment recomputes A, Y and D from the challenge and responses of the compact proof, and checks the challenge
+// against the hash state replayed from the prover's segment of a recorded transcript.
+func (p *CompactProof) VerifySegment(segment hash.Segment, public Public) bool {
+	h, err := segment.Hash()
+	if err != nil {
+		return false
+	}
+	return p.Verify(h, public)
+}
+
+// commitment recomputes the commitment of the proof from its challenge and responses.
+func (p *CompactProof) commitment(public Public) *Commitment {
+	prover := public.Prover
+	minusE := new(saferith.Int).SetInt(p.Challenge).Neg(1)
+
+	// A = Enc(z₁;z₂) ⊕ (-e ⊙ C)
+	A := prover.EncWithNonce(p.Z1, p.Z2).Add(prover, public.C.Clone().Mul(prover, minusE))
+
+	// Y = [z₁]G - [e]X
+	Y := p.group.NewScalar().SetNat(p.Z1.Mod(p.group.Order())).Act(public.G)
+	Y = Y.Sub(p.group.NewScalar().SetNat(p.Challenge.Mod(p.group.Order())).Act(public.X))
+
+	// D = sᶻ¹ tᶻ³ S⁻ᵉ (mod N)
+	D := public.Aux.Commit(p.Z1, p.Z3)
+	D.ModMul(D, public.Aux.NArith().ExpI(p.S, minusE), public.Aux.N())
+
+	return &Commitment{S: p.S, A: A, Y: Y, D: D}
+}
+
+func EmptyCompact(group curve.Curve) *CompactProof {
+	return &CompactProof{group: group}
+}
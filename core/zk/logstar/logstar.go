@@ -2,13 +2,16 @@ package zklogstar
 
 import (
 	"crypto/rand"
+	"io"
 
 	"github.com/cronokirby/saferith"
+	core_hash "github.com/mr-shifu/mpc-lib/core/hash"
 	"github.com/mr-shifu/mpc-lib/core/math/arith"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
 	"github.com/mr-shifu/mpc-lib/core/paillier"
 	"github.com/mr-shifu/mpc-lib/core/pedersen"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
 )
 
@@ -26,6 +29,27 @@ type Public struct {
 
 	Prover *paillier.PublicKey
 	Aux    *pedersen.Parameters
+	// Profile is the profile of the range checks, params.Standard if zero.
+	Profile params.Profile
+}
+
+// WriteTo implements io.WriterTo, writing each value of the statement framed as in the hash.
+func (p Public) WriteTo(w io.Writer) (int64, error) {
+	return p.fields().WriteTo(w)
+}
+
+// Domain implements hash.WriterToWithDomain.
+func (Public) Domain() string {
+	return "zklogstar Public"
+}
+
+// Size implements hash.SizedWriterToWithDomain, so that the statement is streamed into the hash.
+func (p Public) Size() int {
+	return p.fields().Size()
+}
+
+func (p Public) fields() core_hash.Fields {
+	return core_hash.Fields{TheDomain: p.Domain(), Values: []interface{}{p.Aux, p.Prover, p.C, p.X, p.G}}
 }
 
 type Private struct {
@@ -56,6 +80,9 @@ type Proof struct {
 	Z2 *saferith.Nat
 	// Z3 = γ + e μ
 	Z3 *saferith.Int
+
+	// e is the challenge of a proof created with NewProof, kept to compact it.
+	e *saferith.Int
 }
 
 func (p *Proof) IsValid(public Public) bool {
@@ -82,16 +109,15 @@ func NewProof(group curve.Curve, hash hash.Hash, public Public, private Private)
 		public.G = group.NewBasePoint()
 	}
 
-	alpha := sample.IntervalLEps(rand.Reader)
+	rnd := public.Aux.Randomness(public.Profile)
+	alpha, mu, gamma := rnd.Alpha, rnd.Mu, rnd.Gamma
 	r := sample.UnitModN(rand.Reader, N)
-	mu := sample.IntervalLN(rand.Reader)
-	gamma := sample.IntervalLEpsN(rand.Reader)
 
 	commitment := &Commitment{
 		A: public.Prover.EncWithNonce(alpha, r),
 		Y: group.NewScalar().SetNat(alpha.Mod(group.Order())).Act(public.G),
-		S: public.Aux.Commit(private.X, mu),
-		D: public.Aux.Commit(alpha, gamma),
+		S: public.Aux.CommitRandomness(private.X, rnd),
+		D: rnd.D,
 	}
 
 	e, _ := challenge(hash, group, public, commitment)
@@ -110,6 +136,7 @@ func NewProof(group curve.Curve, hash hash.Hash, public Public, private Private)
 	return &Proof{
 		group:      group,
 		Commitment: commitment,
+		e:          e,
 		Z1:         z1,
 		Z2:         z2,
 		Z3:         z3,
@@ -125,7 +152,7 @@ func (p *Proof) Verify(hash hash.Hash, public Public) bool {
 		public.G = p.group.NewBasePoint()
 	}
 
-	if !arith.IsInIntervalLEps(p.Z1) {
+	if !arith.IsInIntervalLEps(p.Z1, public.Profile) {
 		return false
 	}
 
@@ -168,8 +195,18 @@ func (p *Proof) Verify(hash hash.Hash, public Public) bool {
 	return true
 }
 
+// VerifySegment checks that C encrypts the discrete log of X in base G, within the range of the profile,
+// with the hash state replayed from the prover's segment of a recorded transcript.
+func (p *Proof) VerifySegment(segment hash.Segment, public Public) bool {
+	h, err := segment.Hash()
+	if err != nil {
+		return false
+	}
+	return p.Verify(h, public)
+}
+
 func challenge(hash hash.Hash, group curve.Curve, public Public, commitment *Commitment) (e *saferith.Int, err error) {
-	err = hash.WriteAny(public.Aux, public.Prover, public.C, public.X, public.G,
+	err = hash.WriteAny(public,
 		commitment.S, commitment.A, commitment.Y, commitment.D)
 	e = sample.IntervalScalar(hash.Digest(), group)
 	return
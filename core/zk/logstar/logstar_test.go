@@ -22,8 +22,7 @@ func TestLogStar(t *testing.T) {
 	hash_ks := keystore.NewInMemoryKeystore(hahs_vault, hahs_keyopts)
 	hash_mgr := hash.NewHashManager(hash_ks)
 
-	opts := keyopts.Options{}
-	opts.Set("id", "1", "partyid", "a")
+	opts := keyopts.New().WithKeyID("1").WithPartyID("a")
 	h := hash_mgr.NewHasher("test", opts)
 
 	group := curve.Secp256k1{}
@@ -60,4 +59,18 @@ func TestLogStar(t *testing.T) {
 	require.NoError(t, cbor.Unmarshal(out2, proof3), "failed to unmarshal 2nd proof")
 
 	assert.True(t, proof3.Verify(h.Clone(), public))
+
+	compact := proof.Compact()
+	assert.True(t, compact.Verify(h.Clone(), public))
+	assert.Nil(t, proof3.Compact(), "decoded proofs cannot be compacted")
+
+	compactOut, err := cbor.Marshal(compact)
+	require.NoError(t, err, "failed to marshal compact proof")
+	assert.Less(t, len(compactOut), len(out), "compact proof should be smaller")
+	compact2 := EmptyCompact(group)
+	require.NoError(t, cbor.Unmarshal(compactOut, compact2), "failed to unmarshal compact proof")
+	assert.True(t, compact2.Verify(h.Clone(), public))
+
+	compact2.Challenge = sample.IntervalScalar(rand.Reader, group)
+	assert.False(t, compact2.Verify(h.Clone(), public), "compact proof with another challenge should fail")
 }
@@ -2,6 +2,7 @@ package zkmul
 
 import (
 	"crypto/rand"
+	"io"
 
 	"github.com/cronokirby/saferith"
 	"github.com/mr-shifu/mpc-lib/core/hash"
@@ -9,6 +10,7 @@ import (
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
 	"github.com/mr-shifu/mpc-lib/core/paillier"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 )
 
 type Public struct {
@@ -23,6 +25,27 @@ type Public struct {
 
 	// Prover = N
 	Prover *paillier.PublicKey
+	// Profile is the profile of the range checks, params.Standard if zero.
+	Profile params.Profile
+}
+
+// WriteTo implements io.WriterTo, writing each value of the statement framed as in the hash.
+func (p Public) WriteTo(w io.Writer) (int64, error) {
+	return p.fields().WriteTo(w)
+}
+
+// Domain implements hash.WriterToWithDomain.
+func (Public) Domain() string {
+	return "zkmul Public"
+}
+
+// Size implements hash.SizedWriterToWithDomain, so that the statement is streamed into the hash.
+func (p Public) Size() int {
+	return p.fields().Size()
+}
+
+func (p Public) fields() hash.Fields {
+	return hash.Fields{TheDomain: p.Domain(), Values: []interface{}{p.Prover, p.X, p.Y, p.C}}
 }
 
 type Private struct {
@@ -72,7 +95,7 @@ func NewProof(group curve.Curve, hash *hash.Hash, public Public, private Private
 
 	prover := public.Prover
 
-	alpha := sample.IntervalLEps(rand.Reader)
+	alpha := sample.IntervalLEps(rand.Reader, public.Profile)
 	r := sample.UnitModN(rand.Reader, N)
 	s := sample.UnitModN(rand.Reader, N)
 
@@ -143,8 +166,7 @@ func (p *Proof) Verify(group curve.Curve, hash *hash.Hash, public Public) bool {
 }
 
 func challenge(hash *hash.Hash, group curve.Curve, public Public, commitment *Commitment) (e *saferith.Int, err error) {
-	err = hash.WriteAny(public.Prover,
-		public.X, public.Y, public.C,
+	err = hash.WriteAny(public,
 		commitment.A, commitment.B)
 	e = sample.IntervalScalar(hash.Digest(), group)
 	return
@@ -2,6 +2,7 @@ package zkmulstar
 
 import (
 	"crypto/rand"
+	"io"
 
 	"github.com/cronokirby/saferith"
 	"github.com/mr-shifu/mpc-lib/core/hash"
@@ -10,6 +11,7 @@ import (
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
 	"github.com/mr-shifu/mpc-lib/core/paillier"
 	"github.com/mr-shifu/mpc-lib/core/pedersen"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 )
 
 type Public struct {
@@ -25,6 +27,27 @@ type Public struct {
 	// Verifier = N₀
 	Verifier *paillier.PublicKey
 	Aux      *pedersen.Parameters
+	// Profile is the profile of the range checks, params.Standard if zero.
+	Profile params.Profile
+}
+
+// WriteTo implements io.WriterTo, writing each value of the statement framed as in the hash.
+func (p Public) WriteTo(w io.Writer) (int64, error) {
+	return p.fields().WriteTo(w)
+}
+
+// Domain implements hash.WriterToWithDomain.
+func (Public) Domain() string {
+	return "zkmulstar Public"
+}
+
+// Size implements hash.SizedWriterToWithDomain, so that the statement is streamed into the hash.
+func (p Public) Size() int {
+	return p.fields().Size()
+}
+
+func (p Public) fields() hash.Fields {
+	return hash.Fields{TheDomain: p.Domain(), Values: []interface{}{p.Aux, p.Verifier, p.C, p.D, p.X}}
 }
 
 type Private struct {
@@ -79,12 +102,12 @@ func NewProof(group curve.Curve, hash *hash.Hash, public Public, private Private
 
 	verifier := public.Verifier
 
-	alpha := sample.IntervalLEps(rand.Reader)
+	alpha := sample.IntervalLEps(rand.Reader, public.Profile)
 
 	r := sample.UnitModN(rand.Reader, N0)
 
-	gamma := sample.IntervalLEpsN(rand.Reader)
-	m := sample.IntervalLEpsN(rand.Reader)
+	gamma := sample.IntervalLEpsN(rand.Reader, public.Profile)
+	m := sample.IntervalLEpsN(rand.Reader, public.Profile)
 
 	A := public.C.Clone().Mul(verifier, alpha)
 	A.Randomize(verifier, r)
@@ -125,7 +148,7 @@ func (p *Proof) Verify(group curve.Curve, hash *hash.Hash, public Public) bool {
 
 	verifier := public.Verifier
 
-	if !arith.IsInIntervalLEps(p.Z1) {
+	if !arith.IsInIntervalLEps(p.Z1, public.Profile) {
 		return false
 	}
 
@@ -167,8 +190,7 @@ func (p *Proof) Verify(group curve.Curve, hash *hash.Hash, public Public) bool {
 }
 
 func challenge(group curve.Curve, hash *hash.Hash, public Public, commitment *Commitment) (e *saferith.Int, err error) {
-	err = hash.WriteAny(public.Aux, public.Verifier,
-		public.C, public.D, public.X,
+	err = hash.WriteAny(public,
 		commitment.A, commitment.Bx,
 		commitment.E, commitment.S)
 	e = sample.IntervalScalar(hash.Digest(), group)
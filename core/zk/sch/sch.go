@@ -114,6 +114,16 @@ func (p *Proof) Verify(hash hash.Hash, public, gen curve.Point) bool {
 	return p.Z.Verify(hash, public, &p.C, gen)
 }
 
+// VerifySegment checks the proof of knowledge of the discrete log of public in base gen, with the hash state
+// replayed from the prover's segment of a recorded keygen transcript, so that the proofs of a past keygen can be audited.
+func (p *Proof) VerifySegment(segment hash.Segment, public, gen curve.Point) bool {
+	h, err := segment.Hash()
+	if err != nil {
+		return false
+	}
+	return p.Verify(h, public, gen)
+}
+
 // WriteTo implements io.WriterTo.
 func (c *Commitment) WriteTo(w io.Writer) (int64, error) {
 	data, err := c.C.MarshalBinary()
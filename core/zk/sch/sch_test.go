@@ -7,6 +7,7 @@ import (
 	"github.com/fxamacker/cbor/v2"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/hash"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/keystore"
@@ -21,8 +22,7 @@ func TestSchPass(t *testing.T) {
 	hash_ks := keystore.NewInMemoryKeystore(hahs_vault, hahs_keyopts)
 	hash_mgr := hash.NewHashManager(hash_ks)
 
-	opts := keyopts.Options{}
-	opts.Set("id", "1", "partyid", "a")
+	opts := keyopts.New().WithKeyID("1").WithPartyID("a")
 	h := hash_mgr.NewHasher("test", opts)
 
 	group := curve.Secp256k1{}
@@ -53,8 +53,7 @@ func TestSchFail(t *testing.T) {
 	hash_ks := keystore.NewInMemoryKeystore(hahs_vault, hahs_keyopts)
 	hash_mgr := hash.NewHashManager(hash_ks)
 	
-	opts := keyopts.Options{}
-	opts.Set("id", "1", "partyid", "a")
+	opts := keyopts.New().WithKeyID("1").WithPartyID("a")
 	h := hash_mgr.NewHasher("test", opts)
 
 	group := curve.Secp256k1{}
@@ -65,3 +64,37 @@ func TestSchFail(t *testing.T) {
 	proof := a.Prove(h, X, x, nil)
 	assert.False(t, proof.Verify(h, X, a.Commitment(), nil), "proof should not accept identity point")
 }
+
+func TestSchSegment(t *testing.T) {
+	hash_keyopts := keyopts.NewInMemoryKeyOpts()
+	hash_vault := vault.NewInMemoryVault()
+	hash_ks := keystore.NewInMemoryKeystore(hash_vault, hash_keyopts)
+	hash_mgr := hash.NewHashManager(hash_ks)
+
+	opts := keyopts.New().WithKeyID("1").WithPartyID("a")
+	h := hash_mgr.NewHasher("test", opts)
+	require.NoError(t, h.WriteAny([]byte("round 1")))
+
+	group := curve.Secp256k1{}
+	x, X := sample.ScalarPointPair(rand.Reader, group)
+
+	// the prover records the offset of the session hash along with the proof
+	offset := h.(*hash.Hash).Offset()
+	proofHash := h.Clone()
+	require.NoError(t, proofHash.WriteAny(party.ID("a")))
+	proof := NewProof(proofHash, X, x, nil)
+
+	// the session continues after the proof
+	require.NoError(t, h.WriteAny([]byte("round 2")))
+
+	// a verifier which did not take part only gets the persisted transcript
+	data, err := hash_ks.KeyAccessor("test", opts).Get()
+	require.NoError(t, err)
+	transcript, err := hash.UnmarshalTranscript(data)
+	require.NoError(t, err)
+
+	assert.True(t, proof.VerifySegment(transcript.Segment(offset, "a"), X, nil))
+	assert.False(t, proof.VerifySegment(transcript.Segment(offset, "b"), X, nil))
+	assert.False(t, proof.VerifySegment(transcript.Segment(offset+1, "a"), X, nil))
+	assert.False(t, proof.VerifySegment(transcript.Segment(len(transcript)+1, "a"), X, nil))
+}
@@ -0,0 +1,116 @@
+package cluster
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+)
+
+// Sender sends the messages of a session to the other parties, such as Transport.
+type Sender interface {
+	Send(ctx context.Context, name string, parties party.IDSlice, msg *protocol.Message) error
+}
+
+// Faults are the faults injected on the messages sent to a party.
+type Faults struct {
+	// Latency is the delay of every message, and Jitter the maximum random delay added to it,
+	// so that messages sent close together may arrive out of order.
+	Latency time.Duration
+	Jitter  time.Duration
+	// ReorderRate is the probability that a message is held back for ReorderDelay more,
+	// so that the messages sent after it arrive first.
+	ReorderRate  float64
+	ReorderDelay time.Duration
+	// DropRate is the probability that a message is lost.
+	DropRate float64
+}
+
+// Chaos injects faults between the parties, to check the timeouts and retries of a deployment
+// before a real network does. It must not be used in production.
+type Chaos struct {
+	// Faults apply to the messages sent to the parties not in Peers.
+	Faults
+	// Peers overrides the faults of the messages sent to some parties, such as a party in another region.
+	Peers map[party.ID]Faults
+	// Seed seeds the random faults, so that a run can be reproduced. 0 selects a random seed.
+	Seed int64
+}
+
+// ChaosSender is the Sender middleware injecting the faults of a Chaos.
+//
+// Send returns once the messages are scheduled, as the delays are those of the network and not of the sender.
+// The delayed messages are sent by next in the background, until the context of Send is done,
+// and the errors of next are logged since no one is waiting for them.
+type ChaosSender struct {
+	next  Sender
+	self  party.ID
+	chaos Chaos
+
+	mtx sync.Mutex
+	rng *rand.Rand
+}
+
+// NewChaosSender returns a Sender of self injecting the faults of chaos before sending with next.
+func NewChaosSender(self party.ID, next Sender, chaos Chaos) *ChaosSender {
+	seed := chaos.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &ChaosSender{next: next, self: self, chaos: chaos, rng: rand.New(rand.NewSource(seed))}
+}
+
+// Send sends msg separately to each of its recipients, with the faults of the link to the recipient.
+func (c *ChaosSender) Send(ctx context.Context, name string, parties party.IDSlice, msg *protocol.Message) error {
+	for _, id := range parties {
+		if id == c.self || !msg.IsFor(id) {
+			continue
+		}
+		delay, drop := c.faults(id)
+		if drop {
+			continue
+		}
+		go c.deliver(ctx, name, id, msg, delay)
+	}
+	return nil
+}
+
+// faults draws the delay of a message to id, and whether it is lost.
+func (c *ChaosSender) faults(id party.ID) (time.Duration, bool) {
+	f, ok := c.chaos.Peers[id]
+	if !ok {
+		f = c.chaos.Faults
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.rng.Float64() < f.DropRate {
+		return 0, true
+	}
+	delay := f.Latency
+	if f.Jitter > 0 {
+		delay += time.Duration(c.rng.Int63n(int64(f.Jitter) + 1))
+	}
+	if c.rng.Float64() < f.ReorderRate {
+		delay += f.ReorderDelay
+	}
+	return delay, false
+}
+
+func (c *ChaosSender) deliver(ctx context.Context, name string, id party.ID, msg *protocol.Message, delay time.Duration) {
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+	if err := c.next.Send(ctx, name, party.IDSlice{c.self, id}, msg); err != nil && ctx.Err() == nil {
+		log.Printf("cluster: chaos: send %s to %s: %v", msg, id, err)
+	}
+}
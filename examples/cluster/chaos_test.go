@@ -0,0 +1,64 @@
+package cluster
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a Sender recording the messages it sends to each party.
+type recorder struct {
+	mtx  sync.Mutex
+	sent map[party.ID][]*protocol.Message
+}
+
+func (r *recorder) Send(_ context.Context, _ string, parties party.IDSlice, msg *protocol.Message) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	for _, id := range parties {
+		if id != "a" && msg.IsFor(id) {
+			r.sent[id] = append(r.sent[id], msg)
+		}
+	}
+	return nil
+}
+
+func (r *recorder) received(id party.ID) []*protocol.Message {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return append([]*protocol.Message(nil), r.sent[id]...)
+}
+
+func TestChaosSender(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ids := party.IDSlice{"a", "b", "c", "d"}
+	next := &recorder{sent: map[party.ID][]*protocol.Message{}}
+	chaos := NewChaosSender("a", next, Chaos{
+		Faults: Faults{Latency: 100 * time.Millisecond},
+		Peers: map[party.ID]Faults{
+			"c": {DropRate: 1},
+			"d": {ReorderRate: 1, ReorderDelay: 50 * time.Millisecond},
+		},
+		Seed: 1,
+	})
+
+	first := &protocol.Message{From: "a", Protocol: "test", RoundNumber: 2, Data: []byte("first"), Broadcast: true}
+	require.NoError(t, chaos.Send(ctx, "s", ids, first))
+	assert.Empty(t, next.received("b"), "messages must be delayed")
+	second := &protocol.Message{From: "a", To: "d", Protocol: "test", RoundNumber: 2, Data: []byte("second")}
+	require.NoError(t, NewChaosSender("a", next, Chaos{}).Send(ctx, "s", ids, second))
+
+	assert.Eventually(t, func() bool { return len(next.received("b")) == 1 && len(next.received("d")) == 2 },
+		time.Second, 10*time.Millisecond)
+	assert.Empty(t, next.received("c"))
+	d := next.received("d")
+	assert.Equal(t, []byte("second"), d[0].Data, "the first message must be overtaken")
+	assert.Equal(t, []byte("first"), d[1].Data)
+}
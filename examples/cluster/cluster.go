@@ -0,0 +1,234 @@
+// Package cluster is the reference wiring of a signer running the CMP protocols in its own process,
+// and exchanging protocol messages with the other signers over HTTP.
+//
+// A Party is embedded by creating it with NewParty, serving it with net/http, and calling Keygen
+// and Sign with the same session names on every party. Messages received for a session which was
+// not started yet are kept until it is, so the parties need not start their sessions at the same time.
+//
+// This tree ships neither a gRPC transport nor a persistent keystore: the messages are sent with
+// plain HTTP POST requests, and Stores selects where keys are kept, in memory by default.
+// Signing runs the full CMP sign protocol, since presignatures are not exposed by cmp.MPC yet.
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/mr-shifu/mpc-lib/core/ecdsa"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	comm_keyopts "github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
+	comm_keystore "github.com/mr-shifu/mpc-lib/pkg/common/keystore"
+	comm_vault "github.com/mr-shifu/mpc-lib/pkg/common/vault"
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/keystore"
+	comm_mpc_config "github.com/mr-shifu/mpc-lib/pkg/mpc/common/config"
+	comm_message "github.com/mr-shifu/mpc-lib/pkg/mpc/common/message"
+	comm_state "github.com/mr-shifu/mpc-lib/pkg/mpc/common/state"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/config"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/message"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/state"
+	"github.com/mr-shifu/mpc-lib/pkg/vault"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp"
+)
+
+var (
+	ErrSessionExists  = errors.New("cluster: session already exists")
+	ErrUnexpectedType = errors.New("cluster: unexpected result type")
+	ErrTooManyPending = errors.New("cluster: too many messages for sessions not started yet")
+)
+
+// Stores are the stores backing the key material and sessions of a Party.
+// Scratch holds the secrets of the sign sessions, see cmp.NewMPC.
+type Stores struct {
+	Keystores   comm_keystore.KeystoreFactory
+	KeyOpts     comm_keyopts.KeyOptsFactory
+	Vaults      comm_vault.VaultFactory
+	Scratch     comm_vault.VaultFactory
+	KeyConfigs  comm_mpc_config.ConfigStore
+	SignConfigs comm_mpc_config.ConfigStore
+	KeyStates   comm_state.MPCStateStore
+	SignStates  comm_state.MPCStateStore
+	Messages    comm_message.MessageStore
+	Broadcasts  comm_message.MessageStore
+}
+
+// InMemoryStores returns Stores which do not survive restarts.
+func InMemoryStores() Stores {
+	return Stores{
+		Keystores:   &keystore.InmemoryKeystoreFactory{},
+		KeyOpts:     &keyopts.InMemoryKeyOptsFactory{},
+		Vaults:      &vault.InmemoryVaultFactory{},
+		Scratch:     &vault.InmemoryVaultFactory{},
+		KeyConfigs:  config.NewInMemoryConfigStore(),
+		SignConfigs: config.NewInMemoryConfigStore(),
+		KeyStates:   state.NewInMemoryStateStore(),
+		SignStates:  state.NewInMemoryStateStore(),
+		Messages:    message.NewInMemoryMessageStore(),
+		Broadcasts:  message.NewInMemoryMessageStore(),
+	}
+}
+
+// Party is a signer of the cluster.
+type Party struct {
+	self      party.ID
+	mpc       *cmp.MPC
+	pl        *pool.Pool
+	transport Sender
+
+	// sessions maps the name of a running session to its handler,
+	// and pending the name of a session not started yet to the messages received for it.
+	sessions map[string]*protocol.MultiHandler
+	pending  map[string][]*protocol.Message
+	mtx      sync.Mutex
+}
+
+// NewParty returns the party self, reaching the other parties at the base URLs of peers.
+func NewParty(self party.ID, peers map[party.ID]string, stores Stores, pl *pool.Pool) *Party {
+	return &Party{
+		self: self,
+		mpc: cmp.NewMPC(stores.Keystores, stores.KeyOpts, stores.Vaults, stores.Scratch,
+			stores.KeyConfigs, stores.SignConfigs, stores.KeyStates, stores.SignStates,
+			stores.Messages, stores.Broadcasts, pl),
+		pl:        pl,
+		transport: NewTransport(self, peers, nil),
+		sessions:  map[string]*protocol.MultiHandler{},
+		pending:   map[string][]*protocol.Message{},
+	}
+}
+
+// WithChaos injects the faults of chaos in the messages sent by the party, for staging environments.
+// It must be called before the party starts a session.
+func (p *Party) WithChaos(chaos Chaos) *Party {
+	p.transport = NewChaosSender(p.self, p.transport, chaos)
+	return p
+}
+
+// ID returns the ID of the party.
+func (p *Party) ID() party.ID { return p.self }
+
+// Keygen runs the keygen session named keyID with the other parties, and returns the party's share.
+// Any threshold+1 of the parties can then sign with the key.
+func (p *Party) Keygen(ctx context.Context, keyID string, parties party.IDSlice, threshold int) (*cmp.Config, error) {
+	cfg := config.NewKeyConfig(keyID, curve.Secp256k1{}, threshold, p.self, parties)
+	result, err := p.run(ctx, "keygen/"+keyID, parties, p.mpc.Keygen(cfg, p.pl))
+	if err != nil {
+		return nil, err
+	}
+	c, ok := result.(*cmp.Config)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnexpectedType, result)
+	}
+	return c, nil
+}
+
+// Sign runs the sign session named signID of the hash with the key keyID among signers,
+// which must include this party.
+func (p *Party) Sign(ctx context.Context, signID, keyID string, signers party.IDSlice, threshold int, hash []byte) (*ecdsa.Signature, error) {
+	cfg := config.NewSignConfig(signID, keyID, curve.Secp256k1{}, threshold, p.self, signers, hash)
+	result, err := p.run(ctx, "sign/"+signID, signers, p.mpc.Sign(cfg, p.pl))
+	if err != nil {
+		return nil, err
+	}
+	sig, ok := result.(*ecdsa.Signature)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnexpectedType, result)
+	}
+	return sig, nil
+}
+
+// run starts the session `name`, hands it the messages received before it started,
+// and sends its messages to the other parties until it is over.
+func (p *Party) run(ctx context.Context, name string, parties party.IDSlice, start protocol.StartFunc) (interface{}, error) {
+	h, err := protocol.NewMultiHandler(start, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mtx.Lock()
+	if _, ok := p.sessions[name]; ok {
+		p.mtx.Unlock()
+		h.Stop()
+		return nil, fmt.Errorf("%w: %s", ErrSessionExists, name)
+	}
+	p.sessions[name] = h
+	pending := p.pending[name]
+	delete(p.pending, name)
+	p.mtx.Unlock()
+	defer func() {
+		p.mtx.Lock()
+		delete(p.sessions, name)
+		p.mtx.Unlock()
+	}()
+
+	for _, msg := range pending {
+		h.Accept(msg)
+	}
+
+	for {
+		select {
+		case msg, ok := <-h.Listen():
+			if !ok {
+				return h.Result()
+			}
+			if err := p.transport.Send(ctx, name, parties, msg); err != nil {
+				h.Stop()
+				return nil, err
+			}
+		case <-ctx.Done():
+			h.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// ServeHTTP receives the messages sent by the Transport of the other parties.
+func (p *Party) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != MessagePath {
+		http.NotFound(w, r)
+		return
+	}
+	name := r.URL.Query().Get("session")
+	data, err := io.ReadAll(io.LimitReader(r.Body, MaxMessageSize))
+	if err != nil || name == "" {
+		http.Error(w, "invalid message", http.StatusBadRequest)
+		return
+	}
+	msg := &protocol.Message{}
+	if err := msg.UnmarshalBinary(data); err != nil {
+		http.Error(w, "invalid message", http.StatusBadRequest)
+		return
+	}
+	if err := p.deliver(name, msg); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deliver hands msg to the session name, or keeps it until the session is started.
+// Since any client can name a session, at most MaxPendingMessages are kept for at most MaxPendingSessions sessions,
+// and ErrTooManyPending is returned for the others, which the sender retries.
+func (p *Party) deliver(name string, msg *protocol.Message) error {
+	p.mtx.Lock()
+	h, ok := p.sessions[name]
+	if !ok {
+		pending, known := p.pending[name]
+		if (!known && len(p.pending) >= MaxPendingSessions) || len(pending) >= MaxPendingMessages {
+			p.mtx.Unlock()
+			return ErrTooManyPending
+		}
+		p.pending[name] = append(pending, msg)
+	}
+	p.mtx.Unlock()
+	if ok {
+		h.Accept(msg)
+	}
+	return nil
+}
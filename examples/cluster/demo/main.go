@@ -0,0 +1,149 @@
+// Command demo runs a 3-of-5 CMP keygen and sign, each party in its own process.
+//
+// Run without flags, it starts the five parties as child processes of itself, each listening on
+// its own local port, and waits for them. Every party takes part in the keygen, after which the
+// first three parties sign a message and verify the signature.
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/examples/cluster"
+)
+
+var (
+	partyFlag = flag.String("party", "", "ID of the party to run; all parties are started as child processes when empty")
+	basePort  = flag.Int("port", 7600, "port of the first party; party i listens on port+i")
+	parties   = flag.Int("n", 5, "number of parties")
+	threshold = flag.Int("t", 2, "threshold; t+1 parties are needed to sign")
+	timeout   = flag.Duration("timeout", 10*time.Minute, "timeout of the demo")
+
+	latency = flag.Duration("latency", 0, "injected latency of the messages between parties")
+	jitter  = flag.Duration("jitter", 0, "maximum random delay added to the injected latency")
+	reorder = flag.Float64("reorder", 0, "probability that a message is held back so that later ones overtake it")
+	drop    = flag.Float64("drop", 0, "probability that a message is lost; the demo then stalls until its timeout")
+)
+
+func main() {
+	flag.Parse()
+	if *partyFlag == "" {
+		if err := spawn(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if err := run(party.ID(*partyFlag)); err != nil {
+		log.Fatalf("party %s: %v", *partyFlag, err)
+	}
+}
+
+func ids() party.IDSlice {
+	ids := make([]party.ID, *parties)
+	for i := range ids {
+		ids[i] = party.ID("p" + strconv.Itoa(i))
+	}
+	return party.NewIDSlice(ids)
+}
+
+func addr(i int) string {
+	return net.JoinHostPort("127.0.0.1", strconv.Itoa(*basePort+i))
+}
+
+// spawn starts every party as a child process and waits for all of them.
+func spawn() error {
+	var wg sync.WaitGroup
+	errs := make(chan error, *parties)
+	for _, id := range ids() {
+		cmd := exec.Command(os.Args[0], append([]string{"-party", string(id)}, os.Args[1:]...)...)
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		if err := cmd.Start(); err != nil {
+			return err
+		}
+		wg.Add(1)
+		go func(id party.ID) {
+			defer wg.Done()
+			if err := cmd.Wait(); err != nil {
+				errs <- fmt.Errorf("party %s: %w", id, err)
+			}
+		}(id)
+	}
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+// run is the body of the child process of party self.
+func run(self party.ID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	all := ids()
+	peers := make(map[party.ID]string, len(all))
+	listen := ""
+	for i, id := range all {
+		peers[id] = "http://" + addr(i)
+		if id == self {
+			listen = addr(i)
+		}
+	}
+	if listen == "" {
+		return fmt.Errorf("unknown party")
+	}
+
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	p := cluster.NewParty(self, peers, cluster.InMemoryStores(), pl)
+	if *latency > 0 || *jitter > 0 || *reorder > 0 || *drop > 0 {
+		p.WithChaos(cluster.Chaos{Faults: cluster.Faults{
+			Latency:      *latency,
+			Jitter:       *jitter,
+			ReorderRate:  *reorder,
+			ReorderDelay: *latency + *jitter,
+			DropRate:     *drop,
+		}})
+	}
+
+	srv := &http.Server{Addr: listen, Handler: p}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("party %s: %v", self, err)
+			cancel()
+		}
+	}()
+	defer srv.Close()
+
+	const keyID = "demo-key"
+	cfg, err := p.Keygen(ctx, keyID, all, *threshold)
+	if err != nil {
+		return fmt.Errorf("keygen: %w", err)
+	}
+	log.Printf("party %s: keygen done", self)
+
+	signers := all[:*threshold+1]
+	if !signers.Contains(self) {
+		return nil
+	}
+	hash := sha256.Sum256([]byte("hello from the demo cluster"))
+	sig, err := p.Sign(ctx, "demo-sign", keyID, signers, *threshold, hash[:])
+	if err != nil {
+		return fmt.Errorf("sign: %w", err)
+	}
+	if !sig.Verify(cfg.PublicPoint(), hash[:]) {
+		return fmt.Errorf("sign: invalid signature")
+	}
+	log.Printf("party %s: signature verified", self)
+	return nil
+}
@@ -0,0 +1,104 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+)
+
+const (
+	// MessagePath is the path the parties receive protocol messages on.
+	MessagePath = "/message"
+	// MaxMessageSize is the size of the largest message a party accepts.
+	MaxMessageSize = 8 << 20
+	// MaxPendingSessions is the number of sessions not started yet for which a party keeps the messages it receives.
+	MaxPendingSessions = 64
+	// MaxPendingMessages is the number of messages a party keeps for a session not started yet.
+	MaxPendingMessages = 256
+
+	// retryDelay is the first delay before sending a message again, doubled up to maxRetryDelay,
+	// so that parties can be started in any order, and sessions in any order once a party keeps no more messages.
+	retryDelay    = 50 * time.Millisecond
+	maxRetryDelay = 2 * time.Second
+)
+
+var ErrUnknownPeer = errors.New("cluster: unknown peer")
+
+// Transport sends the messages of a party to the other parties with HTTP POST requests.
+type Transport struct {
+	self   party.ID
+	peers  map[party.ID]string
+	client *http.Client
+}
+
+// NewTransport returns the Transport of self, reaching the other parties at the base URLs of peers.
+// If client is nil, http.DefaultClient is used.
+func NewTransport(self party.ID, peers map[party.ID]string, client *http.Client) *Transport {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Transport{self: self, peers: peers, client: client}
+}
+
+// Send sends msg of the session name to its recipient, or to all parties but self for a broadcast.
+// A peer which cannot be reached is retried until ctx is done.
+func (t *Transport) Send(ctx context.Context, name string, parties party.IDSlice, msg *protocol.Message) error {
+	data, err := msg.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	for _, id := range parties {
+		if id == t.self || !msg.IsFor(id) {
+			continue
+		}
+		if err := t.post(ctx, name, id, data); err != nil {
+			return fmt.Errorf("cluster: send to %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func (t *Transport) post(ctx context.Context, name string, id party.ID, data []byte) error {
+	base, ok := t.peers[id]
+	if !ok {
+		return ErrUnknownPeer
+	}
+	target := base + MessagePath + "?session=" + url.QueryEscape(name)
+
+	delay := retryDelay
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/octet-stream")
+		resp, err := t.client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			switch resp.StatusCode {
+			case http.StatusNoContent:
+				return nil
+			case http.StatusServiceUnavailable:
+				// the peer keeps no more messages for sessions it did not start yet
+			default:
+				return fmt.Errorf("unexpected status %s", resp.Status)
+			}
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if delay *= 2; delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
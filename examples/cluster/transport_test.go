@@ -0,0 +1,79 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransportBuffersUntilSessionStarts(t *testing.T) {
+	pl := pool.NewPool(1)
+	defer pl.TearDown()
+
+	ids := party.IDSlice{"a", "b", "c"}
+	peers := map[party.ID]string{}
+	receivers := map[party.ID]*Party{}
+	for _, id := range ids {
+		p := NewParty(id, peers, InMemoryStores(), pl)
+		srv := httptest.NewServer(p)
+		defer srv.Close()
+		peers[id] = srv.URL
+		receivers[id] = p
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	bcast := &protocol.Message{SSID: []byte{1}, From: "a", Protocol: "test", RoundNumber: 2, Data: []byte("hello"), Broadcast: true}
+	require.NoError(t, receivers["a"].transport.Send(ctx, "s", ids, bcast))
+	direct := &protocol.Message{SSID: []byte{1}, From: "a", To: "c", Protocol: "test", RoundNumber: 2, Data: []byte("hi c")}
+	require.NoError(t, receivers["a"].transport.Send(ctx, "s", ids, direct))
+
+	assert.Empty(t, receivers["a"].pending["s"])
+	require.Len(t, receivers["b"].pending["s"], 1)
+	assert.Equal(t, []byte("hello"), receivers["b"].pending["s"][0].Data)
+	require.Len(t, receivers["c"].pending["s"], 2)
+	assert.Equal(t, []byte("hi c"), receivers["c"].pending["s"][1].Data)
+}
+
+func TestTransportUnknownPeer(t *testing.T) {
+	tr := NewTransport("a", map[party.ID]string{}, nil)
+	msg := &protocol.Message{From: "a", To: "b", Protocol: "test", RoundNumber: 2}
+	err := tr.Send(context.Background(), "s", party.IDSlice{"a", "b"}, msg)
+	assert.ErrorIs(t, err, ErrUnknownPeer)
+}
+
+func TestPendingIsBounded(t *testing.T) {
+	pl := pool.NewPool(1)
+	defer pl.TearDown()
+	p := NewParty("a", map[party.ID]string{}, InMemoryStores(), pl)
+
+	msg := &protocol.Message{SSID: []byte{1}, From: "b", Protocol: "test", RoundNumber: 2, Broadcast: true}
+	for i := 0; i < MaxPendingSessions; i++ {
+		require.NoError(t, p.deliver(fmt.Sprint(i), msg))
+	}
+	for i := 1; i < MaxPendingMessages; i++ {
+		require.NoError(t, p.deliver("0", msg))
+	}
+	assert.ErrorIs(t, p.deliver("0", msg), ErrTooManyPending)
+	assert.ErrorIs(t, p.deliver("new", msg), ErrTooManyPending)
+	require.NoError(t, p.deliver("1", msg))
+
+	// the sender is asked to retry later
+	data, err := msg.MarshalBinary()
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodPost, MessagePath+"?session=new", bytes.NewReader(data)))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Len(t, p.pending, MaxPendingSessions)
+}
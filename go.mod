@@ -2,10 +2,15 @@ module github.com/mr-shifu/mpc-lib
 
 go 1.21.4
 
-require github.com/stretchr/testify v1.8.4
+require (
+	filippo.io/age v1.1.1
+	filippo.io/edwards25519 v1.1.0
+	github.com/ProtonMail/go-crypto v1.1.3
+	github.com/stretchr/testify v1.8.4
+)
 
 require (
-	filippo.io/edwards25519 v1.1.0 // indirect
+	github.com/cloudflare/circl v1.3.7 // indirect
 	github.com/klauspost/cpuid/v2 v2.0.12 // indirect
 	github.com/x448/float16 v0.8.4 // indirect
 	golang.org/x/sys v0.17.0 // indirect
@@ -10,6 +10,7 @@ import (
 	"github.com/mr-shifu/mpc-lib/core/pedersen"
 	zkaffg "github.com/mr-shifu/mpc-lib/core/zk/affg"
 	zkaffp "github.com/mr-shifu/mpc-lib/core/zk/affp"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
 )
 
@@ -25,7 +26,7 @@ import (
 // - Proof = zkaffg proof of correct encryption.
 func ProveAffG(group curve.Curve, h hash.Hash,
 	senderSecretShare *saferith.Int, senderSecretSharePoint curve.Point, receiverEncryptedShare *paillier.Ciphertext,
-	sender *paillier.PublicKey, receiver *paillier.PublicKey, verifier *pedersen.Parameters) (Beta *saferith.Int, D, F *paillier.Ciphertext, Proof *zkaffg.Proof) {
+	sender *paillier.PublicKey, receiver *paillier.PublicKey, verifier *pedersen.Parameters, profile params.Profile) (Beta *saferith.Int, D, F *paillier.Ciphertext, Proof *zkaffg.Proof) {
 	D, F, S, R, BetaNeg := newMta(senderSecretShare, receiverEncryptedShare, sender, receiver)
 	Proof = zkaffg.NewProof(group, h, zkaffg.Public{
 		Kv:       receiverEncryptedShare,
@@ -35,6 +36,7 @@ func ProveAffG(group curve.Curve, h hash.Hash,
 		Prover:   sender,
 		Verifier: receiver,
 		Aux:      verifier,
+		Profile:  profile,
 	}, zkaffg.Private{
 		X: senderSecretShare,
 		Y: BetaNeg,
@@ -65,7 +67,8 @@ func ProveAffP(
 	receiverEncryptedShare *paillier.Ciphertext,
 	sender *paillier.PublicKey,
 	receiver *paillier.PublicKey,
-	verifier *pedersen.Parameters) (Beta *saferith.Int, D, F *paillier.Ciphertext, Proof *zkaffp.Proof) {
+	verifier *pedersen.Parameters,
+	profile params.Profile) (Beta *saferith.Int, D, F *paillier.Ciphertext, Proof *zkaffp.Proof) {
 	D, F, S, R, BetaNeg := newMta(senderSecretShare, receiverEncryptedShare, sender, receiver)
 	Proof = zkaffp.NewProof(group, h, zkaffp.Public{
 		Kv:       receiverEncryptedShare,
@@ -75,6 +78,7 @@ func ProveAffP(
 		Prover:   sender,
 		Verifier: receiver,
 		Aux:      verifier,
+		Profile:  profile,
 	}, zkaffp.Private{
 		X:  senderSecretShare,
 		Y:  BetaNeg,
@@ -11,6 +11,7 @@ import (
 	"github.com/mr-shifu/mpc-lib/core/zk"
 	zkaffg "github.com/mr-shifu/mpc-lib/core/zk/affg"
 	zkaffp "github.com/mr-shifu/mpc-lib/core/zk/affp"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/hash"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/keystore"
@@ -25,8 +26,7 @@ func Test_newMtA(t *testing.T) {
 	hash_ks := keystore.NewInMemoryKeystore(hahs_vault, hahs_keyopts)
 	hash_mgr := hash.NewHashManager(hash_ks)
 
-	opts := keyopts.Options{}
-	opts.Set("id", "1", "partyid", "a")
+	opts := keyopts.New().WithKeyID("1").WithPartyID("a")
 	h := hash_mgr.NewHasher("test", opts)
 
 	group := curve.Secp256k1{}
@@ -67,8 +67,8 @@ func Test_newMtA(t *testing.T) {
 
 	{
 		Ai, Aj := aiScalar.ActOnBase(), ajScalar.ActOnBase()
-		betaI, Di, Fi, proofI := ProveAffG(group, h.Clone(), ai, Ai, Bj, paillierI, paillierJ, zk.Pedersen)
-		betaJ, Dj, Fj, proofJ := ProveAffG(group, h.Clone(), aj, Aj, Bi, paillierJ, paillierI, zk.Pedersen)
+		betaI, Di, Fi, proofI := ProveAffG(group, h.Clone(), ai, Ai, Bj, paillierI, paillierJ, zk.Pedersen, params.Standard)
+		betaJ, Dj, Fj, proofJ := ProveAffG(group, h.Clone(), aj, Aj, Bi, paillierJ, paillierI, zk.Pedersen, params.Standard)
 
 		assert.True(t, proofI.Verify(h.Clone(), zkaffg.Public{
 			Kv:       Bj,
@@ -94,8 +94,8 @@ func Test_newMtA(t *testing.T) {
 	{
 		Ai, nonceI := ski.Enc(ai)
 		Aj, nonceJ := skj.Enc(aj)
-		betaI, Di, Fi, proofI := ProveAffP(group, h.Clone(), ai, Ai, nonceI, Bj, paillierI, paillierJ, zk.Pedersen)
-		betaJ, Dj, Fj, proofJ := ProveAffP(group, h.Clone(), aj, Aj, nonceJ, Bi, paillierJ, paillierI, zk.Pedersen)
+		betaI, Di, Fi, proofI := ProveAffP(group, h.Clone(), ai, Ai, nonceI, Bj, paillierI, paillierJ, zk.Pedersen, params.Standard)
+		betaJ, Dj, Fj, proofJ := ProveAffP(group, h.Clone(), aj, Aj, nonceJ, Bi, paillierJ, paillierI, zk.Pedersen, params.Standard)
 
 		assert.True(t, proofI.Verify(group, h.Clone(), zkaffp.Public{
 			Kv:       Bj,
@@ -0,0 +1,79 @@
+package params
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Profile sets the statistical security of the range proofs.
+//
+// A range proof for x ∈ ±2ˡ masks x with α ∈ ±2ˡ⁺ᵉ, where ε is the slack, and the verifier accepts
+// responses in ±2ˡ⁺ᵉ. A smaller ε yields smaller proofs and faster provers, at the cost of
+// a statistical distance of about 2ˡ⁻ᵉ between real and simulated proofs.
+//
+// The profile of a session is set in its config, and passed to the proofs in their public statement.
+// The zero Profile stands for Standard.
+type Profile struct {
+	// Name identifies the profile in configs and logs.
+	Name string
+	// Epsilon is the slack ε, in bits.
+	Epsilon int
+}
+
+var (
+	// Standard is the profile recommended by CMP, with ε = 2κ.
+	Standard = Profile{Name: "standard", Epsilon: Epsilon}
+	// Compact reduces ε to ℓ + StatParam, keeping a statistical distance of 2⁻⁸⁰.
+	Compact = Profile{Name: "compact", Epsilon: L + StatParam}
+)
+
+// ErrInvalidProfile is returned when a profile does not provide enough statistical security.
+var ErrInvalidProfile = errors.New("params: invalid profile")
+
+// OrStandard returns p, or Standard if p is the zero Profile.
+func (p Profile) OrStandard() Profile {
+	if p == (Profile{}) {
+		return Standard
+	}
+	return p
+}
+
+// Validate checks that the slack is large enough for ε - ℓ ≥ StatParam, and no larger than necessary
+// to fit the masked values in the Paillier plaintext space.
+func (p Profile) Validate() error {
+	if p.Epsilon < L+StatParam {
+		return fmt.Errorf("%w: ε = %d must be at least %d", ErrInvalidProfile, p.Epsilon, L+StatParam)
+	}
+	if LPrime+p.Epsilon >= BitsIntModN {
+		return fmt.Errorf("%w: ℓ' + ε = %d must be less than %d", ErrInvalidProfile, LPrime+p.Epsilon, BitsIntModN)
+	}
+	return nil
+}
+
+// Eps returns ε.
+func (p Profile) Eps() int {
+	return p.OrStandard().Epsilon
+}
+
+// LPlusEpsilon returns ℓ + ε.
+func (p Profile) LPlusEpsilon() int {
+	return L + p.Eps()
+}
+
+// LPrimePlusEpsilon returns ℓ' + ε.
+func (p Profile) LPrimePlusEpsilon() int {
+	return LPrime + p.Eps()
+}
+
+// WriteTo implements io.WriterTo interface.
+func (p Profile) WriteTo(w io.Writer) (int64, error) {
+	err := binary.Write(w, binary.BigEndian, uint32(p.Epsilon))
+	return 4, err
+}
+
+// Domain implements hash.WriterToWithDomain.
+func (Profile) Domain() string {
+	return "ZK Profile"
+}
@@ -0,0 +1,17 @@
+package params
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfileValidate(t *testing.T) {
+	assert.NoError(t, Standard.Validate())
+	assert.NoError(t, Compact.Validate())
+	assert.ErrorIs(t, Profile{Name: "weak", Epsilon: L}.Validate(), ErrInvalidProfile)
+	assert.ErrorIs(t, Profile{Name: "huge", Epsilon: BitsIntModN}.Validate(), ErrInvalidProfile)
+
+	assert.Equal(t, Standard, Profile{}.OrStandard())
+	assert.Equal(t, Compact, Compact.OrStandard())
+}
@@ -0,0 +1,130 @@
+package round
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+)
+
+var ErrUnknownContent = errors.New("round: unknown content")
+
+// ContentKey identifies the content of the p2p or broadcast messages of a round of a protocol.
+type ContentKey struct {
+	Protocol  string
+	Round     Number
+	Broadcast bool
+}
+
+func (k ContentKey) String() string {
+	if k.Broadcast {
+		return fmt.Sprintf("%s round %d broadcast", k.Protocol, k.Round)
+	}
+	return fmt.Sprintf("%s round %d", k.Protocol, k.Round)
+}
+
+// ContentConstructor returns an empty content for the group and version of a session, ready for unmarshalling.
+type ContentConstructor func(group curve.Curve, version Version) Content
+
+// Registry maps the rounds of protocols to the constructors of their contents,
+// so that messages can be decoded knowing only their protocol and round, such as recorded messages.
+type Registry struct {
+	mtx          sync.RWMutex
+	constructors map[ContentKey]ContentConstructor
+}
+
+// Contents is the registry of the contents of the protocols of this module, filled by their packages.
+var Contents = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{constructors: map[ContentKey]ContentConstructor{}}
+}
+
+// Register adds the constructor of the content of key to registry.
+// It panics if key is already registered, since packages register their contents when initialized.
+func Register[C Content](registry *Registry, key ContentKey, newContent func(group curve.Curve, version Version) C) {
+	registry.mtx.Lock()
+	defer registry.mtx.Unlock()
+	if _, ok := registry.constructors[key]; ok {
+		panic(fmt.Sprintf("round: %s registered twice", key))
+	}
+	registry.constructors[key] = func(group curve.Curve, version Version) Content { return newContent(group, version) }
+}
+
+// New returns an empty content of key for the group and version of a session.
+func (registry *Registry) New(key ContentKey, group curve.Curve, version Version) (Content, error) {
+	registry.mtx.RLock()
+	newContent, ok := registry.constructors[key]
+	registry.mtx.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownContent, key)
+	}
+	return newContent(group, version), nil
+}
+
+// Keys returns the registered keys, sorted by protocol, round, and p2p before broadcast.
+func (registry *Registry) Keys() []ContentKey {
+	registry.mtx.RLock()
+	keys := make([]ContentKey, 0, len(registry.constructors))
+	for key := range registry.constructors {
+		keys = append(keys, key)
+	}
+	registry.mtx.RUnlock()
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.Protocol != b.Protocol {
+			return a.Protocol < b.Protocol
+		}
+		if a.Round != b.Round {
+			return a.Round < b.Round
+		}
+		return !a.Broadcast && b.Broadcast
+	})
+	return keys
+}
+
+// NewMessageContent returns an empty content of the p2p messages of the given round of the session,
+// from the constructor registered in Contents for its protocol.
+// It panics if none was registered, since packages register their contents when initialized.
+func (h *Helper) NewMessageContent(number Number) Content {
+	return h.newContent(ContentKey{Protocol: h.ProtocolID(), Round: number})
+}
+
+// NewBroadcastContent returns an empty content of the broadcast messages of the given round of the session,
+// from the constructor registered in Contents for its protocol. It panics as NewMessageContent.
+func (h *Helper) NewBroadcastContent(number Number) BroadcastContent {
+	key := ContentKey{Protocol: h.ProtocolID(), Round: number, Broadcast: true}
+	content, ok := h.newContent(key).(BroadcastContent)
+	if !ok {
+		panic(fmt.Sprintf("round: %s is not a broadcast content", key))
+	}
+	return content
+}
+
+func (h *Helper) newContent(key ContentKey) Content {
+	content, err := Contents.New(key, h.Group(), h.Version())
+	if err != nil {
+		panic(err)
+	}
+	return content
+}
+
+// ContentAs returns the content of msg as a C, such as *broadcast2 in StoreBroadcastMessage.
+//
+// The error wraps ErrInvalidContent, and names the expected and received types, if the content is not a non nil C.
+func ContentAs[C Content](msg Message) (C, error) {
+	content, ok := msg.Content.(C)
+	if !ok || isNil(content) {
+		var expected C
+		return expected, fmt.Errorf("%w: expected %T from %s, got %T", ErrInvalidContent, expected, msg.From, msg.Content)
+	}
+	return content, nil
+}
+
+func isNil(content Content) bool {
+	v := reflect.ValueOf(content)
+	return !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil())
+}
@@ -0,0 +1,86 @@
+package round_test
+
+import (
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type registryContent struct {
+	Point   curve.Point
+	Version round.Version
+}
+
+func (registryContent) RoundNumber() round.Number { return 2 }
+
+func TestRegistry(t *testing.T) {
+	registry := round.NewRegistry()
+	key := round.ContentKey{Protocol: "test", Round: 2}
+	round.Register(registry, key, func(group curve.Curve, version round.Version) *registryContent {
+		return &registryContent{Point: group.NewPoint(), Version: version}
+	})
+	assert.Panics(t, func() {
+		round.Register(registry, key, func(curve.Curve, round.Version) *registryContent { return nil })
+	})
+
+	content, err := registry.New(key, curve.Secp256k1{}, 2)
+	require.NoError(t, err)
+	require.IsType(t, &registryContent{}, content)
+	assert.Equal(t, round.Version(2), content.(*registryContent).Version)
+
+	_, err = registry.New(round.ContentKey{Protocol: "test", Round: 2, Broadcast: true}, curve.Secp256k1{}, 2)
+	assert.ErrorIs(t, err, round.ErrUnknownContent)
+	assert.Equal(t, []round.ContentKey{key}, registry.Keys())
+}
+
+func TestContentAs(t *testing.T) {
+	content := &registryContent{}
+	got, err := round.ContentAs[*registryContent](round.Message{From: "a", Content: content})
+	require.NoError(t, err)
+	assert.Same(t, content, got)
+
+	_, err = round.ContentAs[*registryContent](round.Message{From: "a", Content: &corruptContent{}})
+	assert.ErrorIs(t, err, round.ErrInvalidContent)
+	_, err = round.ContentAs[*registryContent](round.Message{From: "a", Content: (*registryContent)(nil)})
+	assert.ErrorIs(t, err, round.ErrInvalidContent)
+	_, err = round.ContentAs[*registryContent](round.Message{From: "a"})
+	assert.ErrorIs(t, err, round.ErrInvalidContent)
+}
+
+type registryBroadcast struct {
+	round.ReliableBroadcastContent
+	Point curve.Point
+}
+
+func (registryBroadcast) RoundNumber() round.Number { return 2 }
+
+func TestHelperContent(t *testing.T) {
+	const protocol = "round/content-test"
+	round.Register(round.Contents, round.ContentKey{Protocol: protocol, Round: 2}, func(group curve.Curve, version round.Version) *registryContent {
+		return &registryContent{Point: group.NewPoint(), Version: version}
+	})
+	round.Register(round.Contents, round.ContentKey{Protocol: protocol, Round: 2, Broadcast: true}, func(group curve.Curve, _ round.Version) *registryBroadcast {
+		return &registryBroadcast{Point: group.NewPoint()}
+	})
+	info := round.Info{
+		ProtocolID:       protocol,
+		FinalRoundNumber: 2,
+		SelfID:           "a",
+		PartyIDs:         []party.ID{"a", "b"},
+		Group:            curve.Secp256k1{},
+		Version:          3,
+	}
+	helper, err := round.ResumeSession("test", info, []byte("ssid"), nil, nil)
+	require.NoError(t, err)
+
+	content := helper.NewMessageContent(2)
+	require.IsType(t, &registryContent{}, content)
+	assert.Equal(t, round.Version(3), content.(*registryContent).Version)
+	require.IsType(t, &registryBroadcast{}, helper.NewBroadcastContent(2))
+	assert.True(t, helper.NewBroadcastContent(2).Reliable())
+	assert.Panics(t, func() { helper.NewMessageContent(3) }, "the contents of a round must be registered")
+}
@@ -0,0 +1,187 @@
+package round
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/cronokirby/saferith"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+)
+
+var (
+	ErrUnknownField     = errors.New("round: unknown content field")
+	ErrUnsupportedField = errors.New("round: unsupported mutation of content field")
+)
+
+// Mutation is a change made by Corrupt to a field of a message.
+type Mutation int
+
+const (
+	// Increment adds one to scalars and numbers, adds the base point to points, negates booleans,
+	// and flips the last bit of byte slices and of the binary encoding of other values, such as ciphertexts.
+	Increment Mutation = iota
+	// Zero sets scalars and numbers to zero, points to the identity, and clears byte slices.
+	Zero
+	// Remove sets the field to its zero value, such as nil for a proof.
+	Remove
+)
+
+func (m Mutation) String() string {
+	switch m {
+	case Increment:
+		return "increment"
+	case Zero:
+		return "zero"
+	case Remove:
+		return "remove"
+	default:
+		return fmt.Sprintf("mutation(%d)", int(m))
+	}
+}
+
+// Corrupt applies mutation to a field of the content of a real round message, so that tests and simulations
+// can check that the recipients reject the message and blame its sender.
+//
+// The field is named by its path from the content, such as "BigGammaShare" or "Proof.Z" for a field
+// of a proof. The field is replaced with a new value, so that values shared with the state of the sender
+// are left unchanged; the content itself is changed in place, and may be shared by all the recipients
+// of a broadcast.
+func Corrupt(msg *Message, field string, mutation Mutation) error {
+	if msg == nil || msg.Content == nil {
+		return fmt.Errorf("%w: no content", ErrUnknownField)
+	}
+	v, err := contentField(reflect.ValueOf(msg.Content), field)
+	if err != nil {
+		return err
+	}
+	if mutation == Remove {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	mutated, err := mutate(v.Interface(), mutation)
+	if err != nil {
+		return fmt.Errorf("%w: %s of type %s: %v", ErrUnsupportedField, field, v.Type(), err)
+	}
+	v.Set(reflect.ValueOf(mutated).Convert(v.Type()))
+	return nil
+}
+
+// contentField returns the settable field at the dotted path, dereferencing pointers along the way.
+func contentField(v reflect.Value, path string) (reflect.Value, error) {
+	for _, name := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}, fmt.Errorf("%w: %s goes through a nil value", ErrUnknownField, path)
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("%w: %s goes through a %s", ErrUnknownField, path, v.Type())
+		}
+		f, ok := v.Type().FieldByName(name)
+		if !ok || !f.IsExported() {
+			return reflect.Value{}, fmt.Errorf("%w: %s has no exported field %s", ErrUnknownField, v.Type(), name)
+		}
+		next, err := v.FieldByIndexErr(f.Index)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%w: %s goes through a nil value", ErrUnknownField, path)
+		}
+		v = next
+	}
+	if !v.CanSet() {
+		return reflect.Value{}, fmt.Errorf("%w: %s cannot be set", ErrUnknownField, path)
+	}
+	return v, nil
+}
+
+// mutate returns a mutated copy of x, leaving x unchanged.
+func mutate(x interface{}, mutation Mutation) (interface{}, error) {
+	if x == nil {
+		return nil, errors.New("nil value")
+	}
+	switch t := x.(type) {
+	case curve.Scalar:
+		group := t.Curve()
+		if mutation == Zero {
+			return group.NewScalar(), nil
+		}
+		one := group.NewScalar().SetNat(new(saferith.Nat).SetUint64(1))
+		return group.NewScalar().Set(t).Add(one), nil
+	case curve.Point:
+		group := t.Curve()
+		if mutation == Zero {
+			return group.NewPoint(), nil
+		}
+		return t.Add(group.NewBasePoint()), nil
+	case *saferith.Nat:
+		if mutation == Zero {
+			return new(saferith.Nat).SetUint64(0), nil
+		}
+		b := new(big.Int).Add(t.Big(), big.NewInt(1))
+		return new(saferith.Nat).SetBig(b, b.BitLen()), nil
+	case *saferith.Int:
+		b := new(big.Int)
+		if mutation != Zero {
+			b.Add(t.Big(), big.NewInt(1))
+		}
+		return new(saferith.Int).SetBig(b, b.BitLen()), nil
+	case bool:
+		return mutation != Zero && !t, nil
+	case []byte:
+		out := make([]byte, len(t))
+		if mutation != Zero {
+			if len(t) == 0 {
+				return nil, errors.New("empty bytes")
+			}
+			copy(out, t)
+			out[len(out)-1] ^= 1
+		}
+		return out, nil
+	case encoding.BinaryMarshaler:
+		if mutation == Zero {
+			return nil, errors.New("cannot be set to zero")
+		}
+		return flipEncoding(t)
+	}
+	switch v := reflect.ValueOf(x); v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if mutation == Zero {
+			return reflect.Zero(v.Type()).Interface(), nil
+		}
+		return reflect.ValueOf(v.Int() + 1).Convert(v.Type()).Interface(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if mutation == Zero {
+			return reflect.Zero(v.Type()).Interface(), nil
+		}
+		return reflect.ValueOf(v.Uint() + 1).Convert(v.Type()).Interface(), nil
+	}
+	return nil, errors.New("unsupported type")
+}
+
+// flipEncoding decodes the binary encoding of x with its last bit flipped into a new value of the same type.
+func flipEncoding(x encoding.BinaryMarshaler) (interface{}, error) {
+	data, err := x.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("empty encoding")
+	}
+	data[len(data)-1] ^= 1
+	t := reflect.TypeOf(x)
+	if t.Kind() != reflect.Pointer {
+		return nil, errors.New("cannot be decoded")
+	}
+	out, ok := reflect.New(t.Elem()).Interface().(encoding.BinaryUnmarshaler)
+	if !ok {
+		return nil, errors.New("cannot be decoded")
+	}
+	if err := out.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,71 @@
+package round_test
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/hash"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	zkdleq "github.com/mr-shifu/mpc-lib/core/zk/dleq"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type corruptContent struct {
+	Scalar curve.Scalar
+	Point  curve.Point
+	Proof  *zkdleq.Proof
+	Data   []byte
+	Count  int
+}
+
+func (corruptContent) RoundNumber() round.Number { return 2 }
+
+func TestCorrupt(t *testing.T) {
+	group := curve.Secp256k1{}
+	a := sample.Scalar(rand.Reader, group)
+	H := sample.Scalar(rand.Reader, group).ActOnBase()
+	public := zkdleq.Public{H: H, X: a.ActOnBase(), Y: a.Act(H)}
+	newContent := func() *corruptContent {
+		return &corruptContent{
+			Scalar: a,
+			Point:  public.X,
+			Proof:  zkdleq.NewProof(group, hash.New(), public, zkdleq.Private{A: a}),
+			Data:   []byte{1, 2, 3},
+			Count:  1,
+		}
+	}
+
+	c := newContent()
+	require.True(t, c.Proof.Verify(hash.New(), public))
+	msg := &round.Message{From: "a", Content: c}
+	require.NoError(t, round.Corrupt(msg, "Scalar", round.Increment))
+	require.NoError(t, round.Corrupt(msg, "Point", round.Increment))
+	require.NoError(t, round.Corrupt(msg, "Data", round.Increment))
+	require.NoError(t, round.Corrupt(msg, "Count", round.Increment))
+	assert.False(t, c.Scalar.Equal(a))
+	assert.True(t, c.Point.Equal(public.X.Add(group.NewBasePoint())))
+	assert.Equal(t, []byte{1, 2, 2}, c.Data)
+	assert.Equal(t, 2, c.Count)
+	assert.True(t, public.X.Equal(a.ActOnBase()), "the original values must be left unchanged")
+
+	// nested fields of proofs
+	for _, field := range []string{"Proof.Z", "Proof.A", "Proof.B"} {
+		c := newContent()
+		require.NoError(t, round.Corrupt(&round.Message{Content: c}, field, round.Increment), field)
+		assert.False(t, c.Proof.Verify(hash.New(), public), field)
+	}
+	c = newContent()
+	require.NoError(t, round.Corrupt(&round.Message{Content: c}, "Proof.Z", round.Zero))
+	assert.True(t, c.Proof.Z.IsZero())
+	require.NoError(t, round.Corrupt(&round.Message{Content: c}, "Point", round.Zero))
+	assert.True(t, c.Point.IsIdentity())
+	require.NoError(t, round.Corrupt(&round.Message{Content: c}, "Proof", round.Remove))
+	assert.Nil(t, c.Proof)
+
+	assert.ErrorIs(t, round.Corrupt(&round.Message{Content: c}, "Proof.Z", round.Increment), round.ErrUnknownField)
+	assert.ErrorIs(t, round.Corrupt(&round.Message{Content: c}, "Missing", round.Increment), round.ErrUnknownField)
+	assert.ErrorIs(t, round.Corrupt(&round.Message{Content: newContent()}, "Proof", round.Increment), round.ErrUnsupportedField)
+}
@@ -0,0 +1,129 @@
+package round
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/party"
+)
+
+// EncodingVersion is the version of the canonical encoding of messages, written as their first byte.
+const EncodingVersion byte = 1
+
+var ErrInvalidEncoding = errors.New("round: invalid message encoding")
+
+// contentEncoding encodes contents with the core deterministic encoding of RFC 8949,
+// so that the same content is always encoded to the same bytes, whatever the order of its maps.
+var contentEncoding cbor.EncMode
+
+func init() {
+	var err error
+	if contentEncoding, err = cbor.CoreDetEncOptions().EncMode(); err != nil {
+		panic(err)
+	}
+}
+
+// MarshalContent returns the canonical encoding of c, which is sent as the Data of a protocol message.
+func MarshalContent(c Content) ([]byte, error) {
+	return contentEncoding.Marshal(c)
+}
+
+// MarshalBinary returns the canonical encoding of m:
+// the version, then From, To, Broadcast, the round number and the content, in this order.
+// Variable length fields are prefixed with their length, so that the encoding is unambiguous.
+func (m *Message) MarshalBinary() ([]byte, error) {
+	if m.Content == nil {
+		return nil, fmt.Errorf("%w: no content", ErrInvalidEncoding)
+	}
+	content, err := MarshalContent(m.Content)
+	if err != nil {
+		return nil, err
+	}
+	buf := []byte{EncodingVersion}
+	buf = AppendField(buf, []byte(m.From))
+	buf = AppendField(buf, []byte(m.To))
+	buf = AppendBool(buf, m.Broadcast)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(m.Content.RoundNumber()))
+	buf = AppendField(buf, content)
+	return buf, nil
+}
+
+// UnmarshalBinary decodes the canonical encoding of a message into m,
+// whose Content must be set to an empty content of the expected type, such as Session.MessageContent.
+func (m *Message) UnmarshalBinary(data []byte) error {
+	if m.Content == nil {
+		return fmt.Errorf("%w: no content to decode into", ErrInvalidEncoding)
+	}
+	if len(data) == 0 || data[0] != EncodingVersion {
+		return fmt.Errorf("%w: unknown version", ErrInvalidEncoding)
+	}
+	data = data[1:]
+	from, data, err := ReadField(data)
+	if err != nil {
+		return err
+	}
+	to, data, err := ReadField(data)
+	if err != nil {
+		return err
+	}
+	broadcast, data, err := ReadBool(data)
+	if err != nil {
+		return err
+	}
+	if len(data) < 2 {
+		return fmt.Errorf("%w: truncated round number", ErrInvalidEncoding)
+	}
+	number := Number(binary.BigEndian.Uint16(data))
+	content, data, err := ReadField(data[2:])
+	if err != nil {
+		return err
+	}
+	if len(data) != 0 {
+		return fmt.Errorf("%w: trailing data", ErrInvalidEncoding)
+	}
+	if err := cbor.Unmarshal(content, m.Content); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+	}
+	if m.Content.RoundNumber() != number {
+		return fmt.Errorf("%w: content of round %d in a message of round %d", ErrInvalidEncoding, m.Content.RoundNumber(), number)
+	}
+	m.From, m.To, m.Broadcast = party.ID(from), party.ID(to), broadcast
+	return nil
+}
+
+// AppendField appends field to buf, prefixed with its length as a 4 byte big endian integer.
+func AppendField(buf, field []byte) []byte {
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(field)))
+	return append(buf, field...)
+}
+
+// ReadField reads a field written by AppendField from the start of data, and returns it with the rest of data.
+func ReadField(data []byte) (field, rest []byte, err error) {
+	if len(data) < 4 {
+		return nil, nil, fmt.Errorf("%w: truncated length", ErrInvalidEncoding)
+	}
+	n := binary.BigEndian.Uint32(data)
+	data = data[4:]
+	if uint64(n) > uint64(len(data)) {
+		return nil, nil, fmt.Errorf("%w: truncated field", ErrInvalidEncoding)
+	}
+	return data[:n], data[n:], nil
+}
+
+// AppendBool appends b to buf as a single byte.
+func AppendBool(buf []byte, b bool) []byte {
+	if b {
+		return append(buf, 1)
+	}
+	return append(buf, 0)
+}
+
+// ReadBool reads a bool written by AppendBool from the start of data, and returns it with the rest of data.
+func ReadBool(data []byte) (b bool, rest []byte, err error) {
+	if len(data) < 1 || data[0] > 1 {
+		return false, nil, fmt.Errorf("%w: invalid bool", ErrInvalidEncoding)
+	}
+	return data[0] == 1, data[1:], nil
+}
@@ -0,0 +1,45 @@
+package round_test
+
+import (
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type encodingContent struct {
+	Shares map[party.ID][]byte
+	Data   []byte
+}
+
+func (encodingContent) RoundNumber() round.Number { return 3 }
+
+func TestMessageEncoding(t *testing.T) {
+	shares := map[party.ID][]byte{}
+	for _, id := range []party.ID{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		shares[id] = []byte(id)
+	}
+	msg := &round.Message{From: "a", To: "b", Content: &encodingContent{Shares: shares, Data: []byte{1, 2}}}
+
+	data, err := msg.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, round.EncodingVersion, data[0])
+	for i := 0; i < 16; i++ {
+		again, err := msg.MarshalBinary()
+		require.NoError(t, err)
+		assert.Equal(t, data, again, "the encoding must not depend on the order of maps")
+	}
+
+	decoded := &round.Message{Content: &encodingContent{}}
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, msg, decoded)
+
+	for n := 0; n < len(data); n++ {
+		assert.ErrorIs(t, (&round.Message{Content: &encodingContent{}}).UnmarshalBinary(data[:n]), round.ErrInvalidEncoding)
+	}
+	assert.ErrorIs(t, decoded.UnmarshalBinary(append(data, 0)), round.ErrInvalidEncoding)
+	data[0]++
+	assert.ErrorIs(t, decoded.UnmarshalBinary(data), round.ErrInvalidEncoding)
+}
@@ -0,0 +1,69 @@
+package round
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+)
+
+// FuzzMessage decodes data as the p2p content expected by r, and verifies it as a message from party `from`
+// addressed to r, as the MultiHandler would on receiving it.
+//
+// It is meant to be called from a fuzz target, with a round reached once in its setup, so that the decoding
+// and verification of untrusted messages can be fuzzed continuously. Only VerifyMessage is called,
+// so that the state of r is left unchanged and every input is run against the same state.
+//
+// An error is returned if data cannot be decoded, or if the message is invalid. Any panic is a bug.
+func FuzzMessage(r Session, from party.ID, data []byte) error {
+	if err := checkFuzzSender(r, from); err != nil {
+		return err
+	}
+	content := r.MessageContent()
+	if content == nil {
+		return errors.New("round: no p2p message expected")
+	}
+	if err := DecodeContent(data, content); err != nil {
+		return err
+	}
+	return r.VerifyMessage(Message{
+		From:    from,
+		To:      r.SelfID(),
+		Content: content,
+	})
+}
+
+// FuzzBroadcastMessage decodes data as the broadcast content expected by r, and verifies it
+// as a broadcast message from party `from`, like FuzzMessage.
+//
+// Broadcast messages are verified and stored at once by StoreBroadcastMessage, so an input which is valid
+// changes the state of r, and a later copy of it may be rejected as a duplicate.
+// Fuzzers rarely produce valid messages, so this only matters for the seeds of the corpus.
+func FuzzBroadcastMessage(r Session, from party.ID, data []byte) error {
+	if err := checkFuzzSender(r, from); err != nil {
+		return err
+	}
+	b, ok := r.(BroadcastRound)
+	if !ok {
+		return errors.New("round: no broadcast message expected")
+	}
+	content := b.BroadcastContent()
+	if content == nil {
+		return errors.New("round: no broadcast message expected")
+	}
+	if err := DecodeContent(data, content); err != nil {
+		return err
+	}
+	return b.StoreBroadcastMessage(Message{
+		From:      from,
+		Broadcast: true,
+		Content:   content,
+	})
+}
+
+func checkFuzzSender(r Session, from party.ID) error {
+	if from == r.SelfID() || !r.PartyIDs().Contains(from) {
+		return fmt.Errorf("round: invalid sender %s", from)
+	}
+	return nil
+}
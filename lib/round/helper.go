@@ -10,6 +10,7 @@ import (
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 	"github.com/mr-shifu/mpc-lib/lib/types"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
 )
@@ -80,6 +81,23 @@ func NewSession(ID string, info Info, sessionID []byte, pl *pool.Pool, h hash.Ha
 		return nil, fmt.Errorf("session: %w", err)
 	}
 
+	if info.Version != 0 {
+		if err := h.WriteAny(info.Version); err != nil {
+			return nil, fmt.Errorf("session: %w", err)
+		}
+	}
+
+	profile := info.Profile.OrStandard()
+	if err := profile.Validate(); err != nil {
+		return nil, fmt.Errorf("session: %w", err)
+	}
+	// the standard profile is not hashed, so that existing SSIDs are unchanged
+	if profile.Epsilon != params.Standard.Epsilon {
+		if err := h.WriteAny(profile); err != nil {
+			return nil, fmt.Errorf("session: %w", err)
+		}
+	}
+
 	if info.Group != nil {
 		if err := h.WriteAny(&core_hash.BytesWithDomain{
 			TheDomain: "Group Name",
@@ -117,17 +135,45 @@ func NewSession(ID string, info Info, sessionID []byte, pl *pool.Pool, h hash.Ha
 	}, nil
 }
 
+// ResumeSession recreates the *Helper of a session created by NewSession, for example on another host,
+// given its ssid and the hash state restored from the session's store.
+func ResumeSession(ID string, info Info, ssid []byte, pl *pool.Pool, h hash.Hash) (*Helper, error) {
+	partyIDs := party.NewIDSlice(info.PartyIDs)
+	if !partyIDs.Valid() || !partyIDs.Contains(info.SelfID) {
+		return nil, errors.New("session: partyIDs invalid")
+	}
+	if len(ssid) == 0 {
+		return nil, errors.New("session: empty ssid")
+	}
+	return &Helper{
+		info:          info,
+		ID:            ID,
+		Pool:          pl,
+		partyIDs:      partyIDs,
+		otherPartyIDs: partyIDs.Remove(info.SelfID),
+		ssid:          ssid,
+		hash:          h,
+	}, nil
+}
+
 // HashForID returns a clone of the hash.Hash for this session, initialized with the given id.
+// It is the branch of the session hash for the domain of party IDs, see HashForDomain.
 func (h *Helper) HashForID(id party.ID) hash.Hash {
+	if id == "" {
+		h.mtx.Lock()
+		defer h.mtx.Unlock()
+		return h.hash.Clone()
+	}
+	return h.HashForDomain(id.Domain(), []byte(id))
+}
+
+// HashForDomain returns an independent branch of the hash.Hash for this session,
+// with each of data written separated by domain.
+func (h *Helper) HashForDomain(domain string, data ...[]byte) hash.Hash {
 	h.mtx.Lock()
 	defer h.mtx.Unlock()
 
-	cloned := h.hash.Clone()
-	if id != "" {
-		_ = cloned.WriteAny(id)
-	}
-
-	return cloned
+	return h.hash.WithDomain(domain, data...)
 }
 
 // UpdateHashState writes additional data to the hash state.
@@ -203,6 +249,12 @@ func (h *Helper) ProtocolID() string { return h.info.ProtocolID }
 // FinalRoundNumber is the number of rounds before the output round.
 func (h *Helper) FinalRoundNumber() Number { return h.info.FinalRoundNumber }
 
+// Version is the version of the protocol run in this session.
+func (h *Helper) Version() Version { return h.info.Version }
+
+// Profile is the profile of the range proofs in this session.
+func (h *Helper) Profile() params.Profile { return h.info.Profile.OrStandard() }
+
 // SSID the unique identifier for this protocol execution.
 func (h *Helper) SSID() []byte { return h.ssid }
 
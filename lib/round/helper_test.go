@@ -111,8 +111,7 @@ func TestNewSession(t *testing.T) {
 			hash_ks := keystore.NewInMemoryKeystore(hahs_vault, hahs_keyopts)
 			hash_mgr := hash.NewHashManager(hash_ks)
 
-			opts := keyopts.Options{}
-			opts.Set("id", keyID, "partyid", "a")
+			opts := keyopts.New().WithKeyID(keyID).WithPartyID("a")
 			h := hash_mgr.NewHasher("test", opts)
 
 			info := round.Info{
@@ -0,0 +1,72 @@
+package round
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+)
+
+// ErrInvariantViolated is wrapped by the errors returned by CheckInvariants.
+var ErrInvariantViolated = errors.New("round: invariant violated")
+
+// Invariant is a documented property of the protocol state, which must hold once a round has been reached.
+type Invariant struct {
+	// Name describes the invariant, e.g. "Γ = ∑ⱼ Γⱼ".
+	Name string
+	// Check returns an error describing the violation, or nil if the invariant holds.
+	Check func() error
+}
+
+// InvariantChecker is implemented by rounds which document invariants of the state
+// produced by the previous rounds.
+//
+// Checking invariants may be expensive, and is only done in debug mode (see protocol.WithDebugInvariants).
+type InvariantChecker interface {
+	Invariants() []Invariant
+}
+
+// InvariantError reports the first invariant violated when reaching a round.
+type InvariantError struct {
+	Round Number
+	Name  string
+	Err   error
+}
+
+func (e *InvariantError) Error() string {
+	return fmt.Sprintf("%s: round %d: %s: %v", ErrInvariantViolated, e.Round, e.Name, e.Err)
+}
+
+func (e *InvariantError) Unwrap() error {
+	return ErrInvariantViolated
+}
+
+// CheckInvariants checks the invariants of r, if any, in order, and returns an *InvariantError for the first violation.
+func CheckInvariants(r Session) error {
+	checker, ok := r.(InvariantChecker)
+	if !ok {
+		return nil
+	}
+	for _, inv := range checker.Invariants() {
+		if err := inv.Check(); err != nil {
+			return &InvariantError{Round: r.Number(), Name: inv.Name, Err: err}
+		}
+	}
+	return nil
+}
+
+// ForAllParties returns an Invariant checking that has(j) returns nil for every j in ids,
+// typically used to check that a value was stored for every party.
+func ForAllParties(name string, ids []party.ID, has func(j party.ID) error) Invariant {
+	return Invariant{
+		Name: name,
+		Check: func() error {
+			for _, j := range ids {
+				if err := has(j); err != nil {
+					return fmt.Errorf("party %s: %w", j, err)
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,47 @@
+package round
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/stretchr/testify/assert"
+)
+
+type invariantSession struct {
+	Session
+	invariants []Invariant
+}
+
+func (invariantSession) Number() Number { return 3 }
+
+func (s invariantSession) Invariants() []Invariant { return s.invariants }
+
+func TestCheckInvariants(t *testing.T) {
+	stored := map[party.ID]bool{"a": true, "b": true}
+	has := func(j party.ID) error {
+		if !stored[j] {
+			return errors.New("missing")
+		}
+		return nil
+	}
+	ok := Invariant{Name: "ok", Check: func() error { return nil }}
+
+	s := invariantSession{invariants: []Invariant{ok, ForAllParties("stored", []party.ID{"a", "b"}, has)}}
+	assert.NoError(t, CheckInvariants(s))
+
+	s.invariants = append(s.invariants, ForAllParties("complete", []party.ID{"a", "b", "c"}, has), Invariant{
+		Name:  "never reached",
+		Check: func() error { return errors.New("fail") },
+	})
+	err := CheckInvariants(s)
+	assert.ErrorIs(t, err, ErrInvariantViolated)
+	var invErr *InvariantError
+	if assert.ErrorAs(t, err, &invErr) {
+		assert.Equal(t, Number(3), invErr.Round)
+		assert.Equal(t, "complete", invErr.Name)
+		assert.EqualError(t, invErr.Err, "party c: missing")
+	}
+
+	assert.NoError(t, CheckInvariants(invariantSession{}.Session), "sessions without invariants are not checked")
+}
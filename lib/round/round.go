@@ -1,5 +1,11 @@
 package round
 
+// Round is a single round of a protocol.
+//
+// The session layer serializes the calls to StoreBroadcastMessage, StoreMessage and Finalize of a round,
+// so that they never run concurrently with each other, nor with VerifyMessage.
+// Rounds may therefore update their state without locking, but a session layer handing messages
+// to rounds from multiple goroutines, such as protocol.MultiHandler, must hold a lock per session.
 type Round interface {
 	// VerifyMessage handles an incoming Message and validates its content with regard to the protocol specification.
 	// The content argument can be cast to the appropriate type for this round without error check.
@@ -15,6 +21,8 @@ type Round interface {
 
 	// Finalize is called after all messages from the parties have been processed in the current round.
 	// Messages for the next round are sent out through the out channel.
+	// The caller provides a buffer large enough for all messages of the round, and hands them to the transport
+	// once Finalize returns, so that Finalize only performs the computation and never waits on the network.
 	// If a non-critical error occurs (like a failure to sample, hash, or send a message), the current round can be
 	// returned so that the caller may try to finalize again.
 	//
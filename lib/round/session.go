@@ -3,6 +3,7 @@ package round
 import (
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
 )
 
@@ -19,6 +20,10 @@ type Info struct {
 	Threshold int
 	// Group returns the group used for this protocol execution.
 	Group curve.Curve
+	// Version is the version of the protocol, which is included in the SSID if non zero.
+	Version Version
+	// Profile is the profile of the range proofs, which is included in the SSID unless it is the standard one.
+	Profile params.Profile
 }
 
 // Session represents the current execution of a round-based protocol.
@@ -0,0 +1,128 @@
+package round
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/cronokirby/saferith"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+)
+
+// ErrDegenerate is wrapped by the errors returned by ValidateContent and DecodeContent.
+var ErrDegenerate = errors.New("round: degenerate value")
+
+// StrictTag is the struct tag controlling the checks of ValidateContent on a field.
+// A field tagged `strict:"optional"` may be nil or zero, and a field tagged `strict:"-"` is not checked.
+const StrictTag = "strict"
+
+// Validator is implemented by values which can check themselves without any context, such as hash.Commitment.
+type Validator interface {
+	Validate() error
+}
+
+// ValidateContent checks the exported fields of a decoded content for degenerate values,
+// recursing into structs, slices and maps:
+//   - nil pointers and interfaces,
+//   - zero scalars and identity points,
+//   - zero saferith.Nat,
+//   - values whose Validate method fails.
+//
+// These checks only need the content itself. Checks which need the state of the round, such as whether
+// a ciphertext is a unit modulo the sender's N², are still done by the round.
+func ValidateContent(content Content) error {
+	return validateValue(reflect.ValueOf(content), "content")
+}
+
+// DecodeContent unmarshals data into content, and checks it with ValidateContent.
+//
+// Every decoder of untrusted messages goes through it, so that rounds receive no degenerate value
+// and need not check the fields of their contents for nil, zero or the identity themselves.
+func DecodeContent(data []byte, content Content) error {
+	if err := cbor.Unmarshal(data, content); err != nil {
+		return fmt.Errorf("round: failed to unmarshal: %w", err)
+	}
+	return ValidateContent(content)
+}
+
+func validateValue(v reflect.Value, path string) error {
+	if !v.IsValid() {
+		return fmt.Errorf("%w: %s is nil", ErrDegenerate, path)
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			if v.Kind() == reflect.Map || v.Kind() == reflect.Slice {
+				return nil
+			}
+			return fmt.Errorf("%w: %s is nil", ErrDegenerate, path)
+		}
+	}
+
+	if v.CanInterface() {
+		switch x := v.Interface().(type) {
+		case curve.Scalar:
+			if x.IsZero() {
+				return fmt.Errorf("%w: %s is zero", ErrDegenerate, path)
+			}
+			return nil
+		case curve.Point:
+			if x.IsIdentity() {
+				return fmt.Errorf("%w: %s is the identity", ErrDegenerate, path)
+			}
+			return nil
+		case *saferith.Nat:
+			if x.EqZero() == 1 {
+				return fmt.Errorf("%w: %s is zero", ErrDegenerate, path)
+			}
+			return nil
+		case Validator:
+			if err := x.Validate(); err != nil {
+				return fmt.Errorf("%w: %s: %v", ErrDegenerate, path, err)
+			}
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return validateValue(v.Elem(), path)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			tag := field.Tag.Get(StrictTag)
+			if tag == "-" {
+				continue
+			}
+			f := v.Field(i)
+			if tag == "optional" && f.IsZero() {
+				continue
+			}
+			if err := validateValue(f, path+"."+field.Name); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		// byte strings are opaque encodings, decoded and checked by the round
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := validateValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := validateValue(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
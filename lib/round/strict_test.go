@@ -0,0 +1,59 @@
+package round_test
+
+import (
+	"testing"
+
+	"github.com/cronokirby/saferith"
+	"github.com/mr-shifu/mpc-lib/core/hash"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/stretchr/testify/assert"
+)
+
+type strictProof struct {
+	Z *saferith.Nat
+}
+
+type strictContent struct {
+	round.NormalBroadcastContent
+	Point      curve.Point
+	Scalars    []curve.Scalar
+	Proof      *strictProof
+	Commitment hash.Commitment
+	Data       []byte
+	Optional   curve.Point `strict:"optional"`
+	Skipped    curve.Point `strict:"-"`
+}
+
+func (strictContent) RoundNumber() round.Number { return 2 }
+
+func TestValidateContent(t *testing.T) {
+	group := curve.Secp256k1{}
+	one := group.NewScalar().SetNat(new(saferith.Nat).SetUint64(1))
+	commitment := make(hash.Commitment, hash.DigestLengthBytes)
+	commitment[0] = 1
+	valid := func() *strictContent {
+		return &strictContent{
+			Point:      one.ActOnBase(),
+			Scalars:    []curve.Scalar{one},
+			Proof:      &strictProof{Z: new(saferith.Nat).SetUint64(2)},
+			Commitment: commitment,
+			Skipped:    group.NewPoint(),
+		}
+	}
+	assert.NoError(t, round.ValidateContent(valid()))
+
+	for name, tamper := range map[string]func(c *strictContent){
+		"identity point":     func(c *strictContent) { c.Point = group.NewPoint() },
+		"nil point":          func(c *strictContent) { c.Point = nil },
+		"zero scalar":        func(c *strictContent) { c.Scalars = append(c.Scalars, group.NewScalar()) },
+		"nil proof":          func(c *strictContent) { c.Proof = nil },
+		"zero nat":           func(c *strictContent) { c.Proof.Z = new(saferith.Nat) },
+		"invalid commitment": func(c *strictContent) { c.Commitment = hash.Commitment{1} },
+		"optional identity":  func(c *strictContent) { c.Optional = group.NewPoint() },
+	} {
+		c := valid()
+		tamper(c)
+		assert.ErrorIs(t, round.ValidateContent(c), round.ErrDegenerate, name)
+	}
+}
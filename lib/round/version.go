@@ -0,0 +1,70 @@
+package round
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+)
+
+// ErrNoCommonVersion is returned by Negotiate when the parties do not all support a common version.
+var ErrNoCommonVersion = errors.New("round: no protocol version supported by all parties")
+
+// Version identifies the revision of a protocol's messages and rounds.
+//
+// When set in Info, it is bound into the SSID, so that parties running incompatible versions
+// can never accept each other's messages. Version 0 means unversioned, and is not hashed.
+type Version uint32
+
+// WriteTo implements io.WriterTo interface.
+func (v Version) WriteTo(w io.Writer) (int64, error) {
+	err := binary.Write(w, binary.BigEndian, uint32(v))
+	return 4, err
+}
+
+// Domain implements hash.WriterToWithDomain.
+func (Version) Domain() string {
+	return "Protocol Version"
+}
+
+// Negotiate returns the highest version supported by this party and all other parties,
+// where supported[j] is the list of versions advertised by party j.
+//
+// If no such version exists, the returned error lists the parties which do not support
+// the highest version we would otherwise run, so that mixed-version committees abort with a clear message.
+func Negotiate(local []Version, supported map[party.ID][]Version) (Version, error) {
+	candidates := append([]Version{}, local...)
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i] > candidates[j] })
+
+	var laggards party.IDSlice
+	for i, v := range candidates {
+		var missing []party.ID
+		for j, versions := range supported {
+			if !containsVersion(versions, v) {
+				missing = append(missing, j)
+			}
+		}
+		if len(missing) == 0 {
+			return v, nil
+		}
+		if i == 0 {
+			laggards = party.NewIDSlice(missing)
+		}
+	}
+	if len(candidates) == 0 {
+		return 0, ErrNoCommonVersion
+	}
+	return 0, fmt.Errorf("%w: parties %v do not support version %d", ErrNoCommonVersion, laggards, candidates[0])
+}
+
+func containsVersion(versions []Version, v Version) bool {
+	for _, w := range versions {
+		if w == v {
+			return true
+		}
+	}
+	return false
+}
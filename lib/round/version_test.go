@@ -0,0 +1,38 @@
+package round_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+)
+
+func TestNegotiate(t *testing.T) {
+	local := []round.Version{1, 2, 3}
+	tests := []struct {
+		name      string
+		supported map[party.ID][]round.Version
+		want      round.Version
+		wantErr   bool
+	}{
+		{"all latest", map[party.ID][]round.Version{"a": {1, 2, 3}, "b": {3}}, 3, false},
+		{"one lagging", map[party.ID][]round.Version{"a": {1, 2, 3}, "b": {1, 2}}, 2, false},
+		{"no overlap", map[party.ID][]round.Version{"a": {3}, "b": {4}}, 0, true},
+		{"no parties", nil, 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := round.Negotiate(local, tt.supported)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Negotiate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, round.ErrNoCommonVersion) {
+				t.Errorf("Negotiate() error = %v, want ErrNoCommonVersion", err)
+			}
+			if got != tt.want {
+				t.Errorf("Negotiate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package test
+
+import (
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+)
+
+// Corruption is a Rule corrupting a field of the messages sent by party From in round Round, with round.Corrupt,
+// so that a test can check that the other parties reject them and blame From.
+type Corruption struct {
+	From     party.ID
+	Round    round.Number
+	Field    string
+	Mutation round.Mutation
+	// Err is the first error returned by round.Corrupt, such as for an unknown field.
+	Err error
+}
+
+func (c *Corruption) ModifyBefore(round.Session) {}
+
+func (c *Corruption) ModifyAfter(round.Session) {}
+
+func (c *Corruption) ModifyContent(rNext round.Session, to party.ID, content round.Content) {
+	if rNext == nil || rNext.SelfID() != c.From || content.RoundNumber() != c.Round {
+		return
+	}
+	msg := &round.Message{From: c.From, To: to, Broadcast: to == "", Content: content}
+	if err := round.Corrupt(msg, c.Field, c.Mutation); err != nil && c.Err == nil {
+		c.Err = err
+	}
+}
@@ -39,7 +39,10 @@ func Rounds(rounds []round.Session, rule Rule) (error, bool) {
 		idx := id
 		r := rounds[idx]
 		errGroup.Go(func() error {
-			var rNew, rNewReal round.Session
+			var (
+				rNew, rNewReal round.Session
+				err            error
+			)
 			if rule != nil {
 				rReal := getRound(r)
 				rule.ModifyBefore(rReal)
@@ -101,24 +104,24 @@ func Rounds(rounds []round.Session, rule Rule) (error, bool) {
 						return errors.New("broadcast message but not broadcast round")
 					}
 					m.Content = b.BroadcastContent()
-					if err = cbor.Unmarshal(msgBytes, m.Content); err != nil {
+					if err := round.DecodeContent(msgBytes, m.Content); err != nil {
 						return err
 					}
 
-					if err = b.StoreBroadcastMessage(m); err != nil {
+					if err := b.StoreBroadcastMessage(m); err != nil {
 						return err
 					}
 				} else {
 					m.Content = r.MessageContent()
-					if err = cbor.Unmarshal(msgBytes, m.Content); err != nil {
+					if err := round.DecodeContent(msgBytes, m.Content); err != nil {
 						return err
 					}
 
 					if m.To == "" || m.To == r.SelfID() {
-						if err = r.VerifyMessage(m); err != nil {
+						if err := r.VerifyMessage(m); err != nil {
 							return err
 						}
-						if err = r.StoreMessage(m); err != nil {
+						if err := r.StoreMessage(m); err != nil {
 							return err
 						}
 					}
@@ -211,24 +214,24 @@ func FROSTRounds(kgs []protocol.Processor, keyID string) ([]round.Session, bool,
 						return errors.New("broadcast message but not broadcast round")
 					}
 					m.Content = b.BroadcastContent()
-					if err = cbor.Unmarshal(msgBytes, m.Content); err != nil {
+					if err := round.DecodeContent(msgBytes, m.Content); err != nil {
 						return err
 					}
 
-					if err = b.StoreBroadcastMessage(m); err != nil {
+					if err := b.StoreBroadcastMessage(m); err != nil {
 						return err
 					}
 				} else {
 					m.Content = r.MessageContent()
-					if err = cbor.Unmarshal(msgBytes, m.Content); err != nil {
+					if err := round.DecodeContent(msgBytes, m.Content); err != nil {
 						return err
 					}
 
 					if m.To == "" || m.To == r.SelfID() {
-						if err = r.VerifyMessage(m); err != nil {
+						if err := r.VerifyMessage(m); err != nil {
 							return err
 						}
-						if err = r.StoreMessage(m); err != nil {
+						if err := r.StoreMessage(m); err != nil {
 							return err
 						}
 					}
@@ -0,0 +1,20 @@
+package types
+
+import (
+	"io"
+)
+
+// SigningContext wraps the context string of the application requesting a signature,
+// which separates the sessions of applications sharing a key.
+type SigningContext []byte
+
+// WriteTo implements io.WriterTo interface.
+func (c SigningContext) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(c)
+	return int64(n), err
+}
+
+// Domain implements hash.WriterToWithDomain.
+func (SigningContext) Domain() string {
+	return "Signing Context"
+}
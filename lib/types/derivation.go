@@ -0,0 +1,24 @@
+package types
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// DerivationPath wraps the BIP32 indices of a child key, from the master key down.
+type DerivationPath []uint32
+
+// WriteTo implements io.WriterTo interface.
+func (p DerivationPath) WriteTo(w io.Writer) (int64, error) {
+	buf := make([]byte, 4*len(p))
+	for i, index := range p {
+		binary.BigEndian.PutUint32(buf[4*i:], index)
+	}
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+// Domain implements hash.WriterToWithDomain.
+func (DerivationPath) Domain() string {
+	return "Derivation Path"
+}
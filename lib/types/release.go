@@ -0,0 +1,23 @@
+package types
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// ReleaseTime wraps the time before which the signers of a session refuse to release their signature shares.
+type ReleaseTime time.Time
+
+// WriteTo implements io.WriterTo interface, writing the time as big endian Unix seconds.
+func (t ReleaseTime) WriteTo(w io.Writer) (int64, error) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(time.Time(t).Unix()))
+	n, err := w.Write(buf[:])
+	return int64(n), err
+}
+
+// Domain implements hash.WriterToWithDomain.
+func (ReleaseTime) Domain() string {
+	return "Release Time"
+}
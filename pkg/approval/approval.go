@@ -0,0 +1,414 @@
+// Package approval verifies that human operators approved a signature request with their FIDO2 security keys.
+//
+// An operator approves a request by signing its Challenge with a WebAuthn assertion (navigator.credentials.get
+// in a browser, or a FIDO2 CLI). Every party derives the challenge from the request it was given, and verifies
+// the assertions against the credentials of the operators registered with it, so that a request which was
+// altered on its way to a party is refused by that party.
+package approval
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/party"
+)
+
+var (
+	ErrNotApproved         = errors.New("approval: not enough operators approved the request")
+	ErrUnknownCredential   = errors.New("approval: unknown credential")
+	ErrInvalidAssertion    = errors.New("approval: invalid assertion")
+	ErrInvalidCredential   = errors.New("approval: invalid credential")
+	ErrDuplicateCredential = errors.New("approval: credential already registered")
+)
+
+// Request is the part of a signature request approved by the operators.
+// It must include every field of the request which changes what is signed, by whom, or when, so that an
+// approval cannot be reused for a request differing in any of them.
+type Request struct {
+	KeyID   string
+	SignID  string
+	Message []byte
+	Context string
+	// Parties are the signers requested, or nil if the node selects them.
+	Parties []party.ID
+	// ReleaseTime is the time before which the signature is not released, if not zero.
+	ReleaseTime time.Time
+	// DedupKey identifies the retries of the request.
+	DedupKey string
+	// Labels are stored with the record of the signature.
+	Labels map[string]string
+}
+
+// Challenge returns the WebAuthn challenge of the request, the SHA-256 hash of its length-prefixed fields.
+// The parties are hashed in the order of the request, and the labels sorted by name.
+func (r Request) Challenge() []byte {
+	h := sha256.New()
+	write := func(field []byte) {
+		var size [8]byte
+		binary.BigEndian.PutUint64(size[:], uint64(len(field)))
+		_, _ = h.Write(size[:])
+		_, _ = h.Write(field)
+	}
+	for _, field := range [][]byte{[]byte("mpc-lib approval v2"), []byte(r.KeyID), []byte(r.SignID), r.Message, []byte(r.Context)} {
+		write(field)
+	}
+	write(binary.BigEndian.AppendUint64(nil, uint64(len(r.Parties))))
+	for _, id := range r.Parties {
+		write([]byte(id))
+	}
+	var release []byte
+	if !r.ReleaseTime.IsZero() {
+		release = binary.BigEndian.AppendUint64(nil, uint64(r.ReleaseTime.UnixNano()))
+	}
+	write(release)
+	write([]byte(r.DedupKey))
+	names := make([]string, 0, len(r.Labels))
+	for name := range r.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	write(binary.BigEndian.AppendUint64(nil, uint64(len(names))))
+	for _, name := range names {
+		write([]byte(name))
+		write([]byte(r.Labels[name]))
+	}
+	return h.Sum(nil)
+}
+
+// Credential is the FIDO2 credential of an operator, as returned by navigator.credentials.create.
+type Credential struct {
+	// Operator identifies the human holding the key; assertions of the same operator only count once.
+	Operator string `json:"operator"`
+	// ID is the credential ID.
+	ID []byte `json:"id"`
+	// PublicKey is the COSE encoded public key, either ES256 (P-256) or EdDSA (Ed25519).
+	PublicKey []byte `json:"publicKey"`
+	// SignCount is the last signature counter seen for the credential.
+	SignCount uint32 `json:"signCount"`
+}
+
+// Assertion is the response of an authenticator to the challenge of a request, as returned by
+// navigator.credentials.get.
+type Assertion struct {
+	CredentialID      []byte `json:"credentialId"`
+	AuthenticatorData []byte `json:"authenticatorData"`
+	ClientDataJSON    []byte `json:"clientDataJSON"`
+	Signature         []byte `json:"signature"`
+}
+
+// Policy verifies the assertions approving a request against the registered credentials.
+// It must be created with NewPolicy or LoadPolicy.
+type Policy struct {
+	// RPID is the WebAuthn relying party ID the credentials were created for, such as "approvals.example.com".
+	RPID string
+	// Origins are the origins from which operators may approve, such as "https://approvals.example.com".
+	Origins []string
+	// Required is the number of distinct operators who must approve a request, at least 1.
+	Required int
+	// UserVerification requires the authenticator to have verified the operator, with a PIN or biometrics,
+	// rather than only their presence.
+	UserVerification bool
+
+	mtx         sync.Mutex
+	credentials map[string]*Credential
+	// path is the file the policy was loaded from, to which the signature counters are written back.
+	path string
+}
+
+// NewPolicy returns a policy without credentials.
+func NewPolicy(rpID string, origins []string, required int, userVerification bool) *Policy {
+	return &Policy{
+		RPID:             rpID,
+		Origins:          origins,
+		Required:         required,
+		UserVerification: userVerification,
+		credentials:      map[string]*Credential{},
+	}
+}
+
+// policyFile is the format of the file read by LoadPolicy.
+type policyFile struct {
+	RPID             string        `json:"rpId"`
+	Origins          []string      `json:"origins"`
+	Required         int           `json:"required"`
+	UserVerification bool          `json:"userVerification"`
+	Credentials      []*Credential `json:"credentials"`
+}
+
+// LoadPolicy reads a policy and its credentials from a JSON file, whose binary fields are base64 encoded.
+// The signature counters of the credentials are written back to the file whenever a request is approved,
+// so that an assertion cannot be replayed to a restarted node.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f policyFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("approval: policy file %s: %w", path, err)
+	}
+	p := NewPolicy(f.RPID, f.Origins, f.Required, f.UserVerification)
+	for _, c := range f.Credentials {
+		if err := p.Register(c); err != nil {
+			return nil, fmt.Errorf("approval: policy file %s: %w", path, err)
+		}
+	}
+	p.path = path
+	return p, nil
+}
+
+// write writes the policy back to the file it was loaded from. p.mtx must be held.
+func (p *Policy) write() error {
+	f := policyFile{RPID: p.RPID, Origins: p.Origins, Required: p.Required, UserVerification: p.UserVerification}
+	for _, c := range p.credentials {
+		f.Credentials = append(f.Credentials, c)
+	}
+	sort.Slice(f.Credentials, func(i, j int) bool {
+		return credentialKey(f.Credentials[i].ID) < credentialKey(f.Credentials[j].ID)
+	})
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p.path), "tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), p.path)
+}
+
+// Register adds the credential of an operator.
+func (p *Policy) Register(c *Credential) error {
+	if c == nil || c.Operator == "" || len(c.ID) == 0 {
+		return fmt.Errorf("%w: missing operator or ID", ErrInvalidCredential)
+	}
+	if _, err := parsePublicKey(c.PublicKey); err != nil {
+		return err
+	}
+	id := credentialKey(c.ID)
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if _, ok := p.credentials[id]; ok {
+		return ErrDuplicateCredential
+	}
+	stored := *c
+	p.credentials[id] = &stored
+	return nil
+}
+
+// Verify checks that at least Required distinct operators approved the request with valid assertions.
+//
+// The signature counters of the credentials are only updated if the request is approved, and persisted if
+// the policy was loaded from a file; the request is refused if they cannot be.
+// An authenticator whose counter did not increase is rejected as a possible clone, unless it does not
+// implement counters, in which case both counters are 0.
+func (p *Policy) Verify(r Request, assertions []Assertion) error {
+	challenge := r.Challenge()
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	operators := map[string]bool{}
+	counts := map[*Credential]uint32{}
+	for i, a := range assertions {
+		c, ok := p.credentials[credentialKey(a.CredentialID)]
+		if !ok {
+			return fmt.Errorf("%w: assertion %d", ErrUnknownCredential, i)
+		}
+		if _, ok := counts[c]; ok {
+			return fmt.Errorf("%w: assertion %d: credential used twice", ErrInvalidAssertion, i)
+		}
+		count, err := p.verifyAssertion(c, challenge, a)
+		if err != nil {
+			return fmt.Errorf("assertion %d of %s: %w", i, c.Operator, err)
+		}
+		counts[c] = count
+		operators[c.Operator] = true
+	}
+	required := p.Required
+	if required < 1 {
+		required = 1
+	}
+	if len(operators) < required {
+		return fmt.Errorf("%w: %d of %d", ErrNotApproved, len(operators), required)
+	}
+	previous := make(map[*Credential]uint32, len(counts))
+	for c, count := range counts {
+		previous[c] = c.SignCount
+		c.SignCount = count
+	}
+	if p.path != "" {
+		if err := p.write(); err != nil {
+			for c, count := range previous {
+				c.SignCount = count
+			}
+			return fmt.Errorf("approval: persist signature counters: %w", err)
+		}
+	}
+	return nil
+}
+
+// WebAuthn authenticator data flags.
+const (
+	flagUserPresent  = 1 << 0
+	flagUserVerified = 1 << 2
+)
+
+// clientData is the part of the client data of an assertion which is checked.
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// verifyAssertion returns the signature counter of a, if it is a valid assertion of the challenge by c.
+func (p *Policy) verifyAssertion(c *Credential, challenge []byte, a Assertion) (uint32, error) {
+	var client clientData
+	if err := json.Unmarshal(a.ClientDataJSON, &client); err != nil {
+		return 0, fmt.Errorf("%w: client data: %v", ErrInvalidAssertion, err)
+	}
+	if client.Type != "webauthn.get" {
+		return 0, fmt.Errorf("%w: client data of type %q", ErrInvalidAssertion, client.Type)
+	}
+	signed, err := base64.RawURLEncoding.DecodeString(client.Challenge)
+	if err != nil || subtle.ConstantTimeCompare(signed, challenge) != 1 {
+		return 0, fmt.Errorf("%w: challenge of another request", ErrInvalidAssertion)
+	}
+	if client.CrossOrigin || !p.allowedOrigin(client.Origin) {
+		return 0, fmt.Errorf("%w: origin %q", ErrInvalidAssertion, client.Origin)
+	}
+
+	// authenticator data: rpIdHash (32) | flags (1) | signCount (4) | extensions
+	data := a.AuthenticatorData
+	if len(data) < 37 {
+		return 0, fmt.Errorf("%w: authenticator data too short", ErrInvalidAssertion)
+	}
+	rpIDHash := sha256.Sum256([]byte(p.RPID))
+	if !bytes.Equal(data[:32], rpIDHash[:]) {
+		return 0, fmt.Errorf("%w: relying party", ErrInvalidAssertion)
+	}
+	flags := data[32]
+	if flags&flagUserPresent == 0 {
+		return 0, fmt.Errorf("%w: user not present", ErrInvalidAssertion)
+	}
+	if p.UserVerification && flags&flagUserVerified == 0 {
+		return 0, fmt.Errorf("%w: user not verified", ErrInvalidAssertion)
+	}
+	count := binary.BigEndian.Uint32(data[33:37])
+	if (count != 0 || c.SignCount != 0) && count <= c.SignCount {
+		return 0, fmt.Errorf("%w: signature counter %d after %d", ErrInvalidAssertion, count, c.SignCount)
+	}
+
+	clientHash := sha256.Sum256(a.ClientDataJSON)
+	signedData := append(append([]byte(nil), data...), clientHash[:]...)
+	key, err := parsePublicKey(c.PublicKey)
+	if err != nil {
+		return 0, err
+	}
+	if !key.verify(signedData, a.Signature) {
+		return 0, fmt.Errorf("%w: signature", ErrInvalidAssertion)
+	}
+	return count, nil
+}
+
+func (p *Policy) allowedOrigin(origin string) bool {
+	for _, o := range p.Origins {
+		if o == origin {
+			return true
+		}
+	}
+	return false
+}
+
+func credentialKey(id []byte) string {
+	return base64.RawURLEncoding.EncodeToString(id)
+}
+
+// COSE key parameters and algorithms, see RFC 9053.
+const (
+	coseKty = 1
+	coseAlg = 3
+	coseCrv = -1
+	coseX   = -2
+	coseY   = -3
+
+	coseKtyOKP     = 1
+	coseKtyEC2     = 2
+	coseAlgES256   = -7
+	coseAlgEdDSA   = -8
+	coseCrvP256    = 1
+	coseCrvEd25519 = 6
+)
+
+// publicKey is an ES256 or EdDSA public key of a credential.
+type publicKey struct {
+	ecdsa   *ecdsa.PublicKey
+	ed25519 ed25519.PublicKey
+}
+
+func parsePublicKey(data []byte) (*publicKey, error) {
+	var key map[int]interface{}
+	if err := cbor.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("%w: public key: %v", ErrInvalidCredential, err)
+	}
+	kty, _ := key[coseKty].(uint64)
+	alg, _ := key[coseAlg].(int64)
+	crv, _ := key[coseCrv].(uint64)
+	x, _ := key[coseX].([]byte)
+	switch {
+	case kty == coseKtyEC2 && alg == coseAlgES256 && crv == coseCrvP256:
+		y, _ := key[coseY].([]byte)
+		if len(x) != 32 || len(y) != 32 {
+			return nil, fmt.Errorf("%w: invalid P-256 coordinates", ErrInvalidCredential)
+		}
+		pk := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !pk.Curve.IsOnCurve(pk.X, pk.Y) {
+			return nil, fmt.Errorf("%w: point not on P-256", ErrInvalidCredential)
+		}
+		return &publicKey{ecdsa: pk}, nil
+	case kty == coseKtyOKP && alg == coseAlgEdDSA && crv == coseCrvEd25519:
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: invalid Ed25519 key", ErrInvalidCredential)
+		}
+		return &publicKey{ed25519: ed25519.PublicKey(x)}, nil
+	}
+	return nil, fmt.Errorf("%w: unsupported key type %d with algorithm %d", ErrInvalidCredential, kty, alg)
+}
+
+func (k *publicKey) verify(data, signature []byte) bool {
+	if k.ecdsa != nil {
+		digest := sha256.Sum256(data)
+		return ecdsa.VerifyASN1(k.ecdsa, digest[:], signature)
+	}
+	return ed25519.Verify(k.ed25519, data, signature)
+}
@@ -0,0 +1,182 @@
+package approval
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// authenticator simulates the FIDO2 security key of an operator.
+type authenticator struct {
+	credential *Credential
+	sign       func(data []byte) []byte
+	count      uint32
+}
+
+func newP256Authenticator(t *testing.T, operator string) *authenticator {
+	sk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	key, err := cbor.Marshal(map[int]interface{}{
+		coseKty: coseKtyEC2, coseAlg: coseAlgES256, coseCrv: coseCrvP256,
+		coseX: sk.X.FillBytes(make([]byte, 32)), coseY: sk.Y.FillBytes(make([]byte, 32)),
+	})
+	require.NoError(t, err)
+	return &authenticator{
+		credential: &Credential{Operator: operator, ID: []byte(operator + "-p256"), PublicKey: key},
+		sign: func(data []byte) []byte {
+			digest := sha256.Sum256(data)
+			sig, err := ecdsa.SignASN1(rand.Reader, sk, digest[:])
+			require.NoError(t, err)
+			return sig
+		},
+	}
+}
+
+func newEd25519Authenticator(t *testing.T, operator string) *authenticator {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key, err := cbor.Marshal(map[int]interface{}{coseKty: coseKtyOKP, coseAlg: coseAlgEdDSA, coseCrv: coseCrvEd25519, coseX: []byte(pk)})
+	require.NoError(t, err)
+	return &authenticator{
+		credential: &Credential{Operator: operator, ID: []byte(operator + "-ed25519"), PublicKey: key},
+		sign:       func(data []byte) []byte { return ed25519.Sign(sk, data) },
+	}
+}
+
+func (a *authenticator) assert(t *testing.T, rpID, origin string, challenge []byte, flags byte) Assertion {
+	client, err := json.Marshal(clientData{Type: "webauthn.get", Challenge: base64.RawURLEncoding.EncodeToString(challenge), Origin: origin})
+	require.NoError(t, err)
+	a.count++
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	data := append(rpIDHash[:], flags)
+	data = binary.BigEndian.AppendUint32(data, a.count)
+	clientHash := sha256.Sum256(client)
+	return Assertion{
+		CredentialID:      a.credential.ID,
+		AuthenticatorData: data,
+		ClientDataJSON:    client,
+		Signature:         a.sign(append(append([]byte(nil), data...), clientHash[:]...)),
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	const rpID, origin = "approvals.example.com", "https://approvals.example.com"
+	policy := NewPolicy(rpID, []string{origin}, 2, true)
+	alice, bob := newP256Authenticator(t, "alice"), newEd25519Authenticator(t, "bob")
+	aliceBackup := newEd25519Authenticator(t, "alice")
+	for _, a := range []*authenticator{alice, bob, aliceBackup} {
+		require.NoError(t, policy.Register(a.credential))
+	}
+	assert.ErrorIs(t, policy.Register(alice.credential), ErrDuplicateCredential)
+
+	req := Request{KeyID: "treasury", SignID: "sign-1", Message: []byte("pay 100 to bob")}
+	challenge := req.Challenge()
+	verified := byte(flagUserPresent | flagUserVerified)
+
+	// two keys of the same operator count once
+	err := policy.Verify(req, []Assertion{
+		alice.assert(t, rpID, origin, challenge, verified),
+		aliceBackup.assert(t, rpID, origin, challenge, verified),
+	})
+	assert.ErrorIs(t, err, ErrNotApproved)
+
+	approvals := []Assertion{alice.assert(t, rpID, origin, challenge, verified), bob.assert(t, rpID, origin, challenge, verified)}
+	require.NoError(t, policy.Verify(req, approvals))
+
+	// the approval is bound to the request, and cannot be replayed
+	other := req
+	other.Message = []byte("pay 1000 to mallory")
+	assert.ErrorIs(t, policy.Verify(other, approvals), ErrInvalidAssertion)
+	assert.ErrorIs(t, policy.Verify(req, approvals), ErrInvalidAssertion)
+
+	tests := map[string]Assertion{
+		"user not verified": bob.assert(t, rpID, origin, challenge, flagUserPresent),
+		"other origin":      bob.assert(t, rpID, "https://phishing.example.com", challenge, verified),
+		"other rp":          bob.assert(t, "phishing.example.com", origin, challenge, verified),
+	}
+	for name, a := range tests {
+		err := policy.Verify(req, []Assertion{alice.assert(t, rpID, origin, challenge, verified), a})
+		assert.ErrorIs(t, err, ErrInvalidAssertion, name)
+	}
+	forged := bob.assert(t, rpID, origin, challenge, verified)
+	forged.Signature = alice.assert(t, rpID, origin, challenge, verified).Signature
+	assert.ErrorIs(t, policy.Verify(req, []Assertion{forged}), ErrInvalidAssertion)
+
+	unknown := newP256Authenticator(t, "mallory")
+	assert.ErrorIs(t, policy.Verify(req, []Assertion{unknown.assert(t, rpID, origin, challenge, verified)}), ErrUnknownCredential)
+}
+
+func TestChallengeFields(t *testing.T) {
+	req := Request{
+		KeyID:       "treasury",
+		SignID:      "sign-1",
+		Message:     []byte("pay 100 to bob"),
+		Parties:     []party.ID{"a", "b"},
+		ReleaseTime: time.Unix(1700000000, 0),
+		Labels:      map[string]string{"ticket": "OPS-1"},
+	}
+	challenge := req.Challenge()
+	changes := map[string]func(r *Request){
+		"key":          func(r *Request) { r.KeyID = "cold" },
+		"sign id":      func(r *Request) { r.SignID = "sign-2" },
+		"message":      func(r *Request) { r.Message = []byte("pay 1000 to mallory") },
+		"context":      func(r *Request) { r.Context = "other" },
+		"parties":      func(r *Request) { r.Parties = []party.ID{"a", "c"} },
+		"no parties":   func(r *Request) { r.Parties = nil },
+		"release time": func(r *Request) { r.ReleaseTime = time.Time{} },
+		"dedup key":    func(r *Request) { r.DedupKey = "retry" },
+		"labels":       func(r *Request) { r.Labels = map[string]string{"ticket": "OPS-2"} },
+		"label split":  func(r *Request) { r.Labels = map[string]string{"ticke": "tOPS-1"} },
+	}
+	for name, change := range changes {
+		other := req
+		change(&other)
+		assert.NotEqual(t, challenge, other.Challenge(), name)
+	}
+	other := req
+	other.Labels = map[string]string{"ticket": "OPS-1"}
+	assert.Equal(t, challenge, other.Challenge())
+}
+
+func TestLoadPolicySignCount(t *testing.T) {
+	const rpID, origin = "approvals.example.com", "https://approvals.example.com"
+	alice := newP256Authenticator(t, "alice")
+	path := filepath.Join(t.TempDir(), "policy.json")
+	data, err := json.Marshal(policyFile{RPID: rpID, Origins: []string{origin}, Required: 1, Credentials: []*Credential{alice.credential}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	policy, err := LoadPolicy(path)
+	require.NoError(t, err)
+	req := Request{KeyID: "treasury", SignID: "sign-1", Message: []byte("pay 100 to bob")}
+	approvals := []Assertion{alice.assert(t, rpID, origin, req.Challenge(), flagUserPresent)}
+	require.NoError(t, policy.Verify(req, approvals))
+
+	// the counter survives a restart, so that the assertion cannot be replayed
+	restarted, err := LoadPolicy(path)
+	require.NoError(t, err)
+	assert.ErrorIs(t, restarted.Verify(req, approvals), ErrInvalidAssertion)
+	require.NoError(t, restarted.Verify(req, []Assertion{alice.assert(t, rpID, origin, req.Challenge(), flagUserPresent)}))
+
+	// a request is refused if its counters cannot be persisted, and the counters are left unchanged
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, os.MkdirAll(filepath.Join(path, "busy"), 0o700))
+	approvals = []Assertion{alice.assert(t, rpID, origin, req.Challenge(), flagUserPresent)}
+	assert.Error(t, restarted.Verify(req, approvals))
+	require.NoError(t, os.RemoveAll(path))
+	require.NoError(t, restarted.Verify(req, approvals))
+}
@@ -0,0 +1,53 @@
+package backup
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// AgeRecipient encrypts bundles to age public keys, as `age --encrypt -r` does.
+// The bundles are binary age files, readable with `age --decrypt -i`.
+type AgeRecipient struct {
+	recipients []*age.X25519Recipient
+}
+
+// NewAgeRecipient returns a Recipient for the age public keys, such as printed by `age-keygen -y`.
+func NewAgeRecipient(recipients ...string) (*AgeRecipient, error) {
+	r := &AgeRecipient{}
+	for _, s := range recipients {
+		recipient, err := age.ParseX25519Recipient(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRecipient, err)
+		}
+		r.recipients = append(r.recipients, recipient)
+	}
+	if len(r.recipients) == 0 {
+		return nil, fmt.Errorf("%w: no age key", ErrInvalidRecipient)
+	}
+	return r, nil
+}
+
+// String returns the public keys.
+func (r *AgeRecipient) String() string {
+	keys := make([]string, 0, len(r.recipients))
+	for _, recipient := range r.recipients {
+		keys = append(keys, recipient.String())
+	}
+	return strings.Join(keys, ", ")
+}
+
+// Encrypt implements Recipient.
+func (r *AgeRecipient) Encrypt(w io.Writer) (io.WriteCloser, error) {
+	recipients := make([]age.Recipient, 0, len(r.recipients))
+	for _, recipient := range r.recipients {
+		recipients = append(recipients, recipient)
+	}
+	enc, err := age.Encrypt(w, recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecipient, err)
+	}
+	return enc, nil
+}
@@ -0,0 +1,112 @@
+// Package backup exports the share of a party as a bundle encrypted to the keys of its custodians,
+// for offline backup with the tools security teams already use.
+//
+// A Recipient encrypts a bundle to one or more keys of the same kind: OpenPGPRecipient to OpenPGP public keys,
+// readable with `gpg --decrypt`, and AgeRecipient to age public keys, readable with `age --decrypt`.
+// Other kinds of keys, such as a KMS, are supported by implementing Recipient.
+// The decrypted bundle is read back with Decode.
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/party"
+)
+
+var (
+	ErrInvalidBundle    = errors.New("backup: invalid bundle")
+	ErrInvalidRecipient = errors.New("backup: invalid recipient")
+)
+
+// bundleVersion is the version of the encoding of Bundle.
+const bundleVersion = 1
+
+// Bundle is the backup of the share of a party.
+type Bundle struct {
+	// KeyID is the ID of the key the share belongs to.
+	KeyID string
+	// Party is the party holding the share.
+	Party party.ID
+	// Created is the time of the export.
+	Created time.Time
+	// Config is the marshalled CMP config of the secp256k1 share, including its secrets,
+	// if the key has one.
+	Config []byte
+	// FROST is the marshalled FROST share of the ed25519 key, if the key has one.
+	FROST []byte
+}
+
+type rawBundle struct {
+	Version int
+	KeyID   string
+	Party   party.ID
+	Created int64
+	Config  []byte
+	FROST   []byte
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (b *Bundle) MarshalBinary() ([]byte, error) {
+	return cbor.Marshal(&rawBundle{
+		Version: bundleVersion,
+		KeyID:   b.KeyID,
+		Party:   b.Party,
+		Created: b.Created.Unix(),
+		Config:  b.Config,
+		FROST:   b.FROST,
+	})
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (b *Bundle) UnmarshalBinary(data []byte) error {
+	var raw rawBundle
+	if err := cbor.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	if raw.Version != bundleVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidBundle, raw.Version)
+	}
+	if raw.KeyID == "" || raw.Party == "" || (len(raw.Config) == 0 && len(raw.FROST) == 0) {
+		return fmt.Errorf("%w: missing key, party or share", ErrInvalidBundle)
+	}
+	*b = Bundle{KeyID: raw.KeyID, Party: raw.Party, Created: time.Unix(raw.Created, 0).UTC(), Config: raw.Config, FROST: raw.FROST}
+	return nil
+}
+
+// Recipient encrypts bundles to the keys of one or more custodians, any of whom can decrypt them.
+type Recipient interface {
+	// Encrypt returns a writer encrypting what is written to it to w. The encryption is only complete once
+	// the writer is closed.
+	Encrypt(w io.Writer) (io.WriteCloser, error)
+	// String describes the keys, such as their OpenPGP fingerprints.
+	String() string
+}
+
+// Export encrypts b to r, and writes it to w.
+func Export(w io.Writer, b *Bundle, r Recipient) error {
+	data, err := b.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	enc, err := r.Encrypt(w)
+	if err != nil {
+		return err
+	}
+	if _, err := enc.Write(data); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// Decode returns the bundle of data, once decrypted by a custodian.
+func Decode(data []byte) (*Bundle, error) {
+	b := &Bundle{}
+	if err := b.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
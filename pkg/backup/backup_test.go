@@ -0,0 +1,177 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBundle(t *testing.T) *Bundle {
+	config := make([]byte, 96<<10)
+	_, err := rand.Read(config)
+	require.NoError(t, err)
+	return &Bundle{KeyID: "treasury", Party: "a", Created: time.Unix(1700000000, 0).UTC(), Config: config}
+}
+
+func TestOpenPGPExport(t *testing.T) {
+	custodian, err := openpgp.NewEntity("custodian", "", "custodian@example.com", nil)
+	require.NoError(t, err)
+	var keyring bytes.Buffer
+	require.NoError(t, custodian.Serialize(&keyring))
+
+	r, err := NewOpenPGPRecipient(keyring.Bytes())
+	require.NoError(t, err)
+	assert.Len(t, r.String(), 40)
+	b := testBundle(t)
+	var out bytes.Buffer
+	require.NoError(t, Export(&out, b, r))
+
+	md, err := openpgp.ReadMessage(&out, openpgp.EntityList{custodian}, nil, nil)
+	require.NoError(t, err)
+	var plaintext bytes.Buffer
+	_, err = plaintext.ReadFrom(md.UnverifiedBody)
+	require.NoError(t, err)
+	decoded, err := Decode(plaintext.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, b, decoded)
+
+	_, err = NewOpenPGPRecipient([]byte("not a key"))
+	assert.ErrorIs(t, err, ErrInvalidRecipient)
+}
+
+// The keys of the custodian in testdata were generated with gpg 2.2:
+//
+//	gpg --quick-gen-key 'custodian <custodian@example.com>' rsa3072 encr,sign never
+//	gpg --armor --export custodian@example.com > custodian.asc
+//	gpg --armor --export-secret-keys custodian@example.com > custodian-secret.asc
+//
+// and bundle.gpg encrypts the marshalled gpgBundle to them, with `gpg --encrypt -r custodian@example.com`.
+const custodianFingerprint = "30A1452EEE55F622E16482BD7874A4D5E2FEF1EE"
+
+var gpgBundle = &Bundle{KeyID: "treasury", Party: "a", Created: time.Unix(1700000000, 0).UTC(), Config: []byte("config")}
+
+func readCustodian(t *testing.T) (public []byte, secret openpgp.EntityList) {
+	public, err := os.ReadFile("testdata/custodian.asc")
+	require.NoError(t, err)
+	data, err := os.ReadFile("testdata/custodian-secret.asc")
+	require.NoError(t, err)
+	secret, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	require.NoError(t, err)
+	return public, secret
+}
+
+func TestOpenPGPInterop(t *testing.T) {
+	public, secret := readCustodian(t)
+
+	// a message encrypted by gpg is read back with the secret key it exported
+	data, err := os.ReadFile("testdata/bundle.gpg")
+	require.NoError(t, err)
+	md, err := openpgp.ReadMessage(bytes.NewReader(data), secret, nil, nil)
+	require.NoError(t, err)
+	plaintext, err := io.ReadAll(md.UnverifiedBody)
+	require.NoError(t, err)
+	decoded, err := Decode(plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, gpgBundle, decoded)
+
+	// a bundle is encrypted to the key exported by gpg, and decrypted by gpg if it is installed
+	r, err := NewOpenPGPRecipient(public)
+	require.NoError(t, err)
+	assert.Equal(t, custodianFingerprint, r.String())
+	b := testBundle(t)
+	var out bytes.Buffer
+	require.NoError(t, Export(&out, b, r))
+
+	gpg, err := exec.LookPath("gpg")
+	if err != nil {
+		t.Skip("gpg is not installed")
+	}
+	home := t.TempDir()
+	t.Cleanup(func() {
+		// gpg starts an agent for the home directory, which outlives it otherwise
+		_ = exec.Command("gpgconf", "--homedir", home, "--kill", "gpg-agent").Run()
+	})
+	gpgCommand := func(stdin []byte, args ...string) []byte {
+		cmd := exec.Command(gpg, append([]string{"--homedir", home, "--batch", "--quiet"}, args...)...)
+		cmd.Stdin = bytes.NewReader(stdin)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		stdout, err := cmd.Output()
+		require.NoError(t, err, stderr.String())
+		return stdout
+	}
+	data, err = os.ReadFile("testdata/custodian-secret.asc")
+	require.NoError(t, err)
+	gpgCommand(data, "--import")
+	decoded, err = Decode(gpgCommand(out.Bytes(), "--decrypt"))
+	require.NoError(t, err)
+	assert.Equal(t, b, decoded)
+}
+
+func TestAgeExport(t *testing.T) {
+	custodians := make([]*age.X25519Identity, 2)
+	keys := make([]string, 0, len(custodians))
+	for i := range custodians {
+		custodian, err := age.GenerateX25519Identity()
+		require.NoError(t, err)
+		custodians[i] = custodian
+		keys = append(keys, custodian.Recipient().String())
+	}
+
+	r, err := NewAgeRecipient(keys...)
+	require.NoError(t, err)
+	assert.Equal(t, keys[0]+", "+keys[1], r.String())
+	b := testBundle(t)
+	var out bytes.Buffer
+	require.NoError(t, Export(&out, b, r))
+
+	// any of the custodians decrypts the bundle
+	for _, custodian := range custodians {
+		plaintext, err := age.Decrypt(bytes.NewReader(out.Bytes()), custodian)
+		require.NoError(t, err)
+		data, err := io.ReadAll(plaintext)
+		require.NoError(t, err)
+		decoded, err := Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, b, decoded)
+	}
+	other, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	_, err = age.Decrypt(bytes.NewReader(out.Bytes()), other)
+	assert.Error(t, err)
+
+	_, err = NewAgeRecipient("not a key")
+	assert.ErrorIs(t, err, ErrInvalidRecipient)
+	_, err = NewAgeRecipient()
+	assert.ErrorIs(t, err, ErrInvalidRecipient)
+}
+
+func TestDecode(t *testing.T) {
+	data, err := testBundle(t).MarshalBinary()
+	require.NoError(t, err)
+	_, err = Decode(data[:len(data)/2])
+	assert.ErrorIs(t, err, ErrInvalidBundle)
+	_, err = Decode((&Bundle{KeyID: "treasury", Party: "a"}).mustMarshal(t))
+	assert.ErrorIs(t, err, ErrInvalidBundle)
+
+	// a key without a secp256k1 share only holds its ed25519 share
+	b := &Bundle{KeyID: "treasury", Party: "a", Created: time.Unix(1700000000, 0).UTC(), FROST: []byte("share")}
+	decoded, err := Decode(b.mustMarshal(t))
+	require.NoError(t, err)
+	assert.Equal(t, b, decoded)
+}
+
+func (b *Bundle) mustMarshal(t *testing.T) []byte {
+	data, err := b.MarshalBinary()
+	require.NoError(t, err)
+	return data
+}
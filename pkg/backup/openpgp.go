@@ -0,0 +1,60 @@
+package backup
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+// OpenPGPRecipient encrypts bundles to OpenPGP public keys, as `gpg --encrypt -r` does.
+// The bundles are binary OpenPGP messages, neither signed nor compressed.
+// They are produced by github.com/ProtonMail/go-crypto/openpgp, the maintained fork of the frozen
+// golang.org/x/crypto/openpgp. Its interoperability with gpg is checked against the keys and messages
+// of gpg in testdata.
+type OpenPGPRecipient struct {
+	entities openpgp.EntityList
+}
+
+// NewOpenPGPRecipient returns a Recipient for the public keys of the keyrings, which may be ASCII armored,
+// such as exported by `gpg --export --armor`.
+func NewOpenPGPRecipient(keyrings ...[]byte) (*OpenPGPRecipient, error) {
+	r := &OpenPGPRecipient{}
+	for _, keyring := range keyrings {
+		read := openpgp.ReadKeyRing
+		if bytes.HasPrefix(bytes.TrimSpace(keyring), []byte("-----BEGIN")) {
+			read = openpgp.ReadArmoredKeyRing
+		}
+		entities, err := read(bytes.NewReader(keyring))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRecipient, err)
+		}
+		r.entities = append(r.entities, entities...)
+	}
+	if len(r.entities) == 0 {
+		return nil, fmt.Errorf("%w: no OpenPGP key", ErrInvalidRecipient)
+	}
+	return r, nil
+}
+
+// String returns the fingerprints of the keys.
+func (r *OpenPGPRecipient) String() string {
+	fingerprints := make([]string, 0, len(r.entities))
+	for _, e := range r.entities {
+		fingerprints = append(fingerprints, fmt.Sprintf("%X", e.PrimaryKey.Fingerprint))
+	}
+	return strings.Join(fingerprints, ", ")
+}
+
+// Encrypt implements Recipient. It fails if a key has no valid encryption subkey.
+func (r *OpenPGPRecipient) Encrypt(w io.Writer) (io.WriteCloser, error) {
+	hints := &openpgp.FileHints{IsBinary: true}
+	enc, err := openpgp.Encrypt(w, r.entities, nil, hints, &packet.Config{DefaultCipher: packet.CipherAES256})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecipient, err)
+	}
+	return enc, nil
+}
@@ -0,0 +1,75 @@
+// Package beacon produces publicly verifiable randomness with the key shares of a committee.
+//
+// The randomness of a round is the output of the threshold VRF of package vrf on the round number,
+// under the key of the committee. Since a VRF output is unique for a given key and input, no party,
+// nor any quorum, can bias it: a quorum can only refuse to produce it. Any t+1 parties can produce it,
+// so that parties blamed during an evaluation are replaced by others, without changing the output.
+// Anyone holding the public key can verify a beacon.
+package beacon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	thresholdenc "github.com/mr-shifu/mpc-lib/pkg/threshold-encryption"
+	"github.com/mr-shifu/mpc-lib/pkg/vrf"
+)
+
+var ErrInvalidBeacon = errors.New("beacon: invalid beacon")
+
+// inputDomain separates the inputs of the beacon from the other uses of the VRF with the same key.
+const inputDomain = "mpc-lib beacon v1"
+
+// Beacon is the randomness of a round, with the VRF proof it was computed from.
+type Beacon struct {
+	Round uint64
+	Proof *vrf.Proof
+	// Randomness is the VRF output of the round, of vrf.OutputSize bytes.
+	Randomness []byte
+}
+
+// Input returns the VRF input of a round, to be passed to vrf.Commit by each party of the quorum.
+func Input(round uint64) []byte {
+	return binary.BigEndian.AppendUint64([]byte(inputDomain), round)
+}
+
+// Combine returns the beacon of round, from the commitments and responses of a quorum to Input(round).
+//
+// As with vrf.Combine, the returned culprits are the parties whose contribution was invalid,
+// and the round must then be evaluated again without them.
+func Combine(committee *thresholdenc.Committee, round uint64, commitments []*vrf.Commitment, responses []*vrf.Response) (*Beacon, []party.ID, error) {
+	input := Input(round)
+	proof, culprits, err := vrf.Combine(committee, input, commitments, responses)
+	if err != nil {
+		return nil, culprits, err
+	}
+	randomness, err := vrf.Verify(committee.PublicKey(), input, proof)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &Beacon{Round: round, Proof: proof, Randomness: randomness}, nil, nil
+}
+
+// Verify checks the proof of b against the public key of the committee, and that its randomness is the VRF output.
+func Verify(public curve.Point, b *Beacon) error {
+	if b == nil {
+		return ErrInvalidBeacon
+	}
+	randomness, err := vrf.Verify(public, Input(b.Round), b.Proof)
+	if err != nil {
+		return fmt.Errorf("%w: round %d: %v", ErrInvalidBeacon, b.Round, err)
+	}
+	if !bytes.Equal(randomness, b.Randomness) {
+		return fmt.Errorf("%w: round %d: randomness is not the VRF output", ErrInvalidBeacon, b.Round)
+	}
+	return nil
+}
+
+// Empty creates an empty Beacon with a fixed group, ready for unmarshalling.
+func Empty(group curve.Curve) *Beacon {
+	return &Beacon{Proof: vrf.EmptyProof(group)}
+}
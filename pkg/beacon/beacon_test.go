@@ -0,0 +1,73 @@
+package beacon
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/test"
+	thresholdenc "github.com/mr-shifu/mpc-lib/pkg/threshold-encryption"
+	"github.com/mr-shifu/mpc-lib/pkg/vrf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBeacon(t *testing.T) {
+	group := curve.Secp256k1{}
+	ids := test.PartyIDs(4)
+	threshold := 1
+
+	f := polynomial.NewPolynomial(group, threshold, sample.Scalar(rand.Reader, group))
+	secrets := make(map[party.ID]curve.Scalar, len(ids))
+	committee := &thresholdenc.Committee{Group: group, Threshold: threshold, Shares: map[party.ID]curve.Point{}}
+	for _, id := range ids {
+		secrets[id] = f.Evaluate(id.Scalar(group))
+		committee.Shares[id] = secrets[id].ActOnBase()
+	}
+	public := committee.PublicKey()
+
+	produce := func(round uint64, quorum []party.ID) *Beacon {
+		nonces := make(map[party.ID]*vrf.Nonces, len(quorum))
+		commitments := make([]*vrf.Commitment, 0, len(quorum))
+		for _, id := range quorum {
+			n, c, err := vrf.Commit(id, secrets[id], Input(round))
+			require.NoError(t, err)
+			nonces[id] = n
+			commitments = append(commitments, c)
+		}
+		responses := make([]*vrf.Response, 0, len(quorum))
+		for _, id := range quorum {
+			r, err := vrf.Respond(nonces[id], secrets[id], committee, commitments)
+			require.NoError(t, err)
+			responses = append(responses, r)
+		}
+		b, culprits, err := Combine(committee, round, commitments, responses)
+		require.NoError(t, err)
+		assert.Empty(t, culprits)
+
+		data, err := cbor.Marshal(b)
+		require.NoError(t, err)
+		b = Empty(group)
+		require.NoError(t, cbor.Unmarshal(data, b))
+		require.NoError(t, Verify(public, b))
+		return b
+	}
+
+	// the randomness of a round does not depend on the quorum producing it
+	b := produce(7, ids[:2])
+	assert.Len(t, b.Randomness, vrf.OutputSize)
+	assert.Equal(t, b.Randomness, produce(7, ids[2:]).Randomness)
+	assert.NotEqual(t, b.Randomness, produce(8, ids[:2]).Randomness)
+
+	// a beacon cannot be claimed for another round, nor with other randomness
+	moved := *b
+	moved.Round = 8
+	assert.ErrorIs(t, Verify(public, &moved), ErrInvalidBeacon)
+	forged := *b
+	forged.Randomness = make([]byte, vrf.OutputSize)
+	assert.ErrorIs(t, Verify(public, &forged), ErrInvalidBeacon)
+}
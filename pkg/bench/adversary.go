@@ -0,0 +1,221 @@
+package bench
+
+import (
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+)
+
+// Adversary controls the messages of a corrupted party, which otherwise runs the protocol honestly.
+// It allows checking end to end that the honest parties abort and blame the right party.
+//
+// Send and Receive are called from the goroutine running the party, and never concurrently.
+type Adversary interface {
+	// Send is called with each message of the corrupted party, and returns the messages to send in its place.
+	Send(msg *protocol.Message) []*protocol.Message
+	// Receive is called with each message delivered to the corrupted party before its handler,
+	// and returns messages to send, such as messages previously held back by Send.
+	Receive(msg *protocol.Message) []*protocol.Message
+}
+
+// isRoundMessage returns true if msg belongs to a round of the protocol,
+// rather than being an abort, heartbeat or other message handled by the framework.
+func isRoundMessage(msg *protocol.Message) bool {
+	return msg.RoundNumber != 0 && msg.RoundNumber < protocol.HandshakeRoundNumber
+}
+
+// inRounds returns true if number is one of rounds, or if rounds is empty.
+func inRounds(number round.Number, rounds []round.Number) bool {
+	if len(rounds) == 0 {
+		return true
+	}
+	for _, r := range rounds {
+		if r == number {
+			return true
+		}
+	}
+	return false
+}
+
+// Equivocate returns an Adversary sending a tampered broadcast message to half of the other parties
+// and the genuine one to the others, in the given rounds or all of them if none is given.
+// Honest parties detect it when verifying the echo of the broadcasts.
+func Equivocate(parties []party.ID, rounds ...round.Number) Adversary {
+	return &equivocate{parties: party.NewIDSlice(parties), rounds: rounds}
+}
+
+type equivocate struct {
+	parties party.IDSlice
+	rounds  []round.Number
+}
+
+func (a *equivocate) Send(msg *protocol.Message) []*protocol.Message {
+	if !msg.Broadcast || !isRoundMessage(msg) || !inRounds(msg.RoundNumber, a.rounds) {
+		return []*protocol.Message{msg}
+	}
+	var msgs []*protocol.Message
+	for i, id := range a.parties.Remove(msg.From) {
+		copied := *msg
+		copied.To = id
+		if i%2 == 1 {
+			copied.Data = tamper(msg.Data)
+		}
+		msgs = append(msgs, &copied)
+	}
+	return msgs
+}
+
+func (a *equivocate) Receive(*protocol.Message) []*protocol.Message { return nil }
+
+// ForgeProofs returns an Adversary tampering with the content of its messages in the given rounds,
+// or all of them if none is given, so that the proofs or values they carry are invalid.
+// The content is kept well formed, so that the honest parties fail to verify it rather than to decode it.
+func ForgeProofs(rounds ...round.Number) Adversary {
+	return &forgeProofs{rounds: rounds}
+}
+
+type forgeProofs struct {
+	rounds []round.Number
+}
+
+func (a *forgeProofs) Send(msg *protocol.Message) []*protocol.Message {
+	if !isRoundMessage(msg) || !inRounds(msg.RoundNumber, a.rounds) {
+		return []*protocol.Message{msg}
+	}
+	forged := *msg
+	forged.Data = tamper(msg.Data)
+	return []*protocol.Message{&forged}
+}
+
+func (a *forgeProofs) Receive(*protocol.Message) []*protocol.Message { return nil }
+
+// tamper flips a bit of the longest byte string in the CBOR encoded data, which is usually
+// the encoding of a group element or of a proof, and returns the re-encoded data.
+// If data holds no byte string, its last byte is flipped instead.
+func tamper(data []byte) []byte {
+	var content interface{}
+	if err := cbor.Unmarshal(data, &content); err == nil {
+		if longest := longestBytes(content); longest != nil {
+			longest[len(longest)-1] ^= 1
+			if tampered, err := cbor.Marshal(content); err == nil {
+				return tampered
+			}
+		}
+	}
+	tampered := append([]byte(nil), data...)
+	if len(tampered) > 0 {
+		tampered[len(tampered)-1] ^= 1
+	}
+	return tampered
+}
+
+func longestBytes(v interface{}) []byte {
+	var longest []byte
+	switch v := v.(type) {
+	case []byte:
+		if len(v) > 0 {
+			longest = v
+		}
+	case []interface{}:
+		for _, e := range v {
+			if b := longestBytes(e); len(b) > len(longest) {
+				longest = b
+			}
+		}
+	case map[interface{}]interface{}:
+		for _, e := range v {
+			if b := longestBytes(e); len(b) > len(longest) {
+				longest = b
+			}
+		}
+	}
+	return longest
+}
+
+// Drop returns an Adversary which does not send its messages to the parties in to,
+// in the given rounds or all of them if none is given. Its broadcast messages are still sent
+// to the other parties, so that only some honest parties stall waiting for them.
+func Drop(parties, to []party.ID, rounds ...round.Number) Adversary {
+	return &drop{parties: party.NewIDSlice(parties), to: party.NewIDSlice(to), rounds: rounds}
+}
+
+type drop struct {
+	parties party.IDSlice
+	to      party.IDSlice
+	rounds  []round.Number
+}
+
+func (a *drop) Send(msg *protocol.Message) []*protocol.Message {
+	if !isRoundMessage(msg) || !inRounds(msg.RoundNumber, a.rounds) {
+		return []*protocol.Message{msg}
+	}
+	if msg.To != "" {
+		if a.to.Contains(msg.To) {
+			return nil
+		}
+		return []*protocol.Message{msg}
+	}
+	var msgs []*protocol.Message
+	for _, id := range a.parties.Remove(msg.From) {
+		if a.to.Contains(id) {
+			continue
+		}
+		copied := *msg
+		copied.To = id
+		msgs = append(msgs, &copied)
+	}
+	return msgs
+}
+
+func (a *drop) Receive(*protocol.Message) []*protocol.Message { return nil }
+
+// Rushing returns an Adversary which holds back its messages of each round until it received
+// the messages of that round from all the other parties, so that it may choose its own after seeing theirs.
+func Rushing(parties []party.ID) Adversary {
+	return &rushing{
+		parties:  party.NewIDSlice(parties),
+		received: map[round.Number]map[party.ID]bool{},
+		held:     map[round.Number][]*protocol.Message{},
+	}
+}
+
+type rushing struct {
+	parties  party.IDSlice
+	received map[round.Number]map[party.ID]bool
+	held     map[round.Number][]*protocol.Message
+}
+
+func (a *rushing) Send(msg *protocol.Message) []*protocol.Message {
+	if !isRoundMessage(msg) || a.receivedAll(msg.RoundNumber, msg.From) {
+		return []*protocol.Message{msg}
+	}
+	a.held[msg.RoundNumber] = append(a.held[msg.RoundNumber], msg)
+	return nil
+}
+
+func (a *rushing) Receive(msg *protocol.Message) []*protocol.Message {
+	if !isRoundMessage(msg) {
+		return nil
+	}
+	if a.received[msg.RoundNumber] == nil {
+		a.received[msg.RoundNumber] = map[party.ID]bool{}
+	}
+	a.received[msg.RoundNumber][msg.From] = true
+	held := a.held[msg.RoundNumber]
+	if len(held) == 0 || !a.receivedAll(msg.RoundNumber, held[0].From) {
+		return nil
+	}
+	delete(a.held, msg.RoundNumber)
+	return held
+}
+
+// receivedAll returns true if a message of the round was received from every party other than self.
+func (a *rushing) receivedAll(number round.Number, self party.ID) bool {
+	for _, id := range a.parties.Remove(self) {
+		if !a.received[number][id] {
+			return false
+		}
+	}
+	return true
+}
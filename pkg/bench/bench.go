@@ -0,0 +1,211 @@
+// Package bench runs protocols end to end between simulated parties, over a network with configurable
+// latency and bandwidth, and reports the time spent by each party in each round.
+//
+// This allows planning the capacity of committees spread across regions without deploying them.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+)
+
+// RoundStats are the measurements of a party for a single round.
+type RoundStats struct {
+	Round round.Number
+	// Wall is the time from entering the round until sending the messages of the next one,
+	// which includes waiting for the messages of the other parties.
+	Wall time.Duration
+	// Compute is the time spent by the party processing messages and finalizing the round.
+	Compute time.Duration
+	// Messages and Bytes count the messages sent at the end of the round, and the size of their content.
+	Messages int
+	Bytes    int
+}
+
+// Report holds the results and measurements of a run.
+type Report struct {
+	// Wall is the time until all parties finished.
+	Wall time.Duration
+	// Rounds are the measurements of each party, by round.
+	Rounds map[party.ID][]RoundStats
+	// Results are the results of each party.
+	Results map[party.ID]interface{}
+	// Errors are the errors of the parties which aborted.
+	Errors map[party.ID]error
+}
+
+// String formats the report as a table with one line per party and round.
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "total %v\n", r.Wall)
+	fmt.Fprintf(&b, "%-12s %5s %14s %14s %8s %10s\n", "party", "round", "wall", "compute", "messages", "bytes")
+	for _, id := range party.NewIDSlice(keys(r.Rounds)) {
+		for _, s := range r.Rounds[id] {
+			fmt.Fprintf(&b, "%-12s %5d %14v %14v %8d %10d\n", id, s.Round, s.Wall, s.Compute, s.Messages, s.Bytes)
+		}
+	}
+	return b.String()
+}
+
+// Run executes a protocol between the parties of start over the simulated network, and waits until all of them finished.
+// An error is returned if the context is done first, or if a party aborted.
+func Run(ctx context.Context, start map[party.ID]protocol.StartFunc, sessionID []byte, topology Topology) (*Report, error) {
+	return RunWithAdversaries(ctx, start, sessionID, topology, nil)
+}
+
+// RunWithAdversaries is like Run, but the messages of the parties in adversaries are controlled by their Adversary.
+// The report still holds the results and errors of all parties, so that the behavior of the honest ones can be checked.
+func RunWithAdversaries(ctx context.Context, start map[party.ID]protocol.StartFunc, sessionID []byte, topology Topology, adversaries map[party.ID]Adversary) (*Report, error) {
+	net := newNetwork(topology)
+	runs := make(map[party.ID]*run, len(start))
+	for id := range start {
+		r := &run{
+			id:       id,
+			net:      net,
+			adv:      adversaries[id],
+			in:       make(chan *protocol.Message, 16),
+			finished: make(chan struct{}),
+		}
+		runs[id] = r
+		net.parties[id] = r
+	}
+
+	begin := time.Now()
+	var wg sync.WaitGroup
+	errs := make(chan error, len(runs))
+	for id, r := range runs {
+		wg.Add(1)
+		go func(r *run, start protocol.StartFunc) {
+			defer wg.Done()
+			if err := r.run(ctx, start, sessionID); err != nil {
+				errs <- fmt.Errorf("bench: party %s: %w", r.id, err)
+			}
+		}(r, start[id])
+	}
+	wg.Wait()
+	close(errs)
+
+	report := &Report{
+		Wall:    time.Since(begin),
+		Rounds:  make(map[party.ID][]RoundStats, len(runs)),
+		Results: make(map[party.ID]interface{}, len(runs)),
+		Errors:  make(map[party.ID]error),
+	}
+	for id, r := range runs {
+		report.Rounds[id] = r.stats
+		report.Results[id] = r.result
+		if r.err != nil {
+			report.Errors[id] = r.err
+		}
+	}
+	return report, <-errs
+}
+
+// run is the execution of the protocol by a single party.
+type run struct {
+	id  party.ID
+	net *network
+	// adv controls the messages of the party if it is corrupted.
+	adv Adversary
+	in  chan *protocol.Message
+	// finished is closed once the party is done, so that late deliveries are dropped.
+	finished chan struct{}
+
+	stats   []RoundStats
+	entered time.Time
+	result  interface{}
+	err     error
+}
+
+func (r *run) deliver(msg *protocol.Message) {
+	select {
+	case r.in <- msg:
+	case <-r.finished:
+	}
+}
+
+func (r *run) run(ctx context.Context, start protocol.StartFunc, sessionID []byte) error {
+	defer close(r.finished)
+
+	r.entered = time.Now()
+	r.stats = []RoundStats{{Round: 1}}
+	h, err := protocol.NewMultiHandler(start, sessionID)
+	if err != nil {
+		return err
+	}
+	r.current().Compute += time.Since(r.entered)
+
+	out := h.Listen()
+	for {
+		select {
+		case msg, ok := <-out:
+			if !ok {
+				r.current().Wall = time.Since(r.entered)
+				r.result, r.err = h.Result()
+				return r.err
+			}
+			r.sent(msg)
+			if r.adv != nil {
+				r.send(r.adv.Send(msg))
+			} else {
+				r.net.send(msg)
+			}
+		case msg := <-r.in:
+			if r.adv != nil {
+				r.send(r.adv.Receive(msg))
+			}
+			begin := time.Now()
+			h.Accept(msg)
+			r.current().Compute += time.Since(begin)
+		case <-ctx.Done():
+			h.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+func (r *run) send(msgs []*protocol.Message) {
+	for _, msg := range msgs {
+		r.net.send(msg)
+	}
+}
+
+func (r *run) current() *RoundStats {
+	return &r.stats[len(r.stats)-1]
+}
+
+// sent moves on to the round of msg, since it is sent once the current round is finalized,
+// and counts it in the round which produced it.
+func (r *run) sent(msg *protocol.Message) {
+	if msg.RoundNumber == 0 || msg.RoundNumber >= protocol.HeartbeatRoundNumber {
+		return
+	}
+	if msg.RoundNumber > r.current().Round {
+		now := time.Now()
+		r.current().Wall = now.Sub(r.entered)
+		r.entered = now
+		r.stats = append(r.stats, RoundStats{Round: msg.RoundNumber})
+	}
+	for i := len(r.stats) - 1; i >= 0; i-- {
+		if r.stats[i].Round < msg.RoundNumber {
+			r.stats[i].Messages++
+			r.stats[i].Bytes += len(msg.Data)
+			return
+		}
+	}
+}
+
+func keys(m map[party.ID][]RoundStats) []party.ID {
+	ids := make([]party.ID, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -0,0 +1,89 @@
+package bench
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/lib/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunFROST(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	ids := test.PartyIDs(3)
+	c := NewCommittee(ids, pl)
+	latency := 20 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	report, err := Run(ctx, c.FROSTKeygen("key", 1), []byte("keygen"), Uniform(Link{Latency: latency}))
+	require.NoError(t, err)
+	require.Len(t, report.Results, len(ids))
+	for _, id := range ids {
+		rounds := report.Rounds[id]
+		require.NotEmpty(t, rounds)
+		assert.Positive(t, rounds[0].Messages)
+		// the first round only computes, every later one waits for the messages of the previous one
+		for _, s := range rounds[1:] {
+			assert.GreaterOrEqual(t, s.Wall, latency)
+		}
+	}
+	assert.GreaterOrEqual(t, report.Wall, latency)
+
+	report, err = Run(ctx, c.FROSTSign("sign", "key", 1, ids, []byte("hello")), []byte("sign"), Uniform(Link{Latency: latency}))
+	require.NoError(t, err)
+	require.Len(t, report.Results, len(ids))
+	for _, r := range report.Results {
+		assert.NotNil(t, r)
+	}
+}
+
+func TestRunAdversaries(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	ids := test.PartyIDs(3)
+	c := NewCommittee(ids, pl)
+	corrupted := ids[0]
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	// a rushing adversary only delays its messages, so the protocol completes
+	report, err := RunWithAdversaries(ctx, c.FROSTKeygen("rushing", 1), []byte("rushing"), Uniform(Link{}),
+		map[party.ID]Adversary{corrupted: Rushing(ids)})
+	require.NoError(t, err)
+	for _, id := range ids {
+		assert.NotNil(t, report.Results[id])
+	}
+
+	report, err = RunWithAdversaries(ctx, c.FROSTKeygen("forged", 1), []byte("forged"), Uniform(Link{}),
+		map[party.ID]Adversary{corrupted: ForgeProofs()})
+	require.Error(t, err)
+	// the honest parties abort, and those which did not follow the abort of another one blame the adversary
+	blamed := false
+	for _, id := range ids.Remove(corrupted) {
+		var protocolErr protocol.Error
+		require.ErrorAs(t, report.Errors[id], &protocolErr, id)
+		if party.NewIDSlice(protocolErr.Culprits).Contains(corrupted) {
+			blamed = true
+		}
+	}
+	assert.True(t, blamed)
+
+	report, err = RunWithAdversaries(ctx, c.FROSTKeygen("equivocated", 1), []byte("equivocated"), Uniform(Link{}),
+		map[party.ID]Adversary{corrupted: Equivocate(ids)})
+	require.Error(t, err)
+	for _, id := range ids.Remove(corrupted) {
+		assert.Error(t, report.Errors[id], id)
+		assert.Nil(t, report.Results[id], id)
+	}
+}
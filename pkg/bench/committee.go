@@ -0,0 +1,100 @@
+package bench
+
+import (
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/keystore"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/config"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/message"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/state"
+	"github.com/mr-shifu/mpc-lib/pkg/vault"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp"
+	"github.com/mr-shifu/mpc-lib/protocols/frost"
+)
+
+// Committee holds the in-memory storage of each party, so that a key generated by one run can be used by the next ones.
+type Committee struct {
+	parties party.IDSlice
+	pl      *pool.Pool
+	cmp     map[party.ID]*cmp.MPC
+	frost   map[party.ID]*frost.FROST
+}
+
+// NewCommittee returns a Committee between the parties, which all share the pool pl.
+func NewCommittee(parties []party.ID, pl *pool.Pool) *Committee {
+	c := &Committee{
+		parties: party.NewIDSlice(parties),
+		pl:      pl,
+		cmp:     make(map[party.ID]*cmp.MPC, len(parties)),
+		frost:   make(map[party.ID]*frost.FROST, len(parties)),
+	}
+	for _, id := range c.parties {
+		c.cmp[id] = cmp.NewMPC(
+			&keystore.InmemoryKeystoreFactory{},
+			&keyopts.InMemoryKeyOptsFactory{},
+			&vault.InmemoryVaultFactory{},
+			&vault.InmemoryVaultFactory{},
+			config.NewInMemoryConfigStore(),
+			config.NewInMemoryConfigStore(),
+			state.NewInMemoryStateStore(),
+			state.NewInMemoryStateStore(),
+			message.NewInMemoryMessageStore(),
+			message.NewInMemoryMessageStore(),
+			pl,
+		)
+		c.frost[id] = frost.NewFROST(
+			&keystore.InmemoryKeystoreFactory{},
+			&keyopts.InMemoryKeyOptsFactory{},
+			&vault.InmemoryVaultFactory{},
+			config.NewInMemoryConfigStore(),
+			config.NewInMemoryConfigStore(),
+			state.NewInMemoryStateStore(),
+			state.NewInMemoryStateStore(),
+			message.NewInMemoryMessageStore(),
+			message.NewInMemoryMessageStore(),
+			pl,
+		)
+	}
+	return c
+}
+
+// CMPKeygen returns the start of a CMP keygen for each party.
+func (c *Committee) CMPKeygen(keyID string, threshold int) map[party.ID]protocol.StartFunc {
+	start := make(map[party.ID]protocol.StartFunc, len(c.parties))
+	for _, id := range c.parties {
+		start[id] = c.cmp[id].Keygen(config.NewKeyConfig(keyID, curve.Secp256k1{}, threshold, id, c.parties), c.pl)
+	}
+	return start
+}
+
+// CMPSign returns the start of a CMP sign of msg for each signer, with a key generated by CMPKeygen.
+func (c *Committee) CMPSign(signID, keyID string, signers []party.ID, msg []byte) map[party.ID]protocol.StartFunc {
+	start := make(map[party.ID]protocol.StartFunc, len(signers))
+	for _, id := range signers {
+		cfg := config.NewSignConfig(signID, keyID, curve.Secp256k1{}, len(signers)-1, id, signers, msg)
+		start[id] = c.cmp[id].Sign(cfg, c.pl)
+	}
+	return start
+}
+
+// FROSTKeygen returns the start of a FROST keygen for each party.
+func (c *Committee) FROSTKeygen(keyID string, threshold int) map[party.ID]protocol.StartFunc {
+	start := make(map[party.ID]protocol.StartFunc, len(c.parties))
+	for _, id := range c.parties {
+		start[id] = c.frost[id].Keygen(config.NewKeyConfig(keyID, curve.Secp256k1{}, threshold, id, c.parties), c.pl)
+	}
+	return start
+}
+
+// FROSTSign returns the start of a FROST sign of msg for each signer, with a key generated by FROSTKeygen.
+func (c *Committee) FROSTSign(signID, keyID string, threshold int, signers []party.ID, msg []byte) map[party.ID]protocol.StartFunc {
+	start := make(map[party.ID]protocol.StartFunc, len(signers))
+	for _, id := range signers {
+		cfg := config.NewSignConfig(signID, keyID, curve.Secp256k1{}, threshold, id, signers, msg)
+		start[id] = c.frost[id].Sign(cfg, c.pl)
+	}
+	return start
+}
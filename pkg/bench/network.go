@@ -0,0 +1,86 @@
+package bench
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+)
+
+// Link describes the simulated connection from one party to another.
+type Link struct {
+	// Latency is the one-way delay of a message.
+	Latency time.Duration
+	// Bandwidth is the upload rate of the sender in bytes per second, 0 meaning unlimited.
+	Bandwidth int
+}
+
+// transmission returns the time needed to upload size bytes over the link.
+func (l Link) transmission(size int) time.Duration {
+	if l.Bandwidth <= 0 {
+		return 0
+	}
+	return time.Duration(int64(size) * int64(time.Second) / int64(l.Bandwidth))
+}
+
+// Topology returns the link used for messages sent from one party to another.
+type Topology func(from, to party.ID) Link
+
+// Uniform returns a Topology using the same link between all parties.
+func Uniform(l Link) Topology {
+	return func(party.ID, party.ID) Link { return l }
+}
+
+// Regions returns a Topology where each party is located in a region, and links depend only on the regions.
+// Links between two parties of the same region use local.
+func Regions(regions map[party.ID]string, links map[[2]string]Link, local Link) Topology {
+	return func(from, to party.ID) Link {
+		a, b := regions[from], regions[to]
+		if a == b {
+			return local
+		}
+		if l, ok := links[[2]string{a, b}]; ok {
+			return l
+		}
+		return links[[2]string{b, a}]
+	}
+}
+
+// network delivers messages after the delay given by its topology.
+// The upload of each sender is sequential, so that messages sent in a burst queue behind each other.
+type network struct {
+	topology Topology
+	parties  map[party.ID]*run
+	// uploaded is the time at which the uplink of each party becomes free.
+	uploaded map[party.ID]time.Time
+	mtx      sync.Mutex
+}
+
+func newNetwork(topology Topology) *network {
+	return &network{
+		topology: topology,
+		parties:  map[party.ID]*run{},
+		uploaded: map[party.ID]time.Time{},
+	}
+}
+
+func (n *network) send(msg *protocol.Message) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	now := time.Now()
+	for id, p := range n.parties {
+		if id == msg.From || !msg.IsFor(id) {
+			continue
+		}
+		link := n.topology(msg.From, id)
+		start := n.uploaded[msg.From]
+		if start.Before(now) {
+			start = now
+		}
+		uploaded := start.Add(link.transmission(len(msg.Data)))
+		n.uploaded[msg.From] = uploaded
+		p := p
+		time.AfterFunc(uploaded.Add(link.Latency).Sub(now), func() { p.deliver(msg) })
+	}
+}
@@ -0,0 +1,116 @@
+package ecdsa
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/party"
+)
+
+// ErrInconsistentShare is returned when an imported share does not match the public data of its key.
+var ErrInconsistentShare = errors.New("ecdsa: share is inconsistent with the public data of the key")
+
+// ShareExpectation is the public data of a threshold key, which an imported share xᵢ is checked against.
+//
+// Fields left nil are not checked.
+type ShareExpectation struct {
+	// ID is the party holding the share, and its evaluation point.
+	ID party.ID
+	// Commitments are the Feldman commitments F(X) = f(X)⋅G to the sharing polynomial, with f(0) the secret key.
+	Commitments *polynomial.Exponent
+	// PublicShares[j] = xⱼ⋅G for all parties.
+	PublicShares map[party.ID]curve.Point
+	// PublicKey is the combined public key.
+	PublicKey curve.Point
+}
+
+// ConsistencyReport lists the checks made on an imported share, and the parties whose public data is inconsistent.
+type ConsistencyReport struct {
+	// PublicShare is false if xᵢ⋅G ≠ PublicShares[i].
+	PublicShare bool
+	// Commitment is false if xᵢ⋅G ≠ F(i).
+	Commitment bool
+	// MismatchedPublicShares are the parties j for which PublicShares[j] ≠ F(j).
+	MismatchedPublicShares []party.ID
+	// CommitmentsPublicKey is false if F(0) ≠ PublicKey.
+	CommitmentsPublicKey bool
+	// SharesPublicKey is false if the public shares do not interpolate to PublicKey.
+	SharesPublicKey bool
+}
+
+// Consistent returns true if all checks passed.
+func (r *ConsistencyReport) Consistent() bool {
+	return r.PublicShare && r.Commitment && len(r.MismatchedPublicShares) == 0 && r.CommitmentsPublicKey && r.SharesPublicKey
+}
+
+// Err returns an ErrInconsistentShare listing the failed checks, or nil.
+func (r *ConsistencyReport) Err() error {
+	if r.Consistent() {
+		return nil
+	}
+	var failed []string
+	if !r.PublicShare {
+		failed = append(failed, "share does not match its public share")
+	}
+	if !r.Commitment {
+		failed = append(failed, "share does not match the commitments")
+	}
+	if len(r.MismatchedPublicShares) > 0 {
+		failed = append(failed, fmt.Sprintf("public shares of %v do not match the commitments", r.MismatchedPublicShares))
+	}
+	if !r.CommitmentsPublicKey {
+		failed = append(failed, "commitments do not match the public key")
+	}
+	if !r.SharesPublicKey {
+		failed = append(failed, "public shares do not match the public key")
+	}
+	return fmt.Errorf("%w: %s", ErrInconsistentShare, strings.Join(failed, ", "))
+}
+
+// CheckShare verifies the public share X = xᵢ⋅G of party expected.ID against expected,
+// and returns the report of the checks.
+func CheckShare(X curve.Point, expected ShareExpectation) *ConsistencyReport {
+	group := X.Curve()
+	r := &ConsistencyReport{
+		PublicShare:          true,
+		Commitment:           true,
+		CommitmentsPublicKey: true,
+		SharesPublicKey:      true,
+	}
+	if expected.PublicShares != nil {
+		public, ok := expected.PublicShares[expected.ID]
+		r.PublicShare = ok && public.Equal(X)
+	}
+	if F := expected.Commitments; F != nil {
+		r.Commitment = F.Evaluate(expected.ID.Scalar(group)).Equal(X)
+		for _, j := range party.NewIDSlice(keys(expected.PublicShares)) {
+			if !F.Evaluate(j.Scalar(group)).Equal(expected.PublicShares[j]) {
+				r.MismatchedPublicShares = append(r.MismatchedPublicShares, j)
+			}
+		}
+		if expected.PublicKey != nil {
+			r.CommitmentsPublicKey = F.Constant().Equal(expected.PublicKey)
+		}
+	}
+	if expected.PublicShares != nil && expected.PublicKey != nil {
+		ids := keys(expected.PublicShares)
+		lagrange := polynomial.Lagrange(group, ids)
+		sum := group.NewPoint()
+		for _, j := range ids {
+			sum = sum.Add(lagrange[j].Act(expected.PublicShares[j]))
+		}
+		r.SharesPublicKey = sum.Equal(expected.PublicKey)
+	}
+	return r
+}
+
+func keys(m map[party.ID]curve.Point) []party.ID {
+	ids := make([]party.ID, 0, len(m))
+	for j := range m {
+		ids = append(ids, j)
+	}
+	return ids
+}
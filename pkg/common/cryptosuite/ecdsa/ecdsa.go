@@ -2,11 +2,13 @@ package ecdsa
 
 import (
 	"github.com/cronokirby/saferith"
+	core_ecdsa "github.com/mr-shifu/mpc-lib/core/ecdsa"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	paillier_core "github.com/mr-shifu/mpc-lib/core/paillier"
 	zkaffg "github.com/mr-shifu/mpc-lib/core/zk/affg"
 	zkenc "github.com/mr-shifu/mpc-lib/core/zk/enc"
 	zklogstar "github.com/mr-shifu/mpc-lib/core/zk/logstar"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/paillier"
 	comm_pek "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/paillierencodedkey"
@@ -42,12 +44,18 @@ type ECDSAKey interface {
 
 	CloneByMultiplier(c curve.Scalar) ECDSAKey
 
+	// CloneByAdder returns a copy of the key with c added to the private key, and c•G to the public key.
+	CloneByAdder(c curve.Scalar) ECDSAKey
+
 	CloneByKeyMultiplier(km ECDSAKey, c curve.Scalar) ECDSAKey
 
 	Commit(m curve.Scalar, c curve.Scalar) curve.Scalar
 
 	CommitByKey(km ECDSAKey, c curve.Scalar) curve.Scalar
 
+	// Sign returns a signature of hash by the whole key, with a deterministic nonce as specified by RFC 6979.
+	Sign(hash []byte) (*core_ecdsa.Signature, error)
+
 	NewSchnorrCommitment() (curve.Point, error)
 
 	ImportSchnorrCommitment(commitment curve.Point) error
@@ -68,7 +76,7 @@ type ECDSAKey interface {
 
 	EncodeByPaillier(pk paillier.PaillierKey) (comm_pek.PaillierEncodedKey, error)
 
-	NewZKEncProof(h hash.Hash, pek comm_pek.PaillierEncodedKey, pk paillier.PaillierKey, ped pedersen.PedersenKey) (*zkenc.Proof, error)
+	NewZKEncProof(h hash.Hash, pek comm_pek.PaillierEncodedKey, pk paillier.PaillierKey, ped pedersen.PedersenKey, profile params.Profile) (*zkenc.Proof, error)
 
 	NewZKLogstarProof(
 		h hash.Hash,
@@ -77,7 +85,8 @@ type ECDSAKey interface {
 		X curve.Point,
 		G curve.Point,
 		prover paillier.PaillierKey,
-		ped pedersen.PedersenKey) (*zklogstar.Proof, error)
+		ped pedersen.PedersenKey,
+		profile params.Profile) (*zklogstar.Proof, error)
 
 	NewMtAAffgProof(
 		h hash.Hash,
@@ -85,6 +94,7 @@ type ECDSAKey interface {
 		selfPaillier paillier.PaillierKey,
 		partyPaillier paillier.PaillierKey,
 		ped pedersen.PedersenKey,
+		profile params.Profile,
 	) (*saferith.Int, *paillier_core.Ciphertext, *paillier_core.Ciphertext, *zkaffg.Proof)
 }
 
@@ -97,6 +107,10 @@ type ECDSAKeyManager interface {
 	// Import imports a ECDSA key from its byte representation.
 	ImportKey(raw interface{}, opts keyopts.Options) (ECDSAKey, error)
 
+	// ImportVerifiedKey imports a ECDSA share after checking it against the public data of its key.
+	// The report is returned even if the share is inconsistent, in which case it is not imported.
+	ImportVerifiedKey(raw interface{}, expected ShareExpectation, opts keyopts.Options) (ECDSAKey, *ConsistencyReport, error)
+
 	// GetKey returns a ECDSA key by its SKI.
 	GetKey(opts keyopts.Options) (ECDSAKey, error)
 }
@@ -2,6 +2,8 @@ package elgamal
 
 import (
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	zksch "github.com/mr-shifu/mpc-lib/core/zk/sch"
+	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
 )
 
@@ -20,8 +22,17 @@ type ElgamalKey interface {
 
 	PublicKeyRaw() curve.Point
 
+	// SecretKeyRaw returns the secret key, or nil if the key is public.
+	SecretKeyRaw() curve.Scalar
+
 	// Encrypt returns the encryption of `message` as ciphertext and nonce.
 	Encrypt(message curve.Scalar) ([]byte, curve.Scalar, error)
+
+	// NewSchnorrProof returns a proof of possession of the secret key.
+	NewSchnorrProof(hash hash.Hash) (*zksch.Proof, error)
+
+	// VerifySchnorrProof verifies a proof of possession of the secret key.
+	VerifySchnorrProof(hash hash.Hash, proof *zksch.Proof) bool
 }
 
 type ElgamalKeyManager interface {
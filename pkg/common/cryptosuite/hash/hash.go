@@ -12,10 +12,20 @@ type Hash interface {
 	Sum() []byte
 	WriteAny(...interface{}) error
 	Clone() Hash
+	// WithDomain returns an independent branch of the hash, with each of data written
+	// separated by domain, or only the domain if data is empty.
+	WithDomain(domain string, data ...[]byte) Hash
 	Commit(data ...interface{}) (core_hash.Commitment, core_hash.Decommitment, error)
 	Decommit(c core_hash.Commitment, d core_hash.Decommitment, data ...interface{}) bool
 }
 
+// Segment references the state of a session Hash at some offset of its recorded transcript,
+// such as the state in which a proof was created.
+type Segment interface {
+	// Hash recomputes the state of the session Hash at the referenced offset.
+	Hash() (Hash, error)
+}
+
 type HashManager interface {
 	NewHasher(keyID string, opts keyopts.Options, data ...core_hash.WriterToWithDomain) Hash
 	RestoreHasher(keyID string, opts keyopts.Options) (Hash, error)
@@ -29,6 +29,9 @@ type PaillierKey interface {
 
 	PublicKeyRaw() *pailliercore.PublicKey
 
+	// SecretKeyRaw returns the secret key, or nil if the key is public.
+	SecretKeyRaw() *pailliercore.SecretKey
+
 	// Modulus returns an arith.Modulus for N.
 	Modulus() *arith.Modulus
 
@@ -73,6 +76,9 @@ type PaillierKeyManager interface {
 	// GenerateKey generates a new Paillier key pair.
 	GenerateKey(opts keyopts.Options) (PaillierKey, error)
 
+	// GenerateKeyWithProgress generates a new Paillier key pair, reporting on the search for its primes.
+	GenerateKeyWithProgress(opts keyopts.Options, progress func(pailliercore.Progress)) (PaillierKey, error)
+
 	// GetKey returns a Paillier key by its SKI.
 	GetKey(opts keyopts.Options) (PaillierKey, error)
 
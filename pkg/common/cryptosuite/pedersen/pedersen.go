@@ -53,4 +53,16 @@ type PedersenKeyManager interface {
 
 	// Verify returns true if the given commitment is valid.
 	Verify(a, b, e *saferith.Int, S, T *saferith.Nat, opts keyopts.Options) bool
+
+	// SetPrecompute enables pools of up to size precomputed commitment randomness for each key.
+	SetPrecompute(size int)
+
+	// Precompute fills the pool of precomputed commitment randomness of a key, and returns the number added.
+	Precompute(pl *pool.Pool, opts keyopts.Options) (int, error)
+
+	// NewProof returns the zkprm proof of the Pedersen parameters of a key, which must have its secret.
+	NewProof(hash hash.Hash, pl *pool.Pool, opts keyopts.Options) (*zkprm.Proof, error)
+
+	// VerifyProof returns true if the given zkprm proof is valid for the Pedersen parameters of a key.
+	VerifyProof(hash hash.Hash, pl *pool.Pool, p *zkprm.Proof, opts keyopts.Options) bool
 }
@@ -0,0 +1,51 @@
+package keyopts
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Names of the options understood by the key stores.
+const (
+	// KeyIDOption is the MPC KeyID, or session ID, the key belongs to.
+	KeyIDOption = "id"
+	// PartyIDOption is the party holding the key, or ROOT for the key of the whole committee.
+	PartyIDOption = "partyid"
+	// EpochOption is the refresh epoch of the key.
+	EpochOption = "epoch"
+)
+
+var (
+	ErrMissingOption = errors.New("keyopts: missing required option")
+	ErrInvalidOption = errors.New("keyopts: invalid option")
+)
+
+// Require checks that opts sets each of the options in names, with a value of the expected type:
+// a non-empty string for the key and party IDs, and a uint64 for the epoch.
+func Require(opts Options, names ...string) error {
+	if opts == nil {
+		return fmt.Errorf("%w: no options given", ErrMissingOption)
+	}
+	for _, name := range names {
+		val, ok := opts.Get(name)
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrMissingOption, name)
+		}
+		switch name {
+		case KeyIDOption, PartyIDOption:
+			if s, ok := val.(string); !ok || s == "" {
+				return fmt.Errorf("%w: %s must be a non-empty string, got %T", ErrInvalidOption, name, val)
+			}
+		case EpochOption:
+			if _, ok := val.(uint64); !ok {
+				return fmt.Errorf("%w: %s must be a uint64, got %T", ErrInvalidOption, name, val)
+			}
+		}
+	}
+	return nil
+}
+
+// RequireKey checks that opts identify a single key, by its key ID and party ID.
+func RequireKey(opts Options) error {
+	return Require(opts, KeyIDOption, PartyIDOption)
+}
@@ -27,6 +27,9 @@ func (cm *CommitmentManager) NewCommitment(cmt []byte, dcm []byte) comm_commitme
 }
 
 func (cm *CommitmentManager) Import(cmt comm_commitment.Commitment, opts keyopts.Options) error {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return err
+	}
 	cb, err := cmt.Bytes()
 	if err != nil {
 		return err
@@ -70,6 +73,9 @@ func (cm *CommitmentManager) ImportDecommitment(dcmt []byte, opts keyopts.Option
 }
 
 func (cm *CommitmentManager) Get(opts keyopts.Options) (comm_commitment.Commitment, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	cb, err := cm.ks.Get(opts)
 	if err != nil {
 		return nil, err
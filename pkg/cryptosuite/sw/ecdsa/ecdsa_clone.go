@@ -17,6 +17,18 @@ func (key ECDSAKey) CloneByMultiplier(c curve.Scalar) comm_ecdsa.ECDSAKey {
 	return cloned
 }
 
+func (key ECDSAKey) CloneByAdder(c curve.Scalar) comm_ecdsa.ECDSAKey {
+	group := key.group
+	cloned := ECDSAKey{
+		group: group,
+	}
+	if key.Private() {
+		cloned.priv = group.NewScalar().Set(key.priv).Add(c)
+	}
+	cloned.pub = key.pub.Add(c.ActOnBase())
+	return cloned
+}
+
 func (key ECDSAKey) CloneByKeyMultiplier(multiplierKey comm_ecdsa.ECDSAKey, c curve.Scalar) comm_ecdsa.ECDSAKey {
 	group := key.group
 	mk, ok := multiplierKey.(ECDSAKey)
@@ -1,17 +1,41 @@
 package ecdsa
 
 import (
+	"crypto/rand"
+
 	"github.com/cronokirby/saferith"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
 	comm_ecdsa "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/ecdsa"
 )
 
 func (key ECDSAKey) Act(g curve.Point, inv bool) curve.Point {
 	priv := key.priv
 	if inv {
-		return priv.Invert().Act(g)
+		return act(key.group, invert(key.group, priv, key.blinding), g, key.blinding)
+	}
+	return act(key.group, priv, g, key.blinding)
+}
+
+// invert returns a copy of s⁻¹, leaving s unchanged. If blinding is set, (s•r)⁻¹•r is computed instead
+// for a random r, since the inversion does not run in constant time.
+func invert(group curve.Curve, s curve.Scalar, blinding bool) curve.Scalar {
+	if !blinding {
+		return group.NewScalar().Set(s).Invert()
+	}
+	r := sample.Scalar(rand.Reader, group)
+	return group.NewScalar().Set(s).Mul(r).Invert().Mul(r)
+}
+
+// act returns s•g. If blinding is set, s is split as (s - r) + r for a random r,
+// so that s itself is never used as a multiplier.
+func act(group curve.Curve, s curve.Scalar, g curve.Point, blinding bool) curve.Point {
+	if !blinding {
+		return s.Act(g)
 	}
-	return priv.Act(g)
+	r := sample.Scalar(rand.Reader, group)
+	masked := group.NewScalar().Set(s).Sub(r)
+	return masked.Act(g).Add(r.Act(g))
 }
 
 func (key ECDSAKey) Commit(m curve.Scalar, c curve.Scalar) curve.Scalar {
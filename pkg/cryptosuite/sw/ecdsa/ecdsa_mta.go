@@ -6,6 +6,7 @@ import (
 	"github.com/mr-shifu/mpc-lib/core/paillier"
 	zkaffg "github.com/mr-shifu/mpc-lib/core/zk/affg"
 	"github.com/mr-shifu/mpc-lib/lib/mta"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
 	comm_paillier "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/paillier"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/pedersen"
@@ -16,7 +17,8 @@ func (k ECDSAKey) NewMtAAffgProof(
 	encoded *paillier.Ciphertext,
 	selfPaillier comm_paillier.PaillierKey,
 	partyPaillier comm_paillier.PaillierKey,
-	ped pedersen.PedersenKey) (*saferith.Int, *paillier.Ciphertext, *paillier.Ciphertext, *zkaffg.Proof) {
+	ped pedersen.PedersenKey,
+	profile params.Profile) (*saferith.Int, *paillier.Ciphertext, *paillier.Ciphertext, *zkaffg.Proof) {
 	if k.Private() {
 		return mta.ProveAffG(
 			k.Group(),
@@ -27,6 +29,7 @@ func (k ECDSAKey) NewMtAAffgProof(
 			selfPaillier.PublicKeyRaw(),
 			partyPaillier.PublicKeyRaw(),
 			ped.PublicKeyRaw(),
+			profile,
 		)
 	}
 	return nil, nil, nil, nil
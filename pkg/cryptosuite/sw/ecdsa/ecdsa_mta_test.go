@@ -8,6 +8,7 @@ import (
 	paillier_core "github.com/mr-shifu/mpc-lib/core/paillier"
 	"github.com/mr-shifu/mpc-lib/core/pool"
 	zkaffg "github.com/mr-shifu/mpc-lib/core/zk/affg"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/hash"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/paillier"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/paillierencodedkey"
@@ -33,8 +34,7 @@ func TestMtA(t *testing.T) {
 
 	partyIDs := []string{"a", "b", "c"}
 
-	opts := keyopts.Options{}
-	opts.Set("id", "123", "partyid", "a")
+	opts := keyopts.New().WithKeyID("123").WithPartyID("a")
 
 	hash_vault := vault.NewInMemoryVault()
 	hash_kr := keyopts.NewInMemoryKeyOpts()
@@ -79,8 +79,7 @@ func TestMtA(t *testing.T) {
 		ped, _ := pk.DerivePedersenKey()
 		peds[party] = ped.(pedersen.PedersenKey)
 
-		opts := keyopts.Options{}
-		opts.Set("id", "123", "partyid", party)
+		opts := keyopts.New().WithKeyID("123").WithPartyID(party)
 
 		gamma, _ := ec_km.GenerateKey(opts)
 		gammas[party] = gamma.(ECDSAKey)
@@ -115,6 +114,7 @@ func TestMtA(t *testing.T) {
 				pk.PublicKey(),
 				pkj.PublicKey(),
 				pedj.PublicKey(),
+				params.Standard,
 			)
 			alpha, _ := pkj.Decode(alphaD)
 			mtaResults[i][j] = MtAResult{
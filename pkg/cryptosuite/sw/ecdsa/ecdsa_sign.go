@@ -0,0 +1,51 @@
+package ecdsa
+
+import (
+	"errors"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	core_ecdsa "github.com/mr-shifu/mpc-lib/core/ecdsa"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+)
+
+// Sign returns a signature of hash with a deterministic nonce, as specified by RFC 6979.
+//
+// The same key and hash always produce the same signature, which allows auditing signatures
+// by recomputing them, and removes the need for a source of randomness when signing.
+func (key ECDSAKey) Sign(hash []byte) (*core_ecdsa.Signature, error) {
+	if !key.Private() {
+		return nil, ErrInvalidKey
+	}
+	if key.group.Name() != (curve.Secp256k1{}).Name() {
+		return nil, errors.New("ecdsa: deterministic nonces are only supported for secp256k1")
+	}
+	priv, err := key.priv.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	m := curve.FromHash(key.group, hash)
+	// the nonce is retried in the negligible case where r or s is zero
+	for i := uint32(0); ; i++ {
+		nonce := secp256k1.NonceRFC6979(priv, hash, nil, nil, i)
+		nonceBytes := nonce.Bytes()
+		k := key.group.NewScalar()
+		if err := k.UnmarshalBinary(nonceBytes[:]); err != nil {
+			return nil, err
+		}
+		nonce.Zero()
+
+		// R = k•G, r = R|ₓ
+		R := k.ActOnBase()
+		r := R.XScalar()
+		if r.IsZero() {
+			continue
+		}
+		// s = k⁻¹•(m + r•x)
+		s := invert(key.group, k, key.blinding).Mul(key.Mul(r).Add(m))
+		if s.IsZero() {
+			continue
+		}
+		return &core_ecdsa.Signature{R: R, S: s}, nil
+	}
+}
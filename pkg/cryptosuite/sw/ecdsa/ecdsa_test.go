@@ -5,7 +5,10 @@ import (
 	"testing"
 
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	comm_ecdsa "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/ecdsa"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/hash"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/vss"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
@@ -15,7 +18,7 @@ import (
 )
 
 func newEcdsakeyManager() *ECDSAKeyManager {
-	cfg := &Config{curve.Secp256k1{}}
+	cfg := &Config{Group: curve.Secp256k1{}}
 
 	ec_vault := vault.NewInMemoryVault()
 	ec_kr := keyopts.NewInMemoryKeyOpts()
@@ -38,8 +41,7 @@ func newEcdsakeyManager() *ECDSAKeyManager {
 func TestGenerateKey(t *testing.T) {
 	mgr := newEcdsakeyManager()
 
-	opts := keyopts.Options{}
-	opts.Set("id", "123", "partyid", "1")
+	opts := keyopts.New().WithKeyID("123").WithPartyID("1")
 
 	// Must Generate a new key successfully
 	key, err := mgr.GenerateKey(opts)
@@ -68,8 +70,7 @@ func TestImportPrivateKey(t *testing.T) {
 	kb, err := key.Bytes()
 	assert.NoError(t, err)
 
-	opts := keyopts.Options{}
-	opts.Set("id", "123", "partyid", "1")
+	opts := keyopts.New().WithKeyID("123").WithPartyID("1")
 
 	_, err = mgr.ImportKey(key, opts)
 	assert.NoError(t, err)
@@ -91,8 +92,7 @@ func TestImportPublicKey(t *testing.T) {
 	kb, err := key.Bytes()
 	assert.NoError(t, err)
 
-	opts := keyopts.Options{}
-	opts.Set("id", "123", "partyid", "1")
+	opts := keyopts.New().WithKeyID("123").WithPartyID("1")
 
 	_, err = mgr.ImportKey(key, opts)
 	assert.NoError(t, err)
@@ -115,8 +115,7 @@ func TestSchnorr(t *testing.T) {
 	
 	hs := keystore.NewInMemoryKeystore(sch_vault, sch_kr)
 	hash_mgr := hash.NewHashManager(hs)
-	opts := keyopts.Options{}
-	opts.Set("id", "123", "partyid", "1")
+	opts := keyopts.New().WithKeyID("123").WithPartyID("1")
 	h := hash_mgr.NewHasher("test", opts)
 
 	// 1. Generate a new key by mgr1
@@ -152,8 +151,7 @@ func TestImportVSS(t *testing.T) {
 	mgr1 := newEcdsakeyManager()
 	mgr2 := newEcdsakeyManager()
 
-	opts := keyopts.Options{}
-	opts.Set("id", "123", "partyid", "1")
+	opts := keyopts.New().WithKeyID("123").WithPartyID("1")
 
 	// 1. Generate a new key by mgr
 	key1, err := mgr1.GenerateKey(opts)
@@ -182,3 +180,66 @@ func TestImportVSS(t *testing.T) {
 	// assert.NoError(t, err)
 	// assert.False(t, key2.Private())
 }
+
+func TestImportVerifiedKey(t *testing.T) {
+	mgr := newEcdsakeyManager()
+	group := curve.Secp256k1{}
+
+	opts := keyopts.New().WithKeyID("123").WithPartyID("a")
+
+	// share a secret with a polynomial of degree 1 between a, b and c
+	secret := sample.Scalar(rand.Reader, group)
+	f := polynomial.NewPolynomial(group, 1, secret)
+	shares := map[party.ID]curve.Scalar{}
+	expected := comm_ecdsa.ShareExpectation{
+		ID:           "a",
+		Commitments:  polynomial.NewPolynomialExponent(f),
+		PublicShares: map[party.ID]curve.Point{},
+		PublicKey:    secret.ActOnBase(),
+	}
+	for _, j := range []party.ID{"a", "b", "c"} {
+		shares[j] = f.Evaluate(j.Scalar(group))
+		expected.PublicShares[j] = shares[j].ActOnBase()
+	}
+
+	// Must import a consistent share
+	key, report, err := mgr.ImportVerifiedKey(NewECDSAKey(shares["a"], expected.PublicShares["a"], group), expected, opts)
+	assert.NoError(t, err)
+	assert.True(t, report.Consistent())
+	assert.True(t, key.Private())
+
+	// Must report a corrupted share without importing it
+	opts = keyopts.New().WithKeyID("456").WithPartyID("a")
+	_, report, err = mgr.ImportVerifiedKey(NewECDSAKey(shares["b"], expected.PublicShares["b"], group), expected, opts)
+	assert.ErrorIs(t, err, comm_ecdsa.ErrInconsistentShare)
+	assert.False(t, report.PublicShare)
+	assert.False(t, report.Commitment)
+	assert.True(t, report.SharesPublicKey)
+	_, err = mgr.GetKey(opts)
+	assert.Error(t, err)
+
+	// Must report a corrupted public share
+	expected.PublicShares["c"] = sample.Scalar(rand.Reader, group).ActOnBase()
+	_, report, err = mgr.ImportVerifiedKey(NewECDSAKey(shares["a"], expected.PublicShares["a"], group), expected, opts)
+	assert.ErrorIs(t, err, comm_ecdsa.ErrInconsistentShare)
+	assert.True(t, report.PublicShare)
+	assert.Equal(t, []party.ID{"c"}, report.MismatchedPublicShares)
+	assert.False(t, report.SharesPublicKey)
+}
+
+func TestActBlinding(t *testing.T) {
+	group := curve.Secp256k1{}
+	sk := sample.Scalar(rand.Reader, group)
+	key := NewECDSAKey(group.NewScalar().Set(sk), sk.ActOnBase(), group)
+	g := sample.Scalar(rand.Reader, group).ActOnBase()
+	expected := sk.Act(g)
+	expectedInv := group.NewScalar().Set(sk).Invert().Act(g)
+
+	for _, blinding := range []bool{false, true} {
+		key := key.withBlinding(blinding)
+		assert.True(t, key.Act(g, false).Equal(expected))
+		assert.True(t, key.Act(g, true).Equal(expectedInv))
+		// the private key is left unchanged by the inversion
+		assert.True(t, key.Act(g, false).Equal(expected))
+	}
+}
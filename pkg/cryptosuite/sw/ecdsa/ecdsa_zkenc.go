@@ -3,20 +3,22 @@ package ecdsa
 import (
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	zkenc "github.com/mr-shifu/mpc-lib/core/zk/enc"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/paillier"
 	comm_pek "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/paillierencodedkey"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/pedersen"
 )
 
-func (k ECDSAKey) NewZKEncProof(h hash.Hash, pek comm_pek.PaillierEncodedKey, pk paillier.PaillierKey, ped pedersen.PedersenKey) (*zkenc.Proof, error) {
+func (k ECDSAKey) NewZKEncProof(h hash.Hash, pek comm_pek.PaillierEncodedKey, pk paillier.PaillierKey, ped pedersen.PedersenKey, profile params.Profile) (*zkenc.Proof, error) {
 	proof := zkenc.NewProof(
 		k.Group(),
 		h,
 		zkenc.Public{
-			K:      pek.Encoded(),
-			Prover: pk.PublicKeyRaw(),
-			Aux:    ped.PublicKeyRaw(),
+			K:       pek.Encoded(),
+			Prover:  pk.PublicKeyRaw(),
+			Aux:     ped.PublicKeyRaw(),
+			Profile: profile,
 		}, zkenc.Private{
 			K:   curve.MakeInt(k.priv),
 			Rho: pek.Nonce(),
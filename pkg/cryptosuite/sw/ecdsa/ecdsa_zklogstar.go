@@ -4,6 +4,7 @@ import (
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	core_paillier "github.com/mr-shifu/mpc-lib/core/paillier"
 	zklogstar "github.com/mr-shifu/mpc-lib/core/zk/logstar"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/paillier"
 	comm_pek "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/paillierencodedkey"
@@ -17,16 +18,18 @@ func (k ECDSAKey) NewZKLogstarProof(
 	X curve.Point,
 	G curve.Point,
 	prover paillier.PaillierKey,
-	ped pedersen.PedersenKey) (*zklogstar.Proof, error) {
+	ped pedersen.PedersenKey,
+	profile params.Profile) (*zklogstar.Proof, error) {
 	proof := zklogstar.NewProof(
 		k.Group(),
 		h,
 		zklogstar.Public{
-			C:      C,
-			X:      X,
-			G:      G,
-			Prover: prover.PublicKeyRaw(),
-			Aux:    ped.PublicKeyRaw(),
+			C:       C,
+			X:       X,
+			G:       G,
+			Prover:  prover.PublicKeyRaw(),
+			Aux:     ped.PublicKeyRaw(),
+			Profile: profile,
 		}, zklogstar.Private{
 			X:   curve.MakeInt(k.priv),
 			Rho: pek.Nonce(),
@@ -28,6 +28,9 @@ type ECDSAKey struct {
 	zks *zksch.ZKSchnorr
 
 	vssmgr comm_vss.VssKeyManager
+
+	// blinding is set when the private key must be blinded before use
+	blinding bool
 }
 
 type rawECDSAKey struct {
@@ -101,6 +104,16 @@ func (key ECDSAKey) withVSSKeyMgr(vssmgr comm_vss.VssKeyManager) ECDSAKey {
 	return key
 }
 
+func (key ECDSAKey) withBlinding(blinding bool) ECDSAKey {
+	key.blinding = blinding
+	return key
+}
+
+// FromBytes decodes a key encoded with Bytes, such as the share Γⱼ broadcast by a signer.
+func FromBytes(data []byte) (ECDSAKey, error) {
+	return fromBytes(data)
+}
+
 func fromBytes(data []byte) (ECDSAKey, error) {
 	key := ECDSAKey{}
 
@@ -113,6 +126,8 @@ func fromBytes(data []byte) (ECDSAKey, error) {
 	switch raw.Group {
 	case "secp256k1":
 		group = curve.Secp256k1{}
+	default:
+		return ECDSAKey{}, ErrInvalidKey
 	}
 	key.group = group
 
@@ -15,6 +15,9 @@ import (
 
 type Config struct {
 	Group curve.Curve
+	// Blinding randomizes the private scalar before each multiplication and inversion,
+	// at the cost of an extra point multiplication.
+	Blinding bool
 }
 
 type ECDSAKeyManager struct {
@@ -42,6 +45,9 @@ func (mgr *ECDSAKeyManager) NewKey(priv curve.Scalar, pub curve.Point, group cur
 }
 
 func (mgr *ECDSAKeyManager) GenerateKey(opts keyopts.Options) (comm_ecdsa.ECDSAKey, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	// Generate a new ECDSA key pair
 	sk, pk := sample.ScalarPointPair(rand.Reader, mgr.cfg.Group)
 
@@ -64,10 +70,14 @@ func (mgr *ECDSAKeyManager) GenerateKey(opts keyopts.Options) (comm_ecdsa.ECDSAK
 	// return the key pair
 	return key.
 		withZKSchnorr(zksch.NewZKSchnorr(mgr.schnorrstore.KeyAccessor(keyID, opts))).
-		withVSSKeyMgr(mgr.vssmgr), nil
+		withVSSKeyMgr(mgr.vssmgr).
+		withBlinding(mgr.cfg.Blinding), nil
 }
 
 func (mgr *ECDSAKeyManager) ImportKey(raw interface{}, opts keyopts.Options) (comm_ecdsa.ECDSAKey, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	var err error
 	var key ECDSAKey
 
@@ -98,10 +108,45 @@ func (mgr *ECDSAKeyManager) ImportKey(raw interface{}, opts keyopts.Options) (co
 
 	return key.
 		withZKSchnorr(zksch.NewZKSchnorr(mgr.schnorrstore.KeyAccessor(keyID, opts))).
-		withVSSKeyMgr(mgr.vssmgr), nil
+		withVSSKeyMgr(mgr.vssmgr).
+		withBlinding(mgr.cfg.Blinding), nil
+}
+
+// ImportVerifiedKey imports a ECDSA share after checking it against the public data of its key,
+// so that a corrupted backup is detected when it is restored.
+func (mgr *ECDSAKeyManager) ImportVerifiedKey(raw interface{}, expected comm_ecdsa.ShareExpectation, opts keyopts.Options) (comm_ecdsa.ECDSAKey, *comm_ecdsa.ConsistencyReport, error) {
+	var key ECDSAKey
+	switch raw := raw.(type) {
+	case []byte:
+		k, err := fromBytes(raw)
+		if err != nil {
+			return nil, nil, err
+		}
+		key = k
+	case ECDSAKey:
+		key = raw
+	default:
+		return nil, nil, ErrInvalidKey
+	}
+	if !key.Private() {
+		return nil, nil, ErrInvalidKey
+	}
+
+	report := comm_ecdsa.CheckShare(key.priv.ActOnBase(), expected)
+	if err := report.Err(); err != nil {
+		return nil, report, err
+	}
+	imported, err := mgr.ImportKey(key, opts)
+	if err != nil {
+		return nil, report, err
+	}
+	return imported, report, nil
 }
 
 func (mgr *ECDSAKeyManager) GetKey(opts keyopts.Options) (comm_ecdsa.ECDSAKey, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	// get the key from the keystore
 	// keyID := hex.EncodeToString(ski)
 	decoded, err := mgr.keystore.Get(opts)
@@ -121,5 +166,6 @@ func (mgr *ECDSAKeyManager) GetKey(opts keyopts.Options) (comm_ecdsa.ECDSAKey, e
 
 	return k.
 		withZKSchnorr(zksch.NewZKSchnorr(mgr.schnorrstore.KeyAccessor(keyID, opts))).
-		withVSSKeyMgr(mgr.vssmgr), nil
+		withVSSKeyMgr(mgr.vssmgr).
+		withBlinding(mgr.cfg.Blinding), nil
 }
\ No newline at end of file
@@ -27,6 +27,9 @@ func NewEd25519KeyManagerImpl(store, schstore keystore.Keystore, vssmgr vssed255
 
 // GenerateKey generates a new Ed25519 key pair.
 func (mgr *Ed25519KeyManagerImpl) GenerateKey(opts keyopts.Options) (Ed25519, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	k, err := GenerateKey()
 	if err != nil {
 		return nil, errors.WithMessage(err, "ed25519: failed to generate key")
@@ -48,6 +51,9 @@ func (mgr *Ed25519KeyManagerImpl) GenerateKey(opts keyopts.Options) (Ed25519, er
 
 // Import imports a Ed25519 key from its byte representation.
 func (mgr *Ed25519KeyManagerImpl) ImportKey(raw interface{}, opts keyopts.Options) (Ed25519, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	k := new(Ed25519Impl)
 	switch tt := raw.(type) {
 	case []byte:
@@ -80,6 +86,9 @@ func (mgr *Ed25519KeyManagerImpl) ImportKey(raw interface{}, opts keyopts.Option
 
 // GetKey returns a Ed25519 key by its SKI.
 func (mgr *Ed25519KeyManagerImpl) GetKey(opts keyopts.Options) (Ed25519, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	kb, err := mgr.keystore.Get(opts)
 	if err != nil {
 		return nil, errors.WithMessage(err, "ed25519: failed to get key from keystore")
@@ -25,8 +25,7 @@ func getKeyManager() *Ed25519KeyManagerImpl {
 func TestEd25519KeyManagerImpl_GenerateKey(t *testing.T) {
 	mgr := getKeyManager()
 
-	opts := keyopts.Options{}
-	opts.Set("id", "1", "partyid", "a")
+	opts := keyopts.New().WithKeyID("1").WithPartyID("a")
 	k, err := mgr.GenerateKey(opts)
 	assert.NoError(t, err)
 	assert.NotNil(t, k)
@@ -46,8 +45,7 @@ func TestEd25519KeyManagerImpl_ImportKey(t *testing.T) {
 	k, err := GenerateKey()
 	assert.NoError(t, err)
 
-	opts := keyopts.Options{}
-	opts.Set("id", "1", "partyid", "a")
+	opts := keyopts.New().WithKeyID("1").WithPartyID("a")
 	_, err = mgr.ImportKey(k, opts)
 	assert.NoError(t, err)
 
@@ -67,8 +65,7 @@ func TestEd25519KeyManagerImpl_ImportKeyBytes(t *testing.T) {
 	kb, err := k.Bytes()
 	assert.NoError(t, err)
 
-	opts := keyopts.Options{}
-	opts.Set("id", "1", "partyid", "a")
+	opts := keyopts.New().WithKeyID("1").WithPartyID("a")
 	_, err = mgr.ImportKey(kb, opts)
 	assert.NoError(t, err)
 
@@ -91,8 +88,7 @@ func TestEd25519KeyManagerImpl_ImportPublicKey(t *testing.T) {
 	kb, err := pk.Bytes()
 	assert.NoError(t, err)
 
-	opts := keyopts.Options{}
-	opts.Set("id", "1", "partyid", "a")
+	opts := keyopts.New().WithKeyID("1").WithPartyID("a")
 	_, err = mgr.ImportKey(kb, opts)
 	assert.NoError(t, err)
 
@@ -104,8 +100,7 @@ func TestEd25519KeyManagerImpl_ImportPublicKey(t *testing.T) {
 }
 
 func TestEd25519KeyManager_SchnorrProof(t *testing.T) {
-	opts1 := keyopts.Options{}
-	opts1.Set("id", "1", "partyid", "a")
+	opts1 := keyopts.New().WithKeyID("1").WithPartyID("a")
 
 	hahs_keyopts := keyopts.NewInMemoryKeyOpts()
 	hahs_vault := vault.NewInMemoryVault()
@@ -4,13 +4,17 @@ import (
 	"crypto/rand"
 	"testing"
 
+	"github.com/fxamacker/cbor/v2"
 	"github.com/mr-shifu/mpc-lib/core/elgamal"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	zksch "github.com/mr-shifu/mpc-lib/core/zk/sch"
+	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/hash"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/keystore"
 	"github.com/mr-shifu/mpc-lib/pkg/vault"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestElgamal(t *testing.T) {
@@ -23,8 +27,7 @@ func TestElgamal(t *testing.T) {
 	mgr := NewElgamalKeyManager(ks, &Config{Group: curve.Secp256k1{}})
 
 	// generate a new ElGamal key pair
-	opts := keyopts.Options{}
-	opts.Set("ID", 123, "partyID", 1)
+	opts := keyopts.New().WithKeyID("123").WithPartyID("1")
 	key, err := mgr.GenerateKey(opts)
 	assert.NoError(t, err)
 	keyBytes, err := key.Bytes()
@@ -57,3 +60,31 @@ func TestElgamal(t *testing.T) {
 	v := ciphertext.Valid()
 	assert.True(t, v)
 }
+
+func TestElgamalSchnorrProof(t *testing.T) {
+	ks := keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())
+	mgr := NewElgamalKeyManager(ks, &Config{Group: curve.Secp256k1{}})
+	hash_mgr := hash.NewHashManager(keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts()))
+
+	opts := keyopts.New().WithKeyID("1").WithPartyID("a")
+	h := hash_mgr.NewHasher("test", opts)
+
+	key, err := mgr.GenerateKey(opts)
+	require.NoError(t, err)
+	proof, err := key.NewSchnorrProof(h.Clone())
+	require.NoError(t, err)
+
+	data, err := cbor.Marshal(proof)
+	require.NoError(t, err)
+	decoded := zksch.EmptyProof(curve.Secp256k1{})
+	require.NoError(t, cbor.Unmarshal(data, decoded))
+	assert.True(t, key.PublicKey().VerifySchnorrProof(h.Clone(), decoded))
+
+	_, err = key.PublicKey().NewSchnorrProof(h.Clone())
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	otherOpts := keyopts.New().WithKeyID("1").WithPartyID("b")
+	other, err := mgr.GenerateKey(otherOpts)
+	require.NoError(t, err)
+	assert.False(t, other.VerifySchnorrProof(h.Clone(), decoded))
+}
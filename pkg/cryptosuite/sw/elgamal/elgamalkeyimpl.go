@@ -8,7 +8,9 @@ import (
 	"github.com/fxamacker/cbor/v2"
 	"github.com/mr-shifu/mpc-lib/core/elgamal"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	zksch "github.com/mr-shifu/mpc-lib/core/zk/sch"
 	cs_elgamal "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/elgamal"
+	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
 )
 
 var (
@@ -70,6 +72,11 @@ func (key ElgamalKey) PublicKeyRaw() curve.Point {
 	return key.publicKey
 }
 
+// SecretKeyRaw returns the secret key, or nil if the key is public.
+func (key ElgamalKey) SecretKeyRaw() curve.Scalar {
+	return key.secretKey
+}
+
 func (key ElgamalKey) Encrypt(message curve.Scalar) ([]byte, curve.Scalar, error) {
 	ct, nonce := elgamal.Encrypt(key.publicKey, message)
 
@@ -81,6 +88,17 @@ func (key ElgamalKey) Encrypt(message curve.Scalar) ([]byte, curve.Scalar, error
 	return buf.Bytes(), nonce, nil
 }
 
+func (key ElgamalKey) NewSchnorrProof(hash hash.Hash) (*zksch.Proof, error) {
+	if !key.Private() {
+		return nil, ErrInvalidKey
+	}
+	return zksch.NewProof(hash, key.publicKey, key.secretKey, nil), nil
+}
+
+func (key ElgamalKey) VerifySchnorrProof(hash hash.Hash, proof *zksch.Proof) bool {
+	return proof.Verify(hash, key.publicKey, nil)
+}
+
 func fromBytes(data []byte) (ElgamalKey, error) {
 	key := ElgamalKey{}
 
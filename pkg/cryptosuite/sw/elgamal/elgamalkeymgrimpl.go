@@ -29,6 +29,9 @@ func NewElgamalKeyManager(store keystore.Keystore, cfg *Config) *ElgamalKeyManag
 }
 
 func (mgr *ElgamalKeyManager) GenerateKey(opts keyopts.Options) (cs_elgamal.ElgamalKey, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	// Generate a new ElGamal key pair
 	sk, pk := sample.ScalarPointPair(rand.Reader, mgr.cfg.Group)
 
@@ -53,6 +56,9 @@ func (mgr *ElgamalKeyManager) GenerateKey(opts keyopts.Options) (cs_elgamal.Elga
 }
 
 func (mgr *ElgamalKeyManager) ImportKey(raw interface{}, opts keyopts.Options) (cs_elgamal.ElgamalKey, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	var err error
 	var key ElgamalKey
 
@@ -85,6 +91,9 @@ func (mgr *ElgamalKeyManager) ImportKey(raw interface{}, opts keyopts.Options) (
 }
 
 func (mgr *ElgamalKeyManager) GetKey(opts keyopts.Options) (cs_elgamal.ElgamalKey, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	// get the key from the keystore
 	decoded, err := mgr.keystore.Get(opts)
 	if err != nil {
@@ -37,6 +37,7 @@ func New(store keystore.KeyAccessor, initialData ...core_hash.WriterToWithDomain
 
 func Restore(store keystore.KeyAccessor) (comm_hash.Hash, error) {
 	hash := &Hash{h: blake3.New(), store: store}
+	_, _ = hash.h.WriteString("CMP-BLAKE")
 
 	ss, err := hash.store.Get()
 	if err != nil {
@@ -73,13 +74,24 @@ func (hash *Hash) WriteAny(data ...interface{}) error {
 			if t == nil {
 				return errors.New("hash.WriteAny: nil []byte")
 			}
-			toBeWritten = core_hash.BytesWithDomain{"[]byte", t}
+			toBeWritten = core_hash.BytesWithDomain{TheDomain: "[]byte", Bytes: t}
 		case *big.Int:
 			if t == nil {
 				return fmt.Errorf("hash.WriteAny: write *big.Int: nil")
 			}
 			bytes, _ := t.GobEncode()
-			toBeWritten = core_hash.BytesWithDomain{"big.Int", bytes}
+			toBeWritten = core_hash.BytesWithDomain{TheDomain: "big.Int", Bytes: bytes}
+		case core_hash.SizedWriterToWithDomain:
+			// the output is kept in the transcript, but written to a buffer allocated once
+			buf := bytes.NewBuffer(make([]byte, 0, t.Size()))
+			n, err := t.WriteTo(buf)
+			if err == nil && n != int64(t.Size()) {
+				err = fmt.Errorf("wrote %d bytes instead of %d", n, t.Size())
+			}
+			if err != nil {
+				return fmt.Errorf("hash.WriteAny: %s: %w", reflect.TypeOf(t).String(), err)
+			}
+			toBeWritten = core_hash.BytesWithDomain{TheDomain: t.Domain(), Bytes: buf.Bytes()}
 		case core_hash.WriterToWithDomain:
 			var buf = new(bytes.Buffer)
 			_, err := t.WriteTo(buf)
@@ -87,7 +99,7 @@ func (hash *Hash) WriteAny(data ...interface{}) error {
 				name := reflect.TypeOf(t)
 				return fmt.Errorf("hash.WriteAny: %s: %w", name.String(), err)
 			}
-			toBeWritten = core_hash.BytesWithDomain{t.Domain(), buf.Bytes()}
+			toBeWritten = core_hash.BytesWithDomain{TheDomain: t.Domain(), Bytes: buf.Bytes()}
 		case encoding.BinaryMarshaler:
 			name := reflect.TypeOf(t)
 			bytes, err := t.MarshalBinary()
@@ -161,6 +173,25 @@ func (hash *Hash) Clone() comm_hash.Hash {
 	}
 }
 
+// WithDomain returns a clone of the hash, not backed by the keystore, with each of data written
+// separated by domain. The writes are recorded in the transcript of the branch, so that a Segment
+// of it can be replayed.
+func (hash *Hash) WithDomain(domain string, data ...[]byte) comm_hash.Hash {
+	branch := &Hash{
+		h:     hash.h.Clone(),
+		state: append([]core_hash.BytesWithDomain(nil), hash.state...),
+	}
+	if len(data) == 0 {
+		data = [][]byte{{}}
+	}
+	for _, d := range data {
+		toBeWritten := core_hash.BytesWithDomain{TheDomain: domain, Bytes: d}
+		_ = branch.updateState(toBeWritten)
+		branch.writeBytesWithDomain(toBeWritten)
+	}
+	return branch
+}
+
 // Commit creates a commitment to data, and returns a commitment hash, and a decommitment string such that
 // commitment = h(data, decommitment).
 func (hash *Hash) Commit(data ...interface{}) (core_hash.Commitment, core_hash.Decommitment, error) {
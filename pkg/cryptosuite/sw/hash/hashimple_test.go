@@ -25,8 +25,7 @@ func TestHash_WriteAny(t *testing.T) {
 	var err error
 
 	testFunc := func(vs ...interface{}) error {
-		opts := keyopts.Options{}
-		opts.Set("ID", 123, "partyID", 1)
+		opts := keyopts.New().WithKeyID("123").WithPartyID("1")
 		h := mgr.NewHasher("test", opts)
 
 		for _, v := range vs {
@@ -57,8 +56,7 @@ func TestHash_WriteAny_Collision(t *testing.T) {
 	var err error
 
 	testFunc := func(vs ...interface{}) ([]byte, error) {
-		opts := keyopts.Options{}
-		opts.Set("ID", 123, "partyID", 1)
+		opts := keyopts.New().WithKeyID("123").WithPartyID("1")
 		h := mgr.NewHasher("test", opts)
 
 		for _, v := range vs {
@@ -92,8 +90,7 @@ func TestHash_Clone(t *testing.T) {
 	hs := keystore.NewInMemoryKeystore(v, kr)
 	mgr := NewHashManager(hs)
 	
-	opts := keyopts.Options{}
-	opts.Set("ID", 123, "partyID", 1)
+	opts := keyopts.New().WithKeyID("123").WithPartyID("1")
 	h := mgr.NewHasher("test", opts)
 
 	h1 := h.Clone()
@@ -123,3 +120,31 @@ func TestHash_Clone(t *testing.T) {
 	hashed = h.Sum()
 	fmt.Printf("hashed: %x\n", hashed)
 }
+
+func TestHash_WithDomain(t *testing.T) {
+	v := vault.NewInMemoryVault()
+	kr := keyopts.NewInMemoryKeyOpts()
+	hs := keystore.NewInMemoryKeystore(v, kr)
+	mgr := NewHashManager(hs)
+
+	opts := keyopts.New().WithKeyID("123").WithPartyID("1")
+	h := mgr.NewHasher("test", opts)
+	assert.NoError(t, h.WriteAny([]byte("session")))
+	stored := h.(*Hash).Transcript()
+
+	a := h.WithDomain("ID", []byte("a"))
+	assert.NotEqual(t, a.Sum(), h.WithDomain("ID", []byte("b")).Sum())
+	assert.Equal(t, a.Sum(), h.Clone().WithDomain("ID", []byte("a")).Sum())
+
+	// the branch does not change the session transcript
+	restored, err := mgr.RestoreHasher("test", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, stored, restored.(*Hash).Transcript())
+	assert.Equal(t, h.Sum(), restored.Sum())
+
+	// the branch is recorded in its own transcript, and matches a segment for the party
+	assert.Equal(t, len(stored)+1, a.(*Hash).Offset())
+	seg, err := stored.Segment(len(stored), "a").Hash()
+	assert.NoError(t, err)
+	assert.Equal(t, a.Sum(), seg.Sum())
+}
@@ -0,0 +1,70 @@
+package hash
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	core_hash "github.com/mr-shifu/mpc-lib/core/hash"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	comm_hash "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
+	"github.com/zeebo/blake3"
+)
+
+var ErrInvalidSegment = errors.New("hash: invalid transcript segment")
+
+// Transcript is the recorded sequence of writes to a session hash, as persisted in its keystore.
+type Transcript []core_hash.BytesWithDomain
+
+// UnmarshalTranscript decodes a transcript as persisted in the keystore of a session hash.
+func UnmarshalTranscript(data []byte) (Transcript, error) {
+	var t Transcript
+	if err := cbor.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSegment, err)
+	}
+	return t, nil
+}
+
+// Transcript returns the writes to the hash so far.
+func (hash *Hash) Transcript() Transcript {
+	return append(Transcript(nil), hash.state...)
+}
+
+// Offset returns the number of writes to the hash so far, to be recorded along with a proof
+// created with it, so that it can later be verified with a Segment.
+func (hash *Hash) Offset() int {
+	return len(hash.state)
+}
+
+// Segment references the state of a session hash after the first Offset writes of its transcript.
+//
+// Proofs are usually created with round.Helper.HashForID, which writes the id of the prover
+// to a clone of the session hash: ID must then be set to that party.
+type Segment struct {
+	Transcript Transcript
+	Offset     int
+	ID         party.ID
+}
+
+// Segment returns a reference to the state of the session hash after offset writes.
+func (t Transcript) Segment(offset int, id party.ID) *Segment {
+	return &Segment{Transcript: t, Offset: offset, ID: id}
+}
+
+// Hash implements comm_hash.Segment, by replaying the writes of the segment.
+// The returned hash is not backed by a keystore.
+func (s *Segment) Hash() (comm_hash.Hash, error) {
+	if s.Offset < 0 || s.Offset > len(s.Transcript) {
+		return nil, fmt.Errorf("%w: offset %d out of %d writes", ErrInvalidSegment, s.Offset, len(s.Transcript))
+	}
+	hash := &Hash{h: blake3.New()}
+	_, _ = hash.h.WriteString("CMP-BLAKE")
+	hash.state = append(hash.state, s.Transcript[:s.Offset]...)
+	for _, d := range hash.state {
+		hash.writeBytesWithDomain(d)
+	}
+	if s.ID != "" {
+		return hash.WithDomain(s.ID.Domain(), []byte(s.ID)), nil
+	}
+	return hash, nil
+}
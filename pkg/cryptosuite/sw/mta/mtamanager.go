@@ -19,6 +19,9 @@ func NewMtAManager(store keystore.Keystore) *MtAManager {
 }
 
 func (m *MtAManager) Get(opts keyopts.Options) (comm_mta.MtA, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	b, err := m.store.Get(opts)
 	if err != nil {
 		return nil, err
@@ -27,6 +30,9 @@ func (m *MtAManager) Get(opts keyopts.Options) (comm_mta.MtA, error) {
 }
 
 func (m *MtAManager) Import(key comm_mta.MtA, opts keyopts.Options) error {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return err
+	}
 	b, err := key.Bytes()
 	if err != nil {
 		return err
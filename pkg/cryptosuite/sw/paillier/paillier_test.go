@@ -24,8 +24,7 @@ func TestPaillier(t *testing.T) {
 	mgr := NewPaillierKeyManager(ks, pl)
 
 	// generate a new Paillier key pair
-	opts := keyopts.Options{}
-	opts.Set("ID", 123, "partyID", 1)
+	opts := keyopts.New().WithKeyID("123").WithPartyID("1")
 	key, err := mgr.GenerateKey(opts)
 	assert.NoError(t, err)
 
@@ -14,14 +14,14 @@ func (k PaillierKey) NewZKFACProof(hash hash.Hash, public zkfac.Public) *zkfac.P
 	Nhat := public.Aux.NArith()
 
 	// Figure 28, point 1.
-	alpha := sample.IntervalLEpsRootN(rand.Reader)
-	beta := sample.IntervalLEpsRootN(rand.Reader)
+	alpha := sample.IntervalLEpsRootN(rand.Reader, public.Profile)
+	beta := sample.IntervalLEpsRootN(rand.Reader, public.Profile)
 	mu := sample.IntervalLN(rand.Reader)
 	nu := sample.IntervalLN(rand.Reader)
 	sigma := sample.IntervalLN2(rand.Reader)
-	r := sample.IntervalLEpsN2(rand.Reader)
-	x := sample.IntervalLEpsN(rand.Reader)
-	y := sample.IntervalLEpsN(rand.Reader)
+	r := sample.IntervalLEpsN2(rand.Reader, public.Profile)
+	x := sample.IntervalLEpsN(rand.Reader, public.Profile)
+	y := sample.IntervalLEpsN(rand.Reader, public.Profile)
 
 	pInt := new(saferith.Int).SetNat(k.secretKey.P())
 	qInt := new(saferith.Int).SetNat(k.secretKey.Q())
@@ -110,11 +110,11 @@ func (k PaillierKey) VerifyZKFAC(p *zkfac.Proof, public zkfac.Public, hash hash.
 	}
 
 	// DEVIATION: for the bounds to work, we add an extra bit, to ensure that we don't have spurious failures.
-	return arith.IsInIntervalLEpsPlus1RootN(p.Z1) && arith.IsInIntervalLEpsPlus1RootN(p.Z2)
+	return arith.IsInIntervalLEpsPlus1RootN(p.Z1, public.Profile) && arith.IsInIntervalLEpsPlus1RootN(p.Z2, public.Profile)
 }
 
 func zkfac_challenge(hash hash.Hash, public zkfac.Public, commitment zkfac.Commitment) (*saferith.Int, error) {
-	err := hash.WriteAny(public.N, public.Aux, commitment.P, commitment.Q, commitment.A, commitment.B, commitment.T)
+	err := hash.WriteAny(public, commitment.P, commitment.Q, commitment.A, commitment.B, commitment.T)
 	if err != nil {
 		return nil, err
 	}
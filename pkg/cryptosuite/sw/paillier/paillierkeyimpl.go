@@ -79,6 +79,11 @@ func (k PaillierKey) PublicKeyRaw() *pailliercore.PublicKey {
 	return k.publicKey
 }
 
+// SecretKeyRaw returns the secret key, or nil if the key is public.
+func (k PaillierKey) SecretKeyRaw() *pailliercore.SecretKey {
+	return k.secretKey
+}
+
 // Modulus returns the modulus of the key.
 func (k PaillierKey) Modulus() *arith.Modulus {
 	return k.publicKey.Modulus()
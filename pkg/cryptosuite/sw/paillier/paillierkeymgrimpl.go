@@ -26,8 +26,16 @@ func NewPaillierKeyManager(store keystore.Keystore, pl *pool.Pool) *PaillierKeyM
 
 // GenerateKey generates a new Paillier key pair.
 func (mgr *PaillierKeyManager) GenerateKey(opts keyopts.Options) (comm_paillier.PaillierKey, error) {
+	return mgr.GenerateKeyWithProgress(opts, nil)
+}
+
+// GenerateKeyWithProgress generates a new Paillier key pair, calling progress after each attempt at finding one of its primes.
+func (mgr *PaillierKeyManager) GenerateKeyWithProgress(opts keyopts.Options, progress func(pailliercore.Progress)) (comm_paillier.PaillierKey, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	// generate a new Paillier key pair
-	pk, sk := pailliercore.KeyGen(mgr.pl)
+	pk, sk := pailliercore.KeyGenWithProgress(mgr.pl, progress)
 	key := PaillierKey{sk, pk}
 
 	// get binary encoded of secret key params (P, Q)
@@ -50,6 +58,9 @@ func (mgr *PaillierKeyManager) GenerateKey(opts keyopts.Options) (comm_paillier.
 
 // GetKey returns a Paillier key by its SKI.
 func (mgr *PaillierKeyManager) GetKey(opts keyopts.Options) (comm_paillier.PaillierKey, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	// get the key from the keystore
 	// keyID := hex.EncodeToString(ski)
 	decoded, err := mgr.keystore.Get(opts)
@@ -68,6 +79,9 @@ func (mgr *PaillierKeyManager) GetKey(opts keyopts.Options) (comm_paillier.Paill
 
 // ImportKey imports a Paillier key from its byte representation.
 func (mgr *PaillierKeyManager) ImportKey(raw interface{}, opts keyopts.Options) (comm_paillier.PaillierKey, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	var err error
 	var key PaillierKey
 
@@ -18,6 +18,9 @@ func NewPaillierEncodedKeyManager(store keystore.Keystore) *PaillierEncodedKeyMa
 }
 
 func (k *PaillierEncodedKeyManager) Get(opts keyopts.Options) (pek.PaillierEncodedKey, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	b, err := k.store.Get(opts)
 	if err != nil {
 		return nil, err
@@ -26,6 +29,9 @@ func (k *PaillierEncodedKeyManager) Get(opts keyopts.Options) (pek.PaillierEncod
 }
 
 func (k *PaillierEncodedKeyManager) Import(raw interface{}, opts keyopts.Options) (pek.PaillierEncodedKey, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	var err error
 	var key PaillierEncodedKey
 
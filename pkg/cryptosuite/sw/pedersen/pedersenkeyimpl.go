@@ -12,6 +12,7 @@ import (
 
 var (
 	ErrEmptyEncodedData = errors.New("encoded secret has empty data")
+	ErrPublicKey        = errors.New("pedersen: key has no secret")
 )
 
 type PedersenKey struct {
@@ -21,7 +22,9 @@ type PedersenKey struct {
 
 type rawPedersenKey struct {
 	Secret []byte
-	Public []byte
+	// Factors are the factors of N, which are only stored with Secret.
+	Factors []byte
+	Public  []byte
 }
 
 func NewPedersenKey(s *saferith.Nat, p *pedersencore.Parameters) PedersenKey {
@@ -33,7 +36,7 @@ func NewPedersenKey(s *saferith.Nat, p *pedersencore.Parameters) PedersenKey {
 
 // Bytes returns the byte representation of the key.
 func (k PedersenKey) Bytes() ([]byte, error) {
-	// pkb, err := k.public.MarshalBiinary()
+	// pkb, err := k.public.MarshalBinary()
 	// if err != nil {
 	// 	return nil, err
 	// }
@@ -62,9 +65,15 @@ func (k PedersenKey) Bytes() ([]byte, error) {
 			return nil, err
 		}
 		raw.Secret = skb
+
+		factors, err := k.public.MarshalFactorsBinary()
+		if err != nil && !errors.Is(err, pedersencore.ErrNoFactors) {
+			return nil, err
+		}
+		raw.Factors = factors
 	}
 
-	pkb, err := k.public.MarshalBiinary()
+	pkb, err := k.public.MarshalBinary()
 	if err != nil {
 		return nil, err
 	}
@@ -74,13 +83,12 @@ func (k PedersenKey) Bytes() ([]byte, error) {
 }
 
 // SKI returns the serialized key identifier.
+// It only depends on N, S and T, so that it is the same whether the factorization of N is known or not.
 func (k PedersenKey) SKI() []byte {
-	pbs, err := k.public.MarshalBiinary()
-	if err != nil {
+	hash := sha256.New()
+	if _, err := k.public.WriteTo(hash); err != nil {
 		return nil
 	}
-	hash := sha256.New()
-	hash.Write(pbs)
 	return hash.Sum(nil)
 }
 
@@ -128,9 +136,14 @@ func fromBytes(data []byte) (PedersenKey, error) {
 	}
 
 	p := new(pedersencore.Parameters)
-	if err := p.UnmarshalBiinary(raw.Public); err != nil {
+	if err := p.UnmarshalBinary(raw.Public); err != nil {
 		return PedersenKey{}, err
 	}
+	if key.secret != nil && len(raw.Factors) != 0 {
+		if err := p.UnmarshalFactorsBinary(raw.Factors); err != nil {
+			return PedersenKey{}, err
+		}
+	}
 	key.public = p
 
 	return key, nil
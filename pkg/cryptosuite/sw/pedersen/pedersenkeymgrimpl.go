@@ -3,9 +3,13 @@ package pedersen
 import (
 	"encoding/hex"
 	"errors"
+	"sync"
 
 	"github.com/cronokirby/saferith"
 	"github.com/mr-shifu/mpc-lib/core/pedersen"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	zkprm "github.com/mr-shifu/mpc-lib/core/zk/prm"
+	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
 	comm_pedersen "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/pedersen"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
 	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
@@ -13,6 +17,11 @@ import (
 
 type PedersenKeyManager struct {
 	ks keystore.Keystore
+
+	// precomputed holds the pools of commitment randomness by keyID, when precomputeSize > 0.
+	mtx            sync.Mutex
+	precomputeSize int
+	precomputed    map[string]*pedersen.Precomputed
 }
 
 func NewPedersenKeymanager(ks keystore.Keystore) *PedersenKeyManager {
@@ -28,6 +37,9 @@ func (mgr *PedersenKeyManager) GenerateKey(opts keyopts.Options) (comm_pedersen.
 
 // ImportKey imports a Pedersen key.
 func (mgr *PedersenKeyManager) ImportKey(raw interface{}, opts keyopts.Options) (comm_pedersen.PedersenKey, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	var err error
 	var key PedersenKey
 
@@ -68,6 +80,9 @@ func (mgr *PedersenKeyManager) ImportKey(raw interface{}, opts keyopts.Options)
 
 // GetKey returns a Pedersen key by its SKI.
 func (mgr *PedersenKeyManager) GetKey(opts keyopts.Options) (comm_pedersen.PedersenKey, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	// retreive key from keystore
 	kb, err := mgr.ks.Get(opts)
 	if err != nil {
@@ -75,7 +90,58 @@ func (mgr *PedersenKeyManager) GetKey(opts keyopts.Options) (comm_pedersen.Peder
 	}
 
 	// decode key from binary
-	return fromBytes(kb)
+	key, err := fromBytes(kb)
+	if err != nil {
+		return PedersenKey{}, err
+	}
+	if pc := mgr.precomputedFor(key); pc != nil {
+		key.public = key.public.WithPrecomputed(pc)
+	}
+	return key, nil
+}
+
+// SetPrecompute enables a pool of up to size precomputed commitment randomness for each key, filled by Precompute
+// and consumed by the proofs committing with the key, such as those of the sign rounds. A size of 0 disables the pools.
+func (mgr *PedersenKeyManager) SetPrecompute(size int) {
+	mgr.mtx.Lock()
+	defer mgr.mtx.Unlock()
+	mgr.precomputeSize = size
+	if size <= 0 {
+		mgr.precomputed = nil
+	}
+}
+
+// Precompute fills the pool of the key in pl, and returns the number of randomness added.
+// It should be called while the node is idle, so that the exponentiations are moved out of the protocol rounds.
+func (mgr *PedersenKeyManager) Precompute(pl *pool.Pool, opts keyopts.Options) (int, error) {
+	key, err := mgr.GetKey(opts)
+	if err != nil {
+		return 0, err
+	}
+	pc := mgr.precomputedFor(key.(PedersenKey))
+	if pc == nil {
+		return 0, nil
+	}
+	return pc.Fill(pl), nil
+}
+
+// precomputedFor returns the pool of key, creating it if needed, or nil if precomputation is disabled.
+func (mgr *PedersenKeyManager) precomputedFor(key PedersenKey) *pedersen.Precomputed {
+	mgr.mtx.Lock()
+	defer mgr.mtx.Unlock()
+	if mgr.precomputeSize <= 0 {
+		return nil
+	}
+	if mgr.precomputed == nil {
+		mgr.precomputed = make(map[string]*pedersen.Precomputed)
+	}
+	keyID := hex.EncodeToString(key.SKI())
+	pc, ok := mgr.precomputed[keyID]
+	if !ok {
+		pc = pedersen.NewPrecomputed(key.public, mgr.precomputeSize)
+		mgr.precomputed[keyID] = pc
+	}
+	return pc
 }
 
 // Commit returns the commitment of the given value.
@@ -95,3 +161,25 @@ func (mgr *PedersenKeyManager) Verify(a, b, e *saferith.Int, S, T *saferith.Nat,
 	}
 	return key.Verify(a, b, e, S, T)
 }
+
+// NewProof returns the zkprm proof that the Pedersen parameters of the stored key generate the same group,
+// using its secret λ. It fails if only the public part of the key is stored.
+func (mgr *PedersenKeyManager) NewProof(hash hash.Hash, pl *pool.Pool, opts keyopts.Options) (*zkprm.Proof, error) {
+	key, err := mgr.GetKey(opts)
+	if err != nil {
+		return nil, err
+	}
+	if !key.Private() {
+		return nil, ErrPublicKey
+	}
+	return key.NewProof(hash, pl), nil
+}
+
+// VerifyProof returns true if p is a valid zkprm proof for the Pedersen parameters of the stored key.
+func (mgr *PedersenKeyManager) VerifyProof(hash hash.Hash, pl *pool.Pool, p *zkprm.Proof, opts keyopts.Options) bool {
+	key, err := mgr.GetKey(opts)
+	if err != nil {
+		return false
+	}
+	return key.VerifyProof(hash, pl, p)
+}
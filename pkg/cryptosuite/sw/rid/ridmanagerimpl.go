@@ -21,6 +21,9 @@ func NewRIDManager(ks keystore.Keystore) *RIDManager {
 
 // GenerateKey generates a new RID key pair.
 func (mgr *RIDManager) GenerateKey(opts keyopts.Options) (cs_rid.RID, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	r, err := types.NewRID(rand.Reader)
 	if err != nil {
 		return nil, err
@@ -39,6 +42,9 @@ func (mgr *RIDManager) GenerateKey(opts keyopts.Options) (cs_rid.RID, error) {
 
 // Import imports a RID key from its byte representation.
 func (mgr *RIDManager) ImportKey(data []byte, opts keyopts.Options) (cs_rid.RID, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	// validate data as rid
 	if err := types.RID(data).Validate(); err != nil {
 		return nil, err
@@ -56,6 +62,9 @@ func (mgr *RIDManager) ImportKey(data []byte, opts keyopts.Options) (cs_rid.RID,
 
 // GetKey returns a RID key by its SKI.
 func (mgr *RIDManager) GetKey(opts keyopts.Options) (cs_rid.RID, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	r, err := mgr.ks.Get(opts)
 	if err != nil {
 		return nil, err
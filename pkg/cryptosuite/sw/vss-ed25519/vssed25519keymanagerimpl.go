@@ -23,6 +23,9 @@ func NewVssKeyManager(ks keystore.Keystore) *VssKeyManagerImpl {
 // GenerateSecrets generates a Polynomail of a specified degree with secret as constant value
 // and stores coefficients and expponents of coefficients.
 func (mgr *VssKeyManagerImpl) GenerateSecrets(secret *ed.Scalar, degree int, opts keyopts.Options) (VssKey, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	// Generate a polynomial with secret as constant value
 	poly, err := polynomial.GeneratePolynomial(degree, secret)
 	if err != nil {
@@ -52,6 +55,9 @@ func (mgr *VssKeyManagerImpl) GenerateSecrets(secret *ed.Scalar, degree int, opt
 
 // ImportSecrets imports exponents of coefficients and returns VssKey.
 func (mgr *VssKeyManagerImpl) ImportSecrets(key any, opts keyopts.Options) (VssKey, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	switch kt := key.(type) {
 	case []byte:
 		k := new(VssKeyImpl)
@@ -92,6 +98,9 @@ func (mgr *VssKeyManagerImpl) ImportSecrets(key any, opts keyopts.Options) (VssK
 
 // GetSecrets returns VssKey of coefficients.
 func (mgr *VssKeyManagerImpl) GetSecrets(opts keyopts.Options) (VssKey, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	vb, err := mgr.ks.Get(opts)
 	if err != nil {
 		return nil, errors.WithMessage(err, "vss: failed to get key")
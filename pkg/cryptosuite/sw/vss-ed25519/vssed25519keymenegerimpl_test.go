@@ -28,8 +28,7 @@ func TestVssEd25519VssKeyManager(t *testing.T) {
 	degree := 5
 
 	// Test Case 1: GenerateSecrets
-	opts := keyopts.Options{}
-	opts.Set("id", "1", "partyid", "a")
+	opts := keyopts.New().WithKeyID("1").WithPartyID("a")
 	vss1, err := mgr1.GenerateSecrets(constant, degree, opts)
 	assert.NoError(t, err)
 	assert.NotNil(t, vss1)
@@ -62,8 +61,7 @@ func TestVssEd25519VssKeyManager_Evaluate(t *testing.T) {
 
 	degree := 5
 
-	opts := keyopts.Options{}
-	opts.Set("id", "1", "partyid", "a")
+	opts := keyopts.New().WithKeyID("1").WithPartyID("a")
 	vss1, err := mgr1.GenerateSecrets(constant, degree, opts)
 	assert.NoError(t, err)
 	assert.NotNil(t, vss1)
@@ -90,7 +88,7 @@ func TestVssEd25519VssKeyManager_Evaluate(t *testing.T) {
 	assert.Equal(t, V, V1)
 
 	// Test Case 3: Evaluate with invalid partyid
-	opts.Set("id", "1", "partyid", "b")
+	opts = keyopts.New().WithKeyID("1").WithPartyID("b")
 	_, err = mgr1.Evaluate(x, opts)
 	assert.Error(t, err)
 }
@@ -109,18 +107,15 @@ func TestVssEd25519VssKeyManager_SumExponents(t *testing.T) {
 	degree := 5
 
 	// generate Vss for secrets
-	opts1 := keyopts.Options{}
-	opts1.Set("id", "1", "partyid", "a")
+	opts1 := keyopts.New().WithKeyID("1").WithPartyID("a")
 	vss1, err := mgr1.GenerateSecrets(s1, degree, opts1)
 	assert.NoError(t, err)
 
-	opts2 := keyopts.Options{}
-	opts2.Set("id", "1", "partyid", "b")
+	opts2 := keyopts.New().WithKeyID("1").WithPartyID("b")
 	vss2, err := mgr1.GenerateSecrets(s2, degree, opts2)
 	assert.NoError(t, err)
 
-	opts3 := keyopts.Options{}
-	opts3.Set("id", "1", "partyid", "c")
+	opts3 := keyopts.New().WithKeyID("1").WithPartyID("c")
 	vss3, err := mgr1.GenerateSecrets(s3, degree, opts3)
 	assert.NoError(t, err)
 
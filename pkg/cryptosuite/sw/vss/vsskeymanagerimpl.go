@@ -26,6 +26,9 @@ func NewVssKeyManager(store keystore.Keystore, g curve.Curve) *VssKeyManager {
 // GenerateSecrets generates a Polynomail of a specified degree with secret as constant value
 // and stores coefficients and expponents of coefficients.
 func (mgr *VssKeyManager) GenerateSecrets(secret curve.Scalar, degree int, opts keyopts.Options) (comm_vss.VssKey, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	// Generate a polynomial with secret as constant value
 	secrets := polynomial.NewPolynomial(mgr.group, degree, secret)
 	// Generate exponents of coefficients
@@ -61,6 +64,9 @@ func (mgr *VssKeyManager) GenerateSecrets(secret curve.Scalar, degree int, opts
 
 // ImportSecrets imports exponents of coefficients in []byte format and returns VssKey.
 func (mgr *VssKeyManager) ImportSecrets(key comm_vss.VssKey, opts keyopts.Options) (comm_vss.VssKey, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	// if data == nil {
 	// 	return nil, errors.New("invalid exponents")
 	// }
@@ -101,6 +107,9 @@ func (mgr *VssKeyManager) ImportSecrets(key comm_vss.VssKey, opts keyopts.Option
 
 // GetSecrets returns VssKey of coefficients.
 func (mgr *VssKeyManager) GetSecrets(opts keyopts.Options) (comm_vss.VssKey, error) {
+	if err := keyopts.RequireKey(opts); err != nil {
+		return nil, err
+	}
 	// encode ski to hex string as keyID
 	// keyID := hex.EncodeToString(ski)
 
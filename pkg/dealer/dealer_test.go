@@ -0,0 +1,163 @@
+package dealer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/test"
+	comm_ecdsa "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/ecdsa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePrivateKey(t *testing.T) {
+	x := sample.Scalar(rand.Reader, curve.Secp256k1{})
+	raw, err := x.MarshalBinary()
+	require.NoError(t, err)
+	public, err := x.ActOnBase().MarshalBinary()
+	require.NoError(t, err)
+
+	sec1, err := asn1.Marshal(ecPrivateKey{
+		Version:       1,
+		PrivateKey:    raw,
+		NamedCurveOID: oidSecp256k1,
+		PublicKey:     asn1.BitString{Bytes: public, BitLength: 8 * len(public)},
+	})
+	require.NoError(t, err)
+	params, err := asn1.Marshal(oidSecp256k1)
+	require.NoError(t, err)
+	p8, err := asn1.Marshal(pkcs8{
+		Algo:       pkcs8Algorithm{Algorithm: oidPublicKeyECDSA, Parameters: asn1.RawValue{FullBytes: params}},
+		PrivateKey: sec1,
+	})
+	require.NoError(t, err)
+
+	for name, data := range map[string][]byte{
+		"hex":       []byte(hex.EncodeToString(raw)),
+		"0x hex":    []byte("0x" + hex.EncodeToString(raw) + "\n"),
+		"SEC1 DER":  sec1,
+		"PKCS8 DER": p8,
+		"SEC1 PEM":  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1}),
+		"PKCS8 PEM": pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: p8}),
+	} {
+		parsed, err := ParsePrivateKey(data)
+		require.NoError(t, err, name)
+		assert.True(t, x.Equal(parsed), name)
+	}
+
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(p256)
+	require.NoError(t, err)
+	_, err = ParsePrivateKey(der)
+	assert.ErrorIs(t, err, ErrUnsupportedCurve)
+
+	_, err = ParsePrivateKey(make([]byte, 64))
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestParsePrivateKeyTrailingWhitespace(t *testing.T) {
+	// the SEC1 DER of a key ends with its public key, which may end with a byte TrimSpace removes
+	var x curve.Scalar
+	var public []byte
+	for public == nil || public[len(public)-1] != '\n' {
+		x = sample.Scalar(rand.Reader, curve.Secp256k1{})
+		var err error
+		public, err = x.ActOnBase().MarshalBinary()
+		require.NoError(t, err)
+	}
+	raw, err := x.MarshalBinary()
+	require.NoError(t, err)
+	sec1, err := asn1.Marshal(ecPrivateKey{
+		Version:       1,
+		PrivateKey:    raw,
+		NamedCurveOID: oidSecp256k1,
+		PublicKey:     asn1.BitString{Bytes: public, BitLength: 8 * len(public)},
+	})
+	require.NoError(t, err)
+	require.Equal(t, byte('\n'), sec1[len(sec1)-1])
+
+	parsed, err := ParsePrivateKey(sec1)
+	require.NoError(t, err)
+	assert.True(t, x.Equal(parsed))
+}
+
+func TestShard(t *testing.T) {
+	group := curve.Secp256k1{}
+	x := sample.Scalar(rand.Reader, group)
+	ids := test.PartyIDs(5)
+
+	s, err := Shard(x, 2, ids)
+	require.NoError(t, err)
+	for _, id := range ids {
+		report := comm_ecdsa.CheckShare(s.Shares[id].ActOnBase(), s.Expectation(id))
+		assert.NoError(t, report.Err())
+	}
+
+	// any threshold+1 shares recover the key
+	quorum := ids[1:4]
+	l := polynomial.Lagrange(group, quorum)
+	recovered := group.NewScalar()
+	for _, id := range quorum {
+		recovered.Add(group.NewScalar().Set(l[id]).Mul(s.Shares[id]))
+	}
+	assert.True(t, x.Equal(recovered))
+
+	_, err = Shard(x, 5, ids)
+	assert.Error(t, err)
+}
+
+func TestMigrate(t *testing.T) {
+	group := curve.Secp256k1{}
+	x := sample.Scalar(rand.Reader, group)
+	old, err := Shard(x, 1, test.PartyIDs(3))
+	require.NoError(t, err)
+
+	quorum := map[party.ID]curve.Scalar{}
+	for _, id := range test.PartyIDs(3)[1:] {
+		quorum[id] = old.Shares[id]
+	}
+	newIDs := test.PartyIDs(5)
+	s, err := Migrate(quorum, old.PublicShares, 1, 2, newIDs)
+	require.NoError(t, err)
+	assert.True(t, s.PublicKey.Equal(old.PublicKey))
+	for _, id := range newIDs {
+		assert.NoError(t, comm_ecdsa.CheckShare(s.Shares[id].ActOnBase(), s.Expectation(id)).Err())
+	}
+
+	_, err = Combine(map[party.ID]curve.Scalar{"a": old.Shares["a"]}, old.PublicShares, 1)
+	assert.ErrorIs(t, err, ErrNotEnoughShares)
+	quorum["b"] = sample.Scalar(rand.Reader, group)
+	_, err = Combine(quorum, old.PublicShares, 1)
+	assert.ErrorIs(t, err, ErrInvalidShare)
+}
+
+func TestEquivalence(t *testing.T) {
+	group := curve.Secp256k1{}
+	x := sample.Scalar(rand.Reader, group)
+	H := sample.Scalar(rand.Reader, group).ActOnBase()
+
+	e, err := ProveEquivalence(x, H)
+	require.NoError(t, err)
+	assert.NoError(t, e.Verify(x.ActOnBase()))
+	assert.ErrorIs(t, e.Verify(sample.Scalar(rand.Reader, group).ActOnBase()), ErrInvalidEquivalence)
+}
+
+func TestExportPrivateKey(t *testing.T) {
+	x := sample.Scalar(rand.Reader, curve.Secp256k1{})
+	data, err := ExportPrivateKey(x)
+	require.NoError(t, err)
+	parsed, err := ParsePrivateKey(data)
+	require.NoError(t, err)
+	assert.True(t, x.Equal(parsed))
+}
@@ -0,0 +1,144 @@
+package dealer
+
+import (
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/mr-shifu/mpc-lib/core/hash"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	zkdleq "github.com/mr-shifu/mpc-lib/core/zk/dleq"
+)
+
+var (
+	ErrInvalidShare       = errors.New("dealer: invalid share")
+	ErrNotEnoughShares    = errors.New("dealer: not enough shares")
+	ErrInvalidEquivalence = errors.New("dealer: invalid equivalence proof")
+)
+
+// Combine recovers the private key x from the shares xⱼ of at least threshold+1 parties of a committee,
+// such as the ECDSA shares of their configs. Each share is checked against the public shares Xⱼ = xⱼ⋅G
+// of the committee, which also give the public key the recovered key is checked against.
+func Combine(shares map[party.ID]curve.Scalar, publicShares map[party.ID]curve.Point, threshold int) (curve.Scalar, error) {
+	if len(shares) < threshold+1 {
+		return nil, fmt.Errorf("%w: %d shares for threshold %d", ErrNotEnoughShares, len(shares), threshold)
+	}
+	quorum := make([]party.ID, 0, len(shares))
+	for j, share := range shares {
+		X, ok := publicShares[j]
+		if !ok || share == nil || !share.ActOnBase().Equal(X) {
+			return nil, fmt.Errorf("%w: share of %s", ErrInvalidShare, j)
+		}
+		quorum = append(quorum, j)
+	}
+
+	committee := make([]party.ID, 0, len(publicShares))
+	for j := range publicShares {
+		committee = append(committee, j)
+	}
+	var group curve.Curve
+	for _, share := range shares {
+		group = share.Curve()
+		break
+	}
+
+	// x = ∑ⱼ λⱼ⋅xⱼ over the quorum, and X = ∑ⱼ λⱼ⋅Xⱼ over the whole committee
+	x := group.NewScalar()
+	for j, l := range polynomial.Lagrange(group, quorum) {
+		x.Add(l.Mul(shares[j]))
+	}
+	X := group.NewPoint()
+	for j, l := range polynomial.Lagrange(group, committee) {
+		X = X.Add(l.Act(publicShares[j]))
+	}
+	if x.IsZero() || !x.ActOnBase().Equal(X) {
+		return nil, fmt.Errorf("%w: public shares are not of degree %d", ErrInvalidShare, threshold)
+	}
+	return x, nil
+}
+
+// Migrate re-shards the key of a committee to a new one, such as a 2-of-3 committee to a 3-of-5 committee,
+// by combining the shares of a quorum of the old committee with Combine, and sharding the key with Shard.
+//
+// The public key is unchanged, so that addresses and signatures remain valid: the new parties check their
+// shares against the returned Sharding, whose PublicKey must be the public key of the old committee.
+func Migrate(shares map[party.ID]curve.Scalar, publicShares map[party.ID]curve.Point, oldThreshold, threshold int, parties []party.ID) (*Sharding, error) {
+	x, err := Combine(shares, publicShares, oldThreshold)
+	if err != nil {
+		return nil, err
+	}
+	return Shard(x, threshold, parties)
+}
+
+// Equivalence proves that the private key x of X = x⋅G is also the key of Y = x⋅H, for a key exported
+// to a system using another generator H of the same curve, without revealing x.
+//
+// Keys exported to another curve cannot be proven equivalent this way: the groups have different orders.
+type Equivalence struct {
+	// H is the generator of the other system.
+	H curve.Point
+	// Y = x⋅H
+	Y curve.Point
+	// Proof is a proof of equal discrete logarithm of X and Y.
+	Proof *zkdleq.Proof
+}
+
+// ProveEquivalence returns the key x⋅H of x for the generator H, and the proof that it has the same discrete log as x⋅G.
+func ProveEquivalence(x curve.Scalar, H curve.Point) (*Equivalence, error) {
+	if x == nil || x.IsZero() {
+		return nil, ErrInvalidKey
+	}
+	if H == nil || H.IsIdentity() {
+		return nil, fmt.Errorf("%w: generator is the identity", ErrInvalidEquivalence)
+	}
+	public := zkdleq.Public{H: H, X: x.ActOnBase(), Y: x.Act(H)}
+	proof := zkdleq.NewProof(x.Curve(), equivalenceHash(x.Curve()), public, zkdleq.Private{A: x})
+	return &Equivalence{H: H, Y: public.Y, Proof: proof}, nil
+}
+
+// Verify returns ErrInvalidEquivalence if e does not prove that Y has the same discrete log as X.
+func (e *Equivalence) Verify(X curve.Point) error {
+	if e == nil || e.H == nil || e.Y == nil || e.Proof == nil || X == nil {
+		return fmt.Errorf("%w: missing fields", ErrInvalidEquivalence)
+	}
+	if !e.Proof.Verify(equivalenceHash(X.Curve()), zkdleq.Public{H: e.H, X: X, Y: e.Y}) {
+		return ErrInvalidEquivalence
+	}
+	return nil
+}
+
+func equivalenceHash(group curve.Curve) *hash.Hash {
+	return hash.New(hash.BytesWithDomain{TheDomain: "Dealer Equivalence", Bytes: []byte(group.Name())})
+}
+
+// ExportPrivateKey encodes the secp256k1 private key x as a PEM encoded SEC1 key,
+// for use by other libraries after the key was recovered with Combine. ParsePrivateKey decodes it.
+func ExportPrivateKey(x curve.Scalar) ([]byte, error) {
+	if x == nil || x.IsZero() {
+		return nil, ErrInvalidKey
+	}
+	if _, ok := x.Curve().(curve.Secp256k1); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurve, x.Curve().Name())
+	}
+	raw, err := x.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	public, err := x.ActOnBase().MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	der, err := asn1.Marshal(ecPrivateKey{
+		Version:       1,
+		PrivateKey:    raw,
+		NamedCurveOID: oidSecp256k1,
+		PublicKey:     asn1.BitString{Bytes: public, BitLength: 8 * len(public)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+}
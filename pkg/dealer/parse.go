@@ -0,0 +1,157 @@
+// Package dealer shards an existing private key between the parties of a committee, as a trusted dealer.
+//
+// It is meant for migrating keys from conventional wallets, or between committees with Migrate: the dealer
+// learns the whole key, and should be run offline and erased afterwards. Each party checks its share with
+// ImportVerifiedKey before using it.
+package dealer
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+)
+
+var (
+	ErrInvalidKey       = errors.New("dealer: invalid private key")
+	ErrUnsupportedCurve = errors.New("dealer: unsupported curve")
+)
+
+var (
+	// oidPublicKeyECDSA is the algorithm of EC keys in PKCS#8 (RFC 5480).
+	oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	// oidSecp256k1 is the named curve secp256k1 (SEC 2).
+	oidSecp256k1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+// ecPrivateKey is the SEC1 encoding of an EC private key (RFC 5915).
+type ecPrivateKey struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+// pkcs8 is the PKCS#8 encoding of a private key (RFC 5208).
+type pkcs8 struct {
+	Version    int
+	Algo       pkcs8Algorithm
+	PrivateKey []byte
+}
+
+type pkcs8Algorithm struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+// ParsePrivateKey decodes a secp256k1 private key in one of the following formats:
+//
+//   - PEM, with a "PRIVATE KEY" (PKCS#8) or "EC PRIVATE KEY" (SEC1) block
+//   - DER encoded PKCS#8 or SEC1
+//   - 32 bytes hex, with an optional 0x prefix
+//
+// Keys over other curves are rejected with ErrUnsupportedCurve.
+func ParsePrivateKey(data []byte) (curve.Scalar, error) {
+	trimmed := bytes.TrimSpace(data)
+	if block, _ := pem.Decode(trimmed); block != nil {
+		switch block.Type {
+		case "PRIVATE KEY":
+			return parsePKCS8(block.Bytes)
+		case "EC PRIVATE KEY":
+			return parseSEC1(block.Bytes, nil)
+		default:
+			return nil, fmt.Errorf("%w: unexpected PEM block %q", ErrInvalidKey, block.Type)
+		}
+	}
+	if raw, err := hex.DecodeString(strings.TrimPrefix(string(trimmed), "0x")); err == nil {
+		return parseScalar(raw)
+	}
+	// DER is binary, and may start or end with bytes which look like whitespace
+	if key, err := parsePKCS8(data); err == nil || errors.Is(err, ErrUnsupportedCurve) {
+		return key, err
+	}
+	return parseSEC1(data, nil)
+}
+
+func parsePKCS8(der []byte) (curve.Scalar, error) {
+	var key pkcs8
+	if rest, err := asn1.Unmarshal(der, &key); err != nil || len(rest) > 0 {
+		return nil, fmt.Errorf("%w: malformed PKCS#8", ErrInvalidKey)
+	}
+	if !key.Algo.Algorithm.Equal(oidPublicKeyECDSA) {
+		return nil, fmt.Errorf("%w: PKCS#8 algorithm %v is not EC", ErrUnsupportedCurve, key.Algo.Algorithm)
+	}
+	var namedCurve asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(key.Algo.Parameters.FullBytes, &namedCurve); err != nil {
+		return nil, fmt.Errorf("%w: PKCS#8 without named curve", ErrInvalidKey)
+	}
+	return parseSEC1(key.PrivateKey, namedCurve)
+}
+
+// parseSEC1 decodes a SEC1 key, whose curve may be given by its PKCS#8 envelope instead.
+func parseSEC1(der []byte, namedCurve asn1.ObjectIdentifier) (curve.Scalar, error) {
+	var key ecPrivateKey
+	if rest, err := asn1.Unmarshal(der, &key); err != nil || len(rest) > 0 {
+		return nil, fmt.Errorf("%w: malformed SEC1", ErrInvalidKey)
+	}
+	if key.Version != 1 {
+		return nil, fmt.Errorf("%w: unknown SEC1 version %d", ErrInvalidKey, key.Version)
+	}
+	if len(key.NamedCurveOID) > 0 {
+		if len(namedCurve) > 0 && !namedCurve.Equal(key.NamedCurveOID) {
+			return nil, fmt.Errorf("%w: conflicting curves", ErrInvalidKey)
+		}
+		namedCurve = key.NamedCurveOID
+	}
+	if !namedCurve.Equal(oidSecp256k1) {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedCurve, namedCurve)
+	}
+	x, err := parseScalar(key.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// the public key is optional, but must match if given
+	if len(key.PublicKey.Bytes) > 0 {
+		public, err := x.ActOnBase().MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(compress(key.PublicKey.Bytes), public) {
+			return nil, fmt.Errorf("%w: public key does not match the private key", ErrInvalidKey)
+		}
+	}
+	return x, nil
+}
+
+// parseScalar decodes a big endian secp256k1 private key, which must be in [1, q).
+func parseScalar(raw []byte) (curve.Scalar, error) {
+	group := curve.Secp256k1{}
+	if len(raw) != 32 {
+		return nil, fmt.Errorf("%w: expected 32 bytes, got %d", ErrInvalidKey, len(raw))
+	}
+	x := group.NewScalar()
+	if err := x.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	if x.IsZero() {
+		return nil, fmt.Errorf("%w: key is zero", ErrInvalidKey)
+	}
+	return x, nil
+}
+
+// compress returns the compressed encoding of an uncompressed SEC1 point, or the point itself.
+func compress(point []byte) []byte {
+	if len(point) != 65 || point[0] != 4 {
+		return point
+	}
+	out := make([]byte, 33)
+	out[0] = 2 | (point[64] & 1)
+	copy(out[1:], point[1:33])
+	return out
+}
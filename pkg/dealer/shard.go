@@ -0,0 +1,75 @@
+package dealer
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	comm_ecdsa "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/ecdsa"
+)
+
+// Sharding is the output of the dealer: a share for each party, and the public data to check them against.
+type Sharding struct {
+	Threshold int
+	// Shares[j] = f(j), with f(0) the private key. Each share must only be sent to its party.
+	Shares map[party.ID]curve.Scalar
+	// Commitments are the Feldman commitments F(X) = f(X)⋅G.
+	Commitments *polynomial.Exponent
+	// PublicShares[j] = f(j)⋅G
+	PublicShares map[party.ID]curve.Point
+	// PublicKey = f(0)⋅G is the public key of the original private key.
+	PublicKey curve.Point
+}
+
+// Shard splits the private key x between the parties, such that any threshold+1 of them can sign.
+func Shard(x curve.Scalar, threshold int, parties []party.ID) (*Sharding, error) {
+	ids := party.NewIDSlice(parties)
+	if !ids.Valid() {
+		return nil, errors.New("dealer: parties are invalid")
+	}
+	if threshold < 0 || threshold > len(ids)-1 {
+		return nil, fmt.Errorf("dealer: threshold %d is invalid for %d parties", threshold, len(ids))
+	}
+	if x == nil || x.IsZero() {
+		return nil, ErrInvalidKey
+	}
+
+	group := x.Curve()
+	// f(X) of degree threshold with f(0) = x
+	f := polynomial.NewPolynomial(group, threshold, x)
+	s := &Sharding{
+		Threshold:    threshold,
+		Shares:       make(map[party.ID]curve.Scalar, len(ids)),
+		Commitments:  polynomial.NewPolynomialExponent(f),
+		PublicShares: make(map[party.ID]curve.Point, len(ids)),
+		PublicKey:    x.ActOnBase(),
+	}
+	for _, j := range ids {
+		share := f.Evaluate(j.Scalar(group))
+		s.Shares[j] = share
+		s.PublicShares[j] = share.ActOnBase()
+	}
+	return s, nil
+}
+
+// ShardPrivateKey parses a private key with ParsePrivateKey, and shards it with Shard.
+func ShardPrivateKey(data []byte, threshold int, parties []party.ID) (*Sharding, error) {
+	x, err := ParsePrivateKey(data)
+	if err != nil {
+		return nil, err
+	}
+	return Shard(x, threshold, parties)
+}
+
+// Expectation returns the public data party id must check its share against,
+// with ECDSAKeyManager.ImportVerifiedKey.
+func (s *Sharding) Expectation(id party.ID) comm_ecdsa.ShareExpectation {
+	return comm_ecdsa.ShareExpectation{
+		ID:           id,
+		Commitments:  s.Commitments,
+		PublicShares: s.PublicShares,
+		PublicKey:    s.PublicKey,
+	}
+}
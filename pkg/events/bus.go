@@ -0,0 +1,184 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrBusClosed = errors.New("events: bus closed")
+
+// Subscriber receives events from a Bus.
+//
+// Notify is retried until it succeeds, so that delivery is at-least-once:
+// subscribers must tolerate receiving the same event more than once.
+type Subscriber interface {
+	Notify(ctx context.Context, e Event) error
+}
+
+// SubscriberFunc adapts a function to the Subscriber interface.
+type SubscriberFunc func(ctx context.Context, e Event) error
+
+func (f SubscriberFunc) Notify(ctx context.Context, e Event) error { return f(ctx, e) }
+
+// RetryPolicy controls how failed deliveries are retried.
+type RetryPolicy struct {
+	// InitialBackoff is the delay before the first retry, doubled after each failure.
+	InitialBackoff time.Duration
+	// MaxBackoff bounds the delay between retries.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is used by NewBus.
+var DefaultRetryPolicy = RetryPolicy{
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     30 * time.Second,
+}
+
+// Bus dispatches events to registered subscribers.
+//
+// Each subscriber has its own queue and goroutine, so that a slow or failing subscriber
+// does not delay the others. Events are delivered to a given subscriber in the order they were published.
+type Bus struct {
+	retry RetryPolicy
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	subs   []*subscription
+	closed bool
+	wg     sync.WaitGroup
+	mtx    sync.Mutex
+}
+
+type subscription struct {
+	sub   Subscriber
+	queue []Event
+	cond  *sync.Cond
+}
+
+// NewBus returns a Bus using the DefaultRetryPolicy.
+func NewBus() *Bus {
+	return NewBusWithRetry(DefaultRetryPolicy)
+}
+
+// NewBusWithRetry returns a Bus retrying failed deliveries according to retry.
+func NewBusWithRetry(retry RetryPolicy) *Bus {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Bus{
+		retry:  retry,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Subscribe registers sub to receive all events published from now on.
+func (b *Bus) Subscribe(sub Subscriber) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.closed {
+		return ErrBusClosed
+	}
+	s := &subscription{sub: sub, cond: sync.NewCond(&b.mtx)}
+	b.subs = append(b.subs, s)
+	b.wg.Add(1)
+	go b.deliver(s)
+	return nil
+}
+
+// Publish queues e for delivery to all subscribers. It does not block on delivery.
+func (b *Bus) Publish(e Event) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.closed {
+		return ErrBusClosed
+	}
+	for _, s := range b.subs {
+		s.queue = append(s.queue, e)
+		s.cond.Signal()
+	}
+	return nil
+}
+
+// Close stops accepting events, and waits for queued events to be delivered until ctx is done.
+// Events which could not be delivered in time are dropped, and the context of the pending deliveries is cancelled.
+// Close then returns at once, even if a subscriber ignores the cancellation and is still blocked in Notify.
+func (b *Bus) Close(ctx context.Context) error {
+	b.mtx.Lock()
+	b.closed = true
+	for _, s := range b.subs {
+		s.cond.Signal()
+	}
+	b.mtx.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		b.cancel()
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	}
+}
+
+func (b *Bus) deliver(s *subscription) {
+	defer b.wg.Done()
+	for {
+		b.mtx.Lock()
+		for len(s.queue) == 0 && !b.closed {
+			s.cond.Wait()
+		}
+		if len(s.queue) == 0 {
+			b.mtx.Unlock()
+			return
+		}
+		e := s.queue[0]
+		b.mtx.Unlock()
+
+		if !b.notify(s.sub, e) {
+			return
+		}
+
+		b.mtx.Lock()
+		s.queue = s.queue[1:]
+		b.mtx.Unlock()
+	}
+}
+
+// notify delivers e to sub, retrying with exponential backoff.
+// It returns false if the bus was cancelled before delivery succeeded.
+func (b *Bus) notify(sub Subscriber, e Event) bool {
+	backoff := b.retry.InitialBackoff
+	for {
+		if err := sub.Notify(b.ctx, e); err == nil {
+			return true
+		}
+		select {
+		case <-b.ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > b.retry.MaxBackoff {
+			backoff = b.retry.MaxBackoff
+		}
+	}
+}
+
+// ChannelSubscriber returns a Subscriber sending events to ch.
+// Delivery blocks until ch accepts the event.
+func ChannelSubscriber(ch chan<- Event) Subscriber {
+	return SubscriberFunc(func(ctx context.Context, e Event) error {
+		select {
+		case ch <- e:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBusAtLeastOnce(t *testing.T) {
+	b := NewBusWithRetry(RetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond})
+
+	ch := make(chan Event, 4)
+	require.NoError(t, b.Subscribe(ChannelSubscriber(ch)))
+
+	var failures int32
+	flaky := make(chan Event, 4)
+	require.NoError(t, b.Subscribe(SubscriberFunc(func(ctx context.Context, e Event) error {
+		if atomic.AddInt32(&failures, 1) <= 2 {
+			return errors.New("unavailable")
+		}
+		flaky <- e
+		return nil
+	})))
+
+	events := []Event{
+		KeygenCompleted{Header: Header{Session: "k1"}},
+		SessionAborted{Header: Header{Session: "s1"}, Reason: "test", Culprits: nil},
+	}
+	for _, e := range events {
+		require.NoError(t, b.Publish(e))
+	}
+	require.NoError(t, b.Close(context.Background()))
+
+	for _, out := range []chan Event{ch, flaky} {
+		require.Len(t, out, len(events))
+		for _, e := range events {
+			got := <-out
+			assert.Equal(t, e.Type(), got.Type())
+			assert.Equal(t, e.SessionID(), got.SessionID())
+		}
+	}
+	assert.ErrorIs(t, b.Publish(events[0]), ErrBusClosed)
+}
+
+func TestBusCloseBlockedSubscriber(t *testing.T) {
+	b := NewBus()
+	// the subscriber ignores the cancellation of its context
+	blocked := make(chan struct{})
+	t.Cleanup(func() { close(blocked) })
+	require.NoError(t, b.Subscribe(SubscriberFunc(func(context.Context, Event) error {
+		<-blocked
+		return nil
+	})))
+	require.NoError(t, b.Publish(KeygenCompleted{Header: Header{Session: "k1"}}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	closed := make(chan error, 1)
+	go func() { closed <- b.Close(ctx) }()
+	select {
+	case err := <-closed:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return once its context was done")
+	}
+}
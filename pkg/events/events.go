@@ -0,0 +1,72 @@
+package events
+
+import (
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+)
+
+// Event types emitted on the Bus.
+const (
+	TypeKeygenCompleted   = "keygen.completed"
+	TypePresignReady      = "presign.ready"
+	TypeSignatureProduced = "signature.produced"
+	TypeSessionAborted    = "session.aborted"
+)
+
+// Event is a notification about the lifecycle of a protocol session.
+type Event interface {
+	// Type returns one of the Type* constants.
+	Type() string
+	// SessionID returns the ID of the session which produced the event.
+	SessionID() string
+}
+
+// Header contains the fields common to all events.
+type Header struct {
+	Session string    `json:"sessionId"`
+	KeyID   string    `json:"keyId"`
+	Time    time.Time `json:"time"`
+}
+
+func (h Header) SessionID() string { return h.Session }
+
+// KeygenCompleted is emitted once a keygen session produced a new key.
+type KeygenCompleted struct {
+	Header
+	// PublicKey is the encoded public key.
+	PublicKey []byte `json:"publicKey"`
+	// TranscriptHash is the hash of the keygen transcript, if the handler provides one.
+	TranscriptHash []byte `json:"transcriptHash,omitempty"`
+}
+
+func (KeygenCompleted) Type() string { return TypeKeygenCompleted }
+
+// PresignReady is emitted once a presignature is available for signing.
+type PresignReady struct {
+	Header
+	// PresignID identifies the presignature.
+	PresignID string `json:"presignId"`
+}
+
+func (PresignReady) Type() string { return TypePresignReady }
+
+// SignatureProduced is emitted once a sign session produced a valid signature.
+type SignatureProduced struct {
+	Header
+	// Message is the hash which was signed.
+	Message []byte `json:"message"`
+	// Signature is the encoded signature.
+	Signature []byte `json:"signature"`
+}
+
+func (SignatureProduced) Type() string { return TypeSignatureProduced }
+
+// SessionAborted is emitted when a session aborted, along with the parties responsible for it, if known.
+type SessionAborted struct {
+	Header
+	Reason   string     `json:"reason"`
+	Culprits []party.ID `json:"culprits,omitempty"`
+}
+
+func (SessionAborted) Type() string { return TypeSessionAborted }
@@ -0,0 +1,162 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Notarization is the public record of a key creation event, published to a transparency log so that
+// anyone can check when a key was created, and that the parties agreed on the transcript which produced it.
+type Notarization struct {
+	SessionID string    `json:"sessionId"`
+	KeyID     string    `json:"keyId,omitempty"`
+	Time      time.Time `json:"time"`
+	// PublicKey is the encoded public key.
+	PublicKey []byte `json:"publicKey"`
+	// TranscriptHash is the hash of the keygen transcript, as returned by protocol.MultiHandler.TranscriptHash.
+	TranscriptHash []byte `json:"transcriptHash"`
+}
+
+// Digest returns a SHA-256 digest committing to the session, public key and transcript hash of n,
+// suited to be stored by a contract as a single 32 byte word. The time is not included,
+// so that all parties of a keygen compute the same digest.
+func (n *Notarization) Digest() []byte {
+	h := sha256.New()
+	for _, field := range [][]byte{[]byte("mpc-lib notarization"), []byte(n.SessionID), []byte(n.KeyID), n.PublicKey, n.TranscriptHash} {
+		var length [4]byte
+		binary.BigEndian.PutUint32(length[:], uint32(len(field)))
+		_, _ = h.Write(length[:])
+		_, _ = h.Write(field)
+	}
+	return h.Sum(nil)
+}
+
+// Publisher records notarizations in an append-only log, such as a transparency log or an EVM contract,
+// and returns a receipt proving the inclusion, such as a signed timestamp or a transaction hash.
+//
+// Publish may be called more than once for the same notarization, and should then be idempotent.
+type Publisher interface {
+	Publish(ctx context.Context, n *Notarization) (receipt []byte, err error)
+}
+
+// PublisherFunc adapts a function to the Publisher interface.
+type PublisherFunc func(ctx context.Context, n *Notarization) ([]byte, error)
+
+func (f PublisherFunc) Publish(ctx context.Context, n *Notarization) ([]byte, error) { return f(ctx, n) }
+
+// Notary is a Subscriber publishing a Notarization for each KeygenCompleted event carrying a transcript hash,
+// as set by PublishResult. Other events are ignored.
+type Notary struct {
+	publisher Publisher
+
+	mtx      sync.Mutex
+	receipts map[string][]byte
+}
+
+// NewNotary returns a Notary publishing to publisher.
+func NewNotary(publisher Publisher) *Notary {
+	return &Notary{
+		publisher: publisher,
+		receipts:  map[string][]byte{},
+	}
+}
+
+// Notify implements Subscriber.
+func (n *Notary) Notify(ctx context.Context, e Event) error {
+	completed, ok := e.(KeygenCompleted)
+	if !ok || len(completed.TranscriptHash) == 0 {
+		return nil
+	}
+	receipt, err := n.publisher.Publish(ctx, &Notarization{
+		SessionID:      completed.Session,
+		KeyID:          completed.KeyID,
+		Time:           completed.Time,
+		PublicKey:      completed.PublicKey,
+		TranscriptHash: completed.TranscriptHash,
+	})
+	if err != nil {
+		return fmt.Errorf("events: notary: %w", err)
+	}
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.receipts[completed.Session] = receipt
+	return nil
+}
+
+// Receipt returns the receipt of the notarization of the keygen session, once it was published.
+func (n *Notary) Receipt(sessionID string) ([]byte, bool) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	receipt, ok := n.receipts[sessionID]
+	return receipt, ok
+}
+
+// TransparencyLog is a Publisher POSTing notarizations as JSON to the submission URL of a transparency log.
+// Binary fields are hex encoded, and the digest is included so that the log may index entries by it.
+// The response body is returned as the receipt.
+type TransparencyLog struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewTransparencyLog returns a TransparencyLog submitting to url with a 10 second timeout.
+func NewTransparencyLog(url string) *TransparencyLog {
+	return &TransparencyLog{
+		URL:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Publish implements Publisher.
+func (l *TransparencyLog) Publish(ctx context.Context, n *Notarization) ([]byte, error) {
+	body, err := json.Marshal(struct {
+		SessionID      string    `json:"sessionId"`
+		KeyID          string    `json:"keyId,omitempty"`
+		Time           time.Time `json:"time"`
+		PublicKey      string    `json:"publicKey"`
+		TranscriptHash string    `json:"transcriptHash"`
+		Digest         string    `json:"digest"`
+	}{
+		SessionID:      n.SessionID,
+		KeyID:          n.KeyID,
+		Time:           n.Time,
+		PublicKey:      hex.EncodeToString(n.PublicKey),
+		TranscriptHash: hex.EncodeToString(n.TranscriptHash),
+		Digest:         hex.EncodeToString(n.Digest()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("transparency log: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("transparency log: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := l.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("transparency log: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("transparency log: unexpected status %s", resp.Status)
+	}
+	receipt, err := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if err != nil {
+		return nil, fmt.Errorf("transparency log: %w", err)
+	}
+	return receipt, nil
+}
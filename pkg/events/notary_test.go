@@ -0,0 +1,59 @@
+package events
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotary(t *testing.T) {
+	received := make(chan map[string]string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var entry map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- entry
+		_, _ = w.Write([]byte("receipt"))
+	}))
+	defer srv.Close()
+
+	notary := NewNotary(NewTransparencyLog(srv.URL))
+	b := NewBusWithRetry(RetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+	require.NoError(t, b.Subscribe(notary))
+	// keygens without a transcript hash and other events are not notarized
+	require.NoError(t, b.Publish(KeygenCompleted{Header: Header{Session: "keygen-0"}, PublicKey: []byte{1}}))
+	require.NoError(t, b.Publish(SignatureProduced{Header: Header{Session: "sign-1"}}))
+	require.NoError(t, b.Publish(KeygenCompleted{
+		Header:         Header{Session: "keygen-1", KeyID: "key-1"},
+		PublicKey:      []byte{0x02, 0xab},
+		TranscriptHash: []byte{0xcd},
+	}))
+	require.NoError(t, b.Close(context.Background()))
+
+	entry := <-received
+	assert.Equal(t, "keygen-1", entry["sessionId"])
+	assert.Equal(t, "02ab", entry["publicKey"])
+	assert.Equal(t, "cd", entry["transcriptHash"])
+	n := &Notarization{SessionID: "keygen-1", KeyID: "key-1", PublicKey: []byte{0x02, 0xab}, TranscriptHash: []byte{0xcd}}
+	assert.Equal(t, hex.EncodeToString(n.Digest()), entry["digest"])
+	select {
+	case entry := <-received:
+		t.Fatalf("unexpected notarization of %s", entry["sessionId"])
+	default:
+	}
+
+	receipt, ok := notary.Receipt("keygen-1")
+	require.True(t, ok)
+	assert.Equal(t, []byte("receipt"), receipt)
+	_, ok = notary.Receipt("keygen-0")
+	assert.False(t, ok)
+}
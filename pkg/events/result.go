@@ -0,0 +1,51 @@
+package events
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/ecdsa"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+)
+
+// PublishResult publishes the event corresponding to the outcome of a finished handler:
+// KeygenCompleted for a keygen config, along with the transcript hash if h provides one, SignatureProduced for a signature, and SessionAborted on error.
+//
+// The header is completed with the current time if it is not set.
+// msg is the signed message, and is ignored unless the result is a signature.
+func PublishResult(b *Bus, h protocol.Handler, header Header, msg []byte) error {
+	if header.Time.IsZero() {
+		header.Time = time.Now()
+	}
+	result, err := h.Result()
+	if err != nil {
+		aborted := SessionAborted{Header: header, Reason: err.Error()}
+		var protocolErr protocol.Error
+		if errors.As(err, &protocolErr) {
+			aborted.Culprits = protocolErr.Culprits
+		}
+		return b.Publish(aborted)
+	}
+
+	switch r := result.(type) {
+	case *ecdsa.Signature:
+		sig, err := r.SigEthereum()
+		if err != nil {
+			return err
+		}
+		return b.Publish(SignatureProduced{Header: header, Message: msg, Signature: sig})
+	case interface{ PublicPoint() curve.Point }:
+		pk, err := r.PublicPoint().MarshalBinary()
+		if err != nil {
+			return err
+		}
+		completed := KeygenCompleted{Header: header, PublicKey: pk}
+		if t, ok := h.(interface{ TranscriptHash() []byte }); ok {
+			completed.TranscriptHash = t.TranscriptHash()
+		}
+		return b.Publish(completed)
+	default:
+		return errors.New("events: unknown protocol result")
+	}
+}
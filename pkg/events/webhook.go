@@ -0,0 +1,138 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+)
+
+// Headers set on webhook requests.
+const (
+	WebhookSignatureHeader = "X-MPC-Signature"
+	WebhookTimestampHeader = "X-MPC-Timestamp"
+)
+
+// WebhookPayload is the JSON body POSTed by a Webhook.
+// Binary fields are hex encoded.
+type WebhookPayload struct {
+	Type           string     `json:"type"`
+	SessionID      string     `json:"sessionId"`
+	KeyID          string     `json:"keyId,omitempty"`
+	Time           time.Time  `json:"time"`
+	PublicKey      string     `json:"publicKey,omitempty"`
+	TranscriptHash string     `json:"transcriptHash,omitempty"`
+	PresignID      string     `json:"presignId,omitempty"`
+	Message        string     `json:"message,omitempty"`
+	Signature      string     `json:"signature,omitempty"`
+	Reason         string     `json:"reason,omitempty"`
+	Culprits       []party.ID `json:"culprits,omitempty"`
+}
+
+// Webhook is a Subscriber which POSTs events as JSON to a URL.
+//
+// Each request is authenticated with an HMAC-SHA256 over "<timestamp>.<body>" using a shared secret,
+// sent hex encoded in the X-MPC-Signature header along with the timestamp in X-MPC-Timestamp.
+// Receivers should check the signature with VerifyWebhook, and reject stale timestamps.
+//
+// Any response other than 2xx is an error, so that the Bus retries the delivery.
+type Webhook struct {
+	URL    string
+	Secret []byte
+	Client *http.Client
+}
+
+// NewWebhook returns a Webhook posting to url with a 10 second timeout.
+func NewWebhook(url string, secret []byte) *Webhook {
+	return &Webhook{
+		URL:    url,
+		Secret: secret,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify implements Subscriber.
+func (w *Webhook) Notify(ctx context.Context, e Event) error {
+	body, err := json.Marshal(NewWebhookPayload(e))
+	if err != nil {
+		return fmt.Errorf("events: webhook: %w", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("events: webhook: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, hex.EncodeToString(webhookMAC(w.Secret, timestamp, body)))
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("events: webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("events: webhook: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// NewWebhookPayload converts an event to its webhook representation.
+func NewWebhookPayload(e Event) *WebhookPayload {
+	p := &WebhookPayload{
+		Type:      e.Type(),
+		SessionID: e.SessionID(),
+	}
+	setHeader := func(h Header) {
+		p.KeyID = h.KeyID
+		p.Time = h.Time
+	}
+	switch e := e.(type) {
+	case KeygenCompleted:
+		setHeader(e.Header)
+		p.PublicKey = hex.EncodeToString(e.PublicKey)
+		p.TranscriptHash = hex.EncodeToString(e.TranscriptHash)
+	case PresignReady:
+		setHeader(e.Header)
+		p.PresignID = e.PresignID
+	case SignatureProduced:
+		setHeader(e.Header)
+		p.Message = hex.EncodeToString(e.Message)
+		p.Signature = hex.EncodeToString(e.Signature)
+	case SessionAborted:
+		setHeader(e.Header)
+		p.Reason = e.Reason
+		p.Culprits = e.Culprits
+	}
+	return p
+}
+
+// VerifyWebhook checks the signature of a webhook request, given its headers and body.
+func VerifyWebhook(secret []byte, timestamp, signature string, body []byte) bool {
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(sig, webhookMAC(secret, timestamp, body))
+}
+
+func webhookMAC(secret []byte, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write([]byte(timestamp))
+	_, _ = mac.Write([]byte{'.'})
+	_, _ = mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,57 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook(t *testing.T) {
+	secret := []byte("secret")
+	var calls int32
+	received := make(chan *WebhookPayload, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// fail the first attempt to exercise retries
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if !VerifyWebhook(secret, r.Header.Get(WebhookTimestampHeader), r.Header.Get(WebhookSignatureHeader), body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var p WebhookPayload
+		if err := json.Unmarshal(body, &p); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- &p
+	}))
+	defer srv.Close()
+
+	b := NewBusWithRetry(RetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+	require.NoError(t, b.Subscribe(NewWebhook(srv.URL, secret)))
+	require.NoError(t, b.Publish(SignatureProduced{
+		Header:    Header{Session: "sign-1", KeyID: "key-1"},
+		Signature: []byte{0xab, 0xcd},
+	}))
+	require.NoError(t, b.Close(context.Background()))
+
+	p := <-received
+	assert.Equal(t, TypeSignatureProduced, p.Type)
+	assert.Equal(t, "sign-1", p.SessionID)
+	assert.Equal(t, "key-1", p.KeyID)
+	assert.Equal(t, "abcd", p.Signature)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+
+	assert.False(t, VerifyWebhook([]byte("other"), "0", "00", []byte("{}")))
+}
@@ -33,7 +33,7 @@ func (kr *KeyOpts) Import(data interface{}, opts keyopts.Options) error {
 	defer kr.lock.Unlock()
 
 	// get KeyID from Options
-	ID, ok := opts.Get("id")
+	ID, ok := opts.Get(keyopts.KeyIDOption)
 	if !ok {
 		return ErrInvalidParamsKeyID
 	}
@@ -43,7 +43,7 @@ func (kr *KeyOpts) Import(data interface{}, opts keyopts.Options) error {
 	}
 
 	// get PartyID from Options
-	partyID, ok := opts.Get("partyid")
+	partyID, ok := opts.Get(keyopts.PartyIDOption)
 	if !ok {
 		return ErrInvalidParamsPartyID
 	}
@@ -76,7 +76,7 @@ func (kr *KeyOpts) Get(opts keyopts.Options) (*keyopts.KeyData, error) {
 	defer kr.lock.RUnlock()
 
 	// get KeyID from Options
-	ID, ok := opts.Get("id")
+	ID, ok := opts.Get(keyopts.KeyIDOption)
 	if !ok {
 		return nil, ErrInvalidParamsKeyID
 	}
@@ -86,7 +86,7 @@ func (kr *KeyOpts) Get(opts keyopts.Options) (*keyopts.KeyData, error) {
 	}
 
 	// get PartyID from Options
-	partyID, ok := opts.Get("partyid")
+	partyID, ok := opts.Get(keyopts.PartyIDOption)
 	if !ok {
 		return nil, ErrInvalidParamsPartyID
 	}
@@ -112,7 +112,7 @@ func (kr *KeyOpts) GetAll(opts keyopts.Options) (map[string]*keyopts.KeyData, er
 	kr.lock.RLock()
 	defer kr.lock.RUnlock()
 
-	ID, ok := opts.Get("id")
+	ID, ok := opts.Get(keyopts.KeyIDOption)
 	if !ok {
 		return nil, ErrInvalidParamsKeyID
 	}
@@ -138,7 +138,7 @@ func (kr *KeyOpts) Delete(opts keyopts.Options) error {
 	defer kr.lock.Unlock()
 
 	// get KeyID from Options
-	ID, ok := opts.Get("id")
+	ID, ok := opts.Get(keyopts.KeyIDOption)
 	if !ok {
 		return ErrInvalidParamsKeyID
 	}
@@ -148,7 +148,7 @@ func (kr *KeyOpts) Delete(opts keyopts.Options) error {
 	}
 
 	// get PartyID from Options
-	partyID, ok := opts.Get("partyid")
+	partyID, ok := opts.Get(keyopts.PartyIDOption)
 	if !ok {
 		return ErrInvalidParamsPartyID
 	}
@@ -172,7 +172,7 @@ func (kr *KeyOpts) DeleteAll(opts keyopts.Options) error {
 	defer kr.lock.Unlock()
 
 	// get KeyID from Options
-	ID, ok := opts.Get("ID")
+	ID, ok := opts.Get(keyopts.KeyIDOption)
 	if !ok {
 		return ErrInvalidParamsKeyID
 	}
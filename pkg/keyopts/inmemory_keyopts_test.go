@@ -23,15 +23,34 @@ func TestImportKeys(t *testing.T) {
 		},
 	}
 	for _, key := range keys {
-		opts, err := NewOptions().Set("id", keyID, "partyid", key.PartyID)
-		assert.NoError(t, err)
-		err = kr.Import(key.SKI, opts)
+		err := kr.Import(key.SKI, New().WithKeyID(keyID).WithPartyID(key.PartyID))
 		assert.NoError(t, err, "Import should not return an error")
 	}
 
-	opts := make(Options)
-	opts.Set("id", "1")
+	opts := New().WithKeyID("1")
 	ks, err := kr.GetAll(opts)
 	assert.NoError(t, err, "GetAll should not return an error")
 	assert.Len(t, ks, len(keys), fmt.Sprintf("GetAll should return %d key", len(keys)))
 }
+
+func TestOptionsBuilder(t *testing.T) {
+	opts := New().WithKeyID("1").WithPartyID("a").WithEpoch(2)
+	assert.NoError(t, keyopts.Require(opts, keyopts.KeyIDOption, keyopts.PartyIDOption, keyopts.EpochOption))
+	epoch, _ := opts.Get(keyopts.EpochOption)
+	assert.Equal(t, uint64(2), epoch)
+
+	err := keyopts.RequireKey(New().WithKeyID("1"))
+	assert.ErrorIs(t, err, keyopts.ErrMissingOption)
+	assert.ErrorContains(t, err, keyopts.PartyIDOption)
+
+	loose, _ := NewOptions().Set("id", 1, "partyid", "a")
+	assert.ErrorIs(t, keyopts.RequireKey(loose), keyopts.ErrInvalidOption)
+	assert.ErrorIs(t, keyopts.RequireKey(New().WithKeyID("").WithPartyID("a")), keyopts.ErrInvalidOption)
+
+	// the ID of the key is found by DeleteAll
+	kr := NewInMemoryKeyOpts()
+	assert.NoError(t, kr.Import("ski", New().WithKeyID("1").WithPartyID("a")))
+	assert.NoError(t, kr.DeleteAll(New().WithKeyID("1")))
+	_, err = kr.Get(New().WithKeyID("1").WithPartyID("a"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
@@ -14,6 +14,30 @@ func NewOptions() Options {
 	return Options{}
 }
 
+// New starts building the options of a key, as in New().WithKeyID(id).WithPartyID(p).
+// Unlike Set, each option is given with its expected type.
+func New() Options {
+	return Options{}
+}
+
+// WithKeyID sets the MPC KeyID, or session ID, the key belongs to.
+func (opts Options) WithKeyID(id string) Options {
+	opts[com_keyopts.KeyIDOption] = id
+	return opts
+}
+
+// WithPartyID sets the party holding the key, or ROOT for the key of the whole committee.
+func (opts Options) WithPartyID(id string) Options {
+	opts[com_keyopts.PartyIDOption] = id
+	return opts
+}
+
+// WithEpoch sets the refresh epoch of the key.
+func (opts Options) WithEpoch(epoch uint64) Options {
+	opts[com_keyopts.EpochOption] = epoch
+	return opts
+}
+
 func (opts Options) Set(kVs ...interface{}) (com_keyopts.Options, error) {
 	if len(kVs)%2 != 0 {
 		return nil, errors.New("keyrepository: invalid options")
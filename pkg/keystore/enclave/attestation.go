@@ -0,0 +1,137 @@
+package enclave
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/params"
+)
+
+var (
+	ErrInvalidAttestation = errors.New("enclave: invalid attestation")
+	ErrNotAttested        = errors.New("enclave: party has no attestation")
+	ErrStaleNonce         = errors.New("enclave: attestation nonce was not issued or was already used")
+)
+
+// Attestation is the platform attestation of a party's wrapping key, published in the identity Directory.
+type Attestation struct {
+	// Party is the party whose shares are wrapped by the attested key.
+	Party party.ID
+	// Platform is the SecureElement's platform, selecting the format of Statement.
+	Platform string
+	// Nonce is issued by Directory.Nonce for a single attestation, so that an attestation cannot be replayed.
+	Nonce []byte
+	// Statement is the attestation returned by SecureElement.Attest.
+	Statement []byte
+}
+
+// Verifier checks platform attestations, typically against the certificate chains of the platform vendors.
+type Verifier interface {
+	// Verify checks that statement attests a key held in a secure element of the given platform, binding challenge.
+	Verify(platform string, statement, challenge []byte) error
+}
+
+// Directory is the identity directory of the committee, holding the attestation of each party's wrapping key.
+type Directory interface {
+	// Nonce issues the nonce of the next attestation of the party, replacing any nonce issued before.
+	Nonce(id party.ID) ([]byte, error)
+	// Publish verifies a and records it, replacing the previous attestation of the party.
+	// The nonce of a must be the last one issued to the party, and is consumed, or ErrStaleNonce is returned.
+	Publish(a *Attestation) error
+	// Attestation returns the attestation of the party, or ErrNotAttested.
+	Attestation(id party.ID) (*Attestation, error)
+}
+
+// Attest returns the Attestation of the wrapping key of se for party id, binding nonce.
+func Attest(se SecureElement, id party.ID, nonce []byte) (*Attestation, error) {
+	a := &Attestation{
+		Party:    id,
+		Platform: se.Platform(),
+		Nonce:    append([]byte{}, nonce...),
+	}
+	statement, err := se.Attest(a.Challenge())
+	if err != nil {
+		return nil, fmt.Errorf("enclave: attest: %w", err)
+	}
+	a.Statement = statement
+	return a, nil
+}
+
+// Challenge returns the challenge bound by the statement, which commits to the party and the nonce.
+func (a *Attestation) Challenge() []byte {
+	h := sha256.New()
+	_, _ = h.Write([]byte("enclave attestation\x00"))
+	_, _ = h.Write([]byte(a.Party))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write(a.Nonce)
+	return h.Sum(nil)
+}
+
+// Verify checks the attestation with v.
+func (a *Attestation) Verify(v Verifier) error {
+	if a.Party == "" || a.Platform == "" || len(a.Nonce) == 0 || len(a.Statement) == 0 {
+		return fmt.Errorf("%w: missing fields", ErrInvalidAttestation)
+	}
+	if err := v.Verify(a.Platform, a.Statement, a.Challenge()); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidAttestation, err)
+	}
+	return nil
+}
+
+// InMemoryDirectory is a Directory which does not survive restarts.
+type InMemoryDirectory struct {
+	verifier     Verifier
+	attestations map[party.ID]*Attestation
+	nonces       map[party.ID][]byte
+	mtx          sync.Mutex
+}
+
+// NewInMemoryDirectory returns a Directory accepting the attestations verified by v.
+func NewInMemoryDirectory(v Verifier) *InMemoryDirectory {
+	return &InMemoryDirectory{
+		verifier:     v,
+		attestations: map[party.ID]*Attestation{},
+		nonces:       map[party.ID][]byte{},
+	}
+}
+
+func (d *InMemoryDirectory) Nonce(id party.ID) ([]byte, error) {
+	nonce := make([]byte, params.SecBytes)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.nonces[id] = nonce
+	return append([]byte{}, nonce...), nil
+}
+
+func (d *InMemoryDirectory) Publish(a *Attestation) error {
+	if err := a.Verify(d.verifier); err != nil {
+		return err
+	}
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	nonce, ok := d.nonces[a.Party]
+	if !ok || !bytes.Equal(nonce, a.Nonce) {
+		return fmt.Errorf("%w: %s", ErrStaleNonce, a.Party)
+	}
+	delete(d.nonces, a.Party)
+	d.attestations[a.Party] = a
+	return nil
+}
+
+func (d *InMemoryDirectory) Attestation(id party.ID) (*Attestation, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	a, ok := d.attestations[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotAttested, id)
+	}
+	return a, nil
+}
@@ -0,0 +1,165 @@
+// Package enclave stores key shares on mobile co-signers, wrapped by a key held in the device's secure element,
+// such as the iOS Secure Enclave or the Android hardware-backed keystore.
+//
+// The wrapping key never leaves the secure element: the platform code implements SecureElement, and is bound
+// through gomobile, whose generated bindings only support the plain types used by its methods. The Keystore
+// stores the wrapped blobs in any vault, so that a copy of the device storage is useless without the device.
+//
+// The wrapping key is attested by the platform, and the attestation is published in a Directory, so that the
+// other parties can check that a co-signer's share is indeed protected by a secure element.
+package enclave
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
+	"github.com/mr-shifu/mpc-lib/pkg/common/vault"
+)
+
+var (
+	ErrKeyNotFound = errors.New("enclave: key not found")
+	ErrNoElement   = errors.New("enclave: no SecureElement configured")
+)
+
+// SecureElement is implemented by the platform code, with a wrapping key which never leaves the device's secure element.
+type SecureElement interface {
+	// Platform names the kind of secure element and attestation format, such as "apple-app-attest"
+	// or "android-key-attestation", for the Verifier of the other parties.
+	Platform() string
+	// Wrap encrypts data with the wrapping key, authenticating label.
+	Wrap(label string, data []byte) ([]byte, error)
+	// Unwrap decrypts a blob returned by Wrap, and fails if it was wrapped with another label.
+	Unwrap(label string, blob []byte) ([]byte, error)
+	// Attest returns the platform attestation of the wrapping key, binding challenge.
+	Attest(challenge []byte) ([]byte, error)
+}
+
+// Keystore implements keystore.Keystore, storing the keys in a vault wrapped by a SecureElement.
+// Each blob is wrapped with its SKI as label, so that blobs cannot be swapped between keys in the vault.
+type Keystore struct {
+	se SecureElement
+	v  vault.Vault
+	kr keyopts.KeyOpts
+}
+
+var _ keystore.Keystore = (*Keystore)(nil)
+
+func NewKeystore(se SecureElement, v vault.Vault, kr keyopts.KeyOpts) *Keystore {
+	return &Keystore{
+		se: se,
+		v:  v,
+		kr: kr,
+	}
+}
+
+func (ks *Keystore) Import(ski string, key []byte, opts keyopts.Options) error {
+	if ks.se == nil {
+		return ErrNoElement
+	}
+	blob, err := ks.se.Wrap(ski, key)
+	if err != nil {
+		return fmt.Errorf("enclave: wrap: %w", err)
+	}
+	if err := ks.v.Import(ski, blob); err != nil {
+		return err
+	}
+	return ks.kr.Import(ski, opts)
+}
+
+func (ks *Keystore) Update(key []byte, opts keyopts.Options) error {
+	if ks.se == nil {
+		return ErrNoElement
+	}
+	kd, err := ks.kr.Get(opts)
+	if err != nil {
+		return err
+	}
+	if kd.SKI == "" {
+		return ErrKeyNotFound
+	}
+	blob, err := ks.se.Wrap(kd.SKI, key)
+	if err != nil {
+		return fmt.Errorf("enclave: wrap: %w", err)
+	}
+	return ks.v.Import(kd.SKI, blob)
+}
+
+func (ks *Keystore) Get(opts keyopts.Options) ([]byte, error) {
+	if ks.se == nil {
+		return nil, ErrNoElement
+	}
+	kd, err := ks.kr.Get(opts)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := ks.v.Get(kd.SKI)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ks.se.Unwrap(kd.SKI, blob)
+	if err != nil {
+		return nil, fmt.Errorf("enclave: unwrap: %w", err)
+	}
+	return key, nil
+}
+
+func (ks *Keystore) Delete(opts keyopts.Options) error {
+	kd, err := ks.kr.Get(opts)
+	if err != nil {
+		return err
+	}
+	if err := ks.v.Delete(kd.SKI); err != nil {
+		return err
+	}
+	return ks.kr.Delete(opts)
+}
+
+func (ks *Keystore) KeyAccessor(ski string, opts keyopts.Options) keystore.KeyAccessor {
+	return &keyAccessor{ski: ski, opts: opts, ks: ks}
+}
+
+type keyAccessor struct {
+	opts keyopts.Options
+	ski  string
+	ks   *Keystore
+}
+
+func (a *keyAccessor) Import(key []byte) error {
+	return a.ks.Import(a.ski, key, a.opts)
+}
+
+func (a *keyAccessor) Get() ([]byte, error) {
+	return a.ks.Get(a.opts)
+}
+
+func (a *keyAccessor) Delete() error {
+	return a.ks.Delete(a.opts)
+}
+
+// KeystoreFactory creates Keystores wrapping the keys with a SecureElement.
+type KeystoreFactory struct {
+	se SecureElement
+}
+
+var _ keystore.KeystoreFactory = (*KeystoreFactory)(nil)
+
+// NewKeystoreFactory returns a KeystoreFactory wrapping the keys with se.
+func NewKeystoreFactory(se SecureElement) (*KeystoreFactory, error) {
+	if se == nil {
+		return nil, ErrNoElement
+	}
+	return &KeystoreFactory{se: se}, nil
+}
+
+// NewKeystore creates a Keystore wrapping the keys with cfg if it is a SecureElement,
+// and with the element of the factory otherwise.
+// Without either, the operations of the Keystore fail with ErrNoElement, since the factory interface cannot return an error.
+func (f *KeystoreFactory) NewKeystore(v vault.Vault, kr keyopts.KeyOpts, cfg interface{}) keystore.Keystore {
+	se, ok := cfg.(SecureElement)
+	if !ok && f != nil {
+		se = f.se
+	}
+	return NewKeystore(se, v, kr)
+}
@@ -0,0 +1,162 @@
+package enclave_test
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/keystore/enclave"
+	"github.com/mr-shifu/mpc-lib/pkg/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// element is a SecureElement keeping its key in memory, attesting with an HMAC.
+type element struct {
+	key []byte
+}
+
+func newElement() *element {
+	key := make([]byte, chacha20poly1305.KeySize)
+	_, _ = rand.Read(key)
+	return &element{key: key}
+}
+
+func (e *element) Platform() string { return "test" }
+
+func (e *element) Wrap(label string, data []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(e.key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	_, _ = rand.Read(nonce)
+	return aead.Seal(nonce, nonce, data, []byte(label)), nil
+}
+
+func (e *element) Unwrap(label string, blob []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(e.key)
+	if err != nil {
+		return nil, err
+	}
+	if len(blob) < aead.NonceSize() {
+		return nil, errors.New("blob too short")
+	}
+	return aead.Open(nil, blob[:aead.NonceSize()], blob[aead.NonceSize():], []byte(label))
+}
+
+func (e *element) Attest(challenge []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, e.key)
+	_, _ = mac.Write(challenge)
+	return mac.Sum(nil), nil
+}
+
+func (e *element) Verify(platform string, statement, challenge []byte) error {
+	expected, _ := e.Attest(challenge)
+	if platform != e.Platform() || !hmac.Equal(statement, expected) {
+		return errors.New("bad statement")
+	}
+	return nil
+}
+
+func TestKeystore(t *testing.T) {
+	se := newElement()
+	v := vault.NewInMemoryVault()
+	ks := enclave.NewKeystore(se, v, keyopts.NewInMemoryKeyOpts())
+
+	opts1 := keyopts.New().WithKeyID("key1").WithPartyID("a")
+	opts2 := keyopts.New().WithKeyID("key2").WithPartyID("a")
+	require.NoError(t, ks.Import("ski1", []byte("share1"), opts1))
+	require.NoError(t, ks.Import("ski2", []byte("share2"), opts2))
+
+	key, err := ks.Get(opts1)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("share1"), key)
+	blob, err := v.Get("ski1")
+	require.NoError(t, err)
+	assert.NotContains(t, string(blob), "share1")
+
+	// a blob moved to another key is rejected
+	require.NoError(t, v.Import("ski2", blob))
+	_, err = ks.Get(opts2)
+	assert.Error(t, err)
+
+	require.NoError(t, ks.Update([]byte("share2'"), opts2))
+	key, err = ks.Get(opts2)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("share2'"), key)
+
+	// without the device's element, the vault is useless
+	_, err = enclave.NewKeystore(newElement(), v, keyopts.NewInMemoryKeyOpts()).Get(opts1)
+	assert.Error(t, err)
+}
+
+func TestDirectory(t *testing.T) {
+	se := newElement()
+	d := enclave.NewInMemoryDirectory(se)
+
+	_, err := d.Attestation("a")
+	assert.ErrorIs(t, err, enclave.ErrNotAttested)
+
+	// a nonce the directory did not issue is refused
+	a, err := enclave.Attest(se, "a", []byte("nonce"))
+	require.NoError(t, err)
+	assert.ErrorIs(t, d.Publish(a), enclave.ErrStaleNonce)
+
+	nonce, err := d.Nonce("a")
+	require.NoError(t, err)
+	a, err = enclave.Attest(se, "a", nonce)
+	require.NoError(t, err)
+	require.NoError(t, d.Publish(a))
+	published, err := d.Attestation("a")
+	require.NoError(t, err)
+	assert.Equal(t, a, published)
+
+	// the nonce is consumed, so that the attestation cannot be replayed
+	assert.ErrorIs(t, d.Publish(a), enclave.ErrStaleNonce)
+
+	// the statement is bound to the party
+	nonce, err = d.Nonce("b")
+	require.NoError(t, err)
+	a, err = enclave.Attest(se, "a", nonce)
+	require.NoError(t, err)
+	forged := *a
+	forged.Party = "b"
+	assert.ErrorIs(t, d.Publish(&forged), enclave.ErrInvalidAttestation)
+
+	// a new nonce replaces the previous one
+	first, err := d.Nonce("b")
+	require.NoError(t, err)
+	_, err = d.Nonce("b")
+	require.NoError(t, err)
+	a, err = enclave.Attest(se, "b", first)
+	require.NoError(t, err)
+	assert.ErrorIs(t, d.Publish(a), enclave.ErrStaleNonce)
+}
+
+func TestKeystoreFactory(t *testing.T) {
+	_, err := enclave.NewKeystoreFactory(nil)
+	assert.ErrorIs(t, err, enclave.ErrNoElement)
+
+	se := newElement()
+	f, err := enclave.NewKeystoreFactory(se)
+	require.NoError(t, err)
+	v := vault.NewInMemoryVault()
+	opts := keyopts.New().WithKeyID("key").WithPartyID("a")
+	require.NoError(t, f.NewKeystore(v, keyopts.NewInMemoryKeyOpts(), nil).Import("ski", []byte("share"), opts))
+	blob, err := v.Get("ski")
+	require.NoError(t, err)
+	key, err := se.Unwrap("ski", blob)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("share"), key)
+
+	// without an element, the keystore fails instead of panicking
+	ks := (&enclave.KeystoreFactory{}).NewKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts(), "not an element")
+	assert.ErrorIs(t, ks.Import("ski", []byte("share"), opts), enclave.ErrNoElement)
+	_, err = ks.Get(opts)
+	assert.ErrorIs(t, err, enclave.ErrNoElement)
+}
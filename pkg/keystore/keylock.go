@@ -0,0 +1,153 @@
+package keystore
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrKeyBusy is returned when a key is locked by a ceremony which conflicts with the one being started.
+var ErrKeyBusy = errors.New("keystore: key is busy")
+
+// LockMode is the mode in which a ceremony locks the key it uses.
+type LockMode int
+
+const (
+	// Shared is taken by the ceremonies which only read the key material, such as presign and sign.
+	// Any number of them may run concurrently.
+	Shared LockMode = iota
+	// Exclusive is taken by the ceremonies which replace the key material, such as keygen, refresh and reshare.
+	// No other ceremony may run on the key meanwhile.
+	Exclusive
+)
+
+// Kinds of ceremonies, whose mode is given by ModeOf.
+const (
+	CeremonyKeygen  = "keygen"
+	CeremonyRefresh = "refresh"
+	CeremonyReshare = "reshare"
+	CeremonyPresign = "presign"
+	CeremonySign    = "sign"
+)
+
+// ModeOf returns the mode in which a ceremony of the given kind locks its key: Shared for presign and sign,
+// and Exclusive for keygen, refresh, reshare and any other kind, which may replace the shares.
+func ModeOf(kind string) LockMode {
+	switch kind {
+	case CeremonyPresign, CeremonySign:
+		return Shared
+	default:
+		return Exclusive
+	}
+}
+
+func (m LockMode) String() string {
+	if m == Exclusive {
+		return "exclusive"
+	}
+	return "shared"
+}
+
+// KeyLocks are advisory locks on the keys of a keystore.
+//
+// The key managers do not check them: each ceremony must lock its key before reading or writing
+// its material, so that a refresh cannot replace shares which a running sign is still using.
+//
+// The epoch of a key counts the releases of its exclusive locks, that is the number of times its material
+// may have been replaced. A Shared lease keeps the epoch it was taken in until it is released.
+type KeyLocks struct {
+	mtx  sync.Mutex
+	keys map[string]*keyLock
+}
+
+type keyLock struct {
+	shared    int
+	exclusive bool
+	epoch     uint64
+}
+
+func NewKeyLocks() *KeyLocks {
+	return &KeyLocks{keys: map[string]*keyLock{}}
+}
+
+// Lock locks keyID in mode, or returns ErrKeyBusy if it is locked in a conflicting mode.
+func (l *KeyLocks) Lock(keyID string, mode LockMode) (*Lease, error) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	k, ok := l.keys[keyID]
+	if !ok {
+		k = &keyLock{}
+		l.keys[keyID] = k
+	}
+	switch {
+	case k.exclusive:
+		return nil, fmt.Errorf("%w: %s is locked by a ceremony replacing its shares", ErrKeyBusy, keyID)
+	case mode == Exclusive && k.shared > 0:
+		return nil, fmt.Errorf("%w: %s is used by %d running ceremonies", ErrKeyBusy, keyID, k.shared)
+	}
+	if mode == Exclusive {
+		k.exclusive = true
+	} else {
+		k.shared++
+	}
+	return &Lease{locks: l, keyID: keyID, mode: mode, epoch: k.epoch}, nil
+}
+
+// Epoch returns the current epoch of keyID, 0 if it was never locked exclusively.
+func (l *KeyLocks) Epoch(keyID string) uint64 {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if k, ok := l.keys[keyID]; ok {
+		return k.epoch
+	}
+	return 0
+}
+
+func (l *KeyLocks) release(lease *Lease) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	k := l.keys[lease.keyID]
+	if lease.mode == Exclusive {
+		k.exclusive = false
+		k.epoch++
+		return
+	}
+	k.shared--
+}
+
+// Lease is a lock held on a key, until it is released.
+type Lease struct {
+	locks *KeyLocks
+	keyID string
+	mode  LockMode
+	epoch uint64
+	once  sync.Once
+}
+
+// KeyID returns the ID of the locked key.
+func (lease *Lease) KeyID() string {
+	return lease.keyID
+}
+
+// Mode returns the mode in which the key is locked.
+func (lease *Lease) Mode() LockMode {
+	return lease.mode
+}
+
+// Epoch returns the epoch of the key when the lease was taken.
+func (lease *Lease) Epoch() uint64 {
+	return lease.epoch
+}
+
+// Stale reports whether the material of the key may have been replaced since the lease was taken,
+// that is whether an Exclusive lease on the key was released meanwhile. A ceremony holding a Shared lease
+// should check it before using its result, in case the lease was released early.
+func (lease *Lease) Stale() bool {
+	return lease.locks.Epoch(lease.keyID) != lease.epoch
+}
+
+// Release unlocks the key. Releasing an Exclusive lease starts a new epoch.
+// It may be called more than once.
+func (lease *Lease) Release() {
+	lease.once.Do(func() { lease.locks.release(lease) })
+}
@@ -0,0 +1,67 @@
+package keystore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyLocks(t *testing.T) {
+	locks := NewKeyLocks()
+
+	sign1, err := locks.Lock("key", Shared)
+	require.NoError(t, err)
+	sign2, err := locks.Lock("key", Shared)
+	require.NoError(t, err)
+	_, err = locks.Lock("key", Exclusive)
+	assert.ErrorIs(t, err, ErrKeyBusy)
+
+	// other keys are not affected
+	other, err := locks.Lock("other", Exclusive)
+	require.NoError(t, err)
+	other.Release()
+
+	sign1.Release()
+	sign1.Release()
+	_, err = locks.Lock("key", Exclusive)
+	assert.ErrorIs(t, err, ErrKeyBusy, "releasing a lease twice must not release another one")
+	sign2.Release()
+
+	refresh, err := locks.Lock("key", Exclusive)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), refresh.Epoch())
+	_, err = locks.Lock("key", Shared)
+	assert.ErrorIs(t, err, ErrKeyBusy)
+	_, err = locks.Lock("key", Exclusive)
+	assert.ErrorIs(t, err, ErrKeyBusy)
+	refresh.Release()
+
+	assert.Equal(t, uint64(1), locks.Epoch("key"))
+	assert.Equal(t, uint64(1), locks.Epoch("other"))
+	sign3, err := locks.Lock("key", Shared)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), sign3.Epoch())
+	sign3.Release()
+	assert.Equal(t, uint64(1), locks.Epoch("key"), "shared leases do not start an epoch")
+
+	// a shared lease released while the key is replaced is stale
+	sign4, err := locks.Lock("key", Shared)
+	require.NoError(t, err)
+	assert.False(t, sign4.Stale())
+	sign4.Release()
+	refresh, err = locks.Lock("key", Exclusive)
+	require.NoError(t, err)
+	assert.False(t, sign4.Stale())
+	refresh.Release()
+	assert.True(t, sign4.Stale())
+}
+
+func TestModeOf(t *testing.T) {
+	for _, kind := range []string{CeremonyKeygen, CeremonyRefresh, CeremonyReshare, "unknown"} {
+		assert.Equal(t, Exclusive, ModeOf(kind), kind)
+	}
+	for _, kind := range []string{CeremonyPresign, CeremonySign} {
+		assert.Equal(t, Shared, ModeOf(kind), kind)
+	}
+}
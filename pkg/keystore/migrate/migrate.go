@@ -0,0 +1,309 @@
+// Package migrate moves keys from one keystore backend to another while the signers using them are live,
+// for instance from file based storage to a vault wrapped by an HSM.
+//
+// A Keystore wraps the source and destination backends, and is used by the key managers in place of the source.
+// Until the cutover, keys are read from the source, and every write goes to both backends. Migrate copies the
+// existing keys to the destination, checking that they read back identically. Cutover then checks all
+// migrated keys again, switches reads and writes to the destination, and starts a new epoch, which can be
+// recorded to know which backend served a session.
+//
+// Keys are re-encrypted by the destination backend, such as a keystore over a vault.ScratchVault: the keys
+// are compared as returned by Get, so that backends may store them differently.
+//
+// With a Verifier, every key copied or imported is also checked against its public data, such as the SKI it
+// is imported with, so that a corrupted key is refused instead of being marked as migrated.
+package migrate
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
+)
+
+var (
+	ErrMismatch   = errors.New("migrate: key differs in the destination")
+	ErrCutover    = errors.New("migrate: keystore was already cut over")
+	ErrUnmigrated = errors.New("migrate: key was not migrated")
+	ErrInvalidKey = errors.New("migrate: key does not match its public data")
+)
+
+// Verifier checks a key against its public data, given the SKI it is imported with.
+type Verifier func(ski string, key []byte) error
+
+// VerifySKI returns a Verifier decoding keys with decode, and checking that they are imported with the hex
+// encoding of their SKI, as the key managers do.
+func VerifySKI[K interface{ SKI() []byte }](decode func([]byte) (K, error)) Verifier {
+	return func(ski string, key []byte) error {
+		k, err := decode(key)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidKey, err)
+		}
+		if hex.EncodeToString(k.SKI()) != ski {
+			return fmt.Errorf("%w: %s", ErrInvalidKey, ski)
+		}
+		return nil
+	}
+}
+
+// Key references a key of the source keystore, by the SKI it was imported with and the options selecting it.
+type Key struct {
+	SKI     string
+	Options keyopts.Options
+}
+
+// Report describes the outcome of Migrate.
+type Report struct {
+	// Copied is the number of keys copied to the destination.
+	Copied int
+	// Skipped is the number of keys which were already migrated, by Migrate or by a write.
+	Skipped int
+	// Failed maps the SKI of the keys which could not be migrated to the reason.
+	Failed map[string]error
+}
+
+// Keystore implements keystore.Keystore over the source and destination backends of a migration.
+type Keystore struct {
+	// mtx is held for writing by writes and copies, so that a key updated during its copy is not lost.
+	mtx  sync.RWMutex
+	from keystore.Keystore
+	to   keystore.Keystore
+	// migrated holds the keys present in the destination, by SKI.
+	migrated map[string]keyopts.Options
+	verifier Verifier
+	epoch    uint64
+	cutover  bool
+}
+
+var _ keystore.Keystore = (*Keystore)(nil)
+
+// New returns a Keystore migrating the keys of from to to. It starts in epoch 0, reading from the source.
+func New(from, to keystore.Keystore) *Keystore {
+	return &Keystore{
+		from:     from,
+		to:       to,
+		migrated: map[string]keyopts.Options{},
+	}
+}
+
+// WithVerifier checks the keys against their public data with v before marking them as migrated.
+func (ks *Keystore) WithVerifier(v Verifier) *Keystore {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+	ks.verifier = v
+	return ks
+}
+
+// Epoch returns the number of cutovers.
+func (ks *Keystore) Epoch() uint64 {
+	ks.mtx.RLock()
+	defer ks.mtx.RUnlock()
+	return ks.epoch
+}
+
+// Migrate copies the keys to the destination, and checks that they read back identically.
+// Keys which were already migrated are skipped, so that Migrate can be called again after a failure.
+func (ks *Keystore) Migrate(keys []Key) (*Report, error) {
+	report := &Report{Failed: map[string]error{}}
+	for _, key := range keys {
+		copied, err := ks.copy(key)
+		switch {
+		case errors.Is(err, ErrCutover):
+			return report, err
+		case err != nil:
+			report.Failed[key.SKI] = err
+		case copied:
+			report.Copied++
+		default:
+			report.Skipped++
+		}
+	}
+	return report, nil
+}
+
+func (ks *Keystore) copy(key Key) (bool, error) {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+	if ks.cutover {
+		return false, ErrCutover
+	}
+	if _, ok := ks.migrated[key.SKI]; ok {
+		return false, nil
+	}
+	data, err := ks.from.Get(key.Options)
+	if err != nil {
+		return false, err
+	}
+	if err := ks.check(key.SKI, data); err != nil {
+		return false, err
+	}
+	if err := ks.to.Import(key.SKI, data, key.Options); err != nil {
+		return false, err
+	}
+	if err := verify(ks.to, key.Options, data); err != nil {
+		return false, err
+	}
+	ks.migrated[key.SKI] = key.Options
+	return true, nil
+}
+
+// check runs the verifier of ks, if any, on the key imported as ski.
+func (ks *Keystore) check(ski string, key []byte) error {
+	if ks.verifier == nil {
+		return nil
+	}
+	return ks.verifier(ski, key)
+}
+
+// Cutover checks every migrated key against the source again, and switches to the destination.
+// It returns the new epoch. Keys must all be migrated before, since the source is no longer used afterwards.
+func (ks *Keystore) Cutover(keys []Key) (uint64, error) {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+	if ks.cutover {
+		return ks.epoch, ErrCutover
+	}
+	for _, key := range keys {
+		if _, ok := ks.migrated[key.SKI]; !ok {
+			return ks.epoch, fmt.Errorf("%w: %s", ErrUnmigrated, key.SKI)
+		}
+	}
+	for ski, opts := range ks.migrated {
+		data, err := ks.from.Get(opts)
+		if err != nil {
+			return ks.epoch, fmt.Errorf("migrate: %s: %w", ski, err)
+		}
+		if err := ks.check(ski, data); err != nil {
+			return ks.epoch, err
+		}
+		if err := verify(ks.to, opts, data); err != nil {
+			return ks.epoch, fmt.Errorf("%w: %s", err, ski)
+		}
+	}
+	ks.cutover = true
+	ks.epoch++
+	return ks.epoch, nil
+}
+
+// verify checks that the key selected by opts in ks is data.
+func verify(ks keystore.Keystore, opts keyopts.Options, data []byte) error {
+	stored, err := ks.Get(opts)
+	if err != nil {
+		return err
+	}
+	want, got := sha256.Sum256(data), sha256.Sum256(stored)
+	if subtle.ConstantTimeCompare(want[:], got[:]) != 1 {
+		return ErrMismatch
+	}
+	return nil
+}
+
+// Import writes a new key to both backends, and marks it as migrated once it was checked against its public
+// data and read back from the destination.
+func (ks *Keystore) Import(ski string, key []byte, opts keyopts.Options) error {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+	if err := ks.check(ski, key); err != nil {
+		return err
+	}
+	if !ks.cutover {
+		if err := ks.from.Import(ski, key, opts); err != nil {
+			return err
+		}
+	}
+	if err := ks.to.Import(ski, key, opts); err != nil {
+		return err
+	}
+	if err := verify(ks.to, opts, key); err != nil {
+		return fmt.Errorf("%w: %s", err, ski)
+	}
+	ks.migrated[ski] = opts
+	return nil
+}
+
+// Update writes to the destination only if the key was migrated, since Migrate copies the updated key otherwise.
+func (ks *Keystore) Update(key []byte, opts keyopts.Options) error {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+	if ks.cutover {
+		return ks.to.Update(key, opts)
+	}
+	if err := ks.from.Update(key, opts); err != nil {
+		return err
+	}
+	if _, err := ks.to.Get(opts); err != nil {
+		return nil
+	}
+	return ks.to.Update(key, opts)
+}
+
+func (ks *Keystore) Get(opts keyopts.Options) ([]byte, error) {
+	ks.mtx.RLock()
+	defer ks.mtx.RUnlock()
+	if ks.cutover {
+		return ks.to.Get(opts)
+	}
+	return ks.from.Get(opts)
+}
+
+func (ks *Keystore) Delete(opts keyopts.Options) error {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+	if ks.cutover {
+		return ks.to.Delete(opts)
+	}
+	if err := ks.from.Delete(opts); err != nil {
+		return err
+	}
+	// the key may not have been migrated yet
+	if _, err := ks.to.Get(opts); err == nil {
+		if err := ks.to.Delete(opts); err != nil {
+			return err
+		}
+	}
+	for ski, o := range ks.migrated {
+		if sameKey(o, opts) {
+			delete(ks.migrated, ski)
+		}
+	}
+	return nil
+}
+
+func (ks *Keystore) KeyAccessor(ski string, opts keyopts.Options) keystore.KeyAccessor {
+	return &keyAccessor{ski: ski, opts: opts, ks: ks}
+}
+
+// sameKey compares the options selecting a key in the keystores, which are its id and party id.
+func sameKey(a, b keyopts.Options) bool {
+	for _, k := range []string{"id", "partyid"} {
+		va, _ := a.Get(k)
+		vb, _ := b.Get(k)
+		if va != vb {
+			return false
+		}
+	}
+	return true
+}
+
+type keyAccessor struct {
+	ski  string
+	opts keyopts.Options
+	ks   *Keystore
+}
+
+func (a *keyAccessor) Import(key []byte) error {
+	return a.ks.Import(a.ski, key, a.opts)
+}
+
+func (a *keyAccessor) Get() ([]byte, error) {
+	return a.ks.Get(a.opts)
+}
+
+func (a *keyAccessor) Delete() error {
+	return a.ks.Delete(a.opts)
+}
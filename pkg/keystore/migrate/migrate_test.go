@@ -0,0 +1,134 @@
+package migrate
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	comm_keyopts "github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
+	comm_keystore "github.com/mr-shifu/mpc-lib/pkg/common/keystore"
+	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/ecdsa"
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/keystore"
+	"github.com/mr-shifu/mpc-lib/pkg/vault"
+	"github.com/stretchr/testify/require"
+)
+
+func newKeystore() comm_keystore.Keystore {
+	return keystore.NewInMemoryKeystore(vault.NewInMemoryVault(), keyopts.NewInMemoryKeyOpts())
+}
+
+// newKey returns a new ECDSA key, and the SKI it is imported with.
+func newKey(t *testing.T) (string, []byte) {
+	group := curve.Secp256k1{}
+	priv := sample.Scalar(rand.Reader, group)
+	key := ecdsa.NewECDSAKey(priv, priv.ActOnBase(), group)
+	data, err := key.Bytes()
+	require.NoError(t, err)
+	return hex.EncodeToString(key.SKI()), data
+}
+
+// corrupted is a keystore returning the keys it holds with their last byte flipped.
+type corrupted struct {
+	comm_keystore.Keystore
+}
+
+func (ks corrupted) Get(opts comm_keyopts.Options) ([]byte, error) {
+	data, err := ks.Keystore.Get(opts)
+	if err != nil {
+		return nil, err
+	}
+	data = append([]byte{}, data...)
+	data[len(data)-1] ^= 1
+	return data, nil
+}
+
+func TestMigrate(t *testing.T) {
+	from, to := newKeystore(), newKeystore()
+	keys := make([]Key, 0, 3)
+	for i := 0; i < 3; i++ {
+		ski, data := newKey(t)
+		opts := keyopts.New().WithKeyID(fmt.Sprintf("key-%d", i)).WithPartyID("a")
+		require.NoError(t, from.Import(ski, data, opts))
+		keys = append(keys, Key{SKI: ski, Options: opts})
+	}
+	ks := New(from, to).WithVerifier(VerifySKI(ecdsa.FromBytes))
+
+	report, err := ks.Migrate(keys[:2])
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Copied)
+	require.Empty(t, report.Failed)
+	_, err = ks.Cutover(keys)
+	require.ErrorIs(t, err, ErrUnmigrated)
+
+	// a key written during the migration goes to both backends, and is not copied again
+	ski, data := newKey(t)
+	opts := keyopts.New().WithKeyID("new").WithPartyID("a")
+	require.NoError(t, ks.Import(ski, data, opts))
+	keys = append(keys, Key{SKI: ski, Options: opts})
+	stored, err := from.Get(opts)
+	require.NoError(t, err)
+	require.Equal(t, data, stored)
+
+	report, err = ks.Migrate(keys)
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Copied)
+	require.Equal(t, 3, report.Skipped)
+
+	epoch, err := ks.Cutover(keys)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), epoch)
+	require.Equal(t, uint64(1), ks.Epoch())
+
+	// reads and writes go to the destination only
+	require.NoError(t, from.Delete(keys[0].Options))
+	stored, err = ks.Get(keys[0].Options)
+	require.NoError(t, err)
+	migrated, err := to.Get(keys[0].Options)
+	require.NoError(t, err)
+	require.Equal(t, migrated, stored)
+
+	_, err = ks.Migrate(keys)
+	require.ErrorIs(t, err, ErrCutover)
+	_, err = ks.Cutover(keys)
+	require.ErrorIs(t, err, ErrCutover)
+}
+
+func TestMigrateVerifies(t *testing.T) {
+	from := newKeystore()
+	ski, data := newKey(t)
+	other, _ := newKey(t)
+	valid := keyopts.New().WithKeyID("valid").WithPartyID("a")
+	invalid := keyopts.New().WithKeyID("invalid").WithPartyID("a")
+	require.NoError(t, from.Import(ski, data, valid))
+	// the key is stored under the SKI of another one
+	require.NoError(t, from.Import(other, data, invalid))
+
+	ks := New(from, newKeystore()).WithVerifier(VerifySKI(ecdsa.FromBytes))
+	report, err := ks.Migrate([]Key{{SKI: ski, Options: valid}, {SKI: other, Options: invalid}})
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Copied)
+	require.ErrorIs(t, report.Failed[other], ErrInvalidKey)
+
+	// a key which does not match its SKI is refused, and not marked as migrated
+	opts := keyopts.New().WithKeyID("imported").WithPartyID("a")
+	require.ErrorIs(t, ks.Import(other, data, opts), ErrInvalidKey)
+	_, err = ks.Cutover([]Key{{SKI: other, Options: opts}})
+	require.ErrorIs(t, err, ErrUnmigrated)
+	require.ErrorIs(t, ks.Import(other, []byte("garbage"), opts), ErrInvalidKey)
+
+	// a key which does not read back from the destination is not marked as migrated either
+	ks = New(newKeystore(), corrupted{newKeystore()})
+	require.ErrorIs(t, ks.Import(ski, data, valid), ErrMismatch)
+	_, err = ks.Cutover([]Key{{SKI: ski, Options: valid}})
+	require.ErrorIs(t, err, ErrUnmigrated)
+
+	ks = New(from, corrupted{newKeystore()})
+	report, err = ks.Migrate([]Key{{SKI: ski, Options: valid}})
+	require.NoError(t, err)
+	require.Zero(t, report.Copied)
+	require.ErrorIs(t, report.Failed[ski], ErrMismatch)
+}
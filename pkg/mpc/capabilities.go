@@ -0,0 +1,104 @@
+// Package mpc describes the protocols supported by this build of the library.
+package mpc
+
+import (
+	"sort"
+
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	cmp_config "github.com/mr-shifu/mpc-lib/protocols/cmp/config"
+	cmp_keygen "github.com/mr-shifu/mpc-lib/protocols/cmp/keygen"
+	cmp_sign "github.com/mr-shifu/mpc-lib/protocols/cmp/sign"
+	frost_keygen "github.com/mr-shifu/mpc-lib/protocols/frost/keygen"
+	frost_sign "github.com/mr-shifu/mpc-lib/protocols/frost/sign"
+	"github.com/mr-shifu/mpc-lib/protocols/single"
+)
+
+// Curves supported by at least one protocol.
+const (
+	CurveSecp256k1 = "secp256k1"
+	CurveEd25519   = "ed25519"
+)
+
+// Serialization formats, whose versions are reported by Capabilities.
+// Each version is defined, and recorded in the encoding, by the package owning the format.
+const (
+	FormatMessage       = "protocol/message"
+	FormatSnapshot      = "protocol/snapshot"
+	FormatConfig        = "cmp/config"
+	FormatPublicPackage = "cmp/public-package"
+)
+
+// ProtocolCapability describes a protocol which this build can run.
+type ProtocolCapability struct {
+	// ID is the protocol ID, as found in round.Info and protocol.Message.
+	ID string `json:"id"`
+	// Versions are the versions of the protocol which can be negotiated with round.Negotiate.
+	Versions []round.Version `json:"versions"`
+	// Curves are the curves the protocol produces keys or signatures for.
+	Curves []string `json:"curves"`
+}
+
+// CapabilitySet describes what this build of the library supports, so that orchestration layers
+// can route sessions to nodes which are able to run them.
+type CapabilitySet struct {
+	Curves       []string             `json:"curves"`
+	Protocols    []ProtocolCapability `json:"protocols"`
+	ProofSystems []string             `json:"proofSystems"`
+	// Serialization maps each serialization format to its current version.
+	Serialization map[string]int `json:"serialization"`
+}
+
+// Capabilities returns the capabilities of this build.
+func Capabilities() CapabilitySet {
+	protocols := []ProtocolCapability{
+		{ID: cmp_keygen.ProtocolID, Versions: []round.Version{cmp_keygen.Version}, Curves: []string{CurveSecp256k1}},
+		{ID: cmp_sign.ProtocolID, Versions: cmp_sign.Versions, Curves: []string{CurveSecp256k1}},
+		{ID: frost_keygen.KEYGEN_THRESHOLD_PROTOCOL, Versions: []round.Version{frost_keygen.Version}, Curves: []string{CurveEd25519}},
+		{ID: frost_sign.SIGN_CONFIG_PROTOCOL_ID, Versions: []round.Version{frost_sign.Version}, Curves: []string{CurveEd25519}},
+		{ID: single.KeygenProtocolID, Versions: []round.Version{single.Version}, Curves: []string{CurveSecp256k1}},
+		{ID: single.SignProtocolID, Versions: []round.Version{single.Version}, Curves: []string{CurveSecp256k1}},
+	}
+
+	seen := map[string]bool{}
+	var curves []string
+	for _, p := range protocols {
+		for _, c := range p.Curves {
+			if !seen[c] {
+				seen[c] = true
+				curves = append(curves, c)
+			}
+		}
+	}
+	sort.Strings(curves)
+
+	return CapabilitySet{
+		Curves:    curves,
+		Protocols: protocols,
+		ProofSystems: []string{
+			"zk/affg", "zk/affp", "zk/dec", "zk/dleq", "zk/elog", "zk/enc", "zk/encelg", "zk/fac", "zk/log",
+			"zk/logstar", "zk/mod", "zk/mul", "zk/mulstar", "zk/nth", "zk/prm", "zk/sch",
+		},
+		Serialization: map[string]int{
+			FormatMessage:       int(round.EncodingVersion),
+			FormatSnapshot:      protocol.SnapshotVersion,
+			FormatConfig:        cmp_config.EncodingVersion,
+			FormatPublicPackage: cmp_config.PublicPackageVersion,
+		},
+	}
+}
+
+// Supports returns true if a session of the given protocol and version can be run.
+func (c CapabilitySet) Supports(protocolID string, version round.Version) bool {
+	for _, p := range c.Protocols {
+		if p.ID != protocolID {
+			continue
+		}
+		for _, v := range p.Versions {
+			if v == version {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package mpc
+
+import (
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	cmp_config "github.com/mr-shifu/mpc-lib/protocols/cmp/config"
+	cmp_keygen "github.com/mr-shifu/mpc-lib/protocols/cmp/keygen"
+	cmp_sign "github.com/mr-shifu/mpc-lib/protocols/cmp/sign"
+	frost_keygen "github.com/mr-shifu/mpc-lib/protocols/frost/keygen"
+	frost_sign "github.com/mr-shifu/mpc-lib/protocols/frost/sign"
+	"github.com/mr-shifu/mpc-lib/protocols/single"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilities(t *testing.T) {
+	c := Capabilities()
+	assert.Equal(t, []string{CurveEd25519, CurveSecp256k1}, c.Curves)
+	assert.NotEmpty(t, c.ProofSystems)
+
+	assert.True(t, c.Supports(cmp_keygen.ProtocolID, cmp_keygen.Version))
+	for _, v := range cmp_sign.Versions {
+		assert.True(t, c.Supports(cmp_sign.ProtocolID, v))
+	}
+	assert.True(t, c.Supports(frost_keygen.KEYGEN_THRESHOLD_PROTOCOL, frost_keygen.Version))
+	assert.True(t, c.Supports(frost_sign.SIGN_CONFIG_PROTOCOL_ID, frost_sign.Version))
+	assert.True(t, c.Supports(single.SignProtocolID, single.Version))
+	assert.False(t, c.Supports(cmp_keygen.ProtocolID, cmp_keygen.Version+1))
+	assert.False(t, c.Supports("unknown", 1))
+
+	assert.Equal(t, map[string]int{
+		FormatMessage:       int(round.EncodingVersion),
+		FormatSnapshot:      protocol.SnapshotVersion,
+		FormatConfig:        cmp_config.EncodingVersion,
+		FormatPublicPackage: cmp_config.PublicPackageVersion,
+	}, c.Serialization)
+
+	// the reported version is the one written in messages
+	data, err := (&protocol.Message{SSID: []byte("ssid"), From: "a", Protocol: cmp_sign.ProtocolID, RoundNumber: 1}).MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, c.Serialization[FormatMessage], int(data[0]))
+}
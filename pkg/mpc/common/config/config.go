@@ -1,8 +1,13 @@
 package config
 
 import (
+	"time"
+
+	"filippo.io/edwards25519"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/params"
+	"github.com/mr-shifu/mpc-lib/lib/round"
 )
 
 type ConfigStore interface {
@@ -16,6 +21,12 @@ type KeyConfig interface {
 	Threshold() int
 	SelfID() party.ID
 	PartyIDs() party.IDSlice
+	// ExpectedPublicKey returns the public key keygen must produce, or nil if any key is accepted.
+	ExpectedPublicKey() curve.Point
+	// ExpectedEd25519PublicKey is ExpectedPublicKey for the protocols over edwards25519, such as FROST keygen.
+	ExpectedEd25519PublicKey() *edwards25519.Point
+	// Profile returns the profile of the range proofs, or the zero Profile to use params.Standard.
+	Profile() params.Profile
 }
 
 type KeyConfigManager interface {
@@ -31,6 +42,16 @@ type SignConfig interface {
 	SelfID() party.ID
 	PartyIDs() party.IDSlice
 	Message() []byte
+	// DerivationPath returns the BIP32 path of the child key to sign with, or nil to sign with the key itself.
+	DerivationPath() []uint32
+	// Context returns the context string of the application requesting the signature, or nil if none.
+	Context() []byte
+	// Version returns the version of the sign protocol to run, or 0 to run the default one.
+	Version() round.Version
+	// ReleaseTime returns the time before which the signature must not be released, or the zero time if none.
+	ReleaseTime() time.Time
+	// Profile returns the profile of the range proofs, or the zero Profile to use params.Standard.
+	Profile() params.Profile
 }
 
 type SignConfigManager interface {
@@ -0,0 +1,38 @@
+package pin
+
+import (
+	"errors"
+
+	"github.com/mr-shifu/mpc-lib/core/hash"
+	pailliercore "github.com/mr-shifu/mpc-lib/core/paillier"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	pedersencore "github.com/mr-shifu/mpc-lib/core/pedersen"
+)
+
+// ErrAuxParamsChanged is returned when a party presents auxiliary parameters which differ
+// from the ones pinned for the same key.
+var ErrAuxParamsChanged = errors.New("pin: auxiliary parameters changed")
+
+// PinStore remembers the fingerprint of each party's Paillier and Pedersen parameters, per key.
+//
+// Parameters are pinned when they are first learned at keygen, and remain valid for the lifetime
+// of the key. A refresh starting a new key epoch must Reset the pins of the key.
+type PinStore interface {
+	// Pin records fp for the party, or returns ErrAuxParamsChanged if a different fingerprint was pinned.
+	Pin(keyID string, partyID party.ID, fp []byte) error
+	// Check returns ErrAuxParamsChanged if fp differs from the pinned fingerprint.
+	// It returns nil if nothing was pinned for the party.
+	Check(keyID string, partyID party.ID, fp []byte) error
+	// Reset removes all pins of the key.
+	Reset(keyID string) error
+}
+
+// Fingerprint returns a hash identifying the parameters (N, s, t) of a party.
+func Fingerprint(paillier *pailliercore.PublicKey, pedersen *pedersencore.Parameters) []byte {
+	return hash.New(
+		hash.BytesWithDomain{TheDomain: "Paillier N", Bytes: paillier.N().Bytes()},
+		hash.BytesWithDomain{TheDomain: "Pedersen N", Bytes: pedersen.N().Bytes()},
+		hash.BytesWithDomain{TheDomain: "Pedersen S", Bytes: pedersen.S().Bytes()},
+		hash.BytesWithDomain{TheDomain: "Pedersen T", Bytes: pedersen.T().Bytes()},
+	).Sum()
+}
@@ -0,0 +1,58 @@
+package record
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Limits of the labels attached to a key or a record.
+const (
+	MaxLabels         = 32
+	MaxLabelNameSize  = 64
+	MaxLabelValueSize = 256
+)
+
+var ErrInvalidLabels = errors.New("record: invalid labels")
+
+// Labels are free-form name/value pairs attached to keys and signature records by operators,
+// such as a wallet name, a customer ID or an environment, so that they can be searched for.
+type Labels map[string]string
+
+// Validate returns ErrInvalidLabels if l exceeds the limits on labels, or has an empty name.
+func (l Labels) Validate() error {
+	if len(l) > MaxLabels {
+		return fmt.Errorf("%w: %d labels, at most %d", ErrInvalidLabels, len(l), MaxLabels)
+	}
+	for name, value := range l {
+		if name == "" || len(name) > MaxLabelNameSize {
+			return fmt.Errorf("%w: name %q must have 1 to %d bytes", ErrInvalidLabels, name, MaxLabelNameSize)
+		}
+		if len(value) > MaxLabelValueSize {
+			return fmt.Errorf("%w: value of %q exceeds %d bytes", ErrInvalidLabels, name, MaxLabelValueSize)
+		}
+	}
+	return nil
+}
+
+// Match returns true if l has all labels of selector with the same values.
+// An empty selector matches all labels.
+func (l Labels) Match(selector Labels) bool {
+	for name, value := range selector {
+		if v, ok := l[name]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Clone returns a copy of l, or nil if l is empty.
+func (l Labels) Clone() Labels {
+	if len(l) == 0 {
+		return nil
+	}
+	c := make(Labels, len(l))
+	for name, value := range l {
+		c[name] = value
+	}
+	return c
+}
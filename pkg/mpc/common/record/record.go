@@ -0,0 +1,84 @@
+package record
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+)
+
+// ErrRecordNotFound is returned when no record exists for a session.
+var ErrRecordNotFound = errors.New("record: not found")
+
+// Record is the evidence kept for each produced signature.
+type Record struct {
+	SessionID   string        `json:"sessionId"`
+	KeyID       string        `json:"keyId"`
+	MessageHash []byte        `json:"messageHash"`
+	Signers     party.IDSlice `json:"signers"`
+	// Time is the time at which the signature was produced.
+	Time time.Time `json:"time"`
+	// PresignatureID is empty when the signature was produced without a presignature.
+	PresignatureID string `json:"presignatureId,omitempty"`
+	// Transcript is the transcript hash confirmed by all signers, if any.
+	Transcript []byte `json:"transcript,omitempty"`
+	Signature  []byte `json:"signature"`
+	// Labels are the labels given to the sign request.
+	Labels Labels `json:"labels,omitempty"`
+}
+
+// Filter selects records. Zero fields match all records.
+type Filter struct {
+	KeyID string `json:"keyId,omitempty"`
+	// Signer selects the records of signatures which the party participated in.
+	Signer party.ID `json:"signer,omitempty"`
+	// From and To bound the time of the records, inclusively.
+	From time.Time `json:"from,omitempty"`
+	To   time.Time `json:"to,omitempty"`
+	// Labels selects the records having all of these labels.
+	Labels Labels `json:"labels,omitempty"`
+}
+
+// Match returns true if r is selected by f.
+func (f Filter) Match(r *Record) bool {
+	if f.KeyID != "" && r.KeyID != f.KeyID {
+		return false
+	}
+	if f.Signer != "" && !r.Signers.Contains(f.Signer) {
+		return false
+	}
+	if !f.From.IsZero() && r.Time.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && r.Time.After(f.To) {
+		return false
+	}
+	if !r.Labels.Match(f.Labels) {
+		return false
+	}
+	return true
+}
+
+// RecordStore keeps a record of each produced signature, for audits.
+type RecordStore interface {
+	// Put stores r, replacing the record of the same session if any,
+	// so that the transcript can be added once it is confirmed.
+	Put(r *Record) error
+	// Get returns the record of the session, or ErrRecordNotFound.
+	Get(sessionID string) (*Record, error)
+	// Query returns the records selected by f, in order of time.
+	Query(f Filter) ([]*Record, error)
+}
+
+// Export writes the records as JSON, one per line.
+func Export(w io.Writer, records []*Record) error {
+	enc := json.NewEncoder(w)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,10 +1,80 @@
 package result
 
-import "github.com/mr-shifu/mpc-lib/core/math/curve"
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/mr-shifu/mpc-lib/core/hash"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+)
+
+// ErrPresignatureSigners is returned when a presignature is used by other signers than the ones which computed it,
+// or when its ID does not bind its signers.
+var ErrPresignatureSigners = errors.New("presignature: signers differ from the ones it was computed by")
+
+// Presignature is the record of the nonce R = k⁻¹⋅G computed by the presigning rounds of a sign session.
+// It is bound to the exact subset of signers whose nonce shares make up R, so that the signing round
+// can check that the same subset completes the signature.
+type Presignature struct {
+	// ID identifies the presignature. It is the same for all of its signers,
+	// and binds SessionID, Signers and R.
+	ID string
+	// SessionID is the ID of the sign session which computed the presignature.
+	SessionID string
+	Signers   party.IDSlice
+	R         curve.Point
+}
+
+// NewPresignature returns the record of the nonce R computed by signers in the sign session sessionID.
+func NewPresignature(sessionID string, signers party.IDSlice, R curve.Point) (*Presignature, error) {
+	signers = party.NewIDSlice(signers)
+	id, err := presignatureID(sessionID, signers, R)
+	if err != nil {
+		return nil, err
+	}
+	return &Presignature{
+		ID:        id,
+		SessionID: sessionID,
+		Signers:   signers,
+		R:         R,
+	}, nil
+}
+
+// Verify returns ErrPresignatureSigners unless the presignature was computed by exactly signers.
+func (p *Presignature) Verify(signers party.IDSlice) error {
+	id, err := presignatureID(p.SessionID, p.Signers, p.R)
+	if err != nil {
+		return err
+	}
+	if id != p.ID {
+		return fmt.Errorf("%w: presignature %s does not bind its signers", ErrPresignatureSigners, p.ID)
+	}
+	if len(signers) != len(p.Signers) || !p.Signers.Contains(signers...) {
+		return fmt.Errorf("%w: presignature %s was computed by %v", ErrPresignatureSigners, p.ID, p.Signers)
+	}
+	return nil
+}
+
+// presignatureID returns the hash of the session, each of the sorted signers and R.
+func presignatureID(sessionID string, signers party.IDSlice, R curve.Point) (string, error) {
+	if R == nil || len(signers) == 0 || !signers.Valid() {
+		return "", errors.New("presignature: invalid signers or nonce")
+	}
+	h := hash.New(hash.BytesWithDomain{TheDomain: "Presignature Session", Bytes: []byte(sessionID)})
+	for _, id := range signers {
+		_ = h.WriteAny(id)
+	}
+	if err := h.WriteAny(R); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum()), nil
+}
 
 type Signature interface {
-	ImportSignR(signID string, r curve.Point)
-	SignR(signID string) curve.Point
+	ImportPresignature(signID string, presig *Presignature)
+	Presignature(signID string) *Presignature
 	ImportSignSigma(signID string, sigma curve.Scalar)
 	SignSigma(signID string) curve.Scalar
 }
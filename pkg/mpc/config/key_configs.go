@@ -1,8 +1,10 @@
 package config
 
 import (
+	"filippo.io/edwards25519"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 )
 
 type KeyConfig struct {
@@ -11,6 +13,10 @@ type KeyConfig struct {
 	threshold int
 	selfID    party.ID
 	partyIDs  party.IDSlice
+
+	expectedPublicKey        curve.Point
+	expectedEd25519PublicKey *edwards25519.Point
+	profile                  params.Profile
 }
 
 func NewKeyConfig(
@@ -48,3 +54,35 @@ func (c *KeyConfig) SelfID() party.ID {
 func (c *KeyConfig) PartyIDs() party.IDSlice {
 	return c.partyIDs
 }
+
+// SetExpectedPublicKey makes keygen abort unless the resulting public key is equal to pk.
+// This is used when a known key is imported or migrated, to detect a mismatch as early as possible.
+func (c *KeyConfig) SetExpectedPublicKey(pk curve.Point) *KeyConfig {
+	c.expectedPublicKey = pk
+	return c
+}
+
+func (c *KeyConfig) ExpectedPublicKey() curve.Point {
+	return c.expectedPublicKey
+}
+
+// SetExpectedEd25519PublicKey is SetExpectedPublicKey for the protocols over edwards25519, such as FROST keygen.
+func (c *KeyConfig) SetExpectedEd25519PublicKey(pk *edwards25519.Point) *KeyConfig {
+	c.expectedEd25519PublicKey = pk
+	return c
+}
+
+func (c *KeyConfig) ExpectedEd25519PublicKey() *edwards25519.Point {
+	return c.expectedEd25519PublicKey
+}
+
+// SetProfile selects the profile of the range proofs of keygen.
+// The profile is bound to the SSID, so all parties must set the same one.
+func (c *KeyConfig) SetProfile(profile params.Profile) *KeyConfig {
+	c.profile = profile
+	return c
+}
+
+func (c *KeyConfig) Profile() params.Profile {
+	return c.profile
+}
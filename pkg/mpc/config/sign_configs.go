@@ -1,8 +1,12 @@
 package config
 
 import (
+	"time"
+
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/params"
+	"github.com/mr-shifu/mpc-lib/lib/round"
 )
 
 type SignConfig struct {
@@ -13,6 +17,12 @@ type SignConfig struct {
 	selfID    party.ID
 	partyIDs  party.IDSlice
 	message   []byte
+
+	derivationPath []uint32
+	context        []byte
+	version        round.Version
+	releaseTime    time.Time
+	profile        params.Profile
 }
 
 func NewSignConfig(
@@ -62,3 +72,62 @@ func (c *SignConfig) PartyIDs() party.IDSlice {
 func (c *SignConfig) Message() []byte {
 	return c.message
 }
+
+// SetDerivationPath makes the session sign with the unhardened BIP32 child of the key at path.
+// All signers must set the same path, otherwise the session aborts.
+func (c *SignConfig) SetDerivationPath(path []uint32) *SignConfig {
+	c.derivationPath = path
+	return c
+}
+
+func (c *SignConfig) DerivationPath() []uint32 {
+	return c.derivationPath
+}
+
+// SetContext binds the session to the context string of the application requesting the signature.
+// The context is mixed into the SSID, and so into the transcript and every proof of the session,
+// but not into the signed message: applications sharing a key cannot replay each other's sessions,
+// and a party asked to sign for another application than the others aborts.
+// All signers must set the same context.
+func (c *SignConfig) SetContext(context string) *SignConfig {
+	c.context = []byte(context)
+	return c
+}
+
+func (c *SignConfig) Context() []byte {
+	return c.context
+}
+
+// SetVersion selects the version of the sign protocol, as negotiated with round.Negotiate.
+// The version is bound to the SSID, so all signers must set the same one.
+func (c *SignConfig) SetVersion(version round.Version) *SignConfig {
+	c.version = version
+	return c
+}
+
+func (c *SignConfig) Version() round.Version {
+	return c.version
+}
+
+// SetReleaseTime makes the signers refuse to release their signature shares before t, so that the committee
+// enforces a time lock, such as for a scheduled payout. The presigning rounds run as soon as the session starts.
+// The release time is bound to the SSID, so all signers must set the same one, with a precision of a second.
+func (c *SignConfig) SetReleaseTime(t time.Time) *SignConfig {
+	c.releaseTime = t
+	return c
+}
+
+func (c *SignConfig) ReleaseTime() time.Time {
+	return c.releaseTime
+}
+
+// SetProfile selects the profile of the range proofs of the session, such as params.Compact.
+// The profile is bound to the SSID, so all signers must set the same one.
+func (c *SignConfig) SetProfile(profile params.Profile) *SignConfig {
+	c.profile = profile
+	return c
+}
+
+func (c *SignConfig) Profile() params.Profile {
+	return c.profile
+}
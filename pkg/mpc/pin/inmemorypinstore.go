@@ -0,0 +1,60 @@
+package pin
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/pin"
+)
+
+type InMemoryPinStore struct {
+	lock sync.RWMutex
+	pins map[string]map[party.ID][]byte
+}
+
+var _ pin.PinStore = (*InMemoryPinStore)(nil)
+
+func NewInMemoryPinStore() *InMemoryPinStore {
+	return &InMemoryPinStore{
+		pins: make(map[string]map[party.ID][]byte),
+	}
+}
+
+func (s *InMemoryPinStore) Pin(keyID string, partyID party.ID, fp []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.check(keyID, partyID, fp); err != nil {
+		return err
+	}
+	if s.pins[keyID] == nil {
+		s.pins[keyID] = make(map[party.ID][]byte)
+	}
+	s.pins[keyID][partyID] = append([]byte{}, fp...)
+	return nil
+}
+
+func (s *InMemoryPinStore) Check(keyID string, partyID party.ID, fp []byte) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.check(keyID, partyID, fp)
+}
+
+func (s *InMemoryPinStore) Reset(keyID string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.pins, keyID)
+	return nil
+}
+
+func (s *InMemoryPinStore) check(keyID string, partyID party.ID, fp []byte) error {
+	pinned, ok := s.pins[keyID][partyID]
+	if ok && !bytes.Equal(pinned, fp) {
+		return fmt.Errorf("%w: party %s, key %s", pin.ErrAuxParamsChanged, partyID, keyID)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package record
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/record"
+)
+
+type InMemoryRecordStore struct {
+	lock    sync.RWMutex
+	records map[string]*record.Record
+}
+
+var _ record.RecordStore = (*InMemoryRecordStore)(nil)
+
+func NewInMemoryRecordStore() *InMemoryRecordStore {
+	return &InMemoryRecordStore{
+		records: make(map[string]*record.Record),
+	}
+}
+
+func (s *InMemoryRecordStore) Put(r *record.Record) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.records[r.SessionID] = clone(r)
+	return nil
+}
+
+func (s *InMemoryRecordStore) Get(sessionID string) (*record.Record, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	r, ok := s.records[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("%w: session %s", record.ErrRecordNotFound, sessionID)
+	}
+	return clone(r), nil
+}
+
+func (s *InMemoryRecordStore) Query(f record.Filter) ([]*record.Record, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var records []*record.Record
+	for _, r := range s.records {
+		if f.Match(r) {
+			records = append(records, clone(r))
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Time.Equal(records[j].Time) {
+			return records[i].SessionID < records[j].SessionID
+		}
+		return records[i].Time.Before(records[j].Time)
+	})
+	return records, nil
+}
+
+// clone copies r, so that the stored records are not modified by the callers.
+func clone(r *record.Record) *record.Record {
+	copied := *r
+	copied.Labels = r.Labels.Clone()
+	return &copied
+}
@@ -4,11 +4,12 @@ import (
 	"sync"
 
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	comm_result "github.com/mr-shifu/mpc-lib/pkg/mpc/common/result"
 )
 
 type Signature struct {
-	r     curve.Point
-	sigma curve.Scalar
+	presig *comm_result.Presignature
+	sigma  curve.Scalar
 }
 
 type SignStore struct {
@@ -21,28 +22,28 @@ func NewSignStore() *SignStore {
 		signatures: make(map[string]*Signature),
 	}
 }
-func (s *SignStore) ImportSignR(signID string, r curve.Point) {
+func (s *SignStore) ImportPresignature(signID string, presig *comm_result.Presignature) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
 	signature, ok := s.signatures[signID]
 	if !ok {
 		s.signatures[signID] = &Signature{
-			r: r,
+			presig: presig,
 		}
 		return
 	}
-	signature.r = r
+	signature.presig = presig
 }
-func (s *SignStore) SignR(signID string) curve.Point {
+func (s *SignStore) Presignature(signID string) *comm_result.Presignature {
 	s.lock.Lock()
 	defer s.lock.Unlock()
-	
+
 	signature, ok := s.signatures[signID]
 	if !ok {
 		return nil
 	}
-	return signature.r
+	return signature.presig
 }
 func (s *SignStore) ImportSignSigma(signID string, sigma curve.Scalar) {
 	s.lock.Lock()
@@ -0,0 +1,194 @@
+// Package oprf implements a threshold oblivious PRF (2HashDH) evaluated by a committee.
+//
+// The PRF is F(k, x) = H₂(x, k⋅H₁(x)), where k is shared between the parties of the committee
+// like a signing key. A client blinds its input as B = r⋅H₁(x), each party of a quorum returns
+// Eᵢ = kᵢ⋅B with a proof that it used its key share, and the client recovers k⋅H₁(x) by interpolating
+// the shares and removing r. The parties learn neither x nor the output, which makes it suitable for
+// password-hardened key backup (OPAQUE-style): the output can only be recomputed with the help of the committee.
+//
+// The key k must come from a keygen dedicated to the OPRF: parties answer Eᵢ = kᵢ⋅B for any B, so that on a
+// signing key they would also return the decryption shares of threshold ciphertexts and the VRF outputs of the key.
+package oprf
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mr-shifu/mpc-lib/core/hash"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	zkdleq "github.com/mr-shifu/mpc-lib/core/zk/dleq"
+	thresholdenc "github.com/mr-shifu/mpc-lib/pkg/threshold-encryption"
+)
+
+// OutputSize is the size in bytes of the PRF output.
+const OutputSize = 32
+
+var (
+	ErrInvalidBlinded   = errors.New("oprf: invalid blinded input")
+	ErrInvalidShare     = errors.New("oprf: invalid evaluation share")
+	ErrNotEnoughShares  = errors.New("oprf: not enough evaluation shares")
+	errHashToCurveFails = errors.New("oprf: failed to hash input to the curve")
+)
+
+// Request is the state of a client between blinding its input and finalizing the output.
+//
+// It contains the blinding factor, and must not be shared.
+type Request struct {
+	input []byte
+	// r is the blinding factor
+	r curve.Scalar
+	// Blinded = r⋅H₁(x) is sent to the parties.
+	Blinded curve.Point
+}
+
+// Blind starts the evaluation of the PRF on input.
+func Blind(group curve.Curve, input []byte) (*Request, error) {
+	P, err := hashToPoint(group, input)
+	if err != nil {
+		return nil, err
+	}
+	r := sample.ScalarUnit(rand.Reader, group)
+	return &Request{
+		input:   input,
+		r:       r,
+		Blinded: r.Act(P),
+	}, nil
+}
+
+// EvaluationShare is the contribution Eᵢ = kᵢ⋅B of a party to the evaluation of the PRF.
+type EvaluationShare struct {
+	ID party.ID
+	// E = kᵢ⋅B
+	E curve.Point
+	// Proof shows that log_G(Kᵢ) = log_B(Eᵢ).
+	Proof *zkdleq.Proof
+}
+
+// Evaluate returns the evaluation share of party `id` with key share `secret` for a blinded input.
+func Evaluate(id party.ID, secret curve.Scalar, blinded curve.Point) (*EvaluationShare, error) {
+	if blinded == nil || blinded.IsIdentity() {
+		return nil, ErrInvalidBlinded
+	}
+	E := secret.Act(blinded)
+	return &EvaluationShare{
+		ID: id,
+		E:  E,
+		Proof: zkdleq.NewProof(secret.Curve(), shareHash(id), zkdleq.Public{
+			H: blinded,
+			X: secret.ActOnBase(),
+			Y: E,
+		}, zkdleq.Private{A: secret}),
+	}, nil
+}
+
+// Finalize verifies the evaluation shares, and returns the PRF output computed from the first t+1 valid ones.
+//
+// The returned culprits are the parties whose share was invalid.
+func (req *Request) Finalize(committee *thresholdenc.Committee, shares []*EvaluationShare) (output []byte, culprits []party.ID, err error) {
+	valid := make(map[party.ID]curve.Point, committee.Threshold+1)
+	ids := make([]party.ID, 0, committee.Threshold+1)
+	for _, share := range shares {
+		if len(ids) == committee.Threshold+1 {
+			break
+		}
+		if share == nil {
+			continue
+		}
+		if _, ok := valid[share.ID]; ok {
+			continue
+		}
+		if err := req.VerifyShare(committee, share); err != nil {
+			culprits = append(culprits, share.ID)
+			continue
+		}
+		valid[share.ID] = share.E
+		ids = append(ids, share.ID)
+	}
+	if len(ids) <= committee.Threshold {
+		return nil, culprits, fmt.Errorf("%w: got %d, need %d", ErrNotEnoughShares, len(ids), committee.Threshold+1)
+	}
+
+	// k⋅B = ∑ⱼ λⱼ⋅Eⱼ
+	l := polynomial.Lagrange(committee.Group, ids)
+	evaluated := committee.Group.NewPoint()
+	for _, j := range ids {
+		evaluated = evaluated.Add(l[j].Act(valid[j]))
+	}
+	// k⋅H₁(x) = r⁻¹⋅k⋅B
+	rInv := committee.Group.NewScalar().Set(req.r).Invert()
+	unblinded, err := rInv.Act(evaluated).MarshalBinary()
+	if err != nil {
+		return nil, culprits, fmt.Errorf("oprf: %w", err)
+	}
+
+	h := hash.New(
+		hash.BytesWithDomain{TheDomain: "OPRF Input", Bytes: req.input},
+		hash.BytesWithDomain{TheDomain: "OPRF Evaluation", Bytes: unblinded},
+	)
+	output = make([]byte, OutputSize)
+	if _, err := io.ReadFull(h.Digest(), output); err != nil {
+		return nil, culprits, fmt.Errorf("oprf: %w", err)
+	}
+	return output, culprits, nil
+}
+
+// VerifyShare returns an error if the share was not computed with the key share of its sender.
+func (req *Request) VerifyShare(committee *thresholdenc.Committee, share *EvaluationShare) error {
+	if share == nil || share.E == nil {
+		return ErrInvalidShare
+	}
+	K, ok := committee.Shares[share.ID]
+	if !ok {
+		return fmt.Errorf("%w: %s is not in the committee", ErrInvalidShare, share.ID)
+	}
+	if !share.Proof.Verify(shareHash(share.ID), zkdleq.Public{H: req.Blinded, X: K, Y: share.E}) {
+		return fmt.Errorf("%w: invalid proof from %s", ErrInvalidShare, share.ID)
+	}
+	return nil
+}
+
+// EmptyEvaluationShare creates an empty EvaluationShare with a fixed group, ready for unmarshalling.
+func EmptyEvaluationShare(group curve.Curve) *EvaluationShare {
+	return &EvaluationShare{
+		E:     group.NewPoint(),
+		Proof: zkdleq.Empty(group),
+	}
+}
+
+// shareHash binds the proof of an evaluation share to its sender.
+func shareHash(id party.ID) *hash.Hash {
+	return hash.New(hash.BytesWithDomain{TheDomain: "OPRF Evaluation Share", Bytes: []byte(id)})
+}
+
+// hashToPoint maps the input to a point whose discrete logarithm is unknown, by try-and-increment
+// over the compressed encoding of points (0x02 or 0x03 followed by the x coordinate).
+//
+// The number of attempts depends on the input, but is only observable by the client, which knows the input.
+func hashToPoint(group curve.Curve, input []byte) (curve.Point, error) {
+	base, err := group.NewBasePoint().MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("oprf: %w", err)
+	}
+	candidate := make([]byte, len(base))
+	for counter := 0; counter < 256; counter++ {
+		h := hash.New(
+			hash.BytesWithDomain{TheDomain: "OPRF Hash To Curve", Bytes: input},
+			hash.BytesWithDomain{TheDomain: "OPRF Hash To Curve Counter", Bytes: []byte{byte(counter)}},
+		)
+		if _, err := io.ReadFull(h.Digest(), candidate); err != nil {
+			return nil, fmt.Errorf("oprf: %w", err)
+		}
+		candidate[0] = 2 | (candidate[0] & 1)
+		P := group.NewPoint()
+		if err := P.UnmarshalBinary(candidate); err != nil || P.IsIdentity() {
+			continue
+		}
+		return P, nil
+	}
+	return nil, errHashToCurveFails
+}
@@ -0,0 +1,70 @@
+package oprf
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/test"
+	thresholdenc "github.com/mr-shifu/mpc-lib/pkg/threshold-encryption"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOPRF(t *testing.T) {
+	group := curve.Secp256k1{}
+	ids := test.PartyIDs(4)
+	threshold := 1
+
+	f := polynomial.NewPolynomial(group, threshold, sample.Scalar(rand.Reader, group))
+	secrets := make(map[party.ID]curve.Scalar, len(ids))
+	committee := &thresholdenc.Committee{Group: group, Threshold: threshold, Shares: map[party.ID]curve.Point{}}
+	for _, id := range ids {
+		secrets[id] = f.Evaluate(id.Scalar(group))
+		committee.Shares[id] = secrets[id].ActOnBase()
+	}
+
+	evaluate := func(input []byte, quorum []party.ID) []byte {
+		req, err := Blind(group, input)
+		require.NoError(t, err)
+		shares := make([]*EvaluationShare, 0, len(quorum))
+		for _, id := range quorum {
+			share, err := Evaluate(id, secrets[id], req.Blinded)
+			require.NoError(t, err)
+			data, err := cbor.Marshal(share)
+			require.NoError(t, err)
+			share = EmptyEvaluationShare(group)
+			require.NoError(t, cbor.Unmarshal(data, share))
+			shares = append(shares, share)
+		}
+		output, culprits, err := req.Finalize(committee, shares)
+		require.NoError(t, err)
+		assert.Empty(t, culprits)
+		return output
+	}
+
+	// the output depends on the input only, not on the blinding nor the quorum
+	password := []byte("correct horse battery staple")
+	output := evaluate(password, ids[:2])
+	assert.Len(t, output, OutputSize)
+	assert.Equal(t, output, evaluate(password, ids[2:]))
+	assert.NotEqual(t, output, evaluate([]byte("password"), ids[:2]))
+
+	// a share computed with another key is rejected and blamed
+	req, err := Blind(group, password)
+	require.NoError(t, err)
+	bad, err := Evaluate(ids[0], secrets[ids[1]], req.Blinded)
+	require.NoError(t, err)
+	good, err := Evaluate(ids[1], secrets[ids[1]], req.Blinded)
+	require.NoError(t, err)
+	_, culprits, err := req.Finalize(committee, []*EvaluationShare{bad, good})
+	assert.ErrorIs(t, err, ErrNotEnoughShares)
+	assert.Equal(t, []party.ID{ids[0]}, culprits)
+
+	_, err = Evaluate(ids[0], secrets[ids[0]], group.NewPoint())
+	assert.ErrorIs(t, err, ErrInvalidBlinded)
+}
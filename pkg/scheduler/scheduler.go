@@ -0,0 +1,187 @@
+// Package scheduler runs sign sessions fairly across keys.
+//
+// When many wallets share the same signers, sessions are queued per key and dispatched with
+// weighted round-robin, so that a key with a burst of requests cannot starve the others.
+// Each key can also be capped to a maximum number of concurrent sessions.
+package scheduler
+
+import (
+	"errors"
+	"sync"
+)
+
+var ErrClosed = errors.New("scheduler: closed")
+
+// Job runs a session. It is called in its own goroutine.
+type Job func()
+
+// Scheduler dispatches jobs queued per key.
+type Scheduler struct {
+	// maxRunning is the maximum number of jobs running at the same time, across all keys.
+	maxRunning int
+	// defaultLimit is the per key concurrency cap, 0 meaning no cap.
+	defaultLimit int
+
+	weights map[string]int
+	limits  map[string]int
+	queues  map[string][]Job
+	running map[string]int
+	total   int
+
+	// ring contains the keys with queued jobs, in round-robin order.
+	ring []string
+	// cursor is the index in ring of the key being served, and credits the number
+	// of jobs it may still start in this turn.
+	cursor  int
+	credits int
+
+	closed bool
+	wg     sync.WaitGroup
+	mtx    sync.Mutex
+}
+
+// New returns a Scheduler running at most maxRunning jobs at once, and at most perKeyLimit jobs per key.
+// A perKeyLimit of 0 disables the per key cap.
+func New(maxRunning, perKeyLimit int) *Scheduler {
+	if maxRunning <= 0 {
+		maxRunning = 1
+	}
+	return &Scheduler{
+		maxRunning:   maxRunning,
+		defaultLimit: perKeyLimit,
+		weights:      map[string]int{},
+		limits:       map[string]int{},
+		queues:       map[string][]Job{},
+		running:      map[string]int{},
+	}
+}
+
+// SetWeight sets the number of jobs a key may start in each round-robin turn. The default weight is 1.
+func (s *Scheduler) SetWeight(key string, weight int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if weight < 1 {
+		weight = 1
+	}
+	s.weights[key] = weight
+}
+
+// SetLimit overrides the concurrency cap of a key. A limit of 0 disables the cap.
+func (s *Scheduler) SetLimit(key string, limit int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.limits[key] = limit
+	s.dispatch()
+}
+
+// Submit queues a job for the given key.
+func (s *Scheduler) Submit(key string, job Job) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	if len(s.queues[key]) == 0 {
+		s.ring = append(s.ring, key)
+	}
+	s.queues[key] = append(s.queues[key], job)
+	s.dispatch()
+	return nil
+}
+
+// Queued returns the number of jobs waiting for the given key.
+func (s *Scheduler) Queued(key string) int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return len(s.queues[key])
+}
+
+// Close stops accepting jobs, and waits for all queued and running jobs to finish.
+func (s *Scheduler) Close() {
+	s.mtx.Lock()
+	s.closed = true
+	s.mtx.Unlock()
+	s.wg.Wait()
+}
+
+// dispatch starts as many jobs as allowed. It must be called with the lock held.
+func (s *Scheduler) dispatch() {
+	for s.total < s.maxRunning && len(s.ring) > 0 {
+		// look for a key which may start a job, visiting each key at most once
+		started := false
+		for tries := 0; tries < len(s.ring); tries++ {
+			if s.cursor >= len(s.ring) {
+				s.cursor = 0
+			}
+			key := s.ring[s.cursor]
+			if s.credits <= 0 {
+				s.credits = s.weight(key)
+			}
+			if s.atLimit(key) {
+				s.next()
+				continue
+			}
+			s.start(key)
+			started = true
+			break
+		}
+		if !started {
+			return
+		}
+	}
+}
+
+// start runs the first job of key, and updates the round-robin state.
+func (s *Scheduler) start(key string) {
+	job := s.queues[key][0]
+	s.queues[key] = s.queues[key][1:]
+	s.running[key]++
+	s.total++
+	s.credits--
+
+	if len(s.queues[key]) == 0 {
+		delete(s.queues, key)
+		s.ring = append(s.ring[:s.cursor], s.ring[s.cursor+1:]...)
+		s.credits = 0
+	} else if s.credits <= 0 {
+		s.next()
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.done(key)
+		job()
+	}()
+}
+
+func (s *Scheduler) done(key string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.running[key]--
+	if s.running[key] == 0 {
+		delete(s.running, key)
+	}
+	s.total--
+	s.dispatch()
+}
+
+func (s *Scheduler) next() {
+	s.cursor++
+	s.credits = 0
+}
+
+func (s *Scheduler) weight(key string) int {
+	if w, ok := s.weights[key]; ok {
+		return w
+	}
+	return 1
+}
+
+func (s *Scheduler) atLimit(key string) bool {
+	limit, ok := s.limits[key]
+	if !ok {
+		limit = s.defaultLimit
+	}
+	return limit > 0 && s.running[key] >= limit
+}
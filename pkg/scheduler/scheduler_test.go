@@ -0,0 +1,63 @@
+package scheduler
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFairness(t *testing.T) {
+	s := New(1, 0)
+	s.SetWeight("b", 2)
+
+	// block the scheduler until all jobs are queued
+	gate := make(chan struct{})
+	var mtx sync.Mutex
+	var order []string
+	record := func(key string) Job {
+		return func() {
+			<-gate
+			mtx.Lock()
+			order = append(order, key)
+			mtx.Unlock()
+		}
+	}
+	for i := 0; i < 6; i++ {
+		assert.NoError(t, s.Submit("a", record("a")))
+	}
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, s.Submit("b", record("b")))
+	}
+	assert.NoError(t, s.Submit("c", record("c")))
+	close(gate)
+	s.Close()
+
+	// the first job of "a" starts before the other keys are queued
+	assert.Equal(t, []string{"a", "a", "b", "b", "c", "a", "b", "b", "a", "a", "a"}, order)
+	assert.ErrorIs(t, s.Submit("a", func() {}), ErrClosed)
+}
+
+func TestPerKeyLimit(t *testing.T) {
+	s := New(10, 2)
+	var mtx sync.Mutex
+	running, max := 0, 0
+	release := make(chan struct{})
+	for i := 0; i < 6; i++ {
+		_ = s.Submit("a", func() {
+			mtx.Lock()
+			running++
+			if running > max {
+				max = running
+			}
+			mtx.Unlock()
+			<-release
+			mtx.Lock()
+			running--
+			mtx.Unlock()
+		})
+	}
+	close(release)
+	s.Close()
+	assert.LessOrEqual(t, max, 2)
+}
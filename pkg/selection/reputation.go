@@ -0,0 +1,286 @@
+package selection
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+)
+
+// DefaultMaxFailureRate is the failure rate above which a party is deprioritized by the default strategy of a node.
+const DefaultMaxFailureRate = 0.1
+
+// Stats are the statistics of a party over the sessions it took part in.
+type Stats struct {
+	// Sessions is the number of sessions which ended, whether completed, aborted or timed out.
+	Sessions int `json:"sessions"`
+	// Timeouts is the number of sessions which timed out while waiting for the party.
+	Timeouts int `json:"timeouts"`
+	// FailedProofs is the number of sessions aborted because a message of the party was invalid.
+	FailedProofs int `json:"failedProofs"`
+	// Aborts is the number of other aborts blamed on the party, such as aborts it sent.
+	Aborts int `json:"aborts"`
+	// LastIncident is the time of the last timeout or abort blamed on the party.
+	LastIncident time.Time `json:"lastIncident,omitempty"`
+}
+
+// Incidents returns the number of sessions which failed because of the party.
+func (s Stats) Incidents() int {
+	return s.Timeouts + s.FailedProofs + s.Aborts
+}
+
+// FailureRate returns the fraction of the party's sessions which failed because of it, 0 if it took part in none.
+func (s Stats) FailureRate() float64 {
+	if s.Sessions == 0 {
+		return 0
+	}
+	rate := float64(s.Incidents()) / float64(s.Sessions)
+	if rate > 1 {
+		return 1
+	}
+	return rate
+}
+
+// ReputationStore keeps the statistics of each party.
+type ReputationStore interface {
+	// Update applies f to the statistics of each of the parties, and stores the results at once.
+	Update(ids []party.ID, f func(id party.ID, s *Stats)) error
+	// All returns the statistics of all parties.
+	All() (map[party.ID]Stats, error)
+}
+
+// InMemoryReputationStore is a ReputationStore lost on restart.
+type InMemoryReputationStore struct {
+	mtx   sync.Mutex
+	stats map[party.ID]Stats
+}
+
+var _ ReputationStore = (*InMemoryReputationStore)(nil)
+
+func NewInMemoryReputationStore() *InMemoryReputationStore {
+	return &InMemoryReputationStore{stats: map[party.ID]Stats{}}
+}
+
+func (s *InMemoryReputationStore) Update(ids []party.ID, f func(party.ID, *Stats)) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, id := range ids {
+		stats := s.stats[id]
+		f(id, &stats)
+		s.stats[id] = stats
+	}
+	return nil
+}
+
+func (s *InMemoryReputationStore) All() (map[party.ID]Stats, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	all := make(map[party.ID]Stats, len(s.stats))
+	for id, stats := range s.stats {
+		all[id] = stats
+	}
+	return all, nil
+}
+
+// FileReputationStore is a ReputationStore kept in a JSON file, which is rewritten once per update,
+// that is once per recorded session.
+type FileReputationStore struct {
+	path string
+
+	mtx   sync.Mutex
+	stats map[party.ID]Stats
+}
+
+var _ ReputationStore = (*FileReputationStore)(nil)
+
+// NewFileReputationStore returns a FileReputationStore loaded from path, which is created on the first update.
+func NewFileReputationStore(path string) (*FileReputationStore, error) {
+	s := &FileReputationStore{path: path, stats: map[party.ID]Stats{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.stats); err != nil {
+		return nil, fmt.Errorf("selection: reputation file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Update writes the statistics to a temporary file which is then renamed,
+// so that a crash never leaves a partially written file.
+func (s *FileReputationStore) Update(ids []party.ID, f func(party.ID, *Stats)) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	previous := make(map[party.ID]Stats, len(ids))
+	existed := make(map[party.ID]bool, len(ids))
+	for _, id := range ids {
+		stats, ok := s.stats[id]
+		if _, seen := previous[id]; !seen {
+			previous[id], existed[id] = stats, ok
+		}
+		f(id, &stats)
+		s.stats[id] = stats
+	}
+	if err := s.write(); err != nil {
+		for id, stats := range previous {
+			if existed[id] {
+				s.stats[id] = stats
+			} else {
+				delete(s.stats, id)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *FileReputationStore) write() error {
+	data, err := json.Marshal(s.stats)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), "tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *FileReputationStore) All() (map[party.ID]Stats, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	all := make(map[party.ID]Stats, len(s.stats))
+	for id, stats := range s.stats {
+		all[id] = stats
+	}
+	return all, nil
+}
+
+// Reputation records the outcome of sessions in a ReputationStore, blaming the parties named by their errors.
+type Reputation struct {
+	store ReputationStore
+}
+
+// NewReputation returns a Reputation recording to store.
+func NewReputation(store ReputationStore) *Reputation {
+	return &Reputation{store: store}
+}
+
+// Completed records a session completed by the parties.
+func (r *Reputation) Completed(parties []party.ID) error {
+	return r.record(parties, nil, func(*Stats) {})
+}
+
+// Aborted records a session between the parties which aborted with err, as returned by protocol.MultiHandler.Result.
+// The culprits of err are charged with a failed proof if their message was rejected, and with an abort otherwise.
+// An error naming no culprit, such as a local failure, is not charged to anyone.
+func (r *Reputation) Aborted(parties []party.ID, err error) error {
+	var e protocol.Error
+	if !errors.As(err, &e) {
+		return r.record(parties, nil, func(*Stats) {})
+	}
+	charge := func(s *Stats) { s.Aborts++ }
+	switch protocol.Code(err) {
+	case protocol.CodeInvalidMessage, protocol.CodeVerificationFailed, protocol.CodeBroadcastMismatch:
+		charge = func(s *Stats) { s.FailedProofs++ }
+	}
+	return r.record(parties, e.Culprits, charge)
+}
+
+// TimedOut records a session between the parties which timed out, while waiting for the silent ones.
+func (r *Reputation) TimedOut(parties []party.ID, silent []party.ID) error {
+	return r.record(parties, silent, func(s *Stats) { s.Timeouts++ })
+}
+
+func (r *Reputation) record(parties, culprits []party.ID, charge func(*Stats)) error {
+	now := time.Now()
+	blamed := make(map[party.ID]bool, len(culprits))
+	for _, id := range culprits {
+		blamed[id] = true
+	}
+	seen := make(map[party.ID]bool, len(parties)+len(culprits))
+	all := make([]party.ID, 0, len(parties)+len(culprits))
+	for _, id := range append(append([]party.ID{}, parties...), culprits...) {
+		if !seen[id] {
+			seen[id] = true
+			all = append(all, id)
+		}
+	}
+	err := r.store.Update(all, func(id party.ID, s *Stats) {
+		s.Sessions++
+		if blamed[id] {
+			charge(s)
+			s.LastIncident = now
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("selection: reputation: %w", err)
+	}
+	return nil
+}
+
+// Stats returns the statistics of all parties.
+func (r *Reputation) Stats() (map[party.ID]Stats, error) {
+	return r.store.All()
+}
+
+// Annotate sets the failure rate of the candidates from their statistics.
+func (r *Reputation) Annotate(candidates []Candidate) ([]Candidate, error) {
+	all, err := r.store.All()
+	if err != nil {
+		return nil, fmt.Errorf("selection: reputation: %w", err)
+	}
+	for i := range candidates {
+		candidates[i].Health.FailureRate = all[candidates[i].ID].FailureRate()
+	}
+	return candidates, nil
+}
+
+type reliable struct {
+	strategy       Strategy
+	maxFailureRate float64
+}
+
+// Reliable returns a Strategy selecting with strategy among the candidates whose failure rate is at most maxFailureRate,
+// and among all candidates only if there are not enough of them, so that unreliable parties are deprioritized
+// without making a key unusable.
+func Reliable(strategy Strategy, maxFailureRate float64) Strategy {
+	return &reliable{strategy: strategy, maxFailureRate: maxFailureRate}
+}
+
+func (s *reliable) Select(self party.ID, candidates []Candidate, size int) (party.IDSlice, error) {
+	trusted := make([]Candidate, 0, len(candidates))
+	for _, c := range candidates {
+		if c.ID == self || c.Health.FailureRate <= s.maxFailureRate {
+			trusted = append(trusted, c)
+		}
+	}
+	signers, err := s.strategy.Select(self, trusted, size)
+	if errors.Is(err, ErrNotEnoughParties) && len(trusted) < len(candidates) {
+		return s.strategy.Select(self, candidates, size)
+	}
+	return signers, err
+}
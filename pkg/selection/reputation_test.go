@@ -0,0 +1,85 @@
+package selection
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReputation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reputation.json")
+	store, err := NewFileReputationStore(path)
+	require.NoError(t, err)
+	r := NewReputation(store)
+
+	parties := []party.ID{"a", "b", "c"}
+	require.NoError(t, r.Completed(parties))
+	require.NoError(t, r.Aborted(parties, protocol.Error{
+		Culprits: []party.ID{"b"},
+		Err:      errors.New("invalid proof"),
+		Code:     protocol.CodeVerificationFailed,
+	}))
+	require.NoError(t, r.Aborted(parties, protocol.Error{
+		Culprits: []party.ID{"c"},
+		Err:      errors.New("aborted by other party"),
+		Code:     protocol.CodeAbortedByPeer,
+	}))
+	require.NoError(t, r.TimedOut(parties, []party.ID{"c"}))
+	// errors naming no culprit are not charged to anyone
+	require.NoError(t, r.Aborted(parties, errors.New("storage failure")))
+
+	// the statistics survive a restart
+	store, err = NewFileReputationStore(path)
+	require.NoError(t, err)
+	stats, err := NewReputation(store).Stats()
+	require.NoError(t, err)
+	assert.Equal(t, 5, stats["a"].Sessions)
+	assert.Zero(t, stats["a"].Incidents())
+	assert.Equal(t, 1, stats["b"].FailedProofs)
+	assert.Equal(t, 1, stats["c"].Aborts)
+	assert.Equal(t, 1, stats["c"].Timeouts)
+	assert.InDelta(t, 0.4, stats["c"].FailureRate(), 1e-9)
+	assert.False(t, stats["c"].LastIncident.IsZero())
+}
+
+func TestReliable(t *testing.T) {
+	r := NewReputation(NewInMemoryReputationStore())
+	require.NoError(t, r.TimedOut([]party.ID{"a", "b", "c", "d"}, []party.ID{"b"}))
+
+	tracker := NewTracker()
+	candidates, err := r.Annotate(tracker.Candidates([]party.ID{"a", "b", "c", "d"}))
+	require.NoError(t, err)
+	strategy := Reliable(RoundRobin(0), DefaultMaxFailureRate)
+
+	signers, err := strategy.Select("a", candidates, 3)
+	require.NoError(t, err)
+	assert.Equal(t, party.IDSlice{"a", "c", "d"}, signers)
+
+	// b is only selected when there are not enough other parties
+	signers, err = strategy.Select("a", candidates, 4)
+	require.NoError(t, err)
+	assert.Equal(t, party.IDSlice{"a", "b", "c", "d"}, signers)
+}
+
+func TestFileReputationStoreFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "reputation.json")
+	store, err := NewFileReputationStore(path)
+	require.NoError(t, err)
+	r := NewReputation(store)
+	require.NoError(t, r.Completed([]party.ID{"a", "b"}))
+
+	// a session is written at once, and none of its parties is updated if the write fails
+	store.path = filepath.Join(dir, "missing", "reputation.json")
+	assert.Error(t, r.TimedOut([]party.ID{"a", "b", "c"}, []party.ID{"c"}))
+	stats, err := r.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats["a"].Sessions)
+	assert.Equal(t, 1, stats["b"].Sessions)
+	assert.NotContains(t, stats, party.ID("c"))
+}
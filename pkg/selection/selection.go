@@ -0,0 +1,240 @@
+// Package selection picks the signing subset of a key when more parties are available than the threshold requires.
+//
+// A Tracker collects the health of the parties: when they were last seen, as reported by the heartbeats
+// of running sessions, their latency, as measured by the transport, and the number of sessions they are running.
+// A Strategy then ranks the parties which are alive, and the best ones sign.
+//
+// A Reputation keeps statistics of the sessions which failed because of each party, so that the Reliable
+// strategy can deprioritize parties which often time out or send invalid messages.
+package selection
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+)
+
+// ErrNotEnoughParties is returned when fewer parties are alive than the size of the signing subset.
+var ErrNotEnoughParties = errors.New("selection: not enough parties available")
+
+// Health is what is known of a party.
+type Health struct {
+	// LastSeen is the time at which a message or heartbeat was last received from the party, zero if never.
+	LastSeen time.Time
+	// Latency is the smoothed round trip time to the party, zero if unknown.
+	Latency time.Duration
+	// Load is the number of sessions the party is running.
+	Load int
+	// FailureRate is the fraction of the party's past sessions which failed because of it, as set by Reputation.
+	FailureRate float64
+}
+
+// Candidate is a party which may be selected, with its health.
+type Candidate struct {
+	ID     party.ID
+	Health Health
+}
+
+// Strategy selects size parties among the candidates, which always include self.
+// The returned subset includes self, and is sorted.
+type Strategy interface {
+	Select(self party.ID, candidates []Candidate, size int) (party.IDSlice, error)
+}
+
+// maxStale is the time after which a party which was seen is considered down by the default strategies.
+const maxStale = time.Minute
+
+// alive returns the candidates other than self which were seen within maxStale of now,
+// or never seen, since a party which has not taken part in a session yet is not known to be down.
+func alive(self party.ID, candidates []Candidate, now time.Time, maxStale time.Duration) []Candidate {
+	out := make([]Candidate, 0, len(candidates))
+	for _, c := range candidates {
+		if c.ID == self {
+			continue
+		}
+		if maxStale > 0 && !c.Health.LastSeen.IsZero() && now.Sub(c.Health.LastSeen) > maxStale {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// pick returns self and the first size-1 candidates.
+func pick(self party.ID, ranked []Candidate, size int) (party.IDSlice, error) {
+	if size < 1 || len(ranked) < size-1 {
+		return nil, fmt.Errorf("%w: %d alive, %d needed", ErrNotEnoughParties, len(ranked)+1, size)
+	}
+	ids := []party.ID{self}
+	for _, c := range ranked[:size-1] {
+		ids = append(ids, c.ID)
+	}
+	return party.NewIDSlice(ids), nil
+}
+
+// latencyLess orders parties by latency, parties of unknown latency last, then by most recently seen, then by ID.
+func latencyLess(a, b Candidate) bool {
+	la, lb := a.Health.Latency, b.Health.Latency
+	if (la == 0) != (lb == 0) {
+		return la != 0
+	}
+	if la != lb {
+		return la < lb
+	}
+	if !a.Health.LastSeen.Equal(b.Health.LastSeen) {
+		return a.Health.LastSeen.After(b.Health.LastSeen)
+	}
+	return a.ID < b.ID
+}
+
+type latencyAware struct {
+	maxStale time.Duration
+}
+
+// LatencyAware returns a Strategy selecting the parties with the lowest latency, among those seen within maxStale.
+// Parties of unknown latency are selected last, most recently seen first.
+// A maxStale of 0 uses one minute.
+func LatencyAware(maxStale time.Duration) Strategy {
+	return &latencyAware{maxStale: staleOrDefault(maxStale)}
+}
+
+func (s *latencyAware) Select(self party.ID, candidates []Candidate, size int) (party.IDSlice, error) {
+	ranked := alive(self, candidates, time.Now(), s.maxStale)
+	sort.SliceStable(ranked, func(i, j int) bool { return latencyLess(ranked[i], ranked[j]) })
+	return pick(self, ranked, size)
+}
+
+type loadAware struct {
+	maxStale time.Duration
+}
+
+// LoadAware returns a Strategy selecting the parties running the fewest sessions, among those seen within maxStale.
+// Ties are broken by latency. A maxStale of 0 uses one minute.
+func LoadAware(maxStale time.Duration) Strategy {
+	return &loadAware{maxStale: staleOrDefault(maxStale)}
+}
+
+func (s *loadAware) Select(self party.ID, candidates []Candidate, size int) (party.IDSlice, error) {
+	ranked := alive(self, candidates, time.Now(), s.maxStale)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Health.Load != ranked[j].Health.Load {
+			return ranked[i].Health.Load < ranked[j].Health.Load
+		}
+		return latencyLess(ranked[i], ranked[j])
+	})
+	return pick(self, ranked, size)
+}
+
+type roundRobin struct {
+	maxStale time.Duration
+
+	mtx  sync.Mutex
+	next int
+}
+
+// RoundRobin returns a Strategy rotating through the parties seen within maxStale, in the order of their IDs,
+// so that signing load is spread evenly. A maxStale of 0 uses one minute.
+func RoundRobin(maxStale time.Duration) Strategy {
+	return &roundRobin{maxStale: staleOrDefault(maxStale)}
+}
+
+func (s *roundRobin) Select(self party.ID, candidates []Candidate, size int) (party.IDSlice, error) {
+	ranked := alive(self, candidates, time.Now(), s.maxStale)
+	sort.Slice(ranked, func(i, j int) bool { return ranked[i].ID < ranked[j].ID })
+	if len(ranked) > 0 {
+		s.mtx.Lock()
+		offset := s.next % len(ranked)
+		s.next = offset + size - 1
+		s.mtx.Unlock()
+		ranked = append(ranked[offset:], ranked[:offset]...)
+	}
+	return pick(self, ranked, size)
+}
+
+func staleOrDefault(d time.Duration) time.Duration {
+	if d <= 0 {
+		return maxStale
+	}
+	return d
+}
+
+// latencyWeight is the weight of a new sample in the smoothed latency, as for TCP's SRTT.
+const latencyWeight = 0.125
+
+// Tracker collects the health of parties. It is safe for concurrent use.
+type Tracker struct {
+	mtx    sync.Mutex
+	health map[party.ID]Health
+}
+
+// NewTracker returns an empty Tracker.
+func NewTracker() *Tracker {
+	return &Tracker{health: map[party.ID]Health{}}
+}
+
+// Observe records the times at which parties were last seen, as returned by protocol.MultiHandler.LastSeen.
+// Times older than the ones already recorded are ignored.
+func (t *Tracker) Observe(lastSeen map[party.ID]time.Time) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	for id, seen := range lastSeen {
+		h := t.health[id]
+		if seen.After(h.LastSeen) {
+			h.LastSeen = seen
+			t.health[id] = h
+		}
+	}
+}
+
+// ObserveLatency records a round trip time to a party, measured by the transport.
+func (t *Tracker) ObserveLatency(id party.ID, rtt time.Duration) {
+	if rtt <= 0 {
+		return
+	}
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	h := t.health[id]
+	if h.Latency == 0 {
+		h.Latency = rtt
+	} else {
+		h.Latency += time.Duration(latencyWeight * float64(rtt-h.Latency))
+	}
+	t.health[id] = h
+}
+
+// SetLoad records the number of sessions each party is running. Parties omitted from load are set to 0.
+func (t *Tracker) SetLoad(load map[party.ID]int) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	for id, h := range t.health {
+		h.Load = load[id]
+		t.health[id] = h
+	}
+	for id, n := range load {
+		h := t.health[id]
+		h.Load = n
+		t.health[id] = h
+	}
+}
+
+// Health returns what is known of a party.
+func (t *Tracker) Health(id party.ID) Health {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.health[id]
+}
+
+// Candidates returns the parties with their health.
+func (t *Tracker) Candidates(parties []party.ID) []Candidate {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	candidates := make([]Candidate, 0, len(parties))
+	for _, id := range parties {
+		candidates = append(candidates, Candidate{ID: id, Health: t.health[id]})
+	}
+	return candidates
+}
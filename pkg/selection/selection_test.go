@@ -0,0 +1,60 @@
+package selection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrategies(t *testing.T) {
+	now := time.Now()
+	tracker := NewTracker()
+	tracker.Observe(map[party.ID]time.Time{
+		"b": now,
+		"c": now.Add(-time.Second),
+		"d": now.Add(-time.Hour),
+		"e": now,
+	})
+	tracker.ObserveLatency("b", 80*time.Millisecond)
+	tracker.ObserveLatency("c", 20*time.Millisecond)
+	tracker.ObserveLatency("d", time.Millisecond)
+	tracker.SetLoad(map[party.ID]int{"b": 0, "c": 3, "e": 1})
+	candidates := tracker.Candidates([]party.ID{"a", "b", "c", "d", "e"})
+
+	// d is stale, and e of unknown latency
+	signers, err := LatencyAware(0).Select("a", candidates, 3)
+	require.NoError(t, err)
+	assert.Equal(t, party.IDSlice{"a", "b", "c"}, signers)
+
+	signers, err = LoadAware(0).Select("a", candidates, 3)
+	require.NoError(t, err)
+	assert.Equal(t, party.IDSlice{"a", "b", "e"}, signers)
+
+	rr := RoundRobin(0)
+	signers, err = rr.Select("a", candidates, 2)
+	require.NoError(t, err)
+	assert.Equal(t, party.IDSlice{"a", "b"}, signers)
+	signers, err = rr.Select("a", candidates, 2)
+	require.NoError(t, err)
+	assert.Equal(t, party.IDSlice{"a", "c"}, signers)
+	signers, err = rr.Select("a", candidates, 3)
+	require.NoError(t, err)
+	assert.Equal(t, party.IDSlice{"a", "b", "e"}, signers)
+
+	_, err = LatencyAware(0).Select("a", candidates, 5)
+	assert.ErrorIs(t, err, ErrNotEnoughParties)
+}
+
+func TestTrackerLatency(t *testing.T) {
+	tracker := NewTracker()
+	tracker.ObserveLatency("b", 100*time.Millisecond)
+	tracker.ObserveLatency("b", 20*time.Millisecond)
+	assert.Equal(t, 90*time.Millisecond, tracker.Health("b").Latency)
+
+	tracker.Observe(map[party.ID]time.Time{"b": time.Unix(2, 0)})
+	tracker.Observe(map[party.ID]time.Time{"b": time.Unix(1, 0)})
+	assert.Equal(t, time.Unix(2, 0), tracker.Health("b").LastSeen)
+}
@@ -0,0 +1,148 @@
+package thresholdenc
+
+import (
+	"fmt"
+
+	"github.com/mr-shifu/mpc-lib/core/hash"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	zkdleq "github.com/mr-shifu/mpc-lib/core/zk/dleq"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp/config"
+)
+
+// Committee is the public key material of the parties able to decrypt.
+type Committee struct {
+	Group     curve.Curve
+	Threshold int
+	// Shares maps each party to its public key share Xⱼ = xⱼ⋅G.
+	Shares map[party.ID]curve.Point
+}
+
+// NewCommittee returns the committee of a CMP config.
+func NewCommittee(c *config.Config) *Committee {
+	shares := make(map[party.ID]curve.Point, len(c.Public))
+	for j, public := range c.Public {
+		shares[j] = public.ECDSA
+	}
+	return &Committee{
+		Group:     c.Group,
+		Threshold: c.Threshold,
+		Shares:    shares,
+	}
+}
+
+// PublicKey returns the public key X = ∑ⱼ λⱼ⋅Xⱼ to encrypt to.
+func (c *Committee) PublicKey() curve.Point {
+	ids := c.partyIDs()
+	l := polynomial.Lagrange(c.Group, ids)
+	sum := c.Group.NewPoint()
+	for _, j := range ids {
+		sum = sum.Add(l[j].Act(c.Shares[j]))
+	}
+	return sum
+}
+
+// DecryptionShare is the contribution Dᵢ = xᵢ⋅R of a party to the decryption of a ciphertext.
+type DecryptionShare struct {
+	ID party.ID
+	// D = xᵢ⋅R
+	D curve.Point
+	// Proof shows that log_G(Xᵢ) = log_R(Dᵢ).
+	Proof *zkdleq.Proof
+}
+
+// NewDecryptionShare returns the decryption share of party `id` with key share `secret`.
+//
+// A share only allows decrypting the given ciphertext, and can be sent to the other parties in the clear.
+func NewDecryptionShare(id party.ID, secret curve.Scalar, ct *Ciphertext) (*DecryptionShare, error) {
+	if err := ct.Validate(); err != nil {
+		return nil, err
+	}
+	D := secret.Act(ct.R)
+	return &DecryptionShare{
+		ID: id,
+		D:  D,
+		Proof: zkdleq.NewProof(secret.Curve(), shareHash(id), zkdleq.Public{
+			H: ct.R,
+			X: secret.ActOnBase(),
+			Y: D,
+		}, zkdleq.Private{A: secret}),
+	}, nil
+}
+
+// VerifyShare returns an error if the share was not created with the key share of its sender.
+func (c *Committee) VerifyShare(ct *Ciphertext, share *DecryptionShare) error {
+	if share == nil || share.D == nil {
+		return ErrInvalidShare
+	}
+	X, ok := c.Shares[share.ID]
+	if !ok {
+		return fmt.Errorf("%w: %s is not in the committee", ErrInvalidShare, share.ID)
+	}
+	if !share.Proof.Verify(shareHash(share.ID), zkdleq.Public{H: ct.R, X: X, Y: share.D}) {
+		return fmt.Errorf("%w: invalid proof from %s", ErrInvalidShare, share.ID)
+	}
+	return nil
+}
+
+// Decrypt verifies the decryption shares and recovers the plaintext from the first t+1 valid ones.
+//
+// The returned culprits are the parties whose share was invalid.
+func (c *Committee) Decrypt(ct *Ciphertext, label []byte, shares []*DecryptionShare) (plaintext []byte, culprits []party.ID, err error) {
+	if err = ct.Validate(); err != nil {
+		return nil, nil, err
+	}
+	valid := make(map[party.ID]curve.Point, c.Threshold+1)
+	ids := make([]party.ID, 0, c.Threshold+1)
+	for _, share := range shares {
+		if len(ids) == c.Threshold+1 {
+			break
+		}
+		if share == nil {
+			continue
+		}
+		if _, ok := valid[share.ID]; ok {
+			continue
+		}
+		if err := c.VerifyShare(ct, share); err != nil {
+			culprits = append(culprits, share.ID)
+			continue
+		}
+		valid[share.ID] = share.D
+		ids = append(ids, share.ID)
+	}
+	if len(ids) <= c.Threshold {
+		return nil, culprits, fmt.Errorf("%w: got %d, need %d", ErrNotEnoughShares, len(ids), c.Threshold+1)
+	}
+
+	// x⋅R = ∑ⱼ λⱼ⋅Dⱼ
+	l := polynomial.Lagrange(c.Group, ids)
+	shared := c.Group.NewPoint()
+	for _, j := range ids {
+		shared = shared.Add(l[j].Act(valid[j]))
+	}
+	plaintext, err = ct.decrypt(shared, label)
+	return plaintext, culprits, err
+}
+
+func (c *Committee) partyIDs() party.IDSlice {
+	ids := make([]party.ID, 0, len(c.Shares))
+	for j := range c.Shares {
+		ids = append(ids, j)
+	}
+	return party.NewIDSlice(ids)
+}
+
+// shareHash binds the proof of a decryption share to its sender.
+func shareHash(id party.ID) *hash.Hash {
+	return hash.New(hash.BytesWithDomain{TheDomain: "Threshold Decryption Share", Bytes: []byte(id)})
+}
+
+// EmptyDecryptionShare creates an empty DecryptionShare with a fixed group, ready for unmarshalling.
+func EmptyDecryptionShare(group curve.Curve) *DecryptionShare {
+	return &DecryptionShare{
+		D:     group.NewPoint(),
+		Proof: zkdleq.Empty(group),
+	}
+}
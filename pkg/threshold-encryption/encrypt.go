@@ -0,0 +1,131 @@
+// Package thresholdenc implements ECIES-like encryption to the public key of a committee.
+//
+// Anyone can encrypt to the committee's public key X = x⋅G, but decrypting requires a quorum
+// of t+1 parties: each party publishes a decryption share Dᵢ = xᵢ⋅R of the ephemeral key R,
+// together with a proof that it was computed with its key share, and the shared point x⋅R
+// is recovered by Lagrange interpolation of the shares.
+// The key material is the same as for signing, so a committee created by CMP or FROST keygen
+// can be used as is.
+package thresholdenc
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/hash"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+var (
+	ErrInvalidCiphertext = errors.New("thresholdenc: invalid ciphertext")
+	ErrInvalidShare      = errors.New("thresholdenc: invalid decryption share")
+	ErrNotEnoughShares   = errors.New("thresholdenc: not enough decryption shares")
+)
+
+// Ciphertext is a message encrypted to a committee.
+type Ciphertext struct {
+	// R = r⋅G
+	R curve.Point
+	// Data = AEAD(H(r⋅X, R), plaintext)
+	Data []byte
+}
+
+// Encrypt encrypts plaintext to the committee public key `public`.
+//
+// The label is authenticated but not encrypted, and must be given again when decrypting.
+// It can be used to bind the ciphertext to its context, such as the purpose of the payload.
+func Encrypt(public curve.Point, plaintext, label []byte) (*Ciphertext, error) {
+	if public.IsIdentity() {
+		return nil, errors.New("thresholdenc: public key is identity")
+	}
+	r := sample.ScalarUnit(rand.Reader, public.Curve())
+	R := r.ActOnBase()
+	aead, err := encryptionKey(r.Act(public), R)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	return &Ciphertext{
+		R:    R,
+		Data: aead.Seal(nil, nonce, plaintext, label),
+	}, nil
+}
+
+// decrypt opens the ciphertext with the shared point x⋅R.
+func (ct *Ciphertext) decrypt(shared curve.Point, label []byte) ([]byte, error) {
+	aead, err := encryptionKey(shared, ct.R)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	plaintext, err := aead.Open(nil, nonce, ct.Data, label)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCiphertext, err)
+	}
+	return plaintext, nil
+}
+
+// Validate returns an error if the ciphertext cannot be decrypted by any committee.
+func (ct *Ciphertext) Validate() error {
+	if ct == nil || ct.R == nil || ct.R.IsIdentity() {
+		return fmt.Errorf("%w: ephemeral key is identity", ErrInvalidCiphertext)
+	}
+	if len(ct.Data) < chacha20poly1305.Overhead {
+		return fmt.Errorf("%w: data is too short", ErrInvalidCiphertext)
+	}
+	return nil
+}
+
+// EmptyCiphertext creates an empty Ciphertext with a fixed group, ready for unmarshalling.
+func EmptyCiphertext(group curve.Curve) *Ciphertext {
+	return &Ciphertext{R: group.NewPoint()}
+}
+
+type ciphertextMarshal struct {
+	R    curve.Point
+	Data []byte
+}
+
+func (ct *Ciphertext) MarshalBinary() ([]byte, error) {
+	return cbor.Marshal(&ciphertextMarshal{R: ct.R, Data: ct.Data})
+}
+
+func (ct *Ciphertext) UnmarshalBinary(data []byte) error {
+	if ct.R == nil {
+		return errors.New("ciphertext must be initialized using EmptyCiphertext")
+	}
+	cm := &ciphertextMarshal{R: ct.R}
+	if err := cbor.Unmarshal(data, cm); err != nil {
+		return fmt.Errorf("thresholdenc: %w", err)
+	}
+	ct.R, ct.Data = cm.R, cm.Data
+	return ct.Validate()
+}
+
+// encryptionKey derives the AEAD key from the shared point and the ephemeral public key.
+// Since the ephemeral key is used only once, a zero nonce is safe.
+func encryptionKey(shared, R curve.Point) (cipher.AEAD, error) {
+	sharedBytes, err := shared.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("thresholdenc: encryption key: %w", err)
+	}
+	RBytes, err := R.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("thresholdenc: encryption key: %w", err)
+	}
+	h := hash.New(
+		hash.BytesWithDomain{TheDomain: "Threshold Encryption Shared Point", Bytes: sharedBytes},
+		hash.BytesWithDomain{TheDomain: "Threshold Encryption Ephemeral", Bytes: RBytes},
+	)
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(h.Digest(), key); err != nil {
+		return nil, fmt.Errorf("thresholdenc: encryption key: %w", err)
+	}
+	return chacha20poly1305.New(key)
+}
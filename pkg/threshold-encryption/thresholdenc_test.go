@@ -0,0 +1,69 @@
+package thresholdenc
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThresholdDecrypt(t *testing.T) {
+	group := curve.Secp256k1{}
+	ids := test.PartyIDs(5)
+	threshold := 2
+
+	secret := sample.Scalar(rand.Reader, group)
+	f := polynomial.NewPolynomial(group, threshold, secret)
+	secrets := make(map[party.ID]curve.Scalar, len(ids))
+	committee := &Committee{Group: group, Threshold: threshold, Shares: map[party.ID]curve.Point{}}
+	for _, id := range ids {
+		secrets[id] = f.Evaluate(id.Scalar(group))
+		committee.Shares[id] = secrets[id].ActOnBase()
+	}
+	require.True(t, secret.ActOnBase().Equal(committee.PublicKey()))
+
+	plaintext, label := []byte("seed"), []byte("backup")
+	ct, err := Encrypt(committee.PublicKey(), plaintext, label)
+	require.NoError(t, err)
+
+	// the ciphertext and shares are sent over the wire
+	data, err := ct.MarshalBinary()
+	require.NoError(t, err)
+	ct = EmptyCiphertext(group)
+	require.NoError(t, ct.UnmarshalBinary(data))
+
+	shares := make([]*DecryptionShare, 0, len(ids))
+	for _, id := range ids[1:] {
+		share, err := NewDecryptionShare(id, secrets[id], ct)
+		require.NoError(t, err)
+		data, err := cbor.Marshal(share)
+		require.NoError(t, err)
+		share = EmptyDecryptionShare(group)
+		require.NoError(t, cbor.Unmarshal(data, share))
+		require.NoError(t, committee.VerifyShare(ct, share))
+		shares = append(shares, share)
+	}
+
+	_, _, err = committee.Decrypt(ct, label, shares[:threshold])
+	assert.ErrorIs(t, err, ErrNotEnoughShares)
+
+	// a share computed with the wrong key is rejected and blamed
+	bad, err := NewDecryptionShare(ids[0], secrets[ids[1]], ct)
+	require.NoError(t, err)
+	assert.ErrorIs(t, committee.VerifyShare(ct, bad), ErrInvalidShare)
+
+	decrypted, culprits, err := committee.Decrypt(ct, label, append([]*DecryptionShare{bad}, shares...))
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+	assert.Equal(t, []party.ID{ids[0]}, culprits)
+
+	_, _, err = committee.Decrypt(ct, []byte("other"), shares)
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+}
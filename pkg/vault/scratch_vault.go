@@ -0,0 +1,176 @@
+package vault
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mr-shifu/mpc-lib/pkg/common/vault"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+var (
+	ErrScratchErased     = errors.New("vault: scratch vault was erased")
+	ErrInvalidScratch    = errors.New("vault: invalid scratch ciphertext")
+	ErrInvalidScratchKey = errors.New("vault: scratch key must be 32 bytes")
+)
+
+// ScratchVault holds the secrets of a session across rounds, such as nonce shares, encrypted in an underlying vault.
+//
+// Secrets are encrypted with XChaCha20-Poly1305 under a session key, bound to their key ID. The underlying vault
+// may therefore be persisted, so that a session can resume after a crash from a snapshot (see
+// protocol.ResumeMultiHandler) without plaintext nonces on disk, as long as the session key is kept elsewhere.
+//
+// Erase deletes the secrets and zeroes the session key once the session is over.
+type ScratchVault struct {
+	lock  sync.Mutex
+	key   []byte
+	vault vault.Vault
+	ids   map[string]struct{}
+}
+
+// NewScratchVault returns a ScratchVault storing its ciphertexts in v, with a 32 bytes session key.
+// The key is copied, and the copy zeroed by Erase.
+func NewScratchVault(v vault.Vault, key []byte) (*ScratchVault, error) {
+	if len(key) != chacha20poly1305.KeySize {
+		return nil, ErrInvalidScratchKey
+	}
+	return &ScratchVault{
+		key:   append([]byte(nil), key...),
+		vault: v,
+		ids:   make(map[string]struct{}),
+	}, nil
+}
+
+func (s *ScratchVault) Import(keyID string, key []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.key == nil {
+		return ErrScratchErased
+	}
+	aead, err := chacha20poly1305.NewX(s.key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(key)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	if err := s.vault.Import(keyID, aead.Seal(nonce, nonce, key, []byte(keyID))); err != nil {
+		return err
+	}
+	s.ids[keyID] = struct{}{}
+	return nil
+}
+
+// Get returns a fresh copy of the plaintext, which the caller should zero once it is no longer needed.
+func (s *ScratchVault) Get(keyID string) ([]byte, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.key == nil {
+		return nil, ErrScratchErased
+	}
+	data, err := s.vault.Get(keyID)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.NewX(s.key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize()+aead.Overhead() {
+		return nil, fmt.Errorf("%w: too short", ErrInvalidScratch)
+	}
+	key, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidScratch, err)
+	}
+	return key, nil
+}
+
+func (s *ScratchVault) Delete(keyID string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.ids, keyID)
+	return s.vault.Delete(keyID)
+}
+
+// Erase deletes all the secrets imported in this vault, and zeroes the session key,
+// so that remaining copies of the ciphertexts cannot be decrypted anymore.
+func (s *ScratchVault) Erase() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var errs []error
+	for keyID := range s.ids {
+		if err := s.vault.Delete(keyID); err != nil {
+			errs = append(errs, err)
+		}
+		delete(s.ids, keyID)
+	}
+	for i := range s.key {
+		s.key[i] = 0
+	}
+	s.key = nil
+	return errors.Join(errs...)
+}
+
+// ScratchVaultFactory wraps each vault of a VaultFactory in a ScratchVault, so that the keystores of a session
+// are all encrypted under the same session key. It can be passed as the scratch vaults of cmp.NewMPC.
+type ScratchVaultFactory struct {
+	lock    sync.Mutex
+	key     []byte
+	vaults  vault.VaultFactory
+	created []*ScratchVault
+}
+
+// NewScratchVaultFactory returns a factory of scratch vaults over the vaults of vf, with a 32 bytes session key.
+func NewScratchVaultFactory(vf vault.VaultFactory, key []byte) (*ScratchVaultFactory, error) {
+	if len(key) != chacha20poly1305.KeySize {
+		return nil, ErrInvalidScratchKey
+	}
+	return &ScratchVaultFactory{
+		key:    append([]byte(nil), key...),
+		vaults: vf,
+	}, nil
+}
+
+// NewVault creates a new ScratchVault over a vault created with cfg.
+func (f *ScratchVaultFactory) NewVault(cfg interface{}) vault.Vault {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	s := &ScratchVault{
+		vault: f.vaults.NewVault(cfg),
+		ids:   make(map[string]struct{}),
+	}
+	// once the factory was erased, the vault is erased as well
+	if f.key != nil {
+		s.key = append([]byte(nil), f.key...)
+	}
+	f.created = append(f.created, s)
+	return s
+}
+
+// Erase erases all the vaults created by the factory, and zeroes the session key.
+func (f *ScratchVaultFactory) Erase() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var errs []error
+	for _, s := range f.created {
+		if err := s.Erase(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	f.created = nil
+	for i := range f.key {
+		f.key[i] = 0
+	}
+	f.key = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,54 @@
+package vault
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScratchVault(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	inner := NewInMemoryVault()
+	f, err := NewScratchVaultFactory(InmemoryVaultFactory{}, key)
+	require.NoError(t, err)
+	s, err := NewScratchVault(inner, key)
+	require.NoError(t, err)
+
+	nonce := []byte("k share")
+	require.NoError(t, s.Import("k", nonce))
+	stored, err := inner.Get("k")
+	require.NoError(t, err)
+	assert.NotContains(t, string(stored), string(nonce))
+
+	got, err := s.Get("k")
+	require.NoError(t, err)
+	assert.Equal(t, nonce, got)
+
+	// a resumed session with the same key reads the persisted secret
+	resumed, err := NewScratchVault(inner, key)
+	require.NoError(t, err)
+	got, err = resumed.Get("k")
+	require.NoError(t, err)
+	assert.Equal(t, nonce, got)
+
+	// ciphertexts are bound to their key ID
+	require.NoError(t, inner.Import("other", stored))
+	_, err = s.Get("other")
+	assert.ErrorIs(t, err, ErrInvalidScratch)
+
+	require.NoError(t, s.Erase())
+	_, err = inner.Get("k")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = s.Get("other")
+	assert.ErrorIs(t, err, ErrScratchErased)
+
+	v := f.NewVault(nil)
+	require.NoError(t, v.Import("k", nonce))
+	require.NoError(t, f.Erase())
+	_, err = v.Get("k")
+	assert.ErrorIs(t, err, ErrScratchErased)
+	_, err = NewScratchVault(inner, key[:16])
+	assert.ErrorIs(t, err, ErrInvalidScratchKey)
+}
@@ -0,0 +1,348 @@
+// Package vrf implements a verifiable random function evaluated by a committee, with its existing key shares.
+//
+// The VRF follows the construction of the elliptic curve VRF of RFC 9381: the output for an input x is β = H(Γ)
+// with Γ = k⋅H₁(x), and the proof π = (Γ, c, s) shows that log_G(Y) = log_H₁(x)(Γ) for the public key Y = k⋅G.
+// It does not implement any of the RFC's suites: H₁, the challenge and the output are hashed with this library's
+// hash and domains, so proofs are only verified by this package.
+//
+// Only secp256k1 is supported, since H₁ decodes candidate points in their SEC 1 compressed encoding.
+// Anyone holding Y can verify π and recompute β, which makes the output suitable for leader election and
+// lotteries: it is unpredictable without the help of t+1 parties, and unique for a given key and input.
+//
+// The evaluation takes two rounds between a quorum of t+1 parties and a combiner:
+//
+//   - each party sends a Commitment with its share Γᵢ = kᵢ⋅H₁(x) and two nonce pairs, see Commit;
+//   - given the commitments of the quorum, each party sends a Response zᵢ, see Respond;
+//   - the combiner verifies the responses and aggregates them into a Proof, see Combine.
+//
+// As in FROST, the nonces are bound to the commitments of the whole quorum, so that sessions may run concurrently.
+// Nonces must never be used twice, which Respond enforces.
+package vrf
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/mr-shifu/mpc-lib/core/hash"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	thresholdenc "github.com/mr-shifu/mpc-lib/pkg/threshold-encryption"
+)
+
+// OutputSize is the size in bytes of the VRF output.
+const OutputSize = 32
+
+var (
+	ErrInvalidCommitments = errors.New("vrf: invalid commitments")
+	ErrInvalidResponse    = errors.New("vrf: invalid response")
+	ErrInvalidProof       = errors.New("vrf: invalid proof")
+	ErrNoncesUsed         = errors.New("vrf: nonces already used")
+	ErrUnsupportedCurve   = errors.New("vrf: unsupported curve")
+	errHashToCurveFails   = errors.New("vrf: failed to hash input to the curve")
+)
+
+// Nonces is the state of a party between its Commitment and its Response.
+//
+// It contains the secret nonces, and must not be shared.
+type Nonces struct {
+	id    party.ID
+	input []byte
+	// d, e are the nonces, set to nil once used.
+	d, e curve.Scalar
+}
+
+// Commitment is the first message of a party to the combiner.
+type Commitment struct {
+	ID party.ID
+	// Gamma = kᵢ⋅H₁(x)
+	Gamma curve.Point
+	// D = dᵢ⋅G, E = eᵢ⋅G
+	D, E curve.Point
+	// DH = dᵢ⋅H₁(x), EH = eᵢ⋅H₁(x)
+	DH, EH curve.Point
+}
+
+// Response is the second message of a party to the combiner.
+type Response struct {
+	ID party.ID
+	// Z = dᵢ + ρᵢ⋅eᵢ + c⋅λᵢ⋅kᵢ
+	Z curve.Scalar
+}
+
+// Proof is the VRF proof π = (Γ, c, s) of RFC 9381.
+type Proof struct {
+	// Gamma = k⋅H₁(x)
+	Gamma curve.Point
+	C     curve.Scalar
+	S     curve.Scalar
+}
+
+// Commit starts the evaluation of the VRF on input by party `id` with key share `secret`.
+func Commit(id party.ID, secret curve.Scalar, input []byte) (*Nonces, *Commitment, error) {
+	group := secret.Curve()
+	P, err := hashToPoint(group, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	d, e := sample.ScalarUnit(rand.Reader, group), sample.ScalarUnit(rand.Reader, group)
+	nonces := &Nonces{id: id, input: input, d: d, e: e}
+	return nonces, &Commitment{
+		ID:    id,
+		Gamma: secret.Act(P),
+		D:     d.ActOnBase(),
+		E:     e.ActOnBase(),
+		DH:    d.Act(P),
+		EH:    e.Act(P),
+	}, nil
+}
+
+// Respond returns the response of the party of nonces, given the commitments of the quorum, including its own.
+//
+// The nonces are erased, so that a second call returns ErrNoncesUsed.
+func Respond(nonces *Nonces, secret curve.Scalar, committee *thresholdenc.Committee, commitments []*Commitment) (*Response, error) {
+	if nonces.d == nil || nonces.e == nil {
+		return nil, ErrNoncesUsed
+	}
+	s, err := newSession(committee, nonces.input, commitments)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.commitments[nonces.id]; !ok {
+		return nil, fmt.Errorf("%w: %s is not in the quorum", ErrInvalidCommitments, nonces.id)
+	}
+	// zᵢ = dᵢ + ρᵢ⋅eᵢ + c⋅λᵢ⋅kᵢ
+	z := committee.Group.NewScalar().Set(s.c).Mul(s.lagrange[nonces.id]).Mul(secret)
+	z.Add(committee.Group.NewScalar().Set(s.binding[nonces.id]).Mul(nonces.e)).Add(nonces.d)
+	nonces.d, nonces.e = nil, nil
+	return &Response{ID: nonces.id, Z: z}, nil
+}
+
+// Combine verifies the responses of the quorum, and returns the proof of the VRF evaluation on input.
+//
+// The returned culprits are the parties whose response, or share Γᵢ, was invalid.
+// The evaluation must then be restarted without them, with new commitments.
+func Combine(committee *thresholdenc.Committee, input []byte, commitments []*Commitment, responses []*Response) (proof *Proof, culprits []party.ID, err error) {
+	s, err := newSession(committee, input, commitments)
+	if err != nil {
+		return nil, nil, err
+	}
+	group := committee.Group
+	zs := make(map[party.ID]curve.Scalar, len(responses))
+	for _, r := range responses {
+		if r != nil && r.Z != nil {
+			zs[r.ID] = r.Z
+		}
+	}
+	sum := group.NewScalar()
+	for _, j := range s.ids {
+		z, ok := zs[j]
+		if !ok || !s.verifyResponse(committee, j, z) {
+			culprits = append(culprits, j)
+			continue
+		}
+		sum.Add(z)
+	}
+	if len(culprits) > 0 {
+		return nil, culprits, fmt.Errorf("%w: from %v", ErrInvalidResponse, culprits)
+	}
+	return &Proof{Gamma: s.gamma, C: s.c, S: sum}, nil, nil
+}
+
+// Verify returns the output of the VRF for input, if proof is valid for the public key.
+func Verify(public curve.Point, input []byte, proof *Proof) ([]byte, error) {
+	if proof == nil || proof.Gamma == nil || proof.C == nil || proof.S == nil || proof.Gamma.IsIdentity() {
+		return nil, ErrInvalidProof
+	}
+	group := public.Curve()
+	P, err := hashToPoint(group, input)
+	if err != nil {
+		return nil, err
+	}
+	// U = s⋅G - c⋅Y, V = s⋅H₁(x) - c⋅Γ
+	U := proof.S.ActOnBase().Sub(proof.C.Act(public))
+	V := proof.S.Act(P).Sub(proof.C.Act(proof.Gamma))
+	c, err := challenge(public, P, proof.Gamma, U, V)
+	if err != nil {
+		return nil, err
+	}
+	if !c.Equal(proof.C) {
+		return nil, ErrInvalidProof
+	}
+	return proof.Output()
+}
+
+// Output returns the VRF output β = H(Γ) of the proof, which must have been verified with Verify.
+func (p *Proof) Output() ([]byte, error) {
+	gamma, err := p.Gamma.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("vrf: %w", err)
+	}
+	h := hash.New(hash.BytesWithDomain{TheDomain: "VRF Output", Bytes: gamma})
+	output := make([]byte, OutputSize)
+	if _, err := io.ReadFull(h.Digest(), output); err != nil {
+		return nil, fmt.Errorf("vrf: %w", err)
+	}
+	return output, nil
+}
+
+// EmptyCommitment creates an empty Commitment with a fixed group, ready for unmarshalling.
+func EmptyCommitment(group curve.Curve) *Commitment {
+	return &Commitment{
+		Gamma: group.NewPoint(),
+		D:     group.NewPoint(),
+		E:     group.NewPoint(),
+		DH:    group.NewPoint(),
+		EH:    group.NewPoint(),
+	}
+}
+
+// EmptyResponse creates an empty Response with a fixed group, ready for unmarshalling.
+func EmptyResponse(group curve.Curve) *Response {
+	return &Response{Z: group.NewScalar()}
+}
+
+// EmptyProof creates an empty Proof with a fixed group, ready for unmarshalling.
+func EmptyProof(group curve.Curve) *Proof {
+	return &Proof{
+		Gamma: group.NewPoint(),
+		C:     group.NewScalar(),
+		S:     group.NewScalar(),
+	}
+}
+
+// session is the state shared by the quorum once the commitments are known.
+type session struct {
+	ids         []party.ID
+	commitments map[party.ID]*Commitment
+	lagrange    map[party.ID]curve.Scalar
+	// p = H₁(x)
+	p curve.Point
+	// binding maps each party to its binding factor ρᵢ.
+	binding map[party.ID]curve.Scalar
+	// gamma = ∑ⱼ λⱼ⋅Γⱼ
+	gamma curve.Point
+	// r = ∑ⱼ Dⱼ + ρⱼ⋅Eⱼ, h = ∑ⱼ DHⱼ + ρⱼ⋅EHⱼ
+	r, h curve.Point
+	c    curve.Scalar
+}
+
+func newSession(committee *thresholdenc.Committee, input []byte, commitments []*Commitment) (*session, error) {
+	group := committee.Group
+	s := &session{commitments: make(map[party.ID]*Commitment, len(commitments))}
+	for _, c := range commitments {
+		if c == nil || c.Gamma == nil || c.D == nil || c.E == nil || c.DH == nil || c.EH == nil {
+			return nil, fmt.Errorf("%w: missing fields", ErrInvalidCommitments)
+		}
+		if _, ok := committee.Shares[c.ID]; !ok {
+			return nil, fmt.Errorf("%w: %s is not in the committee", ErrInvalidCommitments, c.ID)
+		}
+		if _, ok := s.commitments[c.ID]; ok {
+			return nil, fmt.Errorf("%w: duplicate commitment from %s", ErrInvalidCommitments, c.ID)
+		}
+		if c.D.IsIdentity() || c.E.IsIdentity() {
+			return nil, fmt.Errorf("%w: identity nonce from %s", ErrInvalidCommitments, c.ID)
+		}
+		s.commitments[c.ID] = c
+		s.ids = append(s.ids, c.ID)
+	}
+	if len(s.ids) != committee.Threshold+1 {
+		return nil, fmt.Errorf("%w: got %d, need %d", ErrInvalidCommitments, len(s.ids), committee.Threshold+1)
+	}
+	sort.Slice(s.ids, func(i, j int) bool { return s.ids[i] < s.ids[j] })
+
+	var err error
+	if s.p, err = hashToPoint(group, input); err != nil {
+		return nil, err
+	}
+	s.lagrange = polynomial.Lagrange(group, s.ids)
+	s.binding = make(map[party.ID]curve.Scalar, len(s.ids))
+	s.gamma, s.r, s.h = group.NewPoint(), group.NewPoint(), group.NewPoint()
+	for _, j := range s.ids {
+		c := s.commitments[j]
+		if s.binding[j], err = s.bindingFactor(group, j, input); err != nil {
+			return nil, err
+		}
+		s.gamma = s.gamma.Add(s.lagrange[j].Act(c.Gamma))
+		s.r = s.r.Add(c.D).Add(s.binding[j].Act(c.E))
+		s.h = s.h.Add(c.DH).Add(s.binding[j].Act(c.EH))
+	}
+	if s.gamma.IsIdentity() {
+		return nil, fmt.Errorf("%w: Γ is the identity", ErrInvalidCommitments)
+	}
+	if s.c, err = challenge(committee.PublicKey(), s.p, s.gamma, s.r, s.h); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// bindingFactor returns ρⱼ = H(j, x, commitments of the quorum).
+func (s *session) bindingFactor(group curve.Curve, j party.ID, input []byte) (curve.Scalar, error) {
+	h := hash.New(
+		hash.BytesWithDomain{TheDomain: "VRF Binding", Bytes: []byte(j)},
+		hash.BytesWithDomain{TheDomain: "VRF Input", Bytes: input},
+	)
+	for _, id := range s.ids {
+		c := s.commitments[id]
+		if err := h.WriteAny(hash.BytesWithDomain{TheDomain: "VRF Commitment", Bytes: []byte(id)}, c.Gamma, c.D, c.E, c.DH, c.EH); err != nil {
+			return nil, fmt.Errorf("vrf: %w", err)
+		}
+	}
+	return sample.Scalar(h.Digest(), group), nil
+}
+
+// verifyResponse checks that zⱼ⋅G = Dⱼ + ρⱼ⋅Eⱼ + c⋅λⱼ⋅Kⱼ and zⱼ⋅H₁(x) = DHⱼ + ρⱼ⋅EHⱼ + c⋅λⱼ⋅Γⱼ.
+//
+// The second equation fails if Γⱼ was not computed with the key share of j.
+func (s *session) verifyResponse(committee *thresholdenc.Committee, j party.ID, z curve.Scalar) bool {
+	c := s.commitments[j]
+	cl := committee.Group.NewScalar().Set(s.c).Mul(s.lagrange[j])
+	lhs := z.ActOnBase()
+	rhs := c.D.Add(s.binding[j].Act(c.E)).Add(cl.Act(committee.Shares[j]))
+	if !lhs.Equal(rhs) {
+		return false
+	}
+	return z.Act(s.p).Equal(c.DH.Add(s.binding[j].Act(c.EH)).Add(cl.Act(c.Gamma)))
+}
+
+// challenge returns c = H(Y, H₁(x), Γ, U, V).
+func challenge(public, P, gamma, U, V curve.Point) (curve.Scalar, error) {
+	h := hash.New()
+	if err := h.WriteAny(hash.BytesWithDomain{TheDomain: "VRF Challenge", Bytes: []byte(public.Curve().Name())}, public, P, gamma, U, V); err != nil {
+		return nil, fmt.Errorf("vrf: %w", err)
+	}
+	return sample.Scalar(h.Digest(), public.Curve()), nil
+}
+
+// hashToPoint maps the input to a point whose discrete logarithm is unknown, by try-and-increment
+// over the compressed encoding of points (0x02 or 0x03 followed by the x coordinate), like ECVRF-*-TAI.
+func hashToPoint(group curve.Curve, input []byte) (curve.Point, error) {
+	if _, ok := group.(curve.Secp256k1); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurve, group.Name())
+	}
+	base, err := group.NewBasePoint().MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("vrf: %w", err)
+	}
+	candidate := make([]byte, len(base))
+	for counter := 0; counter < 256; counter++ {
+		h := hash.New(
+			hash.BytesWithDomain{TheDomain: "VRF Hash To Curve", Bytes: input},
+			hash.BytesWithDomain{TheDomain: "VRF Hash To Curve Counter", Bytes: []byte{byte(counter)}},
+		)
+		if _, err := io.ReadFull(h.Digest(), candidate); err != nil {
+			return nil, fmt.Errorf("vrf: %w", err)
+		}
+		candidate[0] = 2 | (candidate[0] & 1)
+		P := group.NewPoint()
+		if err := P.UnmarshalBinary(candidate); err != nil || P.IsIdentity() {
+			continue
+		}
+		return P, nil
+	}
+	return nil, errHashToCurveFails
+}
@@ -0,0 +1,118 @@
+package vrf
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/test"
+	thresholdenc "github.com/mr-shifu/mpc-lib/pkg/threshold-encryption"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVRF(t *testing.T) {
+	group := curve.Secp256k1{}
+	ids := test.PartyIDs(4)
+	threshold := 1
+
+	f := polynomial.NewPolynomial(group, threshold, sample.Scalar(rand.Reader, group))
+	secrets := make(map[party.ID]curve.Scalar, len(ids))
+	committee := &thresholdenc.Committee{Group: group, Threshold: threshold, Shares: map[party.ID]curve.Point{}}
+	for _, id := range ids {
+		secrets[id] = f.Evaluate(id.Scalar(group))
+		committee.Shares[id] = secrets[id].ActOnBase()
+	}
+	public := committee.PublicKey()
+
+	commit := func(input []byte, quorum []party.ID) (map[party.ID]*Nonces, []*Commitment) {
+		nonces := make(map[party.ID]*Nonces, len(quorum))
+		commitments := make([]*Commitment, 0, len(quorum))
+		for _, id := range quorum {
+			n, c, err := Commit(id, secrets[id], input)
+			require.NoError(t, err)
+			data, err := cbor.Marshal(c)
+			require.NoError(t, err)
+			c = EmptyCommitment(group)
+			require.NoError(t, cbor.Unmarshal(data, c))
+			nonces[id] = n
+			commitments = append(commitments, c)
+		}
+		return nonces, commitments
+	}
+	evaluate := func(input []byte, quorum []party.ID) []byte {
+		nonces, commitments := commit(input, quorum)
+		responses := make([]*Response, 0, len(quorum))
+		for _, id := range quorum {
+			r, err := Respond(nonces[id], secrets[id], committee, commitments)
+			require.NoError(t, err)
+			responses = append(responses, r)
+		}
+		proof, culprits, err := Combine(committee, input, commitments, responses)
+		require.NoError(t, err)
+		assert.Empty(t, culprits)
+
+		data, err := cbor.Marshal(proof)
+		require.NoError(t, err)
+		proof = EmptyProof(group)
+		require.NoError(t, cbor.Unmarshal(data, proof))
+		output, err := Verify(public, input, proof)
+		require.NoError(t, err)
+
+		// the proof is bound to the input and the key
+		_, err = Verify(public, []byte("another input"), proof)
+		assert.ErrorIs(t, err, ErrInvalidProof)
+		_, err = Verify(sample.Scalar(rand.Reader, group).ActOnBase(), input, proof)
+		assert.ErrorIs(t, err, ErrInvalidProof)
+		return output
+	}
+
+	// the output depends on the input only, not on the nonces nor the quorum
+	round := []byte("round 42")
+	output := evaluate(round, ids[:2])
+	assert.Len(t, output, OutputSize)
+	assert.Equal(t, output, evaluate(round, ids[2:]))
+	assert.NotEqual(t, output, evaluate([]byte("round 43"), ids[:2]))
+
+	// a share Γᵢ computed with another key is blamed
+	quorum := ids[:2]
+	nonces, commitments := commit(round, quorum)
+	_, bad, err := Commit(ids[0], secrets[ids[1]], round)
+	require.NoError(t, err)
+	commitments[0].Gamma = bad.Gamma
+	responses := make([]*Response, 0, len(quorum))
+	for _, id := range quorum {
+		r, err := Respond(nonces[id], secrets[id], committee, commitments)
+		require.NoError(t, err)
+		responses = append(responses, r)
+	}
+	_, culprits, err := Combine(committee, round, commitments, responses)
+	assert.ErrorIs(t, err, ErrInvalidResponse)
+	assert.Equal(t, []party.ID{ids[0]}, culprits)
+
+	// nonces cannot be used twice
+	_, err = Respond(nonces[ids[0]], secrets[ids[0]], committee, commitments)
+	assert.ErrorIs(t, err, ErrNoncesUsed)
+
+	// a quorum of the wrong size is rejected
+	nonces, commitments = commit(round, ids[:3])
+	_, err = Respond(nonces[ids[0]], secrets[ids[0]], committee, commitments)
+	assert.ErrorIs(t, err, ErrInvalidCommitments)
+}
+
+// otherCurve stands for a curve whose points are not encoded like secp256k1 ones.
+type otherCurve struct{ curve.Secp256k1 }
+
+func (otherCurve) Name() string { return "other" }
+
+func TestHashToPointCurves(t *testing.T) {
+	P, err := hashToPoint(curve.Secp256k1{}, []byte("input"))
+	require.NoError(t, err)
+	assert.False(t, P.IsIdentity())
+	_, err = hashToPoint(otherCurve{}, []byte("input"))
+	assert.ErrorIs(t, err, ErrUnsupportedCurve)
+}
@@ -2,6 +2,7 @@ package cmp
 
 import (
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/core/pool"
 	"github.com/mr-shifu/mpc-lib/core/protocol"
 
@@ -28,6 +29,7 @@ import (
 	sw_pedersen "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/pedersen"
 	sw_rid "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/rid"
 	sw_vss "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/vss"
+	pkg_keyopts "github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	comm_config "github.com/mr-shifu/mpc-lib/pkg/mpc/common/config"
 	comm_message "github.com/mr-shifu/mpc-lib/pkg/mpc/common/message"
 	comm_result "github.com/mr-shifu/mpc-lib/pkg/mpc/common/result"
@@ -80,10 +82,16 @@ type MPC struct {
 	pl *pool.Pool
 }
 
+// NewMPC returns the CMP keygen and sign protocols over the given stores.
+//
+// The vaults of scratch hold the secrets a sign session keeps across its rounds, such as the nonce shares and
+// their Paillier encryptions. It may be vf, or a vault.ScratchVaultFactory so that they are stored encrypted
+// under a key which is never persisted.
 func NewMPC(
 	ksf keystore.KeystoreFactory,
 	krf keyopts.KeyOptsFactory,
 	vf vault.VaultFactory,
+	scratch vault.VaultFactory,
 	keycfgstore comm_config.ConfigStore,
 	signcfgstore comm_config.ConfigStore,
 	keystatstore comm_state.MPCStateStore,
@@ -160,42 +168,44 @@ func NewMPC(
 
 	signature := mpc_result.NewSignStore()
 
+	sign_vault := scratch.NewVault(nil)
+
 	gamma_kr := krf.NewKeyOpts(nil)
-	gamma_ks := ksf.NewKeystore(ec_vault, gamma_kr, nil)
+	gamma_ks := ksf.NewKeystore(sign_vault, gamma_kr, nil)
 	gamma_km := sw_ecdsa.NewECDSAKeyManager(gamma_ks, sch_ks, vss_km, &sw_ecdsa.Config{Group: curve.Secp256k1{}})
 
 	signK_kr := krf.NewKeyOpts(nil)
-	signK_ks := ksf.NewKeystore(ec_vault, signK_kr, nil)
+	signK_ks := ksf.NewKeystore(sign_vault, signK_kr, nil)
 	signK_km := sw_ecdsa.NewECDSAKeyManager(signK_ks, sch_ks, vss_km, &sw_ecdsa.Config{Group: curve.Secp256k1{}})
 
 	delta_kr := krf.NewKeyOpts(nil)
-	delta_ks := ksf.NewKeystore(ec_vault, delta_kr, nil)
+	delta_ks := ksf.NewKeystore(sign_vault, delta_kr, nil)
 	delta_km := sw_ecdsa.NewECDSAKeyManager(delta_ks, sch_ks, vss_km, &sw_ecdsa.Config{Group: curve.Secp256k1{}})
 
 	chi_kr := krf.NewKeyOpts(nil)
-	chi_ks := ksf.NewKeystore(ec_vault, chi_kr, nil)
+	chi_ks := ksf.NewKeystore(sign_vault, chi_kr, nil)
 	chi_km := sw_ecdsa.NewECDSAKeyManager(chi_ks, sch_ks, vss_km, &sw_ecdsa.Config{Group: curve.Secp256k1{}})
 
 	bigDelta_kr := krf.NewKeyOpts(nil)
-	bigDelta_ks := ksf.NewKeystore(ec_vault, bigDelta_kr, nil)
+	bigDelta_ks := ksf.NewKeystore(sign_vault, bigDelta_kr, nil)
 	bigDelta_km := sw_ecdsa.NewECDSAKeyManager(bigDelta_ks, sch_ks, vss_km, &sw_ecdsa.Config{Group: curve.Secp256k1{}})
 
-	gamma_pek_vault := vf.NewVault(nil)
+	gamma_pek_vault := scratch.NewVault(nil)
 	gamma_pek_kr := krf.NewKeyOpts(nil)
 	gamma_pek_ks := ksf.NewKeystore(gamma_pek_vault, gamma_pek_kr, nil)
 	gamma_pek_mgr := sw_pek.NewPaillierEncodedKeyManager(gamma_pek_ks)
 
-	signK_pek_vault := vf.NewVault(nil)
+	signK_pek_vault := scratch.NewVault(nil)
 	signK_pek_kr := krf.NewKeyOpts(nil)
 	signK_pek_ks := ksf.NewKeystore(signK_pek_vault, signK_pek_kr, nil)
 	signK_pek_mgr := sw_pek.NewPaillierEncodedKeyManager(signK_pek_ks)
 
-	delta_mta_vault := vf.NewVault(nil)
+	delta_mta_vault := scratch.NewVault(nil)
 	delta_mta_kr := krf.NewKeyOpts(nil)
 	delta_mta_ks := ksf.NewKeystore(delta_mta_vault, delta_mta_kr, nil)
 	delta_mta_km := sw_mta.NewMtAManager(delta_mta_ks)
 
-	chi_mta_vault := vf.NewVault(nil)
+	chi_mta_vault := scratch.NewVault(nil)
 	chi_mta_kr := krf.NewKeyOpts(nil)
 	chi_mta_ks := ksf.NewKeystore(chi_mta_vault, chi_mta_kr, nil)
 	chi_mta_km := sw_mta.NewMtAManager(chi_mta_ks)
@@ -275,7 +285,7 @@ func (mpc *MPC) NewMPCSignManager() *sign.MPCSign {
 		mpc.chi_mta,
 		mpc.sigma,
 		mpc.signature,
-	)
+	).WithChainKeys(mpc.chainKey)
 }
 
 // Config represents the stored state of a party who participated in a successful `Keygen` protocol.
@@ -308,3 +318,36 @@ func (mpc *MPC) Sign(cfg comm_config.SignConfig, pl *pool.Pool) protocol.StartFu
 	mpcsign := mpc.NewMPCSignManager()
 	return mpcsign.StartSign(cfg, pl)
 }
+
+// ResumeSign recreates the round reached by the sign session signID, in order to continue it from a protocol.Snapshot
+// with protocol.ResumeMultiHandler.
+func (mpc *MPC) ResumeSign(signID string, pl *pool.Pool) protocol.ResumeFunc {
+	return mpc.NewMPCSignManager().ResumeSign(signID, pl)
+}
+
+// Presignature returns the record of the presignature computed by the sign session signID,
+// or nil if the session did not compute it yet.
+func (mpc *MPC) Presignature(signID string) *comm_result.Presignature {
+	return mpc.signature.Presignature(signID)
+}
+
+// SetPrecompute enables pools of up to size precomputed Pedersen commitment randomness for the auxiliary parameters
+// of each party, which shifts exponentiations of the sign proofs to Precompute. A size of 0 disables the pools.
+func (mpc *MPC) SetPrecompute(size int) {
+	mpc.pedersen.SetPrecompute(size)
+}
+
+// Precompute fills the pools of commitment randomness of the parties of keyID in the pool of mpc,
+// and returns the number of randomness added. It is meant to be called while the node is idle.
+func (mpc *MPC) Precompute(keyID string, parties []party.ID) (int, error) {
+	added := 0
+	for _, j := range parties {
+		opts := pkg_keyopts.New().WithKeyID(keyID).WithPartyID(string(j))
+		n, err := mpc.pedersen.Precompute(mpc.pl, opts)
+		if err != nil {
+			return added, err
+		}
+		added += n
+	}
+	return added, nil
+}
@@ -1,17 +1,20 @@
 package cmp
 
 import (
+	"bytes"
 	"crypto/rand"
 	"math"
 	"sync"
 	"testing"
 
+	"github.com/fxamacker/cbor/v2"
 	"github.com/google/uuid"
 	"github.com/mr-shifu/mpc-lib/core/ecdsa"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/core/pool"
 	"github.com/mr-shifu/mpc-lib/core/protocol"
+	zkmod "github.com/mr-shifu/mpc-lib/core/zk/mod"
 	"github.com/mr-shifu/mpc-lib/lib/test"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/keystore"
@@ -19,11 +22,12 @@ import (
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/message"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/state"
 	"github.com/mr-shifu/mpc-lib/pkg/vault"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp/keygen"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func do(t *testing.T, id party.ID, ids []party.ID, threshold int, msg []byte, pl *pool.Pool, n *test.Network, wg *sync.WaitGroup) {
+func do(t *testing.T, id party.ID, ids []party.ID, threshold int, msg []byte, pl *pool.Pool, n *test.Network, wg *sync.WaitGroup, opts ...protocol.HandlerOption) {
 	defer wg.Done()
 
 	keyID := uuid.New().String()
@@ -37,12 +41,19 @@ func do(t *testing.T, id party.ID, ids []party.ID, threshold int, msg []byte, pl
 	msgstore := message.NewInMemoryMessageStore()
 	bcststore := message.NewInMemoryMessageStore()
 
-	mpc := NewMPC(ksf, krf, vf, keycfgstore, signcfgstore, keystatestore, signstatestore, msgstore, bcststore, pl)
+	// the secrets of the sign session are encrypted under a key of the session
+	scratchKey := make([]byte, 32)
+	_, err := rand.Read(scratchKey)
+	require.NoError(t, err)
+	scratch, err := vault.NewScratchVaultFactory(vf, scratchKey)
+	require.NoError(t, err)
+
+	mpc := NewMPC(ksf, krf, vf, scratch, keycfgstore, signcfgstore, keystatestore, signstatestore, msgstore, bcststore, pl)
 
 	keycfg := config.NewKeyConfig(keyID, curve.Secp256k1{}, threshold, id, ids)
 	h, err := protocol.NewMultiHandler(
 		mpc.Keygen(keycfg, pl),
-		nil)
+		nil, opts...)
 	require.NoError(t, err)
 	test.HandlerLoop(id, h, n)
 	r, err := h.Result()
@@ -53,7 +64,7 @@ func do(t *testing.T, id party.ID, ids []party.ID, threshold int, msg []byte, pl
 	signID := uuid.New().String()
 	signcfg := config.NewSignConfig(signID, keyID, curve.Secp256k1{}, threshold, id, ids, msg)
 	mpc.Sign(signcfg, pl)
-	h, err = protocol.NewMultiHandler(mpc.Sign(signcfg, pl), nil)
+	h, err = protocol.NewMultiHandler(mpc.Sign(signcfg, pl), nil, opts...)
 	require.NoError(t, err)
 	test.HandlerLoop(c.ID, h, n)
 
@@ -62,6 +73,7 @@ func do(t *testing.T, id party.ID, ids []party.ID, threshold int, msg []byte, pl
 	require.IsType(t, &ecdsa.Signature{}, signResult)
 	signature := signResult.(*ecdsa.Signature)
 	assert.True(t, signature.Verify(c.PublicPoint(), msg))
+	require.NoError(t, scratch.Erase())
 }
 
 func TestCMP(t *testing.T) {
@@ -83,6 +95,22 @@ func TestCMP(t *testing.T) {
 	wg.Wait()
 }
 
+func TestCMPDebugInvariants(t *testing.T) {
+	// the invariants documented by the rounds hold in an honest run
+	N := 3
+	partyIDs := test.PartyIDs(N)
+	n := test.NewNetwork(partyIDs)
+
+	var wg sync.WaitGroup
+	wg.Add(N)
+	for _, id := range partyIDs {
+		pl := pool.NewPool(3)
+		defer pl.TearDown()
+		go do(t, id, partyIDs, N-1, []byte("hello"), pl, n, &wg, protocol.WithDebugInvariants())
+	}
+	wg.Wait()
+}
+
 func TestStart(t *testing.T) {
 	group := curve.Secp256k1{}
 	N := 6
@@ -101,7 +129,7 @@ func TestStart(t *testing.T) {
 	msgstore := message.NewInMemoryMessageStore()
 	bcststore := message.NewInMemoryMessageStore()
 
-	mpc := NewMPC(ksf, krf, vf, keycfgstore, signcfgstore, keystatestore, signstatestore, msgstore, bcststore, pl)
+	mpc := NewMPC(ksf, krf, vf, vf, keycfgstore, signcfgstore, keystatestore, signstatestore, msgstore, bcststore, pl)
 
 	m := []byte("HELLO")
 	selfID := partyIDs[0]
@@ -170,3 +198,75 @@ func TestStart(t *testing.T) {
 		})
 	}
 }
+
+func TestKeygenAudit(t *testing.T) {
+	N := 3
+	partyIDs := test.PartyIDs(N)
+	n := test.NewNetwork(partyIDs)
+	keyID := uuid.New().String()
+	entropy := keygen.EntropySource{Name: "crypto/rand", Attestation: []byte("quote")}
+
+	audits := make([]*keygen.Audit, N)
+	var wg sync.WaitGroup
+	wg.Add(N)
+	for i, id := range partyIDs {
+		pl := pool.NewPool(3)
+		defer pl.TearDown()
+		go func(i int, id party.ID) {
+			defer wg.Done()
+			mpc := NewMPC(
+				&keystore.InmemoryKeystoreFactory{},
+				&keyopts.InMemoryKeyOptsFactory{},
+				&vault.InmemoryVaultFactory{},
+				&vault.InmemoryVaultFactory{},
+				config.NewInMemoryConfigStore(),
+				config.NewInMemoryConfigStore(),
+				state.NewInMemoryStateStore(),
+				state.NewInMemoryStateStore(),
+				message.NewInMemoryMessageStore(),
+				message.NewInMemoryMessageStore(),
+				pl,
+			)
+			start := mpc.NewMPCKeygenManager().WithAudit(entropy, func(a *keygen.Audit) {
+				audits[i] = a
+			}).Start(config.NewKeyConfig(keyID, curve.Secp256k1{}, N-1, id, partyIDs), pl)
+			h, err := protocol.NewMultiHandler(start, nil)
+			require.NoError(t, err)
+			test.HandlerLoop(id, h, n)
+			_, err = h.Result()
+			require.NoError(t, err)
+		}(i, id)
+	}
+	wg.Wait()
+
+	for i, a := range audits {
+		require.NotNil(t, a)
+		assert.Equal(t, partyIDs[i], a.Party)
+		assert.Equal(t, entropy, a.Entropy)
+		assert.False(t, a.GenerationCompleted.Before(a.GenerationStarted))
+		require.Len(t, a.Parameters, N)
+		// all parties record the same public parameters
+		assert.Equal(t, audits[0].SSID, a.SSID)
+		for _, j := range partyIDs {
+			assert.Equal(t, audits[0].Parameters[j], a.Parameters[j])
+			mod := &zkmod.Proof{}
+			require.NoError(t, cbor.Unmarshal(a.Parameters[j].Mod, mod))
+		}
+	}
+
+	// an auditor checks the proofs from the audit alone, and a proof bound to another transcript fails
+	require.NoError(t, audits[0].Verify(nil))
+	tampered := *audits[0]
+	tampered.Transcript = audits[0].Transcript[:len(audits[0].Transcript)-1]
+	assert.ErrorIs(t, tampered.Verify(nil), keygen.ErrInvalidAudit)
+	tampered = *audits[0]
+	tampered.Parameters = map[party.ID]*keygen.AuxParameters{partyIDs[0]: audits[0].Parameters[partyIDs[0]]}
+	tampered.Transcript = audits[1].Transcript
+	require.NoError(t, tampered.Verify(nil))
+	tampered.Parameters = map[party.ID]*keygen.AuxParameters{partyIDs[1]: audits[0].Parameters[partyIDs[0]]}
+	assert.ErrorIs(t, tampered.Verify(nil), keygen.ErrInvalidAudit)
+
+	var out bytes.Buffer
+	require.NoError(t, keygen.ExportAudits(&out, audits))
+	assert.Equal(t, N, bytes.Count(out.Bytes(), []byte("\n")))
+}
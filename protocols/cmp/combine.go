@@ -0,0 +1,78 @@
+package cmp
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mr-shifu/mpc-lib/core/ecdsa"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+)
+
+var (
+	ErrMissingPartialSignature = errors.New("cmp: missing partial signature")
+	ErrInvalidPartialSignature = errors.New("cmp: invalid partial signature")
+	// ErrInvalidPresignContribution is returned for a contribution Δⱼ inconsistent with the presignature.
+	ErrInvalidPresignContribution = errors.New("cmp: invalid presignature contribution")
+)
+
+// VerifyPartialSignature verifies the partial signature σⱼ of party j against the public presignature,
+// so that a coordinator can identify an invalid partial as soon as it is received,
+// rather than once the combined signature fails to verify.
+func VerifyPartialSignature(j party.ID, partial ecdsa.SignatureShare, presigPublic *ecdsa.PreSignaturePublic, msgHash []byte) error {
+	if err := checkPresignature(presigPublic); err != nil {
+		return err
+	}
+	if err := presigPublic.VerifySignatureShare(j, partial, msgHash); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidPartialSignature, err)
+	}
+	return nil
+}
+
+// VerifyPresignContribution verifies the contribution Δⱼ of party j to the public presignature,
+// given δ = ∑ⱼδⱼ broadcast along with it. See ecdsa.PreSignaturePublic.VerifyBigDeltaShare.
+func VerifyPresignContribution(j party.ID, delta curve.Scalar, bigDeltaShare curve.Point, presigPublic *ecdsa.PreSignaturePublic) error {
+	if err := checkPresignature(presigPublic); err != nil {
+		return err
+	}
+	if err := presigPublic.VerifyBigDeltaShare(j, delta, bigDeltaShare); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidPresignContribution, err)
+	}
+	return nil
+}
+
+func checkPresignature(presigPublic *ecdsa.PreSignaturePublic) error {
+	if presigPublic == nil || presigPublic.R == nil || presigPublic.RBar == nil || presigPublic.S == nil {
+		return errors.New("cmp: presignature is nil")
+	}
+	return nil
+}
+
+// CombinePartialSignatures verifies each partial signature σⱼ against the public presignature
+// and returns the full signature (R, ∑ⱼ σⱼ).
+//
+// This only requires public data, so a coordinator can aggregate the signature without
+// taking part in the protocol. A partial is required from every signer of the presignature,
+// and the error returned when a partial is invalid lists the culprits.
+func CombinePartialSignatures(partials map[party.ID]ecdsa.SignatureShare, presigPublic *ecdsa.PreSignaturePublic, msgHash []byte) (*ecdsa.Signature, error) {
+	if err := checkPresignature(presigPublic); err != nil {
+		return nil, err
+	}
+	for j := range presigPublic.RBar.Points {
+		if _, ok := partials[j]; !ok {
+			return nil, fmt.Errorf("%w: party %s", ErrMissingPartialSignature, j)
+		}
+	}
+	if culprits := presigPublic.VerifySignatureShares(partials, msgHash); len(culprits) > 0 {
+		return nil, fmt.Errorf("%w: parties %v", ErrInvalidPartialSignature, party.NewIDSlice(culprits))
+	}
+
+	s := presigPublic.R.Curve().NewScalar()
+	for _, sigma := range partials {
+		s.Add(sigma)
+	}
+	return &ecdsa.Signature{
+		R: presigPublic.R,
+		S: s,
+	}, nil
+}
@@ -0,0 +1,94 @@
+package cmp_test
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/ecdsa"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/test"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// additiveShares returns random shares of secret, one per id.
+func additiveShares(secret curve.Scalar, ids party.IDSlice) map[party.ID]curve.Scalar {
+	group := secret.Curve()
+	shares := make(map[party.ID]curve.Scalar, len(ids))
+	rest := group.NewScalar().Set(secret)
+	for _, id := range ids[1:] {
+		shares[id] = sample.Scalar(rand.Reader, group)
+		rest.Sub(shares[id])
+	}
+	shares[ids[0]] = rest
+	return shares
+}
+
+// newPreSignatures returns the public key X and presignatures of X for ids, as the cmp presign protocol would.
+func newPreSignatures(group curve.Curve, ids party.IDSlice) (curve.Point, map[party.ID]*ecdsa.PreSignature) {
+	x := sample.Scalar(rand.Reader, group)
+	k := sample.Scalar(rand.Reader, group)
+	kInv := group.NewScalar().Set(k).Invert()
+	R := kInv.ActOnBase()
+	// χ = x⋅k
+	chi := group.NewScalar().Set(x).Mul(k)
+
+	kShares := additiveShares(k, ids)
+	chiShares := additiveShares(chi, ids)
+	RBar := make(map[party.ID]curve.Point, len(ids))
+	S := make(map[party.ID]curve.Point, len(ids))
+	for _, id := range ids {
+		RBar[id] = group.NewScalar().Set(kShares[id]).Mul(kInv).ActOnBase()
+		S[id] = chiShares[id].Act(R)
+	}
+	preSignatures := make(map[party.ID]*ecdsa.PreSignature, len(ids))
+	for _, id := range ids {
+		preSignatures[id] = &ecdsa.PreSignature{
+			R:        R,
+			RBar:     party.NewPointMap(RBar),
+			S:        party.NewPointMap(S),
+			KShare:   kShares[id],
+			ChiShare: chiShares[id],
+		}
+	}
+	return x.ActOnBase(), preSignatures
+}
+
+func TestCombinePartialSignatures(t *testing.T) {
+	group := curve.Secp256k1{}
+	ids := test.PartyIDs(3)
+	message := []byte("hello")
+	X, preSignatures := newPreSignatures(group, ids)
+	public := preSignatures[ids[0]].Public()
+
+	partials := make(map[party.ID]ecdsa.SignatureShare, len(ids))
+	for _, id := range ids {
+		partials[id] = preSignatures[id].SignatureShare(message)
+		assert.NoError(t, cmp.VerifyPartialSignature(id, partials[id], public, message))
+	}
+	sig, err := cmp.CombinePartialSignatures(partials, public, message)
+	require.NoError(t, err)
+	assert.True(t, sig.Verify(X, message))
+
+	// a coordinator needs a partial from every signer
+	missing := make(map[party.ID]ecdsa.SignatureShare, len(ids))
+	for _, id := range ids[1:] {
+		missing[id] = partials[id]
+	}
+	_, err = cmp.CombinePartialSignatures(missing, public, message)
+	assert.ErrorIs(t, err, cmp.ErrMissingPartialSignature)
+
+	// the error names the culprits
+	bad := ids[1]
+	partials[bad] = sample.Scalar(rand.Reader, group)
+	_, err = cmp.CombinePartialSignatures(partials, public, message)
+	assert.ErrorIs(t, err, cmp.ErrInvalidPartialSignature)
+	assert.Contains(t, err.Error(), string(bad))
+	assert.ErrorIs(t, cmp.VerifyPartialSignature(bad, partials[bad], public, message), cmp.ErrInvalidPartialSignature)
+
+	_, err = cmp.CombinePartialSignatures(partials, nil, message)
+	assert.Error(t, err)
+}
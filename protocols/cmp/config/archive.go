@@ -0,0 +1,386 @@
+package config
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/types"
+)
+
+var ErrInvalidArchive = errors.New("config: invalid archive")
+
+// Archive is a cold export of the ECDSA secret x, re-shared to a set of escrow keys,
+// such as HSMs held by distinct custodians, so that any Threshold escrows can recover x.
+//
+// The archive is built as follows, without x being reconstructed at any point:
+//   - every party of a signing quorum calls ArchiveShare, re-sharing its share λᵢ⋅xᵢ to the escrows,
+//   - any party combines the contributions of the whole quorum with NewArchive, which checks them against the config,
+//   - to recover, each escrow calls Archive.Open with its secret key, and Threshold fragments are combined with RecoverShare.
+type Archive struct {
+	// PublicKey is the public key X = x⋅G of the archived key.
+	PublicKey curve.Point
+	// ChainKey is the chaining key of the archived key, for BIP32 derivations.
+	ChainKey types.RID
+	// Threshold is the number of escrows required to recover x.
+	Threshold int
+	// Escrows are the public keys of the escrows, by ID.
+	Escrows map[party.ID]curve.Point
+	// Contributions are the re-sharings of the quorum, by party.
+	Contributions map[party.ID]*ArchiveContribution
+}
+
+// ArchiveContribution is the re-sharing of a quorum member's share to the escrows.
+type ArchiveContribution struct {
+	// From is the party re-sharing its share.
+	From party.ID
+	// Quorum are the parties whose contributions sum to x.
+	Quorum party.IDSlice
+	// Commitment = Fᵢ(X) = fᵢ(X)⋅G, with fᵢ(0) = λᵢ⋅xᵢ.
+	Commitment *polynomial.Exponent
+	// Fragments hold fᵢ(j) encrypted to each escrow j.
+	Fragments map[party.ID]*EscrowFragment
+}
+
+// EscrowFragment is a share encrypted to an escrow key E.
+type EscrowFragment struct {
+	// Ephemeral = e⋅G
+	Ephemeral curve.Point
+	// Ciphertext = AEAD(H(e⋅E), fᵢ(j))
+	Ciphertext []byte
+}
+
+// ArchiveShare re-shares this party's share of x to the escrows, such that `threshold` of them can recover x
+// once the contributions of all parties in quorum are combined with NewArchive.
+func (c *Config) ArchiveShare(quorum []party.ID, threshold int, escrows map[party.ID]curve.Point) (*ArchiveContribution, error) {
+	signers := party.NewIDSlice(quorum)
+	if !signers.Contains(c.ID) || !c.CanSign(signers) {
+		return nil, fmt.Errorf("%w: quorum %v cannot sign", ErrInvalidArchive, signers)
+	}
+	if err := validateEscrows(c.Group, threshold, escrows); err != nil {
+		return nil, err
+	}
+
+	// fᵢ(X) of degree threshold-1 with fᵢ(0) = λᵢ⋅xᵢ
+	lambda := polynomial.LagrangeSingle(c.Group, signers, c.ID)
+	f := polynomial.NewPolynomial(c.Group, threshold-1, c.Group.NewScalar().Set(lambda).Mul(c.ECDSA))
+	contribution := &ArchiveContribution{
+		From:       c.ID,
+		Quorum:     signers,
+		Commitment: polynomial.NewPolynomialExponent(f),
+		Fragments:  make(map[party.ID]*EscrowFragment, len(escrows)),
+	}
+	for j, E := range escrows {
+		share, err := f.Evaluate(j.Scalar(c.Group)).MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		e := sample.ScalarUnit(rand.Reader, c.Group)
+		ephemeral := e.ActOnBase()
+		aead, err := deriveKey("Archive", e.Act(E), ephemeral)
+		if err != nil {
+			return nil, err
+		}
+		contribution.Fragments[j] = &EscrowFragment{
+			Ephemeral:  ephemeral,
+			Ciphertext: aead.Seal(nil, make([]byte, aead.NonceSize()), share, fragmentData(c.ID, j)),
+		}
+	}
+	return contribution, nil
+}
+
+// NewArchive combines the contributions of a quorum, after checking them against the public config `public`.
+func NewArchive(public *Config, threshold int, escrows map[party.ID]curve.Point, contributions ...*ArchiveContribution) (*Archive, error) {
+	if err := validateEscrows(public.Group, threshold, escrows); err != nil {
+		return nil, err
+	}
+	if len(contributions) == 0 || contributions[0] == nil {
+		return nil, fmt.Errorf("%w: no contributions", ErrInvalidArchive)
+	}
+	quorum := party.NewIDSlice(contributions[0].Quorum)
+	if !public.validQuorum(quorum) || len(contributions) != len(quorum) {
+		return nil, fmt.Errorf("%w: contributions do not match quorum %v", ErrInvalidArchive, quorum)
+	}
+
+	a := &Archive{
+		PublicKey:     public.PublicPoint(),
+		ChainKey:      public.ChainKey.Copy(),
+		Threshold:     threshold,
+		Escrows:       escrows,
+		Contributions: make(map[party.ID]*ArchiveContribution, len(contributions)),
+	}
+	lagrange := polynomial.Lagrange(public.Group, quorum)
+	for _, contribution := range contributions {
+		if contribution == nil || !quorum.Contains(contribution.From) || !sameQuorum(contribution.Quorum, quorum) {
+			return nil, fmt.Errorf("%w: contribution from outside the quorum", ErrInvalidArchive)
+		}
+		if _, ok := a.Contributions[contribution.From]; ok {
+			return nil, fmt.Errorf("%w: duplicate contribution from %s", ErrInvalidArchive, contribution.From)
+		}
+		if err := contribution.verify(public, lagrange[contribution.From], threshold, escrows); err != nil {
+			return nil, err
+		}
+		a.Contributions[contribution.From] = contribution
+	}
+	return a, nil
+}
+
+// verify checks that the contribution re-shares λᵢ⋅xᵢ with a polynomial of the right degree, to all escrows.
+func (contribution *ArchiveContribution) verify(public *Config, lambda curve.Scalar, threshold int, escrows map[party.ID]curve.Point) error {
+	F := contribution.Commitment
+	if F == nil || F.Degree() != threshold-1 {
+		return fmt.Errorf("%w: invalid commitment from %s", ErrInvalidArchive, contribution.From)
+	}
+	if !F.Constant().Equal(lambda.Act(public.Public[contribution.From].ECDSA)) {
+		return fmt.Errorf("%w: %s did not re-share its share", ErrInvalidArchive, contribution.From)
+	}
+	if len(contribution.Fragments) != len(escrows) {
+		return fmt.Errorf("%w: %s did not re-share to all escrows", ErrInvalidArchive, contribution.From)
+	}
+	for j := range escrows {
+		fragment, ok := contribution.Fragments[j]
+		if !ok || fragment == nil || fragment.Ephemeral == nil || fragment.Ephemeral.IsIdentity() {
+			return fmt.Errorf("%w: %s did not re-share to %s", ErrInvalidArchive, contribution.From, j)
+		}
+	}
+	return nil
+}
+
+// Open decrypts the fragments of escrow j with its secret key, and returns its share of x,
+// which can be combined with those of Threshold-1 other escrows using RecoverShare.
+//
+// Each fragment is checked against the commitment of its contribution, so that a party which encrypted
+// an invalid fragment is identified by the returned error.
+func (a *Archive) Open(j party.ID, secret curve.Scalar) (*RecoveryFragment, error) {
+	E, ok := a.Escrows[j]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown escrow %s", ErrInvalidArchive, j)
+	}
+	if !secret.ActOnBase().Equal(E) {
+		return nil, fmt.Errorf("%w: secret key does not belong to %s", ErrInvalidArchive, j)
+	}
+	group := a.PublicKey.Curve()
+	x := j.Scalar(group)
+	sum := group.NewScalar()
+	for from, contribution := range a.Contributions {
+		fragment := contribution.Fragments[j]
+		aead, err := deriveKey("Archive", secret.Act(fragment.Ephemeral), fragment.Ephemeral)
+		if err != nil {
+			return nil, err
+		}
+		data, err := aead.Open(nil, make([]byte, aead.NonceSize()), fragment.Ciphertext, fragmentData(from, j))
+		if err != nil {
+			return nil, fmt.Errorf("%w: fragment from %s: %v", ErrInvalidArchive, from, err)
+		}
+		share := group.NewScalar()
+		if err := share.UnmarshalBinary(data); err != nil {
+			return nil, fmt.Errorf("%w: fragment from %s: %v", ErrInvalidArchive, from, err)
+		}
+		if !share.ActOnBase().Equal(contribution.Commitment.Evaluate(x)) {
+			return nil, fmt.Errorf("%w: fragment from %s does not match its commitment", ErrInvalidArchive, from)
+		}
+		sum.Add(share)
+	}
+	return &RecoveryFragment{
+		Guardian:  j,
+		Threshold: a.Threshold,
+		Share:     sum,
+		Public:    a.PublicKey,
+	}, nil
+}
+
+// validQuorum is like CanSign, but for a quorum which may not include this party.
+func (c *Config) validQuorum(quorum party.IDSlice) bool {
+	if !ValidThreshold(c.Threshold, len(quorum)) || !quorum.Valid() {
+		return false
+	}
+	for _, j := range quorum {
+		if _, ok := c.Public[j]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// validateEscrows checks the escrow keys, and that the escrows have distinct evaluation points other than 0,
+// since a fragment at 0 would be the contributor's share λᵢ⋅xᵢ itself.
+func validateEscrows(group curve.Curve, threshold int, escrows map[party.ID]curve.Point) error {
+	ids := make([]party.ID, 0, len(escrows))
+	for j, E := range escrows {
+		if E == nil || E.IsIdentity() {
+			return fmt.Errorf("%w: escrow key of %s is identity", ErrInvalidArchive, j)
+		}
+		ids = append(ids, j)
+	}
+	if !party.NewIDSlice(ids).Valid() {
+		return fmt.Errorf("%w: escrows are invalid", ErrInvalidArchive)
+	}
+	if err := checkEvaluationPoints(group, ids); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+	if threshold < 1 || threshold > len(ids) {
+		return fmt.Errorf("%w: threshold %d is invalid for %d escrows", ErrInvalidArchive, threshold, len(ids))
+	}
+	return nil
+}
+
+// fragmentData is the associated data of the fragment from a quorum member to an escrow.
+func fragmentData(from, escrow party.ID) []byte {
+	return []byte(string(from) + "\x00" + string(escrow))
+}
+
+type archiveMarshal struct {
+	PublicKey     []byte
+	ChainKey      types.RID
+	Threshold     int
+	Escrows       map[party.ID][]byte
+	Contributions []*contributionMarshal
+}
+
+type contributionMarshal struct {
+	From       party.ID
+	Quorum     []party.ID
+	Commitment []byte
+	Fragments  map[party.ID]fragmentMarshal
+}
+
+type fragmentMarshal struct {
+	Ephemeral  []byte
+	Ciphertext []byte
+}
+
+// MarshalBinary encodes the archive package, to be stored offline.
+func (a *Archive) MarshalBinary() ([]byte, error) {
+	am := &archiveMarshal{
+		ChainKey:  a.ChainKey,
+		Threshold: a.Threshold,
+		Escrows:   make(map[party.ID][]byte, len(a.Escrows)),
+	}
+	var err error
+	if am.PublicKey, err = a.PublicKey.MarshalBinary(); err != nil {
+		return nil, err
+	}
+	for j, E := range a.Escrows {
+		if am.Escrows[j], err = E.MarshalBinary(); err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range party.NewIDSlice(keysOf(a.Contributions)) {
+		contribution := a.Contributions[id]
+		cm := &contributionMarshal{
+			From:      contribution.From,
+			Quorum:    contribution.Quorum,
+			Fragments: make(map[party.ID]fragmentMarshal, len(contribution.Fragments)),
+		}
+		if cm.Commitment, err = contribution.Commitment.MarshalBinary(); err != nil {
+			return nil, err
+		}
+		for j, fragment := range contribution.Fragments {
+			ephemeral, err := fragment.Ephemeral.MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+			cm.Fragments[j] = fragmentMarshal{Ephemeral: ephemeral, Ciphertext: fragment.Ciphertext}
+		}
+		am.Contributions = append(am.Contributions, cm)
+	}
+	return cbor.Marshal(am)
+}
+
+// UnmarshalArchive decodes an archive package over group. The contributions are checked
+// against the public key of the archive, since the config may not be available anymore.
+func UnmarshalArchive(group curve.Curve, data []byte) (*Archive, error) {
+	am := &archiveMarshal{}
+	if err := cbor.Unmarshal(data, am); err != nil {
+		return nil, fmt.Errorf("archive: %w", err)
+	}
+	a := &Archive{
+		PublicKey:     group.NewPoint(),
+		ChainKey:      am.ChainKey,
+		Threshold:     am.Threshold,
+		Escrows:       make(map[party.ID]curve.Point, len(am.Escrows)),
+		Contributions: make(map[party.ID]*ArchiveContribution, len(am.Contributions)),
+	}
+	if err := a.PublicKey.UnmarshalBinary(am.PublicKey); err != nil {
+		return nil, fmt.Errorf("archive: %w", err)
+	}
+	for j, data := range am.Escrows {
+		E := group.NewPoint()
+		if err := E.UnmarshalBinary(data); err != nil {
+			return nil, fmt.Errorf("archive: escrow %s: %w", j, err)
+		}
+		a.Escrows[j] = E
+	}
+	if err := validateEscrows(group, a.Threshold, a.Escrows); err != nil {
+		return nil, err
+	}
+
+	sum := group.NewPoint()
+	for _, cm := range am.Contributions {
+		if _, ok := a.Contributions[cm.From]; ok {
+			return nil, fmt.Errorf("%w: duplicate contribution from %s", ErrInvalidArchive, cm.From)
+		}
+		contribution := &ArchiveContribution{
+			From:       cm.From,
+			Quorum:     party.NewIDSlice(cm.Quorum),
+			Commitment: polynomial.EmptyExponent(group),
+			Fragments:  make(map[party.ID]*EscrowFragment, len(cm.Fragments)),
+		}
+		if err := contribution.Commitment.UnmarshalBinary(cm.Commitment); err != nil {
+			return nil, fmt.Errorf("archive: contribution from %s: %w", cm.From, err)
+		}
+		if contribution.Commitment.Degree() != a.Threshold-1 {
+			return nil, fmt.Errorf("%w: invalid commitment from %s", ErrInvalidArchive, cm.From)
+		}
+		for j := range a.Escrows {
+			fm, ok := cm.Fragments[j]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s did not re-share to %s", ErrInvalidArchive, cm.From, j)
+			}
+			ephemeral := group.NewPoint()
+			if err := ephemeral.UnmarshalBinary(fm.Ephemeral); err != nil {
+				return nil, fmt.Errorf("archive: contribution from %s: %w", cm.From, err)
+			}
+			contribution.Fragments[j] = &EscrowFragment{Ephemeral: ephemeral, Ciphertext: fm.Ciphertext}
+		}
+		a.Contributions[cm.From] = contribution
+		sum = sum.Add(contribution.Commitment.Constant())
+	}
+	quorum := party.NewIDSlice(keysOf(a.Contributions))
+	for _, contribution := range a.Contributions {
+		if !sameQuorum(contribution.Quorum, quorum) {
+			return nil, fmt.Errorf("%w: contributions do not match quorum %v", ErrInvalidArchive, quorum)
+		}
+	}
+	// X = ∑ᵢ λᵢ⋅Xᵢ
+	if len(a.Contributions) == 0 || !sum.Equal(a.PublicKey) {
+		return nil, fmt.Errorf("%w: contributions do not re-share the public key", ErrInvalidArchive)
+	}
+	return a, nil
+}
+
+// sameQuorum returns true if the sorted quorums a and b hold the same parties.
+func sameQuorum(a, b party.IDSlice) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func keysOf(m map[party.ID]*ArchiveContribution) []party.ID {
+	ids := make([]party.ID, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	return ids
+}
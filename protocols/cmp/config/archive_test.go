@@ -0,0 +1,82 @@
+package config_test
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/lib/test"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchive(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, ids := test.GenerateConfig(group, 4, 2, rand.Reader, pl)
+	quorum := ids[:3]
+
+	secrets := map[party.ID]curve.Scalar{}
+	escrows := map[party.ID]curve.Point{}
+	for _, j := range []party.ID{"escrow1", "escrow2", "escrow3"} {
+		secrets[j] = sample.Scalar(rand.Reader, group)
+		escrows[j] = secrets[j].ActOnBase()
+	}
+
+	_, err := configs[ids[0]].ArchiveShare(ids[:2], 2, escrows)
+	assert.ErrorIs(t, err, config.ErrInvalidArchive, "quorum below threshold")
+
+	// an escrow at 0 would receive the shares themselves, and two escrows at the same point the same fragments
+	for _, id := range []party.ID{"", "\x00escrow1"} {
+		invalid := map[party.ID]curve.Point{id: escrows["escrow2"]}
+		for j, E := range escrows {
+			invalid[j] = E
+		}
+		_, err = configs[ids[0]].ArchiveShare(quorum, 2, invalid)
+		assert.ErrorIs(t, err, config.ErrInvalidEvaluationPoint)
+		assert.ErrorIs(t, err, config.ErrInvalidArchive)
+	}
+
+	contributions := make([]*config.ArchiveContribution, 0, len(quorum))
+	for _, id := range quorum {
+		contribution, err := configs[id].ArchiveShare(quorum, 2, escrows)
+		require.NoError(t, err)
+		contributions = append(contributions, contribution)
+	}
+	public := configs[ids[3]]
+	_, err = config.NewArchive(public, 2, escrows, contributions[:2]...)
+	assert.ErrorIs(t, err, config.ErrInvalidArchive, "missing contribution")
+
+	// a contribution re-sharing another value is rejected
+	forged, err := configs[ids[3]].ArchiveShare(append([]party.ID{ids[3]}, quorum[1:]...), 2, escrows)
+	require.NoError(t, err)
+	forged.From, forged.Quorum = quorum[0], contributions[0].Quorum
+	_, err = config.NewArchive(public, 2, escrows, forged, contributions[1], contributions[2])
+	assert.ErrorIs(t, err, config.ErrInvalidArchive, "forged contribution")
+
+	archive, err := config.NewArchive(public, 2, escrows, contributions...)
+	require.NoError(t, err)
+	data, err := archive.MarshalBinary()
+	require.NoError(t, err)
+	archive, err = config.UnmarshalArchive(group, data)
+	require.NoError(t, err)
+
+	_, err = archive.Open("escrow1", secrets["escrow2"])
+	assert.ErrorIs(t, err, config.ErrInvalidArchive)
+	fragment1, err := archive.Open("escrow1", secrets["escrow1"])
+	require.NoError(t, err)
+	fragment3, err := archive.Open("escrow3", secrets["escrow3"])
+	require.NoError(t, err)
+
+	x, err := config.RecoverShare(fragment1, fragment3)
+	require.NoError(t, err)
+	assert.True(t, x.ActOnBase().Equal(public.PublicPoint()))
+	_, err = config.RecoverShare(fragment1)
+	assert.ErrorIs(t, err, config.ErrInvalidRecoveryFragments)
+}
@@ -1,10 +1,12 @@
 package config
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"math"
+	"strings"
 
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
@@ -42,6 +44,8 @@ type Config struct {
 	ChainKey types.RID
 	// Public maps party.ID to public. It contains all public information associated to a party.
 	Public map[party.ID]*Public
+	// Epoch is the number of refreshes of the shares since keygen.
+	Epoch uint64
 }
 
 // Public holds public information for a party.
@@ -249,6 +253,7 @@ func (c *Config) Derive(adjust curve.Scalar, newChainKey []byte) (*Config, error
 		RID:       c.RID,
 		ChainKey:  newChainKey,
 		Public:    public,
+		Epoch:     c.Epoch,
 	}, nil
 }
 
@@ -284,3 +289,102 @@ type configSerialized struct {
 	ChainKey  types.RID
 	Public    map[party.ID][]byte
 }
+
+// ErrIncompatibleConfig is returned by CompatibleWith when two configs have diverged.
+var ErrIncompatibleConfig = errors.New("config: incompatible configs")
+
+// Diff compares the public parts of two configs held by different parties,
+// and returns a description of each field which differs.
+// The secret parts and the owner's ID are ignored, since they are expected to differ.
+func (c *Config) Diff(other *Config) []string {
+	var diff []string
+	if c.Group.Name() != other.Group.Name() {
+		diff = append(diff, fmt.Sprintf("group: %s != %s", c.Group.Name(), other.Group.Name()))
+		return diff
+	}
+	if !c.PublicPoint().Equal(other.PublicPoint()) {
+		diff = append(diff, "public key")
+	}
+	if c.Threshold != other.Threshold {
+		diff = append(diff, fmt.Sprintf("threshold: %d != %d", c.Threshold, other.Threshold))
+	}
+	if c.Epoch != other.Epoch {
+		diff = append(diff, fmt.Sprintf("epoch: %d != %d", c.Epoch, other.Epoch))
+	}
+	if !bytes.Equal(c.RID, other.RID) {
+		diff = append(diff, "rid")
+	}
+	if !bytes.Equal(c.ChainKey, other.ChainKey) {
+		diff = append(diff, "chain key")
+	}
+
+	ids, otherIDs := c.PartyIDs(), other.PartyIDs()
+	for _, j := range ids {
+		if !otherIDs.Contains(j) {
+			diff = append(diff, fmt.Sprintf("party %s: missing from other config", j))
+		}
+	}
+	for _, j := range otherIDs {
+		if !ids.Contains(j) {
+			diff = append(diff, fmt.Sprintf("party %s: missing from this config", j))
+		}
+	}
+	for _, j := range ids {
+		p, q := c.Public[j], other.Public[j]
+		if p == nil || q == nil {
+			if p != q && otherIDs.Contains(j) {
+				diff = append(diff, fmt.Sprintf("party %s: missing public data", j))
+			}
+			continue
+		}
+		if !pointEqual(p.ECDSA, q.ECDSA) {
+			diff = append(diff, fmt.Sprintf("party %s: ECDSA public share", j))
+		}
+		if !pointEqual(p.ElGamal, q.ElGamal) {
+			diff = append(diff, fmt.Sprintf("party %s: ElGamal public key", j))
+		}
+		if !paillierEqual(p.Paillier, q.Paillier) {
+			diff = append(diff, fmt.Sprintf("party %s: Paillier public key", j))
+		}
+		if !pedersenEqual(p.Pedersen, q.Pedersen) {
+			diff = append(diff, fmt.Sprintf("party %s: Pedersen parameters", j))
+		}
+	}
+	return diff
+}
+
+// pointEqual, paillierEqual and pedersenEqual consider two missing values equal,
+// and a missing value different from any other.
+func pointEqual(p, q curve.Point) bool {
+	if p == nil || q == nil {
+		return p == nil && q == nil
+	}
+	return p.Equal(q)
+}
+
+func paillierEqual(p, q *paillier.PublicKey) bool {
+	if p == nil || q == nil {
+		return p == q
+	}
+	return p.Equal(q)
+}
+
+func pedersenEqual(p, q *pedersen.Parameters) bool {
+	if p == nil || q == nil {
+		return p == q
+	}
+	_, eq, _ := p.N().Cmp(q.N())
+	return eq == 1 && p.S().Eq(q.S()) == 1 && p.T().Eq(q.T()) == 1
+}
+
+// CompatibleWith returns an error listing the diverging fields if the public parts of c and other differ.
+// Parties whose configs are not compatible will fail to verify each other's proofs.
+func (c *Config) CompatibleWith(other *Config) error {
+	if other == nil {
+		return fmt.Errorf("%w: other config is nil", ErrIncompatibleConfig)
+	}
+	if diff := c.Diff(other); len(diff) > 0 {
+		return fmt.Errorf("%w: %s", ErrIncompatibleConfig, strings.Join(diff, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package config_test
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/lib/test"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// copyPublic returns a copy of c whose public data can be modified.
+func copyPublic(c *config.Config) *config.Config {
+	copied := *c
+	copied.Public = make(map[party.ID]*config.Public, len(c.Public))
+	for j, public := range c.Public {
+		p := *public
+		copied.Public[j] = &p
+	}
+	return &copied
+}
+
+// withVersion returns data, a cbor encoded struct, with its Version field set to version.
+func withVersion(t *testing.T, data []byte, version int) []byte {
+	var fields map[string]cbor.RawMessage
+	require.NoError(t, cbor.Unmarshal(data, &fields))
+	encoded, err := cbor.Marshal(version)
+	require.NoError(t, err)
+	fields["Version"] = encoded
+	data, err = cbor.Marshal(fields)
+	require.NoError(t, err)
+	return data
+}
+
+func TestConfigEncodingVersion(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, ids := test.GenerateConfig(group, 2, 1, rand.Reader, pl)
+	data, err := configs[ids[0]].MarshalBinary()
+	require.NoError(t, err)
+
+	var fields struct{ Version int }
+	require.NoError(t, cbor.Unmarshal(data, &fields))
+	assert.Equal(t, config.EncodingVersion, fields.Version)
+	require.NoError(t, config.EmptyConfig(group).UnmarshalBinary(data))
+
+	// configs marshaled before versions were recorded are still read
+	require.NoError(t, config.EmptyConfig(group).UnmarshalBinary(withVersion(t, data, 0)))
+	assert.Error(t, config.EmptyConfig(group).UnmarshalBinary(withVersion(t, data, config.EncodingVersion+1)))
+}
+
+func TestConfigDiff(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, ids := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+	a, b := configs[ids[0]], configs[ids[1]]
+
+	// the configs of different parties only differ in their secrets
+	assert.Empty(t, a.Diff(b))
+	assert.NoError(t, a.CompatibleWith(b))
+	assert.ErrorIs(t, a.CompatibleWith(nil), config.ErrIncompatibleConfig)
+
+	other := copyPublic(b)
+	other.Threshold++
+	other.Epoch++
+	other.Public[ids[2]].Paillier = a.Public[ids[1]].Paillier
+	assert.ElementsMatch(t, []string{
+		"threshold: 1 != 2",
+		"epoch: 0 != 1",
+		"party c: Paillier public key",
+	}, a.Diff(other))
+	assert.ErrorIs(t, a.CompatibleWith(other), config.ErrIncompatibleConfig)
+
+	// missing keys are reported instead of panicking
+	other = copyPublic(b)
+	other.Public[ids[2]].Paillier = nil
+	other.Public[ids[2]].Pedersen = nil
+	other.Public[ids[2]].ElGamal = nil
+	assert.ElementsMatch(t, []string{
+		"party c: ElGamal public key",
+		"party c: Paillier public key",
+		"party c: Pedersen parameters",
+	}, a.Diff(other))
+	assert.Empty(t, other.Diff(other))
+
+	other = copyPublic(b)
+	delete(other.Public, ids[2])
+	assert.Equal(t, []string{"party c: missing from other config"}, a.Diff(other))
+}
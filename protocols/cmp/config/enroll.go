@@ -0,0 +1,643 @@
+package config
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mr-shifu/mpc-lib/core/hash"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/paillier"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pedersen"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	zkfac "github.com/mr-shifu/mpc-lib/core/zk/fac"
+	zkmod "github.com/mr-shifu/mpc-lib/core/zk/mod"
+	zkprm "github.com/mr-shifu/mpc-lib/core/zk/prm"
+	zksch "github.com/mr-shifu/mpc-lib/core/zk/sch"
+)
+
+var (
+	ErrInvalidEnrollment = errors.New("config: invalid enrollment")
+	ErrSessionUsed       = errors.New("config: session already used")
+	// ErrInvalidEvaluationPoint is returned for a party whose Shamir evaluation point is 0, where the secret lies,
+	// or is the evaluation point of another party.
+	ErrInvalidEvaluationPoint = errors.New("config: invalid evaluation point")
+)
+
+// EnrollmentRequest is sent by a party joining the committee, with its freshly generated auxiliary parameters.
+//
+// Enrolling adds a signer without changing the threshold or the public key, and is much cheaper than a reshare:
+//   - the newcomer calls RequestEnrollment, generating its Paillier, Pedersen and ElGamal keys with proofs,
+//   - every existing party verifies the request and adds the newcomer with AddParty,
+//   - each party of a signing quorum calls Enroll, sending the newcomer its share of x_new = f(new),
+//     and a sharing of 0 to all parties,
+//   - every existing party calls ApplyEnrollment with the contributions of the whole quorum, obtaining its new Config,
+//   - the newcomer calls Enrollee.Complete with the same contributions, obtaining its Config.
+//
+// Each contribution λᵢ(new)⋅xᵢ is masked with a random share of 0 bound to the session and the quorum,
+// so that the newcomer learns nothing of the individual shares, and a party contributes only once per session.
+// The sharings of 0 re-randomize all shares, so that the newcomer's share does not lie on the polynomial
+// of the shares held before it joined.
+type EnrollmentRequest struct {
+	// ID is the identifier of the newcomer.
+	ID party.ID
+	// Session is a unique identifier of this enrollment, chosen by the newcomer.
+	Session []byte
+	AuxInfo
+}
+
+// AuxInfo holds the freshly generated auxiliary parameters of a party, with proofs of their validity.
+type AuxInfo struct {
+	// ElGamal is the party's public key Y, to which its shares are encrypted.
+	ElGamal curve.Point
+	// Paillier is the party's public Paillier key.
+	Paillier *paillier.PublicKey
+	// Pedersen is the party's public Pedersen parameters.
+	Pedersen *pedersen.Parameters
+	// Mod is a proof that the Paillier modulus N is a Blum integer.
+	Mod *zkmod.Proof
+	// Prm is a proof that s and t generate the same group modulo N.
+	Prm *zkprm.Proof
+	// Fac holds, for each party verifying the parameters, a proof that N has no small factor,
+	// against the verifier's Pedersen parameters. A small factor would leak the verifier's share
+	// through the MtA of the sign protocol.
+	Fac map[party.ID]*zkfac.Proof
+	// Proof is a proof of knowledge of y.
+	Proof *zksch.Proof
+}
+
+// EnrollmentContribution is sent by a quorum member to the newcomer.
+type EnrollmentContribution struct {
+	// From is the party contributing.
+	From party.ID
+	// Quorum are the parties whose contributions sum to x_new.
+	Quorum party.IDSlice
+	// Mask = ρᵢ⋅G, where the ρᵢ of the quorum sum to 0.
+	Mask curve.Point
+	// Share holds σᵢ = λᵢ(new)⋅xᵢ + ρᵢ, encrypted to the newcomer's ElGamal key.
+	Share *EscrowFragment
+	// Refresh = Gᵢ(X) = gᵢ(X)⋅G, where gᵢ has degree t and gᵢ(0) = 0.
+	Refresh *polynomial.Exponent
+	// Fragments hold gᵢ(j) encrypted to the ElGamal key of each party j.
+	Fragments map[party.ID]*EscrowFragment
+}
+
+// SessionLedger records the sessions to which a party contributed its share.
+// Contributing twice to the same session, such as for two quorums, would give the recipient
+// two masked shares from which it could learn more than its own share.
+type SessionLedger interface {
+	// Use records the session of domain, or returns ErrSessionUsed if it was already recorded.
+	Use(domain string, session []byte) error
+}
+
+// Enrollee is the state of a newcomer between RequestEnrollment and Complete.
+type Enrollee struct {
+	public   *Config
+	request  *EnrollmentRequest
+	elgamal  curve.Scalar
+	paillier *paillier.SecretKey
+}
+
+// RequestEnrollment generates the auxiliary parameters of party id, joining the committee of the public config `public`.
+// The session must be unique to this enrollment.
+func RequestEnrollment(public *Config, id party.ID, session []byte, pl *pool.Pool) (*Enrollee, *EnrollmentRequest, error) {
+	if _, ok := public.Public[id]; ok {
+		return nil, nil, fmt.Errorf("%w: %s is already a party", ErrInvalidEnrollment, id)
+	}
+	if err := checkEvaluationPoints(public.Group, append(public.PartyIDs(), id)); err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidEnrollment, err)
+	}
+	if len(session) == 0 {
+		return nil, nil, fmt.Errorf("%w: empty session", ErrInvalidEnrollment)
+	}
+	r := &EnrollmentRequest{
+		ID:      id,
+		Session: append([]byte{}, session...),
+	}
+	aux, y, sk := newAuxInfo(public.Group, enrollmentHash(public, r), public.Public, pl)
+	r.AuxInfo = *aux
+	return &Enrollee{public: copyCommittee(public), request: r, elgamal: y, paillier: sk}, r, nil
+}
+
+// AddParty verifies the newcomer's parameters and proofs, and adds it to c.
+// Its public share is interpolated from the existing ones, so c.PublicPoint is unchanged.
+func (c *Config) AddParty(r *EnrollmentRequest, pl *pool.Pool) error {
+	if _, ok := c.Public[r.ID]; ok {
+		return fmt.Errorf("%w: %s is already a party", ErrInvalidEnrollment, r.ID)
+	}
+	// a share at 0 is the secret key, and a share at the point of another party is that party's share
+	if err := checkEvaluationPoints(c.Group, append(c.PartyIDs(), r.ID)); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidEnrollment, err)
+	}
+	if len(r.Session) == 0 {
+		return fmt.Errorf("%w: empty session", ErrInvalidEnrollment)
+	}
+	if err := r.AuxInfo.verify(enrollmentHash(c, r), c, pl); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEnrollment, err)
+	}
+	c.addParty(r)
+	return nil
+}
+
+// addParty adds the newcomer of r to c, with the public share X_new = ∑ⱼ λⱼ(new)⋅Xⱼ interpolated
+// over all parties, since their shares lie on a polynomial of degree t.
+func (c *Config) addParty(r *EnrollmentRequest) {
+	ids := c.PartyIDs()
+	lagrange := polynomial.LagrangeAt(c.Group, ids, r.ID.Scalar(c.Group))
+	X := c.Group.NewPoint()
+	for _, j := range ids {
+		X = X.Add(lagrange[j].Act(c.Public[j].ECDSA))
+	}
+	c.Public[r.ID] = r.AuxInfo.public(X)
+}
+
+// Enroll returns this party's contribution to the share of the newcomer, which must have been added with AddParty.
+// All parties in quorum must contribute. The session of r is recorded in ledger, and a party contributes
+// to it only once, even for another quorum.
+func (c *Config) Enroll(r *EnrollmentRequest, quorum []party.ID, ledger SessionLedger) (*EnrollmentContribution, error) {
+	p, ok := c.Public[r.ID]
+	if !ok || !p.ElGamal.Equal(r.ElGamal) {
+		return nil, fmt.Errorf("%w: %s was not added", ErrInvalidEnrollment, r.ID)
+	}
+	signers := party.NewIDSlice(quorum)
+	if signers.Contains(r.ID) || !c.CanSign(signers) {
+		return nil, fmt.Errorf("%w: quorum %v cannot sign", ErrInvalidEnrollment, signers)
+	}
+	if err := ledger.Use("Enrollment", r.Session); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEnrollment, err)
+	}
+	contribution, err := c.maskedShare("Enrollment", r.Session, r.ID, r.ElGamal, signers)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.addRefresh("Enrollment", contribution, r.ID, r.ElGamal); err != nil {
+		return nil, err
+	}
+	return contribution, nil
+}
+
+// ApplyEnrollment checks the contributions of a quorum to the enrollment of r, which must have been added with AddParty,
+// and returns this party's re-randomized Config. c is left unchanged.
+func (c *Config) ApplyEnrollment(r *EnrollmentRequest, contributions ...*EnrollmentContribution) (*Config, error) {
+	p, ok := c.Public[r.ID]
+	if !ok || !p.ElGamal.Equal(r.ElGamal) {
+		return nil, fmt.Errorf("%w: %s was not added", ErrInvalidEnrollment, r.ID)
+	}
+	if r.ID == c.ID {
+		return nil, fmt.Errorf("%w: cannot apply own enrollment", ErrInvalidEnrollment)
+	}
+	public, err := refreshedPublic(c, r.ID, &r.AuxInfo, contributions, ErrInvalidEnrollment)
+	if err != nil {
+		return nil, err
+	}
+	delta, err := refreshShare("Enrollment", c.Group, c.ID, c.ElGamal, contributions, ErrInvalidEnrollment)
+	if err != nil {
+		return nil, err
+	}
+	x := c.Group.NewScalar().Set(c.ECDSA).Add(delta)
+	if !x.ActOnBase().Equal(public[c.ID].ECDSA) {
+		return nil, fmt.Errorf("%w: refreshed share does not match public share", ErrInvalidEnrollment)
+	}
+	return &Config{
+		Group:     c.Group,
+		ID:        c.ID,
+		Threshold: c.Threshold,
+		ECDSA:     x,
+		ElGamal:   c.ElGamal,
+		Paillier:  c.Paillier,
+		RID:       c.RID.Copy(),
+		ChainKey:  c.ChainKey.Copy(),
+		Public:    public,
+	}, nil
+}
+
+// Complete decrypts and checks the contributions of a quorum, and returns the newcomer's Config.
+func (e *Enrollee) Complete(contributions ...*EnrollmentContribution) (*Config, error) {
+	group := e.public.Group
+	committee := copyCommittee(e.public)
+	committee.addParty(e.request)
+	public, err := refreshedPublic(committee, e.request.ID, &e.request.AuxInfo, contributions, ErrInvalidEnrollment)
+	if err != nil {
+		return nil, err
+	}
+	x, err := openMaskedShares("Enrollment", e.public, e.request.ID, e.elgamal, contributions, ErrInvalidEnrollment)
+	if err != nil {
+		return nil, err
+	}
+	delta, err := refreshShare("Enrollment", group, e.request.ID, e.elgamal, contributions, ErrInvalidEnrollment)
+	if err != nil {
+		return nil, err
+	}
+	x.Add(delta)
+	if !x.ActOnBase().Equal(public[e.request.ID].ECDSA) {
+		return nil, fmt.Errorf("%w: enrolled share does not match public share", ErrInvalidEnrollment)
+	}
+	return &Config{
+		Group:     group,
+		ID:        e.request.ID,
+		Threshold: e.public.Threshold,
+		ECDSA:     x,
+		ElGamal:   e.elgamal,
+		Paillier:  e.paillier,
+		RID:       e.public.RID.Copy(),
+		ChainKey:  e.public.ChainKey.Copy(),
+		Public:    public,
+	}, nil
+}
+
+// maskedShare returns this party's contribution λᵢ(id)⋅xᵢ + ρᵢ to the share of party id, encrypted to Y.
+func (c *Config) maskedShare(domain string, session []byte, id party.ID, Y curve.Point, signers party.IDSlice) (*EnrollmentContribution, error) {
+	// ρᵢ = ∑ₖ ±H(yᵢ⋅Yₖ), where both parties of a pair derive the same value with opposite signs
+	rho := c.Group.NewScalar()
+	for _, k := range signers {
+		if k == c.ID {
+			continue
+		}
+		m, err := pairwiseMask(domain, session, id, signers, c.ElGamal.Act(c.Public[k].ElGamal), c.ID, k)
+		if err != nil {
+			return nil, err
+		}
+		if c.ID < k {
+			rho.Add(m)
+		} else {
+			rho.Sub(m)
+		}
+	}
+
+	lambda := polynomial.LagrangeAt(c.Group, signers, id.Scalar(c.Group))[c.ID]
+	sigma, err := c.Group.NewScalar().Set(lambda).Mul(c.ECDSA).Add(rho).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	share, err := encryptFragment(domain, Y, sigma, fragmentData(c.ID, id))
+	if err != nil {
+		return nil, err
+	}
+	return &EnrollmentContribution{
+		From:   c.ID,
+		Quorum: signers,
+		Mask:   rho.ActOnBase(),
+		Share:  share,
+	}, nil
+}
+
+// openMaskedShares decrypts the contributions of a quorum to the share of party id with its ElGamal secret y,
+// checks them against the public shares in `public`, and returns their sum x_id = ∑ᵢ λᵢ(id)⋅xᵢ.
+// The errors returned wrap errInvalid.
+func openMaskedShares(domain string, public *Config, id party.ID, y curve.Scalar, contributions []*EnrollmentContribution, errInvalid error) (curve.Scalar, error) {
+	group := public.Group
+	if len(contributions) == 0 || contributions[0] == nil {
+		return nil, fmt.Errorf("%w: no contributions", errInvalid)
+	}
+	quorum := party.NewIDSlice(contributions[0].Quorum)
+	if quorum.Contains(id) || !public.validQuorum(quorum) || len(contributions) != len(quorum) {
+		return nil, fmt.Errorf("%w: contributions do not match quorum %v", errInvalid, quorum)
+	}
+
+	lagrange := polynomial.LagrangeAt(group, quorum, id.Scalar(group))
+	x := group.NewScalar()
+	masks := group.NewPoint()
+	seen := make(map[party.ID]bool, len(contributions))
+	for _, contribution := range contributions {
+		if contribution == nil || !quorum.Contains(contribution.From) || !sameQuorum(contribution.Quorum, quorum) {
+			return nil, fmt.Errorf("%w: contribution from outside the quorum", errInvalid)
+		}
+		if seen[contribution.From] {
+			return nil, fmt.Errorf("%w: duplicate contribution from %s", errInvalid, contribution.From)
+		}
+		seen[contribution.From] = true
+		if contribution.Mask == nil {
+			return nil, fmt.Errorf("%w: missing mask from %s", errInvalid, contribution.From)
+		}
+		data, err := decryptFragment(domain, y, contribution.Share, fragmentData(contribution.From, id))
+		if err != nil {
+			return nil, fmt.Errorf("%w: share from %s: %v", errInvalid, contribution.From, err)
+		}
+		sigma := group.NewScalar()
+		if err := sigma.UnmarshalBinary(data); err != nil {
+			return nil, fmt.Errorf("%w: share from %s: %v", errInvalid, contribution.From, err)
+		}
+		// σᵢ⋅G = λᵢ(id)⋅Xᵢ + Rᵢ
+		expected := lagrange[contribution.From].Act(public.Public[contribution.From].ECDSA).Add(contribution.Mask)
+		if !sigma.ActOnBase().Equal(expected) {
+			return nil, fmt.Errorf("%w: %s did not contribute its share", errInvalid, contribution.From)
+		}
+		x.Add(sigma)
+		masks = masks.Add(contribution.Mask)
+	}
+	if !masks.IsIdentity() {
+		return nil, fmt.Errorf("%w: masks do not cancel", errInvalid)
+	}
+	return x, nil
+}
+
+// addRefresh adds to contribution a sharing gᵢ of 0 to all parties, where the fragment of party id is encrypted to Y.
+func (c *Config) addRefresh(domain string, contribution *EnrollmentContribution, id party.ID, Y curve.Point) error {
+	// gᵢ(X) of degree t with gᵢ(0) = 0
+	g := polynomial.NewPolynomial(c.Group, c.Threshold, c.Group.NewScalar())
+	contribution.Refresh = polynomial.NewPolynomialExponent(g)
+	contribution.Fragments = make(map[party.ID]*EscrowFragment, len(c.Public))
+	for j, p := range c.Public {
+		Yj := p.ElGamal
+		if j == id {
+			Yj = Y
+		}
+		data, err := g.Evaluate(j.Scalar(c.Group)).MarshalBinary()
+		if err != nil {
+			return err
+		}
+		if contribution.Fragments[j], err = encryptFragment(domain+" Refresh", Yj, data, fragmentData(c.ID, j)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshedPublic checks the structure of the contributions of a quorum, and returns the public data of
+// all parties of c after their refresh, where Xⱼ' = Xⱼ + ∑ᵢ Gᵢ(j), and party id holds the parameters aux.
+// The errors returned wrap errInvalid.
+func refreshedPublic(c *Config, id party.ID, aux *AuxInfo, contributions []*EnrollmentContribution, errInvalid error) (map[party.ID]*Public, error) {
+	if len(contributions) == 0 || contributions[0] == nil {
+		return nil, fmt.Errorf("%w: no contributions", errInvalid)
+	}
+	quorum := party.NewIDSlice(contributions[0].Quorum)
+	if quorum.Contains(id) || !c.validQuorum(quorum) || len(contributions) != len(quorum) {
+		return nil, fmt.Errorf("%w: contributions do not match quorum %v", errInvalid, quorum)
+	}
+
+	refreshes := make([]*polynomial.Exponent, 0, len(contributions))
+	masks := c.Group.NewPoint()
+	seen := make(map[party.ID]bool, len(contributions))
+	for _, contribution := range contributions {
+		if contribution == nil || !quorum.Contains(contribution.From) || !sameQuorum(contribution.Quorum, quorum) {
+			return nil, fmt.Errorf("%w: contribution from outside the quorum", errInvalid)
+		}
+		if seen[contribution.From] {
+			return nil, fmt.Errorf("%w: duplicate contribution from %s", errInvalid, contribution.From)
+		}
+		seen[contribution.From] = true
+		G := contribution.Refresh
+		if G == nil || G.Degree() != c.Threshold || !G.Constant().IsIdentity() {
+			return nil, fmt.Errorf("%w: invalid refresh from %s", errInvalid, contribution.From)
+		}
+		if len(contribution.Fragments) != len(c.Public) {
+			return nil, fmt.Errorf("%w: %s did not refresh all parties", errInvalid, contribution.From)
+		}
+		if contribution.Mask == nil {
+			return nil, fmt.Errorf("%w: missing mask from %s", errInvalid, contribution.From)
+		}
+		refreshes = append(refreshes, G)
+		masks = masks.Add(contribution.Mask)
+	}
+	if !masks.IsIdentity() {
+		return nil, fmt.Errorf("%w: masks do not cancel", errInvalid)
+	}
+	G, err := polynomial.Sum(refreshes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalid, err)
+	}
+
+	public := make(map[party.ID]*Public, len(c.Public))
+	for j, p := range c.Public {
+		X := p.ECDSA.Add(G.Evaluate(j.Scalar(c.Group)))
+		if j == id {
+			public[j] = aux.public(X)
+			continue
+		}
+		public[j] = &Public{
+			ECDSA:    X,
+			ElGamal:  p.ElGamal,
+			Paillier: p.Paillier,
+			Pedersen: p.Pedersen,
+		}
+	}
+	return public, nil
+}
+
+// refreshShare decrypts the fragments of party j with its ElGamal secret y, checks them against
+// their commitments, and returns their sum ∑ᵢ gᵢ(j). The errors returned wrap errInvalid.
+func refreshShare(domain string, group curve.Curve, j party.ID, y curve.Scalar, contributions []*EnrollmentContribution, errInvalid error) (curve.Scalar, error) {
+	x := j.Scalar(group)
+	sum := group.NewScalar()
+	for _, contribution := range contributions {
+		data, err := decryptFragment(domain+" Refresh", y, contribution.Fragments[j], fragmentData(contribution.From, j))
+		if err != nil {
+			return nil, fmt.Errorf("%w: refresh from %s: %v", errInvalid, contribution.From, err)
+		}
+		share := group.NewScalar()
+		if err := share.UnmarshalBinary(data); err != nil {
+			return nil, fmt.Errorf("%w: refresh from %s: %v", errInvalid, contribution.From, err)
+		}
+		if !share.ActOnBase().Equal(contribution.Refresh.Evaluate(x)) {
+			return nil, fmt.Errorf("%w: refresh from %s does not match its commitment", errInvalid, contribution.From)
+		}
+		sum.Add(share)
+	}
+	return sum, nil
+}
+
+// checkEvaluationPoints returns ErrInvalidEvaluationPoint if the Shamir evaluation point of one of ids is 0,
+// or is the evaluation point of another one of ids.
+func checkEvaluationPoints(group curve.Curve, ids []party.ID) error {
+	points := make(map[string]party.ID, len(ids))
+	for _, id := range ids {
+		x := id.Scalar(group)
+		if x.IsZero() {
+			return fmt.Errorf("%w: %q evaluates at 0", ErrInvalidEvaluationPoint, id)
+		}
+		point, err := x.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		if other, ok := points[string(point)]; ok {
+			return fmt.Errorf("%w: %q and %q evaluate at the same point", ErrInvalidEvaluationPoint, other, id)
+		}
+		points[string(point)] = id
+	}
+	return nil
+}
+
+// newAuxInfo samples fresh auxiliary parameters, with proofs bound to h.
+// A proof that N has no small factor is created for each of the verifiers, against their Pedersen parameters.
+func newAuxInfo(group curve.Curve, h *hash.Hash, verifiers map[party.ID]*Public, pl *pool.Pool) (*AuxInfo, curve.Scalar, *paillier.SecretKey) {
+	pk, sk := paillier.KeyGen(pl)
+	ped, lambda := sk.GeneratePedersen()
+	y := sample.Scalar(rand.Reader, group)
+	a := &AuxInfo{
+		ElGamal:  y.ActOnBase(),
+		Paillier: pk,
+		Pedersen: ped,
+		Fac:      make(map[party.ID]*zkfac.Proof, len(verifiers)),
+	}
+	a.Mod = zkmod.NewProof(h.Clone(), zkmod.Private{
+		P:   sk.P(),
+		Q:   sk.Q(),
+		Phi: sk.Phi(),
+	}, zkmod.Public{N: pk.N()}, pl)
+	a.Prm = zkprm.NewProof(zkprm.Private{
+		Lambda: lambda,
+		Phi:    sk.Phi(),
+		P:      sk.P(),
+		Q:      sk.Q(),
+	}, h.Clone(), zkprm.Public{Aux: ped}, pl)
+	for j, verifier := range verifiers {
+		if verifier == nil || verifier.Pedersen == nil {
+			continue
+		}
+		a.Fac[j] = zkfac.NewProof(zkfac.Private{P: sk.P(), Q: sk.Q()}, h.Clone(), zkfac.Public{
+			N:   pk.N(),
+			Aux: verifier.Pedersen,
+		})
+	}
+	a.Proof = zksch.NewProof(&transferHash{h.Clone()}, a.ElGamal, y, nil)
+	return a, y, sk
+}
+
+// verifiersExcept returns the public data of the parties of c other than id, which verify the auxiliary
+// parameters id generates.
+func verifiersExcept(c *Config, id party.ID) map[party.ID]*Public {
+	verifiers := make(map[party.ID]*Public, len(c.Public))
+	for j, p := range c.Public {
+		if j != id {
+			verifiers[j] = p
+		}
+	}
+	return verifiers
+}
+
+// verify checks the parameters and their proofs against h, including the proof that N has no small factor
+// created for the party of c.
+func (a *AuxInfo) verify(h *hash.Hash, c *Config, pl *pool.Pool) error {
+	if a.ElGamal == nil || a.ElGamal.IsIdentity() || a.Paillier == nil || a.Pedersen == nil {
+		return errors.New("missing parameters")
+	}
+	if err := paillier.ValidateN(a.Paillier.N()); err != nil {
+		return err
+	}
+	if err := pedersen.ValidateParameters(a.Pedersen.N(), a.Pedersen.S(), a.Pedersen.T()); err != nil {
+		return err
+	}
+	if a.Paillier.N().Nat().Eq(a.Pedersen.N().Nat()) != 1 {
+		return errors.New("Pedersen and Paillier moduli differ")
+	}
+	if a.Mod == nil || !a.Mod.Verify(zkmod.Public{N: a.Paillier.N()}, h.Clone(), pl) {
+		return errors.New("invalid proof of Paillier modulus")
+	}
+	if a.Prm == nil || !a.Prm.Verify(zkprm.Public{Aux: a.Pedersen}, h.Clone(), pl) {
+		return errors.New("invalid proof of Pedersen parameters")
+	}
+	self, ok := c.Public[c.ID]
+	if !ok || self.Pedersen == nil {
+		return errors.New("missing own Pedersen parameters")
+	}
+	fac := a.Fac[c.ID]
+	if fac == nil || !fac.Verify(zkfac.Public{N: a.Paillier.N(), Aux: self.Pedersen}, h.Clone()) {
+		return errors.New("invalid proof that the Paillier modulus has no small factor")
+	}
+	if a.Proof == nil || !a.Proof.Verify(&transferHash{h.Clone()}, a.ElGamal, nil) {
+		return errors.New("invalid proof of ElGamal key")
+	}
+	return nil
+}
+
+// public returns the public data of the party holding the parameters, with public share X.
+func (a *AuxInfo) public(X curve.Point) *Public {
+	return &Public{
+		ECDSA:    X,
+		ElGamal:  a.ElGamal,
+		Paillier: a.Paillier,
+		Pedersen: a.Pedersen,
+	}
+}
+
+// copyCommittee returns a copy of c whose public map can be modified independently.
+func copyCommittee(c *Config) *Config {
+	committee := *c
+	committee.Public = make(map[party.ID]*Public, len(c.Public))
+	for j, p := range c.Public {
+		committee.Public[j] = p
+	}
+	return &committee
+}
+
+// encryptFragment encrypts data to the key Y, under a fresh ephemeral key.
+func encryptFragment(domain string, Y curve.Point, data, additional []byte) (*EscrowFragment, error) {
+	e := sample.ScalarUnit(rand.Reader, Y.Curve())
+	ephemeral := e.ActOnBase()
+	aead, err := deriveKey(domain, e.Act(Y), ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	return &EscrowFragment{
+		Ephemeral:  ephemeral,
+		Ciphertext: aead.Seal(nil, make([]byte, aead.NonceSize()), data, additional),
+	}, nil
+}
+
+// decryptFragment decrypts a fragment created by encryptFragment with the secret key y.
+func decryptFragment(domain string, y curve.Scalar, f *EscrowFragment, additional []byte) ([]byte, error) {
+	if f == nil || f.Ephemeral == nil || f.Ephemeral.IsIdentity() {
+		return nil, errors.New("missing fragment")
+	}
+	aead, err := deriveKey(domain, y.Act(f.Ephemeral), f.Ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Open(nil, make([]byte, aead.NonceSize()), f.Ciphertext, additional)
+}
+
+// enrollmentHash binds the proofs of the newcomer to the committee and the session.
+func enrollmentHash(c *Config, r *EnrollmentRequest) *hash.Hash {
+	publicKey, _ := c.PublicPoint().MarshalBinary()
+	return hash.New(
+		hash.BytesWithDomain{TheDomain: "Enrollment RID", Bytes: c.RID},
+		hash.BytesWithDomain{TheDomain: "Enrollment Public Key", Bytes: publicKey},
+		hash.BytesWithDomain{TheDomain: "Enrollment Party", Bytes: []byte(r.ID)},
+		hash.BytesWithDomain{TheDomain: "Enrollment Session", Bytes: r.Session},
+	)
+}
+
+// pairwiseMask returns the mask shared by the pair i, k from their ECDH point,
+// for the share of party id in the given session, contributed by the sorted quorum.
+func pairwiseMask(domain string, session []byte, id party.ID, quorum party.IDSlice, shared curve.Point, i, k party.ID) (curve.Scalar, error) {
+	sharedBytes, err := shared.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	if k < i {
+		i, k = k, i
+	}
+	h := hash.New(
+		hash.BytesWithDomain{TheDomain: domain + " Mask Shared Point", Bytes: sharedBytes},
+		hash.BytesWithDomain{TheDomain: domain + " Mask Session", Bytes: session},
+		hash.BytesWithDomain{TheDomain: domain + " Mask Party", Bytes: []byte(id)},
+		quorum,
+		hash.BytesWithDomain{TheDomain: domain + " Mask Pair", Bytes: fragmentData(i, k)},
+	)
+	return sample.Scalar(h.Digest(), shared.Curve()), nil
+}
+
+// InMemorySessionLedger is a SessionLedger which does not survive restarts.
+type InMemorySessionLedger struct {
+	sessions map[string]bool
+	mtx      sync.Mutex
+}
+
+func NewInMemorySessionLedger() *InMemorySessionLedger {
+	return &InMemorySessionLedger{sessions: map[string]bool{}}
+}
+
+func (l *InMemorySessionLedger) Use(domain string, session []byte) error {
+	key := domain + "\x00" + string(session)
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.sessions[key] {
+		return fmt.Errorf("%w: %s %x", ErrSessionUsed, domain, session)
+	}
+	l.sessions[key] = true
+	return nil
+}
@@ -0,0 +1,129 @@
+package config_test
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	zkfac "github.com/mr-shifu/mpc-lib/core/zk/fac"
+	"github.com/mr-shifu/mpc-lib/lib/test"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withoutFac returns a copy of fac without the proof made for id.
+func withoutFac(fac map[party.ID]*zkfac.Proof, id party.ID) map[party.ID]*zkfac.Proof {
+	copied := make(map[party.ID]*zkfac.Proof, len(fac))
+	for j, proof := range fac {
+		if j != id {
+			copied[j] = proof
+		}
+	}
+	return copied
+}
+
+func TestEnrollment(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, ids := test.GenerateConfig(group, 4, 2, rand.Reader, pl)
+	// the generated configs share their public map, whereas each party adds the newcomer to its own
+	for _, c := range configs {
+		public := make(map[party.ID]*config.Public, len(c.Public))
+		for j, p := range c.Public {
+			public[j] = p
+		}
+		c.Public = public
+	}
+	publicKey := configs[ids[0]].PublicPoint()
+	quorum := ids[1:]
+
+	// a newcomer at 0 would get the secret key, and one at the point of a party would get its share
+	for _, id := range []party.ID{"", "\x00", "\x00" + ids[1]} {
+		_, _, err := config.RequestEnrollment(configs[ids[0]], id, []byte("session"), pl)
+		assert.ErrorIs(t, err, config.ErrInvalidEvaluationPoint)
+		assert.ErrorIs(t, err, config.ErrInvalidEnrollment)
+	}
+
+	enrollee, request, err := config.RequestEnrollment(configs[ids[0]], "new", []byte("session"), pl)
+	require.NoError(t, err)
+	_, err = configs[ids[0]].Enroll(request, quorum, config.NewInMemorySessionLedger())
+	assert.ErrorIs(t, err, config.ErrInvalidEnrollment, "newcomer not added")
+
+	// a request bound to another session is rejected
+	forged := *request
+	forged.Session = []byte("other")
+	assert.ErrorIs(t, configs[ids[0]].AddParty(&forged, pl), config.ErrInvalidEnrollment)
+	for _, id := range []party.ID{"", "\x00" + ids[1]} {
+		forged = *request
+		forged.ID = id
+		assert.ErrorIs(t, configs[ids[0]].AddParty(&forged, pl), config.ErrInvalidEvaluationPoint)
+	}
+
+	// the modulus must come with a proof that it has no small factor, made for the verifier
+	forged = *request
+	forged.Fac = withoutFac(request.Fac, ids[0])
+	assert.ErrorContains(t, configs[ids[0]].AddParty(&forged, pl), "small factor")
+	forged.Fac[ids[0]] = request.Fac[ids[1]]
+	assert.ErrorContains(t, configs[ids[0]].AddParty(&forged, pl), "small factor")
+
+	for _, id := range ids {
+		require.NoError(t, configs[id].AddParty(request, pl))
+		assert.True(t, publicKey.Equal(configs[id].PublicPoint()))
+	}
+	assert.ErrorIs(t, configs[ids[0]].AddParty(request, pl), config.ErrInvalidEnrollment, "already added")
+
+	ledgers := make(map[party.ID]config.SessionLedger, len(ids))
+	contributions := make([]*config.EnrollmentContribution, 0, len(quorum))
+	for _, id := range quorum {
+		ledgers[id] = config.NewInMemorySessionLedger()
+		contribution, err := configs[id].Enroll(request, quorum, ledgers[id])
+		require.NoError(t, err)
+		contributions = append(contributions, contribution)
+	}
+	// a second contribution to the same session is refused, even for another quorum
+	_, err = configs[ids[1]].Enroll(request, ids[:3], ledgers[ids[1]])
+	assert.ErrorIs(t, err, config.ErrInvalidEnrollment)
+	assert.ErrorIs(t, err, config.ErrSessionUsed)
+
+	_, err = enrollee.Complete(contributions[:2]...)
+	assert.ErrorIs(t, err, config.ErrInvalidEnrollment, "missing contribution")
+
+	newcomer, err := enrollee.Complete(contributions...)
+	require.NoError(t, err)
+	assert.Equal(t, 2, newcomer.Threshold)
+	assert.True(t, publicKey.Equal(newcomer.PublicPoint()))
+
+	updated := map[party.ID]*config.Config{newcomer.ID: newcomer}
+	for _, id := range ids {
+		c, err := configs[id].ApplyEnrollment(request, contributions...)
+		require.NoError(t, err)
+		assert.False(t, c.ECDSA.Equal(configs[id].ECDSA), "shares are re-randomized")
+		assert.Empty(t, c.Diff(newcomer))
+		updated[id] = c
+	}
+	_, err = configs[ids[0]].ApplyEnrollment(request, contributions[1:]...)
+	assert.ErrorIs(t, err, config.ErrInvalidEnrollment, "missing contribution")
+
+	// the newcomer's share interpolates x with the refreshed shares of any other t parties,
+	// but not with the previous ones
+	signers := party.NewIDSlice([]party.ID{"new", ids[0], ids[3]})
+	require.True(t, newcomer.CanSign(signers))
+	lagrange := polynomial.Lagrange(group, signers)
+	x, stale := group.NewScalar(), group.NewScalar()
+	for _, id := range signers {
+		x.Add(group.NewScalar().Set(lagrange[id]).Mul(updated[id].ECDSA))
+		share := newcomer.ECDSA
+		if id != newcomer.ID {
+			share = configs[id].ECDSA
+		}
+		stale.Add(group.NewScalar().Set(lagrange[id]).Mul(share))
+	}
+	assert.True(t, x.ActOnBase().Equal(publicKey))
+	assert.False(t, stale.ActOnBase().Equal(publicKey))
+}
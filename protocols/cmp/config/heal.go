@@ -0,0 +1,214 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mr-shifu/mpc-lib/core/hash"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/paillier"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	zksch "github.com/mr-shifu/mpc-lib/core/zk/sch"
+)
+
+var ErrInvalidHealing = errors.New("config: invalid healing")
+
+// HealingRequest is sent by a party which lost its share, but kept its identity key, to be provisioned a new one.
+//
+// Healing restores the party's share without exposing it to any single node, and re-randomizes all shares:
+//   - the lost party calls RequestHealing with its identity key, generating fresh auxiliary parameters,
+//   - each party of a signing quorum calls Heal once per session, sending its masked share of x_lost = f(lost),
+//     bound to the session and the quorum, and a sharing of 0 to all parties,
+//   - every other party calls ApplyHealing with the contributions of the whole quorum, obtaining its new Config,
+//   - the lost party calls Healer.Complete with the same contributions, obtaining its Config.
+//
+// Since all shares are re-randomized, the lost share is useless should it be found later.
+// The identity key is not part of the Config, and must be known to the other parties beforehand.
+type HealingRequest struct {
+	// ID is the identifier of the lost party.
+	ID party.ID
+	// Session is a unique identifier of this healing, chosen by the lost party.
+	Session []byte
+	// Identity is the identity key of the lost party.
+	Identity curve.Point
+	// IdentityProof is a proof of knowledge of the identity secret key.
+	IdentityProof *zksch.Proof
+	AuxInfo
+}
+
+// HealingContribution is sent by a quorum member to all parties.
+type HealingContribution struct {
+	// EnrollmentContribution holds the masked share λᵢ(lost)⋅xᵢ + ρᵢ, encrypted to the lost party,
+	// and the sharing of 0 re-randomizing the shares of all parties.
+	EnrollmentContribution
+}
+
+// Healer is the state of a lost party between RequestHealing and Complete.
+type Healer struct {
+	public   *Config
+	request  *HealingRequest
+	elgamal  curve.Scalar
+	paillier *paillier.SecretKey
+}
+
+// RequestHealing generates new auxiliary parameters for party id of the public config `public`,
+// authenticated with the party's identity secret key. The session must be unique to this healing.
+func RequestHealing(public *Config, id party.ID, identity curve.Scalar, session []byte, pl *pool.Pool) (*Healer, *HealingRequest, error) {
+	if _, ok := public.Public[id]; !ok {
+		return nil, nil, fmt.Errorf("%w: unknown party %s", ErrInvalidHealing, id)
+	}
+	if len(session) == 0 {
+		return nil, nil, fmt.Errorf("%w: empty session", ErrInvalidHealing)
+	}
+	r := &HealingRequest{
+		ID:       id,
+		Session:  append([]byte{}, session...),
+		Identity: identity.ActOnBase(),
+	}
+	h := healingHash(public, r)
+	r.IdentityProof = zksch.NewProof(&transferHash{h.Clone()}, r.Identity, identity, nil)
+	aux, y, sk := newAuxInfo(public.Group, h, verifiersExcept(public, id), pl)
+	r.AuxInfo = *aux
+	return &Healer{public: copyCommittee(public), request: r, elgamal: y, paillier: sk}, r, nil
+}
+
+// Heal verifies that r was issued by the owner of the identity key `identity`, and returns this party's contribution
+// to the share of the lost party. All parties in quorum must contribute. The session of r is recorded in ledger,
+// and a party contributes to it only once, even for another quorum.
+func (c *Config) Heal(r *HealingRequest, identity curve.Point, quorum []party.ID, ledger SessionLedger, pl *pool.Pool) (*HealingContribution, error) {
+	if err := r.verify(c, identity, pl); err != nil {
+		return nil, err
+	}
+	signers := party.NewIDSlice(quorum)
+	if signers.Contains(r.ID) || !c.CanSign(signers) {
+		return nil, fmt.Errorf("%w: quorum %v cannot sign", ErrInvalidHealing, signers)
+	}
+	if err := ledger.Use("Healing", r.Session); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidHealing, err)
+	}
+	share, err := c.maskedShare("Healing", r.Session, r.ID, r.ElGamal, signers)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.addRefresh("Healing", share, r.ID, r.ElGamal); err != nil {
+		return nil, err
+	}
+	return &HealingContribution{EnrollmentContribution: *share}, nil
+}
+
+// ApplyHealing verifies r and the contributions of a quorum, and returns this party's re-randomized Config,
+// in which the lost party's auxiliary parameters are replaced. c is left unchanged.
+func (c *Config) ApplyHealing(r *HealingRequest, identity curve.Point, pl *pool.Pool, contributions ...*HealingContribution) (*Config, error) {
+	if r.ID == c.ID {
+		return nil, fmt.Errorf("%w: cannot apply own healing", ErrInvalidHealing)
+	}
+	if err := r.verify(c, identity, pl); err != nil {
+		return nil, err
+	}
+	shares := enrollmentContributions(contributions)
+	public, err := refreshedPublic(c, r.ID, &r.AuxInfo, shares, ErrInvalidHealing)
+	if err != nil {
+		return nil, err
+	}
+	delta, err := refreshShare("Healing", c.Group, c.ID, c.ElGamal, shares, ErrInvalidHealing)
+	if err != nil {
+		return nil, err
+	}
+	x := c.Group.NewScalar().Set(c.ECDSA).Add(delta)
+	if !x.ActOnBase().Equal(public[c.ID].ECDSA) {
+		return nil, fmt.Errorf("%w: refreshed share does not match public share", ErrInvalidHealing)
+	}
+	return &Config{
+		Group:     c.Group,
+		ID:        c.ID,
+		Threshold: c.Threshold,
+		ECDSA:     x,
+		ElGamal:   c.ElGamal,
+		Paillier:  c.Paillier,
+		RID:       c.RID.Copy(),
+		ChainKey:  c.ChainKey.Copy(),
+		Public:    public,
+	}, nil
+}
+
+// Complete decrypts and checks the contributions of a quorum, and returns the lost party's Config.
+func (h *Healer) Complete(contributions ...*HealingContribution) (*Config, error) {
+	group := h.public.Group
+	shares := enrollmentContributions(contributions)
+	public, err := refreshedPublic(h.public, h.request.ID, &h.request.AuxInfo, shares, ErrInvalidHealing)
+	if err != nil {
+		return nil, err
+	}
+	x, err := openMaskedShares("Healing", h.public, h.request.ID, h.elgamal, shares, ErrInvalidHealing)
+	if err != nil {
+		return nil, err
+	}
+	delta, err := refreshShare("Healing", group, h.request.ID, h.elgamal, shares, ErrInvalidHealing)
+	if err != nil {
+		return nil, err
+	}
+	x.Add(delta)
+	if !x.ActOnBase().Equal(public[h.request.ID].ECDSA) {
+		return nil, fmt.Errorf("%w: healed share does not match public share", ErrInvalidHealing)
+	}
+	return &Config{
+		Group:     group,
+		ID:        h.request.ID,
+		Threshold: h.public.Threshold,
+		ECDSA:     x,
+		ElGamal:   h.elgamal,
+		Paillier:  h.paillier,
+		RID:       h.public.RID.Copy(),
+		ChainKey:  h.public.ChainKey.Copy(),
+		Public:    public,
+	}, nil
+}
+
+// verify checks that r was issued by the owner of `identity`, with valid auxiliary parameters.
+func (r *HealingRequest) verify(c *Config, identity curve.Point, pl *pool.Pool) error {
+	if _, ok := c.Public[r.ID]; !ok {
+		return fmt.Errorf("%w: unknown party %s", ErrInvalidHealing, r.ID)
+	}
+	if len(r.Session) == 0 {
+		return fmt.Errorf("%w: empty session", ErrInvalidHealing)
+	}
+	if identity == nil || identity.IsIdentity() || r.Identity == nil || !r.Identity.Equal(identity) {
+		return fmt.Errorf("%w: request was not issued by the identity key of %s", ErrInvalidHealing, r.ID)
+	}
+	h := healingHash(c, r)
+	if r.IdentityProof == nil || !r.IdentityProof.Verify(&transferHash{h.Clone()}, r.Identity, nil) {
+		return fmt.Errorf("%w: invalid proof of identity key", ErrInvalidHealing)
+	}
+	if err := r.AuxInfo.verify(h, c, pl); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidHealing, err)
+	}
+	return nil
+}
+
+// enrollmentContributions returns the contributions embedded in those of a healing,
+// or nil for the nil ones.
+func enrollmentContributions(contributions []*HealingContribution) []*EnrollmentContribution {
+	shares := make([]*EnrollmentContribution, 0, len(contributions))
+	for _, contribution := range contributions {
+		if contribution == nil {
+			shares = append(shares, nil)
+			continue
+		}
+		shares = append(shares, &contribution.EnrollmentContribution)
+	}
+	return shares
+}
+
+// healingHash binds the proofs of the lost party to the committee, the session and its identity key.
+func healingHash(c *Config, r *HealingRequest) *hash.Hash {
+	publicKey, _ := c.PublicPoint().MarshalBinary()
+	identity, _ := r.Identity.MarshalBinary()
+	return hash.New(
+		hash.BytesWithDomain{TheDomain: "Healing RID", Bytes: c.RID},
+		hash.BytesWithDomain{TheDomain: "Healing Public Key", Bytes: publicKey},
+		hash.BytesWithDomain{TheDomain: "Healing Party", Bytes: []byte(r.ID)},
+		hash.BytesWithDomain{TheDomain: "Healing Session", Bytes: r.Session},
+		hash.BytesWithDomain{TheDomain: "Healing Identity", Bytes: identity},
+	)
+}
@@ -0,0 +1,88 @@
+package config_test
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/lib/test"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealing(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, ids := test.GenerateConfig(group, 4, 2, rand.Reader, pl)
+	lost := configs[ids[0]]
+	publicKey := lost.PublicPoint()
+	quorum := ids[1:]
+
+	identity := sample.Scalar(rand.Reader, group)
+	healer, request, err := config.RequestHealing(configs[ids[1]], lost.ID, identity, []byte("session"), pl)
+	require.NoError(t, err)
+	_, err = configs[ids[1]].Heal(request, sample.Scalar(rand.Reader, group).ActOnBase(), quorum, config.NewInMemorySessionLedger(), pl)
+	assert.ErrorIs(t, err, config.ErrInvalidHealing, "request from another identity")
+
+	// the fresh modulus must come with a proof that it has no small factor, made for each verifier
+	forged := *request
+	forged.Fac = withoutFac(request.Fac, ids[1])
+	_, err = configs[ids[1]].Heal(&forged, identity.ActOnBase(), quorum, config.NewInMemorySessionLedger(), pl)
+	assert.ErrorIs(t, err, config.ErrInvalidHealing)
+	assert.ErrorContains(t, err, "small factor")
+	forged.Fac[ids[1]] = request.Fac[ids[2]]
+	_, err = configs[ids[1]].ApplyHealing(&forged, identity.ActOnBase(), pl)
+	assert.ErrorIs(t, err, config.ErrInvalidHealing)
+	assert.ErrorContains(t, err, "small factor", "proof made for another verifier")
+	assert.NotContains(t, request.Fac, lost.ID, "the lost party does not verify its own modulus")
+
+	ledgers := make(map[party.ID]config.SessionLedger, len(quorum))
+	contributions := make([]*config.HealingContribution, 0, len(quorum))
+	for _, id := range quorum {
+		ledgers[id] = config.NewInMemorySessionLedger()
+		contribution, err := configs[id].Heal(request, identity.ActOnBase(), quorum, ledgers[id], pl)
+		require.NoError(t, err)
+		contributions = append(contributions, contribution)
+	}
+	// a second contribution to the same session is refused
+	_, err = configs[ids[1]].Heal(request, identity.ActOnBase(), quorum, ledgers[ids[1]], pl)
+	assert.ErrorIs(t, err, config.ErrSessionUsed)
+	_, err = healer.Complete(contributions[:2]...)
+	assert.ErrorIs(t, err, config.ErrInvalidHealing, "missing contribution")
+
+	healed, err := healer.Complete(contributions...)
+	require.NoError(t, err)
+	assert.True(t, publicKey.Equal(healed.PublicPoint()))
+	assert.False(t, healed.ECDSA.Equal(lost.ECDSA), "shares are re-randomized")
+
+	updated := map[party.ID]*config.Config{lost.ID: healed}
+	for _, id := range quorum {
+		c, err := configs[id].ApplyHealing(request, identity.ActOnBase(), pl, contributions...)
+		require.NoError(t, err)
+		assert.False(t, c.ECDSA.Equal(configs[id].ECDSA), "shares are re-randomized")
+		assert.Empty(t, c.Diff(healed))
+		updated[id] = c
+	}
+
+	// the healed share interpolates x with the refreshed shares, but not with the previous ones
+	signers := party.NewIDSlice([]party.ID{lost.ID, ids[1], ids[3]})
+	lagrange := polynomial.Lagrange(group, signers)
+	x, stale := group.NewScalar(), group.NewScalar()
+	for _, id := range signers {
+		x.Add(group.NewScalar().Set(lagrange[id]).Mul(updated[id].ECDSA))
+		share := configs[id].ECDSA
+		if id == lost.ID {
+			share = healed.ECDSA
+		}
+		stale.Add(group.NewScalar().Set(lagrange[id]).Mul(share))
+	}
+	assert.True(t, x.ActOnBase().Equal(publicKey))
+	assert.False(t, stale.ActOnBase().Equal(publicKey))
+}
@@ -23,13 +23,19 @@ func EmptyConfig(group curve.Curve) *Config {
 	}
 }
 
+// EncodingVersion is the version of the encoding of a Config, recorded by MarshalBinary.
+// Configs marshaled before versions were recorded have version 0.
+const EncodingVersion = 1
+
 type configMarshal struct {
+	Version        int `cbor:",omitempty"`
 	ID             party.ID
 	Threshold      int
 	ECDSA, ElGamal curve.Scalar
 	P, Q           *saferith.Nat
 	RID, ChainKey  types.RID
 	Public         []cbor.RawMessage
+	Epoch          uint64 `cbor:",omitempty"`
 }
 
 type publicMarshal struct {
@@ -40,24 +46,12 @@ type publicMarshal struct {
 }
 
 func (c *Config) MarshalBinary() ([]byte, error) {
-	ps := make([]cbor.RawMessage, 0, len(c.Public))
-	for _, id := range c.PartyIDs() {
-		p := c.Public[id]
-		pm := &publicMarshal{
-			ID:      id,
-			ECDSA:   p.ECDSA,
-			ElGamal: p.ElGamal,
-			N:       p.Pedersen.N(),
-			S:       p.Pedersen.S(),
-			T:       p.Pedersen.T(),
-		}
-		data, err := cbor.Marshal(pm)
-		if err != nil {
-			return nil, err
-		}
-		ps = append(ps, data)
+	ps, err := c.marshalPublic()
+	if err != nil {
+		return nil, err
 	}
 	return cbor.Marshal(&configMarshal{
+		Version:   EncodingVersion,
 		ID:        c.ID,
 		Threshold: c.Threshold,
 		ECDSA:     c.ECDSA,
@@ -67,6 +61,7 @@ func (c *Config) MarshalBinary() ([]byte, error) {
 		RID:       c.RID,
 		ChainKey:  c.ChainKey,
 		Public:    ps,
+		Epoch:     c.Epoch,
 	})
 }
 
@@ -81,6 +76,9 @@ func (c *Config) UnmarshalBinary(data []byte) error {
 	if err := cbor.Unmarshal(data, &cm); err != nil {
 		return fmt.Errorf("config: %w", err)
 	}
+	if cm.Version > EncodingVersion {
+		return fmt.Errorf("config: encoding version %d is not supported", cm.Version)
+	}
 
 	// check ECDSA, ElGamal
 	if cm.ECDSA.IsZero() || cm.ElGamal.IsZero() {
@@ -121,23 +119,11 @@ func (c *Config) UnmarshalBinary(data []byte) error {
 			continue
 		}
 
-		if err := paillier.ValidateN(p.N); err != nil {
-			return fmt.Errorf("config: party %s: %w", p.ID, err)
-		}
-		if err := pedersen.ValidateParameters(p.N, p.S, p.T); err != nil {
-			return fmt.Errorf("config: party %s: %w", p.ID, err)
-		}
-		if p.ECDSA.IsIdentity() || p.ElGamal.IsIdentity() {
-			return fmt.Errorf("config: party %s: ECDSA or ElGamal public key is identity", p.ID)
-		}
-
-		paillierPublic := paillier.NewPublicKey(p.N)
-		ps[p.ID] = &Public{
-			ECDSA:    p.ECDSA,
-			ElGamal:  p.ElGamal,
-			Paillier: paillierPublic,
-			Pedersen: pedersen.New(paillierPublic.Modulus(), p.S, p.T),
+		public, err := p.validate()
+		if err != nil {
+			return err
 		}
+		ps[p.ID] = public
 	}
 
 	// verify number of parties w.r.t. threshold
@@ -161,6 +147,73 @@ func (c *Config) UnmarshalBinary(data []byte) error {
 		RID:       cm.RID,
 		ChainKey:  cm.ChainKey,
 		Public:    ps,
+		Epoch:     cm.Epoch,
 	}
 	return nil
 }
+
+// marshalPublic returns the public data of all parties, sorted by ID.
+func (c *Config) marshalPublic() ([]cbor.RawMessage, error) {
+	ps := make([]cbor.RawMessage, 0, len(c.Public))
+	for _, id := range c.PartyIDs() {
+		p := c.Public[id]
+		pm := &publicMarshal{
+			ID:      id,
+			ECDSA:   p.ECDSA,
+			ElGamal: p.ElGamal,
+			N:       p.Pedersen.N(),
+			S:       p.Pedersen.S(),
+			T:       p.Pedersen.T(),
+		}
+		data, err := cbor.Marshal(pm)
+		if err != nil {
+			return nil, err
+		}
+		ps = append(ps, data)
+	}
+	return ps, nil
+}
+
+// unmarshalPublic decodes and validates the public data of all parties.
+func unmarshalPublic(group curve.Curve, raw []cbor.RawMessage) (map[party.ID]*Public, error) {
+	ps := make(map[party.ID]*Public, len(raw))
+	for _, pm := range raw {
+		p := &publicMarshal{
+			ECDSA:   group.NewPoint(),
+			ElGamal: group.NewPoint(),
+		}
+		if err := cbor.Unmarshal(pm, p); err != nil {
+			return nil, fmt.Errorf("config: party %s: %w", p.ID, err)
+		}
+		if _, ok := ps[p.ID]; ok {
+			return nil, fmt.Errorf("config: party %s: duplicate entry", p.ID)
+		}
+		public, err := p.validate()
+		if err != nil {
+			return nil, err
+		}
+		ps[p.ID] = public
+	}
+	return ps, nil
+}
+
+// validate checks the public data of another party.
+func (p *publicMarshal) validate() (*Public, error) {
+	if err := paillier.ValidateN(p.N); err != nil {
+		return nil, fmt.Errorf("config: party %s: %w", p.ID, err)
+	}
+	if err := pedersen.ValidateParameters(p.N, p.S, p.T); err != nil {
+		return nil, fmt.Errorf("config: party %s: %w", p.ID, err)
+	}
+	if p.ECDSA.IsIdentity() || p.ElGamal.IsIdentity() {
+		return nil, fmt.Errorf("config: party %s: ECDSA or ElGamal public key is identity", p.ID)
+	}
+
+	paillierPublic := paillier.NewPublicKey(p.N)
+	return &Public{
+		ECDSA:    p.ECDSA,
+		ElGamal:  p.ElGamal,
+		Paillier: paillierPublic,
+		Pedersen: pedersen.New(paillierPublic.Modulus(), p.S, p.T),
+	}, nil
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/hash"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	zkmod "github.com/mr-shifu/mpc-lib/core/zk/mod"
+	zksch "github.com/mr-shifu/mpc-lib/core/zk/sch"
+	"github.com/mr-shifu/mpc-lib/lib/types"
+)
+
+var ErrInvalidPublicPackage = errors.New("config: invalid public package")
+
+// PublicPackage is the public material of a committee, exported by one of its parties
+// for external services which verify partial signatures and protocol artifacts.
+//
+// It contains no secret: the Config only holds the public key shares, ElGamal keys and
+// Paillier/Pedersen parameters of all parties.
+// The proofs show that the exporter holds the secrets matching its own public data.
+type PublicPackage struct {
+	// Config is the public part of the exporter's config, with ID set to the exporter.
+	Config *Config
+	// ECDSA is a proof of knowledge of the exporter's share xᵢ.
+	ECDSA *zksch.Proof
+	// ElGamal is a proof of knowledge of the exporter's yᵢ.
+	ElGamal *zksch.Proof
+	// Mod is a proof that the exporter's Paillier modulus Nᵢ is a Blum integer.
+	Mod *zkmod.Proof
+}
+
+// PublicPackageVersion is the version of the encoding of a public package, recorded by ExportPublic.
+const PublicPackageVersion = 1
+
+type publicPackageMarshal struct {
+	Version       int `cbor:",omitempty"`
+	ID            party.ID
+	Threshold     int
+	RID, ChainKey types.RID
+	Public        []cbor.RawMessage
+	ECDSA         *zksch.Proof
+	ElGamal       *zksch.Proof
+	Mod           *zkmod.Proof
+	Signature     []byte
+}
+
+// ExportPublic returns the public package of the committee, signed with the identity key of this party.
+func (c *Config) ExportPublic(key ed25519.PrivateKey, pl *pool.Pool) ([]byte, error) {
+	if c.ECDSA == nil || c.ElGamal == nil || c.Paillier == nil {
+		return nil, errors.New("config: exporting a public package requires the secret shares")
+	}
+	ps, err := c.marshalPublic()
+	if err != nil {
+		return nil, err
+	}
+	self := c.Public[c.ID]
+	h := publicPackageHash(c)
+	pm := &publicPackageMarshal{
+		Version:   PublicPackageVersion,
+		ID:        c.ID,
+		Threshold: c.Threshold,
+		RID:       c.RID,
+		ChainKey:  c.ChainKey,
+		Public:    ps,
+		ECDSA:     zksch.NewProof(&transferHash{h.Clone()}, self.ECDSA, c.ECDSA, nil),
+		ElGamal:   zksch.NewProof(&transferHash{h.Clone()}, self.ElGamal, c.ElGamal, nil),
+		Mod: zkmod.NewProof(h.Clone(), zkmod.Private{
+			P:   c.Paillier.P(),
+			Q:   c.Paillier.Q(),
+			Phi: c.Paillier.Phi(),
+		}, zkmod.Public{N: self.Paillier.N()}, pl),
+	}
+	msg, err := pm.signedData()
+	if err != nil {
+		return nil, err
+	}
+	pm.Signature = ed25519.Sign(key, msg)
+	return cbor.Marshal(pm)
+}
+
+// OpenPublicPackage verifies a package created by ExportPublic against the identity key `pub` of the exporter,
+// as well as the exporter's proofs, and returns it.
+func OpenPublicPackage(data []byte, group curve.Curve, pub ed25519.PublicKey, pl *pool.Pool) (*PublicPackage, error) {
+	pm := &publicPackageMarshal{
+		ECDSA:   zksch.EmptyProof(group),
+		ElGamal: zksch.EmptyProof(group),
+	}
+	if err := cbor.Unmarshal(data, pm); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	if pm.Version > PublicPackageVersion {
+		return nil, fmt.Errorf("%w: version %d is not supported", ErrInvalidPublicPackage, pm.Version)
+	}
+	msg, err := pm.signedData()
+	if err != nil {
+		return nil, err
+	}
+	if len(pub) != ed25519.PublicKeySize || !ed25519.Verify(pub, msg, pm.Signature) {
+		return nil, fmt.Errorf("%w: invalid signature", ErrInvalidPublicPackage)
+	}
+
+	ps, err := unmarshalPublic(group, pm.Public)
+	if err != nil {
+		return nil, err
+	}
+	if !ValidThreshold(pm.Threshold, len(ps)) {
+		return nil, fmt.Errorf("config: threshold %d is invalid", pm.Threshold)
+	}
+	self, ok := ps[pm.ID]
+	if !ok {
+		return nil, fmt.Errorf("%w: no public data for exporter %s", ErrInvalidPublicPackage, pm.ID)
+	}
+	c := &Config{
+		Group:     group,
+		ID:        pm.ID,
+		Threshold: pm.Threshold,
+		RID:       pm.RID,
+		ChainKey:  pm.ChainKey,
+		Public:    ps,
+	}
+
+	h := publicPackageHash(c)
+	if pm.ECDSA == nil || !pm.ECDSA.Verify(&transferHash{h.Clone()}, self.ECDSA, nil) {
+		return nil, fmt.Errorf("%w: invalid proof of ECDSA share", ErrInvalidPublicPackage)
+	}
+	if pm.ElGamal == nil || !pm.ElGamal.Verify(&transferHash{h.Clone()}, self.ElGamal, nil) {
+		return nil, fmt.Errorf("%w: invalid proof of ElGamal key", ErrInvalidPublicPackage)
+	}
+	if pm.Mod == nil || !pm.Mod.Verify(zkmod.Public{N: self.Paillier.N()}, h.Clone(), pl) {
+		return nil, fmt.Errorf("%w: invalid proof of Paillier modulus", ErrInvalidPublicPackage)
+	}
+
+	return &PublicPackage{
+		Config:  c,
+		ECDSA:   pm.ECDSA,
+		ElGamal: pm.ElGamal,
+		Mod:     pm.Mod,
+	}, nil
+}
+
+// PublicPoint returns the public key of the committee.
+func (p *PublicPackage) PublicPoint() curve.Point {
+	return p.Config.PublicPoint()
+}
+
+// publicPackageHash binds the proofs of a package to the committee and the exporter.
+func publicPackageHash(c *Config) *hash.Hash {
+	return hash.New(c, hash.BytesWithDomain{TheDomain: "Public Package Exporter", Bytes: []byte(c.ID)})
+}
+
+func (pm *publicPackageMarshal) signedData() ([]byte, error) {
+	return cbor.Marshal(struct {
+		Domain        string
+		Version       int `cbor:",omitempty"`
+		ID            party.ID
+		Threshold     int
+		RID, ChainKey types.RID
+		Public        []cbor.RawMessage
+		ECDSA         *zksch.Proof
+		ElGamal       *zksch.Proof
+		Mod           *zkmod.Proof
+	}{"CMP Public Package", pm.Version, pm.ID, pm.Threshold, pm.RID, pm.ChainKey, pm.Public, pm.ECDSA, pm.ElGamal, pm.Mod})
+}
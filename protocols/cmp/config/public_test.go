@@ -0,0 +1,56 @@
+package config_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/lib/test"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicPackage(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, ids := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+	c := configs[ids[0]]
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	data, err := c.ExportPublic(priv, pl)
+	require.NoError(t, err)
+
+	p, err := config.OpenPublicPackage(data, group, pub, pl)
+	require.NoError(t, err)
+	assert.True(t, c.PublicPoint().Equal(p.PublicPoint()))
+	assert.Nil(t, p.Config.ECDSA)
+	assert.Nil(t, p.Config.Paillier)
+	assert.Empty(t, c.Diff(p.Config))
+
+	// the version is signed, and packages of a later version are refused
+	_, err = config.OpenPublicPackage(withVersion(t, data, 0), group, pub, pl)
+	assert.ErrorIs(t, err, config.ErrInvalidPublicPackage)
+	_, err = config.OpenPublicPackage(withVersion(t, data, config.PublicPackageVersion+1), group, pub, pl)
+	assert.ErrorIs(t, err, config.ErrInvalidPublicPackage)
+	assert.Contains(t, err.Error(), "version")
+
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, err = config.OpenPublicPackage(data, group, otherPub, pl)
+	assert.ErrorIs(t, err, config.ErrInvalidPublicPackage)
+
+	// a package exported by another party cannot claim the proofs of ids[0]
+	public := *c
+	public.ID = ids[1]
+	public.ECDSA, public.ElGamal = configs[ids[0]].ECDSA, configs[ids[0]].ElGamal
+	data, err = public.ExportPublic(priv, pl)
+	require.NoError(t, err)
+	_, err = config.OpenPublicPackage(data, group, pub, pl)
+	assert.ErrorIs(t, err, config.ErrInvalidPublicPackage)
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/party"
+)
+
+var ErrInvalidRecoveryFragments = errors.New("config: invalid recovery fragments")
+
+// RecoveryFragment is a Shamir share of a single party's ECDSA secret share xᵢ,
+// given to a guardian for backup.
+//
+// Any Threshold fragments can be combined with RecoverShare to recover xᵢ, without involving the other parties.
+type RecoveryFragment struct {
+	// Owner is the party whose share was split.
+	Owner party.ID
+	// Guardian is the holder of this fragment, and its evaluation point.
+	Guardian party.ID
+	// Threshold is the number of fragments required to recover the share.
+	Threshold int
+	// Share = f(Guardian), where f(0) = xᵢ
+	Share curve.Scalar
+	// Public = xᵢ⋅G, used to check the recovered share.
+	Public curve.Point
+}
+
+// EmptyRecoveryFragment returns a RecoveryFragment with a given group, ready for unmarshalling.
+func EmptyRecoveryFragment(group curve.Curve) *RecoveryFragment {
+	return &RecoveryFragment{
+		Share:  group.NewScalar(),
+		Public: group.NewPoint(),
+	}
+}
+
+// SplitShare splits this party's ECDSA share into one fragment for each guardian,
+// such that any `threshold` of them are required to recover it.
+// The guardians must have distinct evaluation points other than 0, or ErrInvalidEvaluationPoint is returned.
+func (c *Config) SplitShare(threshold int, guardians []party.ID) (map[party.ID]*RecoveryFragment, error) {
+	ids := party.NewIDSlice(guardians)
+	if !ids.Valid() {
+		return nil, errors.New("config: guardians are invalid")
+	}
+	if threshold < 1 || threshold > len(ids) {
+		return nil, fmt.Errorf("config: recovery threshold %d is invalid for %d guardians", threshold, len(ids))
+	}
+	// a guardian at 0 would receive xᵢ itself
+	if err := checkEvaluationPoints(c.Group, ids); err != nil {
+		return nil, err
+	}
+
+	// f(X) of degree threshold-1 with f(0) = xᵢ
+	f := polynomial.NewPolynomial(c.Group, threshold-1, c.ECDSA)
+	public := c.ECDSA.ActOnBase()
+	fragments := make(map[party.ID]*RecoveryFragment, len(ids))
+	for _, j := range ids {
+		fragments[j] = &RecoveryFragment{
+			Owner:     c.ID,
+			Guardian:  j,
+			Threshold: threshold,
+			Share:     f.Evaluate(j.Scalar(c.Group)),
+			Public:    public,
+		}
+	}
+	return fragments, nil
+}
+
+// RecoverShare interpolates the secret share of the fragments' owner,
+// and checks it against the public share stored in the fragments.
+func RecoverShare(fragments ...*RecoveryFragment) (curve.Scalar, error) {
+	if len(fragments) == 0 || fragments[0] == nil {
+		return nil, fmt.Errorf("%w: no fragments", ErrInvalidRecoveryFragments)
+	}
+	first := fragments[0]
+	group := first.Public.Curve()
+
+	byGuardian := make(map[party.ID]*RecoveryFragment, len(fragments))
+	for _, f := range fragments {
+		if f == nil || f.Owner != first.Owner || f.Threshold != first.Threshold || !f.Public.Equal(first.Public) {
+			return nil, fmt.Errorf("%w: fragments belong to different shares", ErrInvalidRecoveryFragments)
+		}
+		if _, ok := byGuardian[f.Guardian]; ok {
+			return nil, fmt.Errorf("%w: duplicate fragment from %s", ErrInvalidRecoveryFragments, f.Guardian)
+		}
+		byGuardian[f.Guardian] = f
+	}
+	if len(byGuardian) < first.Threshold {
+		return nil, fmt.Errorf("%w: got %d fragments, need %d", ErrInvalidRecoveryFragments, len(byGuardian), first.Threshold)
+	}
+
+	guardians := make([]party.ID, 0, len(byGuardian))
+	for j := range byGuardian {
+		guardians = append(guardians, j)
+	}
+	lagrange := polynomial.Lagrange(group, guardians)
+	secret := group.NewScalar()
+	for j, f := range byGuardian {
+		secret.Add(group.NewScalar().Set(lagrange[j]).Mul(f.Share))
+	}
+	if !secret.ActOnBase().Equal(first.Public) {
+		return nil, fmt.Errorf("%w: recovered share does not match public share", ErrInvalidRecoveryFragments)
+	}
+	return secret, nil
+}
+
+type recoveryFragmentMarshal struct {
+	Owner, Guardian party.ID
+	Threshold       int
+	Share           curve.Scalar
+	Public          curve.Point
+}
+
+func (f *RecoveryFragment) MarshalBinary() ([]byte, error) {
+	return cbor.Marshal(&recoveryFragmentMarshal{
+		Owner:     f.Owner,
+		Guardian:  f.Guardian,
+		Threshold: f.Threshold,
+		Share:     f.Share,
+		Public:    f.Public,
+	})
+}
+
+func (f *RecoveryFragment) UnmarshalBinary(data []byte) error {
+	if f.Share == nil || f.Public == nil {
+		return errors.New("recovery fragment must be initialized using EmptyRecoveryFragment")
+	}
+	fm := &recoveryFragmentMarshal{
+		Share:  f.Share,
+		Public: f.Public,
+	}
+	if err := cbor.Unmarshal(data, fm); err != nil {
+		return fmt.Errorf("recovery fragment: %w", err)
+	}
+	if fm.Share.IsZero() || fm.Public.IsIdentity() {
+		return errors.New("recovery fragment: share or public share is zero")
+	}
+	*f = RecoveryFragment{
+		Owner:     fm.Owner,
+		Guardian:  fm.Guardian,
+		Threshold: fm.Threshold,
+		Share:     fm.Share,
+		Public:    fm.Public,
+	}
+	return nil
+}
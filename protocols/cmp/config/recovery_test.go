@@ -0,0 +1,55 @@
+package config
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitRecoverShare(t *testing.T) {
+	group := curve.Secp256k1{}
+	c := &Config{
+		Group: group,
+		ID:    "a",
+		ECDSA: sample.Scalar(rand.Reader, group),
+	}
+	guardians := []party.ID{"g1", "g2", "g3", "g4", "g5"}
+	fragments, err := c.SplitShare(3, guardians)
+	require.NoError(t, err)
+	require.Len(t, fragments, len(guardians))
+
+	// round trip a fragment through its encoding
+	data, err := fragments["g2"].MarshalBinary()
+	require.NoError(t, err)
+	g2 := EmptyRecoveryFragment(group)
+	require.NoError(t, g2.UnmarshalBinary(data))
+
+	secret, err := RecoverShare(fragments["g1"], g2, fragments["g5"])
+	require.NoError(t, err)
+	assert.True(t, secret.Equal(c.ECDSA))
+
+	_, err = RecoverShare(fragments["g1"], fragments["g5"])
+	assert.ErrorIs(t, err, ErrInvalidRecoveryFragments)
+
+	_, err = RecoverShare(fragments["g1"], fragments["g1"], fragments["g5"])
+	assert.ErrorIs(t, err, ErrInvalidRecoveryFragments)
+
+	tampered := *fragments["g3"]
+	tampered.Share = sample.Scalar(rand.Reader, group)
+	_, err = RecoverShare(fragments["g1"], &tampered, fragments["g5"])
+	assert.ErrorIs(t, err, ErrInvalidRecoveryFragments)
+
+	_, err = c.SplitShare(6, guardians)
+	assert.Error(t, err)
+
+	// a guardian at 0 would receive the share itself, and two guardians at the same point the same fragment
+	for _, id := range []party.ID{"", "\x00", "\x00g1"} {
+		_, err = c.SplitShare(3, append([]party.ID{id}, guardians...))
+		assert.ErrorIs(t, err, ErrInvalidEvaluationPoint)
+	}
+}
@@ -0,0 +1,273 @@
+package config
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mr-shifu/mpc-lib/core/hash"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	zksch "github.com/mr-shifu/mpc-lib/core/zk/sch"
+	comm_hash "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+var ErrInvalidTransfer = errors.New("config: invalid share transfer")
+
+// ShareTransfer moves the share bundle of a departing party to a successor node,
+// which takes over the same party.ID, without running a full refresh.
+//
+// The transfer proceeds as follows:
+//   - the departing party calls TransferShare with a fresh session and the successor's identity key,
+//   - at least Threshold other parties check the transfer with EndorseTransfer,
+//   - the successor calls AcceptTransfer with the endorsements, obtaining its Config and a Succession,
+//   - every party calls ApplySuccession, replacing the successor's public ElGamal, Paillier and Pedersen keys.
+//
+// The successor generates new auxiliary keys, since the departing party knows the old ones. It also proves
+// knowledge of its identity key over them, so that neither the departing party nor the network can announce
+// keys of their own. The departing party still keeps a copy of the ECDSA share, so a refresh should still
+// be run once convenient. Until then, the share is only as safe as the departing node.
+type ShareTransfer struct {
+	// ID is the party being replaced.
+	ID party.ID
+	// Session is a unique identifier of this transfer, chosen by the departing party.
+	Session []byte
+	// Successor is the identity key of the node taking over ID.
+	Successor curve.Point
+	// Ephemeral = e⋅G
+	Ephemeral curve.Point
+	// Ciphertext = AEAD(H(e⋅Successor), Config)
+	Ciphertext []byte
+	// Proof is a proof of knowledge of xᵢ, bound to the successor.
+	Proof *zksch.Proof
+}
+
+// TransferEndorsement is issued by another party to confirm a ShareTransfer.
+type TransferEndorsement struct {
+	// Endorser is the party which checked the transfer.
+	Endorser party.ID
+	// Proof is a proof of knowledge of the endorser's xⱼ, bound to the transfer.
+	Proof *zksch.Proof
+}
+
+// Succession announces the new auxiliary keys of the party taken over by a successor.
+type Succession struct {
+	ID party.ID
+	// AuxInfo holds the successor's fresh Yᵢ, Nᵢ, sᵢ and tᵢ, with proofs bound to the transfer.
+	AuxInfo
+	// Identity is a proof of knowledge of the successor's identity key, bound to the transfer and AuxInfo.
+	Identity *zksch.Proof
+}
+
+// TransferShare encrypts this party's config to the successor's identity key.
+// The session must not be reused for another transfer.
+func (c *Config) TransferShare(session []byte, successor curve.Point) (*ShareTransfer, error) {
+	if len(session) == 0 {
+		return nil, fmt.Errorf("%w: missing session", ErrInvalidTransfer)
+	}
+	if successor.IsIdentity() {
+		return nil, fmt.Errorf("%w: successor key is identity", ErrInvalidTransfer)
+	}
+	data, err := c.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	e := sample.ScalarUnit(rand.Reader, c.Group)
+	E := e.ActOnBase()
+	t := &ShareTransfer{
+		ID:        c.ID,
+		Session:   append([]byte{}, session...),
+		Successor: successor,
+		Ephemeral: E,
+	}
+	aead, err := deriveKey("Share Transfer", e.Act(successor), E)
+	if err != nil {
+		return nil, err
+	}
+	t.Ciphertext = aead.Seal(nil, make([]byte, aead.NonceSize()), data, []byte(c.ID))
+	t.Proof = zksch.NewProof(t.hash(), c.Public[c.ID].ECDSA, c.ECDSA, nil)
+	return t, nil
+}
+
+// EndorseTransfer checks that t was issued by the owner of the share being transferred,
+// and returns an endorsement binding this party's share to it.
+func (c *Config) EndorseTransfer(t *ShareTransfer) (*TransferEndorsement, error) {
+	if t.ID == c.ID {
+		return nil, fmt.Errorf("%w: cannot endorse own transfer", ErrInvalidTransfer)
+	}
+	if err := t.verify(c); err != nil {
+		return nil, err
+	}
+	return &TransferEndorsement{
+		Endorser: c.ID,
+		Proof:    zksch.NewProof(t.endorsementHash(c.ID), c.Public[c.ID].ECDSA, c.ECDSA, nil),
+	}, nil
+}
+
+// AcceptTransfer is run by the successor with its identity secret key. It checks the transfer and
+// its endorsements against the public config `public` obtained from another party, and decrypts the share.
+//
+// Fresh ElGamal, Paillier and Pedersen keys are generated, and must be announced to all parties
+// through the returned Succession.
+func AcceptTransfer(public *Config, t *ShareTransfer, identity curve.Scalar, endorsements []*TransferEndorsement, pl *pool.Pool) (*Config, *Succession, error) {
+	if !identity.ActOnBase().Equal(t.Successor) {
+		return nil, nil, fmt.Errorf("%w: transfer is for another successor", ErrInvalidTransfer)
+	}
+	if err := t.verify(public); err != nil {
+		return nil, nil, err
+	}
+	endorsers := map[party.ID]bool{}
+	for _, e := range endorsements {
+		p, ok := public.Public[e.Endorser]
+		if e.Endorser == t.ID || !ok || endorsers[e.Endorser] {
+			continue
+		}
+		if e.Proof == nil || !e.Proof.Verify(t.endorsementHash(e.Endorser), p.ECDSA, nil) {
+			return nil, nil, fmt.Errorf("%w: invalid endorsement from %s", ErrInvalidTransfer, e.Endorser)
+		}
+		endorsers[e.Endorser] = true
+	}
+	if len(endorsers) < public.Threshold {
+		return nil, nil, fmt.Errorf("%w: got %d endorsements, need %d", ErrInvalidTransfer, len(endorsers), public.Threshold)
+	}
+
+	aead, err := deriveKey("Share Transfer", identity.Act(t.Ephemeral), t.Ephemeral)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := aead.Open(nil, make([]byte, aead.NonceSize()), t.Ciphertext, []byte(t.ID))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidTransfer, err)
+	}
+	c := EmptyConfig(public.Group)
+	if err := c.UnmarshalBinary(data); err != nil {
+		return nil, nil, err
+	}
+	if c.ID != t.ID {
+		return nil, nil, fmt.Errorf("%w: config belongs to %s", ErrInvalidTransfer, c.ID)
+	}
+	if err := c.CompatibleWith(public); err != nil {
+		return nil, nil, err
+	}
+
+	// the departing party knows yᵢ and the Paillier secret key, so they are replaced
+	h := t.successionHash(public)
+	aux, y, sk := newAuxInfo(c.Group, h, verifiersExcept(public, c.ID), pl)
+	c.ElGamal, c.Paillier = y, sk
+	c.Public[c.ID] = aux.public(c.Public[c.ID].ECDSA)
+	s := &Succession{ID: c.ID, AuxInfo: *aux}
+	s.Identity = zksch.NewProof(s.identityHash(h), t.Successor, identity, nil)
+	return c, s, nil
+}
+
+// ApplySuccession replaces the successor's public auxiliary keys, after checking that s belongs to t
+// and was announced by its successor.
+func (c *Config) ApplySuccession(t *ShareTransfer, s *Succession, pl *pool.Pool) error {
+	p, ok := c.Public[s.ID]
+	if !ok || s.ID != t.ID || s.ID == c.ID {
+		return fmt.Errorf("%w: unexpected party %s", ErrInvalidTransfer, s.ID)
+	}
+	if err := t.verify(c); err != nil {
+		return err
+	}
+	h := t.successionHash(c)
+	if err := s.AuxInfo.verify(h, c, pl); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTransfer, err)
+	}
+	if s.Identity == nil || !s.Identity.Verify(s.identityHash(h), t.Successor, nil) {
+		return fmt.Errorf("%w: succession not announced by the successor", ErrInvalidTransfer)
+	}
+	c.Public[s.ID] = s.AuxInfo.public(p.ECDSA)
+	return nil
+}
+
+// verify checks that t was created by the owner of Xᵢ in c.
+func (t *ShareTransfer) verify(c *Config) error {
+	p, ok := c.Public[t.ID]
+	if !ok {
+		return fmt.Errorf("%w: unknown party %s", ErrInvalidTransfer, t.ID)
+	}
+	if len(t.Session) == 0 || t.Successor == nil || t.Ephemeral == nil || t.Successor.IsIdentity() || t.Ephemeral.IsIdentity() {
+		return fmt.Errorf("%w: invalid keys", ErrInvalidTransfer)
+	}
+	if t.Proof == nil || !t.Proof.Verify(t.hash(), p.ECDSA, nil) {
+		return fmt.Errorf("%w: invalid proof of share", ErrInvalidTransfer)
+	}
+	return nil
+}
+
+// hash returns a hash function bound to the transferred party, the session and the successor.
+func (t *ShareTransfer) hash() comm_hash.Hash {
+	return &transferHash{t.transcript()}
+}
+
+func (t *ShareTransfer) transcript() *hash.Hash {
+	successor, _ := t.Successor.MarshalBinary()
+	ephemeral, _ := t.Ephemeral.MarshalBinary()
+	return hash.New(
+		hash.BytesWithDomain{TheDomain: "Share Transfer Party", Bytes: []byte(t.ID)},
+		hash.BytesWithDomain{TheDomain: "Share Transfer Session", Bytes: t.Session},
+		hash.BytesWithDomain{TheDomain: "Share Transfer Successor", Bytes: successor},
+		hash.BytesWithDomain{TheDomain: "Share Transfer Ephemeral", Bytes: ephemeral},
+	)
+}
+
+// successionHash binds the proofs of the successor's auxiliary keys to the transfer and the committee c.
+func (t *ShareTransfer) successionHash(c *Config) *hash.Hash {
+	h := t.transcript()
+	_ = h.WriteAny(hash.BytesWithDomain{TheDomain: "Share Transfer RID", Bytes: c.RID})
+	return h
+}
+
+// identityHash binds the proof of the successor's identity to its auxiliary keys.
+func (s *Succession) identityHash(h *hash.Hash) comm_hash.Hash {
+	h = h.Clone()
+	_ = h.WriteAny(s.AuxInfo.ElGamal, s.AuxInfo.Paillier, s.AuxInfo.Pedersen)
+	return &transferHash{h}
+}
+
+func (t *ShareTransfer) endorsementHash(endorser party.ID) comm_hash.Hash {
+	h := t.hash()
+	_ = h.WriteAny(hash.BytesWithDomain{TheDomain: "Share Transfer Endorser", Bytes: []byte(endorser)})
+	return h
+}
+
+// transferHash adapts a core hash.Hash for the zk proofs.
+type transferHash struct {
+	*hash.Hash
+}
+
+func (h *transferHash) Clone() comm_hash.Hash {
+	return &transferHash{h.Hash.Clone()}
+}
+
+func (h *transferHash) WithDomain(domain string, data ...[]byte) comm_hash.Hash {
+	return &transferHash{h.Hash.WithDomain(domain, data...)}
+}
+
+// deriveKey derives the AEAD key encrypting a share to a recipient key, from the ECDH shared point.
+// The domain separates the uses of the key, such as "Share Transfer".
+func deriveKey(domain string, shared, ephemeral curve.Point) (cipher.AEAD, error) {
+	sharedBytes, err := shared.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	ephemeralBytes, err := ephemeral.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	h := hash.New(
+		hash.BytesWithDomain{TheDomain: domain + " Shared Point", Bytes: sharedBytes},
+		hash.BytesWithDomain{TheDomain: domain + " Ephemeral", Bytes: ephemeralBytes},
+	)
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(h.Digest(), key); err != nil {
+		return nil, err
+	}
+	return chacha20poly1305.New(key)
+}
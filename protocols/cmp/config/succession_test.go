@@ -0,0 +1,74 @@
+package config_test
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/lib/test"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareTransfer(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, ids := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+	departing, endorser := configs[ids[0]], configs[ids[1]]
+	publicKey := departing.PublicPoint()
+
+	identity := sample.Scalar(rand.Reader, group)
+	_, err := departing.TransferShare(nil, identity.ActOnBase())
+	assert.ErrorIs(t, err, config.ErrInvalidTransfer, "transfer without session")
+	transfer, err := departing.TransferShare([]byte("session"), identity.ActOnBase())
+	require.NoError(t, err)
+
+	_, err = departing.EndorseTransfer(transfer)
+	assert.ErrorIs(t, err, config.ErrInvalidTransfer)
+	endorsement, err := endorser.EndorseTransfer(transfer)
+	require.NoError(t, err)
+
+	_, _, err = config.AcceptTransfer(endorser, transfer, identity, nil, pl)
+	assert.ErrorIs(t, err, config.ErrInvalidTransfer, "transfer without endorsements")
+	_, _, err = config.AcceptTransfer(endorser, transfer, sample.Scalar(rand.Reader, group), []*config.TransferEndorsement{endorsement}, pl)
+	assert.ErrorIs(t, err, config.ErrInvalidTransfer, "transfer to another successor")
+
+	successor, succession, err := config.AcceptTransfer(endorser, transfer, identity, []*config.TransferEndorsement{endorsement}, pl)
+	require.NoError(t, err)
+	assert.Equal(t, departing.ID, successor.ID)
+	assert.True(t, successor.ECDSA.Equal(departing.ECDSA))
+	assert.False(t, successor.ElGamal.Equal(departing.ElGamal))
+	assert.Zero(t, successor.Paillier.N().Nat().Eq(departing.Paillier.N().Nat()), "the Paillier key must be rotated")
+	assert.True(t, successor.PublicPoint().Equal(publicKey))
+
+	forged := *succession
+	forged.ElGamal = sample.Scalar(rand.Reader, group).ActOnBase()
+	assert.ErrorIs(t, endorser.ApplySuccession(transfer, &forged, pl), config.ErrInvalidTransfer, "forged ElGamal key")
+	forged = *succession
+	forged.Identity = nil
+	assert.ErrorIs(t, endorser.ApplySuccession(transfer, &forged, pl), config.ErrInvalidTransfer, "missing identity proof")
+
+	// the successor's modulus must come with a proof that it has no small factor, made for each verifier
+	assert.NotContains(t, succession.Fac, departing.ID)
+	forged = *succession
+	forged.Fac = withoutFac(succession.Fac, endorser.ID)
+	err = endorser.ApplySuccession(transfer, &forged, pl)
+	assert.ErrorIs(t, err, config.ErrInvalidTransfer)
+	assert.ErrorContains(t, err, "small factor")
+	forged.Fac[endorser.ID] = succession.Fac[ids[2]]
+	assert.ErrorContains(t, endorser.ApplySuccession(transfer, &forged, pl), "small factor", "proof made for another verifier")
+
+	// the succession of a transfer cannot be replayed for another session of the same successor
+	other, err := departing.TransferShare([]byte("other session"), identity.ActOnBase())
+	require.NoError(t, err)
+	assert.ErrorIs(t, endorser.ApplySuccession(other, succession, pl), config.ErrInvalidTransfer, "replayed succession")
+
+	require.NoError(t, endorser.ApplySuccession(transfer, succession, pl))
+	assert.True(t, endorser.Public[departing.ID].ElGamal.Equal(successor.ElGamal.ActOnBase()))
+	assert.Empty(t, endorser.Diff(successor))
+}
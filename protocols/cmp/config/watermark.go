@@ -0,0 +1,86 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+var (
+	ErrInvalidWatermarkSignature = errors.New("config: invalid watermark signature")
+	ErrTenantMismatch            = errors.New("config: share belongs to another tenant")
+)
+
+// Watermark is authenticated metadata attached to a serialized share,
+// binding it to the context it was created in.
+type Watermark struct {
+	// Tenant identifies the owner of the share.
+	Tenant string
+	// CreatedAt is the creation time of the share, in seconds since the Unix epoch.
+	CreatedAt int64
+	// ProtocolVersion is the version of the protocol which produced the share.
+	ProtocolVersion string
+	// PolicyHash is the hash of the signing policy associated with the key.
+	PolicyHash []byte
+}
+
+type watermarkedMarshal struct {
+	Watermark Watermark
+	Config    []byte
+	Signature []byte
+}
+
+// MarshalWatermarked serializes the config together with the given watermark,
+// signed with the identity key of the party owning the share.
+func (c *Config) MarshalWatermarked(w Watermark, key ed25519.PrivateKey) ([]byte, error) {
+	data, err := c.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	wm := &watermarkedMarshal{
+		Watermark: w,
+		Config:    data,
+	}
+	msg, err := wm.signedData()
+	if err != nil {
+		return nil, err
+	}
+	wm.Signature = ed25519.Sign(key, msg)
+	return cbor.Marshal(wm)
+}
+
+// UnmarshalWatermarked verifies the watermark of a share serialized with MarshalWatermarked
+// against the identity key `pub`, and checks that it was created for `tenant`.
+// The config is only decoded if both checks succeed, and the watermark is returned.
+//
+// As with UnmarshalBinary, the config must first be initialized using EmptyConfig.
+func (c *Config) UnmarshalWatermarked(data []byte, pub ed25519.PublicKey, tenant string) (*Watermark, error) {
+	wm := &watermarkedMarshal{}
+	if err := cbor.Unmarshal(data, wm); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	msg, err := wm.signedData()
+	if err != nil {
+		return nil, err
+	}
+	if len(pub) != ed25519.PublicKeySize || !ed25519.Verify(pub, msg, wm.Signature) {
+		return nil, ErrInvalidWatermarkSignature
+	}
+	if wm.Watermark.Tenant != tenant {
+		return nil, fmt.Errorf("%w: got %q, expected %q", ErrTenantMismatch, wm.Watermark.Tenant, tenant)
+	}
+	if err := c.UnmarshalBinary(wm.Config); err != nil {
+		return nil, err
+	}
+	return &wm.Watermark, nil
+}
+
+func (wm *watermarkedMarshal) signedData() ([]byte, error) {
+	return cbor.Marshal(struct {
+		Domain    string
+		Watermark Watermark
+		Config    []byte
+	}{"CMP Watermarked Config", wm.Watermark, wm.Config})
+}
@@ -0,0 +1,62 @@
+package config_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/lib/test"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatermark(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, ids := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+	c := configs[ids[0]]
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	w := config.Watermark{
+		Tenant:          "tenant",
+		CreatedAt:       1700000000,
+		ProtocolVersion: "cmp/1",
+		PolicyHash:      []byte{1, 2, 3},
+	}
+	data, err := c.MarshalWatermarked(w, key)
+	require.NoError(t, err)
+
+	restored := config.EmptyConfig(group)
+	watermark, err := restored.UnmarshalWatermarked(data, pub, "tenant")
+	require.NoError(t, err)
+	assert.Equal(t, w, *watermark)
+	assert.Equal(t, c.ID, restored.ID)
+	assert.True(t, c.ECDSA.Equal(restored.ECDSA))
+	assert.True(t, c.PublicPoint().Equal(restored.PublicPoint()))
+
+	_, err = config.EmptyConfig(group).UnmarshalWatermarked(data, pub, "other")
+	assert.ErrorIs(t, err, config.ErrTenantMismatch)
+
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, err = config.EmptyConfig(group).UnmarshalWatermarked(data, otherPub, "tenant")
+	assert.ErrorIs(t, err, config.ErrInvalidWatermarkSignature)
+	_, err = config.EmptyConfig(group).UnmarshalWatermarked(data, nil, "tenant")
+	assert.ErrorIs(t, err, config.ErrInvalidWatermarkSignature)
+
+	// moving the share to another tenant invalidates the signature
+	var decoded map[string]interface{}
+	require.NoError(t, cbor.Unmarshal(data, &decoded))
+	decoded["Watermark"].(map[interface{}]interface{})["Tenant"] = "other"
+	tampered, err := cbor.Marshal(decoded)
+	require.NoError(t, err)
+	_, err = config.EmptyConfig(group).UnmarshalWatermarked(tampered, pub, "other")
+	assert.ErrorIs(t, err, config.ErrInvalidWatermarkSignature)
+}
@@ -0,0 +1,262 @@
+package keygen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/cronokirby/saferith"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/math/arith"
+	pailliercore "github.com/mr-shifu/mpc-lib/core/paillier"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	pedersencore "github.com/mr-shifu/mpc-lib/core/pedersen"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	zkmod "github.com/mr-shifu/mpc-lib/core/zk/mod"
+	zkprm "github.com/mr-shifu/mpc-lib/core/zk/prm"
+	sw_hash "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/hash"
+	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/paillier"
+	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/pedersen"
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/pin"
+)
+
+var ErrInvalidAudit = errors.New("keygen: invalid audit")
+
+// EntropySource describes where the randomness of the Paillier primes of this party came from.
+type EntropySource struct {
+	// Name identifies the source, such as "crypto/rand" or the model of an HSM.
+	Name string `json:"name"`
+	// Attestation is evidence provided by the source, such as a signed quote of a TPM or HSM.
+	// It is opaque to this library, and checked by the auditor.
+	Attestation []byte `json:"attestation,omitempty"`
+}
+
+// AuxParameters are the public Paillier and Pedersen parameters of a party, with the proofs it sent for them.
+type AuxParameters struct {
+	// PaillierN is the Paillier modulus, and PedersenN, PedersenS, PedersenT the Pedersen parameters.
+	PaillierN []byte `json:"paillierN"`
+	PedersenN []byte `json:"pedersenN"`
+	PedersenS []byte `json:"pedersenS"`
+	PedersenT []byte `json:"pedersenT"`
+	// Fingerprint is the fingerprint of the parameters, as pinned by a pin.PinStore.
+	Fingerprint []byte `json:"fingerprint"`
+	// Mod is the CBOR encoded zkmod proof that PaillierN is a Blum integer with two prime factors,
+	// and Prm the CBOR encoded zkprm proof that PedersenS and PedersenT generate the same group.
+	Mod []byte `json:"mod"`
+	Prm []byte `json:"prm"`
+}
+
+// Audit records the provenance of the auxiliary parameters of a keygen, for review by external auditors.
+// It only holds public data: the parameters of each party and their proofs, which were verified by this party,
+// the time at which our own parameters were generated, and the source of their randomness.
+//
+// The proofs were produced against the session hash, whose transcript is recorded, so that Verify checks them again
+// without the messages of the session.
+type Audit struct {
+	KeyID string   `json:"keyId"`
+	SSID  []byte   `json:"ssid"`
+	Party party.ID `json:"party"`
+	// Transcript is the CBOR encoded transcript of the session hash in which the proofs were verified,
+	// as decoded by sw_hash.UnmarshalTranscript. The proofs of party j verify with the hash of its segment for j.
+	Transcript []byte `json:"transcript"`
+	// GenerationStarted and GenerationCompleted bound the generation of our Paillier and Pedersen parameters.
+	GenerationStarted   time.Time     `json:"generationStarted"`
+	GenerationCompleted time.Time     `json:"generationCompleted"`
+	Entropy             EntropySource `json:"entropy"`
+	// Parameters are the parameters of every party, including ours.
+	Parameters map[party.ID]*AuxParameters `json:"parameters"`
+}
+
+// Verify checks the proofs of the parameters of every party against the transcript of the audit.
+func (a *Audit) Verify(pl *pool.Pool) error {
+	transcript, err := sw_hash.UnmarshalTranscript(a.Transcript)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidAudit, err)
+	}
+	if len(a.Parameters) == 0 {
+		return fmt.Errorf("%w: no parameters", ErrInvalidAudit)
+	}
+	for j, params := range a.Parameters {
+		if err := params.verify(transcript.Segment(len(transcript), j), pl); err != nil {
+			return fmt.Errorf("%w: party %s: %w", ErrInvalidAudit, j, err)
+		}
+	}
+	return nil
+}
+
+// verify checks the proofs of the parameters with the hash of segment.
+func (p *AuxParameters) verify(segment *sw_hash.Segment, pl *pool.Pool) error {
+	paillierN, err := modulus(p.PaillierN)
+	if err != nil {
+		return err
+	}
+	pedersenN, err := modulus(p.PedersenN)
+	if err != nil {
+		return err
+	}
+	mod, prm := &zkmod.Proof{}, &zkprm.Proof{}
+	if err := cbor.Unmarshal(p.Mod, mod); err != nil {
+		return err
+	}
+	if err := cbor.Unmarshal(p.Prm, prm); err != nil {
+		return err
+	}
+	s, t := new(saferith.Nat).SetBytes(p.PedersenS), new(saferith.Nat).SetBytes(p.PedersenT)
+	parameters := pedersencore.New(arith.ModulusFromN(pedersenN), s, t)
+
+	h, err := segment.Hash()
+	if err != nil {
+		return err
+	}
+	if !paillier.NewPaillierKey(nil, pailliercore.NewPublicKey(paillierN)).VerifyZKMod(mod, h, pl) {
+		return errors.New("invalid mod proof")
+	}
+	if h, err = segment.Hash(); err != nil {
+		return err
+	}
+	if !pedersen.NewPedersenKey(nil, parameters).VerifyProof(h, pl, prm) {
+		return errors.New("invalid prm proof")
+	}
+	return nil
+}
+
+func modulus(data []byte) (*saferith.Modulus, error) {
+	n := new(saferith.Nat).SetBytes(data)
+	if n.EqZero() == 1 {
+		return nil, errors.New("modulus is zero")
+	}
+	return saferith.ModulusFromNat(n), nil
+}
+
+// ExportAudits writes the audits as JSON, one per line.
+func ExportAudits(w io.Writer, audits []*Audit) error {
+	enc := json.NewEncoder(w)
+	for _, a := range audits {
+		if err := enc.Encode(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WithAudit calls audit with the Audit of each keygen which completed, recording entropy as the source
+// of the randomness of our parameters. The audit can then be stored and exported with ExportAudits.
+func (m *MPCKeygen) WithAudit(entropy EntropySource, audit func(*Audit)) *MPCKeygen {
+	m.entropy = entropy
+	m.audit = audit
+	return m
+}
+
+// auditor collects the Audit of a session as its rounds are computed.
+type auditor struct {
+	emit func(*Audit)
+
+	mtx   sync.Mutex
+	audit *Audit
+}
+
+// newAuditor returns nil if no audit was requested.
+func (m *MPCKeygen) newAuditor(keyID string, ssid []byte, self party.ID) *auditor {
+	if m.audit == nil {
+		return nil
+	}
+	return &auditor{
+		emit: m.audit,
+		audit: &Audit{
+			KeyID:      keyID,
+			SSID:       ssid,
+			Party:      self,
+			Entropy:    m.entropy,
+			Parameters: map[party.ID]*AuxParameters{},
+		},
+	}
+}
+
+// generated records the time during which our parameters were generated.
+func (a *auditor) generated(started, completed time.Time) {
+	if a == nil {
+		return
+	}
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.audit.GenerationStarted, a.audit.GenerationCompleted = started, completed
+}
+
+// recordTranscript records the transcript of the session hash, in which the proofs of round 4 are verified.
+func (r *round3) recordTranscript() error {
+	if r.auditor == nil {
+		return nil
+	}
+	h, ok := r.Hash().(*sw_hash.Hash)
+	if !ok {
+		return fmt.Errorf("keygen: session hash %T has no transcript", r.Hash())
+	}
+	data, err := cbor.Marshal(h.Transcript())
+	if err != nil {
+		return err
+	}
+	r.auditor.mtx.Lock()
+	defer r.auditor.mtx.Unlock()
+	r.auditor.audit.Transcript = data
+	return nil
+}
+
+// recordAuxParameters adds the parameters of a party and their proofs, once verified.
+func (r *round1) recordAuxParameters(j party.ID, mod *zkmod.Proof, prm *zkprm.Proof) error {
+	if r.auditor == nil {
+		return nil
+	}
+	opts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(j))
+	paillierj, err := r.paillier_km.GetKey(opts)
+	if err != nil {
+		return err
+	}
+	pedersenj, err := r.pedersen_km.GetKey(opts)
+	if err != nil {
+		return err
+	}
+	modBytes, err := cbor.Marshal(mod)
+	if err != nil {
+		return err
+	}
+	prmBytes, err := cbor.Marshal(prm)
+	if err != nil {
+		return err
+	}
+	paillier, pedersen := paillierj.PublicKeyRaw(), pedersenj.PublicKeyRaw()
+	params := &AuxParameters{
+		PaillierN:   paillier.N().Bytes(),
+		PedersenN:   pedersen.N().Bytes(),
+		PedersenS:   pedersen.S().Bytes(),
+		PedersenT:   pedersen.T().Bytes(),
+		Fingerprint: pin.Fingerprint(paillier, pedersen),
+		Mod:         modBytes,
+		Prm:         prmBytes,
+	}
+
+	r.auditor.mtx.Lock()
+	defer r.auditor.mtx.Unlock()
+	r.auditor.audit.Parameters[j] = params
+	return nil
+}
+
+// done emits the audit once the keygen completed with the parameters of all parties.
+func (a *auditor) done(parties party.IDSlice) {
+	if a == nil {
+		return
+	}
+	a.mtx.Lock()
+	audit := a.audit
+	for _, j := range parties {
+		if _, ok := audit.Parameters[j]; !ok {
+			a.mtx.Unlock()
+			return
+		}
+	}
+	a.mtx.Unlock()
+	a.emit(audit)
+}
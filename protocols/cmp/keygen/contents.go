@@ -0,0 +1,31 @@
+package keygen
+
+import (
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	zksch "github.com/mr-shifu/mpc-lib/core/zk/sch"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+)
+
+func init() {
+	round.Register(round.Contents, round.ContentKey{Protocol: ProtocolID, Round: 2, Broadcast: true}, newBroadcast2)
+	round.Register(round.Contents, round.ContentKey{Protocol: ProtocolID, Round: 3, Broadcast: true}, newBroadcast3)
+	round.Register(round.Contents, round.ContentKey{Protocol: ProtocolID, Round: 4}, newMessage4)
+	round.Register(round.Contents, round.ContentKey{Protocol: ProtocolID, Round: 4, Broadcast: true}, newBroadcast4)
+	round.Register(round.Contents, round.ContentKey{Protocol: ProtocolID, Round: 5, Broadcast: true}, newBroadcast5)
+}
+
+func newBroadcast2(curve.Curve, round.Version) *broadcast2 { return &broadcast2{} }
+
+func newBroadcast3(group curve.Curve, _ round.Version) *broadcast3 {
+	return &broadcast3{SchnorrCommitments: group.NewPoint()}
+}
+
+func newMessage4(curve.Curve, round.Version) *message4 { return &message4{} }
+
+func newBroadcast4(group curve.Curve, _ round.Version) *broadcast4 {
+	return &broadcast4{ElGamal: zksch.EmptyProof(group)}
+}
+
+func newBroadcast5(group curve.Curve, _ round.Version) *broadcast5 {
+	return &broadcast5{SchnorrResponse: group.NewScalar()}
+}
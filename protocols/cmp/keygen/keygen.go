@@ -1,6 +1,7 @@
 package keygen
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/mr-shifu/mpc-lib/core/pool"
@@ -17,11 +18,24 @@ import (
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	mpc_config "github.com/mr-shifu/mpc-lib/pkg/mpc/common/config"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/message"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/pin"
 	mpc_state "github.com/mr-shifu/mpc-lib/pkg/mpc/common/state"
 )
 
 const Rounds round.Number = 5
 
+// ProtocolID identifies the keygen protocol in messages and SSIDs.
+const ProtocolID = "cmp/keygen"
+
+// Version is the current version of the keygen protocol.
+// Version 2 requires the proof of possession of the ElGamal key in the round 4 broadcast, which version 1
+// parties do not send: since the version is part of the SSID, the two cannot run a keygen together.
+const Version round.Version = 2
+
+// ErrUnexpectedPublicKey is returned when the public key resulting from keygen differs from
+// the ExpectedPublicKey set in the config.
+var ErrUnexpectedPublicKey = errors.New("keygen: public key differs from the expected public key")
+
 type MPCKeygen struct {
 	configmgr   mpc_config.KeyConfigManager
 	statemgr    mpc_state.MPCStateManager
@@ -37,6 +51,10 @@ type MPCKeygen struct {
 	chainKey_km rid.RIDManager
 	hash_mgr    hash.HashManager
 	commit_mgr  commitment.CommitmentManager
+	pins        pin.PinStore
+	progress    func(Progress)
+	entropy     EntropySource
+	audit       func(*Audit)
 }
 
 func NewMPCKeygen(
@@ -74,20 +92,28 @@ func NewMPCKeygen(
 	}
 }
 
+// WithPinStore pins the Paillier and Pedersen parameters received from each party,
+// so that a later keygen for the same key with different parameters is refused.
+func (m *MPCKeygen) WithPinStore(pins pin.PinStore) *MPCKeygen {
+	m.pins = pins
+	return m
+}
+
 func (m *MPCKeygen) Start(cfg mpc_config.KeyConfig, pl *pool.Pool) protocol.StartFunc {
 	return func(sessionID []byte) (_ round.Session, err error) {
 		info := round.Info{
-			ProtocolID:       "cmp/keygen",
+			ProtocolID:       ProtocolID,
 			SelfID:           cfg.SelfID(),
 			PartyIDs:         cfg.PartyIDs(),
 			Threshold:        cfg.Threshold(),
 			Group:            cfg.Group(),
 			FinalRoundNumber: Rounds,
+			Version:          Version,
+			Profile:          cfg.Profile(),
 		}
 
 		// m.keys[keyID] = info
-		opts := keyopts.Options{}
-		opts.Set("id", cfg.ID(), "partyid", string(info.SelfID))
+		opts := keyopts.New().WithKeyID(cfg.ID()).WithPartyID(string(info.SelfID))
 		h := m.hash_mgr.NewHasher(cfg.ID(), opts)
 
 		helper, err := round.NewSession(cfg.ID(), info, sessionID, pl, h)
@@ -126,6 +152,11 @@ func (m *MPCKeygen) Start(cfg mpc_config.KeyConfig, pl *pool.Pool) protocol.Star
 			rid_km:      m.rid_km,
 			chainKey_km: m.chainKey_km,
 			commit_mgr:  m.commit_mgr,
+			pins:        m.pins,
+			progress:    m.progress,
+			auditor:     m.newAuditor(cfg.ID(), helper.SSID(), info.SelfID),
+
+			ExpectedPublicKey: cfg.ExpectedPublicKey(),
 		}, nil
 
 	}
@@ -1,12 +1,14 @@
 package keygen
 
 import (
+	"crypto/rand"
 	"fmt"
 	"testing"
 
 	"github.com/fxamacker/cbor/v2"
 	"github.com/google/uuid"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
 	"github.com/mr-shifu/mpc-lib/core/pool"
 	"github.com/mr-shifu/mpc-lib/lib/round"
 	"github.com/mr-shifu/mpc-lib/lib/test"
@@ -180,5 +182,58 @@ func TestKeygen(t *testing.T) {
 			break
 		}
 	}
-	// checkOutput(t, rounds)
+	checkOutput(t, rounds)
+}
+
+func TestKeygenExpectedPublicKey(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	N := 3
+	partyIDs := test.PartyIDs(N)
+
+	// run returns the final rounds of a keygen, calling beforeLast with the rounds before the last one.
+	run := func(expected curve.Point, beforeLast func(rounds []round.Session)) []round.Session {
+		keyID := uuid.NewString()
+		rounds := make([]round.Session, 0, N)
+		for _, partyID := range partyIDs {
+			cfg := mpc_config.NewKeyConfig(keyID, group, N-1, partyID, partyIDs).SetExpectedPublicKey(expected)
+			r, err := newMPCKeygen().Start(cfg, pl)(nil)
+			require.NoError(t, err)
+			rounds = append(rounds, r)
+		}
+		for {
+			if _, ok := rounds[0].(*round4); ok {
+				beforeLast(rounds)
+			}
+			err, done := test.Rounds(rounds, nil)
+			require.NoError(t, err)
+			if done {
+				return rounds
+			}
+		}
+	}
+
+	// the public key is the sum of the constant exponents of all parties, known once they were all received
+	rounds := run(nil, func(rounds []round.Session) {
+		r := rounds[0].(*round4)
+		expected := group.NewPoint()
+		for _, j := range partyIDs {
+			vssKey, err := r.vss_mgr.GetSecrets(keyopts.New().WithKeyID(r.ID).WithPartyID(string(j)))
+			require.NoError(t, err)
+			exp, err := vssKey.ExponentsRaw()
+			require.NoError(t, err)
+			expected = expected.Add(exp.Constant())
+		}
+		for _, r := range rounds {
+			r.(*round4).ExpectedPublicKey = expected
+		}
+	})
+	checkOutput(t, rounds)
+
+	rounds = run(sample.Scalar(rand.Reader, group).ActOnBase(), func([]round.Session) {})
+	for _, r := range rounds {
+		require.IsType(t, &round.Abort{}, r)
+		require.ErrorIs(t, r.(*round.Abort).Err, ErrUnexpectedPublicKey)
+	}
 }
@@ -0,0 +1,69 @@
+package keygen
+
+import (
+	"errors"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+)
+
+// proveAuxiliaryKeys returns the proofs of possession of our auxiliary keys, which are broadcast in round 4:
+//
+// - zkmod for the Paillier modulus
+// - zkprm for the Pedersen parameters
+// - Schnorr for the ElGamal key.
+//
+// Every auxiliary key imported in round 3 must be covered here, so that a party cannot publish
+// a key derived from the keys of others without knowing its secret.
+func (r *round3) proveAuxiliaryKeys(h hash.Hash) (*broadcast4, error) {
+	opts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(r.SelfID()))
+
+	pk, err := r.paillier_km.GetKey(opts)
+	if err != nil {
+		return nil, err
+	}
+	prm, err := r.pedersen_km.NewProof(h.Clone(), r.Pool, opts)
+	if err != nil {
+		return nil, err
+	}
+	elgamal, err := r.elgamal_km.GetKey(opts)
+	if err != nil {
+		return nil, err
+	}
+	sch, err := elgamal.NewSchnorrProof(h.Clone())
+	if err != nil {
+		return nil, err
+	}
+	return &broadcast4{
+		Mod:     pk.NewZKModProof(h.Clone(), r.Pool),
+		Prm:     prm,
+		ElGamal: sch,
+	}, nil
+}
+
+// verifyAuxiliaryKeys verifies the proofs of possession of the auxiliary keys of from.
+func (r *round4) verifyAuxiliaryKeys(from party.ID, body *broadcast4) error {
+	fromOpts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(from))
+
+	paillier, err := r.paillier_km.GetKey(fromOpts)
+	if err != nil {
+		return err
+	}
+	if !paillier.VerifyZKMod(body.Mod, r.HashForID(from), r.Pool) {
+		return errors.New("failed to validate mod proof")
+	}
+
+	if !r.pedersen_km.VerifyProof(r.HashForID(from), r.Pool, body.Prm, fromOpts) {
+		return errors.New("failed to validate prm proof")
+	}
+
+	elgamal, err := r.elgamal_km.GetKey(fromOpts)
+	if err != nil {
+		return err
+	}
+	if !elgamal.VerifySchnorrProof(r.HashForID(from), body.ElGamal) {
+		return errors.New("failed to validate elgamal proof")
+	}
+	return nil
+}
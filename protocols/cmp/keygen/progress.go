@@ -0,0 +1,56 @@
+package keygen
+
+import (
+	pailliercore "github.com/mr-shifu/mpc-lib/core/paillier"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+)
+
+// Phase is the step of the keygen being computed by this party.
+type Phase string
+
+const (
+	// PhasePaillier is the search for the safe primes of the Paillier key, which takes the longest.
+	PhasePaillier Phase = "paillier"
+	// PhaseCommit samples the remaining secrets of round 1 and commits to them.
+	PhaseCommit Phase = "commit"
+	// PhaseDecommit reveals the values committed to in round 1.
+	PhaseDecommit Phase = "decommit"
+	// PhaseProve proves the Paillier and Pedersen parameters, and sends the encrypted shares.
+	PhaseProve Phase = "prove"
+	// PhaseCombine verifies the proofs and shares received, and computes the new shares.
+	PhaseCombine Phase = "combine"
+	// PhaseVerify verifies the final Schnorr proofs of the other parties.
+	PhaseVerify Phase = "verify"
+)
+
+// Progress describes the progress of a keygen.
+type Progress struct {
+	Round round.Number `json:"round"`
+	Phase Phase        `json:"phase"`
+	// Paillier is set during PhasePaillier.
+	Paillier *pailliercore.Progress `json:"paillier,omitempty"`
+}
+
+// WithProgress calls progress whenever the keygen enters a new phase,
+// and after each attempt at finding a prime of the Paillier key.
+func (m *MPCKeygen) WithProgress(progress func(Progress)) *MPCKeygen {
+	m.progress = progress
+	return m
+}
+
+// report calls the progress callback of the keygen, if any.
+func (r *round1) report(number round.Number, phase Phase) {
+	if r.progress != nil {
+		r.progress(Progress{Round: number, Phase: phase})
+	}
+}
+
+// reportPaillier returns a callback reporting the search for the Paillier primes, or nil.
+func (r *round1) reportPaillier() func(pailliercore.Progress) {
+	if r.progress == nil {
+		return nil
+	}
+	return func(p pailliercore.Progress) {
+		r.progress(Progress{Round: 1, Phase: PhasePaillier, Paillier: &p})
+	}
+}
@@ -3,6 +3,7 @@ package keygen
 import (
 	"encoding/hex"
 	"errors"
+	"time"
 
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/party"
@@ -18,6 +19,7 @@ import (
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/vss"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/message"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/pin"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/state"
 )
 
@@ -38,6 +40,9 @@ type round1 struct {
 	rid_km      rid.RIDManager
 	chainKey_km rid.RIDManager
 	commit_mgr  commitment.CommitmentManager
+	pins        pin.PinStore
+	progress    func(Progress)
+	auditor     *auditor
 
 	// PreviousSecretECDSA = sk'ᵢ
 	// Contains the previous secret ECDSA key share which is being refreshed
@@ -54,6 +59,9 @@ type round1 struct {
 	//
 	// In that case, we will simply use the previous chain key at the very end.
 	PreviousChainKey types.RID
+
+	// ExpectedPublicKey is the public key this keygen must produce, if set.
+	ExpectedPublicKey curve.Point
 }
 
 // VerifyMessage implements round.Round.
@@ -77,12 +85,15 @@ func (r *round1) StoreMessage(round.Message) error { return nil }
 // - commit to message.
 func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// generate Paillier and Pedersen
-	opts := keyopts.Options{}
-	opts.Set("id", r.ID, "partyid", string(r.SelfID()))
-	paillierKey, err := r.paillier_km.GenerateKey(opts)
+	opts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(r.SelfID()))
+	r.report(1, PhasePaillier)
+	started := time.Now()
+	paillierKey, err := r.paillier_km.GenerateKeyWithProgress(opts, r.reportPaillier())
 	if err != nil {
 		return nil, err
 	}
+	r.auditor.generated(started, time.Now())
+	r.report(1, PhaseCommit)
 
 	// derive Pedersen from Paillier
 	pedersenKey, err := paillierKey.DerivePedersenKey()
@@ -116,8 +127,7 @@ func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 	}
 	sharePublic := share.ActOnBase()
 	shareKey := r.ecdsa_km.NewKey(share, sharePublic, r.Group())
-	vssOpts := keyopts.Options{}
-	vssOpts.Set("id", hex.EncodeToString(vssKey.SKI()), "partyid", string(r.SelfID()))
+	vssOpts := keyopts.New().WithKeyID(hex.EncodeToString(vssKey.SKI())).WithPartyID(string(r.SelfID()))
 	if _, err := r.ec_vss_km.ImportKey(shareKey, vssOpts); err != nil {
 		return nil, err
 	}
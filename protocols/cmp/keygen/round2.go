@@ -21,16 +21,15 @@ type broadcast2 struct {
 // StoreBroadcastMessage implements round.BroadcastRound.
 // - save commitment Vⱼ.
 func (r *round2) StoreBroadcastMessage(msg round.Message) error {
-	body, ok := msg.Content.(*broadcast2)
-	if !ok || body == nil {
-		return round.ErrInvalidContent
+	body, err := round.ContentAs[*broadcast2](msg)
+	if err != nil {
+		return err
 	}
 	if err := body.Commitment.Validate(); err != nil {
 		return err
 	}
 
-	fromOpts := keyopts.Options{}
-	fromOpts.Set("id", r.ID, "partyid", string(msg.From))
+	fromOpts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(msg.From))
 
 	cmt := r.commit_mgr.NewCommitment(body.Commitment, nil)
 	if err := r.commit_mgr.Import(cmt, fromOpts); err != nil {
@@ -57,13 +56,14 @@ func (round2) StoreMessage(round.Message) error { return nil }
 //
 // - send all committed data.
 func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
+	r.report(r.Number(), PhaseDecommit)
+
 	// Verify if all parties commitments are received
 	if !r.CanFinalize() {
 		return nil, round.ErrNotEnoughMessages
 	}
 
-	opts := keyopts.Options{}
-	opts.Set("id", r.ID, "partyid", string(r.SelfID()))
+	opts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(r.SelfID()))
 
 	// TODO need keyID to get the key
 	elgamalKey, err := r.elgamal_km.GetKey(opts)
@@ -184,7 +184,9 @@ func (round2) MessageContent() round.Content { return nil }
 func (broadcast2) RoundNumber() round.Number { return 2 }
 
 // BroadcastContent implements round.BroadcastRound.
-func (round2) BroadcastContent() round.BroadcastContent { return &broadcast2{} }
+func (r *round2) BroadcastContent() round.BroadcastContent {
+	return r.NewBroadcastContent(r.Number())
+}
 
 // Number implements round.Round.
 func (round2) Number() round.Number { return 2 }
@@ -11,6 +11,7 @@ import (
 	"github.com/mr-shifu/mpc-lib/lib/types"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/vss"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/pin"
 )
 
 var _ round.Round = (*round3)(nil)
@@ -56,9 +57,9 @@ type broadcast3 struct {
 // - store ridⱼ, Cⱼ, Nⱼ, Sⱼ, Tⱼ, Fⱼ(X), Aⱼ.
 func (r *round3) StoreBroadcastMessage(msg round.Message) error {
 	from := msg.From
-	body, ok := msg.Content.(*broadcast3)
-	if !ok || body == nil {
-		return round.ErrInvalidContent
+	body, err := round.ContentAs[*broadcast3](msg)
+	if err != nil {
+		return err
 	}
 
 	// TODO verify vss polynomial
@@ -88,8 +89,7 @@ func (r *round3) StoreBroadcastMessage(msg round.Message) error {
 	// 	return errors.New("vss polynomial has incorrect degree")
 	// }
 
-	fromOpts := keyopts.Options{}
-	fromOpts.Set("id", r.ID, "partyid", string(from))
+	fromOpts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(from))
 
 	ridFrom, err := r.rid_km.ImportKey(body.RID, fromOpts)
 	if err != nil {
@@ -101,7 +101,8 @@ func (r *round3) StoreBroadcastMessage(msg round.Message) error {
 		return err
 	}
 
-	if _, err := r.paillier_km.ImportKey(body.PaillierKey, fromOpts); err != nil {
+	paillierFrom, err := r.paillier_km.ImportKey(body.PaillierKey, fromOpts)
+	if err != nil {
 		return err
 	}
 
@@ -110,6 +111,13 @@ func (r *round3) StoreBroadcastMessage(msg round.Message) error {
 		return err
 	}
 
+	if r.pins != nil {
+		fp := pin.Fingerprint(paillierFrom.PublicKeyRaw(), pedersenFrom.PublicKeyRaw())
+		if err := r.pins.Pin(r.ID, from, fp); err != nil {
+			return err
+		}
+	}
+
 	fromKey, err := r.ecdsa_km.ImportKey(body.EcdsaKey, fromOpts)
 	if err != nil {
 		return err
@@ -190,29 +198,29 @@ func (round3) StoreMessage(round.Message) error { return nil }
 // - set rid = ⊕ⱼ ridⱼ and update hash state
 // - prove Nᵢ is Blum
 // - prove Pedersen parameters
+// - prove possession of the ElGamal key
 // - prove Schnorr for all coefficients of fᵢ(X)
 //   - if refresh skip constant coefficient
 //
 // - send proofs and encryption of share for Pⱼ.
 func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
+	r.report(r.Number(), PhaseProve)
+
 	// Verify if all parties messages are received
 	if !r.CanFinalize() {
 		return nil, round.ErrNotEnoughMessages
 	}
 
-	opts := keyopts.Options{}
-	opts.Set("id", r.ID, "partyid", string(r.SelfID()))
+	opts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(r.SelfID()))
 
-	rootOpts := keyopts.Options{}
-	rootOpts.Set("id", r.ID, "partyid", "ROOT")
+	rootOpts := keyopts.New().WithKeyID(r.ID).WithPartyID("ROOT")
 
 	// c = ⊕ⱼ cⱼ
 	chainKey := r.PreviousChainKey
 	if chainKey == nil {
 		chainKey = types.EmptyRID()
 		for _, j := range r.PartyIDs() {
-			partyOpts := keyopts.Options{}
-			partyOpts.Set("id", r.ID, "partyid", string(j))
+			partyOpts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(j))
 			ck, err := r.chainKey_km.GetKey(partyOpts)
 			if err != nil {
 				return nil, err
@@ -227,8 +235,7 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// RID = ⊕ⱼ RIDⱼ
 	rid := types.EmptyRID()
 	for _, j := range r.PartyIDs() {
-		partyOpts := keyopts.Options{}
-		partyOpts.Set("id", r.ID, "partyid", string(j))
+		partyOpts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(j))
 		rj, err := r.rid_km.GetKey(partyOpts)
 		if err != nil {
 			return nil, err
@@ -243,27 +250,23 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 	h := r.Hash().Clone()
 	_ = h.WriteAny(rid, r.SelfID())
 
-	// Prove N is a blum prime with zkmod
-	pk, err := r.paillier_km.GetKey(opts)
+	// prove possession of the Paillier, Pedersen and ElGamal keys
+	proofs, err := r.proveAuxiliaryKeys(h)
 	if err != nil {
 		return nil, err
 	}
-	mod := pk.NewZKModProof(h.Clone(), r.Pool)
-
-	// prove s, t are correct as aux parameters with zkprm
-	ped, err := r.pedersen_km.GetKey(opts)
-	if err != nil {
+	if err := r.recordAuxParameters(r.SelfID(), proofs.Mod, proofs.Prm); err != nil {
 		return nil, err
 	}
-	prm := ped.NewProof(h.Clone(), r.Pool)
-
-	if err := r.BroadcastMessage(out, &broadcast4{
-		Mod: mod,
-		Prm: prm,
-	}); err != nil {
+	if err := r.BroadcastMessage(out, proofs); err != nil {
 		return r, err
 	}
 
+	pk, err := r.paillier_km.GetKey(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	vssKey, err := r.vss_mgr.GetSecrets(opts)
 	if err != nil {
 		return nil, err
@@ -271,8 +274,7 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 
 	// create P2P messages with encrypted shares and zkfac proof
 	for _, j := range r.OtherPartyIDs() {
-		partyOpts := keyopts.Options{}
-		partyOpts.Set("id", r.ID, "partyid", string(j))
+		partyOpts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(j))
 
 		pedj, err := r.pedersen_km.GetKey(partyOpts)
 		if err != nil {
@@ -284,8 +286,9 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 		}
 
 		fac := pk.NewZKFACProof(h.Clone(), zkfac.Public{
-			N:   pk.PublicKey().ParamN(),
-			Aux: pedj.PublicKeyRaw(),
+			N:       pk.PublicKey().ParamN(),
+			Aux:     pedj.PublicKeyRaw(),
+			Profile: r.Profile(),
 		})
 
 		// compute fᵢ(j)
@@ -312,6 +315,9 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 
 	// Write rid to the hash state
 	r.UpdateHashState(rid)
+	if err := r.recordTranscript(); err != nil {
+		return r, err
+	}
 	return &round4{
 		round3: r,
 	}, nil
@@ -338,11 +344,7 @@ func (broadcast3) RoundNumber() round.Number { return 3 }
 
 // BroadcastContent implements round.BroadcastRound.
 func (r *round3) BroadcastContent() round.BroadcastContent {
-	return &broadcast3{
-		// VSSPolynomial:      polynomial.EmptyExponent(r.Group()),
-		SchnorrCommitments: r.Group().NewPoint(), //zksch.EmptyCommitment(r.Group()),
-		// ElGamalPublic:      r.Group().NewPoint(),
-	}
+	return r.NewBroadcastContent(r.Number())
 }
 
 // Number implements round.Round.
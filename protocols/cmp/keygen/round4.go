@@ -10,6 +10,7 @@ import (
 	zkfac "github.com/mr-shifu/mpc-lib/core/zk/fac"
 	zkmod "github.com/mr-shifu/mpc-lib/core/zk/mod"
 	zkprm "github.com/mr-shifu/mpc-lib/core/zk/prm"
+	zksch "github.com/mr-shifu/mpc-lib/core/zk/sch"
 	"github.com/mr-shifu/mpc-lib/lib/round"
 	comm_ecdsa "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/ecdsa"
 	comm_keyopts "github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
@@ -34,37 +35,26 @@ type broadcast4 struct {
 	round.NormalBroadcastContent
 	Mod *zkmod.Proof
 	Prm *zkprm.Proof
+	// ElGamal is a proof of possession of the ElGamal secret key
+	ElGamal *zksch.Proof
 }
 
 // StoreBroadcastMessage implements round.BroadcastRound.
 //
 // - verify Mod, Prm proof for N
+// - verify Schnorr proof for the ElGamal key
 func (r *round4) StoreBroadcastMessage(msg round.Message) error {
 	from := msg.From
-	body, ok := msg.Content.(*broadcast4)
-	if !ok || body == nil {
-		return round.ErrInvalidContent
-	}
-
-	fromOpts := keyopts.Options{}
-	fromOpts.Set("id", r.ID, "partyid", string(from))
-
-	// verify zkmod
-	ped, err := r.pedersen_km.GetKey(fromOpts)
+	body, err := round.ContentAs[*broadcast4](msg)
 	if err != nil {
 		return err
 	}
-	paillier, err := r.paillier_km.GetKey(fromOpts)
-	if err != nil {
+
+	if err := r.verifyAuxiliaryKeys(from, body); err != nil {
 		return err
 	}
-	if !paillier.VerifyZKMod(body.Mod, r.HashForID(from), r.Pool) {
-		return errors.New("failed to validate mod proof")
-	}
-
-	// verify zkprm
-	if !ped.VerifyProof(r.HashForID(from), r.Pool, body.Prm) {
-		return errors.New("failed to validate prm proof")
+	if err := r.recordAuxParameters(from, body.Mod, body.Prm); err != nil {
+		return err
 	}
 
 	// Mark the message as received
@@ -82,16 +72,14 @@ func (r *round4) StoreBroadcastMessage(msg round.Message) error {
 // - verify validity of share ciphertext.
 func (r *round4) VerifyMessage(msg round.Message) error {
 	from := msg.From
-	body, ok := msg.Content.(*message4)
-	if !ok || body == nil {
-		return round.ErrInvalidContent
+	body, err := round.ContentAs[*message4](msg)
+	if err != nil {
+		return err
 	}
 
-	selfOpts := keyopts.Options{}
-	selfOpts.Set("id", r.ID, "partyid", string(r.SelfID()))
+	selfOpts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(r.SelfID()))
 
-	fromOpts := keyopts.Options{}
-	fromOpts.Set("id", r.ID, "partyid", string(from))
+	fromOpts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(from))
 
 	paillierKey, err := r.paillier_km.GetKey(selfOpts)
 	if err != nil {
@@ -112,8 +100,9 @@ func (r *round4) VerifyMessage(msg round.Message) error {
 		return err
 	}
 	if !paillierKey.VerifyZKFAC(body.Fac, zkfac.Public{
-		N:   paillierj.PublicKey().ParamN(),
-		Aux: ped.PublicKeyRaw(),
+		N:       paillierj.PublicKey().ParamN(),
+		Aux:     ped.PublicKeyRaw(),
+		Profile: r.Profile(),
 	}, r.HashForID(from)) {
 		return errors.New("failed to validate fac proof")
 	}
@@ -128,13 +117,15 @@ func (r *round4) VerifyMessage(msg round.Message) error {
 // - check VSS condition.
 // - save share.
 func (r *round4) StoreMessage(msg round.Message) error {
-	from, body := msg.From, msg.Content.(*message4)
+	from := msg.From
+	body, err := round.ContentAs[*message4](msg)
+	if err != nil {
+		return err
+	}
 
-	selfOpts := keyopts.Options{}
-	selfOpts.Set("id", r.ID, "partyid", string(r.SelfID()))
+	selfOpts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(r.SelfID()))
 
-	fromOpts := keyopts.Options{}
-	fromOpts.Set("id", r.ID, "partyid", string(from))
+	fromOpts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(from))
 
 	// decrypt share
 	paillierKey, err := r.paillier_km.GetKey(selfOpts)
@@ -169,8 +160,7 @@ func (r *round4) StoreMessage(msg round.Message) error {
 		return errors.New("failed to validate VSS share")
 	}
 
-	vssShareOpts := keyopts.Options{}
-	vssShareOpts.Set("id", hex.EncodeToString(vssKey.SKI()), "partyid", string(r.SelfID()))
+	vssShareOpts := keyopts.New().WithKeyID(hex.EncodeToString(vssKey.SKI())).WithPartyID(string(r.SelfID()))
 	vssShareKey := sw_ecdsa.NewECDSAKey(Share, PublicShare, r.Group())
 	if _, err := r.ec_vss_km.ImportKey(vssShareKey, vssShareOpts); err != nil {
 		return err
@@ -195,19 +185,19 @@ func (r *round4) StoreMessage(msg round.Message) error {
 // - write new ssid hash to old hash state
 // - create proof of knowledge of secret.
 func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
+	r.report(r.Number(), PhaseCombine)
+
 	// check if we received all messages
 	if !r.CanFinalize() {
 		return nil, round.ErrNotEnoughMessages
 	}
 
-	opts := keyopts.Options{}
-	opts.Set("id", r.ID, "partyid", string(r.SelfID()))
+	opts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(r.SelfID()))
 
 	// Calculate MPC public Key
 	mpcPublicKey := r.Group().NewPoint()
 	for _, partyID := range r.PartyIDs() {
-		partyOpts := keyopts.Options{}
-		partyOpts.Set("id", r.ID, "partyid", string(partyID))
+		partyOpts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(partyID))
 
 		vssKey, err := r.vss_mgr.GetSecrets(partyOpts)
 		if err != nil {
@@ -221,9 +211,17 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 		mpcPublicKey = mpcPublicKey.Add(pub)
 	}
 
+	// abort if the public key differs from the one we expected
+	if r.ExpectedPublicKey != nil && !r.ExpectedPublicKey.Equal(mpcPublicKey) {
+		// update state to Aborted in StateManager
+		if err := r.statemanger.SetAborted(r.ID); err != nil {
+			return r, err
+		}
+		return r.AbortRound(ErrUnexpectedPublicKey), nil
+	}
+
 	// Import MPC public Key
-	rootOpts := keyopts.Options{}
-	rootOpts.Set("id", r.ID, "partyid", "ROOT")
+	rootOpts := keyopts.New().WithKeyID(r.ID).WithPartyID("ROOT")
 	k := r.ecdsa_km.NewKey(nil, mpcPublicKey, r.Group())
 	if _, err := r.ecdsa_km.ImportKey(k, rootOpts); err != nil {
 		return nil, err
@@ -233,8 +231,7 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// var allExponents []*polynomial.Exponent
 	vssOptsList := make([]comm_keyopts.Options, 0)
 	for _, partyID := range r.PartyIDs() {
-		partyOpts := keyopts.Options{}
-		partyOpts.Set("id", r.ID, "partyid", string(partyID))
+		partyOpts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(partyID))
 		vssOptsList = append(vssOptsList, partyOpts)
 	}
 	rootVss, err := r.vss_mgr.SumExponents(vssOptsList...)
@@ -251,9 +248,7 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 		return nil, err
 	}
 	for _, j := range r.PartyIDs() {
-		vssPartyOpts := keyopts.Options{}
-
-		vssPartyOpts.Set("id", hex.EncodeToString(vssPoly.SKI()), "partyid", string(j))
+		vssPartyOpts := keyopts.New().WithKeyID(hex.EncodeToString(vssPoly.SKI())).WithPartyID(string(j))
 
 		vssPub, err := vssPoly.EvaluateByExponents(j.Scalar(r.Group()))
 		if err != nil {
@@ -268,16 +263,14 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// Sum all VSS shares to generate MPC VSS Share
 	var vss_shares []comm_ecdsa.ECDSAKey
 	for _, j := range r.OtherPartyIDs() {
-		partyOpts := keyopts.Options{}
-		partyOpts.Set("id", r.ID, "partyid", string(j))
+		partyOpts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(j))
 
 		vss, err := r.vss_mgr.GetSecrets(partyOpts)
 		if err != nil {
 			return nil, err
 		}
 
-		vssOpts := keyopts.Options{}
-		vssOpts.Set("id", hex.EncodeToString(vss.SKI()), "partyid", string(r.SelfID()))
+		vssOpts := keyopts.New().WithKeyID(hex.EncodeToString(vss.SKI())).WithPartyID(string(r.SelfID()))
 		vss_share, err := r.ec_vss_km.GetKey(vssOpts)
 		if err != nil {
 			return nil, err
@@ -288,8 +281,7 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 	if err != nil {
 		return nil, err
 	}
-	vssOpts := keyopts.Options{}
-	vssOpts.Set("id", hex.EncodeToString(vss.SKI()), "partyid", string(r.SelfID()))
+	vssOpts := keyopts.New().WithKeyID(hex.EncodeToString(vss.SKI())).WithPartyID(string(r.SelfID()))
 	selfVSSShare, err := r.ec_vss_km.GetKey(vssOpts)
 	if err != nil {
 		return nil, err
@@ -297,8 +289,7 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 	vssSharePrivateKey := selfVSSShare.AddKeys(vss_shares...)
 	vssSharePublicKey := vssSharePrivateKey.ActOnBase()
 	vssShareKey := sw_ecdsa.NewECDSAKey(vssSharePrivateKey, vssSharePublicKey, r.Group())
-	rootVssOpts := keyopts.Options{}
-	rootVssOpts.Set("id", hex.EncodeToString(rootVss.SKI()), "partyid", "ROOT")
+	rootVssOpts := keyopts.New().WithKeyID(hex.EncodeToString(rootVss.SKI())).WithPartyID("ROOT")
 	if _, err := r.ec_vss_km.ImportKey(vssShareKey, rootVssOpts); err != nil {
 		return nil, err
 	}
@@ -313,9 +304,10 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 		return nil, err
 	}
 	PublicData := make(map[party.ID]*config.Public, len(r.PartyIDs()))
+	var elgamalSecret curve.Scalar
+	var paillierSecret *paillier.SecretKey
 	for _, j := range r.PartyIDs() {
-		partyOpts := keyopts.Options{}
-		partyOpts.Set("id", r.ID, "partyid", string(j))
+		partyOpts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(j))
 
 		elgamalj, err := r.elgamal_km.GetKey(partyOpts)
 		if err != nil {
@@ -336,6 +328,11 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 			return r, err
 		}
 
+		if j == r.SelfID() {
+			elgamalSecret = elgamalj.SecretKeyRaw()
+			paillierSecret = paillierj.SecretKeyRaw()
+		}
+
 		PublicData[j] = &config.Public{
 			ECDSA:    PublicECDSAShare,
 			ElGamal:  elgamalj.PublicKeyRaw(),
@@ -358,17 +355,16 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 		return nil, err
 	}
 
-	// TODO elgamal and paillier secret key is missed here
 	UpdatedConfig := &config.Config{
 		Group:     r.Group(),
 		ID:        r.SelfID(),
 		Threshold: r.Threshold(),
 		ECDSA:     vssSharePrivateKey,
-		// ElGamal:   r.ElGamalSecret,
-		// Paillier:  r.PaillierSecret,
-		RID:      rid.Raw(),
-		ChainKey: chainKey.Raw(),
-		Public:   PublicData,
+		ElGamal:   elgamalSecret,
+		Paillier:  paillierSecret,
+		RID:       rid.Raw(),
+		ChainKey:  chainKey.Raw(),
+		Public:    PublicData,
 	}
 
 	// write new ssid to hash, to bind the Schnorr proof to this new config
@@ -426,13 +422,15 @@ func (r *round4) CanFinalize() bool {
 func (message4) RoundNumber() round.Number { return 4 }
 
 // MessageContent implements round.Round.
-func (round4) MessageContent() round.Content { return &message4{} }
+func (r *round4) MessageContent() round.Content { return r.NewMessageContent(r.Number()) }
 
 // RoundNumber implements round.Content.
 func (broadcast4) RoundNumber() round.Number { return 4 }
 
 // BroadcastContent implements round.BroadcastRound.
-func (round4) BroadcastContent() round.BroadcastContent { return &broadcast4{} }
+func (r *round4) BroadcastContent() round.BroadcastContent {
+	return r.NewBroadcastContent(r.Number())
+}
 
 // Number implements round.Round.
 func (round4) Number() round.Number { return 4 }
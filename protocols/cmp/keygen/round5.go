@@ -28,13 +28,12 @@ type broadcast5 struct {
 // - verify all Schnorr proof for the new ecdsa share.
 func (r *round5) StoreBroadcastMessage(msg round.Message) error {
 	from := msg.From
-	body, ok := msg.Content.(*broadcast5)
-	if !ok || body == nil {
-		return round.ErrInvalidContent
+	body, err := round.ContentAs[*broadcast5](msg)
+	if err != nil {
+		return err
 	}
 
-	fromOpts := keyopts.Options{}
-	fromOpts.Set("id", r.ID, "partyid", string(from))
+	fromOpts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(from))
 
 	// TODO implement SchnorrResponse validation
 	// if !body.SchnorrResponse.IsValid() {
@@ -86,10 +85,13 @@ func (r *round5) StoreMessage(round.Message) error { return nil }
 
 // Finalize implements round.Round.
 func (r *round5) Finalize(chan<- *round.Message) (round.Session, error) {
+	r.report(r.Number(), PhaseVerify)
+
 	// Verify if all parties commitments are received
 	if !r.CanFinalize() {
 		return nil, round.ErrNotEnoughMessages
 	}
+	r.auditor.done(r.PartyIDs())
 	return r.ResultRound(r.UpdatedConfig), nil
 }
 
@@ -114,9 +116,7 @@ func (broadcast5) RoundNumber() round.Number { return 5 }
 
 // BroadcastContent implements round.BroadcastRound.
 func (r *round5) BroadcastContent() round.BroadcastContent {
-	return &broadcast5{
-		SchnorrResponse: r.Group().NewScalar(), // sch.EmptyResponse(r.Group()),
-	}
+	return r.NewBroadcastContent(r.Number())
 }
 
 // Number implements round.Round.
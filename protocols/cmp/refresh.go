@@ -0,0 +1,65 @@
+package cmp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+)
+
+// ErrRefreshDiscontinuity is returned when a refreshed config is not a valid refresh of the previous one.
+var ErrRefreshDiscontinuity = errors.New("cmp: refresh discontinuity")
+
+// VerifyRefreshContinuity checks that newConfig results from refreshing oldConfig, using only public data.
+//
+// rerandomizer is the sum F(X) of the commitments to the zero-sharing polynomials published by the parties
+// during the refresh, so that each public share is updated as X'ⱼ = Xⱼ + F(j), with F(0) = 0.
+// The checks are that
+//   - the group, threshold, parties and chain key are unchanged, and the RID is fresh,
+//   - the epoch of newConfig follows the one of oldConfig, so that an older refresh cannot be replayed,
+//   - the public key is unchanged,
+//   - each public share was re-randomized by F, so that old shares no longer match the new ones,
+//   - the party's own share matches its new public share.
+func VerifyRefreshContinuity(oldConfig, newConfig *Config, rerandomizer *polynomial.Exponent) error {
+	if oldConfig == nil || newConfig == nil || rerandomizer == nil {
+		return fmt.Errorf("%w: nil argument", ErrRefreshDiscontinuity)
+	}
+	if oldConfig.Group.Name() != newConfig.Group.Name() || oldConfig.Threshold != newConfig.Threshold || oldConfig.ID != newConfig.ID {
+		return fmt.Errorf("%w: group, threshold or party changed", ErrRefreshDiscontinuity)
+	}
+	oldIDs, newIDs := oldConfig.PartyIDs(), newConfig.PartyIDs()
+	if len(oldIDs) != len(newIDs) || !oldIDs.Contains(newIDs...) {
+		return fmt.Errorf("%w: parties changed from %v to %v", ErrRefreshDiscontinuity, oldIDs, newIDs)
+	}
+	if !bytes.Equal(oldConfig.ChainKey, newConfig.ChainKey) {
+		return fmt.Errorf("%w: chain key changed", ErrRefreshDiscontinuity)
+	}
+	if newConfig.Epoch != oldConfig.Epoch+1 {
+		return fmt.Errorf("%w: epoch %d does not follow epoch %d", ErrRefreshDiscontinuity, newConfig.Epoch, oldConfig.Epoch)
+	}
+	if bytes.Equal(oldConfig.RID, newConfig.RID) {
+		return fmt.Errorf("%w: RID was not refreshed", ErrRefreshDiscontinuity)
+	}
+	if !oldConfig.PublicPoint().Equal(newConfig.PublicPoint()) {
+		return fmt.Errorf("%w: public key changed", ErrRefreshDiscontinuity)
+	}
+
+	if rerandomizer.Degree() != newConfig.Threshold || !rerandomizer.Constant().IsIdentity() {
+		return fmt.Errorf("%w: re-randomizer is not a sharing of zero of degree %d", ErrRefreshDiscontinuity, newConfig.Threshold)
+	}
+	for _, j := range newIDs {
+		delta := rerandomizer.Evaluate(j.Scalar(newConfig.Group))
+		if delta.IsIdentity() {
+			return fmt.Errorf("%w: share of %s was not re-randomized", ErrRefreshDiscontinuity, j)
+		}
+		if !oldConfig.Public[j].ECDSA.Add(delta).Equal(newConfig.Public[j].ECDSA) {
+			return fmt.Errorf("%w: public share of %s does not match the re-randomizer", ErrRefreshDiscontinuity, j)
+		}
+	}
+
+	if newConfig.ECDSA != nil && !newConfig.ECDSA.ActOnBase().Equal(newConfig.Public[newConfig.ID].ECDSA) {
+		return fmt.Errorf("%w: secret share does not match its public share", ErrRefreshDiscontinuity)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package cmp_test
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/lib/test"
+	"github.com/mr-shifu/mpc-lib/lib/types"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp"
+	"github.com/mr-shifu/mpc-lib/protocols/cmp/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyRefreshContinuity(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, ids := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+	old := configs[ids[0]]
+
+	// refresh the shares with a sharing of zero
+	f := polynomial.NewPolynomial(group, old.Threshold, nil)
+	rerandomizer := polynomial.NewPolynomialExponent(f)
+	refreshed := *old
+	refreshed.RID, _ = types.NewRID(rand.Reader)
+	refreshed.Epoch = old.Epoch + 1
+	refreshed.ECDSA = group.NewScalar().Set(old.ECDSA).Add(f.Evaluate(old.ID.Scalar(group)))
+	refreshed.Public = map[party.ID]*config.Public{}
+	for j, public := range old.Public {
+		updated := *public
+		updated.ECDSA = public.ECDSA.Add(rerandomizer.Evaluate(j.Scalar(group)))
+		refreshed.Public[j] = &updated
+	}
+	require.NoError(t, cmp.VerifyRefreshContinuity(old, &refreshed, rerandomizer))
+
+	// the epoch is kept by the encoding of the config
+	data, err := refreshed.MarshalBinary()
+	require.NoError(t, err)
+	decoded := config.EmptyConfig(group)
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, refreshed.Epoch, decoded.Epoch)
+
+	// the public shares must be updated by the published re-randomizer
+	other := polynomial.NewPolynomialExponent(polynomial.NewPolynomial(group, old.Threshold, nil))
+	assert.ErrorIs(t, cmp.VerifyRefreshContinuity(old, &refreshed, other), cmp.ErrRefreshDiscontinuity)
+
+	// the shares must have been re-randomized
+	assert.ErrorIs(t, cmp.VerifyRefreshContinuity(old, old, rerandomizer), cmp.ErrRefreshDiscontinuity)
+
+	// a refresh of the same epoch cannot be replayed, and none can be skipped
+	for _, epoch := range []uint64{old.Epoch, old.Epoch + 2} {
+		skipped := refreshed
+		skipped.Epoch = epoch
+		assert.ErrorIs(t, cmp.VerifyRefreshContinuity(old, &skipped, rerandomizer), cmp.ErrRefreshDiscontinuity)
+	}
+}
@@ -0,0 +1,83 @@
+package sign
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mr-shifu/mpc-lib/core/ecdsa"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	sw_ecdsa "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/ecdsa"
+)
+
+// ErrInvalidAggregate is returned by the Aggregator when the broadcasts of the signers do not yield a valid signature.
+var ErrInvalidAggregate = errors.New("sign: broadcasts do not yield a valid signature")
+
+// Aggregator returns a protocol.Aggregator computing the signature of message by the signers, under the public key,
+// from the broadcasts of a sign session, so that a protocol.Coordinator can publish it without holding a share.
+//
+// Once it received the broadcasts of rounds 3, 4 and 5 of all signers, it
+//   - sets Γ = ∑ⱼ Γⱼ, δ = ∑ⱼ δⱼ and verifies ∑ⱼ Δⱼ = [δ]G
+//   - sets R = [δ⁻¹] Γ and σ = ∑ⱼ σⱼ
+//   - returns the signature (R, σ), once verified against public.
+func Aggregator(group curve.Curve, signers []party.ID, public curve.Point, message []byte) protocol.Aggregator {
+	return func(broadcasts map[round.Number]map[party.ID]*protocol.Message) (interface{}, error) {
+		for number := round.Number(3); number <= protocolSignRounds; number++ {
+			for _, j := range signers {
+				if _, ok := broadcasts[number][j]; !ok {
+					return nil, nil
+				}
+			}
+		}
+
+		Gamma, Delta, BigDelta, Sigma := group.NewPoint(), group.NewScalar(), group.NewPoint(), group.NewScalar()
+		for _, j := range signers {
+			b3 := newBroadcast3(group, Version)
+			if err := decodeBroadcast(broadcasts[3][j], b3); err != nil {
+				return nil, err
+			}
+			gammaj, err := sw_ecdsa.FromBytes(b3.BigGammaShare)
+			if err != nil {
+				return nil, fmt.Errorf("%w: Γ of %s: %w", ErrInvalidAggregate, j, err)
+			}
+			Gamma = Gamma.Add(gammaj.PublicKeyRaw())
+
+			b4 := newBroadcast4(group, Version)
+			if err := decodeBroadcast(broadcasts[4][j], b4); err != nil {
+				return nil, err
+			}
+			Delta = Delta.Add(b4.DeltaShare)
+			BigDelta = BigDelta.Add(b4.BigDeltaShare)
+
+			b5 := newBroadcast5(group, Version)
+			if err := decodeBroadcast(broadcasts[5][j], b5); err != nil {
+				return nil, err
+			}
+			Sigma = Sigma.Add(b5.SigmaShare)
+		}
+
+		if Delta.IsZero() || !Delta.ActOnBase().Equal(BigDelta) {
+			return nil, fmt.Errorf("%w: Δ is inconsistent with [δ]G", ErrInvalidAggregate)
+		}
+		deltaInv := group.NewScalar().Set(Delta).Invert()
+		signature := &ecdsa.Signature{
+			R: deltaInv.Act(Gamma),
+			S: Sigma,
+		}
+		if !signature.Verify(public, message) {
+			return nil, fmt.Errorf("%w: signature does not verify", ErrInvalidAggregate)
+		}
+		return signature, nil
+	}
+}
+
+// decodeBroadcast decodes the content of msg, broadcast by a signer, into content.
+func decodeBroadcast(msg *protocol.Message, content round.Content) error {
+	if err := cbor.Unmarshal(msg.Data, content); err != nil {
+		return fmt.Errorf("%w: round %d from %s: %w", ErrInvalidAggregate, msg.RoundNumber, msg.From, err)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package sign
+
+import (
+	"github.com/mr-shifu/mpc-lib/core/party"
+	zkaffg "github.com/mr-shifu/mpc-lib/core/zk/affg"
+	zklogstar "github.com/mr-shifu/mpc-lib/core/zk/logstar"
+)
+
+// verifyAffg verifies the affg proof received from `from`, in the form sent in the session's version.
+func (r *round2) verifyAffg(from party.ID, proof *zkaffg.Proof, compact *zkaffg.CompactProof, public zkaffg.Public) bool {
+	if r.Version() >= VersionCompact {
+		return compact.Verify(r.HashForID(from), public)
+	}
+	return proof.Verify(r.HashForID(from), public)
+}
+
+// verifyLogstar verifies the log* proof received from `from`, in the form sent in the session's version.
+func (r *round2) verifyLogstar(from party.ID, proof *zklogstar.Proof, compact *zklogstar.CompactProof, public zklogstar.Public) bool {
+	if r.Version() >= VersionCompact {
+		return compact.Verify(r.HashForID(from), public)
+	}
+	return proof.Verify(r.HashForID(from), public)
+}
@@ -0,0 +1,59 @@
+package sign
+
+import (
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	zkaffg "github.com/mr-shifu/mpc-lib/core/zk/affg"
+	zklogstar "github.com/mr-shifu/mpc-lib/core/zk/logstar"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+)
+
+func init() {
+	round.Register(round.Contents, round.ContentKey{Protocol: protocolSignID, Round: 2}, newMessage2)
+	round.Register(round.Contents, round.ContentKey{Protocol: protocolSignID, Round: 2, Broadcast: true}, newBroadcast2)
+	round.Register(round.Contents, round.ContentKey{Protocol: protocolSignID, Round: 3}, newMessage3)
+	round.Register(round.Contents, round.ContentKey{Protocol: protocolSignID, Round: 3, Broadcast: true}, newBroadcast3)
+	round.Register(round.Contents, round.ContentKey{Protocol: protocolSignID, Round: 4}, newMessage4)
+	round.Register(round.Contents, round.ContentKey{Protocol: protocolSignID, Round: 4, Broadcast: true}, newBroadcast4)
+	round.Register(round.Contents, round.ContentKey{Protocol: protocolSignID, Round: 5, Broadcast: true}, newBroadcast5)
+}
+
+func newMessage2(curve.Curve, round.Version) *message2 { return &message2{} }
+
+func newBroadcast2(curve.Curve, round.Version) *broadcast2 { return &broadcast2{} }
+
+// newMessage3 returns the proofs in their compact form from VersionCompact on.
+func newMessage3(group curve.Curve, version round.Version) *message3 {
+	if version >= VersionCompact {
+		return &message3{
+			CompactProofLog:   zklogstar.EmptyCompact(group),
+			CompactDeltaProof: zkaffg.EmptyCompact(group),
+			CompactChiProof:   zkaffg.EmptyCompact(group),
+		}
+	}
+	return &message3{
+		ProofLog:   zklogstar.Empty(group),
+		DeltaProof: zkaffg.Empty(group),
+		ChiProof:   zkaffg.Empty(group),
+	}
+}
+
+func newBroadcast3(curve.Curve, round.Version) *broadcast3 { return &broadcast3{} }
+
+// newMessage4 returns the proof in its compact form from VersionCompact on.
+func newMessage4(group curve.Curve, version round.Version) *message4 {
+	if version >= VersionCompact {
+		return &message4{CompactProofLog: zklogstar.EmptyCompact(group)}
+	}
+	return &message4{ProofLog: zklogstar.Empty(group)}
+}
+
+func newBroadcast4(group curve.Curve, _ round.Version) *broadcast4 {
+	return &broadcast4{
+		DeltaShare:    group.NewScalar(),
+		BigDeltaShare: group.NewPoint(),
+	}
+}
+
+func newBroadcast5(group curve.Curve, _ round.Version) *broadcast5 {
+	return &broadcast5{SigmaShare: group.NewScalar()}
+}
@@ -0,0 +1,64 @@
+package sign
+
+import (
+	"errors"
+
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+)
+
+// Invariants implements round.InvariantChecker.
+//
+// - Kⱼ, Gⱼ are stored for all parties.
+func (r *round3) Invariants() []round.Invariant {
+	return []round.Invariant{
+		round.ForAllParties("Kⱼ stored", r.PartyIDs(), func(j party.ID) error {
+			_, err := r.signK_pek.Get(r.signOpts(j))
+			return err
+		}),
+		round.ForAllParties("Gⱼ stored", r.PartyIDs(), func(j party.ID) error {
+			_, err := r.gamma_pek.Get(r.signOpts(j))
+			return err
+		}),
+	}
+}
+
+// Invariants implements round.InvariantChecker.
+//
+// - invariants of round3.
+// - Γⱼ are stored for all parties, and Γ = ∑ⱼ Γⱼ.
+func (r *round4) Invariants() []round.Invariant {
+	return append(r.round3.Invariants(),
+		round.ForAllParties("Γⱼ stored", r.PartyIDs(), func(j party.ID) error {
+			_, err := r.gamma.GetKey(r.signOpts(j))
+			return err
+		}),
+		round.Invariant{
+			Name: "Γ = ∑ⱼ Γⱼ",
+			Check: func() error {
+				sum := r.Group().NewPoint()
+				for _, j := range r.PartyIDs() {
+					gammaj, err := r.gamma.GetKey(r.signOpts(j))
+					if err != nil {
+						return err
+					}
+					sum = sum.Add(gammaj.PublicKeyRaw())
+				}
+				Gamma, err := r.gamma.GetKey(r.signOpts("ROOT"))
+				if err != nil {
+					return err
+				}
+				if !sum.Equal(Gamma.PublicKeyRaw()) {
+					return errors.New("aggregate Γ differs from the sum of the shares")
+				}
+				return nil
+			},
+		},
+	)
+}
+
+func (r *round1) signOpts(j party.ID) keyopts.Options {
+	opts := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string(j))
+	return opts
+}
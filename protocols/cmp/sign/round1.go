@@ -1,6 +1,7 @@
 package sign
 
 import (
+	"github.com/mr-shifu/mpc-lib/core/bloom"
 	"github.com/mr-shifu/mpc-lib/lib/round"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/ecdsa"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
@@ -12,6 +13,7 @@ import (
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/config"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/message"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/pin"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/result"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/state"
 )
@@ -48,6 +50,9 @@ type round1 struct {
 	chi_mta   mta.MtAManager
 
 	sigma result.SigmaStore
+
+	seen *bloom.Filter
+	pins pin.PinStore
 }
 
 // StoreBroadcastMessage implements round.Round.
@@ -65,6 +70,7 @@ func (round1) StoreMessage(round.Message) error { return nil }
 // - Γᵢ = [γᵢ]⋅G
 // - Gᵢ = Encᵢ(γᵢ;νᵢ)
 // - Kᵢ = Encᵢ(kᵢ;ρᵢ)
+// - broadcast Kᵢ, Gᵢ and the fingerprint of (Nᵢ, sᵢ, tᵢ)
 //
 // NOTE
 // The protocol instructs us to broadcast Kᵢ and Gᵢ, but the protocol we implement
@@ -75,16 +81,14 @@ func (round1) StoreMessage(round.Message) error { return nil }
 // In two rounds, we compare the hashes received and if they are different then we abort.
 func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// Retreive Paillier Key to encode K and Gamma
-	kopts := keyopts.Options{}
-	kopts.Set("id", r.cfg.KeyID(), "partyid", string(r.SelfID()))
+	kopts := keyopts.New().WithKeyID(r.cfg.KeyID()).WithPartyID(string(r.SelfID()))
 
 	paillierKey, err := r.paillier_km.GetKey(kopts)
 	if err != nil {
 		return r, err
 	}
 
-	sopts := keyopts.Options{}
-	sopts.Set("id", r.cfg.ID(), "partyid", string(r.SelfID()))
+	sopts := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string(r.SelfID()))
 
 	// Generate Gamma ECDSA key to mask K and store its SKI to Gamma keyrpository
 	gamma, err := r.gamma.GenerateKey(sopts)
@@ -117,21 +121,23 @@ func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 	}
 
 	otherIDs := r.OtherPartyIDs()
-	broadcastMsg := broadcast2{K: KSharePEK.Encoded(), G: gammaPEK.Encoded()}
+	broadcastMsg := broadcast2{
+		K: KSharePEK.Encoded(),
+		G: gammaPEK.Encoded(),
+	}
 	if err := r.BroadcastMessage(out, &broadcastMsg); err != nil {
 		return r, err
 	}
 	errors := r.Pool.Parallelize(len(otherIDs), func(i int) interface{} {
 		j := otherIDs[i]
 
-		partyKopts := keyopts.Options{}
-		partyKopts.Set("id", r.cfg.KeyID(), "partyid", string(j))
+		partyKopts := keyopts.New().WithKeyID(r.cfg.KeyID()).WithPartyID(string(j))
 
 		pedj, err := r.pedersen_km.GetKey(partyKopts)
 		if err != nil {
 			return err
 		}
-		proof, err := KShare.NewZKEncProof(r.HashForID(r.SelfID()), KSharePEK, paillierKey.PublicKey(), pedj.PublicKey())
+		proof, err := KShare.NewZKEncProof(r.HashForID(r.SelfID()), KSharePEK, paillierKey.PublicKey(), pedj.PublicKey(), r.Profile())
 		if err != nil {
 			return err
 		}
@@ -10,10 +10,16 @@ import (
 	sw_mta "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/mta"
 	pek "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/paillierencodedkey"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/pin"
 )
 
 var _ round.Round = (*round2)(nil)
 
+var (
+	ErrDegenerateCiphertext = errors.New("sign: K or G is a degenerate ciphertext")
+	ErrReplayedCiphertext   = errors.New("sign: K or G was already used in this epoch")
+)
+
 type round2 struct {
 	*round1
 }
@@ -32,28 +38,41 @@ type message2 struct {
 
 // StoreBroadcastMessage implements round.Round.
 //
+// - check the parameters stored for j against their pin,
 // - store Kⱼ, Gⱼ.
 func (r *round2) StoreBroadcastMessage(msg round.Message) error {
 	from := msg.From
-	body, ok := msg.Content.(*broadcast2)
-	if !ok || body == nil {
-		return round.ErrInvalidContent
+	body, err := round.ContentAs[*broadcast2](msg)
+	if err != nil {
+		return err
 	}
 
-	koptsFrom := keyopts.Options{}
-	koptsFrom.Set("id", r.cfg.KeyID(), "partyid", string(from))
+	koptsFrom := keyopts.New().WithKeyID(r.cfg.KeyID()).WithPartyID(string(from))
 
-	soptsFrom := keyopts.Options{}
-	soptsFrom.Set("id", r.cfg.ID(), "partyid", string(from))
+	soptsFrom := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string(from))
 
 	paillierj, err := r.paillier_km.GetKey(koptsFrom)
 	if err != nil {
 		return err
 	}
 
+	if r.pins != nil {
+		pedersenj, err := r.pedersen_km.GetKey(koptsFrom)
+		if err != nil {
+			return err
+		}
+		fp := pin.Fingerprint(paillierj.PublicKeyRaw(), pedersenj.PublicKeyRaw())
+		if err := r.pins.Check(r.cfg.KeyID(), from, fp); err != nil {
+			return err
+		}
+	}
+
 	if !paillierj.ValidateCiphertexts(body.K, body.G) {
 		return errors.New("invalid K, G")
 	}
+	if err := r.checkNonceCiphertexts(paillierj.PublicKeyRaw(), body.K, body.G); err != nil {
+		return err
+	}
 
 	k_pekj := pek.NewPaillierEncodedkey(nil, body.K, nil, r.Group())
 	if _, err := r.signK_pek.Import(k_pekj, soptsFrom); err != nil {
@@ -75,28 +94,48 @@ func (r *round2) StoreBroadcastMessage(msg round.Message) error {
 	return nil
 }
 
+// checkNonceCiphertexts rejects Kⱼ, Gⱼ if they were encrypted without randomness, are equal,
+// or were already received in the current epoch.
+func (r *round2) checkNonceCiphertexts(pk *paillier.PublicKey, K, G *paillier.Ciphertext) error {
+	if pk.IsDegenerate(K) || pk.IsDegenerate(G) {
+		return ErrDegenerateCiphertext
+	}
+	if K.Equal(G) {
+		return ErrDegenerateCiphertext
+	}
+	if r.seen == nil {
+		return nil
+	}
+	values := make([][]byte, 0, 2)
+	for _, ct := range []*paillier.Ciphertext{K, G} {
+		data, err := ct.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		values = append(values, data)
+	}
+	// Kⱼ is only recorded if Gⱼ was not replayed either, so that a rejected message does not burn it
+	if r.seen.AddAll(values...) {
+		return ErrReplayedCiphertext
+	}
+	return nil
+}
+
 // VerifyMessage implements round.Round.
 //
 // - verify zkenc(Kⱼ).
 func (r *round2) VerifyMessage(msg round.Message) error {
 	from, to := msg.From, msg.To
-	body, ok := msg.Content.(*message2)
-	if !ok || body == nil {
-		return round.ErrInvalidContent
-	}
-
-	if body.ProofEnc == nil {
-		return round.ErrNilFields
+	body, err := round.ContentAs[*message2](msg)
+	if err != nil {
+		return err
 	}
 
-	koptsFrom := keyopts.Options{}
-	koptsFrom.Set("id", r.cfg.KeyID(), "partyid", string(from))
+	koptsFrom := keyopts.New().WithKeyID(r.cfg.KeyID()).WithPartyID(string(from))
 
-	koptsTo := keyopts.Options{}
-	koptsTo.Set("id", r.cfg.KeyID(), "partyid", string(to))
+	koptsTo := keyopts.New().WithKeyID(r.cfg.KeyID()).WithPartyID(string(to))
 
-	soptsFrom := keyopts.Options{}
-	soptsFrom.Set("id", r.cfg.ID(), "partyid", string(from))
+	soptsFrom := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string(from))
 
 	paillierFrom, err := r.paillier_km.GetKey(koptsFrom)
 	if err != nil {
@@ -112,9 +151,10 @@ func (r *round2) VerifyMessage(msg round.Message) error {
 		return err
 	}
 	if !body.ProofEnc.Verify(r.Group(), r.HashForID(from), zkenc.Public{
-		K:      Kj.Encoded(),
-		Prover: paillierFrom.PublicKeyRaw(),
-		Aux:    pedersenTo.PublicKeyRaw(),
+		K:       Kj.Encoded(),
+		Prover:  paillierFrom.PublicKeyRaw(),
+		Aux:     pedersenTo.PublicKeyRaw(),
+		Profile: r.Profile(),
 	}) {
 		return errors.New("failed to validate enc proof for K")
 	}
@@ -135,11 +175,9 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 		return nil, round.ErrNotEnoughMessages
 	}
 
-	sopts := keyopts.Options{}
-	sopts.Set("id", r.cfg.ID(), "partyid", string(r.SelfID()))
+	sopts := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string(r.SelfID()))
 
-	kopts := keyopts.Options{}
-	kopts.Set("id", r.cfg.KeyID(), "partyid", string(r.SelfID()))
+	kopts := keyopts.New().WithKeyID(r.cfg.KeyID()).WithPartyID(string(r.SelfID()))
 
 	// Retreive Gamma key from keystore
 	gamma, err := r.gamma.GetKey(sopts)
@@ -167,11 +205,9 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 	mtaOuts := r.Pool.Parallelize(len(otherIDs), func(i int) interface{} {
 		j := otherIDs[i]
 
-		soptsj := keyopts.Options{}
-		soptsj.Set("id", r.cfg.ID(), "partyid", string(j))
+		soptsj := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string(j))
 
-		koptsj := keyopts.Options{}
-		koptsj.Set("id", r.cfg.KeyID(), "partyid", string(j))
+		koptsj := keyopts.New().WithKeyID(r.cfg.KeyID()).WithPartyID(string(j))
 
 		// TODO must be changed to signID
 		gamma, err := r.gamma.GetKey(sopts)
@@ -205,6 +241,7 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 			paillierKey.PublicKey(),
 			paillierj.PublicKey(),
 			pedj.PublicKey(),
+			r.Profile(),
 		)
 
 		ChiBeta, ChiD, ChiF, ChiProof := eckey.NewMtAAffgProof(
@@ -213,6 +250,7 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 			paillierKey.PublicKey(),
 			paillierj.PublicKey(),
 			pedj.PublicKey(),
+			r.Profile(),
 		)
 
 		gammaPEK, err := r.gamma_pek.Get(sopts)
@@ -227,12 +265,13 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 			nil,
 			paillierKey.PublicKey(),
 			pedj.PublicKey(),
+			r.Profile(),
 		)
 		if err != nil {
 			return err
 		}
 
-		err = r.SendMessage(out, &message3{
+		msg := &message3{
 			DeltaD:     DeltaD,
 			DeltaF:     DeltaF,
 			DeltaProof: DeltaProof,
@@ -240,7 +279,19 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 			ChiF:       ChiF,
 			ChiProof:   ChiProof,
 			ProofLog:   proof,
-		}, j)
+		}
+		if r.Version() >= VersionCompact {
+			msg = &message3{
+				DeltaD:            DeltaD,
+				DeltaF:            DeltaF,
+				ChiD:              ChiD,
+				ChiF:              ChiF,
+				CompactDeltaProof: DeltaProof.Compact(),
+				CompactChiProof:   ChiProof.Compact(),
+				CompactProofLog:   proof.Compact(),
+			}
+		}
+		err = r.SendMessage(out, msg, j)
 		return mtaOut{
 			err:       err,
 			DeltaBeta: DeltaBeta,
@@ -255,8 +306,7 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 			return r, m.err
 		}
 
-		soptsj := keyopts.Options{}
-		soptsj.Set("id", r.cfg.ID(), "partyid", string(j))
+		soptsj := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string(j))
 
 		delta_mta := sw_mta.NewMtA(nil, m.DeltaBeta)
 		if err := r.delta_mta.Import(delta_mta, soptsj); err != nil {
@@ -295,13 +345,15 @@ func (r *round2) CanFinalize() bool {
 func (message2) RoundNumber() round.Number { return 2 }
 
 // MessageContent implements round.Round.
-func (round2) MessageContent() round.Content { return &message2{} }
+func (r *round2) MessageContent() round.Content { return r.NewMessageContent(r.Number()) }
 
 // RoundNumber implements round.Content.
 func (broadcast2) RoundNumber() round.Number { return 2 }
 
 // BroadcastContent implements round.BroadcastRound.
-func (round2) BroadcastContent() round.BroadcastContent { return &broadcast2{} }
+func (r *round2) BroadcastContent() round.BroadcastContent {
+	return r.NewBroadcastContent(r.Number())
+}
 
 // Number implements round.Round.
 func (round2) Number() round.Number { return 2 }
@@ -22,11 +22,16 @@ type round3 struct {
 type message3 struct {
 	DeltaD     *paillier.Ciphertext // DeltaD = Dᵢⱼ
 	DeltaF     *paillier.Ciphertext // DeltaF = Fᵢⱼ
-	DeltaProof *zkaffg.Proof
+	DeltaProof *zkaffg.Proof        `strict:"optional"`
 	ChiD       *paillier.Ciphertext // DeltaD = D̂_{ij}
 	ChiF       *paillier.Ciphertext // ChiF = F̂ᵢⱼ
-	ChiProof   *zkaffg.Proof
-	ProofLog   *zklogstar.Proof
+	ChiProof   *zkaffg.Proof        `strict:"optional"`
+	ProofLog   *zklogstar.Proof     `strict:"optional"`
+	// From VersionCompact on, the proofs are sent in their compact form instead.
+	// Only one form of each proof is sent, so both are optional, and the round rejects a missing one.
+	CompactDeltaProof *zkaffg.CompactProof    `cbor:",omitempty" strict:"optional"`
+	CompactChiProof   *zkaffg.CompactProof    `cbor:",omitempty" strict:"optional"`
+	CompactProofLog   *zklogstar.CompactProof `cbor:",omitempty" strict:"optional"`
 }
 
 type broadcast3 struct {
@@ -38,16 +43,15 @@ type broadcast3 struct {
 //
 // - store Γⱼ
 func (r *round3) StoreBroadcastMessage(msg round.Message) error {
-	body, ok := msg.Content.(*broadcast3)
-	if !ok || body == nil {
-		return round.ErrInvalidContent
+	body, err := round.ContentAs[*broadcast3](msg)
+	if err != nil {
+		return err
 	}
 	// if body.BigGammaShare.IsIdentity() {
 	// 	return round.ErrNilFields
 	// }
 
-	soptsFrom := keyopts.Options{}
-	soptsFrom.Set("id", r.cfg.ID(), "partyid", string(msg.From))
+	soptsFrom := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string(msg.From))
 
 	// gamma := sw_ecdsa.NewECDSAKey(nil, body.BigGammaShare, body.BigGammaShare.Curve())
 	if _, err := r.gamma.ImportKey(body.BigGammaShare, soptsFrom); err != nil {
@@ -69,22 +73,18 @@ func (r *round3) StoreBroadcastMessage(msg round.Message) error {
 // - verify zkproofs affg (2x) zklog*.
 func (r *round3) VerifyMessage(msg round.Message) error {
 	from, to := msg.From, msg.To
-	body, ok := msg.Content.(*message3)
-	if !ok || body == nil {
-		return round.ErrInvalidContent
+	body, err := round.ContentAs[*message3](msg)
+	if err != nil {
+		return err
 	}
 
-	koptsFrom := keyopts.Options{}
-	koptsFrom.Set("id", r.cfg.KeyID(), "partyid", string(from))
+	koptsFrom := keyopts.New().WithKeyID(r.cfg.KeyID()).WithPartyID(string(from))
 
-	koptsTo := keyopts.Options{}
-	koptsTo.Set("id", r.cfg.KeyID(), "partyid", string(to))
+	koptsTo := keyopts.New().WithKeyID(r.cfg.KeyID()).WithPartyID(string(to))
 
-	soptsFrom := keyopts.Options{}
-	soptsFrom.Set("id", r.cfg.ID(), "partyid", string(from))
+	soptsFrom := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string(from))
 
-	soptsTo := keyopts.Options{}
-	soptsTo.Set("id", r.cfg.ID(), "partyid", string(to))
+	soptsTo := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string(to))
 
 	paillierFrom, err := r.paillier_km.GetKey(koptsFrom)
 	if err != nil {
@@ -118,7 +118,7 @@ func (r *round3) VerifyMessage(msg round.Message) error {
 		return err
 	}
 
-	if !body.DeltaProof.Verify(r.HashForID(from), zkaffg.Public{
+	if !r.verifyAffg(from, body.DeltaProof, body.CompactDeltaProof, zkaffg.Public{
 		Kv:       shareKTo_pek.Encoded(),
 		Dv:       body.DeltaD,
 		Fp:       body.DeltaF,
@@ -126,11 +126,12 @@ func (r *round3) VerifyMessage(msg round.Message) error {
 		Prover:   paillierFrom.PublicKeyRaw(),
 		Verifier: paillierTo.PublicKeyRaw(),
 		Aux:      pedTo.PublicKeyRaw(),
+		Profile:  r.Profile(),
 	}) {
 		return errors.New("failed to validate affg proof for Delta MtA")
 	}
 
-	if !body.ChiProof.Verify(r.HashForID(from), zkaffg.Public{
+	if !r.verifyAffg(from, body.ChiProof, body.CompactChiProof, zkaffg.Public{
 		Kv:       shareKTo_pek.Encoded(),
 		Dv:       body.ChiD,
 		Fp:       body.ChiF,
@@ -138,15 +139,17 @@ func (r *round3) VerifyMessage(msg round.Message) error {
 		Prover:   paillierFrom.PublicKeyRaw(),
 		Verifier: paillierTo.PublicKeyRaw(),
 		Aux:      pedTo.PublicKeyRaw(),
+		Profile:  r.Profile(),
 	}) {
 		return errors.New("failed to validate affg proof for Chi MtA")
 	}
 
-	if !body.ProofLog.Verify(r.HashForID(from), zklogstar.Public{
-		C:      gammaFrom_pek.Encoded(),
-		X:      gammaFrom.PublicKeyRaw(),
-		Prover: paillierFrom.PublicKeyRaw(),
-		Aux:    pedTo.PublicKeyRaw(),
+	if !r.verifyLogstar(from, body.ProofLog, body.CompactProofLog, zklogstar.Public{
+		C:       gammaFrom_pek.Encoded(),
+		X:       gammaFrom.PublicKeyRaw(),
+		Prover:  paillierFrom.PublicKeyRaw(),
+		Aux:     pedTo.PublicKeyRaw(),
+		Profile: r.Profile(),
 	}) {
 		return errors.New("failed to validate log proof")
 	}
@@ -159,13 +162,15 @@ func (r *round3) VerifyMessage(msg round.Message) error {
 // - Decrypt MtA shares,
 // - save αᵢⱼ, α̂ᵢⱼ.
 func (r *round3) StoreMessage(msg round.Message) error {
-	from, body := msg.From, msg.Content.(*message3)
+	from := msg.From
+	body, err := round.ContentAs[*message3](msg)
+	if err != nil {
+		return err
+	}
 
-	kopts := keyopts.Options{}
-	kopts.Set("id", r.cfg.KeyID(), "partyid", string(r.SelfID()))
+	kopts := keyopts.New().WithKeyID(r.cfg.KeyID()).WithPartyID(string(r.SelfID()))
 
-	soptsFrom := keyopts.Options{}
-	soptsFrom.Set("id", r.cfg.ID(), "partyid", string(from))
+	soptsFrom := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string(from))
 
 	// αᵢⱼ
 	paillierKey, err := r.paillier_km.GetKey(kopts)
@@ -210,25 +215,21 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 		return nil, round.ErrNotEnoughMessages
 	}
 
-	sopts := keyopts.Options{}
-	sopts.Set("id", r.cfg.ID(), "partyid", string(r.SelfID()))
+	sopts := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string(r.SelfID()))
 
-	kopts := keyopts.Options{}
-	kopts.Set("id", r.cfg.KeyID(), "partyid", string(r.SelfID()))
+	kopts := keyopts.New().WithKeyID(r.cfg.KeyID()).WithPartyID(string(r.SelfID()))
 
 	// Γ = ∑ⱼ Γⱼ
 	Gamma := r.Group().NewPoint()
 	for _, j := range r.PartyIDs() {
-		soptsj := keyopts.Options{}
-		soptsj.Set("id", r.cfg.ID(), "partyid", string(j))
+		soptsj := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string(j))
 		gammaj, err := r.gamma.GetKey(soptsj)
 		if err != nil {
 			return nil, err
 		}
 		Gamma = Gamma.Add(gammaj.PublicKeyRaw())
 	}
-	soptsRoot := keyopts.Options{}
-	soptsRoot.Set("id", r.cfg.ID(), "partyid", "ROOT")
+	soptsRoot := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID("ROOT")
 	gammaRoot := sw_ecdsa.NewECDSAKey(nil, Gamma, Gamma.Curve())
 	if _, err := r.gamma.ImportKey(gammaRoot, soptsRoot); err != nil {
 		return nil, err
@@ -248,8 +249,7 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// δᵢ = γᵢ kᵢ + ∑ⱼ δᵢⱼ
 	deltaSum := new(saferith.Int)
 	for _, j := range r.OtherPartyIDs() {
-		soptsj := keyopts.Options{}
-		soptsj.Set("id", r.cfg.ID(), "partyid", string(j))
+		soptsj := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string(j))
 		//δᵢ += αᵢⱼ + βᵢⱼ
 		deltaj, err := r.delta_mta.Get(soptsj)
 		if err != nil {
@@ -272,8 +272,7 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// χᵢ = xᵢ kᵢ + ∑ⱼ χᵢⱼ
 	chiSum := new(saferith.Int)
 	for _, j := range r.OtherPartyIDs() {
-		soptsj := keyopts.Options{}
-		soptsj.Set("id", r.cfg.ID(), "partyid", string(j))
+		soptsj := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string(j))
 		chij, err := r.chi_mta.Get(soptsj)
 		if err != nil {
 			return nil, err
@@ -314,8 +313,7 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 	errs := r.Pool.Parallelize(len(otherIDs), func(i int) interface{} {
 		j := otherIDs[i]
 
-		koptsj := keyopts.Options{}
-		koptsj.Set("id", r.cfg.KeyID(), "partyid", string(j))
+		koptsj := keyopts.New().WithKeyID(r.cfg.KeyID()).WithPartyID(string(j))
 
 		pedj, err := r.pedersen_km.GetKey(koptsj)
 		if err != nil {
@@ -330,12 +328,17 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 			Gamma,               // G
 			paillier.PublicKey(),
 			pedj.PublicKey(),
+			r.Profile(),
 		)
 		if err != nil {
 			return err
 		}
 
-		if err := r.SendMessage(out, &message4{ProofLog: proofLog}, j); err != nil {
+		msg := &message4{ProofLog: proofLog}
+		if r.Version() >= VersionCompact {
+			msg = &message4{CompactProofLog: proofLog.Compact()}
+		}
+		if err := r.SendMessage(out, msg, j); err != nil {
 			return err
 		}
 		return nil
@@ -377,22 +380,14 @@ func (r *round3) CanFinalize() bool {
 func (message3) RoundNumber() round.Number { return 3 }
 
 // MessageContent implements round.Round.
-func (r *round3) MessageContent() round.Content {
-	return &message3{
-		ProofLog:   zklogstar.Empty(r.Group()),
-		DeltaProof: zkaffg.Empty(r.Group()),
-		ChiProof:   zkaffg.Empty(r.Group()),
-	}
-}
+func (r *round3) MessageContent() round.Content { return r.NewMessageContent(r.Number()) }
 
 // RoundNumber implements round.Content.
 func (broadcast3) RoundNumber() round.Number { return 3 }
 
 // BroadcastContent implements round.BroadcastRound.
 func (r *round3) BroadcastContent() round.BroadcastContent {
-	return &broadcast3{
-		// BigGammaShare: r.Group().NewPoint(),
-	}
+	return r.NewBroadcastContent(r.Number())
 }
 
 // Number implements round.Round.
@@ -2,6 +2,8 @@ package sign
 
 import (
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	zklogstar "github.com/mr-shifu/mpc-lib/core/zk/logstar"
@@ -9,6 +11,7 @@ import (
 	comm_ecdsa "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/ecdsa"
 	sw_ecdsa "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/ecdsa"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/result"
 )
 
 var _ round.Round = (*round4)(nil)
@@ -18,7 +21,10 @@ type round4 struct {
 }
 
 type message4 struct {
-	ProofLog *zklogstar.Proof
+	ProofLog *zklogstar.Proof `strict:"optional"`
+	// From VersionCompact on, the proof is sent in its compact form instead.
+	// Only one form is sent, so both are optional, and the round rejects a missing one.
+	CompactProofLog *zklogstar.CompactProof `cbor:",omitempty" strict:"optional"`
 }
 
 type broadcast4 struct {
@@ -33,16 +39,11 @@ type broadcast4 struct {
 //
 // - store δⱼ, Δⱼ
 func (r *round4) StoreBroadcastMessage(msg round.Message) error {
-	body, ok := msg.Content.(*broadcast4)
-	if !ok || body == nil {
-		return round.ErrInvalidContent
-	}
-	if body.DeltaShare.IsZero() || body.BigDeltaShare.IsIdentity() {
-		return round.ErrNilFields
+	body, err := round.ContentAs[*broadcast4](msg)
+	if err != nil {
+		return err
 	}
-
-	soptsFrom := keyopts.Options{}
-	soptsFrom.Set("id", r.cfg.ID(), "partyid", string(msg.From))
+	soptsFrom := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string(msg.From))
 
 	bigDeltaShareFrom := body.BigDeltaShare
 	bigDeltaFrom := sw_ecdsa.NewECDSAKey(nil, bigDeltaShareFrom, bigDeltaShareFrom.Curve())
@@ -71,22 +72,18 @@ func (r *round4) StoreBroadcastMessage(msg round.Message) error {
 // - Verify Π(log*)(ϕ”ᵢⱼ, Δⱼ, Γ).
 func (r *round4) VerifyMessage(msg round.Message) error {
 	from, to := msg.From, msg.To
-	body, ok := msg.Content.(*message4)
-	if !ok || body == nil {
-		return round.ErrInvalidContent
+	body, err := round.ContentAs[*message4](msg)
+	if err != nil {
+		return err
 	}
 
-	koptsFrom := keyopts.Options{}
-	koptsFrom.Set("id", r.cfg.KeyID(), "partyid", string(from))
+	koptsFrom := keyopts.New().WithKeyID(r.cfg.KeyID()).WithPartyID(string(from))
 
-	koptsTo := keyopts.Options{}
-	koptsTo.Set("id", r.cfg.KeyID(), "partyid", string(to))
+	koptsTo := keyopts.New().WithKeyID(r.cfg.KeyID()).WithPartyID(string(to))
 
-	soptsFrom := keyopts.Options{}
-	soptsFrom.Set("id", r.cfg.ID(), "partyid", string(from))
+	soptsFrom := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string(from))
 
-	soptsRoot := keyopts.Options{}
-	soptsRoot.Set("id", r.cfg.ID(), "partyid", string("ROOT"))
+	soptsRoot := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string("ROOT"))
 
 	kFromPek, err := r.signK_pek.Get(soptsFrom)
 	if err != nil {
@@ -113,13 +110,14 @@ func (r *round4) VerifyMessage(msg round.Message) error {
 	}
 
 	zkLogPublic := zklogstar.Public{
-		C:      kFromPek.Encoded(),
-		X:      bigDeltaShareFrom.PublicKeyRaw(),
-		G:      gamma.PublicKeyRaw(),
-		Prover: paillierFrom.PublicKeyRaw(),
-		Aux:    pedTo.PublicKeyRaw(),
+		C:       kFromPek.Encoded(),
+		X:       bigDeltaShareFrom.PublicKeyRaw(),
+		G:       gamma.PublicKeyRaw(),
+		Prover:  paillierFrom.PublicKeyRaw(),
+		Aux:     pedTo.PublicKeyRaw(),
+		Profile: r.Profile(),
 	}
-	if !body.ProofLog.Verify(r.HashForID(from), zkLogPublic) {
+	if !r.verifyLogstar(from, body.ProofLog, body.CompactProofLog, zkLogPublic) {
 		return errors.New("failed to validate log proof")
 	}
 
@@ -136,6 +134,7 @@ func (round4) StoreMessage(round.Message) error {
 // - set δ = ∑ⱼ δⱼ
 // - set Δ = ∑ⱼ Δⱼ
 // - verify Δ = [δ]G
+// - refuse to release σᵢ before the release time, if any
 // - compute σᵢ = rχᵢ + kᵢm.
 func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// Verify if all parties commitments are received
@@ -143,17 +142,20 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 		return nil, round.ErrNotEnoughMessages
 	}
 
-	sopts := keyopts.Options{}
-	sopts.Set("id", r.cfg.ID(), "partyid", string(r.SelfID()))
+	// σᵢ is the only share revealing the signature, so the time lock is enforced here,
+	// once the presignature is computed
+	if release := r.cfg.ReleaseTime(); !release.IsZero() && time.Now().Before(release) {
+		return r.AbortRound(fmt.Errorf("%w: until %s", ErrNotReleased, release.UTC().Format(time.RFC3339))), nil
+	}
+
+	sopts := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string(r.SelfID()))
 
-	soptsRoot := keyopts.Options{}
-	soptsRoot.Set("id", r.cfg.ID(), "partyid", "ROOT")
+	soptsRoot := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID("ROOT")
 
 	// δ = ∑ⱼ δⱼ
 	var deltaShares []comm_ecdsa.ECDSAKey
 	for _, j := range r.OtherPartyIDs() {
-		soptsj := keyopts.Options{}
-		soptsj.Set("id", r.cfg.ID(), "partyid", string(j))
+		soptsj := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string(j))
 		delta, err := r.delta.GetKey(soptsj)
 		if err != nil {
 			return nil, err
@@ -169,8 +171,7 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// Δ = ∑ⱼ Δⱼ
 	BigDelta := r.Group().NewPoint()
 	for _, j := range r.PartyIDs() {
-		soptsj := keyopts.Options{}
-		soptsj.Set("id", r.cfg.ID(), "partyid", string(j))
+		soptsj := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string(j))
 		bigDeltaj, err := r.bigDelta.GetKey(soptsj)
 		if err != nil {
 			return nil, err
@@ -193,6 +194,13 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 	BigR := deltaInv.Act(gamma.PublicKeyRaw())            // R = [δ⁻¹] Γ
 	R := BigR.XScalar()                                   // r = R|ₓ
 
+	// the presignature is bound to this session and the signers which computed it, which they all agree on
+	presig, err := result.NewPresignature(r.cfg.ID(), r.PartyIDs(), BigR)
+	if err != nil {
+		return r, err
+	}
+	r.signature.ImportPresignature(r.cfg.ID(), presig)
+
 	// rχᵢ
 	chiShare, err := r.chi.GetKey(sopts)
 	if err != nil {
@@ -211,7 +219,6 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 	if err := r.sigma.ImportSigma(SigmaShare, sopts); err != nil {
 		return nil, err
 	}
-	r.signature.ImportSignR(r.cfg.ID(), BigR)
 
 	// Send to all
 	err = r.BroadcastMessage(out, &broadcast5{SigmaShare: SigmaShare})
@@ -246,21 +253,14 @@ func (r *round4) CanFinalize() bool {
 func (message4) RoundNumber() round.Number { return 4 }
 
 // MessageContent implements round.Round.
-func (r *round4) MessageContent() round.Content {
-	return &message4{
-		ProofLog: zklogstar.Empty(r.Group()),
-	}
-}
+func (r *round4) MessageContent() round.Content { return r.NewMessageContent(r.Number()) }
 
 // RoundNumber implements round.Content.
 func (broadcast4) RoundNumber() round.Number { return 4 }
 
 // BroadcastContent implements round.BroadcastRound.
 func (r *round4) BroadcastContent() round.BroadcastContent {
-	return &broadcast4{
-		DeltaShare:    r.Group().NewScalar(),
-		BigDeltaShare: r.Group().NewPoint(),
-	}
+	return r.NewBroadcastContent(r.Number())
 }
 
 // Number implements round.Round.
@@ -2,6 +2,7 @@ package sign
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/mr-shifu/mpc-lib/core/ecdsa"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
@@ -24,17 +25,12 @@ type broadcast5 struct {
 //
 // - save σⱼ
 func (r *round5) StoreBroadcastMessage(msg round.Message) error {
-	body, ok := msg.Content.(*broadcast5)
-	if !ok || body == nil {
-		return round.ErrInvalidContent
-	}
-
-	if body.SigmaShare.IsZero() {
-		return round.ErrNilFields
+	body, err := round.ContentAs[*broadcast5](msg)
+	if err != nil {
+		return err
 	}
 
-	soptsFrom := keyopts.Options{}
-	soptsFrom.Set("id", r.cfg.ID(), "partyid", string(msg.From))
+	soptsFrom := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string(msg.From))
 
 	// r.SigmaShares[msg.From] = body.SigmaShare
 	if err := r.sigma.ImportSigma(body.SigmaShare, soptsFrom); err != nil {
@@ -59,6 +55,7 @@ func (round5) StoreMessage(round.Message) error { return nil }
 
 // Finalize implements round.Round
 //
+// - check that the signers are those of the presignature
 // - compute σ = ∑ⱼ σⱼ
 // - verify signature.
 func (r *round5) Finalize(chan<- *round.Message) (round.Session, error) {
@@ -67,17 +64,27 @@ func (r *round5) Finalize(chan<- *round.Message) (round.Session, error) {
 		return nil, round.ErrNotEnoughMessages
 	}
 
-	soptsRoot := keyopts.Options{}
-	soptsRoot.Set("id", r.cfg.ID(), "partyid", string("ROOT"))
+	presig := r.signature.Presignature(r.cfg.ID())
+	if presig == nil {
+		return r, errors.New("sign: no presignature for session")
+	}
+	// the presignature may have been computed by another session, in which case it must bind the same signers
+	if err := presig.Verify(r.PartyIDs()); err != nil {
+		// update state to Aborted in StateManager
+		if err := r.statemgr.SetAborted(r.ID); err != nil {
+			return r, err
+		}
+		return r.AbortRound(fmt.Errorf("%w: %w", ErrInvalidSigners, err)), nil
+	}
+
+	soptsRoot := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string("ROOT"))
 
-	koptsRoot := keyopts.Options{}
-	koptsRoot.Set("id", r.cfg.KeyID(), "partyid", string("ROOT"))
+	koptsRoot := keyopts.New().WithKeyID(r.cfg.KeyID()).WithPartyID(string("ROOT"))
 
 	// compute σ = ∑ⱼ σⱼ
 	Sigma := r.Group().NewScalar()
 	for _, j := range r.PartyIDs() {
-		soptsj := keyopts.Options{}
-		soptsj.Set("id", r.cfg.ID(), "partyid", string(j))
+		soptsj := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string(j))
 		sigmaShare, err := r.sigma.GetSigma(soptsj)
 		if err != nil {
 			return nil, err
@@ -86,10 +93,9 @@ func (r *round5) Finalize(chan<- *round.Message) (round.Session, error) {
 	}
 
 	r.signature.ImportSignSigma(r.cfg.ID(), Sigma)
-	signR := r.signature.SignR(r.cfg.ID())
 
 	signature := &ecdsa.Signature{
-		R: signR,
+		R: presig.R,
 		S: Sigma,
 	}
 
@@ -105,16 +111,19 @@ func (r *round5) Finalize(chan<- *round.Message) (round.Session, error) {
 		return r.AbortRound(errors.New("failed to validate signature")), nil
 	}
 
-	ecKey, err = r.ec.GetKey(koptsRoot)
-	if err != nil {
-		return nil, err
-	}
-	if !signature.Verify(ecKey.PublicKeyRaw(), r.cfg.Message()) {
-		// update state to Aborted in StateManager
-		if err := r.statemgr.SetAborted(r.ID); err != nil {
-			return r, err
+	// a child key differs from the key itself, and was checked above
+	if len(r.cfg.DerivationPath()) == 0 {
+		ecKey, err = r.ec.GetKey(koptsRoot)
+		if err != nil {
+			return nil, err
+		}
+		if !signature.Verify(ecKey.PublicKeyRaw(), r.cfg.Message()) {
+			// update state to Aborted in StateManager
+			if err := r.statemgr.SetAborted(r.ID); err != nil {
+				return r, err
+			}
+			return r.AbortRound(errors.New("failed to validate signature")), nil
 		}
-		return r.AbortRound(errors.New("failed to validate signature")), nil
 	}
 
 	// update last round processed in StateManager
@@ -150,9 +159,7 @@ func (broadcast5) RoundNumber() round.Number { return 5 }
 
 // BroadcastContent implements round.BroadcastRound.
 func (r *round5) BroadcastContent() round.BroadcastContent {
-	return &broadcast5{
-		SigmaShare: r.Group().NewScalar(),
-	}
+	return r.NewBroadcastContent(r.Number())
 }
 
 // Number implements round.Round.
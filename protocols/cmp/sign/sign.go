@@ -5,9 +5,14 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/mr-shifu/mpc-lib/core/bloom"
+	core_hash "github.com/mr-shifu/mpc-lib/core/hash"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/math/polynomial"
+	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/core/pool"
 	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/lib/bip32"
 	"github.com/mr-shifu/mpc-lib/lib/round"
 	"github.com/mr-shifu/mpc-lib/lib/types"
 
@@ -17,10 +22,12 @@ import (
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/paillier"
 	pek "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/paillierencodedkey"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/pedersen"
+	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/rid"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/vss"
 	sw_ecdsa "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/ecdsa"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/config"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/pin"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/result"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/state"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/message"
@@ -30,8 +37,37 @@ import (
 const (
 	protocolSignID                  = "cmp/sign"
 	protocolSignRounds round.Number = 5
+	// ProtocolID identifies the sign protocol in messages and SSIDs.
+	ProtocolID = protocolSignID
+	// Version is the current version of the sign protocol.
+	Version round.Version = 1
+	// VersionCompact sends the affg and log* proofs of rounds 3 and 4 in their compact,
+	// challenge-response form, which roughly halves these messages for bandwidth-constrained signers.
+	VersionCompact round.Version = 2
 )
 
+// Versions are the versions of the sign protocol which can be run, and advertised to round.Negotiate.
+var Versions = []round.Version{Version, VersionCompact}
+
+// ErrInvalidSigners is returned when the signers of a session are not a valid signing subset of the key,
+// or are not the signers its presignature was computed by.
+var ErrInvalidSigners = errors.New("sign: signers are not a valid signing subset")
+
+// ErrInvalidDerivationPath is returned when the child key at the derivation path of a session cannot be derived.
+var ErrInvalidDerivationPath = errors.New("sign: invalid derivation path")
+
+// ErrUnsupportedVersion is returned when the version set in the config of a session cannot be run.
+var ErrUnsupportedVersion = errors.New("sign: unsupported protocol version")
+
+// ErrNotReleased aborts a session which reaches the release of the signature shares before its release time.
+var ErrNotReleased = errors.New("sign: signature is not released yet")
+
+// MPCSign runs the sign protocol.
+//
+// The state of a session is held in the key managers, under the IDs of the session and of each party,
+// so its size grows with the number of signers. The handler verifies each message as it arrives and drops
+// the messages of a round once it is over, but the state itself is not yet laid out in slices indexed by party:
+// doing so changes the interfaces of the key managers, and is left to a separate change.
 type MPCSign struct {
 	signcfgmgr config.SignConfigManager
 	statmgr    state.MPCStateManager
@@ -62,6 +98,14 @@ type MPCSign struct {
 
 	sigma     result.SigmaStore
 	signature result.Signature
+
+	pins pin.PinStore
+
+	// chainKeys holds the chain keys generated at keygen, used to derive child keys.
+	chainKeys rid.RIDManager
+
+	// seen records the Kⱼ, Gⱼ ciphertexts received in the current epoch.
+	seen *bloom.Filter
 }
 
 func NewMPCSign(
@@ -112,20 +156,110 @@ func NewMPCSign(
 	}
 }
 
+// WithCiphertextFilter sets a filter recording the ciphertexts Kⱼ, Gⱼ received from other parties,
+// so that ciphertexts replayed across sessions are rejected.
+// The filter may be persisted with MarshalBinary, and should be reset at the start of each epoch.
+func (m *MPCSign) WithCiphertextFilter(f *bloom.Filter) *MPCSign {
+	m.seen = f
+	return m
+}
+
+// WithPinStore aborts sessions in which a party presents Paillier or Pedersen parameters
+// which differ from the ones pinned at keygen.
+func (m *MPCSign) WithPinStore(pins pin.PinStore) *MPCSign {
+	m.pins = pins
+	return m
+}
+
+// WithChainKeys sets the manager holding the chain keys generated at keygen,
+// which is required to sign with child keys (see config.SignConfig.DerivationPath).
+func (m *MPCSign) WithChainKeys(km rid.RIDManager) *MPCSign {
+	m.chainKeys = km
+	return m
+}
+
+// checkSigners returns ErrInvalidSigners if the signers are fewer than t+1, or if one of them does not hold a share of the key.
+func (m *MPCSign) checkSigners(vss vss.VssKey, signers party.IDSlice) error {
+	exponents, err := vss.ExponentsRaw()
+	if err != nil {
+		return err
+	}
+	if t := exponents.Degree(); len(signers) <= t {
+		return fmt.Errorf("%w: %d signers for threshold %d", ErrInvalidSigners, len(signers), t)
+	}
+	for _, j := range signers {
+		opts := keyopts.New().WithKeyID(hex.EncodeToString(vss.SKI())).WithPartyID(string(j))
+		if _, err := m.ec_vss.GetKey(opts); err != nil {
+			return fmt.Errorf("%w: %s holds no share of the key", ErrInvalidSigners, j)
+		}
+	}
+	return nil
+}
+
+// signVersion returns the version of the sign protocol set in cfg, defaulting to Version.
+func signVersion(cfg config.SignConfig) (round.Version, error) {
+	v := cfg.Version()
+	if v == 0 {
+		return Version, nil
+	}
+	for _, supported := range Versions {
+		if v == supported {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %d", ErrUnsupportedVersion, v)
+}
+
+// derive returns the scalar which, added to the key with public point public, yields its unhardened BIP32 child at path.
+func (m *MPCSign) derive(keyID string, group curve.Curve, public curve.Point, path []uint32) (curve.Scalar, error) {
+	if m.chainKeys == nil {
+		return nil, fmt.Errorf("%w: no chain keys", ErrInvalidDerivationPath)
+	}
+	point, ok := public.(*curve.Secp256k1Point)
+	if !ok {
+		return nil, fmt.Errorf("%w: derivation requires secp256k1", ErrInvalidDerivationPath)
+	}
+	opts := keyopts.New().WithKeyID(keyID).WithPartyID("ROOT")
+	chainKey, err := m.chainKeys.GetKey(opts)
+	if err != nil {
+		return nil, err
+	}
+	chaining := chainKey.Raw()
+	tweak := group.NewScalar()
+	for _, i := range path {
+		if i>>31 != 0 {
+			return nil, fmt.Errorf("%w: hardened index %d", ErrInvalidDerivationPath, i)
+		}
+		scalar, next, err := bip32.DeriveScalar(point, chaining, i)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDerivationPath, err)
+		}
+		tweak.Add(scalar)
+		point = point.Add(scalar.ActOnBase()).(*curve.Secp256k1Point)
+		chaining = next
+	}
+	return tweak, nil
+}
+
 func (m *MPCSign) StartSign(cfg config.SignConfig, pl *pool.Pool) protocol.StartFunc {
 	return func(sessionID []byte) (round.Session, error) {
+		version, err := signVersion(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("sign.Create: %w", err)
+		}
 		info := round.Info{
-			ProtocolID:       "cmp/sign",
+			ProtocolID:       protocolSignID,
 			FinalRoundNumber: 5,
+			Version:          version,
 			SelfID:           cfg.SelfID(),
 			PartyIDs:         cfg.PartyIDs(),
 			Threshold:        cfg.Threshold(),
 			Group:            cfg.Group(),
+			Profile:          cfg.Profile(),
 		}
 		group := info.Group
 
-		opts := keyopts.Options{}
-		opts.Set("id", cfg.ID(), "partyid", info.SelfID)
+		opts := keyopts.New().WithKeyID(cfg.ID()).WithPartyID(string(info.SelfID))
 
 		h := m.hash_mgr.NewHasher(cfg.ID(), opts)
 
@@ -134,45 +268,65 @@ func (m *MPCSign) StartSign(cfg config.SignConfig, pl *pool.Pool) protocol.Start
 			return nil, errors.New("sign.Create: message is nil")
 		}
 
-		helper, err := round.NewSession(cfg.ID(), info, sessionID, pl, h, types.SigningMessage(cfg.Message()))
+		// the path is bound to the session, so that signers given different paths abort
+		aux := []core_hash.WriterToWithDomain{types.SigningMessage(cfg.Message()), types.DerivationPath(cfg.DerivationPath())}
+		// the context is only hashed if set, so that existing SSIDs are unchanged
+		if len(cfg.Context()) > 0 {
+			aux = append(aux, types.SigningContext(cfg.Context()))
+		}
+		// as is the release time, so that all signers enforce the same one
+		if release := cfg.ReleaseTime(); !release.IsZero() {
+			aux = append(aux, types.ReleaseTime(release))
+		}
+		helper, err := round.NewSession(cfg.ID(), info, sessionID, pl, h, aux...)
 		if err != nil {
 			return nil, fmt.Errorf("sign.Create: %w", err)
 		}
 
-		// if !config.CanSign(helper.PartyIDs()) {
-		// 	return nil, errors.New("sign.Create: signers is not a valid signing subset")
-		// }
+		vssOpts := keyopts.New().WithKeyID(cfg.KeyID()).WithPartyID("ROOT")
+		vss, err := m.vss_mgr.GetSecrets(vssOpts)
+		if err != nil {
+			return nil, err
+		}
+		if err := m.checkSigners(vss, helper.PartyIDs()); err != nil {
+			return nil, fmt.Errorf("sign.Create: %w", err)
+		}
+
+		// the tweak of a child key is added to the share of a single signer, chosen the same way by all of them
+		var tweak curve.Scalar
+		if path := cfg.DerivationPath(); len(path) > 0 {
+			exponents, err := vss.ExponentsRaw()
+			if err != nil {
+				return nil, err
+			}
+			if tweak, err = m.derive(cfg.KeyID(), group, exponents.Constant(), path); err != nil {
+				return nil, fmt.Errorf("sign.Create: %w", err)
+			}
+		}
 
 		// Scale public data
 
 		lagrange := polynomial.Lagrange(group, cfg.PartyIDs())
 		clonedPubKey := info.Group.NewPoint()
 		for _, j := range helper.PartyIDs() {
-			vssOpts := keyopts.Options{}
-			vssOpts.Set("id", cfg.KeyID(), "partyid", "ROOT")
-			vss, err := m.vss_mgr.GetSecrets(vssOpts)
-			if err != nil {
-				return nil, err
-			}
-
-			partyVSSOpts := keyopts.Options{}
-			partyVSSOpts.Set("id", hex.EncodeToString(vss.SKI()), "partyid", string(j))
+			partyVSSOpts := keyopts.New().WithKeyID(hex.EncodeToString(vss.SKI())).WithPartyID(string(j))
 
 			vssShareKey, err := m.ec_vss.GetKey(partyVSSOpts)
 			if err != nil {
 				return nil, err
 			}
 
-			partyOpts := keyopts.Options{}
-			partyOpts.Set("id", cfg.ID(), "partyid", string(j))
+			partyOpts := keyopts.New().WithKeyID(cfg.ID()).WithPartyID(string(j))
 			clonedj := vssShareKey.CloneByMultiplier(lagrange[j])
+			if tweak != nil && j == helper.PartyIDs()[0] {
+				clonedj = clonedj.CloneByAdder(tweak)
+			}
 			if _, err := m.ec.ImportKey(clonedj, partyOpts); err != nil {
 				return nil, err
 			}
 			clonedPubKey = clonedPubKey.Add(clonedj.PublicKeyRaw())
 		}
-		rootECOpts := keyopts.Options{}
-		rootECOpts.Set("id", cfg.ID(), "partyid", "ROOT")
+		rootECOpts := keyopts.New().WithKeyID(cfg.ID()).WithPartyID("ROOT")
 		cloned := sw_ecdsa.NewECDSAKey(nil, clonedPubKey, info.Group)
 		if _, err := m.ec.ImportKey(cloned, rootECOpts); err != nil {
 			return nil, err
@@ -186,28 +340,89 @@ func (m *MPCSign) StartSign(cfg config.SignConfig, pl *pool.Pool) protocol.Start
 			return nil, err
 		}
 
-		return &round1{
-			Helper:      helper,
-			cfg:         cfg,
-			statemgr:    m.statmgr,
-			msgmgr:      m.msgmgr,
-			bcstmgr:     m.bcstmgr,
-			hash_mgr:    m.hash_mgr,
-			paillier_km: m.paillier_km,
-			pedersen_km: m.pedersen_km,
-			ec:          m.ec,
-			vss_mgr:     m.vss_mgr,
-			gamma:       m.gamma,
-			signK:       m.signK,
-			delta:       m.delta,
-			chi:         m.chi,
-			bigDelta:    m.bigDelta,
-			gamma_pek:   m.gamma_pek,
-			signK_pek:   m.signK_pek,
-			delta_mta:   m.delta_mta,
-			chi_mta:     m.chi_mta,
-			sigma:       m.sigma,
-			signature:   m.signature,
-		}, nil
+		return m.newRound1(helper, cfg), nil
+	}
+}
+
+// ResumeSign recreates the round reached by the sign session signID from the stores, so that a session
+// moved from another host can be continued with protocol.ResumeMultiHandler.
+func (m *MPCSign) ResumeSign(signID string, pl *pool.Pool) protocol.ResumeFunc {
+	return func(ssid []byte, number round.Number) (round.Session, error) {
+		cfg, err := m.signcfgmgr.GetConfig(signID)
+		if err != nil {
+			return nil, fmt.Errorf("sign.Resume: %w", err)
+		}
+		state, err := m.statmgr.Get(signID)
+		if err != nil {
+			return nil, fmt.Errorf("sign.Resume: %w", err)
+		}
+		// the stores must hold the state of the round before the resumed one, and not of a later one
+		if last := state.LastRound(); last != int(number)-1 || number < 2 || number > protocolSignRounds {
+			return nil, fmt.Errorf("sign.Resume: cannot resume round %d after round %d", number, last)
+		}
+
+		version, err := signVersion(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("sign.Resume: %w", err)
+		}
+		info := round.Info{
+			ProtocolID:       protocolSignID,
+			FinalRoundNumber: protocolSignRounds,
+			Version:          version,
+			SelfID:           cfg.SelfID(),
+			PartyIDs:         cfg.PartyIDs(),
+			Threshold:        cfg.Threshold(),
+			Group:            cfg.Group(),
+			Profile:          cfg.Profile(),
+		}
+		opts := keyopts.New().WithKeyID(cfg.ID()).WithPartyID(string(info.SelfID))
+		h, err := m.hash_mgr.RestoreHasher(cfg.ID(), opts)
+		if err != nil {
+			return nil, fmt.Errorf("sign.Resume: %w", err)
+		}
+		helper, err := round.ResumeSession(cfg.ID(), info, ssid, pl, h)
+		if err != nil {
+			return nil, fmt.Errorf("sign.Resume: %w", err)
+		}
+
+		r2 := &round2{round1: m.newRound1(helper, cfg)}
+		switch number {
+		case 2:
+			return r2, nil
+		case 3:
+			return &round3{round2: r2}, nil
+		case 4:
+			return &round4{round3: &round3{round2: r2}}, nil
+		default:
+			return &round5{round4: &round4{round3: &round3{round2: r2}}}, nil
+		}
+	}
+}
+
+func (m *MPCSign) newRound1(helper *round.Helper, cfg config.SignConfig) *round1 {
+	return &round1{
+		Helper:      helper,
+		cfg:         cfg,
+		statemgr:    m.statmgr,
+		msgmgr:      m.msgmgr,
+		bcstmgr:     m.bcstmgr,
+		hash_mgr:    m.hash_mgr,
+		paillier_km: m.paillier_km,
+		pedersen_km: m.pedersen_km,
+		ec:          m.ec,
+		vss_mgr:     m.vss_mgr,
+		gamma:       m.gamma,
+		signK:       m.signK,
+		delta:       m.delta,
+		chi:         m.chi,
+		bigDelta:    m.bigDelta,
+		gamma_pek:   m.gamma_pek,
+		signK_pek:   m.signK_pek,
+		delta_mta:   m.delta_mta,
+		chi_mta:     m.chi_mta,
+		sigma:       m.sigma,
+		signature:   m.signature,
+		seen:        m.seen,
+		pins:        m.pins,
 	}
 }
@@ -1,18 +1,30 @@
 package sign
 
 import (
+	"crypto/rand"
 	"fmt"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/cronokirby/saferith"
+	"github.com/fxamacker/cbor/v2"
 	"github.com/google/uuid"
+	"github.com/mr-shifu/mpc-lib/core/bloom"
+	core_ecdsa "github.com/mr-shifu/mpc-lib/core/ecdsa"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/sample"
+	core_paillier "github.com/mr-shifu/mpc-lib/core/paillier"
 	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/lib/params"
 	"github.com/mr-shifu/mpc-lib/lib/round"
 	"github.com/mr-shifu/mpc-lib/lib/test"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/keystore"
 	"github.com/mr-shifu/mpc-lib/pkg/vault"
+	cmp_config "github.com/mr-shifu/mpc-lib/protocols/cmp/config"
 	"github.com/mr-shifu/mpc-lib/protocols/cmp/keygen"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/sha3"
@@ -28,7 +40,10 @@ import (
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/rid"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/vss"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/config"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/common/pin"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/message"
+	mpc_pin "github.com/mr-shifu/mpc-lib/pkg/mpc/pin"
+	comm_result "github.com/mr-shifu/mpc-lib/pkg/mpc/common/result"
 	mpc_result "github.com/mr-shifu/mpc-lib/pkg/mpc/result"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/state"
 )
@@ -264,5 +279,427 @@ func TestSign(t *testing.T) {
 			break
 		}
 	}
+
+	// every signer records the same presignature, bound to the signers which computed it
+	presig := mpcsigns[partyIDs[0]].signature.Presignature(signID)
+	require.NotNil(t, presig)
+	require.True(t, presig.R.Equal(signRounds[0].(*round.Output).Result.(*core_ecdsa.Signature).R))
+	for _, partyID := range partyIDs {
+		p := mpcsigns[partyID].signature.Presignature(signID)
+		require.NotNil(t, p)
+		require.Equal(t, presig.ID, p.ID)
+		require.Equal(t, partyIDs, p.Signers)
+	}
+
+	// the same key signs with compact proofs
+	compactSignID := uuid.NewString()
+	compactRounds := make([]round.Session, 0, N)
+	for _, partyID := range partyIDs {
+		cfg := config.NewSignConfig(compactSignID, keyID, group, N-1, partyID, partyIDs, messageHash).SetVersion(VersionCompact)
+		r, err := mpcsigns[partyID].StartSign(cfg, pl)(nil)
+		require.NoError(t, err, "round creation should not result in an error")
+		compactRounds = append(compactRounds, r)
+	}
+	for {
+		err, done := test.Rounds(compactRounds, nil)
+		require.NoError(t, err, "failed to process round")
+		if done {
+			break
+		}
+	}
+	for _, r := range compactRounds {
+		require.IsType(t, &round.Output{}, r, "compact session should produce an output")
+	}
+
+	// the same key signs with the compact profile of the range proofs
+	profileRounds := make([]round.Session, 0, N)
+	for _, partyID := range partyIDs {
+		cfg := config.NewSignConfig(uuid.NewString(), keyID, group, N-1, partyID, partyIDs, messageHash).SetProfile(params.Compact)
+		r, err := mpcsigns[partyID].StartSign(cfg, pl)(nil)
+		require.NoError(t, err, "round creation should not result in an error")
+		profileRounds = append(profileRounds, r)
+	}
+	for {
+		err, done := test.Rounds(profileRounds, nil)
+		require.NoError(t, err, "failed to process round")
+		if done {
+			break
+		}
+	}
+	for _, r := range profileRounds {
+		require.IsType(t, &round.Output{}, r, "session with the compact profile should produce an output")
+	}
+	cfg := config.NewSignConfig(uuid.NewString(), keyID, group, N-1, partyIDs[0], partyIDs, messageHash).SetProfile(params.Profile{Epsilon: params.L})
+	_, err := mpcsigns[partyIDs[0]].StartSign(cfg, pl)(nil)
+	require.ErrorIs(t, err, params.ErrInvalidProfile)
+
+	// a coordinator aggregates the signature from the broadcasts it relays
+	key, err := mpcsigns[partyIDs[0]].ec.GetKey(keyopts.New().WithKeyID(keyID).WithPartyID("ROOT"))
+	require.NoError(t, err)
+	aggregatedSignID := uuid.NewString()
+	aggregatedRounds := make([]round.Session, 0, N)
+	for _, partyID := range partyIDs {
+		cfg := config.NewSignConfig(aggregatedSignID, keyID, group, N-1, partyID, partyIDs, messageHash)
+		r, err := mpcsigns[partyID].StartSign(cfg, pl)(nil)
+		require.NoError(t, err, "round creation should not result in an error")
+		aggregatedRounds = append(aggregatedRounds, r)
+	}
+	recorder := &broadcastRecorder{}
+	for {
+		err, done := test.Rounds(aggregatedRounds, recorder)
+		require.NoError(t, err, "failed to process round")
+		if done {
+			break
+		}
+	}
+	coordinator := protocol.NewCoordinator([]byte(aggregatedSignID), ProtocolID, partyIDs)
+	coordinator.SetAggregator(Aggregator(group, partyIDs, key.PublicKeyRaw(), messageHash))
+	go func() {
+		for range coordinator.Listen() {
+		}
+	}()
+	for _, msg := range recorder.msgs {
+		msg.SSID = []byte(aggregatedSignID)
+		require.NoError(t, coordinator.Accept(msg))
+	}
+	<-coordinator.Done()
+	aggregated, err := coordinator.Result()
+	require.NoError(t, err)
+	signature := aggregatedRounds[0].(*round.Output).Result.(*core_ecdsa.Signature)
+	require.True(t, signature.R.Equal(aggregated.(*core_ecdsa.Signature).R))
+	require.True(t, signature.S.Equal(aggregated.(*core_ecdsa.Signature).S))
+	coordinator.Stop()
+
+	// a partial signature which does not add up is rejected
+	recorder.msgs[len(recorder.msgs)-1].Data, err = cbor.Marshal(&broadcast5{SigmaShare: sample.Scalar(rand.Reader, group)})
+	require.NoError(t, err)
+	_, err = Aggregator(group, partyIDs, key.PublicKeyRaw(), messageHash)(recorder.broadcasts())
+	require.ErrorIs(t, err, ErrInvalidAggregate)
+
+	// a degenerate share is rejected as soon as it is decoded, in both versions of the protocol
+	for _, version := range []round.Version{Version, VersionCompact} {
+		degenerateRounds := make([]round.Session, 0, N)
+		for _, partyID := range partyIDs {
+			cfg := config.NewSignConfig(uuid.NewString(), keyID, group, N-1, partyID, partyIDs, messageHash).SetVersion(version)
+			r, err := mpcsigns[partyID].StartSign(cfg, pl)(nil)
+			require.NoError(t, err, "round creation should not result in an error")
+			degenerateRounds = append(degenerateRounds, r)
+		}
+		for {
+			err, done := test.Rounds(degenerateRounds, degenerateSigma{})
+			if err != nil {
+				require.ErrorIs(t, err, round.ErrDegenerate)
+				break
+			}
+			require.False(t, done, "a zero partial signature must be rejected")
+		}
+	}
+
+	// the signers refuse to release their shares before the release time
+	lockedRounds := make([]round.Session, 0, N)
+	release := time.Now().Add(time.Hour)
+	for _, partyID := range partyIDs {
+		cfg := config.NewSignConfig(uuid.NewString(), keyID, group, N-1, partyID, partyIDs, messageHash).SetReleaseTime(release)
+		r, err := mpcsigns[partyID].StartSign(cfg, pl)(nil)
+		require.NoError(t, err, "round creation should not result in an error")
+		lockedRounds = append(lockedRounds, r)
+	}
+	for {
+		err, done := test.Rounds(lockedRounds, nil)
+		require.NoError(t, err, "failed to process round")
+		if done {
+			break
+		}
+	}
+	for _, r := range lockedRounds {
+		require.IsType(t, &round.Abort{}, r, "locked session should abort")
+		require.ErrorIs(t, r.(*round.Abort).Err, ErrNotReleased)
+	}
+
+	// the signature is only completed by the signers of the presignature
+	reboundRounds := make([]round.Session, 0, N)
+	for _, partyID := range partyIDs {
+		cfg := config.NewSignConfig(uuid.NewString(), keyID, group, N-1, partyID, partyIDs, messageHash)
+		r, err := mpcsigns[partyID].StartSign(cfg, pl)(nil)
+		require.NoError(t, err, "round creation should not result in an error")
+		reboundRounds = append(reboundRounds, r)
+	}
+	for {
+		err, done := test.Rounds(reboundRounds, reboundPresignature{})
+		require.NoError(t, err, "failed to process round")
+		if done {
+			break
+		}
+	}
+	for _, r := range reboundRounds {
+		require.IsType(t, &round.Abort{}, r, "session with other signers than its presignature should abort")
+		require.ErrorIs(t, r.(*round.Abort).Err, ErrInvalidSigners)
+	}
+
+	cfg = config.NewSignConfig(uuid.NewString(), keyID, group, N-1, partyIDs[0], partyIDs, messageHash).SetVersion(Version + 7)
+	_, err = mpcsigns[partyIDs[0]].StartSign(cfg, pl)(nil)
+	require.ErrorIs(t, err, ErrUnsupportedVersion)
 	// checkOutput(t, rounds)
 }
+
+// degenerateSigma is a test.Rule replacing the partial signatures with zero.
+type degenerateSigma struct{}
+
+func (degenerateSigma) ModifyBefore(round.Session) {}
+
+func (degenerateSigma) ModifyAfter(round.Session) {}
+
+func (degenerateSigma) ModifyContent(rNext round.Session, _ party.ID, content round.Content) {
+	if b, ok := content.(*broadcast5); ok {
+		b.SigmaShare = rNext.Group().NewScalar()
+	}
+}
+
+// reboundPresignature is a test.Rule binding the presignature to a subset of the signers before the signing round.
+type reboundPresignature struct{}
+
+func (reboundPresignature) ModifyBefore(r round.Session) {
+	r5, ok := r.(*round5)
+	if !ok {
+		return
+	}
+	presig := *r5.signature.Presignature(r5.cfg.ID())
+	presig.Signers = presig.Signers[:1]
+	r5.signature.ImportPresignature(r5.cfg.ID(), &presig)
+}
+
+func (reboundPresignature) ModifyAfter(round.Session) {}
+
+func (reboundPresignature) ModifyContent(round.Session, party.ID, round.Content) {}
+
+func TestSignPresignatureSigners(t *testing.T) {
+	keyID := uuid.NewString()
+	group := curve.Secp256k1{}
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	N := 3
+	partyIDs := test.PartyIDs(N)
+	mpcsigns := make(map[party.ID]*MPCSign)
+	rounds := make([]round.Session, 0, N)
+	for _, partyID := range partyIDs {
+		mpckg, mpcSign := newMPC()
+		mpcsigns[partyID] = mpcSign
+		r, err := mpckg.Start(config.NewKeyConfig(keyID, group, 1, partyID, partyIDs), pl)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	for {
+		err, done := test.Rounds(rounds, nil)
+		require.NoError(t, err, "failed to process round")
+		if done {
+			break
+		}
+	}
+
+	sign := func(signID string, signers party.IDSlice, rule test.Rule) []round.Session {
+		signRounds := make([]round.Session, 0, len(signers))
+		for _, partyID := range signers {
+			cfg := config.NewSignConfig(signID, keyID, group, 1, partyID, signers, make([]byte, 32))
+			r, err := mpcsigns[partyID].StartSign(cfg, pl)(nil)
+			require.NoError(t, err)
+			signRounds = append(signRounds, r)
+		}
+		for {
+			err, done := test.Rounds(signRounds, rule)
+			require.NoError(t, err, "failed to process round")
+			if done {
+				break
+			}
+		}
+		return signRounds
+	}
+
+	// the presignature of a session binds the subset of signers which computed it
+	signID := uuid.NewString()
+	signers := party.NewIDSlice([]party.ID{partyIDs[0], partyIDs[1]})
+	for _, r := range sign(signID, signers, nil) {
+		require.IsType(t, &round.Output{}, r)
+	}
+	presig := mpcsigns[partyIDs[0]].signature.Presignature(signID)
+	require.NoError(t, presig.Verify(signers))
+
+	// it is refused by a session of another subset of signers
+	others := party.NewIDSlice([]party.ID{partyIDs[0], partyIDs[2]})
+	require.ErrorIs(t, presig.Verify(others), comm_result.ErrPresignatureSigners)
+	for _, r := range sign(uuid.NewString(), others, loadedPresignature{presig}) {
+		require.IsType(t, &round.Abort{}, r, "session loading the presignature of other signers should abort")
+		require.ErrorIs(t, r.(*round.Abort).Err, ErrInvalidSigners)
+	}
+}
+
+// loadedPresignature is a test.Rule loading the presignature of another session before the signing round.
+type loadedPresignature struct {
+	presig *comm_result.Presignature
+}
+
+func (l loadedPresignature) ModifyBefore(r round.Session) {
+	if r5, ok := r.(*round5); ok {
+		r5.signature.ImportPresignature(r5.cfg.ID(), l.presig)
+	}
+}
+
+func (loadedPresignature) ModifyAfter(round.Session) {}
+
+func (loadedPresignature) ModifyContent(round.Session, party.ID, round.Content) {}
+
+// broadcastRecorder is a test.Rule recording the broadcasts of a session, as a protocol.Coordinator would receive them.
+type broadcastRecorder struct {
+	mtx  sync.Mutex
+	msgs []*protocol.Message
+}
+
+func (b *broadcastRecorder) ModifyBefore(round.Session) {}
+
+func (b *broadcastRecorder) ModifyAfter(round.Session) {}
+
+func (b *broadcastRecorder) ModifyContent(rNext round.Session, _ party.ID, content round.Content) {
+	if _, ok := content.(round.BroadcastContent); !ok {
+		return
+	}
+	data, err := cbor.Marshal(content)
+	if err != nil {
+		panic(err)
+	}
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.msgs = append(b.msgs, &protocol.Message{
+		Protocol:    ProtocolID,
+		From:        rNext.SelfID(),
+		RoundNumber: content.RoundNumber(),
+		Broadcast:   true,
+		Data:        data,
+	})
+}
+
+func (b *broadcastRecorder) broadcasts() map[round.Number]map[party.ID]*protocol.Message {
+	broadcasts := map[round.Number]map[party.ID]*protocol.Message{}
+	for _, msg := range b.msgs {
+		if broadcasts[msg.RoundNumber] == nil {
+			broadcasts[msg.RoundNumber] = map[party.ID]*protocol.Message{}
+		}
+		broadcasts[msg.RoundNumber][msg.From] = msg
+	}
+	return broadcasts
+}
+
+func TestSignPins(t *testing.T) {
+	keyID := uuid.NewString()
+	group := curve.Secp256k1{}
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	N := 2
+	partyIDs := test.PartyIDs(N)
+	mpcsigns := make(map[party.ID]*MPCSign)
+	pins := make(map[party.ID]*mpc_pin.InMemoryPinStore)
+	rounds := make([]round.Session, 0, N)
+	for _, partyID := range partyIDs {
+		pins[partyID] = mpc_pin.NewInMemoryPinStore()
+		mpckg, mpcSign := newMPC()
+		mpcsigns[partyID] = mpcSign.WithPinStore(pins[partyID])
+		r, err := mpckg.WithPinStore(pins[partyID]).Start(config.NewKeyConfig(keyID, group, N-1, partyID, partyIDs), pl)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	for {
+		err, done := test.Rounds(rounds, nil)
+		require.NoError(t, err, "failed to process round")
+		if done {
+			break
+		}
+	}
+
+	sign := func() error {
+		signRounds := make([]round.Session, 0, N)
+		for _, partyID := range partyIDs {
+			cfg := config.NewSignConfig(uuid.NewString(), keyID, group, N-1, partyID, partyIDs, make([]byte, 32))
+			r, err := mpcsigns[partyID].StartSign(cfg, pl)(nil)
+			require.NoError(t, err)
+			signRounds = append(signRounds, r)
+		}
+		for {
+			err, done := test.Rounds(signRounds, nil)
+			if err != nil {
+				return err
+			}
+			if done {
+				break
+			}
+		}
+		for _, r := range signRounds {
+			require.IsType(t, &round.Output{}, r)
+		}
+		return nil
+	}
+
+	// the parameters stored for the signers are the pinned ones
+	require.NoError(t, sign())
+	// parameters stored for a signer which differ from its pin are refused
+	require.NoError(t, pins[partyIDs[0]].Reset(keyID))
+	require.NoError(t, pins[partyIDs[0]].Pin(keyID, partyIDs[1], make([]byte, 32)))
+	require.ErrorIs(t, sign(), pin.ErrAuxParamsChanged)
+}
+
+func TestDerive(t *testing.T) {
+	group := curve.Secp256k1{}
+	ksf := keystore.InmemoryKeystoreFactory{}
+	krf := keyopts.InMemoryKeyOptsFactory{}
+	vf := vault.InmemoryVaultFactory{}
+	chainKeys := rid.NewRIDManager(ksf.NewKeystore(vf.NewVault(nil), krf.NewKeyOpts(nil), nil))
+	m := (&MPCSign{}).WithChainKeys(chainKeys)
+
+	chainKey := make([]byte, params.SecBytes)
+	_, _ = rand.Read(chainKey)
+	opts := keyopts.New().WithKeyID("key").WithPartyID("ROOT")
+	_, err := chainKeys.ImportKey(chainKey, opts)
+	require.NoError(t, err)
+
+	secret := sample.Scalar(rand.Reader, group)
+	cfg := &cmp_config.Config{
+		Group:    group,
+		ID:       "a",
+		ECDSA:    secret,
+		ChainKey: chainKey,
+		Public:   map[party.ID]*cmp_config.Public{"a": {ECDSA: secret.ActOnBase()}},
+	}
+	child := cfg
+	for _, i := range []uint32{44, 0, 7} {
+		child, err = child.DeriveBIP32(i)
+		require.NoError(t, err)
+	}
+
+	tweak, err := m.derive("key", group, cfg.PublicPoint(), []uint32{44, 0, 7})
+	require.NoError(t, err)
+	require.True(t, group.NewScalar().Set(secret).Add(tweak).Equal(child.ECDSA))
+
+	_, err = m.derive("key", group, cfg.PublicPoint(), []uint32{1 << 31})
+	require.ErrorIs(t, err, ErrInvalidDerivationPath)
+	_, err = (&MPCSign{}).derive("key", group, cfg.PublicPoint(), []uint32{1})
+	require.ErrorIs(t, err, ErrInvalidDerivationPath)
+}
+
+func TestCheckNonceCiphertexts(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	pk, _ := core_paillier.KeyGen(pl)
+	enc := func() *core_paillier.Ciphertext {
+		ct, _ := pk.Enc(new(saferith.Int).SetUint64(1))
+		return ct
+	}
+	r := &round2{round1: &round1{seen: bloom.New(100, 0.001)}}
+
+	K, G := enc(), enc()
+	require.NoError(t, r.checkNonceCiphertexts(pk, K, G))
+	require.ErrorIs(t, r.checkNonceCiphertexts(pk, K, enc()), ErrReplayedCiphertext)
+
+	// a fresh Kⱼ sent with a replayed Gⱼ is not recorded, and can still be used
+	fresh := enc()
+	require.ErrorIs(t, r.checkNonceCiphertexts(pk, fresh, G), ErrReplayedCiphertext)
+	require.NoError(t, r.checkNonceCiphertexts(pk, fresh, enc()))
+}
@@ -1,6 +1,9 @@
 package frost
 
 import (
+	"encoding/hex"
+
+	"github.com/fxamacker/cbor/v2"
 	"github.com/mr-shifu/mpc-lib/core/pool"
 	"github.com/mr-shifu/mpc-lib/core/protocol"
 
@@ -15,6 +18,7 @@ import (
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/hash"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/rid"
 	vssed25519 "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/vss-ed25519"
+	mpc_keyopts "github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	comm_config "github.com/mr-shifu/mpc-lib/pkg/mpc/common/config"
 	comm_msg "github.com/mr-shifu/mpc-lib/pkg/mpc/common/message"
 	comm_result "github.com/mr-shifu/mpc-lib/pkg/mpc/common/result"
@@ -205,3 +209,71 @@ func (frost *FROST) Sign(cfg comm_config.SignConfig, pl *pool.Pool) protocol.Sta
 	sign := frost.NewMPCSignManager()
 	return sign.Start(cfg)
 }
+
+// ResumeSign recreates the round reached by the sign session signID, in order to continue it from a protocol.Snapshot
+// with protocol.ResumeMultiHandler.
+func (frost *FROST) ResumeSign(signID string) protocol.ResumeFunc {
+	return frost.NewMPCSignManager().Resume(signID)
+}
+
+// Share is the secret share of a party in a FROST key, as exported for backups.
+type Share struct {
+	// Config is the result of the keygen of the party.
+	Config *Config
+	// Exponents is the public VSS polynomial of the key, from which the public shares of all parties are derived.
+	Exponents []byte
+	// Secret is the share of the party followed by its public key, as encoded by ed25519.Ed25519.
+	Secret []byte
+}
+
+type rawShare struct {
+	Config    []byte
+	Exponents []byte
+	Secret    []byte
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (s *Share) MarshalBinary() ([]byte, error) {
+	config, err := s.Config.MarshalPublic()
+	if err != nil {
+		return nil, err
+	}
+	return cbor.Marshal(&rawShare{Config: config, Exponents: s.Exponents, Secret: s.Secret})
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (s *Share) UnmarshalBinary(data []byte) error {
+	var raw rawShare
+	if err := cbor.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	config := EmptyConfig()
+	if err := config.UnmarshalPublic(raw.Config); err != nil {
+		return err
+	}
+	*s = Share{Config: config, Exponents: raw.Exponents, Secret: raw.Secret}
+	return nil
+}
+
+// ExportShare returns the share of self in the key generated by the keygen of config, with ID keyID.
+// The share is secret, and must be encrypted before it leaves the node.
+func (frost *FROST) ExportShare(keyID string, config *Config) (*Share, error) {
+	vss, err := frost.vss_mgr.GetSecrets(mpc_keyopts.New().WithKeyID(keyID).WithPartyID("ROOT"))
+	if err != nil {
+		return nil, err
+	}
+	exponents, err := vss.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	shareOpts := mpc_keyopts.New().WithKeyID(hex.EncodeToString(vss.SKI())).WithPartyID(string(config.ID))
+	share, err := frost.ed_vss_km.GetKey(shareOpts)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := share.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return &Share{Config: config, Exponents: exponents, Secret: secret}, nil
+}
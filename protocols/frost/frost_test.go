@@ -1,15 +1,21 @@
 package frost
 
 import (
+	"crypto/rand"
 	"sync"
 	"testing"
 
+	"filippo.io/edwards25519"
+	"github.com/fxamacker/cbor/v2"
 	"github.com/google/uuid"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/core/pool"
 	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/lib/round"
 	"github.com/mr-shifu/mpc-lib/lib/test"
+	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/ed25519"
+	vssed25519 "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/vss-ed25519"
 	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/keystore"
 	"github.com/mr-shifu/mpc-lib/pkg/mpc/config"
@@ -45,6 +51,25 @@ func do(t *testing.T, id party.ID, ids []party.ID, threshold int, msg []byte, pl
 	require.IsType(t, &Config{}, r)
 	c := r.(*Config)
 
+	// the exported share is the evaluation of the VSS polynomial of the key at the party
+	share, err := frost.ExportShare(keyID, c)
+	require.NoError(t, err)
+	data, err := share.MarshalBinary()
+	require.NoError(t, err)
+	decoded := &Share{}
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	require.Equal(t, 1, decoded.Config.PublicKey.Equal(c.PublicKey))
+	secret := new(ed25519.Ed25519Impl)
+	require.NoError(t, secret.FromBytes(decoded.Secret))
+	require.True(t, secret.Private())
+	vss := new(vssed25519.VssKeyImpl)
+	require.NoError(t, vss.FromBytes(decoded.Exponents))
+	index, err := id.Ed25519Scalar()
+	require.NoError(t, err)
+	public, err := vss.EvaluateByExponents(index)
+	require.NoError(t, err)
+	require.Equal(t, 1, public.Equal(secret.PublickeyPoint()))
+
 	signID := uuid.New().String()
 	signcfg := config.NewSignConfig(signID, keyID, curve.Secp256k1{}, threshold, id, ids, msg)
 	frost.Sign(signcfg, pl)
@@ -80,3 +105,620 @@ func TestFROST(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestFROSTSnapshot(t *testing.T) {
+	ids := test.PartyIDs(3)
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	keyID, signID := uuid.New().String(), uuid.New().String()
+	frosts := make(map[party.ID]*FROST, len(ids))
+	for _, id := range ids {
+		frosts[id] = NewFROST(
+			&keystore.InmemoryKeystoreFactory{},
+			&keyopts.InMemoryKeyOptsFactory{},
+			&vault.InmemoryVaultFactory{},
+			config.NewInMemoryConfigStore(),
+			config.NewInMemoryConfigStore(),
+			state.NewInMemoryStateStore(),
+			state.NewInMemoryStateStore(),
+			message.NewInMemoryMessageStore(),
+			message.NewInMemoryMessageStore(),
+			pl,
+		)
+	}
+
+	// run runs the sessions of handlers until no message is left, after delivering pending
+	run := func(handlers map[party.ID]*protocol.MultiHandler, pending []*protocol.Message) {
+		for {
+			for _, id := range ids {
+				msgs, _ := protocol.DrainMessages(handlers[id])
+				pending = append(pending, msgs...)
+			}
+			if len(pending) == 0 {
+				return
+			}
+			for _, msg := range pending {
+				for _, id := range ids {
+					if msg.IsFor(id) {
+						handlers[id].Accept(msg)
+					}
+				}
+			}
+			pending = nil
+		}
+	}
+
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(ids))
+	for _, id := range ids {
+		h, err := protocol.NewMultiHandler(frosts[id].Keygen(config.NewKeyConfig(keyID, curve.Secp256k1{}, 1, id, ids), pl), nil)
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+	run(handlers, nil)
+	for _, id := range ids {
+		_, err := handlers[id].Result()
+		require.NoError(t, err)
+	}
+
+	msg := []byte("hello")
+	for _, id := range ids {
+		h, err := protocol.NewMultiHandler(frosts[id].Sign(config.NewSignConfig(signID, keyID, curve.Secp256k1{}, 1, id, ids, msg), pl), nil)
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+	var pending []*protocol.Message
+	for _, id := range ids {
+		msgs, _ := protocol.DrainMessages(handlers[id])
+		pending = append(pending, msgs...)
+	}
+	// the first party receives a single message of round 2, before moving to another host
+	for _, m := range pending {
+		if m.From == ids[1] && m.IsFor(ids[0]) {
+			handlers[ids[0]].Accept(m)
+		}
+	}
+	snapshot, err := handlers[ids[0]].Snapshot()
+	require.NoError(t, err)
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	data, err := snapshot.Encrypt(key)
+	require.NoError(t, err)
+
+	_, err = protocol.DecryptSnapshot(data, make([]byte, 32))
+	require.ErrorIs(t, err, protocol.ErrInvalidSnapshot)
+	restored, err := protocol.DecryptSnapshot(data, key)
+	require.NoError(t, err)
+	handlers[ids[0]], err = protocol.ResumeMultiHandler(frosts[ids[0]].ResumeSign(signID), restored)
+	require.NoError(t, err)
+
+	// messages delivered before the snapshot are ignored as duplicates
+	run(handlers, pending)
+	for _, id := range ids {
+		r, err := handlers[id].Result()
+		require.NoError(t, err)
+		require.IsType(t, &result.EddsaSignature{}, r)
+	}
+
+	// the stores have moved on, so the snapshot cannot be resumed again
+	_, err = protocol.ResumeMultiHandler(frosts[ids[0]].ResumeSign(signID), restored)
+	require.Error(t, err)
+}
+
+// election is a protocol.Election whose leader is set by the test.
+type election struct {
+	leader string
+	term   uint64
+}
+
+func (e *election) Leader() (string, uint64, error) { return e.leader, e.term, nil }
+
+func TestFROSTFailover(t *testing.T) {
+	ids := test.PartyIDs(3)
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	keyID, signID := uuid.New().String(), uuid.New().String()
+	frosts := make(map[party.ID]*FROST, len(ids))
+	for _, id := range ids {
+		frosts[id] = NewFROST(
+			&keystore.InmemoryKeystoreFactory{},
+			&keyopts.InMemoryKeyOptsFactory{},
+			&vault.InmemoryVaultFactory{},
+			config.NewInMemoryConfigStore(),
+			config.NewInMemoryConfigStore(),
+			state.NewInMemoryStateStore(),
+			state.NewInMemoryStateStore(),
+			message.NewInMemoryMessageStore(),
+			message.NewInMemoryMessageStore(),
+			pl,
+		)
+	}
+	run := func(handlers map[party.ID]*protocol.MultiHandler, pending []*protocol.Message) {
+		for {
+			for _, id := range ids {
+				msgs, _ := protocol.DrainMessages(handlers[id])
+				pending = append(pending, msgs...)
+			}
+			if len(pending) == 0 {
+				return
+			}
+			for _, msg := range pending {
+				for _, id := range ids {
+					if msg.IsFor(id) {
+						handlers[id].Accept(msg)
+					}
+				}
+			}
+			pending = nil
+		}
+	}
+
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(ids))
+	for _, id := range ids {
+		h, err := protocol.NewMultiHandler(frosts[id].Keygen(config.NewKeyConfig(keyID, curve.Secp256k1{}, 1, id, ids), pl), nil)
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+	run(handlers, nil)
+
+	// the first party runs two replicas sharing its stores
+	elected := &election{leader: "active", term: 1}
+	store := protocol.NewInMemoryCheckpointStore()
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	active := protocol.NewFailover("active", elected, store, key)
+	passive := protocol.NewFailover("passive", elected, store, key)
+
+	msg := []byte("hello")
+	for _, id := range ids {
+		h, err := protocol.NewMultiHandler(frosts[id].Sign(config.NewSignConfig(signID, keyID, curve.Secp256k1{}, 1, id, ids, msg), pl), nil)
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+	require.NoError(t, active.Register(signID, handlers[ids[0]]))
+	_, err := passive.TakeOver(frosts[ids[0]].ResumeSign)
+	require.ErrorIs(t, err, protocol.ErrNotLeader)
+
+	var pending []*protocol.Message
+	for _, id := range ids {
+		msgs, _ := protocol.DrainMessages(handlers[id])
+		pending = append(pending, msgs...)
+	}
+	// the active replica receives a single message, and crashes before its own messages are delivered
+	var delivered []*protocol.Message
+	for _, m := range pending {
+		switch {
+		case m.From == ids[1] && m.IsFor(ids[0]):
+			require.NoError(t, active.Accept(m))
+		case m.From != ids[0]:
+			delivered = append(delivered, m)
+		}
+	}
+	require.NoError(t, active.Checkpoint())
+
+	elected.leader, elected.term = "passive", 2
+	resumed, err := passive.TakeOver(frosts[ids[0]].ResumeSign)
+	require.NoError(t, err)
+	require.Len(t, resumed, 1)
+	require.ErrorIs(t, active.Accept(pending[0]), protocol.ErrNotLeader)
+	require.ErrorIs(t, active.Checkpoint(), protocol.ErrNotLeader)
+	_, err = handlers[ids[0]].Result()
+	require.ErrorIs(t, err, protocol.ErrNotLeader)
+
+	handlers[ids[0]] = resumed[0]
+	run(handlers, delivered)
+	for _, id := range ids {
+		r, err := handlers[id].Result()
+		require.NoError(t, err)
+		require.IsType(t, &result.EddsaSignature{}, r)
+	}
+	require.NoError(t, passive.Checkpoint())
+	checkpoints, err := store.List()
+	require.NoError(t, err)
+	require.Empty(t, checkpoints)
+
+	// the leader crashes once its messages of the second round were delivered, without calling Checkpoint:
+	// the session was checkpointed before they were sent, so they are sent again as they were, not computed anew
+	signID = uuid.New().String()
+	for _, id := range ids {
+		h, err := protocol.NewMultiHandler(frosts[id].Sign(config.NewSignConfig(signID, keyID, curve.Secp256k1{}, 1, id, ids, msg), pl), nil)
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+	require.NoError(t, passive.Register(signID, handlers[ids[0]]))
+	pending = nil
+	for _, id := range ids {
+		msgs, _ := protocol.DrainMessages(handlers[id])
+		pending = append(pending, msgs...)
+	}
+	for _, m := range pending {
+		for _, id := range ids {
+			if m.IsFor(id) {
+				handlers[id].Accept(m)
+			}
+		}
+	}
+	sent := map[string]bool{}
+	delivered = nil
+	for _, id := range ids {
+		msgs, _ := protocol.DrainMessages(handlers[id])
+		for _, m := range msgs {
+			if m.From == ids[0] {
+				sent[string(m.Hash())] = true
+			}
+		}
+		delivered = append(delivered, msgs...)
+	}
+	require.NotEmpty(t, sent)
+
+	elected.leader, elected.term = "active", 3
+	resumed, err = active.TakeOver(frosts[ids[0]].ResumeSign)
+	require.NoError(t, err)
+	require.Len(t, resumed, 1)
+	resent, _ := protocol.DrainMessages(resumed[0])
+	n := 0
+	for _, m := range resent {
+		if !m.IsResend() {
+			require.True(t, sent[string(m.Hash())], "message of round %d computed anew", m.RoundNumber)
+			n++
+		}
+	}
+	require.Equal(t, len(sent), n)
+
+	handlers[ids[0]] = resumed[0]
+	run(handlers, delivered)
+	for _, id := range ids {
+		r, err := handlers[id].Result()
+		require.NoError(t, err)
+		require.IsType(t, &result.EddsaSignature{}, r)
+	}
+}
+
+func TestFROSTConcurrentAccept(t *testing.T) {
+	ids := test.PartyIDs(4)
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	keyID := uuid.New().String()
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(ids))
+	for _, id := range ids {
+		frost := NewFROST(
+			&keystore.InmemoryKeystoreFactory{},
+			&keyopts.InMemoryKeyOptsFactory{},
+			&vault.InmemoryVaultFactory{},
+			config.NewInMemoryConfigStore(),
+			config.NewInMemoryConfigStore(),
+			state.NewInMemoryStateStore(),
+			state.NewInMemoryStateStore(),
+			message.NewInMemoryMessageStore(),
+			message.NewInMemoryMessageStore(),
+			pl,
+		)
+		h, err := protocol.NewMultiHandler(frost.Keygen(config.NewKeyConfig(keyID, curve.Secp256k1{}, 2, id, ids), pl), nil)
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+
+	// every message is delivered from its own goroutine, as a transport with one connection per peer would
+	for {
+		var pending []*protocol.Message
+		for _, id := range ids {
+			msgs, _ := protocol.DrainMessages(handlers[id])
+			pending = append(pending, msgs...)
+		}
+		if len(pending) == 0 {
+			break
+		}
+		var wg sync.WaitGroup
+		for _, msg := range pending {
+			for _, id := range ids {
+				if !msg.IsFor(id) {
+					continue
+				}
+				wg.Add(1)
+				go func(h *protocol.MultiHandler, msg *protocol.Message) {
+					defer wg.Done()
+					if h.CanAccept(msg) {
+						h.Accept(msg)
+					}
+				}(handlers[id], msg)
+			}
+		}
+		wg.Wait()
+	}
+
+	for _, id := range ids {
+		r, err := handlers[id].Result()
+		require.NoError(t, err)
+		require.IsType(t, &Config{}, r)
+	}
+}
+
+func TestFROSTCollectAllErrors(t *testing.T) {
+	ids := test.PartyIDs(3)
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	keyID := uuid.New().String()
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(ids))
+	for _, id := range ids {
+		frost := NewFROST(
+			&keystore.InmemoryKeystoreFactory{},
+			&keyopts.InMemoryKeyOptsFactory{},
+			&vault.InmemoryVaultFactory{},
+			config.NewInMemoryConfigStore(),
+			config.NewInMemoryConfigStore(),
+			state.NewInMemoryStateStore(),
+			state.NewInMemoryStateStore(),
+			message.NewInMemoryMessageStore(),
+			message.NewInMemoryMessageStore(),
+			pl,
+		)
+		h, err := protocol.NewMultiHandler(frost.Keygen(config.NewKeyConfig(keyID, curve.Secp256k1{}, 1, id, ids), pl), nil)
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+	handlers[ids[0]].SetVerificationPolicy(protocol.CollectAllErrors)
+
+	// both other parties send garbage to the first one in round 2
+	for {
+		var pending []*protocol.Message
+		for _, id := range ids {
+			msgs, _ := protocol.DrainMessages(handlers[id])
+			pending = append(pending, msgs...)
+		}
+		if len(pending) == 0 {
+			break
+		}
+		for _, msg := range pending {
+			for _, id := range ids {
+				if !msg.IsFor(id) {
+					continue
+				}
+				delivered := msg
+				if id == ids[0] && msg.RoundNumber == 2 {
+					corrupted := *msg
+					corrupted.Data = []byte{0xff}
+					delivered = &corrupted
+				}
+				if handlers[id].CanAccept(delivered) {
+					handlers[id].Accept(delivered)
+				}
+			}
+		}
+	}
+
+	_, err := handlers[ids[0]].Result()
+	var protocolErr protocol.Error
+	require.ErrorAs(t, err, &protocolErr)
+	require.ElementsMatch(t, []party.ID{ids[1], ids[2]}, protocolErr.Culprits)
+	require.Equal(t, protocol.CodeInvalidMessage, protocol.Code(err))
+}
+
+func TestFROSTMemoryBudget(t *testing.T) {
+	ids := test.PartyIDs(3)
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	keygen := func(budget int) map[party.ID]*protocol.MultiHandler {
+		keyID := uuid.New().String()
+		handlers := make(map[party.ID]*protocol.MultiHandler, len(ids))
+		for _, id := range ids {
+			frost := NewFROST(
+				&keystore.InmemoryKeystoreFactory{},
+				&keyopts.InMemoryKeyOptsFactory{},
+				&vault.InmemoryVaultFactory{},
+				config.NewInMemoryConfigStore(),
+				config.NewInMemoryConfigStore(),
+				state.NewInMemoryStateStore(),
+				state.NewInMemoryStateStore(),
+				message.NewInMemoryMessageStore(),
+				message.NewInMemoryMessageStore(),
+				pl,
+			)
+			h, err := protocol.NewMultiHandler(frost.Keygen(config.NewKeyConfig(keyID, curve.Secp256k1{}, 1, id, ids), pl), nil)
+			require.NoError(t, err)
+			h.SetMemoryBudget(budget)
+			handlers[id] = h
+		}
+		for {
+			var pending []*protocol.Message
+			for _, id := range ids {
+				msgs, _ := protocol.DrainMessages(handlers[id])
+				pending = append(pending, msgs...)
+			}
+			if len(pending) == 0 {
+				return handlers
+			}
+			for _, msg := range pending {
+				for _, id := range ids {
+					if msg.IsFor(id) && handlers[id].CanAccept(msg) {
+						handlers[id].Accept(msg)
+					}
+				}
+			}
+		}
+	}
+
+	// a budget large enough for the session does not change its outcome
+	for _, h := range keygen(1 << 20) {
+		_, err := h.Result()
+		require.NoError(t, err)
+		usage := h.MemoryUsage()
+		require.Positive(t, usage.Total())
+		require.LessOrEqual(t, usage.Total(), 1<<20)
+	}
+
+	// a budget too small for the messages of the second round aborts the session
+	for _, h := range keygen(64) {
+		_, err := h.Result()
+		require.ErrorIs(t, err, protocol.ErrMemoryBudget)
+		require.Equal(t, protocol.CodeResourceExhausted, protocol.Code(err))
+	}
+}
+
+// wireDelegate is a protocol.RoundDelegate sending the rounds and messages of delegate over the wire, encoded with cbor.
+type wireDelegate struct {
+	t        *testing.T
+	delegate protocol.RoundDelegate
+}
+
+func (d *wireDelegate) Start(sessionID []byte) (*protocol.DelegatedRound, error) {
+	dr, err := d.delegate.Start(sessionID)
+	return d.decode(dr), err
+}
+
+func (d *wireDelegate) Store(ssid []byte, msg *protocol.DelegatedMessage) error {
+	return d.delegate.Store(ssid, msg)
+}
+
+func (d *wireDelegate) Finalize(ssid []byte, number round.Number) (*protocol.DelegatedRound, []*protocol.DelegatedMessage, error) {
+	dr, msgs, err := d.delegate.Finalize(ssid, number)
+	return d.decode(dr), msgs, err
+}
+
+func (d *wireDelegate) decode(dr *protocol.DelegatedRound) *protocol.DelegatedRound {
+	if dr == nil {
+		return nil
+	}
+	data, err := cbor.Marshal(dr)
+	require.NoError(d.t, err)
+	decoded := &protocol.DelegatedRound{}
+	require.NoError(d.t, cbor.Unmarshal(data, decoded))
+	return decoded
+}
+
+func TestFROSTDelegated(t *testing.T) {
+	N := 3
+	ids := test.PartyIDs(N)
+	n := test.NewNetwork(ids)
+	keyID := uuid.New().String()
+
+	var (
+		wg         sync.WaitGroup
+		mtx        sync.Mutex
+		publicKeys = make(map[party.ID]*edwards25519.Point, N)
+	)
+	wg.Add(N)
+	for _, id := range ids {
+		pl := pool.NewPool(1)
+		defer pl.TearDown()
+		go func(id party.ID) {
+			defer wg.Done()
+			frost := NewFROST(
+				&keystore.InmemoryKeystoreFactory{},
+				&keyopts.InMemoryKeyOptsFactory{},
+				&vault.InmemoryVaultFactory{},
+				config.NewInMemoryConfigStore(),
+				config.NewInMemoryConfigStore(),
+				state.NewInMemoryStateStore(),
+				state.NewInMemoryStateStore(),
+				message.NewInMemoryMessageStore(),
+				message.NewInMemoryMessageStore(),
+				pl,
+			)
+			// the rounds are computed by the delegate, and the handler only moves the messages
+			delegate := &wireDelegate{t: t, delegate: protocol.NewLocalDelegate(frost.Keygen(config.NewKeyConfig(keyID, curve.Secp256k1{}, N-1, id, ids), pl))}
+			h, err := protocol.NewMultiHandler(protocol.DelegatedSession(delegate, pl), nil)
+			require.NoError(t, err)
+			test.HandlerLoop(id, h, n)
+			r, err := h.Result()
+			require.NoError(t, err)
+			// the node only gets the public part of the config
+			require.IsType(t, &protocol.DelegatedResult{}, r)
+			cfg := EmptyConfig()
+			require.NoError(t, cfg.UnmarshalPublic(r.(*protocol.DelegatedResult).Public))
+			mtx.Lock()
+			publicKeys[id] = cfg.PublicKey
+			mtx.Unlock()
+			require.Equal(t, id, cfg.ID)
+		}(id)
+	}
+	wg.Wait()
+	for _, id := range ids {
+		require.Equal(t, 1, publicKeys[id].Equal(publicKeys[ids[0]]), "parties must agree on the public key")
+	}
+}
+
+func TestFROSTSigningContext(t *testing.T) {
+	ids := test.PartyIDs(3)
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	keyID := uuid.New().String()
+	frosts := make(map[party.ID]*FROST, len(ids))
+	for _, id := range ids {
+		frosts[id] = NewFROST(
+			&keystore.InmemoryKeystoreFactory{},
+			&keyopts.InMemoryKeyOptsFactory{},
+			&vault.InmemoryVaultFactory{},
+			config.NewInMemoryConfigStore(),
+			config.NewInMemoryConfigStore(),
+			state.NewInMemoryStateStore(),
+			state.NewInMemoryStateStore(),
+			message.NewInMemoryMessageStore(),
+			message.NewInMemoryMessageStore(),
+			pl,
+		)
+	}
+
+	run := func(handlers map[party.ID]*protocol.MultiHandler) {
+		for {
+			var pending []*protocol.Message
+			for _, id := range ids {
+				msgs, _ := protocol.DrainMessages(handlers[id])
+				pending = append(pending, msgs...)
+			}
+			if len(pending) == 0 {
+				return
+			}
+			for _, msg := range pending {
+				for _, id := range ids {
+					if msg.IsFor(id) && handlers[id].CanAccept(msg) {
+						handlers[id].Accept(msg)
+					}
+				}
+			}
+		}
+	}
+
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(ids))
+	for _, id := range ids {
+		h, err := protocol.NewMultiHandler(frosts[id].Keygen(config.NewKeyConfig(keyID, curve.Secp256k1{}, 1, id, ids), pl), nil)
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+	run(handlers)
+
+	// sign runs a session in which the first party is given context first, and the others context others
+	sign := func(first, others string) map[party.ID]*protocol.MultiHandler {
+		signID := uuid.New().String()
+		for i, id := range ids {
+			cfg := config.NewSignConfig(signID, keyID, curve.Secp256k1{}, 1, id, ids, []byte("hello"))
+			if i == 0 {
+				cfg.SetContext(first)
+			} else {
+				cfg.SetContext(others)
+			}
+			h, err := protocol.NewMultiHandler(frosts[id].Sign(cfg, pl), nil)
+			require.NoError(t, err)
+			handlers[id] = h
+		}
+		run(handlers)
+		return handlers
+	}
+
+	// a party signing for another application cannot take part in the session
+	for _, h := range sign("wallet/v1", "payments/v1") {
+		_, err := h.Result()
+		require.Error(t, err)
+	}
+
+	signed := sign("payments/v1", "payments/v1")
+	for _, id := range ids {
+		r, err := signed[id].Result()
+		require.NoError(t, err)
+		require.IsType(t, &result.EddsaSignature{}, r)
+	}
+}
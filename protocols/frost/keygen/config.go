@@ -2,10 +2,14 @@ package keygen
 
 import (
 	"filippo.io/edwards25519"
+	"github.com/fxamacker/cbor/v2"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
 )
 
+var _ protocol.PublicResult = (*Config)(nil)
+
 // Config contains all the information produced after key generation, from the perspective
 // of a single participant.
 //
@@ -36,3 +40,33 @@ func EmptyConfig() *Config {
 func (r *Config) Curve() curve.Curve {
 	return nil
 }
+
+type rawConfig struct {
+	ID        party.ID
+	Threshold int
+	PublicKey []byte
+}
+
+// MarshalPublic implements protocol.PublicResult. The config holds no secret, the share of the participant
+// being kept in its keystore.
+func (r *Config) MarshalPublic() ([]byte, error) {
+	return cbor.Marshal(&rawConfig{
+		ID:        r.ID,
+		Threshold: r.Threshold,
+		PublicKey: r.PublicKey.Bytes(),
+	})
+}
+
+// UnmarshalPublic decodes a config encoded with MarshalPublic.
+func (r *Config) UnmarshalPublic(data []byte) error {
+	var raw rawConfig
+	if err := cbor.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	publicKey, err := new(edwards25519.Point).SetBytes(raw.PublicKey)
+	if err != nil {
+		return err
+	}
+	*r = Config{ID: raw.ID, Threshold: raw.Threshold, PublicKey: publicKey}
+	return nil
+}
@@ -0,0 +1,26 @@
+package keygen
+
+import (
+	ed "filippo.io/edwards25519"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/math/polynomial-ed25519"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+)
+
+func init() {
+	round.Register(round.Contents, round.ContentKey{Protocol: KEYGEN_THRESHOLD_PROTOCOL, Round: 2, Broadcast: true}, newBroadcast2)
+	round.Register(round.Contents, round.ContentKey{Protocol: KEYGEN_THRESHOLD_PROTOCOL, Round: 3}, newMessage3)
+	round.Register(round.Contents, round.ContentKey{Protocol: KEYGEN_THRESHOLD_PROTOCOL, Round: 3, Broadcast: true}, newBroadcast3)
+}
+
+// The contents are over edwards25519 whatever the group.
+
+func newBroadcast2(curve.Curve, round.Version) *broadcast2 {
+	return &broadcast2{VSSPolynomial: new(polynomial.Polynomial)}
+}
+
+func newMessage3(curve.Curve, round.Version) *message3 {
+	return &message3{VSSShare: ed.NewScalar()}
+}
+
+func newBroadcast3(curve.Curve, round.Version) *broadcast3 { return &broadcast3{} }
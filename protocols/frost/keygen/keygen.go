@@ -22,8 +22,14 @@ import (
 const (
 	Rounds                    round.Number = 3
 	KEYGEN_THRESHOLD_PROTOCOL string       = "frost/keygen-threshold"
+	// Version is the current version of the keygen protocol.
+	Version round.Version = 1
 )
 
+// ErrUnexpectedPublicKey is returned when the public key resulting from keygen differs from
+// the ExpectedEd25519PublicKey set in the config.
+var ErrUnexpectedPublicKey = errors.New("keygen: public key differs from the expected public key")
+
 type FROSTKeygen struct {
 	configmgr   config.KeyConfigManager
 	statemgr    mpc_state.MPCStateManager
@@ -83,6 +89,7 @@ func (m *FROSTKeygen) Start(configs any) protocol.StartFunc {
 			Threshold:        cfg.Threshold(),
 			Group:            cfg.Group(),
 			FinalRoundNumber: Rounds,
+			Version:          Version,
 		}
 
 		if err := m.configmgr.ImportConfig(cfg); err != nil {
@@ -90,10 +97,7 @@ func (m *FROSTKeygen) Start(configs any) protocol.StartFunc {
 		}
 
 		// instantiate a new hasher for new keygen session
-		opts, err := keyopts.NewOptions().Set("id", cfg.ID(), "partyid", string(info.SelfID))
-		if err != nil {
-			return nil, errors.WithMessage(err, "keygen: failed to set options")
-		}
+		opts := keyopts.New().WithKeyID(cfg.ID()).WithPartyID(string(info.SelfID))
 		h := m.hash_mgr.NewHasher(cfg.ID(), opts)
 
 		// generate new helper for new keygen session
@@ -134,10 +138,10 @@ func (m *FROSTKeygen) GetRound(keyID string) (round.Session, error) {
 		Threshold:        cfg.Threshold(),
 		Group:            cfg.Group(),
 		FinalRoundNumber: Rounds,
+		Version:          Version,
 	}
 	// instantiate a new hasher for new keygen session
-	opts := keyopts.Options{}
-	opts.Set("id", cfg.ID(), "partyid", string(info.SelfID))
+	opts := keyopts.New().WithKeyID(cfg.ID()).WithPartyID(string(info.SelfID))
 	h := m.hash_mgr.NewHasher(cfg.ID(), opts)
 
 	// generate new helper for new keygen session
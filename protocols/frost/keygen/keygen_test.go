@@ -4,12 +4,16 @@ import (
 	"fmt"
 	"testing"
 
+	ed "filippo.io/edwards25519"
+	"github.com/fxamacker/cbor/v2"
 	"github.com/google/uuid"
 	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
 	"github.com/mr-shifu/mpc-lib/core/pool"
 	"github.com/mr-shifu/mpc-lib/core/protocol"
 	"github.com/mr-shifu/mpc-lib/lib/round"
 	"github.com/mr-shifu/mpc-lib/lib/test"
+	com_keyopts "github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/commitment"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/ed25519"
 	"github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/hash"
@@ -120,3 +124,105 @@ func TestKeygen(t *testing.T) {
 		}
 	}
 }
+
+func TestKeygenExpectedPublicKey(t *testing.T) {
+	group := curve.Secp256k1{}
+	N := 3
+	partyIDs := test.PartyIDs(N)
+
+	// run starts a keygen and returns the final rounds, calling beforeLast with the configs before the last round.
+	run := func(beforeLast func(kgs []protocol.Processor, keyID string, cfgs []*config.KeyConfig)) []round.Session {
+		keyID := uuid.NewString()
+		kgs := make([]protocol.Processor, 0, N)
+		cfgs := make([]*config.KeyConfig, 0, N)
+		for _, partyID := range partyIDs {
+			cfg := config.NewKeyConfig(keyID, group, N-1, partyID, partyIDs)
+			mpckg := newFROSTKeygen()
+			kgs = append(kgs, mpckg)
+			cfgs = append(cfgs, cfg)
+			_, err := mpckg.Start(cfg)(nil)
+			require.NoError(t, err)
+		}
+		for {
+			r, err := kgs[0].GetRound(keyID)
+			require.NoError(t, err)
+			if r.Number() == Rounds {
+				beforeLast(kgs, keyID, cfgs)
+			}
+			rounds, done, err := test.FROSTRounds(kgs, keyID)
+			require.NoError(t, err)
+			if done {
+				return rounds
+			}
+		}
+	}
+
+	// the public key is the sum of the constant exponents of all parties, known once they were all received
+	rounds := run(func(kgs []protocol.Processor, keyID string, cfgs []*config.KeyConfig) {
+		optsList := make([]com_keyopts.Options, 0, N)
+		for _, j := range partyIDs {
+			optsList = append(optsList, keyopts.New().WithKeyID(keyID).WithPartyID(string(j)))
+		}
+		sum, err := kgs[0].(*FROSTKeygen).vss_mgr.SumExponents(optsList...)
+		require.NoError(t, err)
+		exponents, err := sum.ExponentsRaw()
+		require.NoError(t, err)
+		for _, cfg := range cfgs {
+			cfg.SetExpectedEd25519PublicKey(exponents.Constant())
+		}
+	})
+	for _, r := range rounds {
+		require.IsType(t, &round.Output{}, r)
+	}
+
+	rounds = run(func(_ []protocol.Processor, _ string, cfgs []*config.KeyConfig) {
+		for _, cfg := range cfgs {
+			cfg.SetExpectedEd25519PublicKey(ed.NewGeneratorPoint())
+		}
+	})
+	for _, r := range rounds {
+		require.IsType(t, &round.Abort{}, r)
+		require.ErrorIs(t, r.(*round.Abort).Err, ErrUnexpectedPublicKey)
+	}
+}
+
+func FuzzRound2Broadcast(f *testing.F) {
+	keyID := uuid.NewString()
+
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	N := 3
+	partyIDs := test.PartyIDs(N)
+
+	kgs := make([]protocol.Processor, 0, N)
+	for _, partyID := range partyIDs {
+		cfg := config.NewKeyConfig(keyID, group, N-1, partyID, partyIDs)
+		mpckg := newFROSTKeygen()
+		kgs = append(kgs, mpckg)
+		_, err := mpckg.Start(cfg)(nil)
+		require.NoError(f, err)
+	}
+
+	// seed the corpus with the genuine messages of the other parties
+	out := make(chan *round.Message, N*(N+1))
+	for _, kg := range kgs {
+		_, err := kg.Finalize(out, keyID)
+		require.NoError(f, err)
+	}
+	close(out)
+	for msg := range out {
+		if msg.Broadcast && msg.From != partyIDs[0] {
+			data, err := cbor.Marshal(msg.Content)
+			require.NoError(f, err)
+			f.Add(string(msg.From), data)
+		}
+	}
+
+	r, err := kgs[0].GetRound(keyID)
+	require.NoError(f, err)
+	f.Fuzz(func(t *testing.T, from string, data []byte) {
+		_ = round.FuzzBroadcastMessage(r, party.ID(from), data)
+	})
+}
@@ -42,13 +42,10 @@ func (r *round1) StoreMessage(round.Message) error { return nil }
 // Finalize implements round.Round
 func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// ToDo maybe we can include create options into helper
-	opts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(r.SelfID()))
-	if err != nil {
-		return r, fmt.Errorf("frost.Keygen.Round1: failed to create options")
-	}
+	opts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(r.SelfID()))
 
 	// 1, Generate a new EC Key Pair
-	_, err = r.ed_km.GenerateKey(opts)
+	_, err := r.ed_km.GenerateKey(opts)
 	if err != nil {
 		return r, fmt.Errorf("frost.Keygen.Round1: failed to generate EC key pair")
 	}
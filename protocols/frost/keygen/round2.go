@@ -44,19 +44,16 @@ type round2 struct {
 // StoreBroadcastMessage implements round.BroadcastRound.
 func (r *round2) StoreBroadcastMessage(msg round.Message) error {
 	from := msg.From
-	body, ok := msg.Content.(*broadcast2)
-	if !ok || body == nil {
-		return round.ErrInvalidContent
+	body, err := round.ContentAs[*broadcast2](msg)
+	if err != nil {
+		return err
 	}
 
-	if body.VSSPolynomial == nil {
+	if body.VSSPolynomial == nil || body.VSSPolynomial.Degree() != r.Threshold() {
 		return errors.New("frost.Keygen.Round2: invalid VSS polynomial")
 	}
 
-	fromOpts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(from))
-	if err != nil {
-		return errors.New("frost.Keygen.Round2: failed to create options")
-	}
+	fromOpts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(from))
 
 	// validate commitment and import it to commitment store
 	if err := body.Commitment.Validate(); err != nil {
@@ -120,10 +117,7 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 		return nil, round.ErrNotEnoughMessages
 	}
 
-	opts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(r.SelfID()))
-	if err != nil {
-		return nil, errors.New("frost.Keygen.Round2: failed to create options")
-	}
+	opts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(r.SelfID()))
 
 	// 1. Get ChainKey from commitment store
 	chainKey, err := r.chainKey_km.GetKey(opts)
@@ -172,10 +166,7 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 			if err != nil {
 				return nil, err
 			}
-			vssOpts, err := keyopts.NewOptions().Set("id", hex.EncodeToString(vssKey.SKI()), "partyid", string(r.SelfID()))
-			if err != nil {
-				return nil, errors.New("frost.Keygen.Round2: failed to create options")
-			}
+			vssOpts := keyopts.New().WithKeyID(hex.EncodeToString(vssKey.SKI())).WithPartyID(string(r.SelfID()))
 			if _, err := r.ed_vss_km.ImportKey(shareKey, vssOpts); err != nil {
 				return nil, err
 			}
@@ -216,10 +207,7 @@ func (r *round2) CanFinalize() bool {
 
 // BroadcastContent implements round.BroadcastRound.
 func (r *round2) BroadcastContent() round.BroadcastContent {
-	return &broadcast2{
-		VSSPolynomial: new(polynomial.Polynomial),
-		// SchnorrProof:  r.Group().NewScalar(),
-	}
+	return r.NewBroadcastContent(r.Number())
 }
 
 // MessageContent implements round.Round.
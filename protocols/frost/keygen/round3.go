@@ -6,6 +6,7 @@ import (
 
 	ed "filippo.io/edwards25519"
 	"github.com/mr-shifu/mpc-lib/core/hash"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
 	"github.com/mr-shifu/mpc-lib/lib/round"
 	"github.com/mr-shifu/mpc-lib/lib/types"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/commitment"
@@ -47,13 +48,12 @@ type round3 struct {
 // StoreBroadcastMessage implements round.BroadcastRound.
 func (r *round3) StoreBroadcastMessage(msg round.Message) error {
 	from := msg.From
-	body, ok := msg.Content.(*broadcast3)
-	if !ok || body == nil {
-		return round.ErrInvalidContent
+	body, err := round.ContentAs[*broadcast3](msg)
+	if err != nil {
+		return err
 	}
 
-	fromOpts := keyopts.Options{}
-	fromOpts.Set("id", r.ID, "partyid", string(from))
+	fromOpts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(from))
 
 	// 1. Validate ChainKey and Decommitment
 	if err := body.ChainKey.Validate(); err != nil {
@@ -99,24 +99,19 @@ func (r *round3) StoreBroadcastMessage(msg round.Message) error {
 
 // VerifyMessage implements round.Round.
 func (r *round3) VerifyMessage(msg round.Message) error {
-	body, ok := msg.Content.(*message3)
-	if !ok || body == nil {
-		return round.ErrInvalidContent
-	}
-
-	// check nil
-	if body.VSSShare == nil {
-		return round.ErrNilFields
-	}
-
-	return nil
+	_, err := round.ContentAs[*message3](msg)
+	return err
 }
 
 // StoreMessage implements round.Round.
 //
 // Verify the VSS condition here since we will not be sending this message to other parties for verification.
 func (r *round3) StoreMessage(msg round.Message) error {
-	from, body := msg.From, msg.Content.(*message3)
+	from := msg.From
+	body, err := round.ContentAs[*message3](msg)
+	if err != nil {
+		return err
+	}
 
 	// These steps come from Figure 1, Round 2 of the Frost paper
 
@@ -125,10 +120,7 @@ func (r *round3) StoreMessage(msg round.Message) error {
 	//   fₗ(i) * G =? ∑ₖ₌₀ᵗ (iᵏ mod q) * ϕₗₖ
 	//
 	// aborting if the check fails."
-	fromOpts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(from))
-	if err != nil {
-		return errors.New("frost.Keygen.Round2: failed to create options")
-	}
+	fromOpts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(from))
 
 	// 1. Verify VSS share against exponents evaluation
 	expected := new(ed.Point).ScalarBaseMult(body.VSSShare)
@@ -149,10 +141,7 @@ func (r *round3) StoreMessage(msg round.Message) error {
 	if err != nil {
 		return err
 	}
-	vssOpts, err := keyopts.NewOptions().Set("id", hex.EncodeToString(vss.SKI()), "partyid", string(r.SelfID()))
-	if err != nil {
-		return errors.New("frost.Keygen.Round2: failed to create options")
-	}
+	vssOpts := keyopts.New().WithKeyID(hex.EncodeToString(vss.SKI())).WithPartyID(string(r.SelfID()))
 	ed_vss, err := ed25519.NewKey(body.VSSShare, expected)
 	if err != nil {
 		return err
@@ -178,18 +167,12 @@ func (r *round3) Finalize(chan<- *round.Message) (round.Session, error) {
 		return nil, round.ErrNotEnoughMessages
 	}
 
-	rootOpts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", "ROOT")
-	if err != nil {
-		return nil, errors.New("frost.Keygen.Round3: failed to create options")
-	}
+	rootOpts := keyopts.New().WithKeyID(r.ID).WithPartyID("ROOT")
 
 	// 1. XOR all chainKeys to get the group chainKey
 	chainKey := types.EmptyRID()
 	for _, j := range r.PartyIDs() {
-		partyOpts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(j))
-		if err != nil {
-			return nil, errors.New("frost.Keygen.Round3: failed to create options")
-		}
+		partyOpts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(j))
 		ck, err := r.chainKey_km.GetKey(partyOpts)
 		if err != nil {
 			return nil, err
@@ -203,10 +186,7 @@ func (r *round3) Finalize(chan<- *round.Message) (round.Session, error) {
 	// 2. Sum all VSS Exponents Shares to generate MPC VSS Exponent and Import it to VSS Keystore
 	vssOptsList := make([]com_keyopts.Options, 0)
 	for _, partyID := range r.PartyIDs() {
-		partyOpts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(partyID))
-		if err != nil {
-			return nil, errors.New("frost.Keygen.Round3: failed to create options")
-		}
+		partyOpts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(partyID))
 		vssOptsList = append(vssOptsList, partyOpts)
 	}
 	rootVss, err := r.vss_mgr.SumExponents(vssOptsList...)
@@ -224,6 +204,18 @@ func (r *round3) Finalize(chan<- *round.Message) (round.Session, error) {
 		return nil, err
 	}
 	pubKey := exponents.Constant()
+
+	// abort if the public key differs from the one we expected
+	cfg, err := r.configmgr.GetConfig(r.ID)
+	if err != nil {
+		return nil, err
+	}
+	if expected := cfg.ExpectedEd25519PublicKey(); expected != nil && expected.Equal(pubKey) != 1 {
+		if err := r.statemgr.SetAborted(r.ID); err != nil {
+			return r, err
+		}
+		return r.AbortRound(ErrUnexpectedPublicKey), nil
+	}
 	key, err := ed25519.NewKey(nil, pubKey)
 	if err != nil {
 		return nil, err
@@ -235,20 +227,14 @@ func (r *round3) Finalize(chan<- *round.Message) (round.Session, error) {
 	// 4. Sum all VSS self shares to generate MPC VSS Share
 	optsList := make([]com_keyopts.Options, 0)
 	for _, j := range r.PartyIDs() {
-		partyOpts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(j))
-		if err != nil {
-			return nil, errors.New("frost.Keygen.Round3: failed to create options")
-		}
+		partyOpts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(j))
 
 		vss, err := r.vss_mgr.GetSecrets(partyOpts)
 		if err != nil {
 			return nil, err
 		}
 
-		vssOpts, err := keyopts.NewOptions().Set("id", hex.EncodeToString(vss.SKI()), "partyid", string(r.SelfID()))
-		if err != nil {
-			return nil, errors.New("frost.Keygen.Round3: failed to create options")
-		}
+		vssOpts := keyopts.New().WithKeyID(hex.EncodeToString(vss.SKI())).WithPartyID(string(r.SelfID()))
 		optsList = append(optsList, vssOpts)
 	}
 	// ToDo Verify
@@ -256,19 +242,13 @@ func (r *round3) Finalize(chan<- *round.Message) (round.Session, error) {
 	if err != nil {
 		return nil, err
 	}
-	rootVssOpts, err := keyopts.NewOptions().Set("id", hex.EncodeToString(rootVss.SKI()), "partyid", string(r.SelfID()))
-	if err != nil {
-		return nil, errors.New("frost.Keygen.Round3: failed to create options")
-	}
+	rootVssOpts := keyopts.New().WithKeyID(hex.EncodeToString(rootVss.SKI())).WithPartyID(string(r.SelfID()))
 	if _, err := r.ed_vss_km.ImportKey(vssShareKey, rootVssOpts); err != nil {
 		return nil, err
 	}
 
 	for _, j := range r.OtherPartyIDs() {
-		vssPartyOpts, err := keyopts.NewOptions().Set("id", hex.EncodeToString(vssPoly.SKI()), "partyid", string(j))
-		if err != nil {
-			return nil, errors.New("frost.Keygen.Round3: failed to create options")
-		}
+		vssPartyOpts := keyopts.New().WithKeyID(hex.EncodeToString(vssPoly.SKI())).WithPartyID(string(j))
 
 		jScalar, err := j.Ed25519Scalar()
 		if err != nil {
@@ -313,14 +293,12 @@ func (r *round3) CanFinalize() bool {
 
 // BroadcastContent implements round.BroadcastRound.
 func (r *round3) BroadcastContent() round.BroadcastContent {
-	return &broadcast3{}
+	return r.NewBroadcastContent(r.Number())
 }
 
 // MessageContent implements round.Round.
 func (r *round3) MessageContent() round.Content {
-	return &message3{
-		VSSShare: ed.NewScalar(),
-	}
+	return r.NewMessageContent(r.Number())
 }
 
 // Number implements round.Round.
@@ -337,7 +315,7 @@ func (msg *message3) MarshalBinary() ([]byte, error) {
 }
 
 func (msg *message3) UnmarshalBinary(data []byte) error {
-	s, err := ed.NewScalar().SetCanonicalBytes(data)
+	s, err := curve.DecodeEd25519Scalar(data)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,25 @@
+package sign
+
+import (
+	"filippo.io/edwards25519"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+)
+
+func init() {
+	round.Register(round.Contents, round.ContentKey{Protocol: SIGN_CONFIG_PROTOCOL_ID, Round: 2, Broadcast: true}, newBroadcast2)
+	round.Register(round.Contents, round.ContentKey{Protocol: SIGN_CONFIG_PROTOCOL_ID, Round: 3, Broadcast: true}, newBroadcast3)
+}
+
+// The contents are over edwards25519 whatever the group.
+
+func newBroadcast2(curve.Curve, round.Version) *broadcast2 {
+	return &broadcast2{
+		D: new(edwards25519.Point),
+		E: new(edwards25519.Point),
+	}
+}
+
+func newBroadcast3(curve.Curve, round.Version) *broadcast3 {
+	return &broadcast3{Z: edwards25519.NewScalar()}
+}
@@ -53,14 +53,8 @@ const deriveHashKeyContext = "Derive hash Key"
 
 // Finalize implements round.Round.
 func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
-	opts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(r.SelfID()))
-	if err != nil {
-		return r, errors.New("frost.Sign.Round1: failed to create options")
-	}
-	kopts, err := keyopts.NewOptions().Set("id", r.cfg.KeyID(), "partyid", string(r.SelfID()))
-	if err != nil {
-		return r, errors.New("frost.Sign.Round1: failed to create options")
-	}
+	opts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(r.SelfID()))
+	kopts := keyopts.New().WithKeyID(r.cfg.KeyID()).WithPartyID(string(r.SelfID()))
 
 	k, err := r.eddsa_km.GetKey(kopts)
 	if err != nil {
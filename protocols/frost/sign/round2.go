@@ -56,19 +56,16 @@ type broadcast2 struct {
 
 // StoreBroadcastMessage implements round.BroadcastRound.
 func (r *round2) StoreBroadcastMessage(msg round.Message) error {
-	body, ok := msg.Content.(*broadcast2)
-	if !ok || body == nil {
-		return round.ErrInvalidContent
+	body, err := round.ContentAs[*broadcast2](msg)
+	if err != nil {
+		return err
 	}
 
 	if body.D.Equal(edwards25519.NewIdentityPoint()) == 1 || body.E.Equal(edwards25519.NewIdentityPoint()) == 1 {
 		return errors.New("nonce commitment is the identity point")
 	}
 
-	opts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(msg.From))
-	if err != nil {
-		return errors.New("frost.sign.Round2: failed to set options")
-	}
+	opts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(msg.From))
 
 	// store D params as EC Key into EC keystore
 	dk, err := ed25519.NewKey(nil, body.D)
@@ -112,10 +109,7 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 	Ds := make(map[party.ID]*edwards25519.Point)
 	Es := make(map[party.ID]*edwards25519.Point)
 	for _, l := range r.PartyIDs() {
-		opts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(l))
-		if err != nil {
-			return nil, errors.New("frost.sign.Round2: failed to set options")
-		}
+		opts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(l))
 		dk, err := r.sign_d.GetKey(opts)
 		if err != nil {
 			return r, err
@@ -154,10 +148,7 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 		RShares[l] = new(edwards25519.Point).ScalarMult(rho[l], Es[l])
 		RShares[l].Add(RShares[l], Ds[l])
 
-		opts_l, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(l))
-		if err != nil {
-			return nil, errors.New("frost.sign.Round2: failed to set options")
-		}
+		opts_l := keyopts.New().WithKeyID(r.ID).WithPartyID(string(l))
 		if err := r.sigmgr.Import(r.sigmgr.NewEddsaSignature(RShares[l], nil), opts_l); err != nil {
 			return r, nil
 		}
@@ -168,19 +159,13 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 		}
 		R.Add(R, RShares[l])
 	}
-	rootOpts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", "ROOT")
-	if err != nil {
-		return nil, errors.New("frost.sign.Round2: failed to set options")
-	}
+	rootOpts := keyopts.New().WithKeyID(r.ID).WithPartyID("ROOT")
 	if err := r.sigmgr.Import(r.sigmgr.NewEddsaSignature(R, nil), rootOpts); err != nil {
 		return r, nil
 	}
 
 	// 3. Generate a random number as commitment to the nonce
-	kopts, err := keyopts.NewOptions().Set("id", r.cfg.KeyID(), "partyid", "ROOT")
-	if err != nil {
-		return nil, errors.New("frost.sign.Round2: failed to set options")
-	}
+	kopts := keyopts.New().WithKeyID(r.cfg.KeyID()).WithPartyID("ROOT")
 	edKey, err := r.eddsa_km.GetKey(kopts)
 	if err != nil {
 		return r, err
@@ -197,10 +182,7 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 	}
 
 	// 4. Compute zᵢ = dᵢ + (eᵢ ρᵢ) + λᵢ sᵢ c
-	sopts, err := keyopts.NewOptions().Set("id", r.cfg.ID(), "partyid", string(r.SelfID()))
-	if err != nil {
-		return nil, errors.New("frost.sign.Round2: failed to set options")
-	}
+	sopts := keyopts.New().WithKeyID(r.cfg.ID()).WithPartyID(string(r.SelfID()))
 	ek, err := r.sign_e.GetKey(sopts)
 	if err != nil {
 		return r, err
@@ -289,10 +271,7 @@ func (msg *broadcast2) UnmarshalBinary(b []byte) error {
 
 // BroadcastContent implements round.BroadcastRound.
 func (r *round2) BroadcastContent() round.BroadcastContent {
-	return &broadcast2{
-		D: new(edwards25519.Point),
-		E: new(edwards25519.Point),
-	}
+	return r.NewBroadcastContent(r.Number())
 }
 
 // Number implements round.Round.
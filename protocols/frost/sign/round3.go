@@ -6,7 +6,7 @@ import (
 
 	"filippo.io/edwards25519"
 	"github.com/mr-shifu/mpc-lib/core/eddsa"
-	"github.com/pkg/errors"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
 
 	"github.com/mr-shifu/mpc-lib/lib/round"
 	"github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
@@ -44,30 +44,16 @@ type round3 struct {
 // StoreBroadcastMessage implements round.BroadcastRound.
 func (r *round3) StoreBroadcastMessage(msg round.Message) error {
 	from := msg.From
-	body, ok := msg.Content.(*broadcast3)
-	if !ok || body == nil {
-		return round.ErrInvalidContent
-	}
-
-	// check nil
-	if body.Z == nil {
-		return round.ErrNilFields
-	}
-
-	kopts, err := keyopts.NewOptions().Set("id", r.cfg.KeyID(), "partyid", "ROOT")
+	body, err := round.ContentAs[*broadcast3](msg)
 	if err != nil {
-		return errors.New("forst.sign.Round3: failed to set options")
+		return err
 	}
 
-	sopts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(msg.From))
-	if err != nil {
-		return errors.New("forst.sign.Round3: failed to set options")
-	}
+	kopts := keyopts.New().WithKeyID(r.cfg.KeyID()).WithPartyID("ROOT")
 
-	rootOpts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", "ROOT")
-	if err != nil {
-		return errors.New("forst.sign.Round3: failed to set options")
-	}
+	sopts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(msg.From))
+
+	rootOpts := keyopts.New().WithKeyID(r.ID).WithPartyID("ROOT")
 
 	// 1. Reproduce c random number as commitment to the nonce
 	rootSig, err := r.sigmgr.Get(rootOpts)
@@ -132,20 +118,14 @@ func (r *round3) Finalize(chan<- *round.Message) (round.Session, error) {
 	// 1. Compute the group's response z = ∑ᵢ zᵢ
 	z := edwards25519.NewScalar()
 	for _, l := range r.PartyIDs() {
-		opts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", string(l))
-		if err != nil {
-			return nil, errors.New("forst.sign.Round3: failed to set options")
-		}
+		opts := keyopts.New().WithKeyID(r.ID).WithPartyID(string(l))
 		sig, err := r.sigmgr.Get(opts)
 		if err != nil {
 			return r.AbortRound(err), nil
 		}
 		z.Add(z, sig.Z())
 	}
-	rootOpts, err := keyopts.NewOptions().Set("id", r.ID, "partyid", "ROOT")
-	if err != nil {
-		return nil, errors.New("forst.sign.Round3: failed to set options")
-	}
+	rootOpts := keyopts.New().WithKeyID(r.ID).WithPartyID("ROOT")
 	if err := r.sigmgr.SetZ(z, rootOpts); err != nil {
 		return r, nil
 	}
@@ -201,11 +181,7 @@ func (msg *broadcast3) MarshalBinary() ([]byte, error) {
 }
 
 func (msg *broadcast3) UnmarshalBinary(data []byte) error {
-	if len(data) != 32 {
-		return errors.New("invalid data length")
-	}
-
-	z, err := edwards25519.NewScalar().SetCanonicalBytes(data)
+	z, err := curve.DecodeEd25519Scalar(data)
 	if err != nil {
 		return err
 	}
@@ -217,9 +193,7 @@ func (msg *broadcast3) UnmarshalBinary(data []byte) error {
 
 // BroadcastContent implements round.BroadcastRound.
 func (r *round3) BroadcastContent() round.BroadcastContent {
-	return &broadcast3{
-		Z: edwards25519.NewScalar(),
-	}
+	return r.NewBroadcastContent(r.Number())
 }
 
 // Number implements round.Round.
@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"fmt"
 
+	core_hash "github.com/mr-shifu/mpc-lib/core/hash"
 	"github.com/mr-shifu/mpc-lib/core/math/polynomial-ed25519"
 	"github.com/mr-shifu/mpc-lib/core/pool"
 	"github.com/mr-shifu/mpc-lib/core/protocol"
@@ -25,6 +26,8 @@ const (
 	SIGN_CONFIG_PROTOCOL_ID = "frost/sign-threshold"
 	// This protocol has 3 concrete rounds.
 	protocolRounds round.Number = 3
+	// Version is the current version of the sign protocol.
+	Version round.Version = 1
 )
 
 type FROSTSign struct {
@@ -86,16 +89,14 @@ func (f *FROSTSign) Start(configs any) protocol.StartFunc {
 		info := round.Info{
 			ProtocolID:       SIGN_CONFIG_PROTOCOL_ID,
 			FinalRoundNumber: protocolRounds,
+			Version:          Version,
 			SelfID:           cfg.SelfID(),
 			PartyIDs:         cfg.PartyIDs(),
 			Threshold:        cfg.Threshold(),
 			Group:            cfg.Group(),
 		}
 
-		opts, err := keyopts.NewOptions().Set("id", cfg.ID(), "partyid", info.SelfID)
-		if err != nil {
-			return nil, errors.New("frost_sign: failed to set options")
-		}
+		opts := keyopts.New().WithKeyID(cfg.ID()).WithPartyID(string(info.SelfID))
 
 		h := f.hash_mgr.NewHasher(cfg.ID(), opts)
 
@@ -105,7 +106,12 @@ func (f *FROSTSign) Start(configs any) protocol.StartFunc {
 		}
 
 		// create a new helper
-		helper, err := round.NewSession(cfg.ID(), info, sessionID, f.pl, h, types.SigningMessage(cfg.Message()))
+		aux := []core_hash.WriterToWithDomain{types.SigningMessage(cfg.Message())}
+		// the context is only hashed if set, so that existing SSIDs are unchanged
+		if len(cfg.Context()) > 0 {
+			aux = append(aux, types.SigningContext(cfg.Context()))
+		}
+		helper, err := round.NewSession(cfg.ID(), info, sessionID, f.pl, h, aux...)
 		if err != nil {
 			return nil, fmt.Errorf("sign.StartSign: %w", err)
 		}
@@ -116,29 +122,20 @@ func (f *FROSTSign) Start(configs any) protocol.StartFunc {
 			return nil, err
 		}
 		for _, j := range helper.PartyIDs() {
-			vssOpts, err := keyopts.NewOptions().Set("id", cfg.KeyID(), "partyid", "ROOT")
-			if err != nil {
-				return nil, errors.New("frost_sign: failed to set options")
-			}
+			vssOpts := keyopts.New().WithKeyID(cfg.KeyID()).WithPartyID("ROOT")
 			vss, err := f.vss_mgr.GetSecrets(vssOpts)
 			if err != nil {
 				return nil, err
 			}
 
-			partyVSSOpts, err := keyopts.NewOptions().Set("id", hex.EncodeToString(vss.SKI()), "partyid", string(j))
-			if err != nil {
-				return nil, errors.New("frost_sign: failed to set options")
-			}
+			partyVSSOpts := keyopts.New().WithKeyID(hex.EncodeToString(vss.SKI())).WithPartyID(string(j))
 
 			vssShareKey, err := f.ed_vss_km.GetKey(partyVSSOpts)
 			if err != nil {
 				return nil, err
 			}
 
-			partyOpts, err := keyopts.NewOptions().Set("id", cfg.ID(), "partyid", string(j))
-			if err != nil {
-				return nil, errors.New("frost_sign: failed to set options")
-			}
+			partyOpts := keyopts.New().WithKeyID(cfg.ID()).WithPartyID(string(j))
 			clonedj := vssShareKey.Multiply(lagrange[j])
 			if err != nil {
 				return nil, err
@@ -156,21 +153,70 @@ func (f *FROSTSign) Start(configs any) protocol.StartFunc {
 			return nil, err
 		}
 
-		return &round1{
-			Helper:     helper,
-			cfg:        cfg,
-			statemgr:   f.statemgr,
-			sigmgr:     f.sigmgr,
-			msgmgr:     f.msgmgr,
-			bcstmgr:    f.bcstmgr,
-			eddsa_km:   f.eddsa_km,
-			ed_vss_km:  f.ed_vss_km,
-			ed_sign_km: f.ed_sign_km,
-			vss_mgr:    f.vss_mgr,
-			sign_d:     f.sign_d,
-			sign_e:     f.sign_e,
-			hash_mgr:   f.hash_mgr,
-		}, nil
+		return f.newRound1(helper, cfg), nil
+	}
+}
+
+// Resume recreates the round reached by the sign session signID from the stores, so that a session
+// moved from another host can be continued with protocol.ResumeMultiHandler.
+func (f *FROSTSign) Resume(signID string) protocol.ResumeFunc {
+	return func(ssid []byte, number round.Number) (round.Session, error) {
+		cfg, err := f.signcfgmgr.GetConfig(signID)
+		if err != nil {
+			return nil, errors.WithMessage(err, "frost_sign: failed to get config")
+		}
+		state, err := f.statemgr.Get(signID)
+		if err != nil {
+			return nil, errors.WithMessage(err, "frost_sign: failed to get state")
+		}
+		// the stores must hold the state of the round before the resumed one, and not of a later one
+		if last := state.LastRound(); last != int(number)-1 || number < 2 || number > protocolRounds {
+			return nil, fmt.Errorf("frost_sign: cannot resume round %d after round %d", number, last)
+		}
+
+		info := round.Info{
+			ProtocolID:       SIGN_CONFIG_PROTOCOL_ID,
+			FinalRoundNumber: protocolRounds,
+			Version:          Version,
+			SelfID:           cfg.SelfID(),
+			PartyIDs:         cfg.PartyIDs(),
+			Threshold:        cfg.Threshold(),
+			Group:            cfg.Group(),
+		}
+		opts := keyopts.New().WithKeyID(cfg.ID()).WithPartyID(string(info.SelfID))
+		h, err := f.hash_mgr.RestoreHasher(cfg.ID(), opts)
+		if err != nil {
+			return nil, errors.WithMessage(err, "frost_sign: failed to restore hash")
+		}
+		helper, err := round.ResumeSession(cfg.ID(), info, ssid, f.pl, h)
+		if err != nil {
+			return nil, fmt.Errorf("frost_sign: %w", err)
+		}
+
+		// all rounds hold the same fields
+		r1 := f.newRound1(helper, cfg)
+		if number == 2 {
+			return (*round2)(r1), nil
+		}
+		return (*round3)(r1), nil
+	}
+}
+
+func (f *FROSTSign) newRound1(helper *round.Helper, cfg config.SignConfig) *round1 {
+	return &round1{
+		Helper:     helper,
+		cfg:        cfg,
+		statemgr:   f.statemgr,
+		sigmgr:     f.sigmgr,
+		msgmgr:     f.msgmgr,
+		bcstmgr:    f.bcstmgr,
+		eddsa_km:   f.eddsa_km,
+		ed_vss_km:  f.ed_vss_km,
+		ed_sign_km: f.ed_sign_km,
+		vss_mgr:    f.vss_mgr,
+		sign_d:     f.sign_d,
+		sign_e:     f.sign_e,
+		hash_mgr:   f.hash_mgr,
 	}
 }
 
@@ -187,12 +233,10 @@ func (f *FROSTSign) GetRound(signID string) (round.Session, error) {
 		Threshold:        cfg.Threshold(),
 		Group:            cfg.Group(),
 		FinalRoundNumber: protocolRounds,
+		Version:          Version,
 	}
 	// instantiate a new hasher for new sign session
-	opts, err := keyopts.NewOptions().Set("id", cfg.ID(), "partyid", string(info.SelfID))
-	if err != nil {
-		return nil, errors.New("frost_sign: failed to set options")
-	}
+	opts := keyopts.New().WithKeyID(cfg.ID()).WithPartyID(string(info.SelfID))
 	h := f.hash_mgr.NewHasher(cfg.ID(), opts)
 
 	// generate new helper for new sign session
@@ -0,0 +1,99 @@
+package single
+
+import (
+	"errors"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	comm_ecdsa "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/ecdsa"
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+)
+
+var (
+	_ round.Round = (*keygen1)(nil)
+	_ round.Round = (*sign1)(nil)
+)
+
+// keygen1 is the only round of Keygen, which receives no message.
+type keygen1 struct {
+	*round.Helper
+	ec   comm_ecdsa.ECDSAKeyManager
+	opts keyopts.Options
+	// expected is the public key to produce, or nil.
+	expected curve.Point
+}
+
+// VerifyMessage implements round.Round.
+func (keygen1) VerifyMessage(round.Message) error { return nil }
+
+// StoreMessage implements round.Round.
+func (keygen1) StoreMessage(round.Message) error { return nil }
+
+// StoreBroadcastMessage implements round.Round.
+func (keygen1) StoreBroadcastMessage(round.Message) error { return nil }
+
+// Finalize implements round.Round
+//
+// - sample the key x and store it.
+func (r *keygen1) Finalize(chan<- *round.Message) (round.Session, error) {
+	key, err := r.ec.GenerateKey(r.opts)
+	if err != nil {
+		return r, err
+	}
+	if r.expected != nil && !r.expected.Equal(key.PublicKeyRaw()) {
+		return r.AbortRound(errors.New("generated key does not match the expected public key")), nil
+	}
+	return r.ResultRound(key.PublicKeyRaw()), nil
+}
+
+func (keygen1) CanFinalize() bool { return true }
+
+// MessageContent implements round.Round.
+func (keygen1) MessageContent() round.Content { return nil }
+
+// Number implements round.Round.
+func (keygen1) Number() round.Number { return 1 }
+
+// sign1 is the only round of Sign, which receives no message.
+type sign1 struct {
+	*round.Helper
+	ec      comm_ecdsa.ECDSAKeyManager
+	opts    keyopts.Options
+	message []byte
+}
+
+// VerifyMessage implements round.Round.
+func (sign1) VerifyMessage(round.Message) error { return nil }
+
+// StoreMessage implements round.Round.
+func (sign1) StoreMessage(round.Message) error { return nil }
+
+// StoreBroadcastMessage implements round.Round.
+func (sign1) StoreBroadcastMessage(round.Message) error { return nil }
+
+// Finalize implements round.Round
+//
+// - compute k from x and m as in RFC 6979
+// - return σ = (R = k⋅G, s = k⁻¹(m + r⋅x)).
+func (r *sign1) Finalize(chan<- *round.Message) (round.Session, error) {
+	key, err := r.ec.GetKey(r.opts)
+	if err != nil {
+		return r, err
+	}
+	sig, err := key.Sign(r.message)
+	if err != nil {
+		return r, err
+	}
+	if !sig.Verify(key.PublicKeyRaw(), r.message) {
+		return r.AbortRound(errors.New("failed to validate signature")), nil
+	}
+	return r.ResultRound(sig), nil
+}
+
+func (sign1) CanFinalize() bool { return true }
+
+// MessageContent implements round.Round.
+func (sign1) MessageContent() round.Content { return nil }
+
+// Number implements round.Round.
+func (sign1) Number() round.Number { return 1 }
@@ -0,0 +1,128 @@
+// Package single implements a signer holding a whole ECDSA key, for development and as a fallback.
+//
+// It has the same API as the CMP protocol: Keygen and Sign return a protocol.StartFunc to be run by a
+// protocol.MultiHandler, keys are kept in the same key managers, and Sign returns an *ecdsa.Signature.
+// Applications can therefore be developed against a single party, and switch to CMP in production.
+//
+// Nonces are derived from the key and the message as specified by RFC 6979, so that the same message
+// always gets the same signature. This makes signatures reproducible for audits, but also means that
+// the key is not protected by a threshold: it must never be used for production funds.
+package single
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/pool"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/lib/round"
+	comm_ecdsa "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/ecdsa"
+	comm_hash "github.com/mr-shifu/mpc-lib/pkg/common/cryptosuite/hash"
+	comm_keyopts "github.com/mr-shifu/mpc-lib/pkg/common/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/common/keystore"
+	"github.com/mr-shifu/mpc-lib/pkg/common/vault"
+	sw_ecdsa "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/ecdsa"
+	sw_hash "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/hash"
+	sw_vss "github.com/mr-shifu/mpc-lib/pkg/cryptosuite/sw/vss"
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	comm_config "github.com/mr-shifu/mpc-lib/pkg/mpc/common/config"
+)
+
+const (
+	// KeygenProtocolID and SignProtocolID identify the single party protocols in SSIDs.
+	KeygenProtocolID = "single/keygen"
+	SignProtocolID   = "single/sign"
+	// Version is the current version of the single party protocols.
+	Version round.Version = 1
+)
+
+var ErrNotSingleParty = errors.New("single: config must contain only this party, with threshold 0")
+
+type Signer struct {
+	ec       comm_ecdsa.ECDSAKeyManager
+	hash_mgr comm_hash.HashManager
+}
+
+func NewSigner(ksf keystore.KeystoreFactory, krf comm_keyopts.KeyOptsFactory, vf vault.VaultFactory) *Signer {
+	vss_kr := krf.NewKeyOpts(nil)
+	vss_vault := vf.NewVault(nil)
+	vss_ks := ksf.NewKeystore(vss_vault, vss_kr, nil)
+	vss_km := sw_vss.NewVssKeyManager(vss_ks, curve.Secp256k1{})
+
+	ec_kr := krf.NewKeyOpts(nil)
+	ec_vault := vf.NewVault(nil)
+	ec_ks := ksf.NewKeystore(ec_vault, ec_kr, nil)
+	sch_kr := krf.NewKeyOpts(nil)
+	sch_vault := vf.NewVault(nil)
+	sch_ks := ksf.NewKeystore(sch_vault, sch_kr, nil)
+	ecdsa_km := sw_ecdsa.NewECDSAKeyManager(ec_ks, sch_ks, vss_km, &sw_ecdsa.Config{Group: curve.Secp256k1{}})
+
+	hash_kr := krf.NewKeyOpts(nil)
+	hash_vault := vf.NewVault(nil)
+	hash_ks := ksf.NewKeystore(hash_vault, hash_kr, nil)
+	hash_mgr := sw_hash.NewHashManager(hash_ks)
+
+	return &Signer{
+		ec:       ecdsa_km,
+		hash_mgr: hash_mgr,
+	}
+}
+
+// Keygen generates a new ECDSA key over the curve defined by `cfg`, held entirely by this party.
+// Returns the public key as a curve.Point if successful.
+func (s *Signer) Keygen(cfg comm_config.KeyConfig, pl *pool.Pool) protocol.StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		helper, opts, err := s.session(KeygenProtocolID, cfg.ID(), cfg.ID(), cfg.SelfID(), cfg.PartyIDs(), cfg.Threshold(), cfg.Group(), sessionID, pl)
+		if err != nil {
+			return nil, fmt.Errorf("keygen: %w", err)
+		}
+		return &keygen1{Helper: helper, ec: s.ec, opts: opts, expected: cfg.ExpectedPublicKey()}, nil
+	}
+}
+
+// Sign generates an ECDSA signature for the message of `cfg` with the key generated by Keygen.
+// Returns *ecdsa.Signature if successful.
+func (s *Signer) Sign(cfg comm_config.SignConfig, pl *pool.Pool) protocol.StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		if len(cfg.Message()) == 0 {
+			return nil, errors.New("sign: message is nil")
+		}
+		if len(cfg.DerivationPath()) > 0 {
+			return nil, errors.New("sign: derivation paths are not supported by the single party signer")
+		}
+		helper, opts, err := s.session(SignProtocolID, cfg.ID(), cfg.KeyID(), cfg.SelfID(), cfg.PartyIDs(), cfg.Threshold(), cfg.Group(), sessionID, pl)
+		if err != nil {
+			return nil, fmt.Errorf("sign: %w", err)
+		}
+		if _, err := s.ec.GetKey(opts); err != nil {
+			return nil, fmt.Errorf("sign: %w", err)
+		}
+		return &sign1{Helper: helper, ec: s.ec, opts: opts, message: cfg.Message()}, nil
+	}
+}
+
+// session checks that this party is the only one, and returns the helper of the session
+// and the options of the key keyID.
+func (s *Signer) session(protocolID, ID, keyID string, selfID party.ID, partyIDs party.IDSlice, threshold int, group curve.Curve, sessionID []byte, pl *pool.Pool) (*round.Helper, keyopts.Options, error) {
+	if threshold != 0 || len(partyIDs) != 1 || partyIDs[0] != selfID {
+		return nil, nil, ErrNotSingleParty
+	}
+	info := round.Info{
+		ProtocolID:       protocolID,
+		FinalRoundNumber: 1,
+		Version:          Version,
+		SelfID:           selfID,
+		PartyIDs:         partyIDs,
+		Threshold:        threshold,
+		Group:            group,
+	}
+	opts := keyopts.New().WithKeyID(keyID).WithPartyID(string(selfID))
+	hashOpts := keyopts.New().WithKeyID(ID).WithPartyID(string(selfID))
+	helper, err := round.NewSession(ID, info, sessionID, pl, s.hash_mgr.NewHasher(ID, hashOpts))
+	if err != nil {
+		return nil, nil, err
+	}
+	return helper, opts, nil
+}
@@ -0,0 +1,59 @@
+package single
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mr-shifu/mpc-lib/core/ecdsa"
+	"github.com/mr-shifu/mpc-lib/core/math/curve"
+	"github.com/mr-shifu/mpc-lib/core/party"
+	"github.com/mr-shifu/mpc-lib/core/protocol"
+	"github.com/mr-shifu/mpc-lib/pkg/keyopts"
+	"github.com/mr-shifu/mpc-lib/pkg/keystore"
+	"github.com/mr-shifu/mpc-lib/pkg/mpc/config"
+	"github.com/mr-shifu/mpc-lib/pkg/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigner(t *testing.T) {
+	id := party.ID("a")
+	ids := []party.ID{id}
+	s := NewSigner(&keystore.InmemoryKeystoreFactory{}, &keyopts.InMemoryKeyOptsFactory{}, &vault.InmemoryVaultFactory{})
+
+	keyID := uuid.New().String()
+	h, err := protocol.NewMultiHandler(s.Keygen(config.NewKeyConfig(keyID, curve.Secp256k1{}, 0, id, ids), nil), nil)
+	require.NoError(t, err)
+	r, err := h.Result()
+	require.NoError(t, err)
+	require.Implements(t, (*curve.Point)(nil), r)
+	public := r.(curve.Point)
+
+	sign := func(msg []byte) *ecdsa.Signature {
+		cfg := config.NewSignConfig(uuid.New().String(), keyID, curve.Secp256k1{}, 0, id, ids, msg)
+		h, err := protocol.NewMultiHandler(s.Sign(cfg, nil), nil)
+		require.NoError(t, err)
+		r, err := h.Result()
+		require.NoError(t, err)
+		require.IsType(t, &ecdsa.Signature{}, r)
+		return r.(*ecdsa.Signature)
+	}
+
+	msg := []byte("hello")
+	sig := sign(msg)
+	assert.True(t, sig.Verify(public, msg))
+	data, err := sig.SigEthereum()
+	require.NoError(t, err)
+	assert.Len(t, data, 65)
+
+	// nonces are deterministic, so signing again gives the same signature
+	again, err := sign(msg).SigEthereum()
+	require.NoError(t, err)
+	assert.Equal(t, data, again)
+	other, err := sign([]byte("world")).SigEthereum()
+	require.NoError(t, err)
+	assert.NotEqual(t, data, other)
+
+	_, err = protocol.NewMultiHandler(s.Keygen(config.NewKeyConfig(keyID, curve.Secp256k1{}, 1, id, []party.ID{id, "b"}), nil), nil)
+	assert.ErrorIs(t, err, ErrNotSingleParty)
+}